@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"os"
 	"os/signal"
@@ -16,14 +17,24 @@ import (
 	"github.com/99designs/gqlgen/graphql/playground"
 	"github.com/Govorov1705/ozon-test/config"
 	"github.com/Govorov1705/ozon-test/graph"
+	"github.com/Govorov1705/ozon-test/internal/broadcasters"
+	"github.com/Govorov1705/ozon-test/internal/dtos"
+	"github.com/Govorov1705/ozon-test/internal/jwt"
+	"github.com/Govorov1705/ozon-test/internal/loaders"
 	"github.com/Govorov1705/ozon-test/internal/logger"
 	"github.com/Govorov1705/ozon-test/internal/middleware"
+	"github.com/Govorov1705/ozon-test/internal/migrations"
+	"github.com/Govorov1705/ozon-test/internal/models"
+	"github.com/Govorov1705/ozon-test/internal/outbox"
 	"github.com/Govorov1705/ozon-test/internal/repositories"
 	"github.com/Govorov1705/ozon-test/internal/services"
 	"github.com/Govorov1705/ozon-test/internal/storages/inmemory"
 	inmemRepos "github.com/Govorov1705/ozon-test/internal/storages/inmemory/repositories"
 	"github.com/Govorov1705/ozon-test/internal/storages/postgresql"
+	psqlBroadcasters "github.com/Govorov1705/ozon-test/internal/storages/postgresql/broadcasters"
 	psqlRepos "github.com/Govorov1705/ozon-test/internal/storages/postgresql/repositories"
+	"github.com/Govorov1705/ozon-test/internal/storages/sqlite"
+	sqliteRepos "github.com/Govorov1705/ozon-test/internal/storages/sqlite/repositories"
 	"github.com/Govorov1705/ozon-test/internal/transactions"
 	"github.com/gin-gonic/gin"
 	"github.com/gorilla/websocket"
@@ -31,16 +42,28 @@ import (
 	"go.uber.org/zap"
 )
 
+// Ограничение сложности запроса не дает раздувать его вложенными
+// выборками, например цепочкой replies
+const queryComplexityLimit = 500
+
 func graphqlHandler(
 	usersService *services.UsersService,
 	postsService *services.PostsService,
 	commentsService *services.CommentsService,
+	reactionsService *services.ReactionsService,
+	searchService *services.SearchService,
+	communitiesService *services.CommunitiesService,
+	postActivityBroadcaster broadcasters.Broadcaster[*dtos.PostActivityEvent],
 	allowedOrigins []string,
 ) gin.HandlerFunc {
 	h := handler.New(graph.NewExecutableSchema(graph.Config{Resolvers: graph.NewResolver(
 		usersService,
 		postsService,
 		commentsService,
+		reactionsService,
+		searchService,
+		communitiesService,
+		postActivityBroadcaster,
 	)}))
 
 	h.AddTransport(transport.Websocket{
@@ -62,6 +85,7 @@ func graphqlHandler(
 	h.SetQueryCache(lru.New[*ast.QueryDocument](1000))
 
 	h.Use(extension.Introspection{})
+	h.Use(extension.FixedComplexityLimit(queryComplexityLimit))
 	h.Use(extension.AutomaticPersistedQuery{
 		Cache: lru.New[string](100),
 	})
@@ -79,42 +103,185 @@ func playgroundHandler() gin.HandlerFunc {
 	}
 }
 
+func jwksHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, jwt.GetJWKS())
+	}
+}
+
+// runMigrate выполняет подкоманду migrate для хранилища из STORAGE
+func runMigrate(args []string) error {
+	var (
+		m   *migrations.Migrator
+		err error
+	)
+
+	switch config.Cfg.Storage {
+	case config.StoragePostgreSQL:
+		m, err = postgresql.NewMigrator(config.Cfg.DBURL)
+	case config.StorageSQLite:
+		db, openErr := sqlite.Open(config.Cfg.SQLitePath)
+		if openErr != nil {
+			return openErr
+		}
+		m, err = sqlite.NewMigrator(db)
+	default:
+		return fmt.Errorf("storage %q has no migrations", config.Cfg.Storage)
+	}
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	return m.Run(args, os.Stdout)
+}
+
 func main() {
 	config.InitConfig()
 	logger.InitLogger()
 
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if err := jwt.InitKeys(); err != nil {
+		logger.Logger.Fatal("Error loading JWT keys", zap.Error(err))
+	}
+
 	var (
-		txStarter    transactions.TxStarter
-		usersRepo    repositories.UsersRepository
-		postsRepo    repositories.PostsRepository
-		commentsRepo repositories.CommentsRepository
+		txStarter         transactions.TxStarter
+		usersRepo         repositories.UsersRepository
+		refreshTokensRepo repositories.RefreshTokensRepository
+		postsRepo         repositories.PostsRepository
+		commentsRepo      repositories.CommentsRepository
+		outboxRepo        repositories.OutboxRepository
+		communitiesRepo   repositories.CommunitiesRepository
+		pgStorage         *postgresql.Storage
+		inmemStorage      *inmemory.Storage
 	)
 
 	switch config.Cfg.Storage {
 	case config.StorageInmemory:
 		logger.Logger.Info("Using in-memory storage")
 
-		txStarter = &inmemory.InMemoryTxStarter{}
+		if config.Cfg.InmemoryDataDir != "" && config.Cfg.InmemorySnapshotInterval <= 0 {
+			logger.Logger.Fatal("In-memory snapshot interval must be positive")
+		}
 
-		usersRepo = inmemRepos.NewUsersRepository()
-		postsRepo = inmemRepos.NewPostsRepository()
-		commentsRepo = inmemRepos.NewCommentsRepository()
+		var err error
+		inmemStorage, err = inmemory.NewStorage(
+			config.Cfg.InmemoryDataDir,
+			inmemory.FsyncPolicy(config.Cfg.InmemoryFsync),
+			config.Cfg.InmemoryFsyncInterval,
+		)
+		if err != nil {
+			logger.Logger.Fatal("Error creating in-memory storage", zap.Error(err))
+		}
+		txStarter = inmemory.NewTxStarter(inmemStorage)
+
+		usersRepo = inmemRepos.NewUsersRepository(inmemStorage)
+		refreshTokensRepo = inmemRepos.NewRefreshTokensRepository(inmemStorage)
+		postsRepo = inmemRepos.NewPostsRepository(inmemStorage)
+		commentsRepo = inmemRepos.NewCommentsRepository(inmemStorage)
+		outboxRepo = inmemRepos.NewOutboxRepository(inmemStorage)
+		communitiesRepo = inmemRepos.NewCommunitiesRepository(inmemStorage)
+
+		if err := inmemStorage.Load(); err != nil {
+			logger.Logger.Fatal("Error loading in-memory storage", zap.Error(err))
+		}
 	case config.StoragePostgreSQL:
 		logger.Logger.Info("Using PostgreSQL as a storage")
 
-		storage := postgresql.NewStorage(config.Cfg.DBURL)
-		txStarter = postgresql.NewPgxpoolTxStarter(storage.Pool)
+		pgStorage = postgresql.NewStorage(config.Cfg.DBURL)
+		txStarter = postgresql.NewPgxpoolTxStarter(pgStorage.Pool)
+
+		usersRepo = psqlRepos.NewUsersRepository(pgStorage.Pool)
+		refreshTokensRepo = psqlRepos.NewRefreshTokensRepository(pgStorage.Pool)
+		postsRepo = psqlRepos.NewPostsRepository(pgStorage.Pool)
+		commentsRepo = psqlRepos.NewCommentsRepository(pgStorage.Pool)
+		outboxRepo = psqlRepos.NewOutboxRepository(pgStorage.Pool)
+		communitiesRepo = psqlRepos.NewCommunitiesRepository(pgStorage.Pool)
+	case config.StorageSQLite:
+		logger.Logger.Info("Using SQLite as a storage")
+
+		sqliteStorage := sqlite.NewStorage(config.Cfg.SQLitePath)
+		txStarter = sqlite.NewSQLiteTxStarter(sqliteStorage.DB)
 
-		usersRepo = psqlRepos.NewUsersRepository(storage.Pool)
-		postsRepo = psqlRepos.NewPostsRepository(storage.Pool)
-		commentsRepo = psqlRepos.NewCommentsRepository(storage.Pool)
+		usersRepo = sqliteRepos.NewUsersRepository(sqliteStorage.DB)
+		refreshTokensRepo = sqliteRepos.NewRefreshTokensRepository(sqliteStorage.DB)
+		postsRepo = sqliteRepos.NewPostsRepository(sqliteStorage.DB)
+		commentsRepo = sqliteRepos.NewCommentsRepository(sqliteStorage.DB)
+		outboxRepo = sqliteRepos.NewOutboxRepository(sqliteStorage.DB)
+		communitiesRepo = sqliteRepos.NewCommunitiesRepository(sqliteStorage.DB)
 	default:
 		logger.Logger.Fatal("Unsupported storage backend")
 	}
 
-	usersService := services.NewUsersService(usersRepo)
-	postsService := services.NewPostsService(txStarter, postsRepo, commentsRepo)
-	commentsService := services.NewCommentsService(txStarter, commentsRepo, postsRepo)
+	bgCtx, stopBackground := context.WithCancel(context.Background())
+	defer stopBackground()
+
+	go inmemStorage.Run(bgCtx, config.Cfg.InmemorySnapshotInterval)
+
+	overflowPolicy := broadcasters.OverflowPolicy(config.Cfg.SubscriptionOverflowPolicy)
+	if overflowPolicy != broadcasters.OverflowDisconnect && overflowPolicy != broadcasters.OverflowDropOldest {
+		logger.Logger.Fatal("Unsupported subscription overflow policy")
+	}
+	if config.Cfg.SubscriptionBufferSize < 1 {
+		logger.Logger.Fatal("Subscription buffer size must be positive")
+	}
+
+	var postActivityBroadcaster broadcasters.Broadcaster[*dtos.PostActivityEvent]
+
+	switch config.Cfg.Broadcaster {
+	case config.BroadcasterInmemory:
+		logger.Logger.Info("Using in-memory broadcaster")
+
+		postActivityBroadcaster = broadcasters.NewBroadcaster[*dtos.PostActivityEvent](
+			config.Cfg.SubscriptionBufferSize,
+			overflowPolicy,
+		)
+	case config.BroadcasterPostgreSQL:
+		if pgStorage == nil {
+			logger.Logger.Fatal("PostgreSQL broadcaster requires PostgreSQL storage")
+		}
+		logger.Logger.Info("Using PostgreSQL LISTEN/NOTIFY as a broadcaster")
+
+		postActivityBroadcaster = psqlBroadcasters.NewBroadcaster(
+			bgCtx,
+			pgStorage.Pool,
+			"post_activity",
+			outbox.NewPostActivityHydrator(commentsRepo),
+			config.Cfg.SubscriptionBufferSize,
+			overflowPolicy,
+		)
+	default:
+		logger.Logger.Fatal("Unsupported broadcaster backend")
+	}
+
+	relay := outbox.NewRelay(
+		txStarter,
+		outboxRepo,
+		config.Cfg.OutboxPollInterval,
+		config.Cfg.OutboxRetention,
+	)
+	postActivityHandler := outbox.NewPostActivityHandler(commentsRepo, postActivityBroadcaster)
+	relay.Handle(models.EventCommentAdded, postActivityHandler)
+	relay.Handle(models.EventCommentUpdated, postActivityHandler)
+	relay.Handle(models.EventCommentDeleted, postActivityHandler)
+	relay.Handle(models.EventCommentsToggled, postActivityHandler)
+	relay.Handle(models.EventReactionChanged, postActivityHandler)
+	go relay.Run(bgCtx)
+
+	usersService := services.NewUsersService(txStarter, usersRepo, refreshTokensRepo)
+	postsService := services.NewPostsService(txStarter, postsRepo, commentsRepo, outboxRepo, communitiesRepo)
+	commentsService := services.NewCommentsService(txStarter, commentsRepo, postsRepo, usersRepo, outboxRepo)
+	reactionsService := services.NewReactionsService(txStarter, postsRepo, commentsRepo, outboxRepo)
+	searchService := services.NewSearchService(postsRepo, commentsRepo)
+	communitiesService := services.NewCommunitiesService(txStarter, communitiesRepo)
 
 	if config.Cfg.Mode == config.ModeProd {
 		gin.SetMode(gin.ReleaseMode)
@@ -122,14 +289,20 @@ func main() {
 
 	r := gin.Default()
 
-	r.Use(middleware.Auth)
+	r.Use(middleware.NewAuth(usersService))
+	r.Use(loaders.Middleware(usersService, postsService, commentsService, communitiesService))
 	r.Any("/query", graphqlHandler(
 		usersService,
 		postsService,
 		commentsService,
+		reactionsService,
+		searchService,
+		communitiesService,
+		postActivityBroadcaster,
 		config.Cfg.AllowedOrigins,
 	))
 	r.GET("/", playgroundHandler())
+	r.GET("/.well-known/jwks.json", jwksHandler())
 
 	srv := &http.Server{
 		Addr:    ":8080",
@@ -154,4 +327,9 @@ func main() {
 	if err := srv.Shutdown(ctx); err != nil {
 		logger.Logger.Error("HTTP server shutdown:", zap.Error(err))
 	}
+	stopBackground()
+
+	if err := inmemStorage.Close(); err != nil {
+		logger.Logger.Error("In-memory storage shutdown:", zap.Error(err))
+	}
 }
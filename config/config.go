@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/caarlos0/env/v11"
 )
@@ -12,14 +13,37 @@ const (
 	ModeProd          = "prod"
 	StorageInmemory   = "inmemory"
 	StoragePostgreSQL = "postgresql"
+	StorageSQLite     = "sqlite"
+
+	BroadcasterInmemory   = "inmemory"
+	BroadcasterPostgreSQL = "postgresql"
 )
 
 type Config struct {
-	Mode           string   `env:"MODE"`
-	SecretKey      string   `env:"SECRET_KEY"`
-	DBURL          string   `env:"DB_URL"`
-	AllowedOrigins []string `env:"ALLOWED_ORIGINS"`
-	Storage        string   `env:"STORAGE"`
+	Mode                       string        `env:"MODE"`
+	SecretKey                  string        `env:"SECRET_KEY"`
+	DBURL                      string        `env:"DB_URL"`
+	SQLitePath                 string        `env:"SQLITE_PATH" envDefault:"ozon-test.db"`
+	AutoMigrate                bool          `env:"AUTO_MIGRATE" envDefault:"false"`
+	AllowedOrigins             []string      `env:"ALLOWED_ORIGINS"`
+	Storage                    string        `env:"STORAGE"`
+	Broadcaster                string        `env:"BROADCASTER" envDefault:"inmemory"`
+	OutboxPollInterval         time.Duration `env:"OUTBOX_POLL_INTERVAL" envDefault:"200ms"`
+	SubscriptionBufferSize     int           `env:"SUBSCRIPTION_BUFFER_SIZE" envDefault:"64"`
+	SubscriptionOverflowPolicy string        `env:"SUBSCRIPTION_OVERFLOW_POLICY" envDefault:"disconnect"`
+	OutboxRetention            time.Duration `env:"OUTBOX_RETENTION" envDefault:"24h"`
+	LegacyAuth                 bool          `env:"LEGACY_AUTH" envDefault:"false"`
+	AccessTokenTTL             time.Duration `env:"ACCESS_TOKEN_TTL" envDefault:"15m"`
+	RefreshTokenTTL            time.Duration `env:"REFRESH_TOKEN_TTL" envDefault:"720h"`
+	JWTKeysDir                 string        `env:"JWT_KEYS_DIR"`
+	JWTSigningKeyID            string        `env:"JWT_SIGNING_KEY_ID"`
+	Moderators                 []string      `env:"MODERATORS"`
+	AllowedReactions           []string      `env:"ALLOWED_REACTIONS" envDefault:"👍,👎,😄,🎉,😕,❤️,🚀,👀"`
+	MaxPostTags                int           `env:"MAX_POST_TAGS" envDefault:"5"`
+	InmemoryDataDir            string        `env:"INMEMORY_DATA_DIR"`
+	InmemoryFsync              string        `env:"INMEMORY_FSYNC" envDefault:"interval"`
+	InmemoryFsyncInterval      time.Duration `env:"INMEMORY_FSYNC_INTERVAL" envDefault:"1s"`
+	InmemorySnapshotInterval   time.Duration `env:"INMEMORY_SNAPSHOT_INTERVAL" envDefault:"5m"`
 }
 
 var Cfg Config
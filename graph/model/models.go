@@ -0,0 +1,28 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CommentWithReplies описан вручную: помимо полей схемы он хранит ответы,
+// загруженные вместе с деревом, чтобы резолвер replies мог отдать их без новых запросов
+type CommentWithReplies struct {
+	ID               uuid.UUID          `json:"id"`
+	PostID           uuid.UUID          `json:"postId"`
+	UserID           *uuid.UUID         `json:"userId,omitempty"`
+	RootID           uuid.UUID          `json:"rootId"`
+	ReplyTo          *uuid.UUID         `json:"replyTo,omitempty"`
+	Content          string             `json:"content"`
+	CreatedAt        time.Time          `json:"createdAt"`
+	EditedAt         *time.Time         `json:"editedAt,omitempty"`
+	IsDeleted        bool               `json:"isDeleted"`
+	Score            int32              `json:"score"`
+	Upvotes          int32              `json:"upvotes"`
+	Downvotes        int32              `json:"downvotes"`
+	ReplyCount       int32              `json:"replyCount"`
+	HasMoreReplies   bool               `json:"hasMoreReplies"`
+	PreloadedReplies *CommentConnection `json:"-"`
+	RemainingDepth   int32              `json:"-"`
+}
@@ -3,39 +3,123 @@
 package model
 
 import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+type PostActivityEvent interface {
+	IsPostActivityEvent()
+}
+
+type SearchNode interface {
+	IsSearchNode()
+}
+
 type Auth struct {
 	Username string `json:"username"`
 	Password string `json:"password"`
 }
 
 type Comment struct {
-	ID        uuid.UUID  `json:"id"`
-	PostID    uuid.UUID  `json:"postId"`
-	UserID    uuid.UUID  `json:"userId"`
-	RootID    uuid.UUID  `json:"rootId"`
-	ReplyTo   *uuid.UUID `json:"replyTo,omitempty"`
-	Content   string     `json:"content"`
-	CreatedAt time.Time  `json:"createdAt"`
-}
-
-type CommentWithReplies struct {
-	ID        uuid.UUID             `json:"id"`
-	PostID    uuid.UUID             `json:"postId"`
-	UserID    uuid.UUID             `json:"userId"`
-	RootID    uuid.UUID             `json:"rootId"`
-	ReplyTo   *uuid.UUID            `json:"replyTo,omitempty"`
-	Content   string                `json:"content"`
-	CreatedAt time.Time             `json:"createdAt"`
-	Replies   []*CommentWithReplies `json:"replies"`
+	ID        uuid.UUID          `json:"id"`
+	PostID    uuid.UUID          `json:"postId"`
+	UserID    *uuid.UUID         `json:"userId,omitempty"`
+	Author    *User              `json:"author,omitempty"`
+	RootID    uuid.UUID          `json:"rootId"`
+	ReplyTo   *uuid.UUID         `json:"replyTo,omitempty"`
+	Content   string             `json:"content"`
+	CreatedAt time.Time          `json:"createdAt"`
+	EditedAt  *time.Time         `json:"editedAt,omitempty"`
+	IsDeleted bool               `json:"isDeleted"`
+	Score     int32              `json:"score"`
+	Upvotes   int32              `json:"upvotes"`
+	Downvotes int32              `json:"downvotes"`
+	Reactions []*Reaction        `json:"reactions"`
+	Revisions []*CommentRevision `json:"revisions"`
+	Cursor    string             `json:"cursor"`
+}
+
+func (Comment) IsSearchNode() {}
+
+type CommentAdded struct {
+	Comment *Comment `json:"comment"`
+}
+
+func (CommentAdded) IsPostActivityEvent() {}
+
+type CommentConnection struct {
+	Edges    []*CommentEdge `json:"edges"`
+	PageInfo *PageInfo      `json:"pageInfo"`
+}
+
+type CommentDeleted struct {
+	PostID    uuid.UUID `json:"postId"`
+	CommentID uuid.UUID `json:"commentId"`
+	Removed   bool      `json:"removed"`
+}
+
+func (CommentDeleted) IsPostActivityEvent() {}
+
+type CommentEdge struct {
+	Cursor string              `json:"cursor"`
+	Node   *CommentWithReplies `json:"node"`
+}
+
+type CommentRevision struct {
+	ID        uuid.UUID `json:"id"`
+	CommentID uuid.UUID `json:"commentId"`
+	Content   string    `json:"content"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type CommentUpdated struct {
+	Comment *Comment `json:"comment"`
+}
+
+func (CommentUpdated) IsPostActivityEvent() {}
+
+type CommentsToggled struct {
+	PostID             uuid.UUID `json:"postId"`
+	AreCommentsAllowed bool      `json:"areCommentsAllowed"`
+}
+
+func (CommentsToggled) IsPostActivityEvent() {}
+
+type Community struct {
+	ID                 uuid.UUID          `json:"id"`
+	OwnerID            uuid.UUID          `json:"ownerId"`
+	Owner              *User              `json:"owner"`
+	Name               string             `json:"name"`
+	Slug               string             `json:"slug"`
+	Description        string             `json:"description"`
+	AreCommentsAllowed bool               `json:"areCommentsAllowed"`
+	CreatedAt          time.Time          `json:"createdAt"`
+	MemberCount        int32              `json:"memberCount"`
+	ViewerRole         *CommunityRole     `json:"viewerRole,omitempty"`
+	Moderators         []*CommunityMember `json:"moderators"`
+	Posts              *PostConnection    `json:"posts"`
+}
+
+type CommunityMember struct {
+	UserID   uuid.UUID     `json:"userId"`
+	User     *User         `json:"user"`
+	Role     CommunityRole `json:"role"`
+	JoinedAt time.Time     `json:"joinedAt"`
 }
 
 type Jwt struct {
-	Token string `json:"token"`
+	Token        string `json:"token"`
+	RefreshToken string `json:"refreshToken"`
+}
+
+type Login struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
 }
 
 type Mutation struct {
@@ -47,34 +131,283 @@ type NewComment struct {
 	Content string     `json:"content"`
 }
 
+type NewCommunity struct {
+	Name               string  `json:"name"`
+	Slug               string  `json:"slug"`
+	Description        *string `json:"description,omitempty"`
+	AreCommentsAllowed *bool   `json:"areCommentsAllowed,omitempty"`
+}
+
 type NewPost struct {
-	Title              string `json:"title"`
-	Content            string `json:"content"`
-	AreCommentsAllowed *bool  `json:"areCommentsAllowed,omitempty"`
+	Title              string     `json:"title"`
+	Content            string     `json:"content"`
+	AreCommentsAllowed *bool      `json:"areCommentsAllowed,omitempty"`
+	Tags               []string   `json:"tags,omitempty"`
+	CommunityID        *uuid.UUID `json:"communityId,omitempty"`
+}
+
+type PageInfo struct {
+	HasNextPage     bool    `json:"hasNextPage"`
+	HasPreviousPage bool    `json:"hasPreviousPage"`
+	StartCursor     *string `json:"startCursor,omitempty"`
+	EndCursor       *string `json:"endCursor,omitempty"`
 }
 
 type Post struct {
-	ID                 uuid.UUID `json:"id"`
-	UserID             uuid.UUID `json:"userId"`
-	Title              string    `json:"title"`
-	Content            string    `json:"content"`
-	AreCommentsAllowed bool      `json:"areCommentsAllowed"`
-	CreatedAt          time.Time `json:"createdAt"`
+	ID                 uuid.UUID   `json:"id"`
+	UserID             *uuid.UUID  `json:"userId,omitempty"`
+	Author             *User       `json:"author,omitempty"`
+	CommunityID        *uuid.UUID  `json:"communityId,omitempty"`
+	Community          *Community  `json:"community,omitempty"`
+	Title              string      `json:"title"`
+	Content            string      `json:"content"`
+	AreCommentsAllowed bool        `json:"areCommentsAllowed"`
+	CreatedAt          time.Time   `json:"createdAt"`
+	UpdatedAt          *time.Time  `json:"updatedAt,omitempty"`
+	IsDeleted          bool        `json:"isDeleted"`
+	Tags               []string    `json:"tags"`
+	Reactions          []*Reaction `json:"reactions"`
+}
+
+func (Post) IsSearchNode() {}
+
+type PostConnection struct {
+	Edges    []*PostEdge `json:"edges"`
+	PageInfo *PageInfo   `json:"pageInfo"`
+}
+
+type PostEdge struct {
+	Cursor string `json:"cursor"`
+	Node   *Post  `json:"node"`
 }
 
 type PostWithComments struct {
-	Post     *Post                 `json:"post"`
-	Comments []*CommentWithReplies `json:"comments"`
+	Post     *Post              `json:"post"`
+	Comments *CommentConnection `json:"comments"`
 }
 
 type Query struct {
 }
 
+type Reaction struct {
+	Emoji            string `json:"emoji"`
+	Count            int32  `json:"count"`
+	ViewerHasReacted bool   `json:"viewerHasReacted"`
+}
+
+type ReactionChanged struct {
+	PostID   uuid.UUID `json:"postId"`
+	TargetID uuid.UUID `json:"targetId"`
+	Emoji    string    `json:"emoji"`
+	Count    int32     `json:"count"`
+}
+
+func (ReactionChanged) IsPostActivityEvent() {}
+
+type Register struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+type SearchConnection struct {
+	Edges    []*SearchEdge `json:"edges"`
+	PageInfo *PageInfo     `json:"pageInfo"`
+}
+
+type SearchEdge struct {
+	Cursor  string     `json:"cursor"`
+	Rank    float64    `json:"rank"`
+	Snippet string     `json:"snippet"`
+	Node    SearchNode `json:"node"`
+}
+
 type Subscription struct {
 }
 
+type Tag struct {
+	Name      string `json:"name"`
+	PostCount int32  `json:"postCount"`
+}
+
 type User struct {
-	ID             uuid.UUID `json:"id"`
-	Username       string    `json:"username"`
-	HashedPassword string    `json:"hashedPassword"`
+	ID           uuid.UUID `json:"id"`
+	Username     string    `json:"username"`
+	CreatedAt    time.Time `json:"createdAt"`
+	PostCount    int32     `json:"postCount"`
+	CommentCount int32     `json:"commentCount"`
+}
+
+type CommentSort string
+
+const (
+	CommentSortNew           CommentSort = "NEW"
+	CommentSortOld           CommentSort = "OLD"
+	CommentSortTop           CommentSort = "TOP"
+	CommentSortControversial CommentSort = "CONTROVERSIAL"
+	CommentSortBest          CommentSort = "BEST"
+)
+
+var AllCommentSort = []CommentSort{
+	CommentSortNew,
+	CommentSortOld,
+	CommentSortTop,
+	CommentSortControversial,
+	CommentSortBest,
+}
+
+func (e CommentSort) IsValid() bool {
+	switch e {
+	case CommentSortNew, CommentSortOld, CommentSortTop, CommentSortControversial, CommentSortBest:
+		return true
+	}
+	return false
+}
+
+func (e CommentSort) String() string {
+	return string(e)
+}
+
+func (e *CommentSort) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = CommentSort(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid CommentSort", str)
+	}
+	return nil
+}
+
+func (e CommentSort) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *CommentSort) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e CommentSort) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type CommunityRole string
+
+const (
+	CommunityRoleMember    CommunityRole = "MEMBER"
+	CommunityRoleModerator CommunityRole = "MODERATOR"
+	CommunityRoleOwner     CommunityRole = "OWNER"
+)
+
+var AllCommunityRole = []CommunityRole{
+	CommunityRoleMember,
+	CommunityRoleModerator,
+	CommunityRoleOwner,
+}
+
+func (e CommunityRole) IsValid() bool {
+	switch e {
+	case CommunityRoleMember, CommunityRoleModerator, CommunityRoleOwner:
+		return true
+	}
+	return false
+}
+
+func (e CommunityRole) String() string {
+	return string(e)
+}
+
+func (e *CommunityRole) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = CommunityRole(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid CommunityRole", str)
+	}
+	return nil
+}
+
+func (e CommunityRole) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *CommunityRole) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e CommunityRole) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type SearchType string
+
+const (
+	SearchTypePost    SearchType = "POST"
+	SearchTypeComment SearchType = "COMMENT"
+	SearchTypeAll     SearchType = "ALL"
+)
+
+var AllSearchType = []SearchType{
+	SearchTypePost,
+	SearchTypeComment,
+	SearchTypeAll,
+}
+
+func (e SearchType) IsValid() bool {
+	switch e {
+	case SearchTypePost, SearchTypeComment, SearchTypeAll:
+		return true
+	}
+	return false
+}
+
+func (e SearchType) String() string {
+	return string(e)
+}
+
+func (e *SearchType) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = SearchType(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid SearchType", str)
+	}
+	return nil
+}
+
+func (e SearchType) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *SearchType) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e SearchType) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
 }
@@ -1,9 +1,17 @@
 package graph
 
 import (
+	"context"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/Govorov1705/ozon-test/graph/model"
 	"github.com/Govorov1705/ozon-test/internal/broadcasters"
+	"github.com/Govorov1705/ozon-test/internal/dtos"
+	"github.com/Govorov1705/ozon-test/internal/mappers"
+	"github.com/Govorov1705/ozon-test/internal/models"
 	"github.com/Govorov1705/ozon-test/internal/services"
 	"github.com/go-playground/validator/v10"
+	"github.com/vektah/gqlparser/v2/gqlerror"
 )
 
 type Resolver struct {
@@ -11,19 +19,104 @@ type Resolver struct {
 	UsersService            *services.UsersService
 	PostsService            *services.PostsService
 	CommentsService         *services.CommentsService
-	CommentAddedBroadcaster *broadcasters.CommentAddedBroadcaster
+	ReactionsService        *services.ReactionsService
+	SearchService           *services.SearchService
+	CommunitiesService      *services.CommunitiesService
+	PostActivityBroadcaster broadcasters.Broadcaster[*dtos.PostActivityEvent]
 }
 
 func NewResolver(
 	us *services.UsersService,
 	ps *services.PostsService,
 	cs *services.CommentsService,
+	rs *services.ReactionsService,
+	ss *services.SearchService,
+	cms *services.CommunitiesService,
+	pab broadcasters.Broadcaster[*dtos.PostActivityEvent],
 ) *Resolver {
+	validate := validator.New()
+	validate.RegisterValidation("password", dtos.ValidatePassword)
+	validate.RegisterValidation("slug", dtos.ValidateSlug)
+
 	return &Resolver{
-		validate:                validator.New(),
+		validate:                validate,
 		UsersService:            us,
 		PostsService:            ps,
 		CommentsService:         cs,
-		CommentAddedBroadcaster: broadcasters.NewCommentAddedBroadcaster(),
+		ReactionsService:        rs,
+		SearchService:           ss,
+		CommunitiesService:      cms,
+		PostActivityBroadcaster: pab,
+	}
+}
+
+// voteComment - общая часть мутаций upvoteComment, downvoteComment и clearVote
+func (r *Resolver) voteComment(ctx context.Context, req *dtos.VoteCommentRequest) (*model.Comment, error) {
+	err := r.validate.Struct(req)
+	if err != nil {
+		return nil, &gqlerror.Error{
+			Message: err.Error(),
+			Path:    graphql.GetPath(ctx),
+		}
+	}
+
+	comment, err := r.CommentsService.VoteComment(ctx, req)
+	if err != nil {
+		return nil, &gqlerror.Error{
+			Message: err.Error(),
+			Path:    graphql.GetPath(ctx),
+		}
+	}
+
+	return mappers.ModelCommentToGQL(comment), nil
+}
+
+// changeReaction - общая часть мутаций addReaction и removeReaction
+func (r *Resolver) changeReaction(
+	ctx context.Context,
+	change func(ctx context.Context, req *dtos.ReactionRequest) ([]*models.ReactionCount, error),
+	req *dtos.ReactionRequest,
+) ([]*model.Reaction, error) {
+	err := r.validate.Struct(req)
+	if err != nil {
+		return nil, &gqlerror.Error{
+			Message: err.Error(),
+			Path:    graphql.GetPath(ctx),
+		}
+	}
+
+	reactions, err := change(ctx, req)
+	if err != nil {
+		return nil, &gqlerror.Error{
+			Message: err.Error(),
+			Path:    graphql.GetPath(ctx),
+		}
+	}
+
+	return mappers.ModelReactionsToGQL(reactions), nil
+}
+
+// setCommunityModerator - общая часть мутаций addCommunityModerator и removeCommunityModerator
+func (r *Resolver) setCommunityModerator(
+	ctx context.Context,
+	set func(ctx context.Context, req *dtos.CommunityModeratorRequest) (*models.CommunityMember, error),
+	req *dtos.CommunityModeratorRequest,
+) (*model.CommunityMember, error) {
+	err := r.validate.Struct(req)
+	if err != nil {
+		return nil, &gqlerror.Error{
+			Message: err.Error(),
+			Path:    graphql.GetPath(ctx),
+		}
 	}
+
+	member, err := set(ctx, req)
+	if err != nil {
+		return nil, &gqlerror.Error{
+			Message: err.Error(),
+			Path:    graphql.GetPath(ctx),
+		}
+	}
+
+	return mappers.ModelCommunityMemberToGQL(member), nil
 }
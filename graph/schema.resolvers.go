@@ -8,28 +8,755 @@ import (
 	"context"
 
 	"github.com/99designs/gqlgen/graphql"
+	"github.com/Govorov1705/ozon-test/config"
 	"github.com/Govorov1705/ozon-test/graph/model"
 	"github.com/Govorov1705/ozon-test/internal/dtos"
 	"github.com/Govorov1705/ozon-test/internal/errs"
+	"github.com/Govorov1705/ozon-test/internal/loaders"
 	"github.com/Govorov1705/ozon-test/internal/mappers"
 	"github.com/Govorov1705/ozon-test/internal/middleware"
+	"github.com/Govorov1705/ozon-test/internal/pagination"
 	"github.com/google/uuid"
 	"github.com/vektah/gqlparser/v2/gqlerror"
 )
 
+// Author is the resolver for the author field.
+func (r *commentResolver) Author(ctx context.Context, obj *model.Comment) (*model.User, error) {
+	if obj.UserID == nil {
+		return nil, nil
+	}
+
+	user, err := loaders.GetUser(ctx, *obj.UserID)
+	if err != nil {
+		return nil, &gqlerror.Error{
+			Message: err.Error(),
+			Path:    graphql.GetPath(ctx),
+		}
+	}
+
+	return mappers.ModelUserToGQL(user), nil
+}
+
+// Reactions is the resolver for the reactions field.
+func (r *commentResolver) Reactions(ctx context.Context, obj *model.Comment) ([]*model.Reaction, error) {
+	reactions, err := loaders.GetCommentReactions(ctx, obj.ID)
+	if err != nil {
+		return nil, &gqlerror.Error{
+			Message: err.Error(),
+			Path:    graphql.GetPath(ctx),
+		}
+	}
+
+	return mappers.ModelReactionsToGQL(reactions), nil
+}
+
+// Revisions is the resolver for the revisions field.
+func (r *commentResolver) Revisions(ctx context.Context, obj *model.Comment) ([]*model.CommentRevision, error) {
+	revisions, err := loaders.GetCommentRevisions(ctx, obj.ID)
+	if err != nil {
+		return nil, &gqlerror.Error{
+			Message: err.Error(),
+			Path:    graphql.GetPath(ctx),
+		}
+	}
+
+	return mappers.ModelCommentRevisionsToGQL(revisions), nil
+}
+
+// Author is the resolver for the author field.
+func (r *commentWithRepliesResolver) Author(ctx context.Context, obj *model.CommentWithReplies) (*model.User, error) {
+	if obj.UserID == nil {
+		return nil, nil
+	}
+
+	user, err := loaders.GetUser(ctx, *obj.UserID)
+	if err != nil {
+		return nil, &gqlerror.Error{
+			Message: err.Error(),
+			Path:    graphql.GetPath(ctx),
+		}
+	}
+
+	return mappers.ModelUserToGQL(user), nil
+}
+
+// Reactions is the resolver for the reactions field.
+func (r *commentWithRepliesResolver) Reactions(ctx context.Context, obj *model.CommentWithReplies) ([]*model.Reaction, error) {
+	reactions, err := loaders.GetCommentReactions(ctx, obj.ID)
+	if err != nil {
+		return nil, &gqlerror.Error{
+			Message: err.Error(),
+			Path:    graphql.GetPath(ctx),
+		}
+	}
+
+	return mappers.ModelReactionsToGQL(reactions), nil
+}
+
+// Revisions is the resolver for the revisions field.
+func (r *commentWithRepliesResolver) Revisions(ctx context.Context, obj *model.CommentWithReplies) ([]*model.CommentRevision, error) {
+	revisions, err := loaders.GetCommentRevisions(ctx, obj.ID)
+	if err != nil {
+		return nil, &gqlerror.Error{
+			Message: err.Error(),
+			Path:    graphql.GetPath(ctx),
+		}
+	}
+
+	return mappers.ModelCommentRevisionsToGQL(revisions), nil
+}
+
+// Replies is the resolver for the replies field.
+func (r *commentWithRepliesResolver) Replies(ctx context.Context, obj *model.CommentWithReplies, first *int32, after *string) (*model.CommentConnection, error) {
+	// Глубже maxDepth дерево не раскрывается: остальные ответы клиент
+	// загружает отдельным запросом commentReplies
+	if obj.RemainingDepth <= 0 {
+		return &model.CommentConnection{
+			Edges:    []*model.CommentEdge{},
+			PageInfo: &model.PageInfo{HasNextPage: obj.ReplyCount > 0},
+		}, nil
+	}
+
+	maxDepth := obj.RemainingDepth
+
+	req := dtos.GetRepliesRequest{
+		CommentID: obj.ID,
+		First:     first,
+		After:     after,
+		MaxDepth:  &maxDepth,
+	}
+	err := r.validate.Struct(&req)
+	if err != nil {
+		return nil, &gqlerror.Error{
+			Message: err.Error(),
+			Path:    graphql.GetPath(ctx),
+		}
+	}
+
+	// Первая страница ответов загружена вместе с деревом и отдается без
+	// запроса к хранилищу, если ее хватает для запрошенного размера
+	preloaded := obj.PreloadedReplies
+	if preloaded != nil && after == nil {
+		n := int(pagination.DefaultRepliesPageSize)
+		if first != nil {
+			n = int(*first)
+		}
+		if n < len(preloaded.Edges) {
+			edges := preloaded.Edges[:n]
+			return &model.CommentConnection{
+				Edges: edges,
+				PageInfo: &model.PageInfo{
+					HasNextPage: true,
+					StartCursor: preloaded.PageInfo.StartCursor,
+					EndCursor:   &edges[n-1].Cursor,
+				},
+			}, nil
+		}
+		if n == len(preloaded.Edges) || !preloaded.PageInfo.HasNextPage {
+			return preloaded, nil
+		}
+	}
+
+	replies, err := r.CommentsService.GetReplies(ctx, &req)
+	if err != nil {
+		return nil, &gqlerror.Error{
+			Message: err.Error(),
+			Path:    graphql.GetPath(ctx),
+		}
+	}
+
+	return mappers.DTOCommentsConnectionToGQL(replies), nil
+}
+
+// Owner is the resolver for the owner field.
+func (r *communityResolver) Owner(ctx context.Context, obj *model.Community) (*model.User, error) {
+	user, err := loaders.GetUser(ctx, obj.OwnerID)
+	if err != nil {
+		return nil, &gqlerror.Error{
+			Message: err.Error(),
+			Path:    graphql.GetPath(ctx),
+		}
+	}
+
+	return mappers.ModelUserToGQL(user), nil
+}
+
+// MemberCount is the resolver for the memberCount field.
+func (r *communityResolver) MemberCount(ctx context.Context, obj *model.Community) (int32, error) {
+	count, err := loaders.GetMemberCount(ctx, obj.ID)
+	if err != nil {
+		return 0, &gqlerror.Error{
+			Message: err.Error(),
+			Path:    graphql.GetPath(ctx),
+		}
+	}
+
+	return count, nil
+}
+
+// ViewerRole is the resolver for the viewerRole field.
+func (r *communityResolver) ViewerRole(ctx context.Context, obj *model.Community) (*model.CommunityRole, error) {
+	userID, ok := middleware.GetUserID(ctx)
+	if !ok {
+		return nil, nil
+	}
+
+	role, err := r.CommunitiesService.GetViewerRole(ctx, obj.ID, userID)
+	if err != nil {
+		return nil, &gqlerror.Error{
+			Message: err.Error(),
+			Path:    graphql.GetPath(ctx),
+		}
+	}
+	if role == nil {
+		return nil, nil
+	}
+
+	GQLRole := model.CommunityRole(*role)
+	return &GQLRole, nil
+}
+
+// Moderators is the resolver for the moderators field.
+func (r *communityResolver) Moderators(ctx context.Context, obj *model.Community) ([]*model.CommunityMember, error) {
+	moderators, err := r.CommunitiesService.GetModerators(ctx, obj.ID)
+	if err != nil {
+		return nil, &gqlerror.Error{
+			Message: err.Error(),
+			Path:    graphql.GetPath(ctx),
+		}
+	}
+
+	return mappers.ModelCommunityMembersToGQL(moderators), nil
+}
+
+// Posts is the resolver for the posts field.
+func (r *communityResolver) Posts(ctx context.Context, obj *model.Community, first *int32, after *string, last *int32, before *string) (*model.PostConnection, error) {
+	req := dtos.GetPostsRequest{
+		First:       first,
+		After:       after,
+		Last:        last,
+		Before:      before,
+		CommunityID: &obj.ID,
+	}
+	err := r.validate.Struct(&req)
+	if err != nil {
+		return nil, &gqlerror.Error{
+			Message: err.Error(),
+			Path:    graphql.GetPath(ctx),
+		}
+	}
+
+	postsConnection, err := r.PostsService.GetPosts(ctx, &req)
+	if err != nil {
+		return nil, &gqlerror.Error{
+			Message: err.Error(),
+			Path:    graphql.GetPath(ctx),
+		}
+	}
+
+	return mappers.DTOPostsConnectionToGQL(postsConnection), nil
+}
+
+// User is the resolver for the user field.
+func (r *communityMemberResolver) User(ctx context.Context, obj *model.CommunityMember) (*model.User, error) {
+	user, err := loaders.GetUser(ctx, obj.UserID)
+	if err != nil {
+		return nil, &gqlerror.Error{
+			Message: err.Error(),
+			Path:    graphql.GetPath(ctx),
+		}
+	}
+
+	return mappers.ModelUserToGQL(user), nil
+}
+
 // Auth is the resolver for the auth field.
 func (r *mutationResolver) Auth(ctx context.Context, input model.Auth) (*model.Jwt, error) {
+	if !config.Cfg.LegacyAuth {
+		return nil, &gqlerror.Error{
+			Message: errs.ErrLegacyAuthDisabled.Error(),
+			Path:    graphql.GetPath(ctx),
+		}
+	}
+
+	_, ok := middleware.GetUserID(ctx)
+	if ok {
+		return nil, &gqlerror.Error{
+			Message: "You are already authenticated",
+			Path:    graphql.GetPath(ctx),
+		}
+	}
+
+	req := dtos.AuthRequest{
+		Username: input.Username,
+		Password: input.Password,
+	}
+	err := r.validate.Struct(&req)
+	if err != nil {
+		return nil, &gqlerror.Error{
+			Message: err.Error(),
+			Path:    graphql.GetPath(ctx),
+		}
+	}
+
+	tokens, err := r.UsersService.Auth(ctx, &req)
+	if err != nil {
+		return nil, &gqlerror.Error{
+			Message: err.Error(),
+			Path:    graphql.GetPath(ctx),
+		}
+	}
+
+	return mappers.DTOTokenPairToGQL(tokens), nil
+}
+
+// Register is the resolver for the register field.
+func (r *mutationResolver) Register(ctx context.Context, input model.Register) (*model.Jwt, error) {
 	_, ok := middleware.GetUserID(ctx)
 	if ok {
 		return nil, &gqlerror.Error{
-			Message: "You are already authenticated",
+			Message: "You are already authenticated",
+			Path:    graphql.GetPath(ctx),
+		}
+	}
+
+	req := dtos.RegisterRequest{
+		Username: input.Username,
+		Password: input.Password,
+	}
+	err := r.validate.Struct(&req)
+	if err != nil {
+		return nil, &gqlerror.Error{
+			Message: err.Error(),
+			Path:    graphql.GetPath(ctx),
+		}
+	}
+
+	tokens, err := r.UsersService.Register(ctx, &req)
+	if err != nil {
+		return nil, &gqlerror.Error{
+			Message: err.Error(),
+			Path:    graphql.GetPath(ctx),
+		}
+	}
+
+	return mappers.DTOTokenPairToGQL(tokens), nil
+}
+
+// Login is the resolver for the login field.
+func (r *mutationResolver) Login(ctx context.Context, input model.Login) (*model.Jwt, error) {
+	_, ok := middleware.GetUserID(ctx)
+	if ok {
+		return nil, &gqlerror.Error{
+			Message: "You are already authenticated",
+			Path:    graphql.GetPath(ctx),
+		}
+	}
+
+	req := dtos.LoginRequest{
+		Username: input.Username,
+		Password: input.Password,
+	}
+	err := r.validate.Struct(&req)
+	if err != nil {
+		return nil, &gqlerror.Error{
+			Message: err.Error(),
+			Path:    graphql.GetPath(ctx),
+		}
+	}
+
+	tokens, err := r.UsersService.Login(ctx, &req)
+	if err != nil {
+		return nil, &gqlerror.Error{
+			Message: err.Error(),
+			Path:    graphql.GetPath(ctx),
+		}
+	}
+
+	return mappers.DTOTokenPairToGQL(tokens), nil
+}
+
+// RefreshToken is the resolver for the refreshToken field.
+func (r *mutationResolver) RefreshToken(ctx context.Context, refreshToken string) (*model.Jwt, error) {
+	req := dtos.RefreshTokenRequest{
+		RefreshToken: refreshToken,
+	}
+	err := r.validate.Struct(&req)
+	if err != nil {
+		return nil, &gqlerror.Error{
+			Message: err.Error(),
+			Path:    graphql.GetPath(ctx),
+		}
+	}
+
+	tokens, err := r.UsersService.RefreshToken(ctx, &req)
+	if err != nil {
+		return nil, &gqlerror.Error{
+			Message: err.Error(),
+			Path:    graphql.GetPath(ctx),
+		}
+	}
+
+	return mappers.DTOTokenPairToGQL(tokens), nil
+}
+
+// Logout is the resolver for the logout field.
+func (r *mutationResolver) Logout(ctx context.Context) (bool, error) {
+	tokenID, ok := middleware.GetTokenID(ctx)
+	if !ok {
+		return false, &gqlerror.Error{
+			Message: errs.ErrUnauthenticated.Error(),
+			Path:    graphql.GetPath(ctx),
+		}
+	}
+
+	err := r.UsersService.Logout(ctx, tokenID)
+	if err != nil {
+		return false, &gqlerror.Error{
+			Message: err.Error(),
+			Path:    graphql.GetPath(ctx),
+		}
+	}
+
+	return true, nil
+}
+
+// LogoutAllSessions is the resolver for the logoutAllSessions field.
+func (r *mutationResolver) LogoutAllSessions(ctx context.Context) (bool, error) {
+	userID, ok := middleware.GetUserID(ctx)
+	if !ok {
+		return false, &gqlerror.Error{
+			Message: errs.ErrUnauthenticated.Error(),
+			Path:    graphql.GetPath(ctx),
+		}
+	}
+
+	err := r.UsersService.LogoutAllSessions(ctx, userID)
+	if err != nil {
+		return false, &gqlerror.Error{
+			Message: err.Error(),
+			Path:    graphql.GetPath(ctx),
+		}
+	}
+
+	return true, nil
+}
+
+// CreatePost is the resolver for the createPost field.
+func (r *mutationResolver) CreatePost(ctx context.Context, input model.NewPost) (*model.Post, error) {
+	userID, ok := middleware.GetUserID(ctx)
+	if !ok {
+		return nil, &gqlerror.Error{
+			Message: errs.ErrUnauthenticated.Error(),
+			Path:    graphql.GetPath(ctx),
+		}
+	}
+
+	req := dtos.CreatePostRequest{
+		UserID:             userID,
+		Title:              input.Title,
+		Content:            input.Content,
+		AreCommentsAllowed: input.AreCommentsAllowed,
+		Tags:               input.Tags,
+		CommunityID:        input.CommunityID,
+	}
+	err := r.validate.Struct(&req)
+	if err != nil {
+		return nil, &gqlerror.Error{
+			Message: err.Error(),
+			Path:    graphql.GetPath(ctx),
+		}
+	}
+
+	post, err := r.PostsService.CreatePost(ctx, &req)
+	if err != nil {
+		return nil, &gqlerror.Error{
+			Message: err.Error(),
+			Path:    graphql.GetPath(ctx),
+		}
+	}
+
+	return mappers.ModelPostToGQL(post), nil
+}
+
+// CreateComment is the resolver for the createComment field.
+func (r *mutationResolver) CreateComment(ctx context.Context, input model.NewComment) (*model.Comment, error) {
+	userID, ok := middleware.GetUserID(ctx)
+	if !ok {
+		return nil, &gqlerror.Error{
+			Message: errs.ErrUnauthenticated.Error(),
+			Path:    graphql.GetPath(ctx),
+		}
+	}
+
+	req := dtos.CreateCommentRequest{
+		PostID:  input.PostID,
+		UserID:  userID,
+		ReplyTo: input.ReplyTo,
+		Content: input.Content,
+	}
+	err := r.validate.Struct(&req)
+	if err != nil {
+		return nil, &gqlerror.Error{
+			Message: err.Error(),
+			Path:    graphql.GetPath(ctx),
+		}
+	}
+
+	comment, err := r.CommentsService.CreateComment(ctx, &req)
+	if err != nil {
+		return nil, &gqlerror.Error{
+			Message: err.Error(),
+			Path:    graphql.GetPath(ctx),
+		}
+	}
+
+	return mappers.ModelCommentToGQL(comment), nil
+}
+
+// EditComment is the resolver for the editComment field.
+func (r *mutationResolver) EditComment(ctx context.Context, id uuid.UUID, content string) (*model.Comment, error) {
+	userID, ok := middleware.GetUserID(ctx)
+	if !ok {
+		return nil, &gqlerror.Error{
+			Message: errs.ErrUnauthenticated.Error(),
+			Path:    graphql.GetPath(ctx),
+		}
+	}
+
+	req := dtos.EditCommentRequest{
+		CommentID: id,
+		UserID:    userID,
+		Content:   content,
+	}
+	err := r.validate.Struct(&req)
+	if err != nil {
+		return nil, &gqlerror.Error{
+			Message: err.Error(),
+			Path:    graphql.GetPath(ctx),
+		}
+	}
+
+	comment, err := r.CommentsService.EditComment(ctx, &req)
+	if err != nil {
+		return nil, &gqlerror.Error{
+			Message: err.Error(),
+			Path:    graphql.GetPath(ctx),
+		}
+	}
+
+	return mappers.ModelCommentToGQL(comment), nil
+}
+
+// DeleteComment is the resolver for the deleteComment field.
+func (r *mutationResolver) DeleteComment(ctx context.Context, id uuid.UUID) (*model.Comment, error) {
+	userID, ok := middleware.GetUserID(ctx)
+	if !ok {
+		return nil, &gqlerror.Error{
+			Message: errs.ErrUnauthenticated.Error(),
+			Path:    graphql.GetPath(ctx),
+		}
+	}
+
+	req := dtos.DeleteCommentRequest{
+		CommentID: id,
+		UserID:    userID,
+	}
+	err := r.validate.Struct(&req)
+	if err != nil {
+		return nil, &gqlerror.Error{
+			Message: err.Error(),
+			Path:    graphql.GetPath(ctx),
+		}
+	}
+
+	comment, err := r.CommentsService.DeleteComment(ctx, &req)
+	if err != nil {
+		return nil, &gqlerror.Error{
+			Message: err.Error(),
+			Path:    graphql.GetPath(ctx),
+		}
+	}
+
+	return mappers.ModelCommentToGQL(comment), nil
+}
+
+// HardDeleteComment is the resolver for the hardDeleteComment field.
+func (r *mutationResolver) HardDeleteComment(ctx context.Context, id uuid.UUID) (bool, error) {
+	userID, ok := middleware.GetUserID(ctx)
+	if !ok {
+		return false, &gqlerror.Error{
+			Message: errs.ErrUnauthenticated.Error(),
+			Path:    graphql.GetPath(ctx),
+		}
+	}
+
+	req := dtos.DeleteCommentRequest{
+		CommentID: id,
+		UserID:    userID,
+	}
+	err := r.validate.Struct(&req)
+	if err != nil {
+		return false, &gqlerror.Error{
+			Message: err.Error(),
+			Path:    graphql.GetPath(ctx),
+		}
+	}
+
+	removed, err := r.CommentsService.HardDeleteComment(ctx, &req)
+	if err != nil {
+		return false, &gqlerror.Error{
+			Message: err.Error(),
+			Path:    graphql.GetPath(ctx),
+		}
+	}
+
+	return removed, nil
+}
+
+// UpvoteComment is the resolver for the upvoteComment field.
+func (r *mutationResolver) UpvoteComment(ctx context.Context, id uuid.UUID) (*model.Comment, error) {
+	userID, ok := middleware.GetUserID(ctx)
+	if !ok {
+		return nil, &gqlerror.Error{
+			Message: errs.ErrUnauthenticated.Error(),
+			Path:    graphql.GetPath(ctx),
+		}
+	}
+
+	return r.voteComment(ctx, &dtos.VoteCommentRequest{
+		CommentID: id,
+		UserID:    userID,
+		Value:     1,
+	})
+}
+
+// DownvoteComment is the resolver for the downvoteComment field.
+func (r *mutationResolver) DownvoteComment(ctx context.Context, id uuid.UUID) (*model.Comment, error) {
+	userID, ok := middleware.GetUserID(ctx)
+	if !ok {
+		return nil, &gqlerror.Error{
+			Message: errs.ErrUnauthenticated.Error(),
+			Path:    graphql.GetPath(ctx),
+		}
+	}
+
+	return r.voteComment(ctx, &dtos.VoteCommentRequest{
+		CommentID: id,
+		UserID:    userID,
+		Value:     -1,
+	})
+}
+
+// ClearVote is the resolver for the clearVote field.
+func (r *mutationResolver) ClearVote(ctx context.Context, id uuid.UUID) (*model.Comment, error) {
+	userID, ok := middleware.GetUserID(ctx)
+	if !ok {
+		return nil, &gqlerror.Error{
+			Message: errs.ErrUnauthenticated.Error(),
+			Path:    graphql.GetPath(ctx),
+		}
+	}
+
+	return r.voteComment(ctx, &dtos.VoteCommentRequest{
+		CommentID: id,
+		UserID:    userID,
+		Value:     0,
+	})
+}
+
+// AddReaction is the resolver for the addReaction field.
+func (r *mutationResolver) AddReaction(ctx context.Context, targetID uuid.UUID, emoji string) ([]*model.Reaction, error) {
+	userID, ok := middleware.GetUserID(ctx)
+	if !ok {
+		return nil, &gqlerror.Error{
+			Message: errs.ErrUnauthenticated.Error(),
+			Path:    graphql.GetPath(ctx),
+		}
+	}
+
+	return r.changeReaction(ctx, r.ReactionsService.AddReaction, &dtos.ReactionRequest{
+		TargetID: targetID,
+		UserID:   userID,
+		Emoji:    emoji,
+	})
+}
+
+// RemoveReaction is the resolver for the removeReaction field.
+func (r *mutationResolver) RemoveReaction(ctx context.Context, targetID uuid.UUID, emoji string) ([]*model.Reaction, error) {
+	userID, ok := middleware.GetUserID(ctx)
+	if !ok {
+		return nil, &gqlerror.Error{
+			Message: errs.ErrUnauthenticated.Error(),
+			Path:    graphql.GetPath(ctx),
+		}
+	}
+
+	return r.changeReaction(ctx, r.ReactionsService.RemoveReaction, &dtos.ReactionRequest{
+		TargetID: targetID,
+		UserID:   userID,
+		Emoji:    emoji,
+	})
+}
+
+// DisableComments is the resolver for the disableComments field.
+func (r *mutationResolver) DisableComments(ctx context.Context, postID uuid.UUID) (*model.Post, error) {
+	userID, ok := middleware.GetUserID(ctx)
+	if !ok {
+		return nil, &gqlerror.Error{
+			Message: errs.ErrUnauthenticated.Error(),
+			Path:    graphql.GetPath(ctx),
+		}
+	}
+
+	post, err := r.PostsService.DisableComments(ctx, userID, postID)
+	if err != nil {
+		return nil, &gqlerror.Error{
+			Message: err.Error(),
+			Path:    graphql.GetPath(ctx),
+		}
+	}
+
+	return mappers.ModelPostToGQL(post), nil
+}
+
+// EnableComments is the resolver for the enableComments field.
+func (r *mutationResolver) EnableComments(ctx context.Context, postID uuid.UUID) (*model.Post, error) {
+	userID, ok := middleware.GetUserID(ctx)
+	if !ok {
+		return nil, &gqlerror.Error{
+			Message: errs.ErrUnauthenticated.Error(),
+			Path:    graphql.GetPath(ctx),
+		}
+	}
+
+	post, err := r.PostsService.EnableComments(ctx, userID, postID)
+	if err != nil {
+		return nil, &gqlerror.Error{
+			Message: err.Error(),
 			Path:    graphql.GetPath(ctx),
 		}
 	}
 
-	req := dtos.AuthRequest{
-		Username: input.Username,
-		Password: input.Password,
+	return mappers.ModelPostToGQL(post), nil
+}
+
+// UpdatePost is the resolver for the updatePost field.
+func (r *mutationResolver) UpdatePost(ctx context.Context, id uuid.UUID, title *string, content *string, tags []string) (*model.Post, error) {
+	userID, ok := middleware.GetUserID(ctx)
+	if !ok {
+		return nil, &gqlerror.Error{
+			Message: errs.ErrUnauthenticated.Error(),
+			Path:    graphql.GetPath(ctx),
+		}
+	}
+
+	req := dtos.UpdatePostRequest{
+		PostID:  id,
+		UserID:  userID,
+		Title:   title,
+		Content: content,
+		Tags:    tags,
 	}
 	err := r.validate.Struct(&req)
 	if err != nil {
@@ -39,7 +766,7 @@ func (r *mutationResolver) Auth(ctx context.Context, input model.Auth) (*model.J
 		}
 	}
 
-	token, err := r.UsersService.Auth(ctx, &req)
+	post, err := r.PostsService.UpdatePost(ctx, &req)
 	if err != nil {
 		return nil, &gqlerror.Error{
 			Message: err.Error(),
@@ -47,11 +774,11 @@ func (r *mutationResolver) Auth(ctx context.Context, input model.Auth) (*model.J
 		}
 	}
 
-	return &model.Jwt{Token: token}, nil
+	return mappers.ModelPostToGQL(post), nil
 }
 
-// CreatePost is the resolver for the createPost field.
-func (r *mutationResolver) CreatePost(ctx context.Context, input model.NewPost) (*model.Post, error) {
+// DeletePost is the resolver for the deletePost field.
+func (r *mutationResolver) DeletePost(ctx context.Context, id uuid.UUID) (*model.Post, error) {
 	userID, ok := middleware.GetUserID(ctx)
 	if !ok {
 		return nil, &gqlerror.Error{
@@ -60,12 +787,36 @@ func (r *mutationResolver) CreatePost(ctx context.Context, input model.NewPost)
 		}
 	}
 
-	req := dtos.CreatePostRequest{
-		UserID:             userID,
-		Title:              input.Title,
-		Content:            input.Content,
+	post, err := r.PostsService.DeletePost(ctx, userID, id)
+	if err != nil {
+		return nil, &gqlerror.Error{
+			Message: err.Error(),
+			Path:    graphql.GetPath(ctx),
+		}
+	}
+
+	return mappers.ModelPostToGQL(post), nil
+}
+
+// CreateCommunity is the resolver for the createCommunity field.
+func (r *mutationResolver) CreateCommunity(ctx context.Context, input model.NewCommunity) (*model.Community, error) {
+	userID, ok := middleware.GetUserID(ctx)
+	if !ok {
+		return nil, &gqlerror.Error{
+			Message: errs.ErrUnauthenticated.Error(),
+			Path:    graphql.GetPath(ctx),
+		}
+	}
+
+	req := dtos.CreateCommunityRequest{
+		OwnerID:            userID,
+		Name:               input.Name,
+		Slug:               input.Slug,
 		AreCommentsAllowed: input.AreCommentsAllowed,
 	}
+	if input.Description != nil {
+		req.Description = *input.Description
+	}
 	err := r.validate.Struct(&req)
 	if err != nil {
 		return nil, &gqlerror.Error{
@@ -74,7 +825,7 @@ func (r *mutationResolver) CreatePost(ctx context.Context, input model.NewPost)
 		}
 	}
 
-	post, err := r.PostsService.CreatePost(ctx, &req)
+	community, err := r.CommunitiesService.CreateCommunity(ctx, &req)
 	if err != nil {
 		return nil, &gqlerror.Error{
 			Message: err.Error(),
@@ -82,11 +833,11 @@ func (r *mutationResolver) CreatePost(ctx context.Context, input model.NewPost)
 		}
 	}
 
-	return mappers.ModelPostToGQL(post), nil
+	return mappers.ModelCommunityToGQL(community), nil
 }
 
-// CreateComment is the resolver for the createComment field.
-func (r *mutationResolver) CreateComment(ctx context.Context, input model.NewComment) (*model.Comment, error) {
+// JoinCommunity is the resolver for the joinCommunity field.
+func (r *mutationResolver) JoinCommunity(ctx context.Context, id uuid.UUID) (*model.Community, error) {
 	userID, ok := middleware.GetUserID(ctx)
 	if !ok {
 		return nil, &gqlerror.Error{
@@ -95,13 +846,7 @@ func (r *mutationResolver) CreateComment(ctx context.Context, input model.NewCom
 		}
 	}
 
-	req := dtos.CreateCommentRequest{
-		PostID:  input.PostID,
-		UserID:  userID,
-		ReplyTo: input.ReplyTo,
-		Content: input.Content,
-	}
-	err := r.validate.Struct(&req)
+	community, err := r.CommunitiesService.JoinCommunity(ctx, userID, id)
 	if err != nil {
 		return nil, &gqlerror.Error{
 			Message: err.Error(),
@@ -109,7 +854,20 @@ func (r *mutationResolver) CreateComment(ctx context.Context, input model.NewCom
 		}
 	}
 
-	comment, err := r.CommentsService.CreateComment(ctx, &req)
+	return mappers.ModelCommunityToGQL(community), nil
+}
+
+// LeaveCommunity is the resolver for the leaveCommunity field.
+func (r *mutationResolver) LeaveCommunity(ctx context.Context, id uuid.UUID) (*model.Community, error) {
+	userID, ok := middleware.GetUserID(ctx)
+	if !ok {
+		return nil, &gqlerror.Error{
+			Message: errs.ErrUnauthenticated.Error(),
+			Path:    graphql.GetPath(ctx),
+		}
+	}
+
+	community, err := r.CommunitiesService.LeaveCommunity(ctx, userID, id)
 	if err != nil {
 		return nil, &gqlerror.Error{
 			Message: err.Error(),
@@ -117,16 +875,29 @@ func (r *mutationResolver) CreateComment(ctx context.Context, input model.NewCom
 		}
 	}
 
-	GQLComment := mappers.ModelCommentToGQL(comment)
+	return mappers.ModelCommunityToGQL(community), nil
+}
 
-	r.CommentAddedBroadcaster.Publish(req.PostID, GQLComment)
+// AddCommunityModerator is the resolver for the addCommunityModerator field.
+func (r *mutationResolver) AddCommunityModerator(ctx context.Context, communityID uuid.UUID, userID uuid.UUID) (*model.CommunityMember, error) {
+	ownerID, ok := middleware.GetUserID(ctx)
+	if !ok {
+		return nil, &gqlerror.Error{
+			Message: errs.ErrUnauthenticated.Error(),
+			Path:    graphql.GetPath(ctx),
+		}
+	}
 
-	return GQLComment, nil
+	return r.setCommunityModerator(ctx, r.CommunitiesService.AddModerator, &dtos.CommunityModeratorRequest{
+		CommunityID: communityID,
+		UserID:      ownerID,
+		MemberID:    userID,
+	})
 }
 
-// DisableComments is the resolver for the disableComments field.
-func (r *mutationResolver) DisableComments(ctx context.Context, postID uuid.UUID) (*model.Post, error) {
-	userID, ok := middleware.GetUserID(ctx)
+// RemoveCommunityModerator is the resolver for the removeCommunityModerator field.
+func (r *mutationResolver) RemoveCommunityModerator(ctx context.Context, communityID uuid.UUID, userID uuid.UUID) (*model.CommunityMember, error) {
+	ownerID, ok := middleware.GetUserID(ctx)
 	if !ok {
 		return nil, &gqlerror.Error{
 			Message: errs.ErrUnauthenticated.Error(),
@@ -134,7 +905,20 @@ func (r *mutationResolver) DisableComments(ctx context.Context, postID uuid.UUID
 		}
 	}
 
-	post, err := r.PostsService.DisableComments(ctx, userID, postID)
+	return r.setCommunityModerator(ctx, r.CommunitiesService.RemoveModerator, &dtos.CommunityModeratorRequest{
+		CommunityID: communityID,
+		UserID:      ownerID,
+		MemberID:    userID,
+	})
+}
+
+// Author is the resolver for the author field.
+func (r *postResolver) Author(ctx context.Context, obj *model.Post) (*model.User, error) {
+	if obj.UserID == nil {
+		return nil, nil
+	}
+
+	user, err := loaders.GetUser(ctx, *obj.UserID)
 	if err != nil {
 		return nil, &gqlerror.Error{
 			Message: err.Error(),
@@ -142,20 +926,29 @@ func (r *mutationResolver) DisableComments(ctx context.Context, postID uuid.UUID
 		}
 	}
 
-	return mappers.ModelPostToGQL(post), nil
+	return mappers.ModelUserToGQL(user), nil
 }
 
-// EnableComments is the resolver for the enableComments field.
-func (r *mutationResolver) EnableComments(ctx context.Context, postID uuid.UUID) (*model.Post, error) {
-	userID, ok := middleware.GetUserID(ctx)
-	if !ok {
+// Community is the resolver for the community field.
+func (r *postResolver) Community(ctx context.Context, obj *model.Post) (*model.Community, error) {
+	if obj.CommunityID == nil {
+		return nil, nil
+	}
+
+	community, err := loaders.GetCommunity(ctx, *obj.CommunityID)
+	if err != nil {
 		return nil, &gqlerror.Error{
-			Message: errs.ErrUnauthenticated.Error(),
+			Message: err.Error(),
 			Path:    graphql.GetPath(ctx),
 		}
 	}
 
-	post, err := r.PostsService.EnableComments(ctx, userID, postID)
+	return mappers.ModelCommunityToGQL(community), nil
+}
+
+// Tags is the resolver for the tags field.
+func (r *postResolver) Tags(ctx context.Context, obj *model.Post) ([]string, error) {
+	tags, err := loaders.GetPostTags(ctx, obj.ID)
 	if err != nil {
 		return nil, &gqlerror.Error{
 			Message: err.Error(),
@@ -163,7 +956,20 @@ func (r *mutationResolver) EnableComments(ctx context.Context, postID uuid.UUID)
 		}
 	}
 
-	return mappers.ModelPostToGQL(post), nil
+	return tags, nil
+}
+
+// Reactions is the resolver for the reactions field.
+func (r *postResolver) Reactions(ctx context.Context, obj *model.Post) ([]*model.Reaction, error) {
+	reactions, err := loaders.GetPostReactions(ctx, obj.ID)
+	if err != nil {
+		return nil, &gqlerror.Error{
+			Message: err.Error(),
+			Path:    graphql.GetPath(ctx),
+		}
+	}
+
+	return mappers.ModelReactionsToGQL(reactions), nil
 }
 
 // GetPosts is the resolver for the getPosts field.
@@ -179,12 +985,84 @@ func (r *queryResolver) GetPosts(ctx context.Context) ([]*model.Post, error) {
 	return mappers.ModelPostsToGQL(posts), nil
 }
 
+// Posts is the resolver for the posts field.
+func (r *queryResolver) Posts(ctx context.Context, first *int32, after *string, last *int32, before *string, tag *string) (*model.PostConnection, error) {
+	req := dtos.GetPostsRequest{
+		First:  first,
+		After:  after,
+		Last:   last,
+		Before: before,
+		Tag:    tag,
+	}
+	err := r.validate.Struct(&req)
+	if err != nil {
+		return nil, &gqlerror.Error{
+			Message: err.Error(),
+			Path:    graphql.GetPath(ctx),
+		}
+	}
+
+	postsConnection, err := r.PostsService.GetPosts(ctx, &req)
+	if err != nil {
+		return nil, &gqlerror.Error{
+			Message: err.Error(),
+			Path:    graphql.GetPath(ctx),
+		}
+	}
+
+	return mappers.DTOPostsConnectionToGQL(postsConnection), nil
+}
+
+// Tags is the resolver for the tags field.
+func (r *queryResolver) Tags(ctx context.Context, prefix *string, first *int32) ([]*model.Tag, error) {
+	req := dtos.GetTagsRequest{
+		First: first,
+	}
+	if prefix != nil {
+		req.Prefix = *prefix
+	}
+	err := r.validate.Struct(&req)
+	if err != nil {
+		return nil, &gqlerror.Error{
+			Message: err.Error(),
+			Path:    graphql.GetPath(ctx),
+		}
+	}
+
+	tags, err := r.PostsService.SearchTags(ctx, &req)
+	if err != nil {
+		return nil, &gqlerror.Error{
+			Message: err.Error(),
+			Path:    graphql.GetPath(ctx),
+		}
+	}
+
+	return mappers.ModelTagsToGQL(tags), nil
+}
+
+// Community is the resolver for the community field.
+func (r *queryResolver) Community(ctx context.Context, slug string) (*model.Community, error) {
+	community, err := r.CommunitiesService.GetCommunity(ctx, slug)
+	if err != nil {
+		return nil, &gqlerror.Error{
+			Message: err.Error(),
+			Path:    graphql.GetPath(ctx),
+		}
+	}
+
+	return mappers.ModelCommunityToGQL(community), nil
+}
+
 // GetPostWithComments is the resolver for the getPostWithComments field.
-func (r *queryResolver) GetPostWithComments(ctx context.Context, postID uuid.UUID, limit *int32, offset *int32) (*model.PostWithComments, error) {
+func (r *queryResolver) GetPostWithComments(ctx context.Context, postID uuid.UUID, first *int32, after *string, maxDepth *int32, sort *model.CommentSort) (*model.PostWithComments, error) {
 	req := dtos.GetPostWithCommentsRequest{
-		PostID: postID,
-		Limit:  limit,
-		Offset: offset,
+		PostID:   postID,
+		First:    first,
+		After:    after,
+		MaxDepth: maxDepth,
+	}
+	if sort != nil {
+		req.Sort = pagination.Sort(*sort)
 	}
 	err := r.validate.Struct(&req)
 	if err != nil {
@@ -194,7 +1072,7 @@ func (r *queryResolver) GetPostWithComments(ctx context.Context, postID uuid.UUI
 		}
 	}
 
-	postWithComments, err := r.PostsService.GetPostWithComments(ctx, postID, limit, offset)
+	postWithComments, err := r.PostsService.GetPostWithComments(ctx, &req)
 	if err != nil {
 		return nil, &gqlerror.Error{
 			Message: err.Error(),
@@ -205,27 +1083,202 @@ func (r *queryResolver) GetPostWithComments(ctx context.Context, postID uuid.UUI
 	return mappers.DTOPostWithCommentsToGQL(postWithComments), nil
 }
 
+// CommentReplies is the resolver for the commentReplies field.
+func (r *queryResolver) CommentReplies(ctx context.Context, commentID uuid.UUID, first *int32, after *string, maxDepth *int32) (*model.CommentConnection, error) {
+	req := dtos.GetRepliesRequest{
+		CommentID: commentID,
+		First:     first,
+		After:     after,
+		MaxDepth:  maxDepth,
+	}
+	err := r.validate.Struct(&req)
+	if err != nil {
+		return nil, &gqlerror.Error{
+			Message: err.Error(),
+			Path:    graphql.GetPath(ctx),
+		}
+	}
+
+	replies, err := r.CommentsService.GetReplies(ctx, &req)
+	if err != nil {
+		return nil, &gqlerror.Error{
+			Message: err.Error(),
+			Path:    graphql.GetPath(ctx),
+		}
+	}
+
+	return mappers.DTOCommentsConnectionToGQL(replies), nil
+}
+
+// Search is the resolver for the search field.
+func (r *queryResolver) Search(ctx context.Context, query string, typeArg *model.SearchType, first *int32, after *string) (*model.SearchConnection, error) {
+	req := dtos.SearchRequest{
+		Query: query,
+		First: first,
+		After: after,
+	}
+	if typeArg != nil {
+		req.Type = dtos.SearchType(*typeArg)
+	}
+	err := r.validate.Struct(&req)
+	if err != nil {
+		return nil, &gqlerror.Error{
+			Message: err.Error(),
+			Path:    graphql.GetPath(ctx),
+		}
+	}
+
+	searchConnection, err := r.SearchService.Search(ctx, &req)
+	if err != nil {
+		return nil, &gqlerror.Error{
+			Message: err.Error(),
+			Path:    graphql.GetPath(ctx),
+		}
+	}
+
+	return mappers.DTOSearchConnectionToGQL(searchConnection), nil
+}
+
+// User is the resolver for the user field.
+func (r *queryResolver) User(ctx context.Context, id uuid.UUID) (*model.User, error) {
+	user, err := r.UsersService.GetByID(ctx, id)
+	if err != nil {
+		return nil, &gqlerror.Error{
+			Message: err.Error(),
+			Path:    graphql.GetPath(ctx),
+		}
+	}
+
+	return mappers.ModelUserToGQL(user), nil
+}
+
+// UserByUsername is the resolver for the userByUsername field.
+func (r *queryResolver) UserByUsername(ctx context.Context, username string) (*model.User, error) {
+	user, err := r.UsersService.GetByUsername(ctx, username)
+	if err != nil {
+		return nil, &gqlerror.Error{
+			Message: err.Error(),
+			Path:    graphql.GetPath(ctx),
+		}
+	}
+
+	return mappers.ModelUserToGQL(user), nil
+}
+
+// Me is the resolver for the me field.
+func (r *queryResolver) Me(ctx context.Context) (*model.User, error) {
+	userID, ok := middleware.GetUserID(ctx)
+	if !ok {
+		return nil, &gqlerror.Error{
+			Message: errs.ErrUnauthenticated.Error(),
+			Path:    graphql.GetPath(ctx),
+		}
+	}
+
+	user, err := r.UsersService.GetByID(ctx, userID)
+	if err != nil {
+		return nil, &gqlerror.Error{
+			Message: err.Error(),
+			Path:    graphql.GetPath(ctx),
+		}
+	}
+
+	return mappers.ModelUserToGQL(user), nil
+}
+
 // CommentAdded is the resolver for the commentAdded field.
-func (r *subscriptionResolver) CommentAdded(ctx context.Context, postID uuid.UUID) (<-chan *model.Comment, error) {
-	ch := r.CommentAddedBroadcaster.Subscribe(postID)
+func (r *subscriptionResolver) CommentAdded(ctx context.Context, postID uuid.UUID, since *string) (<-chan *model.Comment, error) {
+	if since != nil {
+		_, err := pagination.DecodeCursor(*since)
+		if err != nil {
+			return nil, &gqlerror.Error{
+				Message: err.Error(),
+				Path:    graphql.GetPath(ctx),
+			}
+		}
+	}
+
+	// Подписываемся до догрузки из хранилища, чтобы не пропустить
+	// комментарии, добавленные в промежутке
+	sub := r.PostActivityBroadcaster.Subscribe(postID)
+	ch := make(chan *model.Comment)
+
+	go r.streamCommentsAdded(ctx, postID, since, sub, ch)
+
+	return ch, nil
+}
 
-	go func() {
-		<-ctx.Done()
-		r.CommentAddedBroadcaster.Unsubscribe(postID, ch)
-	}()
+// PostActivity is the resolver for the postActivity field.
+func (r *subscriptionResolver) PostActivity(ctx context.Context, postID uuid.UUID) (<-chan model.PostActivityEvent, error) {
+	sub := r.PostActivityBroadcaster.Subscribe(postID)
+	ch := make(chan model.PostActivityEvent)
+
+	go r.streamPostActivity(ctx, postID, sub, ch)
 
 	return ch, nil
 }
 
+// PostCount is the resolver for the postCount field.
+func (r *userResolver) PostCount(ctx context.Context, obj *model.User) (int32, error) {
+	count, err := loaders.GetPostCount(ctx, obj.ID)
+	if err != nil {
+		return 0, &gqlerror.Error{
+			Message: err.Error(),
+			Path:    graphql.GetPath(ctx),
+		}
+	}
+
+	return count, nil
+}
+
+// CommentCount is the resolver for the commentCount field.
+func (r *userResolver) CommentCount(ctx context.Context, obj *model.User) (int32, error) {
+	count, err := loaders.GetCommentCount(ctx, obj.ID)
+	if err != nil {
+		return 0, &gqlerror.Error{
+			Message: err.Error(),
+			Path:    graphql.GetPath(ctx),
+		}
+	}
+
+	return count, nil
+}
+
+// Comment returns CommentResolver implementation.
+func (r *Resolver) Comment() CommentResolver { return &commentResolver{r} }
+
+// CommentWithReplies returns CommentWithRepliesResolver implementation.
+func (r *Resolver) CommentWithReplies() CommentWithRepliesResolver {
+	return &commentWithRepliesResolver{r}
+}
+
+// Community returns CommunityResolver implementation.
+func (r *Resolver) Community() CommunityResolver { return &communityResolver{r} }
+
+// CommunityMember returns CommunityMemberResolver implementation.
+func (r *Resolver) CommunityMember() CommunityMemberResolver { return &communityMemberResolver{r} }
+
 // Mutation returns MutationResolver implementation.
 func (r *Resolver) Mutation() MutationResolver { return &mutationResolver{r} }
 
+// Post returns PostResolver implementation.
+func (r *Resolver) Post() PostResolver { return &postResolver{r} }
+
 // Query returns QueryResolver implementation.
 func (r *Resolver) Query() QueryResolver { return &queryResolver{r} }
 
 // Subscription returns SubscriptionResolver implementation.
 func (r *Resolver) Subscription() SubscriptionResolver { return &subscriptionResolver{r} }
 
+// User returns UserResolver implementation.
+func (r *Resolver) User() UserResolver { return &userResolver{r} }
+
+type commentResolver struct{ *Resolver }
+type commentWithRepliesResolver struct{ *Resolver }
+type communityResolver struct{ *Resolver }
+type communityMemberResolver struct{ *Resolver }
 type mutationResolver struct{ *Resolver }
+type postResolver struct{ *Resolver }
 type queryResolver struct{ *Resolver }
 type subscriptionResolver struct{ *Resolver }
+type userResolver struct{ *Resolver }
@@ -0,0 +1,145 @@
+package graph
+
+import (
+	"context"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/Govorov1705/ozon-test/graph/model"
+	"github.com/Govorov1705/ozon-test/internal/broadcasters"
+	"github.com/Govorov1705/ozon-test/internal/dtos"
+	"github.com/Govorov1705/ozon-test/internal/mappers"
+	"github.com/Govorov1705/ozon-test/internal/models"
+	"github.com/Govorov1705/ozon-test/internal/pagination"
+	"github.com/google/uuid"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+type postActivitySubscription = broadcasters.Subscription[*dtos.PostActivityEvent]
+
+// streamCommentsAdded сначала досылает из хранилища комментарии, добавленные
+// после since, а затем переключается на события из подписки sub. События,
+// пришедшие во время догрузки, накапливаются в очереди sub; уже отправленные
+// из хранилища комментарии и комментарии не новее since повторно не отправляются.
+// since должен быть уже проверен вызывающей стороной
+func (r *Resolver) streamCommentsAdded(
+	ctx context.Context,
+	postID uuid.UUID,
+	since *string,
+	sub *postActivitySubscription,
+	out chan<- *model.Comment,
+) {
+	defer close(out)
+	defer r.PostActivityBroadcaster.Unsubscribe(postID, sub)
+
+	// replayed нужен, только пока из подписки приходят события, накопленные
+	// во время догрузки. Он сбрасывается, как только приходит комментарий
+	// новее последнего догруженного (lastReplayed)
+	var (
+		replayed     map[uuid.UUID]struct{}
+		lastReplayed *pagination.Cursor
+	)
+
+	var sinceCursor *pagination.Cursor
+	if since != nil {
+		sinceCursor, _ = pagination.DecodeCursor(*since)
+
+		cursor := *since
+		for {
+			comments, err := r.CommentsService.GetCommentsSince(ctx, postID, cursor, pagination.MaxPageSize)
+			if err != nil {
+				addSubscriptionError(ctx, err)
+				return
+			}
+
+			for _, comment := range comments {
+				if replayed == nil {
+					replayed = make(map[uuid.UUID]struct{})
+				}
+				replayed[comment.ID] = struct{}{}
+				lastReplayed = &pagination.Cursor{CreatedAt: comment.CreatedAt, ID: comment.ID}
+				if !send(ctx, out, mappers.ModelCommentToGQL(comment)) {
+					return
+				}
+			}
+
+			if int32(len(comments)) < pagination.MaxPageSize {
+				break
+			}
+			last := comments[len(comments)-1]
+			cursor = pagination.EncodeCursor(last.CreatedAt, last.ID)
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-sub.C:
+			if !ok {
+				addSubscriptionError(ctx, sub.Err())
+				return
+			}
+			if event.Type != models.EventCommentAdded {
+				continue
+			}
+			comment := event.Comment
+			if replayed != nil {
+				if _, ok := replayed[comment.ID]; ok {
+					continue
+				}
+				if lastReplayed.Compare(comment.CreatedAt, comment.ID) > 0 {
+					replayed = nil
+				}
+			}
+			if sinceCursor != nil && sinceCursor.Compare(comment.CreatedAt, comment.ID) <= 0 {
+				continue
+			}
+			if !send(ctx, out, mappers.ModelCommentToGQL(comment)) {
+				return
+			}
+		}
+	}
+}
+
+func (r *Resolver) streamPostActivity(
+	ctx context.Context,
+	postID uuid.UUID,
+	sub *postActivitySubscription,
+	out chan<- model.PostActivityEvent,
+) {
+	defer close(out)
+	defer r.PostActivityBroadcaster.Unsubscribe(postID, sub)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-sub.C:
+			if !ok {
+				addSubscriptionError(ctx, sub.Err())
+				return
+			}
+			if !send(ctx, out, mappers.DTOPostActivityEventToGQL(event)) {
+				return
+			}
+		}
+	}
+}
+
+// Ошибка будет отправлена клиенту после закрытия канала подписки
+func addSubscriptionError(ctx context.Context, err error) {
+	transport.AddSubscriptionError(ctx, &gqlerror.Error{
+		Message: err.Error(),
+		Path:    graphql.GetPath(ctx),
+	})
+}
+
+func send[T any](ctx context.Context, out chan<- T, value T) bool {
+	select {
+	case out <- value:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,135 @@
+package broadcasters
+
+import (
+	"sync"
+
+	"github.com/Govorov1705/ozon-test/internal/errs"
+	"github.com/google/uuid"
+)
+
+// Broadcaster рассылает события типа T подписчикам темы (например, поста)
+type Broadcaster[T any] interface {
+	Subscribe(topic uuid.UUID) *Subscription[T]
+	Unsubscribe(topic uuid.UUID, sub *Subscription[T])
+	// Publish повторно с тем же eventID не рассылает событие второй раз.
+	// Ошибка означает, что событие не отправлено и публикацию нужно повторить
+	Publish(eventID, topic uuid.UUID, event T) error
+}
+
+// OverflowPolicy определяет, что делать, когда очередь подписчика заполнена
+type OverflowPolicy string
+
+const (
+	// OverflowDisconnect отключает подписчика с ошибкой errs.ErrSubscriberTooSlow
+	OverflowDisconnect OverflowPolicy = "disconnect"
+	// OverflowDropOldest вытесняет из очереди самое старое событие
+	OverflowDropOldest OverflowPolicy = "drop_oldest"
+)
+
+// Сколько последних ID событий помнит broadcaster для отсева дубликатов
+const dedupWindow = 4096
+
+// Subscription - ограниченная очередь событий одного подписчика.
+// При отключении из-за переполнения канал C закрывается, а Err возвращает причину
+type Subscription[T any] struct {
+	C   <-chan T
+	ch  chan T
+	err error
+}
+
+// Err можно вызывать только после закрытия C
+func (s *Subscription[T]) Err() error {
+	return s.err
+}
+
+// InMemoryBroadcaster рассылает события только подписчикам текущего процесса
+type InMemoryBroadcaster[T any] struct {
+	mu          sync.Mutex
+	subscribers map[uuid.UUID][]*Subscription[T]
+	bufferSize  int
+	policy      OverflowPolicy
+	seen        map[uuid.UUID]struct{}
+	seenOrder   []uuid.UUID
+}
+
+func NewBroadcaster[T any](bufferSize int, policy OverflowPolicy) Broadcaster[T] {
+	return &InMemoryBroadcaster[T]{
+		subscribers: make(map[uuid.UUID][]*Subscription[T]),
+		bufferSize:  bufferSize,
+		policy:      policy,
+		seen:        make(map[uuid.UUID]struct{}),
+	}
+}
+
+func (b *InMemoryBroadcaster[T]) Subscribe(topic uuid.UUID) *Subscription[T] {
+	ch := make(chan T, b.bufferSize)
+	sub := &Subscription[T]{C: ch, ch: ch}
+
+	b.mu.Lock()
+	b.subscribers[topic] = append(b.subscribers[topic], sub)
+	b.mu.Unlock()
+
+	return sub
+}
+
+func (b *InMemoryBroadcaster[T]) Unsubscribe(topic uuid.UUID, sub *Subscription[T]) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.remove(topic, sub)
+}
+
+func (b *InMemoryBroadcaster[T]) Publish(eventID, topic uuid.UUID, event T) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.seen[eventID]; ok {
+		return nil
+	}
+	b.seen[eventID] = struct{}{}
+	b.seenOrder = append(b.seenOrder, eventID)
+	if len(b.seenOrder) > dedupWindow {
+		delete(b.seen, b.seenOrder[0])
+		b.seenOrder = b.seenOrder[1:]
+	}
+
+	// Копия нужна, потому что remove меняет срез подписчиков
+	subs := append([]*Subscription[T](nil), b.subscribers[topic]...)
+	for _, sub := range subs {
+		select {
+		case sub.ch <- event:
+			continue
+		default:
+		}
+
+		switch b.policy {
+		case OverflowDropOldest:
+			// Отправляет в канал только Publish под блокировкой,
+			// поэтому после вытеснения место в очереди гарантированно есть
+			select {
+			case <-sub.ch:
+			default:
+			}
+			sub.ch <- event
+		default:
+			sub.err = errs.ErrSubscriberTooSlow
+			close(sub.ch)
+			b.remove(topic, sub)
+		}
+	}
+
+	return nil
+}
+
+func (b *InMemoryBroadcaster[T]) remove(topic uuid.UUID, sub *Subscription[T]) {
+	subs := b.subscribers[topic]
+	for i, s := range subs {
+		if s == sub {
+			b.subscribers[topic] = append(subs[:i], subs[i+1:]...)
+			break
+		}
+	}
+	if len(b.subscribers[topic]) == 0 {
+		delete(b.subscribers, topic)
+	}
+}
@@ -1,6 +1,55 @@
 package dtos
 
+import (
+	"unicode"
+
+	"github.com/go-playground/validator/v10"
+)
+
 type AuthRequest struct {
 	Username string `validate:"min=6,max=100"`
 	Password string `validate:"min=6"`
 }
+
+type RegisterRequest struct {
+	Username string `validate:"min=6,max=100"`
+	Password string `validate:"password"`
+}
+
+type LoginRequest struct {
+	Username string `validate:"min=6,max=100"`
+	Password string `validate:"required,max=72"`
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `validate:"required"`
+}
+
+type TokenPair struct {
+	AccessToken  string
+	RefreshToken string
+}
+
+// ValidatePassword проверяет надежность пароля: от 8 до 72 байт (ограничение bcrypt),
+// хотя бы одна строчная буква, одна заглавная и одна цифра
+func ValidatePassword(fl validator.FieldLevel) bool {
+	password := fl.Field().String()
+
+	if len(password) < 8 || len(password) > 72 {
+		return false
+	}
+
+	var hasLower, hasUpper, hasDigit bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		}
+	}
+
+	return hasLower && hasUpper && hasDigit
+}
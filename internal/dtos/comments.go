@@ -5,9 +5,29 @@ import (
 	"github.com/google/uuid"
 )
 
+// RemainingDepth - на сколько уровней ниже этого комментария еще можно загружать ответы
 type CommentWithReplies struct {
 	models.Comment
-	Replies []*CommentWithReplies
+	ReplyCount     int32
+	RemainingDepth int32
+	Replies        CommentsConnection
+}
+
+type CommentEdge struct {
+	Cursor string
+	Node   *CommentWithReplies
+}
+
+type CommentsConnection struct {
+	Edges    []*CommentEdge
+	PageInfo PageInfo
+}
+
+type GetRepliesRequest struct {
+	CommentID uuid.UUID `validate:"required"`
+	First     *int32    `validate:"omitempty,gt=0,lte=100"`
+	After     *string
+	MaxDepth  *int32 `validate:"omitempty,gte=1,lte=10"`
 }
 
 type CreateCommentRequest struct {
@@ -16,3 +36,21 @@ type CreateCommentRequest struct {
 	ReplyTo *uuid.UUID
 	Content string `validate:"required,max=2000"`
 }
+
+type EditCommentRequest struct {
+	CommentID uuid.UUID `validate:"required"`
+	UserID    uuid.UUID `validate:"required"`
+	Content   string    `validate:"required,max=2000"`
+}
+
+// Value: 1 - голос "за", -1 - "против", 0 - снять голос
+type VoteCommentRequest struct {
+	CommentID uuid.UUID `validate:"required"`
+	UserID    uuid.UUID `validate:"required"`
+	Value     int8      `validate:"oneof=-1 0 1"`
+}
+
+type DeleteCommentRequest struct {
+	CommentID uuid.UUID `validate:"required"`
+	UserID    uuid.UUID `validate:"required"`
+}
@@ -0,0 +1,37 @@
+package dtos
+
+import (
+	"regexp"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+)
+
+var slugRe = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+type CreateCommunityRequest struct {
+	OwnerID            uuid.UUID `validate:"required"`
+	Name               string    `validate:"required,max=100"`
+	Slug               string    `validate:"slug"`
+	Description        string    `validate:"max=1000"`
+	AreCommentsAllowed *bool
+}
+
+// UserID - владелец сообщества, MemberID - участник, которому меняют роль
+type CommunityModeratorRequest struct {
+	CommunityID uuid.UUID `validate:"required"`
+	UserID      uuid.UUID `validate:"required"`
+	MemberID    uuid.UUID `validate:"required"`
+}
+
+// ValidateSlug проверяет адрес сообщества: от 3 до 32 строчных латинских букв
+// и цифр, которые можно разделять одиночными дефисами
+func ValidateSlug(fl validator.FieldLevel) bool {
+	slug := fl.Field().String()
+
+	if len(slug) < 3 || len(slug) > 32 {
+		return false
+	}
+
+	return slugRe.MatchString(slug)
+}
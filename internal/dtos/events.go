@@ -0,0 +1,22 @@
+package dtos
+
+import (
+	"github.com/Govorov1705/ozon-test/internal/models"
+	"github.com/google/uuid"
+)
+
+// PostActivityEvent - событие в обсуждении поста, Type - один из models.Event*.
+// Комментарий не сериализуется, чтобы событие оставалось компактным:
+// получатель загружает его по CommentID. В событии о реакции TargetID -
+// ID поста или комментария, а Count - число реакций Emoji после изменения
+type PostActivityEvent struct {
+	Type               string          `json:"type"`
+	PostID             uuid.UUID       `json:"postId"`
+	CommentID          uuid.UUID       `json:"commentId"`
+	TargetID           uuid.UUID       `json:"targetId"`
+	Removed            bool            `json:"removed,omitempty"`
+	AreCommentsAllowed bool            `json:"areCommentsAllowed,omitempty"`
+	Emoji              string          `json:"emoji,omitempty"`
+	Count              int32           `json:"count,omitempty"`
+	Comment            *models.Comment `json:"-"`
+}
@@ -2,12 +2,23 @@ package dtos
 
 import (
 	"github.com/Govorov1705/ozon-test/internal/models"
+	"github.com/Govorov1705/ozon-test/internal/pagination"
 	"github.com/google/uuid"
 )
 
 type PostWithComments struct {
 	Post     *models.Post
-	Comments []*CommentWithReplies
+	Comments CommentsConnection
+}
+
+type PostEdge struct {
+	Cursor string
+	Node   *models.Post
+}
+
+type PostsConnection struct {
+	Edges    []*PostEdge
+	PageInfo PageInfo
 }
 
 type CreatePostRequest struct {
@@ -15,10 +26,37 @@ type CreatePostRequest struct {
 	Title              string    `validate:"required,max=100"`
 	Content            string    `validate:"required,max=2000"`
 	AreCommentsAllowed *bool
+	Tags               []string
+	CommunityID        *uuid.UUID
+}
+
+type GetPostsRequest struct {
+	First       *int32 `validate:"omitempty,gt=0,lte=100"`
+	After       *string
+	Last        *int32 `validate:"omitempty,gt=0,lte=100"`
+	Before      *string
+	Tag         *string
+	CommunityID *uuid.UUID
+}
+
+type GetTagsRequest struct {
+	Prefix string `validate:"max=32"`
+	First  *int32 `validate:"omitempty,gt=0,lte=100"`
 }
 
 type GetPostWithCommentsRequest struct {
-	PostID uuid.UUID `validate:"required"`
-	Limit  *int32    `validate:"gt=0"`
-	Offset *int32    `validate:"gte=0"`
+	PostID   uuid.UUID `validate:"required"`
+	First    *int32    `validate:"omitempty,gt=0,lte=100"`
+	After    *string
+	MaxDepth *int32 `validate:"omitempty,gte=0,lte=10"`
+	Sort     pagination.Sort
+}
+
+type UpdatePostRequest struct {
+	PostID  uuid.UUID `validate:"required"`
+	UserID  uuid.UUID `validate:"required"`
+	Title   *string   `validate:"omitempty,min=1,max=100"`
+	Content *string   `validate:"omitempty,min=1,max=2000"`
+	// nil оставляет теги как есть, пустой список снимает все
+	Tags []string
 }
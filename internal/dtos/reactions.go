@@ -0,0 +1,10 @@
+package dtos
+
+import "github.com/google/uuid"
+
+// TargetID - ID поста или комментария
+type ReactionRequest struct {
+	TargetID uuid.UUID `validate:"required"`
+	UserID   uuid.UUID `validate:"required"`
+	Emoji    string    `validate:"required,max=32"`
+}
@@ -0,0 +1,28 @@
+package dtos
+
+import "github.com/Govorov1705/ozon-test/internal/models"
+
+type SearchType string
+
+const (
+	SearchPosts    SearchType = "POST"
+	SearchComments SearchType = "COMMENT"
+	SearchAll      SearchType = "ALL"
+)
+
+type SearchRequest struct {
+	Query string     `validate:"required,max=200"`
+	Type  SearchType `validate:"omitempty,oneof=POST COMMENT ALL"`
+	First *int32     `validate:"omitempty,gt=0,lte=100"`
+	After *string
+}
+
+type SearchEdge struct {
+	Cursor string
+	Node   *models.SearchHit
+}
+
+type SearchConnection struct {
+	Edges    []*SearchEdge
+	PageInfo PageInfo
+}
@@ -11,4 +11,17 @@ var (
 	ErrCommentsNotAllowed   = errors.New("comments are not allowed on this post")
 	ErrPostAndReplyMismatch = errors.New("reply id's post id doesn't match provided post id")
 	ErrUnauthorized         = errors.New("you are not authorized to do that")
+	ErrInvalidCursor        = errors.New("invalid cursor")
+	ErrInvalidPagination    = errors.New("invalid pagination arguments")
+	ErrLegacyAuthDisabled   = errors.New("auth mutation is disabled, use register or login")
+	ErrInvalidRefreshToken  = errors.New("invalid refresh token")
+	ErrCommentDeleted       = errors.New("comment is deleted")
+	ErrPostDeleted          = errors.New("post is deleted")
+	ErrSubscriberTooSlow    = errors.New("subscriber is too slow, events were lost")
+	ErrReactionNotAllowed   = errors.New("reaction is not allowed")
+	ErrInvalidSearchQuery   = errors.New("search query must contain at least one word")
+	ErrInvalidTag           = errors.New("tag must be 1-32 letters, digits or -_+. characters")
+	ErrTooManyTags          = errors.New("too many tags")
+	ErrNotCommunityMember   = errors.New("you are not a member of this community")
+	ErrOwnerCannotLeave     = errors.New("community owner cannot leave it")
 )
@@ -0,0 +1,3 @@
+package jwt
+
+var KeyFunc = (*KeySet).keyFunc
@@ -9,25 +9,72 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 )
 
-func CreateJWT(userID string) (string, error) {
+var keys *KeySet
+
+// InitKeys загружает асимметричные ключи, если задан JWT_KEYS_DIR. Без него
+// токены подписываются HMAC-секретом SECRET_KEY, как раньше. Если задан и
+// SECRET_KEY, выданные им токены принимаются еще ACCESS_TOKEN_TTL после запуска
+func InitKeys() error {
+	if config.Cfg.JWTKeysDir == "" {
+		return nil
+	}
+
+	ks, err := LoadKeySet(config.Cfg.JWTKeysDir, config.Cfg.JWTSigningKeyID)
+	if err != nil {
+		return err
+	}
+
+	if config.Cfg.SecretKey != "" {
+		ks.AcceptLegacySecret([]byte(config.Cfg.SecretKey), time.Now().Add(config.Cfg.AccessTokenTTL))
+	}
+
+	keys = ks
+	return nil
+}
+
+func GetJWKS() JWKS {
+	if keys == nil {
+		return JWKS{Keys: []JWK{}}
+	}
+
+	return keys.JWKS()
+}
+
+// CreateJWT выдает короткоживущий access-токен. В jti записывается ID
+// refresh-токена, выданного вместе с ним: по нему проверяется, не отозвана ли сессия
+func CreateJWT(userID, tokenID string) (string, error) {
 	now := time.Now().UTC()
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+	claims := jwt.MapClaims{
 		"sub": userID,
+		"jti": tokenID,
 		"iat": now.Unix(),
-		"exp": now.Add(10 * 24 * time.Hour).Unix(),
-	})
+		"exp": now.Add(config.Cfg.AccessTokenTTL).Unix(),
+	}
+
+	if keys == nil {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+		return token.SignedString([]byte(config.Cfg.SecretKey))
+	}
+
+	token := jwt.NewWithClaims(keys.signing.Method, claims)
+	token.Header["kid"] = keys.signing.ID
 
-	return token.SignedString([]byte(config.Cfg.SecretKey))
+	return token.SignedString(keys.signing.Private)
 }
 
 func ValidateJWT(tokenString string) (jwt.MapClaims, error) {
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (any, error) {
+	keyFunc := func(token *jwt.Token) (any, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
 
 		return []byte(config.Cfg.SecretKey), nil
-	})
+	}
+	if keys != nil {
+		keyFunc = keys.keyFunc
+	}
+
+	token, err := jwt.Parse(tokenString, keyFunc)
 
 	if err != nil {
 		return nil, err
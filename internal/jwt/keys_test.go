@@ -0,0 +1,285 @@
+package jwt_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Govorov1705/ozon-test/internal/jwt"
+	gojwt "github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testKeys struct {
+	dir     string
+	rsa     *rsa.PrivateKey
+	ed      ed25519.PrivateKey
+	retired *rsa.PrivateKey
+}
+
+// writeTestKeys кладет в каталог текущий RSA-ключ (PKCS#1), Ed25519-ключ
+// (PKCS#8) и выведенный из ротации RSA-ключ, от которого осталась только
+// публичная часть
+func writeTestKeys(t *testing.T) *testKeys {
+	t.Helper()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	retired, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+
+	writePEM(t, dir, "rsa-1", "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rsaKey))
+
+	edDER, err := x509.MarshalPKCS8PrivateKey(edKey)
+	require.NoError(t, err)
+	writePEM(t, dir, "ed-1", "PRIVATE KEY", edDER)
+
+	retiredDER, err := x509.MarshalPKIXPublicKey(&retired.PublicKey)
+	require.NoError(t, err)
+	writePEM(t, dir, "rsa-0", "PUBLIC KEY", retiredDER)
+
+	return &testKeys{dir: dir, rsa: rsaKey, ed: edKey, retired: retired}
+}
+
+func writePEM(t *testing.T, dir, kid, blockType string, der []byte) {
+	t.Helper()
+
+	data := pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+	require.NoError(t, os.WriteFile(filepath.Join(dir, kid+".pem"), data, 0o600))
+}
+
+func sign(t *testing.T, method gojwt.SigningMethod, kid string, key any) string {
+	t.Helper()
+
+	token := gojwt.NewWithClaims(method, gojwt.MapClaims{
+		"sub": "user",
+		"exp": time.Now().Add(time.Minute).Unix(),
+	})
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+
+	return signed
+}
+
+func TestLoadKeySet(t *testing.T) {
+	keys := writeTestKeys(t)
+
+	type testCase struct {
+		name        string
+		signingKID  string
+		setup       func(dir string)
+		expectError bool
+	}
+
+	testCases := []testCase{
+		{
+			name:       "OK (RS256 signing key)",
+			signingKID: "rsa-1",
+		},
+		{
+			name:       "OK (Ed25519 signing key)",
+			signingKID: "ed-1",
+		},
+		{
+			name:        "signing key not found",
+			signingKID:  "rsa-2",
+			expectError: true,
+		},
+		{
+			name:        "signing key has no private part",
+			signingKID:  "rsa-0",
+			expectError: true,
+		},
+		{
+			name:       "broken PEM file",
+			signingKID: "rsa-1",
+			setup: func(dir string) {
+				require.NoError(t, os.WriteFile(filepath.Join(dir, "broken.pem"), []byte("not a key"), 0o600))
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := keys.dir
+			if tc.setup != nil {
+				dir = t.TempDir()
+				for _, kid := range []string{"rsa-1", "ed-1", "rsa-0"} {
+					data, err := os.ReadFile(filepath.Join(keys.dir, kid+".pem"))
+					require.NoError(t, err)
+					require.NoError(t, os.WriteFile(filepath.Join(dir, kid+".pem"), data, 0o600))
+				}
+				tc.setup(dir)
+			}
+
+			ks, err := jwt.LoadKeySet(dir, tc.signingKID)
+
+			if tc.expectError {
+				assert.Error(t, err)
+				assert.Nil(t, ks)
+			} else {
+				assert.NoError(t, err)
+				assert.Len(t, ks.JWKS().Keys, 3)
+			}
+		})
+	}
+}
+
+func TestKeySet_KeyFunc(t *testing.T) {
+	keys := writeTestKeys(t)
+	secret := []byte("legacy secret")
+
+	ks, err := jwt.LoadKeySet(keys.dir, "rsa-1")
+	require.NoError(t, err)
+	ks.AcceptLegacySecret(secret, time.Now().Add(time.Minute))
+
+	expired, err := jwt.LoadKeySet(keys.dir, "rsa-1")
+	require.NoError(t, err)
+	expired.AcceptLegacySecret(secret, time.Now().Add(-time.Minute))
+
+	rsaPublicDER, err := x509.MarshalPKIXPublicKey(&keys.rsa.PublicKey)
+	require.NoError(t, err)
+	rsaPublicPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: rsaPublicDER})
+
+	type testCase struct {
+		name        string
+		ks          *jwt.KeySet
+		token       string
+		expectError bool
+	}
+
+	testCases := []testCase{
+		{
+			name:  "OK (RS256)",
+			ks:    ks,
+			token: sign(t, gojwt.SigningMethodRS256, "rsa-1", keys.rsa),
+		},
+		{
+			name:  "OK (EdDSA)",
+			ks:    ks,
+			token: sign(t, gojwt.SigningMethodEdDSA, "ed-1", keys.ed),
+		},
+		{
+			name:  "OK (rotated-out key)",
+			ks:    ks,
+			token: sign(t, gojwt.SigningMethodRS256, "rsa-0", keys.retired),
+		},
+		{
+			name:  "OK (legacy HMAC secret)",
+			ks:    ks,
+			token: sign(t, gojwt.SigningMethodHS256, "", secret),
+		},
+		{
+			name:        "unknown kid",
+			ks:          ks,
+			token:       sign(t, gojwt.SigningMethodRS256, "rsa-2", keys.rsa),
+			expectError: true,
+		},
+		{
+			name:        "signed by another key under known kid",
+			ks:          ks,
+			token:       sign(t, gojwt.SigningMethodRS256, "rsa-1", keys.retired),
+			expectError: true,
+		},
+		{
+			name:        "EdDSA token with RSA kid",
+			ks:          ks,
+			token:       sign(t, gojwt.SigningMethodEdDSA, "rsa-1", keys.ed),
+			expectError: true,
+		},
+		{
+			name:        "HS256 token with RSA kid signed by public key",
+			ks:          ks,
+			token:       sign(t, gojwt.SigningMethodHS256, "rsa-1", rsaPublicPEM),
+			expectError: true,
+		},
+		{
+			name:        "HS256 token with wrong secret",
+			ks:          ks,
+			token:       sign(t, gojwt.SigningMethodHS256, "", []byte("other secret")),
+			expectError: true,
+		},
+		{
+			name:        "HS384 token without kid",
+			ks:          ks,
+			token:       sign(t, gojwt.SigningMethodHS384, "", secret),
+			expectError: true,
+		},
+		{
+			name:        "legacy HMAC secret after cutover window",
+			ks:          expired,
+			token:       sign(t, gojwt.SigningMethodHS256, "", secret),
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			token, err := gojwt.Parse(tc.token, func(token *gojwt.Token) (any, error) {
+				return jwt.KeyFunc(tc.ks, token)
+			})
+
+			if tc.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.True(t, token.Valid)
+			}
+		})
+	}
+}
+
+func TestKeySet_JWKS(t *testing.T) {
+	keys := writeTestKeys(t)
+
+	ks, err := jwt.LoadKeySet(keys.dir, "rsa-1")
+	require.NoError(t, err)
+
+	jwks := ks.JWKS()
+
+	// Ключи отсортированы по kid, приватные части не публикуются
+	expected := []jwt.JWK{
+		{
+			Kty: "OKP",
+			Kid: "ed-1",
+			Use: "sig",
+			Alg: "EdDSA",
+			Crv: "Ed25519",
+			X:   base64.RawURLEncoding.EncodeToString(keys.ed.Public().(ed25519.PublicKey)),
+		},
+		{
+			Kty: "RSA",
+			Kid: "rsa-0",
+			Use: "sig",
+			Alg: "RS256",
+			N:   base64.RawURLEncoding.EncodeToString(keys.retired.N.Bytes()),
+			E:   "AQAB",
+		},
+		{
+			Kty: "RSA",
+			Kid: "rsa-1",
+			Use: "sig",
+			Alg: "RS256",
+			N:   base64.RawURLEncoding.EncodeToString(keys.rsa.N.Bytes()),
+			E:   "AQAB",
+		},
+	}
+	assert.Equal(t, expected, jwks.Keys)
+}
@@ -0,0 +1,341 @@
+package loaders
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Govorov1705/ozon-test/internal/errs"
+	"github.com/Govorov1705/ozon-test/internal/middleware"
+	"github.com/Govorov1705/ozon-test/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/graph-gophers/dataloader/v7"
+)
+
+type contextKey string
+
+const loadersKey contextKey = "loaders"
+
+type UsersGetter interface {
+	GetByIDs(ctx context.Context, IDs []uuid.UUID) ([]*models.User, error)
+}
+
+type UserCounter interface {
+	CountByUserIDs(ctx context.Context, userIDs []uuid.UUID) (map[uuid.UUID]int32, error)
+}
+
+type ReactionsGetter interface {
+	GetReactions(ctx context.Context, IDs []uuid.UUID, viewerID *uuid.UUID) (map[uuid.UUID][]*models.ReactionCount, error)
+}
+
+type TagsGetter interface {
+	GetTags(ctx context.Context, postIDs []uuid.UUID) (map[uuid.UUID][]string, error)
+}
+
+// Content - посты или комментарии
+type Content interface {
+	UserCounter
+	ReactionsGetter
+}
+
+type RevisionsGetter interface {
+	GetRevisions(ctx context.Context, commentIDs []uuid.UUID, viewerID *uuid.UUID) (map[uuid.UUID][]*models.CommentRevision, error)
+}
+
+type Posts interface {
+	Content
+	TagsGetter
+}
+
+type Comments interface {
+	Content
+	RevisionsGetter
+}
+
+type Communities interface {
+	GetByIDs(ctx context.Context, IDs []uuid.UUID) ([]*models.Community, error)
+	CountMembers(ctx context.Context, communityIDs []uuid.UUID) (map[uuid.UUID]int32, error)
+}
+
+// Loaders создаются на каждый запрос, чтобы кеш не переживал запрос
+// и не отдавал устаревшие данные
+type Loaders struct {
+	UserByID             *dataloader.Loader[uuid.UUID, *models.User]
+	PostCountByUserID    *dataloader.Loader[uuid.UUID, int32]
+	CommentCountByUserID *dataloader.Loader[uuid.UUID, int32]
+	// Реакции и правки не кешируются: подписка живет в контексте одного
+	// запроса, а данные в ее событиях должны быть актуальными
+	ReactionsByPostID        *dataloader.Loader[uuid.UUID, []*models.ReactionCount]
+	ReactionsByCommentID     *dataloader.Loader[uuid.UUID, []*models.ReactionCount]
+	RevisionsByCommentID     *dataloader.Loader[uuid.UUID, []*models.CommentRevision]
+	TagsByPostID             *dataloader.Loader[uuid.UUID, []string]
+	CommunityByID            *dataloader.Loader[uuid.UUID, *models.Community]
+	MemberCountByCommunityID *dataloader.Loader[uuid.UUID, int32]
+}
+
+func NewLoaders(users UsersGetter, posts Posts, comments Comments, communities Communities) *Loaders {
+	return &Loaders{
+		UserByID: dataloader.NewBatchedLoader(
+			usersBatchFunc(users),
+			dataloader.WithWait[uuid.UUID, *models.User](time.Millisecond),
+		),
+		PostCountByUserID: dataloader.NewBatchedLoader(
+			countsBatchFunc(posts),
+			dataloader.WithWait[uuid.UUID, int32](time.Millisecond),
+		),
+		CommentCountByUserID: dataloader.NewBatchedLoader(
+			countsBatchFunc(comments),
+			dataloader.WithWait[uuid.UUID, int32](time.Millisecond),
+		),
+		ReactionsByPostID: dataloader.NewBatchedLoader(
+			reactionsBatchFunc(posts),
+			dataloader.WithWait[uuid.UUID, []*models.ReactionCount](time.Millisecond),
+			dataloader.WithCache[uuid.UUID, []*models.ReactionCount](&dataloader.NoCache[uuid.UUID, []*models.ReactionCount]{}),
+		),
+		ReactionsByCommentID: dataloader.NewBatchedLoader(
+			reactionsBatchFunc(comments),
+			dataloader.WithWait[uuid.UUID, []*models.ReactionCount](time.Millisecond),
+			dataloader.WithCache[uuid.UUID, []*models.ReactionCount](&dataloader.NoCache[uuid.UUID, []*models.ReactionCount]{}),
+		),
+		RevisionsByCommentID: dataloader.NewBatchedLoader(
+			revisionsBatchFunc(comments),
+			dataloader.WithWait[uuid.UUID, []*models.CommentRevision](time.Millisecond),
+			dataloader.WithCache[uuid.UUID, []*models.CommentRevision](&dataloader.NoCache[uuid.UUID, []*models.CommentRevision]{}),
+		),
+		TagsByPostID: dataloader.NewBatchedLoader(
+			tagsBatchFunc(posts),
+			dataloader.WithWait[uuid.UUID, []string](time.Millisecond),
+		),
+		CommunityByID: dataloader.NewBatchedLoader(
+			communitiesBatchFunc(communities),
+			dataloader.WithWait[uuid.UUID, *models.Community](time.Millisecond),
+		),
+		MemberCountByCommunityID: dataloader.NewBatchedLoader(
+			memberCountsBatchFunc(communities),
+			dataloader.WithWait[uuid.UUID, int32](time.Millisecond),
+		),
+	}
+}
+
+func Middleware(users UsersGetter, posts Posts, comments Comments, communities Communities) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := context.WithValue(c.Request.Context(), loadersKey, NewLoaders(users, posts, comments, communities))
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+func For(ctx context.Context) *Loaders {
+	return ctx.Value(loadersKey).(*Loaders)
+}
+
+func GetUser(ctx context.Context, userID uuid.UUID) (*models.User, error) {
+	return For(ctx).UserByID.Load(ctx, userID)()
+}
+
+func GetPostCount(ctx context.Context, userID uuid.UUID) (int32, error) {
+	return For(ctx).PostCountByUserID.Load(ctx, userID)()
+}
+
+func GetCommentCount(ctx context.Context, userID uuid.UUID) (int32, error) {
+	return For(ctx).CommentCountByUserID.Load(ctx, userID)()
+}
+
+func GetPostReactions(ctx context.Context, postID uuid.UUID) ([]*models.ReactionCount, error) {
+	return For(ctx).ReactionsByPostID.Load(ctx, postID)()
+}
+
+func GetCommentReactions(ctx context.Context, commentID uuid.UUID) ([]*models.ReactionCount, error) {
+	return For(ctx).ReactionsByCommentID.Load(ctx, commentID)()
+}
+
+func GetCommentRevisions(ctx context.Context, commentID uuid.UUID) ([]*models.CommentRevision, error) {
+	return For(ctx).RevisionsByCommentID.Load(ctx, commentID)()
+}
+
+func GetPostTags(ctx context.Context, postID uuid.UUID) ([]string, error) {
+	return For(ctx).TagsByPostID.Load(ctx, postID)()
+}
+
+func GetCommunity(ctx context.Context, communityID uuid.UUID) (*models.Community, error) {
+	return For(ctx).CommunityByID.Load(ctx, communityID)()
+}
+
+func GetMemberCount(ctx context.Context, communityID uuid.UUID) (int32, error) {
+	return For(ctx).MemberCountByCommunityID.Load(ctx, communityID)()
+}
+
+// Результаты возвращаются в порядке ключей, как того требует dataloader
+func usersBatchFunc(users UsersGetter) dataloader.BatchFunc[uuid.UUID, *models.User] {
+	return func(ctx context.Context, IDs []uuid.UUID) []*dataloader.Result[*models.User] {
+		results := make([]*dataloader.Result[*models.User], len(IDs))
+
+		found, err := users.GetByIDs(ctx, IDs)
+		if err != nil {
+			for i := range results {
+				results[i] = &dataloader.Result[*models.User]{Error: err}
+			}
+			return results
+		}
+
+		byID := make(map[uuid.UUID]*models.User, len(found))
+		for _, user := range found {
+			byID[user.ID] = user
+		}
+
+		for i, ID := range IDs {
+			user, ok := byID[ID]
+			if !ok {
+				results[i] = &dataloader.Result[*models.User]{Error: fmt.Errorf("user %w", errs.ErrNotFound)}
+				continue
+			}
+			results[i] = &dataloader.Result[*models.User]{Data: user}
+		}
+
+		return results
+	}
+}
+
+func countsBatchFunc(counter UserCounter) dataloader.BatchFunc[uuid.UUID, int32] {
+	return func(ctx context.Context, userIDs []uuid.UUID) []*dataloader.Result[int32] {
+		results := make([]*dataloader.Result[int32], len(userIDs))
+
+		counts, err := counter.CountByUserIDs(ctx, userIDs)
+		if err != nil {
+			for i := range results {
+				results[i] = &dataloader.Result[int32]{Error: err}
+			}
+			return results
+		}
+
+		for i, ID := range userIDs {
+			results[i] = &dataloader.Result[int32]{Data: counts[ID]}
+		}
+
+		return results
+	}
+}
+
+// Пакет собирается из запросов одного клиента, поэтому пользователь,
+// для которого заполняется ViewerHasReacted, берется из ctx
+func reactionsBatchFunc(getter ReactionsGetter) dataloader.BatchFunc[uuid.UUID, []*models.ReactionCount] {
+	return func(ctx context.Context, IDs []uuid.UUID) []*dataloader.Result[[]*models.ReactionCount] {
+		results := make([]*dataloader.Result[[]*models.ReactionCount], len(IDs))
+
+		var viewerID *uuid.UUID
+		if userID, ok := middleware.GetUserID(ctx); ok {
+			viewerID = &userID
+		}
+
+		reactions, err := getter.GetReactions(ctx, IDs, viewerID)
+		if err != nil {
+			for i := range results {
+				results[i] = &dataloader.Result[[]*models.ReactionCount]{Error: err}
+			}
+			return results
+		}
+
+		for i, ID := range IDs {
+			results[i] = &dataloader.Result[[]*models.ReactionCount]{Data: reactions[ID]}
+		}
+
+		return results
+	}
+}
+
+// Правки видны не всем, поэтому, как и для реакций, зритель берется из ctx
+func revisionsBatchFunc(getter RevisionsGetter) dataloader.BatchFunc[uuid.UUID, []*models.CommentRevision] {
+	return func(ctx context.Context, commentIDs []uuid.UUID) []*dataloader.Result[[]*models.CommentRevision] {
+		results := make([]*dataloader.Result[[]*models.CommentRevision], len(commentIDs))
+
+		var viewerID *uuid.UUID
+		if userID, ok := middleware.GetUserID(ctx); ok {
+			viewerID = &userID
+		}
+
+		revisions, err := getter.GetRevisions(ctx, commentIDs, viewerID)
+		if err != nil {
+			for i := range results {
+				results[i] = &dataloader.Result[[]*models.CommentRevision]{Error: err}
+			}
+			return results
+		}
+
+		for i, ID := range commentIDs {
+			results[i] = &dataloader.Result[[]*models.CommentRevision]{Data: revisions[ID]}
+		}
+
+		return results
+	}
+}
+
+func tagsBatchFunc(getter TagsGetter) dataloader.BatchFunc[uuid.UUID, []string] {
+	return func(ctx context.Context, postIDs []uuid.UUID) []*dataloader.Result[[]string] {
+		results := make([]*dataloader.Result[[]string], len(postIDs))
+
+		tags, err := getter.GetTags(ctx, postIDs)
+		if err != nil {
+			for i := range results {
+				results[i] = &dataloader.Result[[]string]{Error: err}
+			}
+			return results
+		}
+
+		for i, ID := range postIDs {
+			results[i] = &dataloader.Result[[]string]{Data: tags[ID]}
+		}
+
+		return results
+	}
+}
+
+func communitiesBatchFunc(communities Communities) dataloader.BatchFunc[uuid.UUID, *models.Community] {
+	return func(ctx context.Context, IDs []uuid.UUID) []*dataloader.Result[*models.Community] {
+		results := make([]*dataloader.Result[*models.Community], len(IDs))
+
+		found, err := communities.GetByIDs(ctx, IDs)
+		if err != nil {
+			for i := range results {
+				results[i] = &dataloader.Result[*models.Community]{Error: err}
+			}
+			return results
+		}
+
+		byID := make(map[uuid.UUID]*models.Community, len(found))
+		for _, community := range found {
+			byID[community.ID] = community
+		}
+
+		for i, ID := range IDs {
+			community, ok := byID[ID]
+			if !ok {
+				results[i] = &dataloader.Result[*models.Community]{Error: fmt.Errorf("community %w", errs.ErrNotFound)}
+				continue
+			}
+			results[i] = &dataloader.Result[*models.Community]{Data: community}
+		}
+
+		return results
+	}
+}
+
+func memberCountsBatchFunc(communities Communities) dataloader.BatchFunc[uuid.UUID, int32] {
+	return func(ctx context.Context, communityIDs []uuid.UUID) []*dataloader.Result[int32] {
+		results := make([]*dataloader.Result[int32], len(communityIDs))
+
+		counts, err := communities.CountMembers(ctx, communityIDs)
+		if err != nil {
+			for i := range results {
+				results[i] = &dataloader.Result[int32]{Error: err}
+			}
+			return results
+		}
+
+		for i, ID := range communityIDs {
+			results[i] = &dataloader.Result[int32]{Data: counts[ID]}
+		}
+
+		return results
+	}
+}
@@ -0,0 +1,36 @@
+package mappers
+
+import (
+	"github.com/Govorov1705/ozon-test/graph/model"
+	"github.com/Govorov1705/ozon-test/internal/dtos"
+	"github.com/Govorov1705/ozon-test/internal/models"
+)
+
+func DTOPostActivityEventToGQL(event *dtos.PostActivityEvent) model.PostActivityEvent {
+	switch event.Type {
+	case models.EventCommentAdded:
+		return &model.CommentAdded{Comment: ModelCommentToGQL(event.Comment)}
+	case models.EventCommentUpdated:
+		return &model.CommentUpdated{Comment: ModelCommentToGQL(event.Comment)}
+	case models.EventCommentDeleted:
+		return &model.CommentDeleted{
+			PostID:    event.PostID,
+			CommentID: event.CommentID,
+			Removed:   event.Removed,
+		}
+	case models.EventCommentsToggled:
+		return &model.CommentsToggled{
+			PostID:             event.PostID,
+			AreCommentsAllowed: event.AreCommentsAllowed,
+		}
+	case models.EventReactionChanged:
+		return &model.ReactionChanged{
+			PostID:   event.PostID,
+			TargetID: event.TargetID,
+			Emoji:    event.Emoji,
+			Count:    event.Count,
+		}
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,13 @@
+package mappers
+
+import (
+	"github.com/Govorov1705/ozon-test/graph/model"
+	"github.com/Govorov1705/ozon-test/internal/dtos"
+)
+
+func DTOTokenPairToGQL(tokens *dtos.TokenPair) *model.Jwt {
+	return &model.Jwt{
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+	}
+}
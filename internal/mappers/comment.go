@@ -4,53 +4,109 @@ import (
 	"github.com/Govorov1705/ozon-test/graph/model"
 	"github.com/Govorov1705/ozon-test/internal/dtos"
 	"github.com/Govorov1705/ozon-test/internal/models"
+	"github.com/Govorov1705/ozon-test/internal/pagination"
 	"github.com/google/uuid"
 )
 
+const deletedCommentContent = "[deleted]"
+
 func ModelCommentToGQL(comment *models.Comment) *model.Comment {
 	var replyTo *uuid.UUID
 	if comment.ReplyTo != nil {
 		replyTo = comment.ReplyTo
 	}
 
+	userID, content, isDeleted := commentAuthorAndContent(comment)
+
 	return &model.Comment{
 		ID:        comment.ID,
 		PostID:    comment.PostID,
-		UserID:    comment.UserID,
+		UserID:    userID,
 		RootID:    comment.RootID,
 		ReplyTo:   replyTo,
-		Content:   comment.Content,
+		Content:   content,
 		CreatedAt: comment.CreatedAt,
+		EditedAt:  comment.EditedAt,
+		IsDeleted: isDeleted,
+		Score:     comment.Score,
+		Upvotes:   comment.Upvotes,
+		Downvotes: comment.Downvotes,
+		Cursor:    pagination.EncodeCursor(comment.CreatedAt, comment.ID),
 	}
 }
 
-func DTOCommentsWithRepliesToGQL(cwr []*dtos.CommentWithReplies) []*model.CommentWithReplies {
-	GQLcommentsWithReplies := make([]*model.CommentWithReplies, len(cwr))
+// У удаленного комментария скрываются автор и текст
+func commentAuthorAndContent(comment *models.Comment) (*uuid.UUID, string, bool) {
+	if comment.DeletedAt != nil {
+		return nil, deletedCommentContent, true
+	}
+
+	userID := comment.UserID
+	return &userID, comment.Content, false
+}
 
-	for i, c := range cwr {
-		var replyTo *uuid.UUID
-		if c.ReplyTo != nil {
-			replyTo = c.ReplyTo
+func ModelCommentRevisionsToGQL(revisions []*models.CommentRevision) []*model.CommentRevision {
+	GQLRevisions := make([]*model.CommentRevision, len(revisions))
+
+	for i, r := range revisions {
+		GQLRevisions[i] = &model.CommentRevision{
+			ID:        r.ID,
+			CommentID: r.CommentID,
+			Content:   r.Content,
+			CreatedAt: r.CreatedAt,
 		}
+	}
+
+	return GQLRevisions
+}
 
-		GQLcommentsWithReplies[i] = &model.CommentWithReplies{
-			ID:        c.ID,
-			PostID:    c.PostID,
-			UserID:    c.UserID,
-			RootID:    c.RootID,
-			ReplyTo:   replyTo,
-			Content:   c.Content,
-			CreatedAt: c.CreatedAt,
-			Replies:   DTOCommentsWithRepliesToGQL(c.Replies),
+func DTOCommentWithRepliesToGQL(c *dtos.CommentWithReplies) *model.CommentWithReplies {
+	var replyTo *uuid.UUID
+	if c.ReplyTo != nil {
+		replyTo = c.ReplyTo
+	}
+
+	userID, content, isDeleted := commentAuthorAndContent(&c.Comment)
+
+	return &model.CommentWithReplies{
+		ID:               c.ID,
+		PostID:           c.PostID,
+		UserID:           userID,
+		RootID:           c.RootID,
+		ReplyTo:          replyTo,
+		Content:          content,
+		CreatedAt:        c.CreatedAt,
+		EditedAt:         c.EditedAt,
+		IsDeleted:        isDeleted,
+		Score:            c.Score,
+		Upvotes:          c.Upvotes,
+		Downvotes:        c.Downvotes,
+		ReplyCount:       c.ReplyCount,
+		HasMoreReplies:   c.Replies.PageInfo.HasNextPage,
+		PreloadedReplies: DTOCommentsConnectionToGQL(&c.Replies),
+		RemainingDepth:   c.RemainingDepth,
+	}
+}
+
+func DTOCommentsConnectionToGQL(commentsConnection *dtos.CommentsConnection) *model.CommentConnection {
+	edges := make([]*model.CommentEdge, len(commentsConnection.Edges))
+
+	for i, e := range commentsConnection.Edges {
+		edges[i] = &model.CommentEdge{
+			Cursor: e.Cursor,
+			Node:   DTOCommentWithRepliesToGQL(e.Node),
 		}
 	}
 
-	return GQLcommentsWithReplies
+	return &model.CommentConnection{
+		Edges:    edges,
+		PageInfo: DTOPageInfoToGQL(&commentsConnection.PageInfo),
+	}
 }
 
 func DTOPostWithCommentsToGQL(postWithComments *dtos.PostWithComments) *model.PostWithComments {
 	return &model.PostWithComments{
 		Post:     ModelPostToGQL(postWithComments.Post),
-		Comments: DTOCommentsWithRepliesToGQL(postWithComments.Comments),
+		Comments: DTOCommentsConnectionToGQL(&postWithComments.Comments),
 	}
 }
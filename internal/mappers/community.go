@@ -0,0 +1,36 @@
+package mappers
+
+import (
+	"github.com/Govorov1705/ozon-test/graph/model"
+	"github.com/Govorov1705/ozon-test/internal/models"
+)
+
+func ModelCommunityToGQL(community *models.Community) *model.Community {
+	return &model.Community{
+		ID:                 community.ID,
+		OwnerID:            community.OwnerID,
+		Name:               community.Name,
+		Slug:               community.Slug,
+		Description:        community.Description,
+		AreCommentsAllowed: community.AreCommentsAllowed,
+		CreatedAt:          community.CreatedAt,
+	}
+}
+
+func ModelCommunityMemberToGQL(member *models.CommunityMember) *model.CommunityMember {
+	return &model.CommunityMember{
+		UserID:   member.UserID,
+		Role:     model.CommunityRole(member.Role),
+		JoinedAt: member.JoinedAt,
+	}
+}
+
+func ModelCommunityMembersToGQL(members []*models.CommunityMember) []*model.CommunityMember {
+	GQLMembers := make([]*model.CommunityMember, len(members))
+
+	for i, m := range members {
+		GQLMembers[i] = ModelCommunityMemberToGQL(m)
+	}
+
+	return GQLMembers
+}
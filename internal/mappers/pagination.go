@@ -0,0 +1,15 @@
+package mappers
+
+import (
+	"github.com/Govorov1705/ozon-test/graph/model"
+	"github.com/Govorov1705/ozon-test/internal/dtos"
+)
+
+func DTOPageInfoToGQL(pageInfo *dtos.PageInfo) *model.PageInfo {
+	return &model.PageInfo{
+		HasNextPage:     pageInfo.HasNextPage,
+		HasPreviousPage: pageInfo.HasPreviousPage,
+		StartCursor:     pageInfo.StartCursor,
+		EndCursor:       pageInfo.EndCursor,
+	}
+}
@@ -2,17 +2,37 @@ package mappers
 
 import (
 	"github.com/Govorov1705/ozon-test/graph/model"
+	"github.com/Govorov1705/ozon-test/internal/dtos"
 	"github.com/Govorov1705/ozon-test/internal/models"
 )
 
+const deletedPostTitle = "[removed]"
+
+// У удаленного поста скрываются автор, заголовок и текст
 func ModelPostToGQL(post *models.Post) *model.Post {
+	if post.DeletedAt != nil {
+		return &model.Post{
+			ID:                 post.ID,
+			CommunityID:        post.CommunityID,
+			Title:              deletedPostTitle,
+			AreCommentsAllowed: false,
+			CreatedAt:          post.CreatedAt,
+			UpdatedAt:          post.UpdatedAt,
+			IsDeleted:          true,
+		}
+	}
+
+	userID := post.UserID
+
 	return &model.Post{
 		ID:                 post.ID,
-		UserID:             post.UserID,
+		UserID:             &userID,
+		CommunityID:        post.CommunityID,
 		Title:              post.Title,
 		Content:            post.Content,
 		AreCommentsAllowed: post.AreCommentsAllowed,
 		CreatedAt:          post.CreatedAt,
+		UpdatedAt:          post.UpdatedAt,
 	}
 }
 
@@ -25,3 +45,19 @@ func ModelPostsToGQL(posts []*models.Post) []*model.Post {
 
 	return GQLPosts
 }
+
+func DTOPostsConnectionToGQL(postsConnection *dtos.PostsConnection) *model.PostConnection {
+	edges := make([]*model.PostEdge, len(postsConnection.Edges))
+
+	for i, e := range postsConnection.Edges {
+		edges[i] = &model.PostEdge{
+			Cursor: e.Cursor,
+			Node:   ModelPostToGQL(e.Node),
+		}
+	}
+
+	return &model.PostConnection{
+		Edges:    edges,
+		PageInfo: DTOPageInfoToGQL(&postsConnection.PageInfo),
+	}
+}
@@ -0,0 +1,20 @@
+package mappers
+
+import (
+	"github.com/Govorov1705/ozon-test/graph/model"
+	"github.com/Govorov1705/ozon-test/internal/models"
+)
+
+func ModelReactionsToGQL(reactions []*models.ReactionCount) []*model.Reaction {
+	GQLReactions := make([]*model.Reaction, len(reactions))
+
+	for i, r := range reactions {
+		GQLReactions[i] = &model.Reaction{
+			Emoji:            r.Emoji,
+			Count:            r.Count,
+			ViewerHasReacted: r.ViewerHasReacted,
+		}
+	}
+
+	return GQLReactions
+}
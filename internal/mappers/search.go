@@ -0,0 +1,36 @@
+package mappers
+
+import (
+	"github.com/Govorov1705/ozon-test/graph/model"
+	"github.com/Govorov1705/ozon-test/internal/dtos"
+	"github.com/Govorov1705/ozon-test/internal/models"
+)
+
+func ModelSearchHitToGQL(cursor string, hit *models.SearchHit) *model.SearchEdge {
+	edge := &model.SearchEdge{
+		Cursor:  cursor,
+		Rank:    hit.Rank,
+		Snippet: hit.Snippet,
+	}
+
+	if hit.Post != nil {
+		edge.Node = ModelPostToGQL(hit.Post)
+	} else {
+		edge.Node = ModelCommentToGQL(hit.Comment)
+	}
+
+	return edge
+}
+
+func DTOSearchConnectionToGQL(searchConnection *dtos.SearchConnection) *model.SearchConnection {
+	edges := make([]*model.SearchEdge, len(searchConnection.Edges))
+
+	for i, e := range searchConnection.Edges {
+		edges[i] = ModelSearchHitToGQL(e.Cursor, e.Node)
+	}
+
+	return &model.SearchConnection{
+		Edges:    edges,
+		PageInfo: DTOPageInfoToGQL(&searchConnection.PageInfo),
+	}
+}
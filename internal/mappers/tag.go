@@ -0,0 +1,19 @@
+package mappers
+
+import (
+	"github.com/Govorov1705/ozon-test/graph/model"
+	"github.com/Govorov1705/ozon-test/internal/models"
+)
+
+func ModelTagsToGQL(tags []*models.Tag) []*model.Tag {
+	GQLTags := make([]*model.Tag, len(tags))
+
+	for i, t := range tags {
+		GQLTags[i] = &model.Tag{
+			Name:      t.Name,
+			PostCount: t.PostCount,
+		}
+	}
+
+	return GQLTags
+}
@@ -5,10 +5,11 @@ import (
 	"github.com/Govorov1705/ozon-test/internal/models"
 )
 
+// Хеш пароля никогда не попадает в GraphQL
 func ModelUserToGQL(user *models.User) *model.User {
 	return &model.User{
-		ID:             user.ID,
-		Username:       user.Username,
-		HashedPassword: user.HashedPassword,
+		ID:        user.ID,
+		Username:  user.Username,
+		CreatedAt: user.CreatedAt,
 	}
 }
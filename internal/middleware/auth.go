@@ -12,7 +12,14 @@ import (
 
 type contextKey string
 
-const userIDKey contextKey = "userID"
+const (
+	userIDKey  contextKey = "userID"
+	tokenIDKey contextKey = "tokenID"
+)
+
+type SessionChecker interface {
+	IsSessionActive(ctx context.Context, tokenID uuid.UUID) (bool, error)
+}
 
 func WithUserID(ctx context.Context, userID uuid.UUID) context.Context {
 	return context.WithValue(ctx, userIDKey, userID)
@@ -23,7 +30,24 @@ func GetUserID(ctx context.Context) (uuid.UUID, bool) {
 	return userID, ok
 }
 
-func Auth(c *gin.Context) {
+func WithTokenID(ctx context.Context, tokenID uuid.UUID) context.Context {
+	return context.WithValue(ctx, tokenIDKey, tokenID)
+}
+
+func GetTokenID(ctx context.Context) (uuid.UUID, bool) {
+	tokenID, ok := ctx.Value(tokenIDKey).(uuid.UUID)
+	return tokenID, ok
+}
+
+// NewAuth возвращает middleware, которое, помимо подписи и срока действия,
+// проверяет по claim jti, что сессия токена не была отозвана
+func NewAuth(sessions SessionChecker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		auth(c, sessions)
+	}
+}
+
+func auth(c *gin.Context, sessions SessionChecker) {
 	authHeader := c.GetHeader("Authorization")
 
 	if authHeader == "" {
@@ -44,14 +68,28 @@ func Auth(c *gin.Context) {
 		return
 	}
 
-	userIDStr := claims["sub"].(string)
+	userIDStr, _ := claims["sub"].(string)
 	userID, err := uuid.Parse(userIDStr)
 	if err != nil {
 		c.Next()
 		return
 	}
 
+	tokenIDStr, _ := claims["jti"].(string)
+	tokenID, err := uuid.Parse(tokenIDStr)
+	if err != nil {
+		c.Next()
+		return
+	}
+
+	active, err := sessions.IsSessionActive(c.Request.Context(), tokenID)
+	if err != nil || !active {
+		c.Next()
+		return
+	}
+
 	ctx := WithUserID(c.Request.Context(), userID)
+	ctx = WithTokenID(ctx, tokenID)
 	c.Request = c.Request.WithContext(ctx)
 	c.Next()
 }
@@ -0,0 +1,185 @@
+package migrations
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"strconv"
+
+	"github.com/Govorov1705/ozon-test/internal/logger"
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"go.uber.org/zap"
+)
+
+const Usage = `usage: server migrate <command>
+
+commands:
+  up [N]         apply all or N pending migrations
+  down [N|all]   roll back N migrations (1 by default) or all of them
+  goto V         migrate up or down to version V
+  version        print the current version
+  force V        set version V without running migrations, V = -1 clears it`
+
+// Migrator управляет миграциями одного хранилища, встроенными в бинарник
+type Migrator struct {
+	*migrate.Migrate
+	latest uint
+}
+
+// New читает миграции из каталога migrations в fsys, а newMigrate
+// подключает их к базе хранилища
+func New(fsys fs.FS, newMigrate func(src source.Driver) (*migrate.Migrate, error)) (*Migrator, error) {
+	src, err := iofs.New(fsys, "migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	latest, err := src.First()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		next, err := src.Next(latest)
+		if errors.Is(err, fs.ErrNotExist) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		latest = next
+	}
+
+	m, err := newMigrate(src)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{Migrate: m, latest: latest}, nil
+}
+
+// Prepare вызывается при старте сервера. С autoMigrate применяет все
+// миграции, иначе только проверяет схему: запуск с прерванной миграцией
+// невозможен, а об отставании схемы предупреждает лог
+func (m *Migrator) Prepare(autoMigrate bool) error {
+	if autoMigrate {
+		err := m.Up()
+		if err != nil && !errors.Is(err, migrate.ErrNoChange) {
+			return err
+		}
+		logger.Logger.Info("Migrations applied")
+	}
+
+	version, dirty, err := m.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		logger.Logger.Warn("Database has no migrations applied, run `server migrate up`")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if dirty {
+		return fmt.Errorf("database schema is dirty at version %d, fix it and run `server migrate force`", version)
+	}
+	if version < m.latest {
+		logger.Logger.Warn(
+			"Database schema is behind, run `server migrate up`",
+			zap.Uint("version", version),
+			zap.Uint("latest", m.latest),
+		)
+	}
+
+	return nil
+}
+
+// Run выполняет подкоманду migrate и печатает получившуюся версию в out
+func (m *Migrator) Run(args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return errors.New(Usage)
+	}
+
+	var err error
+
+	switch command, arg := args[0], optionalArg(args); {
+	case command == "up" && arg == "":
+		err = m.Up()
+	case command == "up":
+		var n int
+		n, err = parseSteps(arg)
+		if err == nil {
+			err = m.Steps(n)
+		}
+	case command == "down" && arg == "":
+		err = m.Steps(-1)
+	case command == "down" && arg == "all":
+		err = m.Down()
+	case command == "down":
+		var n int
+		n, err = parseSteps(arg)
+		if err == nil {
+			err = m.Steps(-n)
+		}
+	case command == "goto" && arg != "":
+		var version uint64
+		version, err = strconv.ParseUint(arg, 10, 0)
+		if err == nil {
+			err = m.Migrate.Migrate(uint(version))
+		}
+	case command == "force" && arg != "":
+		var version int
+		version, err = strconv.Atoi(arg)
+		if err == nil {
+			err = m.Force(version)
+		}
+	case command == "version" && arg == "":
+	default:
+		return errors.New(Usage)
+	}
+
+	if errors.Is(err, migrate.ErrNoChange) {
+		fmt.Fprintln(out, "no change")
+		err = nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return m.printVersion(out)
+}
+
+func (m *Migrator) printVersion(out io.Writer) error {
+	version, dirty, err := m.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		fmt.Fprintln(out, "no migrations applied")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if dirty {
+		fmt.Fprintf(out, "version %d (dirty), latest %d\n", version, m.latest)
+	} else {
+		fmt.Fprintf(out, "version %d, latest %d\n", version, m.latest)
+	}
+
+	return nil
+}
+
+func optionalArg(args []string) string {
+	if len(args) > 1 {
+		return args[1]
+	}
+	return ""
+}
+
+func parseSteps(arg string) (int, error) {
+	n, err := strconv.Atoi(arg)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("invalid number of migrations %q", arg)
+	}
+	return n, nil
+}
@@ -1,8 +1,10 @@
 package models
 
 import (
+	"math"
 	"time"
 
+	"github.com/Govorov1705/ozon-test/internal/pagination"
 	"github.com/google/uuid"
 )
 
@@ -14,4 +16,75 @@ type Comment struct {
 	ReplyTo   *uuid.UUID
 	Content   string
 	CreatedAt time.Time
+	EditedAt  *time.Time
+	DeletedAt *time.Time
+	CommentVotes
+}
+
+// CommentVotes - денормализованные счетчики голосов за комментарий. Рейтинги
+// пересчитываются при каждом голосе и хранятся вместе с комментарием,
+// чтобы по ним можно было сортировать и листать выборку
+type CommentVotes struct {
+	Upvotes     int32
+	Downvotes   int32
+	Score       int32
+	Controversy float64
+	Best        float64
+}
+
+// wilsonZ - квантиль нормального распределения для 95% доверительного интервала
+const wilsonZ = 1.959963984540054
+
+func NewCommentVotes(upvotes, downvotes int32) CommentVotes {
+	votes := CommentVotes{
+		Upvotes:   upvotes,
+		Downvotes: downvotes,
+		Score:     upvotes - downvotes,
+	}
+
+	// Спорность растет с числом голосов и тем сильнее, чем ближе
+	// голоса "за" и "против" друг к другу
+	if upvotes > 0 && downvotes > 0 {
+		up, down := float64(upvotes), float64(downvotes)
+		votes.Controversy = math.Pow(up+down, math.Min(up, down)/math.Max(up, down))
+	}
+
+	// Нижняя граница интервала Уилсона для доли голосов "за": комментарий
+	// с малым числом голосов не обгоняет проверенный многими
+	if n := float64(upvotes + downvotes); n > 0 {
+		p := float64(upvotes) / n
+		z2 := wilsonZ * wilsonZ
+		votes.Best = (p + z2/(2*n) - wilsonZ*math.Sqrt((p*(1-p)+z2/(4*n))/n)) / (1 + z2/n)
+	}
+
+	return votes
+}
+
+// Rank возвращает рейтинг, по которому комментарии упорядочены при sort
+func (v *CommentVotes) Rank(sort pagination.Sort) float64 {
+	switch sort {
+	case pagination.SortTop:
+		return float64(v.Score)
+	case pagination.SortControversial:
+		return v.Controversy
+	case pagination.SortBest:
+		return v.Best
+	}
+	return 0
+}
+
+// CommentVote - голос пользователя за комментарий: 1 или -1
+type CommentVote struct {
+	CommentID uuid.UUID
+	UserID    uuid.UUID
+	Value     int8
+	CreatedAt time.Time
+}
+
+// CommentRevision хранит содержимое комментария до очередного редактирования
+type CommentRevision struct {
+	ID        uuid.UUID
+	CommentID uuid.UUID
+	Content   string
+	CreatedAt time.Time
 }
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Community - сообщество, в котором публикуются посты. AreCommentsAllowed -
+// значение по умолчанию для постов, созданных в нем
+type Community struct {
+	ID                 uuid.UUID
+	OwnerID            uuid.UUID
+	Name               string
+	Slug               string
+	Description        string
+	AreCommentsAllowed bool
+	CreatedAt          time.Time
+}
+
+type CommunityRole string
+
+const (
+	CommunityRoleMember    CommunityRole = "MEMBER"
+	CommunityRoleModerator CommunityRole = "MODERATOR"
+	CommunityRoleOwner     CommunityRole = "OWNER"
+)
+
+// CanModerate показывает, может ли участник управлять чужими постами
+// сообщества. Владелец - тоже модератор
+func (r CommunityRole) CanModerate() bool {
+	return r == CommunityRoleModerator || r == CommunityRoleOwner
+}
+
+type CommunityMember struct {
+	CommunityID uuid.UUID
+	UserID      uuid.UUID
+	Role        CommunityRole
+	JoinedAt    time.Time
+}
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	EventCommentAdded    = "comment_added"
+	EventCommentUpdated  = "comment_updated"
+	EventCommentDeleted  = "comment_deleted"
+	EventCommentsToggled = "comments_toggled"
+	EventReactionChanged = "reaction_changed"
+)
+
+// OutboxEvent записывается в одной транзакции с изменением данных и
+// доставляется подписчикам уже после коммита
+type OutboxEvent struct {
+	ID          uuid.UUID
+	Type        string
+	Payload     []byte
+	CreatedAt   time.Time
+	ProcessedAt *time.Time
+}
@@ -9,8 +9,11 @@ import (
 type Post struct {
 	ID                 uuid.UUID
 	UserID             uuid.UUID
+	CommunityID        *uuid.UUID
 	Title              string
 	Content            string
 	AreCommentsAllowed bool
 	CreatedAt          time.Time
+	UpdatedAt          *time.Time
+	DeletedAt          *time.Time
 }
@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Reaction - реакция пользователя эмодзи на пост или комментарий TargetID.
+// Одним эмодзи пользователь реагирует на цель не больше одного раза
+type Reaction struct {
+	TargetID  uuid.UUID
+	UserID    uuid.UUID
+	Emoji     string
+	CreatedAt time.Time
+}
+
+// ReactionCount - все реакции на цель одним эмодзи. ViewerHasReacted
+// показывает, есть ли среди них реакция запросившего пользователя
+type ReactionCount struct {
+	Emoji            string
+	Count            int32
+	ViewerHasReacted bool
+}
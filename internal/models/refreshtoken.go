@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RefreshToken хранит хеш выданного refresh-токена. Токены одной сессии
+// объединены общим SessionID: при ротации старый токен отзывается, а новый
+// выдается в той же сессии
+type RefreshToken struct {
+	ID        uuid.UUID
+	SessionID uuid.UUID
+	UserID    uuid.UUID
+	TokenHash string
+	ExpiresAt time.Time
+	RevokedAt *time.Time
+	CreatedAt time.Time
+}
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SearchHit - найденный пост или комментарий: заполнено ровно одно из полей
+// Post и Comment. Snippet - фрагмент текста с выделенными словами запроса.
+// Rank сравним только между результатами одного хранилища
+type SearchHit struct {
+	Post    *Post
+	Comment *Comment
+	Rank    float64
+	Snippet string
+}
+
+// Key возвращает позицию результата в выборке (rank, created_at, id)
+func (h *SearchHit) Key() (float64, time.Time, uuid.UUID) {
+	if h.Post != nil {
+		return h.Rank, h.Post.CreatedAt, h.Post.ID
+	}
+	return h.Rank, h.Comment.CreatedAt, h.Comment.ID
+}
@@ -0,0 +1,7 @@
+package models
+
+// Tag - тег и число неудаленных постов с ним
+type Tag struct {
+	Name      string
+	PostCount int32
+}
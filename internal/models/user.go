@@ -1,9 +1,14 @@
 package models
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 type User struct {
 	ID             uuid.UUID
 	Username       string
 	HashedPassword string
+	CreatedAt      time.Time
 }
@@ -0,0 +1,61 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/Govorov1705/ozon-test/internal/broadcasters"
+	"github.com/Govorov1705/ozon-test/internal/dtos"
+	"github.com/Govorov1705/ozon-test/internal/errs"
+	"github.com/Govorov1705/ozon-test/internal/models"
+	"github.com/Govorov1705/ozon-test/internal/repositories"
+)
+
+// NewPostActivityHydrator возвращает функцию, загружающую комментарий
+// для событий добавления и изменения
+func NewPostActivityHydrator(cr repositories.CommentsRepository) func(ctx context.Context, event *dtos.PostActivityEvent) error {
+	return func(ctx context.Context, event *dtos.PostActivityEvent) error {
+		if event.Type != models.EventCommentAdded && event.Type != models.EventCommentUpdated {
+			return nil
+		}
+
+		comment, err := cr.GetByID(ctx, event.CommentID, false)
+		if err != nil {
+			return err
+		}
+		event.Comment = comment
+
+		return nil
+	}
+}
+
+// NewPostActivityHandler обрабатывает все события models.Event*
+func NewPostActivityHandler(
+	cr repositories.CommentsRepository,
+	b broadcasters.Broadcaster[*dtos.PostActivityEvent],
+) Handler {
+	hydrate := NewPostActivityHydrator(cr)
+
+	return func(ctx context.Context, event *models.OutboxEvent) error {
+		var activity dtos.PostActivityEvent
+		err := json.Unmarshal(event.Payload, &activity)
+		if err != nil {
+			return err
+		}
+
+		err = hydrate(ctx, &activity)
+		if err != nil {
+			// Комментарий уже удален окончательно, рассылать нечего:
+			// об удалении подписчики узнают из отдельного события
+			if errors.Is(err, errs.ErrNotFound) {
+				return nil
+			}
+			return err
+		}
+
+		// Если отправить не удалось, relay оставит событие необработанным
+		// и повторит его в следующий раз
+		return b.Publish(event.ID, activity.PostID, &activity)
+	}
+}
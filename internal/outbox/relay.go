@@ -0,0 +1,140 @@
+package outbox
+
+import (
+	"context"
+	"time"
+
+	"github.com/Govorov1705/ozon-test/internal/errs"
+	"github.com/Govorov1705/ozon-test/internal/logger"
+	"github.com/Govorov1705/ozon-test/internal/models"
+	"github.com/Govorov1705/ozon-test/internal/repositories"
+	"github.com/Govorov1705/ozon-test/internal/transactions"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	batchSize       int32 = 100
+	cleanupInterval       = time.Minute
+)
+
+type Handler func(ctx context.Context, event *models.OutboxEvent) error
+
+// Relay доставляет события из outbox обработчикам. Событие помечается
+// обработанным только после успешной доставки, поэтому при сбое оно будет
+// доставлено повторно (at-least-once); получатели отбрасывают дубликаты по ID события
+type Relay struct {
+	txStarter    transactions.TxStarter
+	outboxRepo   repositories.OutboxRepository
+	handlers     map[string]Handler
+	pollInterval time.Duration
+	retention    time.Duration
+}
+
+func NewRelay(
+	txStarter transactions.TxStarter,
+	or repositories.OutboxRepository,
+	pollInterval time.Duration,
+	retention time.Duration,
+) *Relay {
+	return &Relay{
+		txStarter:    txStarter,
+		outboxRepo:   or,
+		handlers:     make(map[string]Handler),
+		pollInterval: pollInterval,
+		retention:    retention,
+	}
+}
+
+// Handle регистрирует обработчик. Вызывать до Run
+func (r *Relay) Handle(eventType string, h Handler) {
+	r.handlers[eventType] = h
+}
+
+// Run обрабатывает события, пока не отменен ctx
+func (r *Relay) Run(ctx context.Context) {
+	poll := time.NewTicker(r.pollInterval)
+	defer poll.Stop()
+	cleanup := time.NewTicker(cleanupInterval)
+	defer cleanup.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-poll.C:
+			// Пока события есть, выбираем их без паузы
+			for {
+				n, err := r.processBatch(ctx)
+				if err != nil || n < batchSize {
+					break
+				}
+			}
+		case <-cleanup.C:
+			err := r.outboxRepo.DeleteProcessedBefore(ctx, time.Now().Add(-r.retention))
+			if err != nil {
+				logger.Logger.Error("error cleaning up outbox", zap.Error(err))
+			}
+		}
+	}
+}
+
+func (r *Relay) processBatch(ctx context.Context) (n int32, err error) {
+	tx, err := r.txStarter.Begin(ctx)
+	if err != nil {
+		logger.Logger.Error("error starting transaction", zap.Error(err))
+		return 0, errs.ErrInternal
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback(ctx)
+			panic(p)
+		} else if err != nil {
+			if rollbackErr := tx.Rollback(ctx); rollbackErr != nil {
+				logger.Logger.Error("rollback failed", zap.Error(rollbackErr))
+				err = errs.ErrInternal
+			}
+		} else {
+			if commitErr := tx.Commit(ctx); commitErr != nil {
+				logger.Logger.Error("error committing transaction", zap.Error(commitErr))
+				err = errs.ErrInternal
+			}
+		}
+	}()
+
+	ctx = transactions.PutTxIntoContext(ctx, tx)
+
+	events, err := r.outboxRepo.GetUnprocessed(ctx, batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	processed := make([]uuid.UUID, 0, len(events))
+	for _, event := range events {
+		h, ok := r.handlers[event.Type]
+		if !ok {
+			logger.Logger.Warn("no handler for outbox event", zap.String("type", event.Type))
+		} else if err := h(ctx, event); err != nil {
+			// Остальные события доставим в следующий раз, чтобы не нарушить порядок
+			logger.Logger.Error(
+				"error handling outbox event",
+				zap.String("id", event.ID.String()),
+				zap.Error(err),
+			)
+			break
+		}
+		processed = append(processed, event.ID)
+	}
+
+	if len(processed) == 0 {
+		return 0, nil
+	}
+
+	err = r.outboxRepo.MarkProcessed(ctx, processed)
+	if err != nil {
+		return 0, err
+	}
+
+	return int32(len(processed)), nil
+}
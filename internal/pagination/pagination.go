@@ -0,0 +1,247 @@
+package pagination
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Govorov1705/ozon-test/internal/errs"
+	"github.com/google/uuid"
+)
+
+const (
+	DefaultPageSize        int32 = 10
+	DefaultRepliesPageSize int32 = 5
+	MaxPageSize            int32 = 100
+)
+
+// Sort задает порядок выборки. По умолчанию (пустое значение) - SortNew
+type Sort string
+
+const (
+	SortNew           Sort = "NEW"
+	SortOld           Sort = "OLD"
+	SortTop           Sort = "TOP"
+	SortControversial Sort = "CONTROVERSIAL"
+	SortBest          Sort = "BEST"
+	// SortRelevance - порядок результатов поиска, рейтинг - релевантность
+	SortRelevance Sort = "RELEVANCE"
+)
+
+// Ranked сообщает, упорядочена ли выборка сначала по рейтингу записи,
+// а уже затем по (created_at DESC, id DESC)
+func (s Sort) Ranked() bool {
+	return s == SortTop || s == SortControversial || s == SortBest || s == SortRelevance
+}
+
+// Cursor указывает на позицию записи в выборке, упорядоченной по (created_at, id),
+// а для ранжированных выборок - по (rank, created_at, id)
+type Cursor struct {
+	Ranked    bool
+	Rank      float64
+	CreatedAt time.Time
+	ID        uuid.UUID
+}
+
+func EncodeCursor(createdAt time.Time, id uuid.UUID) string {
+	raw := fmt.Sprintf("%s|%s", createdAt.UTC().Format(time.RFC3339Nano), id)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// EncodeRankedCursor кодирует рейтинг без потери точности, чтобы курсор
+// указывал ровно на ту же запись
+func EncodeRankedCursor(rank float64, createdAt time.Time, id uuid.UUID) string {
+	raw := fmt.Sprintf("%s|%s|%s", strconv.FormatFloat(rank, 'g', -1, 64), createdAt.UTC().Format(time.RFC3339Nano), id)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func DecodeCursor(s string) (*Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errs.ErrInvalidCursor
+	}
+
+	cursor := &Cursor{}
+
+	parts := strings.Split(string(raw), "|")
+	switch len(parts) {
+	case 2:
+	case 3:
+		cursor.Ranked = true
+		cursor.Rank, err = strconv.ParseFloat(parts[0], 64)
+		// NaN не сравнивается ни с одним рейтингом, а бесконечность сделала бы
+		// курсор границей всей выборки
+		if err != nil || math.IsNaN(cursor.Rank) || math.IsInf(cursor.Rank, 0) {
+			return nil, errs.ErrInvalidCursor
+		}
+		parts = parts[1:]
+	default:
+		return nil, errs.ErrInvalidCursor
+	}
+	createdAtStr, IDStr := parts[0], parts[1]
+
+	createdAt, err := time.Parse(time.RFC3339Nano, createdAtStr)
+	if err != nil {
+		return nil, errs.ErrInvalidCursor
+	}
+
+	ID, err := uuid.Parse(IDStr)
+	if err != nil {
+		return nil, errs.ErrInvalidCursor
+	}
+
+	cursor.CreatedAt = createdAt
+	cursor.ID = ID
+
+	return cursor, nil
+}
+
+// Compare сравнивает позицию записи (createdAt, id) с курсором по аналогии
+// с row comparison в PostgreSQL: -1, если запись меньше курсора, 0 - если равна, 1 - если больше
+func (c *Cursor) Compare(createdAt time.Time, id uuid.UUID) int {
+	if cmp := createdAt.Compare(c.CreatedAt); cmp != 0 {
+		return cmp
+	}
+	return bytes.Compare(id[:], c.ID[:])
+}
+
+// CompareRanked сравнивает позицию записи (rank, createdAt, id) с курсором
+func (c *Cursor) CompareRanked(rank float64, createdAt time.Time, id uuid.UUID) int {
+	if rank != c.Rank {
+		if rank < c.Rank {
+			return -1
+		}
+		return 1
+	}
+	return c.Compare(createdAt, id)
+}
+
+// Page описывает keyset-выборку по порядку (created_at DESC, id DESC),
+// если Sort не задает другой. При SortOld порядок возрастающий, а
+// ранжированные выборки упорядочены по (rank DESC, created_at DESC, id DESC).
+// При Backward = true записи выбираются в обратном порядке (для last/before),
+// а вызывающая сторона сама разворачивает результат.
+// Limit уже включает одну дополнительную запись для определения наличия следующей страницы.
+type Page struct {
+	After    *Cursor
+	Before   *Cursor
+	Limit    int32
+	Backward bool
+	Sort     Sort
+}
+
+// Contains сообщает, попадает ли запись в границы страницы без учета Limit
+func (p *Page) Contains(createdAt time.Time, id uuid.UUID) bool {
+	return p.ContainsRanked(0, createdAt, id)
+}
+
+// ContainsRanked - Contains для ранжированных выборок. В остальных
+// выборках rank не учитывается
+func (p *Page) ContainsRanked(rank float64, createdAt time.Time, id uuid.UUID) bool {
+	compare := func(c *Cursor) int {
+		cmp := c.Compare(createdAt, id)
+		if p.Sort.Ranked() {
+			cmp = c.CompareRanked(rank, createdAt, id)
+		}
+		if p.Sort == SortOld {
+			return -cmp
+		}
+		return cmp
+	}
+
+	if p.After != nil && compare(p.After) >= 0 {
+		return false
+	}
+	if p.Before != nil && compare(p.Before) <= 0 {
+		return false
+	}
+	return true
+}
+
+// SetSort задает порядок выборки и проверяет, что курсоры получены из
+// выборки того же вида: позиция в ранжированной выборке без рейтинга
+// не определена
+func (p *Page) SetSort(sort Sort) error {
+	for _, cursor := range []*Cursor{p.After, p.Before} {
+		if cursor != nil && cursor.Ranked != sort.Ranked() {
+			return errs.ErrInvalidCursor
+		}
+	}
+
+	// Порядок по умолчанию хранится пустым значением
+	if sort == SortNew {
+		sort = ""
+	}
+	p.Sort = sort
+
+	return nil
+}
+
+// NewPage проверяет аргументы в стиле Relay (first/after, last/before) и собирает из них Page
+func NewPage(first *int32, after *string, last *int32, before *string) (*Page, error) {
+	page, err := newPage(first, after, last, before)
+	if err != nil {
+		return nil, err
+	}
+
+	return page, page.SetSort(SortNew)
+}
+
+// NewSortedPage собирает Page для выборки с порядком sort, листаемой только вперед
+func NewSortedPage(first *int32, after *string, sort Sort) (*Page, error) {
+	page, err := newPage(first, after, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return page, page.SetSort(sort)
+}
+
+func newPage(first *int32, after *string, last *int32, before *string) (*Page, error) {
+	if first != nil && last != nil {
+		return nil, fmt.Errorf("%w: first and last can't be used together", errs.ErrInvalidPagination)
+	}
+
+	page := &Page{}
+
+	size := DefaultPageSize
+	switch {
+	case first != nil:
+		size = *first
+	case last != nil:
+		size = *last
+		page.Backward = true
+	}
+
+	if size <= 0 || size > MaxPageSize {
+		return nil, fmt.Errorf("%w: page size must be between 1 and %d", errs.ErrInvalidPagination, MaxPageSize)
+	}
+	page.Limit = size + 1
+
+	if after != nil {
+		cursor, err := DecodeCursor(*after)
+		if err != nil {
+			return nil, err
+		}
+		page.After = cursor
+	}
+
+	if before != nil {
+		cursor, err := DecodeCursor(*before)
+		if err != nil {
+			return nil, err
+		}
+		page.Before = cursor
+	}
+
+	return page, nil
+}
+
+// Size возвращает запрошенный размер страницы
+func (p *Page) Size() int {
+	return int(p.Limit - 1)
+}
@@ -4,12 +4,38 @@ import (
 	"context"
 
 	"github.com/Govorov1705/ozon-test/internal/models"
+	"github.com/Govorov1705/ozon-test/internal/pagination"
 	"github.com/google/uuid"
 )
 
 type CommentsRepository interface {
 	Add(ctx context.Context, postID, userID uuid.UUID, rootID, replyTo *uuid.UUID, content string) (*models.Comment, error)
 	GetByID(ctx context.Context, commentID uuid.UUID, forUpdate bool) (*models.Comment, error)
-	GetRootCommentsByPostID(ctx context.Context, postID uuid.UUID, limit, offset *int32) ([]*models.Comment, error)
-	GetChildrenCommentsByRootIDs(ctx context.Context, rootIDs []*uuid.UUID) ([]*models.Comment, error)
+	// GetByIDs возвращает найденные комментарии в произвольном порядке;
+	// отсутствующие ID пропускаются
+	GetByIDs(ctx context.Context, IDs []uuid.UUID) ([]*models.Comment, error)
+	GetByPostID(ctx context.Context, postID uuid.UUID, page *pagination.Page) ([]*models.Comment, error)
+	GetRootCommentsByPostID(ctx context.Context, postID uuid.UUID, page *pagination.Page) ([]*models.Comment, error)
+	GetRepliesByParentID(ctx context.Context, parentID uuid.UUID, page *pagination.Page) ([]*models.Comment, error)
+	GetRepliesByParentIDs(ctx context.Context, parentIDs []uuid.UUID, limit int32) ([]*models.Comment, error)
+	CountRepliesByParentIDs(ctx context.Context, parentIDs []uuid.UUID) (map[uuid.UUID]int32, error)
+	UpdateContent(ctx context.Context, commentID uuid.UUID, content string) (*models.Comment, error)
+	AddRevision(ctx context.Context, commentID uuid.UUID, content string) (*models.CommentRevision, error)
+	// GetRevisionsByCommentIDs возвращает правки каждого комментария в порядке создания
+	GetRevisionsByCommentIDs(ctx context.Context, commentIDs []uuid.UUID) (map[uuid.UUID][]*models.CommentRevision, error)
+	SoftDelete(ctx context.Context, commentID uuid.UUID) (*models.Comment, error)
+	HasUndeletedReplies(ctx context.Context, commentID uuid.UUID) (bool, error)
+	Delete(ctx context.Context, commentID uuid.UUID) error
+	CountByUserIDs(ctx context.Context, userIDs []uuid.UUID) (map[uuid.UUID]int32, error)
+	// Search устроен так же, как в PostsRepository
+	Search(ctx context.Context, query string, page *pagination.Page) ([]*models.SearchHit, error)
+	GetVote(ctx context.Context, commentID, userID uuid.UUID) (*models.CommentVote, error)
+	SetVote(ctx context.Context, commentID, userID uuid.UUID, value int8) (*models.CommentVote, error)
+	// DeleteVote не считает ошибкой отсутствие голоса
+	DeleteVote(ctx context.Context, commentID, userID uuid.UUID) error
+	UpdateVotes(ctx context.Context, commentID uuid.UUID, votes models.CommentVotes) (*models.Comment, error)
+	// Реакции устроены так же, как в PostsRepository
+	AddReaction(ctx context.Context, commentID, userID uuid.UUID, emoji string) (bool, error)
+	DeleteReaction(ctx context.Context, commentID, userID uuid.UUID, emoji string) (bool, error)
+	GetReactions(ctx context.Context, commentIDs []uuid.UUID, viewerID *uuid.UUID) (map[uuid.UUID][]*models.ReactionCount, error)
 }
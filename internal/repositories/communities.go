@@ -0,0 +1,26 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/Govorov1705/ozon-test/internal/models"
+	"github.com/google/uuid"
+)
+
+type CommunitiesRepository interface {
+	// Add возвращает errs.ErrAlreadyExists, если slug уже занят
+	Add(ctx context.Context, ownerID uuid.UUID, name, slug, description string, areCommentsAllowed bool) (*models.Community, error)
+	GetByID(ctx context.Context, communityID uuid.UUID, forUpdate bool) (*models.Community, error)
+	GetByIDs(ctx context.Context, IDs []uuid.UUID) ([]*models.Community, error)
+	GetBySlug(ctx context.Context, slug string) (*models.Community, error)
+	// AddMember возвращает false, если пользователь уже состоит в сообществе
+	AddMember(ctx context.Context, communityID, userID uuid.UUID, role models.CommunityRole) (bool, error)
+	// DeleteMember возвращает false, если пользователь не состоял в сообществе
+	DeleteMember(ctx context.Context, communityID, userID uuid.UUID) (bool, error)
+	// GetMember возвращает errs.ErrNotFound, если пользователь не состоит в сообществе
+	GetMember(ctx context.Context, communityID, userID uuid.UUID) (*models.CommunityMember, error)
+	SetMemberRole(ctx context.Context, communityID, userID uuid.UUID, role models.CommunityRole) (*models.CommunityMember, error)
+	// GetModerators возвращает владельца и модераторов в порядке вступления
+	GetModerators(ctx context.Context, communityID uuid.UUID) ([]*models.CommunityMember, error)
+	CountMembers(ctx context.Context, communityIDs []uuid.UUID) (map[uuid.UUID]int32, error)
+}
@@ -0,0 +1,409 @@
+package conformance
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/Govorov1705/ozon-test/internal/errs"
+	"github.com/Govorov1705/ozon-test/internal/models"
+	"github.com/Govorov1705/ozon-test/internal/pagination"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var commentsTests = []testCase{
+	{"AddAndGet", testCommentsAddAndGet},
+	{"NotFound", testCommentsNotFound},
+	{"GetByIDs", testCommentsGetByIDs},
+	{"GetByPostID", testCommentsGetByPostID},
+	{"GetRootCommentsByPostID", testCommentsGetRootCommentsByPostID},
+	{"GetRepliesByParentID", testCommentsGetRepliesByParentID},
+	{"GetRepliesByParentIDs", testCommentsGetRepliesByParentIDs},
+	{"CountRepliesByParentIDs", testCommentsCountRepliesByParentIDs},
+	{"UpdateContentAndRevisions", testCommentsUpdateContentAndRevisions},
+	{"GetRevisionsByCommentIDs", testCommentsGetRevisionsByCommentIDs},
+	{"SoftDelete", testCommentsSoftDelete},
+	{"HasUndeletedReplies", testCommentsHasUndeletedReplies},
+	{"DeleteSubtree", testCommentsDeleteSubtree},
+	{"CountByUserIDs", testCommentsCountByUserIDs},
+	{"ReturnsCopies", testCommentsReturnsCopies},
+}
+
+func testCommentsAddAndGet(t *testing.T, b *Backend) {
+	ctx := context.Background()
+	user := addUser(t, b, "alice")
+	post := addPost(t, b, user.ID, "post")
+
+	root := addComment(t, b, post, user.ID, nil, "root")
+	assert.NotEqual(t, uuid.Nil, root.ID)
+	assert.Equal(t, post.ID, root.PostID)
+	assert.Equal(t, user.ID, root.UserID)
+	assert.Equal(t, root.ID, root.RootID)
+	assert.Nil(t, root.ReplyTo)
+	assert.Equal(t, "root", root.Content)
+	assert.False(t, root.CreatedAt.IsZero())
+	assert.Nil(t, root.EditedAt)
+	assert.Nil(t, root.DeletedAt)
+
+	reply := addComment(t, b, post, user.ID, root, "reply")
+	nested := addComment(t, b, post, user.ID, reply, "nested")
+	assert.Equal(t, root.ID, reply.RootID)
+	assert.Equal(t, &root.ID, reply.ReplyTo)
+	assert.Equal(t, root.ID, nested.RootID)
+	assert.Equal(t, &reply.ID, nested.ReplyTo)
+
+	for _, forUpdate := range []bool{false, true} {
+		got, err := b.Comments.GetByID(ctx, nested.ID, forUpdate)
+		require.NoError(t, err)
+		assert.Equal(t, nested.ID, got.ID)
+		assert.Equal(t, root.ID, got.RootID)
+		assert.Equal(t, &reply.ID, got.ReplyTo)
+		assert.Equal(t, "nested", got.Content)
+		assert.True(t, nested.CreatedAt.Equal(got.CreatedAt))
+	}
+}
+
+func testCommentsNotFound(t *testing.T, b *Backend) {
+	ctx := context.Background()
+	ID := uuid.New()
+
+	_, err := b.Comments.GetByID(ctx, ID, false)
+	assert.ErrorIs(t, err, errs.ErrNotFound)
+	_, err = b.Comments.GetByID(ctx, ID, true)
+	assert.ErrorIs(t, err, errs.ErrNotFound)
+	_, err = b.Comments.UpdateContent(ctx, ID, "content")
+	assert.ErrorIs(t, err, errs.ErrNotFound)
+	_, err = b.Comments.SoftDelete(ctx, ID)
+	assert.ErrorIs(t, err, errs.ErrNotFound)
+	err = b.Comments.Delete(ctx, ID)
+	assert.ErrorIs(t, err, errs.ErrNotFound)
+}
+
+func testCommentsGetByIDs(t *testing.T, b *Backend) {
+	ctx := context.Background()
+	user := addUser(t, b, "alice")
+	post := addPost(t, b, user.ID, "post")
+	first := addComment(t, b, post, user.ID, nil, "first")
+	second := addComment(t, b, post, user.ID, first, "second")
+	addComment(t, b, post, user.ID, nil, "other")
+
+	comments, err := b.Comments.GetByIDs(ctx, []uuid.UUID{first.ID, second.ID, uuid.New()})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []uuid.UUID{first.ID, second.ID}, idsOf(comments))
+
+	comments, err = b.Comments.GetByIDs(ctx, []uuid.UUID{})
+	require.NoError(t, err)
+	assert.Empty(t, comments)
+}
+
+func testCommentsGetByPostID(t *testing.T, b *Backend) {
+	user := addUser(t, b, "alice")
+	post := addPost(t, b, user.ID, "post")
+	other := addPost(t, b, user.ID, "other")
+
+	first := addComment(t, b, post, user.ID, nil, "first")
+	second := addComment(t, b, post, user.ID, nil, "second")
+	comments := []*models.Comment{
+		first,
+		second,
+		addComment(t, b, post, user.ID, first, "reply"),
+		addComment(t, b, post, user.ID, second, "reply"),
+		addComment(t, b, post, user.ID, nil, "third"),
+	}
+	addComment(t, b, other, user.ID, nil, "other post")
+
+	testPagination(t, newestFirst(comments...), comments, func(page *pagination.Page) ([]*models.Comment, error) {
+		return b.Comments.GetByPostID(context.Background(), post.ID, page)
+	})
+}
+
+func testCommentsGetRootCommentsByPostID(t *testing.T, b *Backend) {
+	user := addUser(t, b, "alice")
+	post := addPost(t, b, user.ID, "post")
+	other := addPost(t, b, user.ID, "other")
+
+	roots := make([]*models.Comment, 5)
+	for i := range roots {
+		roots[i] = addComment(t, b, post, user.ID, nil, fmt.Sprintf("root %d", i))
+		addComment(t, b, post, user.ID, roots[i], "reply")
+	}
+	addComment(t, b, other, user.ID, nil, "other post")
+
+	testPagination(t, newestFirst(roots...), roots, func(page *pagination.Page) ([]*models.Comment, error) {
+		return b.Comments.GetRootCommentsByPostID(context.Background(), post.ID, page)
+	})
+}
+
+func testCommentsGetRepliesByParentID(t *testing.T, b *Backend) {
+	user := addUser(t, b, "alice")
+	post := addPost(t, b, user.ID, "post")
+	parent := addComment(t, b, post, user.ID, nil, "parent")
+	sibling := addComment(t, b, post, user.ID, nil, "sibling")
+
+	replies := make([]*models.Comment, 5)
+	for i := range replies {
+		replies[i] = addComment(t, b, post, user.ID, parent, fmt.Sprintf("reply %d", i))
+	}
+	addComment(t, b, post, user.ID, replies[0], "nested")
+	addComment(t, b, post, user.ID, sibling, "sibling's reply")
+
+	testPagination(t, newestFirst(replies...), replies, func(page *pagination.Page) ([]*models.Comment, error) {
+		return b.Comments.GetRepliesByParentID(context.Background(), parent.ID, page)
+	})
+}
+
+func testCommentsGetRepliesByParentIDs(t *testing.T, b *Backend) {
+	user := addUser(t, b, "alice")
+	post := addPost(t, b, user.ID, "post")
+	first := addComment(t, b, post, user.ID, nil, "first")
+	second := addComment(t, b, post, user.ID, nil, "second")
+	empty := addComment(t, b, post, user.ID, nil, "empty")
+	skipped := addComment(t, b, post, user.ID, nil, "skipped")
+
+	firstReplies := make([]*models.Comment, 3)
+	for i := range firstReplies {
+		firstReplies[i] = addComment(t, b, post, user.ID, first, fmt.Sprintf("reply %d", i))
+	}
+	secondReply := addComment(t, b, post, user.ID, second, "reply")
+	addComment(t, b, post, user.ID, secondReply, "nested")
+	addComment(t, b, post, user.ID, skipped, "not requested")
+
+	replies, err := b.Comments.GetRepliesByParentIDs(
+		context.Background(),
+		[]uuid.UUID{first.ID, second.ID, empty.ID},
+		2,
+	)
+	require.NoError(t, err)
+
+	// Из каждой ветки берутся limit самых новых ответов, а общий
+	// результат упорядочен так же, как страницы
+	newest := newestFirst(firstReplies...)[:2]
+	expected := []*models.Comment{secondReply}
+	for _, reply := range firstReplies {
+		if reply.ID == newest[0] || reply.ID == newest[1] {
+			expected = append(expected, reply)
+		}
+	}
+	assert.Equal(t, newestFirst(expected...), idsOf(replies))
+}
+
+func testCommentsCountRepliesByParentIDs(t *testing.T, b *Backend) {
+	ctx := context.Background()
+	user := addUser(t, b, "alice")
+	post := addPost(t, b, user.ID, "post")
+	first := addComment(t, b, post, user.ID, nil, "first")
+	second := addComment(t, b, post, user.ID, nil, "second")
+	empty := addComment(t, b, post, user.ID, nil, "empty")
+
+	reply := addComment(t, b, post, user.ID, first, "reply")
+	deleted := addComment(t, b, post, user.ID, first, "deleted")
+	addComment(t, b, post, user.ID, reply, "nested")
+	addComment(t, b, post, user.ID, second, "reply")
+
+	// Удаленные ответы остаются в дереве и учитываются
+	_, err := b.Comments.SoftDelete(ctx, deleted.ID)
+	require.NoError(t, err)
+
+	counts, err := b.Comments.CountRepliesByParentIDs(ctx, []uuid.UUID{first.ID, second.ID, empty.ID})
+	require.NoError(t, err)
+	assert.Equal(t, map[uuid.UUID]int32{first.ID: 2, second.ID: 1, empty.ID: 0}, counts)
+}
+
+func testCommentsUpdateContentAndRevisions(t *testing.T, b *Backend) {
+	ctx := context.Background()
+	user := addUser(t, b, "alice")
+	post := addPost(t, b, user.ID, "post")
+	comment := addComment(t, b, post, user.ID, nil, "v1")
+
+	assert.Empty(t, revisionsOf(t, b, comment.ID))
+
+	for _, content := range []string{"v2", "v3"} {
+		current, err := b.Comments.GetByID(ctx, comment.ID, true)
+		require.NoError(t, err)
+
+		revision, err := b.Comments.AddRevision(ctx, comment.ID, current.Content)
+		require.NoError(t, err)
+		assert.NotEqual(t, uuid.Nil, revision.ID)
+		assert.Equal(t, comment.ID, revision.CommentID)
+		assert.Equal(t, current.Content, revision.Content)
+		assert.False(t, revision.CreatedAt.IsZero())
+
+		updated, err := b.Comments.UpdateContent(ctx, comment.ID, content)
+		require.NoError(t, err)
+		assert.Equal(t, content, updated.Content)
+		assert.NotNil(t, updated.EditedAt)
+	}
+
+	revisions := revisionsOf(t, b, comment.ID)
+	require.Len(t, revisions, 2)
+	assert.Equal(t, "v1", revisions[0].Content)
+	assert.Equal(t, "v2", revisions[1].Content)
+
+	got, err := b.Comments.GetByID(ctx, comment.ID, false)
+	require.NoError(t, err)
+	assert.Equal(t, "v3", got.Content)
+	assert.NotNil(t, got.EditedAt)
+}
+
+func testCommentsGetRevisionsByCommentIDs(t *testing.T, b *Backend) {
+	ctx := context.Background()
+	user := addUser(t, b, "alice")
+	post := addPost(t, b, user.ID, "post")
+	first := addComment(t, b, post, user.ID, nil, "first")
+	second := addComment(t, b, post, user.ID, nil, "second")
+	unedited := addComment(t, b, post, user.ID, nil, "unedited")
+
+	for _, revision := range []struct {
+		comment *models.Comment
+		content string
+	}{
+		{first, "first v1"},
+		{second, "second v1"},
+		{first, "first v2"},
+	} {
+		_, err := b.Comments.AddRevision(ctx, revision.comment.ID, revision.content)
+		require.NoError(t, err)
+	}
+
+	revisions, err := b.Comments.GetRevisionsByCommentIDs(ctx, []uuid.UUID{first.ID, second.ID, unedited.ID})
+	require.NoError(t, err)
+
+	contents := map[uuid.UUID][]string{}
+	for ID, commentRevisions := range revisions {
+		for _, revision := range commentRevisions {
+			assert.Equal(t, ID, revision.CommentID)
+			contents[ID] = append(contents[ID], revision.Content)
+		}
+	}
+	assert.Equal(t, map[uuid.UUID][]string{
+		first.ID:  {"first v1", "first v2"},
+		second.ID: {"second v1"},
+	}, contents)
+}
+
+func testCommentsSoftDelete(t *testing.T, b *Backend) {
+	ctx := context.Background()
+	user := addUser(t, b, "alice")
+	post := addPost(t, b, user.ID, "post")
+	comment := addComment(t, b, post, user.ID, nil, "content")
+
+	_, err := b.Comments.AddRevision(ctx, comment.ID, "old content")
+	require.NoError(t, err)
+
+	deleted, err := b.Comments.SoftDelete(ctx, comment.ID)
+	require.NoError(t, err)
+	assert.Empty(t, deleted.Content)
+	assert.NotNil(t, deleted.DeletedAt)
+
+	got, err := b.Comments.GetByID(ctx, comment.ID, false)
+	require.NoError(t, err)
+	assert.Empty(t, got.Content)
+	assert.NotNil(t, got.DeletedAt)
+
+	// История правок остается, удаляется только текст
+	revisions := revisionsOf(t, b, comment.ID)
+	require.Len(t, revisions, 1)
+	assert.Equal(t, "old content", revisions[0].Content)
+}
+
+func testCommentsHasUndeletedReplies(t *testing.T, b *Backend) {
+	ctx := context.Background()
+	user := addUser(t, b, "alice")
+	post := addPost(t, b, user.ID, "post")
+	root := addComment(t, b, post, user.ID, nil, "root")
+	reply := addComment(t, b, post, user.ID, root, "reply")
+	nested := addComment(t, b, post, user.ID, reply, "nested")
+
+	has, err := b.Comments.HasUndeletedReplies(ctx, nested.ID)
+	require.NoError(t, err)
+	assert.False(t, has)
+
+	// Учитываются ответы на любой глубине
+	_, err = b.Comments.SoftDelete(ctx, reply.ID)
+	require.NoError(t, err)
+	has, err = b.Comments.HasUndeletedReplies(ctx, root.ID)
+	require.NoError(t, err)
+	assert.True(t, has)
+
+	_, err = b.Comments.SoftDelete(ctx, nested.ID)
+	require.NoError(t, err)
+	has, err = b.Comments.HasUndeletedReplies(ctx, root.ID)
+	require.NoError(t, err)
+	assert.False(t, has)
+}
+
+func testCommentsDeleteSubtree(t *testing.T, b *Backend) {
+	ctx := context.Background()
+	user := addUser(t, b, "alice")
+	post := addPost(t, b, user.ID, "post")
+	root := addComment(t, b, post, user.ID, nil, "root")
+	reply := addComment(t, b, post, user.ID, root, "reply")
+	nested := addComment(t, b, post, user.ID, reply, "nested")
+	sibling := addComment(t, b, post, user.ID, root, "sibling")
+
+	_, err := b.Comments.AddRevision(ctx, nested.ID, "old content")
+	require.NoError(t, err)
+
+	require.NoError(t, b.Comments.Delete(ctx, reply.ID))
+
+	// Ответы удаляются вместе с комментарием
+	for _, ID := range []uuid.UUID{reply.ID, nested.ID} {
+		_, err = b.Comments.GetByID(ctx, ID, false)
+		assert.ErrorIs(t, err, errs.ErrNotFound)
+	}
+	assert.Empty(t, revisionsOf(t, b, nested.ID))
+
+	remaining, err := b.Comments.GetByPostID(ctx, post.ID, &pagination.Page{Limit: 10})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []uuid.UUID{root.ID, sibling.ID}, idsOf(remaining))
+
+	err = b.Comments.Delete(ctx, reply.ID)
+	assert.ErrorIs(t, err, errs.ErrNotFound)
+}
+
+func testCommentsCountByUserIDs(t *testing.T, b *Backend) {
+	ctx := context.Background()
+	alice := addUser(t, b, "alice")
+	bob := addUser(t, b, "bob")
+	carol := addUser(t, b, "carol")
+	post := addPost(t, b, alice.ID, "post")
+
+	root := addComment(t, b, post, alice.ID, nil, "first")
+	addComment(t, b, post, alice.ID, root, "second")
+	deleted := addComment(t, b, post, alice.ID, nil, "deleted")
+	addComment(t, b, post, bob.ID, root, "bob's")
+	_, err := b.Comments.SoftDelete(ctx, deleted.ID)
+	require.NoError(t, err)
+
+	counts, err := b.Comments.CountByUserIDs(ctx, []uuid.UUID{alice.ID, bob.ID, carol.ID})
+	require.NoError(t, err)
+	assert.Equal(t, map[uuid.UUID]int32{alice.ID: 2, bob.ID: 1, carol.ID: 0}, counts)
+}
+
+func testCommentsReturnsCopies(t *testing.T, b *Backend) {
+	ctx := context.Background()
+	user := addUser(t, b, "alice")
+	post := addPost(t, b, user.ID, "post")
+	comment := addComment(t, b, post, user.ID, nil, "content")
+
+	comment.Content = "changed by caller"
+	got, err := b.Comments.GetByID(ctx, comment.ID, false)
+	require.NoError(t, err)
+	assert.Equal(t, "content", got.Content)
+
+	_, err = b.Comments.UpdateContent(ctx, comment.ID, "updated")
+	require.NoError(t, err)
+	assert.Equal(t, "content", got.Content)
+	assert.Nil(t, got.EditedAt)
+}
+
+func revisionsOf(t *testing.T, b *Backend, commentID uuid.UUID) []*models.CommentRevision {
+	t.Helper()
+
+	revisions, err := b.Comments.GetRevisionsByCommentIDs(context.Background(), []uuid.UUID{commentID})
+	require.NoError(t, err)
+
+	return revisions[commentID]
+}
@@ -0,0 +1,320 @@
+package conformance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/Govorov1705/ozon-test/internal/errs"
+	"github.com/Govorov1705/ozon-test/internal/models"
+	"github.com/Govorov1705/ozon-test/internal/pagination"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var communitiesTests = []testCase{
+	{"AddAndGet", testCommunitiesAddAndGet},
+	{"NotFound", testCommunitiesNotFound},
+	{"DuplicateSlug", testCommunitiesDuplicateSlug},
+	{"Members", testCommunitiesMembers},
+	{"SetMemberRole", testCommunitiesSetMemberRole},
+	{"Moderators", testCommunitiesModerators},
+	{"Rollback", testCommunitiesRollback},
+	{"Posts", testCommunitiesPosts},
+	{"PostsPagination", testCommunitiesPostsPagination},
+}
+
+func addCommunity(t *testing.T, b *Backend, ownerID uuid.UUID, slug string) *models.Community {
+	t.Helper()
+
+	community, err := b.Communities.Add(context.Background(), ownerID, "Community "+slug, slug, "about "+slug, true)
+	require.NoError(t, err)
+
+	return community
+}
+
+func addMember(t *testing.T, b *Backend, communityID, userID uuid.UUID, role models.CommunityRole) {
+	t.Helper()
+
+	added, err := b.Communities.AddMember(context.Background(), communityID, userID, role)
+	require.NoError(t, err)
+	require.True(t, added)
+}
+
+func addCommunityPost(t *testing.T, b *Backend, userID, communityID uuid.UUID, title string) *models.Post {
+	t.Helper()
+
+	post, err := b.Posts.Add(context.Background(), userID, &communityID, title, "content of "+title, true)
+	require.NoError(t, err)
+
+	return post
+}
+
+func testCommunitiesAddAndGet(t *testing.T, b *Backend) {
+	ctx := context.Background()
+	user := addUser(t, b, "alice")
+
+	community, err := b.Communities.Add(ctx, user.ID, "Go developers", "golang", "All about Go", false)
+	require.NoError(t, err)
+	assert.NotEqual(t, uuid.Nil, community.ID)
+	assert.Equal(t, user.ID, community.OwnerID)
+	assert.Equal(t, "Go developers", community.Name)
+	assert.Equal(t, "golang", community.Slug)
+	assert.Equal(t, "All about Go", community.Description)
+	assert.False(t, community.AreCommentsAllowed)
+	assert.False(t, community.CreatedAt.IsZero())
+
+	byID, err := b.Communities.GetByID(ctx, community.ID, false)
+	require.NoError(t, err)
+	assertSameCommunity(t, community, byID)
+
+	locked, err := b.Communities.GetByID(ctx, community.ID, true)
+	require.NoError(t, err)
+	assertSameCommunity(t, community, locked)
+
+	bySlug, err := b.Communities.GetBySlug(ctx, "golang")
+	require.NoError(t, err)
+	assertSameCommunity(t, community, bySlug)
+
+	other := addCommunity(t, b, user.ID, "rust")
+	found, err := b.Communities.GetByIDs(ctx, []uuid.UUID{community.ID, uuid.New(), other.ID})
+	require.NoError(t, err)
+
+	foundIDs := make([]uuid.UUID, len(found))
+	for i, c := range found {
+		foundIDs[i] = c.ID
+	}
+	assert.ElementsMatch(t, []uuid.UUID{community.ID, other.ID}, foundIDs)
+}
+
+func assertSameCommunity(t *testing.T, expected, actual *models.Community) {
+	t.Helper()
+
+	assert.Equal(t, expected.ID, actual.ID)
+	assert.Equal(t, expected.OwnerID, actual.OwnerID)
+	assert.Equal(t, expected.Name, actual.Name)
+	assert.Equal(t, expected.Slug, actual.Slug)
+	assert.Equal(t, expected.Description, actual.Description)
+	assert.Equal(t, expected.AreCommentsAllowed, actual.AreCommentsAllowed)
+	assert.True(t, expected.CreatedAt.Equal(actual.CreatedAt))
+}
+
+func testCommunitiesNotFound(t *testing.T, b *Backend) {
+	ctx := context.Background()
+
+	_, err := b.Communities.GetByID(ctx, uuid.New(), false)
+	assert.ErrorIs(t, err, errs.ErrNotFound)
+	_, err = b.Communities.GetByID(ctx, uuid.New(), true)
+	assert.ErrorIs(t, err, errs.ErrNotFound)
+
+	_, err = b.Communities.GetBySlug(ctx, "missing")
+	assert.ErrorIs(t, err, errs.ErrNotFound)
+
+	_, err = b.Communities.GetMember(ctx, uuid.New(), uuid.New())
+	assert.ErrorIs(t, err, errs.ErrNotFound)
+}
+
+func testCommunitiesDuplicateSlug(t *testing.T, b *Backend) {
+	user := addUser(t, b, "alice")
+	addCommunity(t, b, user.ID, "golang")
+
+	_, err := b.Communities.Add(context.Background(), user.ID, "Another", "golang", "", true)
+	assert.ErrorIs(t, err, errs.ErrAlreadyExists)
+}
+
+func testCommunitiesMembers(t *testing.T, b *Backend) {
+	ctx := context.Background()
+	alice := addUser(t, b, "alice")
+	bob := addUser(t, b, "bob")
+	community := addCommunity(t, b, alice.ID, "golang")
+	empty := addCommunity(t, b, alice.ID, "rust")
+
+	addMember(t, b, community.ID, alice.ID, models.CommunityRoleOwner)
+	addMember(t, b, community.ID, bob.ID, models.CommunityRoleMember)
+
+	// Повторное вступление ничего не меняет
+	added, err := b.Communities.AddMember(ctx, community.ID, bob.ID, models.CommunityRoleModerator)
+	require.NoError(t, err)
+	assert.False(t, added)
+
+	member, err := b.Communities.GetMember(ctx, community.ID, bob.ID)
+	require.NoError(t, err)
+	assert.Equal(t, community.ID, member.CommunityID)
+	assert.Equal(t, bob.ID, member.UserID)
+	assert.Equal(t, models.CommunityRoleMember, member.Role)
+	assert.False(t, member.JoinedAt.IsZero())
+
+	counts, err := b.Communities.CountMembers(ctx, []uuid.UUID{community.ID, empty.ID})
+	require.NoError(t, err)
+	assert.Equal(t, map[uuid.UUID]int32{community.ID: 2, empty.ID: 0}, counts)
+
+	deleted, err := b.Communities.DeleteMember(ctx, community.ID, bob.ID)
+	require.NoError(t, err)
+	assert.True(t, deleted)
+
+	deleted, err = b.Communities.DeleteMember(ctx, community.ID, bob.ID)
+	require.NoError(t, err)
+	assert.False(t, deleted)
+
+	_, err = b.Communities.GetMember(ctx, community.ID, bob.ID)
+	assert.ErrorIs(t, err, errs.ErrNotFound)
+
+	counts, err = b.Communities.CountMembers(ctx, []uuid.UUID{community.ID})
+	require.NoError(t, err)
+	assert.Equal(t, map[uuid.UUID]int32{community.ID: 1}, counts)
+}
+
+func testCommunitiesSetMemberRole(t *testing.T, b *Backend) {
+	ctx := context.Background()
+	alice := addUser(t, b, "alice")
+	bob := addUser(t, b, "bob")
+	community := addCommunity(t, b, alice.ID, "golang")
+	addMember(t, b, community.ID, bob.ID, models.CommunityRoleMember)
+
+	member, err := b.Communities.SetMemberRole(ctx, community.ID, bob.ID, models.CommunityRoleModerator)
+	require.NoError(t, err)
+	assert.Equal(t, bob.ID, member.UserID)
+	assert.Equal(t, models.CommunityRoleModerator, member.Role)
+
+	member, err = b.Communities.GetMember(ctx, community.ID, bob.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.CommunityRoleModerator, member.Role)
+
+	_, err = b.Communities.SetMemberRole(ctx, community.ID, alice.ID, models.CommunityRoleModerator)
+	assert.ErrorIs(t, err, errs.ErrNotFound)
+}
+
+func testCommunitiesModerators(t *testing.T, b *Backend) {
+	ctx := context.Background()
+	owner := addUser(t, b, "owner")
+	community := addCommunity(t, b, owner.ID, "golang")
+	addMember(t, b, community.ID, owner.ID, models.CommunityRoleOwner)
+
+	users := make([]*models.User, 3)
+	for i := range users {
+		users[i] = addUser(t, b, fmt.Sprintf("user%d", i))
+		addMember(t, b, community.ID, users[i].ID, models.CommunityRoleMember)
+	}
+
+	_, err := b.Communities.SetMemberRole(ctx, community.ID, users[2].ID, models.CommunityRoleModerator)
+	require.NoError(t, err)
+	_, err = b.Communities.SetMemberRole(ctx, community.ID, users[0].ID, models.CommunityRoleModerator)
+	require.NoError(t, err)
+
+	// Порядок - по времени вступления, а не назначения
+	moderators, err := b.Communities.GetModerators(ctx, community.ID)
+	require.NoError(t, err)
+	require.Len(t, moderators, 3)
+	assert.Equal(t, owner.ID, moderators[0].UserID)
+	assert.Equal(t, models.CommunityRoleOwner, moderators[0].Role)
+	assert.Equal(t, users[0].ID, moderators[1].UserID)
+	assert.Equal(t, users[2].ID, moderators[2].UserID)
+
+	moderators, err = b.Communities.GetModerators(ctx, uuid.New())
+	require.NoError(t, err)
+	assert.Empty(t, moderators)
+}
+
+func testCommunitiesRollback(t *testing.T, b *Backend) {
+	ctx := context.Background()
+	alice := addUser(t, b, "alice")
+	bob := addUser(t, b, "bob")
+	carol := addUser(t, b, "carol")
+	community := addCommunity(t, b, alice.ID, "golang")
+	addMember(t, b, community.ID, bob.ID, models.CommunityRoleMember)
+	addMember(t, b, community.ID, carol.ID, models.CommunityRoleMember)
+
+	errAbort := errors.New("abort")
+	err := inTx(ctx, b, func(ctx context.Context) error {
+		added, err := b.Communities.Add(ctx, alice.ID, "Rust", "rust", "", true)
+		if err != nil {
+			return err
+		}
+		_, err = b.Communities.AddMember(ctx, added.ID, alice.ID, models.CommunityRoleOwner)
+		if err != nil {
+			return err
+		}
+		_, err = b.Communities.AddMember(ctx, community.ID, alice.ID, models.CommunityRoleOwner)
+		if err != nil {
+			return err
+		}
+		_, err = b.Communities.SetMemberRole(ctx, community.ID, bob.ID, models.CommunityRoleModerator)
+		if err != nil {
+			return err
+		}
+		_, err = b.Communities.DeleteMember(ctx, community.ID, carol.ID)
+		if err != nil {
+			return err
+		}
+		return errAbort
+	})
+	require.ErrorIs(t, err, errAbort)
+
+	_, err = b.Communities.GetBySlug(ctx, "rust")
+	assert.ErrorIs(t, err, errs.ErrNotFound)
+
+	_, err = b.Communities.GetMember(ctx, community.ID, alice.ID)
+	assert.ErrorIs(t, err, errs.ErrNotFound)
+
+	member, err := b.Communities.GetMember(ctx, community.ID, bob.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.CommunityRoleMember, member.Role)
+
+	_, err = b.Communities.GetMember(ctx, community.ID, carol.ID)
+	assert.NoError(t, err)
+
+	// Slug отмененного сообщества снова свободен
+	addCommunity(t, b, alice.ID, "rust")
+}
+
+func testCommunitiesPosts(t *testing.T, b *Backend) {
+	ctx := context.Background()
+	user := addUser(t, b, "alice")
+	community := addCommunity(t, b, user.ID, "golang")
+	other := addCommunity(t, b, user.ID, "rust")
+
+	older := addCommunityPost(t, b, user.ID, community.ID, "older")
+	addCommunityPost(t, b, user.ID, other.ID, "other")
+	outside := addPost(t, b, user.ID, "outside")
+	newer := addCommunityPost(t, b, user.ID, community.ID, "newer")
+	deleted := addCommunityPost(t, b, user.ID, community.ID, "deleted")
+
+	_, err := b.Posts.SoftDelete(ctx, deleted.ID)
+	require.NoError(t, err)
+
+	post, err := b.Posts.GetByID(ctx, older.ID, false)
+	require.NoError(t, err)
+	require.NotNil(t, post.CommunityID)
+	assert.Equal(t, community.ID, *post.CommunityID)
+	assert.Nil(t, outside.CommunityID)
+
+	page, err := b.Posts.GetPageByCommunity(ctx, community.ID, &pagination.Page{Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, newestFirst(older, newer), idsOf(page))
+	for _, post := range page {
+		require.NotNil(t, post.CommunityID)
+		assert.Equal(t, community.ID, *post.CommunityID)
+	}
+
+	// Общая лента включает посты сообществ
+	all, err := b.Posts.GetPage(ctx, &pagination.Page{Limit: 10})
+	require.NoError(t, err)
+	assert.Len(t, all, 4)
+}
+
+func testCommunitiesPostsPagination(t *testing.T, b *Backend) {
+	user := addUser(t, b, "alice")
+	community := addCommunity(t, b, user.ID, "golang")
+
+	posts := make([]*models.Post, 5)
+	for i := range posts {
+		posts[i] = addCommunityPost(t, b, user.ID, community.ID, fmt.Sprintf("post %d", i))
+		addPost(t, b, user.ID, fmt.Sprintf("outside %d", i))
+	}
+
+	testPagination(t, newestFirst(posts...), posts, func(page *pagination.Page) ([]*models.Post, error) {
+		return b.Posts.GetPageByCommunity(context.Background(), community.ID, page)
+	})
+}
@@ -0,0 +1,182 @@
+// Package conformance содержит общие поведенческие тесты репозиториев.
+// Каждое хранилище запускает их через Run на своей реализации, чтобы
+// порядок выборок, границы страниц, ошибки и работа с транзакциями
+// совпадали во всех хранилищах
+package conformance
+
+import (
+	"bytes"
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/Govorov1705/ozon-test/internal/models"
+	"github.com/Govorov1705/ozon-test/internal/repositories"
+	"github.com/Govorov1705/ozon-test/internal/transactions"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+// Backend - репозитории одного хранилища поверх общей базы
+type Backend struct {
+	TxStarter     transactions.TxStarter
+	Users         repositories.UsersRepository
+	RefreshTokens repositories.RefreshTokensRepository
+	Posts         repositories.PostsRepository
+	Comments      repositories.CommentsRepository
+	Communities   repositories.CommunitiesRepository
+	Outbox        repositories.OutboxRepository
+}
+
+// NewBackend создает хранилище без данных. Ресурсы хранилища
+// освобождаются через t.Cleanup
+type NewBackend func(t *testing.T) *Backend
+
+type testCase struct {
+	name string
+	run  func(t *testing.T, b *Backend)
+}
+
+// Run запускает все тесты, каждый на новом хранилище
+func Run(t *testing.T, newBackend NewBackend) {
+	suites := []struct {
+		name  string
+		tests []testCase
+	}{
+		{"Users", usersTests},
+		{"RefreshTokens", refreshTokensTests},
+		{"Posts", postsTests},
+		{"Comments", commentsTests},
+		{"Votes", votesTests},
+		{"Reactions", reactionsTests},
+		{"Search", searchTests},
+		{"Tags", tagsTests},
+		{"Communities", communitiesTests},
+		{"Outbox", outboxTests},
+		{"Transactions", transactionsTests},
+	}
+
+	for _, suite := range suites {
+		t.Run(suite.name, func(t *testing.T) {
+			for _, tc := range suite.tests {
+				t.Run(tc.name, func(t *testing.T) {
+					tc.run(t, newBackend(t))
+				})
+			}
+		})
+	}
+}
+
+// inTx выполняет fn в транзакции так же, как сервисы: коммит при
+// успехе, откат при ошибке
+func inTx(ctx context.Context, b *Backend, fn func(ctx context.Context) error) (err error) {
+	tx, err := b.TxStarter.Begin(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback(ctx)
+			panic(p)
+		} else if err != nil {
+			tx.Rollback(ctx)
+		} else {
+			err = tx.Commit(ctx)
+		}
+	}()
+
+	return fn(transactions.PutTxIntoContext(ctx, tx))
+}
+
+func addUser(t *testing.T, b *Backend, username string) *models.User {
+	t.Helper()
+
+	user, err := b.Users.Add(context.Background(), username, "hashed-"+username)
+	require.NoError(t, err)
+
+	return user
+}
+
+func addPost(t *testing.T, b *Backend, userID uuid.UUID, title string) *models.Post {
+	t.Helper()
+
+	post, err := b.Posts.Add(context.Background(), userID, nil, title, "content of "+title, true)
+	require.NoError(t, err)
+
+	return post
+}
+
+// addComment создает комментарий к посту или ответ на parent
+func addComment(t *testing.T, b *Backend, post *models.Post, userID uuid.UUID, parent *models.Comment, content string) *models.Comment {
+	t.Helper()
+
+	var rootID, replyTo *uuid.UUID
+	if parent != nil {
+		rootID = &parent.RootID
+		replyTo = &parent.ID
+	}
+
+	comment, err := b.Comments.Add(context.Background(), post.ID, userID, rootID, replyTo, content)
+	require.NoError(t, err)
+
+	return comment
+}
+
+// keyed - запись, упорядочиваемая по (created_at, id)
+type keyed interface {
+	*models.Post | *models.Comment | *models.OutboxEvent | *models.SearchHit
+}
+
+func keyOf[T keyed](item T) (createdAt time.Time, ID uuid.UUID) {
+	switch v := any(item).(type) {
+	case *models.Post:
+		return v.CreatedAt, v.ID
+	case *models.Comment:
+		return v.CreatedAt, v.ID
+	case *models.OutboxEvent:
+		return v.CreatedAt, v.ID
+	case *models.SearchHit:
+		_, createdAt, ID := v.Key()
+		return createdAt, ID
+	}
+	panic("unreachable")
+}
+
+func compareKeys[T keyed](a, b T) int {
+	aCreatedAt, aID := keyOf(a)
+	bCreatedAt, bID := keyOf(b)
+	if cmp := aCreatedAt.Compare(bCreatedAt); cmp != 0 {
+		return cmp
+	}
+	return bytes.Compare(aID[:], bID[:])
+}
+
+// oldestFirst возвращает ID в порядке (created_at, id)
+func oldestFirst[T keyed](items ...T) []uuid.UUID {
+	sorted := slices.Clone(items)
+	slices.SortFunc(sorted, compareKeys)
+
+	return idsOf(sorted)
+}
+
+// newestFirst возвращает ID в порядке (created_at DESC, id DESC), которым
+// упорядочены страницы. Ожидаемый порядок берется из созданных записей,
+// поэтому не зависит от точности часов хранилища
+func newestFirst[T keyed](items ...T) []uuid.UUID {
+	sorted := slices.Clone(items)
+	slices.SortFunc(sorted, func(a, b T) int { return compareKeys(b, a) })
+
+	return idsOf(sorted)
+}
+
+// idsOf возвращает ID записей в исходном порядке
+func idsOf[T keyed](items []T) []uuid.UUID {
+	result := make([]uuid.UUID, len(items))
+	for i, item := range items {
+		_, result[i] = keyOf(item)
+	}
+
+	return result
+}
@@ -0,0 +1,116 @@
+package conformance
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Govorov1705/ozon-test/internal/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var outboxTests = []testCase{
+	{"GetUnprocessedOrder", testOutboxGetUnprocessedOrder},
+	{"MarkProcessed", testOutboxMarkProcessed},
+	{"DeleteProcessedBefore", testOutboxDeleteProcessedBefore},
+}
+
+// addEvents добавляет count событий и возвращает их в том виде,
+// в каком их отдает GetUnprocessed
+func addEvents(t *testing.T, b *Backend, count int) []*models.OutboxEvent {
+	t.Helper()
+
+	ctx := context.Background()
+
+	added := make(map[uuid.UUID]struct{}, count)
+	for i := range count {
+		ID := uuid.New()
+		payload := fmt.Appendf(nil, `{"n":%d}`, i)
+		require.NoError(t, b.Outbox.Add(ctx, ID, models.EventCommentAdded, payload))
+		added[ID] = struct{}{}
+	}
+
+	unprocessed, err := b.Outbox.GetUnprocessed(ctx, int32(len(added)+100))
+	require.NoError(t, err)
+
+	events := []*models.OutboxEvent{}
+	for _, event := range unprocessed {
+		if _, ok := added[event.ID]; ok {
+			events = append(events, event)
+		}
+	}
+	require.Len(t, events, count)
+
+	return events
+}
+
+func testOutboxGetUnprocessedOrder(t *testing.T, b *Backend) {
+	ctx := context.Background()
+
+	ID := uuid.New()
+	require.NoError(t, b.Outbox.Add(ctx, ID, models.EventCommentAdded, []byte(`{"n":0}`)))
+
+	events, err := b.Outbox.GetUnprocessed(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, ID, events[0].ID)
+	assert.Equal(t, models.EventCommentAdded, events[0].Type)
+	assert.JSONEq(t, `{"n":0}`, string(events[0].Payload))
+	assert.False(t, events[0].CreatedAt.IsZero())
+	assert.Nil(t, events[0].ProcessedAt)
+
+	events = append(events, addEvents(t, b, 5)...)
+
+	// События отдаются от старых к новым
+	all, err := b.Outbox.GetUnprocessed(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, oldestFirst(events...), idsOf(all))
+
+	limited, err := b.Outbox.GetUnprocessed(ctx, 3)
+	require.NoError(t, err)
+	assert.Equal(t, oldestFirst(events...)[:3], idsOf(limited))
+}
+
+func testOutboxMarkProcessed(t *testing.T, b *Backend) {
+	ctx := context.Background()
+	events := addEvents(t, b, 5)
+	IDs := oldestFirst(events...)
+
+	require.NoError(t, b.Outbox.MarkProcessed(ctx, IDs[:2]))
+
+	unprocessed, err := b.Outbox.GetUnprocessed(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, IDs[2:], idsOf(unprocessed))
+
+	require.NoError(t, b.Outbox.MarkProcessed(ctx, []uuid.UUID{}))
+	unprocessed, err = b.Outbox.GetUnprocessed(ctx, 10)
+	require.NoError(t, err)
+	assert.Len(t, unprocessed, 3)
+}
+
+func testOutboxDeleteProcessedBefore(t *testing.T, b *Backend) {
+	ctx := context.Background()
+	events := addEvents(t, b, 3)
+	IDs := oldestFirst(events...)
+
+	require.NoError(t, b.Outbox.MarkProcessed(ctx, IDs[:2]))
+
+	// Обработанные позже границы события остаются: повторно добавить
+	// событие с тем же ID нельзя
+	require.NoError(t, b.Outbox.DeleteProcessedBefore(ctx, time.Now().Add(-time.Hour)))
+	assert.Error(t, b.Outbox.Add(ctx, IDs[0], models.EventCommentAdded, []byte(`{}`)))
+
+	require.NoError(t, b.Outbox.DeleteProcessedBefore(ctx, time.Now().Add(time.Hour)))
+	for _, ID := range IDs[:2] {
+		assert.NoError(t, b.Outbox.Add(ctx, ID, models.EventCommentAdded, []byte(`{}`)))
+	}
+
+	// Необработанные события не удаляются независимо от возраста
+	unprocessed, err := b.Outbox.GetUnprocessed(ctx, 10)
+	require.NoError(t, err)
+	assert.Contains(t, idsOf(unprocessed), IDs[2])
+	assert.Len(t, unprocessed, 3)
+}
@@ -0,0 +1,118 @@
+package conformance
+
+import (
+	"testing"
+
+	"github.com/Govorov1705/ozon-test/internal/models"
+	"github.com/Govorov1705/ozon-test/internal/pagination"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testPagination проверяет границы keyset-страниц на выборке из пяти
+// записей. expected - ID записей в порядке (created_at DESC, id DESC)
+func testPagination[T keyed](t *testing.T, expected []uuid.UUID, items []T, fetch func(page *pagination.Page) ([]T, error)) {
+	t.Helper()
+	testSortedPagination(t, pagination.SortNew, expected, items, fetch)
+}
+
+// testSortedPagination - testPagination для выборки с порядком sort.
+// expected - ID записей в этом порядке. В ранжированных выборках
+// записи - комментарии или результаты поиска, и курсор включает их рейтинг
+func testSortedPagination[T keyed](t *testing.T, sort pagination.Sort, expected []uuid.UUID, items []T, fetch func(page *pagination.Page) ([]T, error)) {
+	t.Helper()
+	require.Len(t, expected, 5)
+
+	cursor := func(ID uuid.UUID) *pagination.Cursor {
+		for _, item := range items {
+			createdAt, itemID := keyOf(item)
+			if itemID != ID {
+				continue
+			}
+			c := &pagination.Cursor{CreatedAt: createdAt, ID: itemID}
+			if sort.Ranked() {
+				c.Ranked = true
+				c.Rank = rankOf(item, sort)
+			}
+			return c
+		}
+		t.Fatalf("no item with ID %s", ID)
+		return nil
+	}
+
+	// Page хранит порядок по умолчанию пустым значением
+	pageSort := sort
+	if sort == pagination.SortNew {
+		pageSort = ""
+	}
+
+	testCases := []struct {
+		name     string
+		page     *pagination.Page
+		expected []uuid.UUID
+	}{
+		{
+			name:     "first page",
+			page:     &pagination.Page{Limit: 2},
+			expected: expected[:2],
+		},
+		{
+			name:     "after cursor",
+			page:     &pagination.Page{After: cursor(expected[1]), Limit: 2},
+			expected: expected[2:4],
+		},
+		{
+			name:     "last partial page",
+			page:     &pagination.Page{After: cursor(expected[3]), Limit: 2},
+			expected: expected[4:],
+		},
+		{
+			name:     "after last item",
+			page:     &pagination.Page{After: cursor(expected[4]), Limit: 2},
+			expected: []uuid.UUID{},
+		},
+		{
+			name:     "limit above total",
+			page:     &pagination.Page{Limit: 10},
+			expected: expected,
+		},
+		{
+			name:     "backward from the end",
+			page:     &pagination.Page{Limit: 2, Backward: true},
+			expected: []uuid.UUID{expected[4], expected[3]},
+		},
+		{
+			name:     "backward before cursor",
+			page:     &pagination.Page{Before: cursor(expected[2]), Limit: 2, Backward: true},
+			expected: []uuid.UUID{expected[1], expected[0]},
+		},
+		{
+			name:     "before first item",
+			page:     &pagination.Page{Before: cursor(expected[0]), Limit: 2, Backward: true},
+			expected: []uuid.UUID{},
+		},
+		{
+			name:     "between cursors",
+			page:     &pagination.Page{After: cursor(expected[0]), Before: cursor(expected[4]), Limit: 10},
+			expected: expected[1:4],
+		},
+	}
+
+	for _, tc := range testCases {
+		tc.page.Sort = pageSort
+		got, err := fetch(tc.page)
+		require.NoError(t, err, tc.name)
+		assert.Equal(t, tc.expected, idsOf(got), tc.name)
+	}
+}
+
+func rankOf[T keyed](item T, sort pagination.Sort) float64 {
+	switch v := any(item).(type) {
+	case *models.Comment:
+		return v.Rank(sort)
+	case *models.SearchHit:
+		return v.Rank
+	}
+	panic("item is not ranked")
+}
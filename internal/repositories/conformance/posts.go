@@ -0,0 +1,194 @@
+package conformance
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/Govorov1705/ozon-test/internal/errs"
+	"github.com/Govorov1705/ozon-test/internal/models"
+	"github.com/Govorov1705/ozon-test/internal/pagination"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var postsTests = []testCase{
+	{"AddAndGet", testPostsAddAndGet},
+	{"NotFound", testPostsNotFound},
+	{"Update", testPostsUpdate},
+	{"ToggleComments", testPostsToggleComments},
+	{"SoftDelete", testPostsSoftDelete},
+	{"GetAllOrder", testPostsGetAllOrder},
+	{"Pagination", testPostsPagination},
+	{"CountByUserIDs", testPostsCountByUserIDs},
+	{"ReturnsCopies", testPostsReturnsCopies},
+}
+
+func testPostsAddAndGet(t *testing.T, b *Backend) {
+	ctx := context.Background()
+	user := addUser(t, b, "alice")
+
+	post, err := b.Posts.Add(ctx, user.ID, nil, "title", "content", false)
+	require.NoError(t, err)
+	assert.NotEqual(t, uuid.Nil, post.ID)
+	assert.Equal(t, user.ID, post.UserID)
+	assert.Equal(t, "title", post.Title)
+	assert.Equal(t, "content", post.Content)
+	assert.False(t, post.AreCommentsAllowed)
+	assert.False(t, post.CreatedAt.IsZero())
+	assert.Nil(t, post.UpdatedAt)
+	assert.Nil(t, post.DeletedAt)
+
+	for _, forUpdate := range []bool{false, true} {
+		got, err := b.Posts.GetByID(ctx, post.ID, forUpdate)
+		require.NoError(t, err)
+		assert.Equal(t, post.ID, got.ID)
+		assert.Equal(t, post.Title, got.Title)
+		assert.Equal(t, post.Content, got.Content)
+		assert.Equal(t, post.AreCommentsAllowed, got.AreCommentsAllowed)
+		assert.True(t, post.CreatedAt.Equal(got.CreatedAt))
+	}
+}
+
+func testPostsNotFound(t *testing.T, b *Backend) {
+	ctx := context.Background()
+	ID := uuid.New()
+
+	_, err := b.Posts.GetByID(ctx, ID, false)
+	assert.ErrorIs(t, err, errs.ErrNotFound)
+	_, err = b.Posts.GetByID(ctx, ID, true)
+	assert.ErrorIs(t, err, errs.ErrNotFound)
+	_, err = b.Posts.DisableComments(ctx, ID)
+	assert.ErrorIs(t, err, errs.ErrNotFound)
+	_, err = b.Posts.EnableComments(ctx, ID)
+	assert.ErrorIs(t, err, errs.ErrNotFound)
+	_, err = b.Posts.Update(ctx, ID, "title", "content")
+	assert.ErrorIs(t, err, errs.ErrNotFound)
+	_, err = b.Posts.SoftDelete(ctx, ID)
+	assert.ErrorIs(t, err, errs.ErrNotFound)
+}
+
+func testPostsUpdate(t *testing.T, b *Backend) {
+	ctx := context.Background()
+	user := addUser(t, b, "alice")
+	post := addPost(t, b, user.ID, "title")
+
+	updated, err := b.Posts.Update(ctx, post.ID, "new title", "new content")
+	require.NoError(t, err)
+	assert.Equal(t, "new title", updated.Title)
+	assert.Equal(t, "new content", updated.Content)
+	assert.NotNil(t, updated.UpdatedAt)
+	assert.True(t, post.CreatedAt.Equal(updated.CreatedAt))
+
+	got, err := b.Posts.GetByID(ctx, post.ID, false)
+	require.NoError(t, err)
+	assert.Equal(t, "new title", got.Title)
+	assert.NotNil(t, got.UpdatedAt)
+}
+
+func testPostsToggleComments(t *testing.T, b *Backend) {
+	ctx := context.Background()
+	user := addUser(t, b, "alice")
+	post := addPost(t, b, user.ID, "title")
+
+	disabled, err := b.Posts.DisableComments(ctx, post.ID)
+	require.NoError(t, err)
+	assert.False(t, disabled.AreCommentsAllowed)
+
+	got, err := b.Posts.GetByID(ctx, post.ID, false)
+	require.NoError(t, err)
+	assert.False(t, got.AreCommentsAllowed)
+
+	enabled, err := b.Posts.EnableComments(ctx, post.ID)
+	require.NoError(t, err)
+	assert.True(t, enabled.AreCommentsAllowed)
+}
+
+func testPostsSoftDelete(t *testing.T, b *Backend) {
+	ctx := context.Background()
+	user := addUser(t, b, "alice")
+	post := addPost(t, b, user.ID, "deleted")
+	kept := addPost(t, b, user.ID, "kept")
+
+	deleted, err := b.Posts.SoftDelete(ctx, post.ID)
+	require.NoError(t, err)
+	assert.Empty(t, deleted.Title)
+	assert.Empty(t, deleted.Content)
+	assert.NotNil(t, deleted.DeletedAt)
+
+	// Удаленный пост доступен по ID, но не попадает в списки
+	got, err := b.Posts.GetByID(ctx, post.ID, false)
+	require.NoError(t, err)
+	assert.NotNil(t, got.DeletedAt)
+
+	all, err := b.Posts.GetAll(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{kept.ID}, idsOf(all))
+
+	page, err := b.Posts.GetPage(ctx, &pagination.Page{Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{kept.ID}, idsOf(page))
+}
+
+func testPostsGetAllOrder(t *testing.T, b *Backend) {
+	user := addUser(t, b, "alice")
+
+	posts := make([]*models.Post, 5)
+	for i := range posts {
+		posts[i] = addPost(t, b, user.ID, fmt.Sprintf("post %d", i))
+	}
+
+	all, err := b.Posts.GetAll(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, newestFirst(posts...), idsOf(all))
+}
+
+func testPostsPagination(t *testing.T, b *Backend) {
+	user := addUser(t, b, "alice")
+
+	posts := make([]*models.Post, 5)
+	for i := range posts {
+		posts[i] = addPost(t, b, user.ID, fmt.Sprintf("post %d", i))
+	}
+
+	testPagination(t, newestFirst(posts...), posts, func(page *pagination.Page) ([]*models.Post, error) {
+		return b.Posts.GetPage(context.Background(), page)
+	})
+}
+
+func testPostsCountByUserIDs(t *testing.T, b *Backend) {
+	ctx := context.Background()
+	alice := addUser(t, b, "alice")
+	bob := addUser(t, b, "bob")
+	carol := addUser(t, b, "carol")
+
+	addPost(t, b, alice.ID, "first")
+	addPost(t, b, alice.ID, "second")
+	deleted := addPost(t, b, alice.ID, "deleted")
+	addPost(t, b, bob.ID, "bob's")
+	_, err := b.Posts.SoftDelete(ctx, deleted.ID)
+	require.NoError(t, err)
+
+	counts, err := b.Posts.CountByUserIDs(ctx, []uuid.UUID{alice.ID, bob.ID, carol.ID})
+	require.NoError(t, err)
+	assert.Equal(t, map[uuid.UUID]int32{alice.ID: 2, bob.ID: 1, carol.ID: 0}, counts)
+}
+
+// Изменение возвращенной записи не должно менять хранилище, а записи,
+// прочитанные раньше, не должны меняться при последующих изменениях
+func testPostsReturnsCopies(t *testing.T, b *Backend) {
+	ctx := context.Background()
+	user := addUser(t, b, "alice")
+	post := addPost(t, b, user.ID, "title")
+
+	post.Title = "changed by caller"
+	got, err := b.Posts.GetByID(ctx, post.ID, false)
+	require.NoError(t, err)
+	assert.Equal(t, "title", got.Title)
+
+	_, err = b.Posts.Update(ctx, post.ID, "updated", "content")
+	require.NoError(t, err)
+	assert.Equal(t, "title", got.Title)
+	assert.Nil(t, got.UpdatedAt)
+}
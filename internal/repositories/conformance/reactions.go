@@ -0,0 +1,177 @@
+package conformance
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Govorov1705/ozon-test/internal/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var reactionsTests = []testCase{
+	{"AddDelete", testReactionsAddDelete},
+	{"Counts", testReactionsCounts},
+	{"Rollback", testReactionsRollback},
+	{"DeletedWithComment", testReactionsDeletedWithComment},
+}
+
+// reactionsRepo - общая часть PostsRepository и CommentsRepository
+type reactionsRepo interface {
+	AddReaction(ctx context.Context, targetID, userID uuid.UUID, emoji string) (bool, error)
+	DeleteReaction(ctx context.Context, targetID, userID uuid.UUID, emoji string) (bool, error)
+	GetReactions(ctx context.Context, targetIDs []uuid.UUID, viewerID *uuid.UUID) (map[uuid.UUID][]*models.ReactionCount, error)
+}
+
+// forEachTarget запускает тест реакций и для постов, и для комментариев
+func forEachTarget(t *testing.T, b *Backend, test func(t *testing.T, repo reactionsRepo, newTarget func() uuid.UUID)) {
+	user := addUser(t, b, "author")
+	post := addPost(t, b, user.ID, "post")
+
+	t.Run("Posts", func(t *testing.T) {
+		test(t, b.Posts, func() uuid.UUID {
+			return addPost(t, b, user.ID, "target").ID
+		})
+	})
+	t.Run("Comments", func(t *testing.T) {
+		test(t, b.Comments, func() uuid.UUID {
+			return addComment(t, b, post, user.ID, nil, "target").ID
+		})
+	})
+}
+
+func reactionsOf(t *testing.T, repo reactionsRepo, targetID uuid.UUID, viewerID *uuid.UUID) []models.ReactionCount {
+	t.Helper()
+
+	reactions, err := repo.GetReactions(context.Background(), []uuid.UUID{targetID}, viewerID)
+	require.NoError(t, err)
+
+	counts := []models.ReactionCount{}
+	for _, reaction := range reactions[targetID] {
+		counts = append(counts, *reaction)
+	}
+
+	return counts
+}
+
+func testReactionsAddDelete(t *testing.T, b *Backend) {
+	alice := addUser(t, b, "alice")
+
+	forEachTarget(t, b, func(t *testing.T, repo reactionsRepo, newTarget func() uuid.UUID) {
+		ctx := context.Background()
+		targetID := newTarget()
+
+		added, err := repo.AddReaction(ctx, targetID, alice.ID, "👍")
+		require.NoError(t, err)
+		assert.True(t, added)
+
+		added, err = repo.AddReaction(ctx, targetID, alice.ID, "👍")
+		require.NoError(t, err)
+		assert.False(t, added)
+
+		assert.Equal(t, []models.ReactionCount{{Emoji: "👍", Count: 1, ViewerHasReacted: true}}, reactionsOf(t, repo, targetID, &alice.ID))
+
+		deleted, err := repo.DeleteReaction(ctx, targetID, alice.ID, "👍")
+		require.NoError(t, err)
+		assert.True(t, deleted)
+
+		deleted, err = repo.DeleteReaction(ctx, targetID, alice.ID, "👍")
+		require.NoError(t, err)
+		assert.False(t, deleted)
+
+		assert.Empty(t, reactionsOf(t, repo, targetID, &alice.ID))
+	})
+}
+
+func testReactionsCounts(t *testing.T, b *Backend) {
+	alice := addUser(t, b, "alice")
+	bob := addUser(t, b, "bob")
+
+	forEachTarget(t, b, func(t *testing.T, repo reactionsRepo, newTarget func() uuid.UUID) {
+		ctx := context.Background()
+		first, second, empty := newTarget(), newTarget(), newTarget()
+
+		for _, reaction := range []struct {
+			targetID uuid.UUID
+			userID   uuid.UUID
+			emoji    string
+		}{
+			{first, alice.ID, "🎉"},
+			{first, bob.ID, "👍"},
+			{first, bob.ID, "🎉"},
+			{second, bob.ID, "👀"},
+		} {
+			_, err := repo.AddReaction(ctx, reaction.targetID, reaction.userID, reaction.emoji)
+			require.NoError(t, err)
+		}
+
+		reactions, err := repo.GetReactions(ctx, []uuid.UUID{first, second, empty}, &alice.ID)
+		require.NoError(t, err)
+		require.Len(t, reactions, 3)
+		assert.Empty(t, reactions[empty])
+
+		// Эмодзи идут в порядке первой реакции ими
+		require.Len(t, reactions[first], 2)
+		assert.Equal(t, models.ReactionCount{Emoji: "🎉", Count: 2, ViewerHasReacted: true}, *reactions[first][0])
+		assert.Equal(t, models.ReactionCount{Emoji: "👍", Count: 1, ViewerHasReacted: false}, *reactions[first][1])
+
+		require.Len(t, reactions[second], 1)
+		assert.Equal(t, models.ReactionCount{Emoji: "👀", Count: 1, ViewerHasReacted: false}, *reactions[second][0])
+
+		assert.Equal(t, []models.ReactionCount{
+			{Emoji: "🎉", Count: 2},
+			{Emoji: "👍", Count: 1},
+		}, reactionsOf(t, repo, first, nil))
+	})
+}
+
+func testReactionsRollback(t *testing.T, b *Backend) {
+	alice := addUser(t, b, "alice")
+	bob := addUser(t, b, "bob")
+
+	forEachTarget(t, b, func(t *testing.T, repo reactionsRepo, newTarget func() uuid.UUID) {
+		ctx := context.Background()
+		targetID := newTarget()
+
+		_, err := repo.AddReaction(ctx, targetID, alice.ID, "👍")
+		require.NoError(t, err)
+
+		errAbort := errors.New("abort")
+		err = inTx(ctx, b, func(ctx context.Context) error {
+			_, err := repo.DeleteReaction(ctx, targetID, alice.ID, "👍")
+			if err != nil {
+				return err
+			}
+			_, err = repo.AddReaction(ctx, targetID, bob.ID, "🚀")
+			if err != nil {
+				return err
+			}
+			return errAbort
+		})
+		require.ErrorIs(t, err, errAbort)
+
+		assert.Equal(t, []models.ReactionCount{{Emoji: "👍", Count: 1}}, reactionsOf(t, repo, targetID, nil))
+	})
+}
+
+func testReactionsDeletedWithComment(t *testing.T, b *Backend) {
+	ctx := context.Background()
+	user := addUser(t, b, "alice")
+	post := addPost(t, b, user.ID, "post")
+	root := addComment(t, b, post, user.ID, nil, "root")
+	reply := addComment(t, b, post, user.ID, root, "reply")
+
+	for _, comment := range []*models.Comment{root, reply} {
+		_, err := b.Comments.AddReaction(ctx, comment.ID, user.ID, "👍")
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, b.Comments.Delete(ctx, root.ID))
+
+	reactions, err := b.Comments.GetReactions(ctx, []uuid.UUID{root.ID, reply.ID}, nil)
+	require.NoError(t, err)
+	assert.Empty(t, reactions[root.ID])
+	assert.Empty(t, reactions[reply.ID])
+}
@@ -0,0 +1,151 @@
+package conformance
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Govorov1705/ozon-test/internal/errs"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var refreshTokensTests = []testCase{
+	{"AddAndGet", testRefreshTokensAddAndGet},
+	{"DuplicateHash", testRefreshTokensDuplicateHash},
+	{"NotFound", testRefreshTokensNotFound},
+	{"Revoke", testRefreshTokensRevoke},
+	{"RevokeSession", testRefreshTokensRevokeSession},
+	{"RevokeAllByUserID", testRefreshTokensRevokeAllByUserID},
+}
+
+func testRefreshTokensAddAndGet(t *testing.T, b *Backend) {
+	ctx := context.Background()
+	user := addUser(t, b, "alice")
+
+	ID, sessionID := uuid.New(), uuid.New()
+	expiresAt := time.Now().Add(time.Hour).Truncate(time.Microsecond)
+
+	token, err := b.RefreshTokens.Add(ctx, ID, sessionID, user.ID, "hash", expiresAt)
+	require.NoError(t, err)
+	assert.Equal(t, ID, token.ID)
+	assert.Equal(t, sessionID, token.SessionID)
+	assert.Equal(t, user.ID, token.UserID)
+	assert.Equal(t, "hash", token.TokenHash)
+	assert.True(t, expiresAt.Equal(token.ExpiresAt))
+	assert.Nil(t, token.RevokedAt)
+	assert.False(t, token.CreatedAt.IsZero())
+
+	byID, err := b.RefreshTokens.GetByID(ctx, ID)
+	require.NoError(t, err)
+	assert.Equal(t, "hash", byID.TokenHash)
+
+	for _, forUpdate := range []bool{false, true} {
+		byHash, err := b.RefreshTokens.GetByTokenHash(ctx, "hash", forUpdate)
+		require.NoError(t, err)
+		assert.Equal(t, ID, byHash.ID)
+	}
+}
+
+func testRefreshTokensDuplicateHash(t *testing.T, b *Backend) {
+	ctx := context.Background()
+	user := addUser(t, b, "alice")
+	expiresAt := time.Now().Add(time.Hour)
+
+	_, err := b.RefreshTokens.Add(ctx, uuid.New(), uuid.New(), user.ID, "hash", expiresAt)
+	require.NoError(t, err)
+
+	_, err = b.RefreshTokens.Add(ctx, uuid.New(), uuid.New(), user.ID, "hash", expiresAt)
+	assert.ErrorIs(t, err, errs.ErrAlreadyExists)
+}
+
+func testRefreshTokensNotFound(t *testing.T, b *Backend) {
+	ctx := context.Background()
+
+	_, err := b.RefreshTokens.GetByID(ctx, uuid.New())
+	assert.ErrorIs(t, err, errs.ErrNotFound)
+
+	_, err = b.RefreshTokens.GetByTokenHash(ctx, "missing", false)
+	assert.ErrorIs(t, err, errs.ErrNotFound)
+
+	// Отзыв неизвестного токена ничего не делает, как UPDATE без строк
+	assert.NoError(t, b.RefreshTokens.Revoke(ctx, uuid.New()))
+}
+
+func testRefreshTokensRevoke(t *testing.T, b *Backend) {
+	ctx := context.Background()
+	user := addUser(t, b, "alice")
+	expiresAt := time.Now().Add(time.Hour)
+	sessionID := uuid.New()
+
+	revoked, err := b.RefreshTokens.Add(ctx, uuid.New(), sessionID, user.ID, "revoked", expiresAt)
+	require.NoError(t, err)
+	kept, err := b.RefreshTokens.Add(ctx, uuid.New(), sessionID, user.ID, "kept", expiresAt)
+	require.NoError(t, err)
+
+	require.NoError(t, b.RefreshTokens.Revoke(ctx, revoked.ID))
+
+	got, err := b.RefreshTokens.GetByID(ctx, revoked.ID)
+	require.NoError(t, err)
+	require.NotNil(t, got.RevokedAt)
+	firstRevokedAt := *got.RevokedAt
+
+	// Повторный отзыв не меняет время первого
+	require.NoError(t, b.RefreshTokens.Revoke(ctx, revoked.ID))
+	got, err = b.RefreshTokens.GetByID(ctx, revoked.ID)
+	require.NoError(t, err)
+	assert.True(t, firstRevokedAt.Equal(*got.RevokedAt))
+
+	got, err = b.RefreshTokens.GetByID(ctx, kept.ID)
+	require.NoError(t, err)
+	assert.Nil(t, got.RevokedAt)
+}
+
+func testRefreshTokensRevokeSession(t *testing.T, b *Backend) {
+	ctx := context.Background()
+	user := addUser(t, b, "alice")
+	expiresAt := time.Now().Add(time.Hour)
+	sessionID, otherSessionID := uuid.New(), uuid.New()
+
+	first, err := b.RefreshTokens.Add(ctx, uuid.New(), sessionID, user.ID, "first", expiresAt)
+	require.NoError(t, err)
+	second, err := b.RefreshTokens.Add(ctx, uuid.New(), sessionID, user.ID, "second", expiresAt)
+	require.NoError(t, err)
+	other, err := b.RefreshTokens.Add(ctx, uuid.New(), otherSessionID, user.ID, "other", expiresAt)
+	require.NoError(t, err)
+
+	require.NoError(t, b.RefreshTokens.RevokeSession(ctx, sessionID))
+
+	assertRevoked(t, b, first.ID, true)
+	assertRevoked(t, b, second.ID, true)
+	assertRevoked(t, b, other.ID, false)
+}
+
+func testRefreshTokensRevokeAllByUserID(t *testing.T, b *Backend) {
+	ctx := context.Background()
+	alice := addUser(t, b, "alice")
+	bob := addUser(t, b, "bob")
+	expiresAt := time.Now().Add(time.Hour)
+
+	first, err := b.RefreshTokens.Add(ctx, uuid.New(), uuid.New(), alice.ID, "first", expiresAt)
+	require.NoError(t, err)
+	second, err := b.RefreshTokens.Add(ctx, uuid.New(), uuid.New(), alice.ID, "second", expiresAt)
+	require.NoError(t, err)
+	other, err := b.RefreshTokens.Add(ctx, uuid.New(), uuid.New(), bob.ID, "other", expiresAt)
+	require.NoError(t, err)
+
+	require.NoError(t, b.RefreshTokens.RevokeAllByUserID(ctx, alice.ID))
+
+	assertRevoked(t, b, first.ID, true)
+	assertRevoked(t, b, second.ID, true)
+	assertRevoked(t, b, other.ID, false)
+}
+
+func assertRevoked(t *testing.T, b *Backend, ID uuid.UUID, revoked bool) {
+	t.Helper()
+
+	token, err := b.RefreshTokens.GetByID(context.Background(), ID)
+	require.NoError(t, err)
+	assert.Equal(t, revoked, token.RevokedAt != nil)
+}
@@ -0,0 +1,236 @@
+package conformance
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Govorov1705/ozon-test/internal/models"
+	"github.com/Govorov1705/ozon-test/internal/pagination"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var searchTests = []testCase{
+	{"Match", testSearchMatch},
+	{"Rank", testSearchRank},
+	{"Snippet", testSearchSnippet},
+	{"SnippetEscapesHTML", testSearchSnippetEscapesHTML},
+	{"Updated", testSearchUpdated},
+	{"Deleted", testSearchDeleted},
+	{"Rollback", testSearchRollback},
+	{"Pagination", testSearchPagination},
+}
+
+// Рейтинг в разных хранилищах считается по-разному, поэтому тесты
+// проверяют только порядок и состав результатов
+var searchPage = &pagination.Page{Limit: 10, Sort: pagination.SortRelevance}
+
+func addPostWithContent(t *testing.T, b *Backend, userID uuid.UUID, title, content string) *models.Post {
+	t.Helper()
+
+	post, err := b.Posts.Add(context.Background(), userID, nil, title, content, true)
+	require.NoError(t, err)
+
+	return post
+}
+
+func searchPosts(t *testing.T, b *Backend, query string) []*models.SearchHit {
+	t.Helper()
+
+	hits, err := b.Posts.Search(context.Background(), query, searchPage)
+	require.NoError(t, err)
+
+	return hits
+}
+
+func searchComments(t *testing.T, b *Backend, query string) []*models.SearchHit {
+	t.Helper()
+
+	hits, err := b.Comments.Search(context.Background(), query, searchPage)
+	require.NoError(t, err)
+
+	return hits
+}
+
+func testSearchMatch(t *testing.T, b *Backend) {
+	user := addUser(t, b, "alice")
+	both := addPostWithContent(t, b, user.ID, "Golang generics", "Type parameters arrived in release 18")
+	one := addPostWithContent(t, b, user.ID, "Rust traits", "Compared with golang interfaces")
+	addPostWithContent(t, b, user.ID, "Cooking", "Nothing about programming here")
+
+	post := addPost(t, b, user.ID, "post")
+	comment := addComment(t, b, post, user.ID, nil, "Generics in golang are finally here")
+	addComment(t, b, post, user.ID, nil, "Unrelated comment")
+
+	assert.ElementsMatch(t, []uuid.UUID{both.ID, one.ID}, idsOf(searchPosts(t, b, "golang")))
+	assert.ElementsMatch(t, []uuid.UUID{both.ID, one.ID}, idsOf(searchPosts(t, b, "GoLang")))
+
+	// Документ должен содержать все слова запроса
+	assert.Equal(t, []uuid.UUID{both.ID}, idsOf(searchPosts(t, b, "golang generics")))
+	assert.Equal(t, []uuid.UUID{both.ID}, idsOf(searchPosts(t, b, "generics, golang!")))
+	assert.Empty(t, searchPosts(t, b, "golang cooking"))
+	assert.Empty(t, searchPosts(t, b, "haskell"))
+
+	hits := searchComments(t, b, "golang generics")
+	require.Len(t, hits, 1)
+	assert.Nil(t, hits[0].Post)
+	assert.Equal(t, comment.ID, hits[0].Comment.ID)
+	assert.Equal(t, comment.Content, hits[0].Comment.Content)
+}
+
+func testSearchRank(t *testing.T, b *Backend) {
+	user := addUser(t, b, "alice")
+
+	// Совпадение в заголовке важнее совпадения в тексте
+	inContent := addPostWithContent(t, b, user.ID, "Weekly notes", "A few words on golang")
+	inTitle := addPostWithContent(t, b, user.ID, "Golang notes", "A few words on weekly")
+
+	assert.Equal(t, []uuid.UUID{inTitle.ID, inContent.ID}, idsOf(searchPosts(t, b, "golang")))
+
+	post := addPost(t, b, user.ID, "post")
+	twice := addComment(t, b, post, user.ID, nil, "golang and golang again")
+	once := addComment(t, b, post, user.ID, nil, "golang and rust again")
+
+	hits := searchComments(t, b, "golang")
+	assert.Equal(t, []uuid.UUID{twice.ID, once.ID}, idsOf(hits))
+	assert.Greater(t, hits[0].Rank, hits[1].Rank)
+}
+
+func testSearchSnippet(t *testing.T, b *Backend) {
+	user := addUser(t, b, "alice")
+	words := strings.Repeat("filler ", 40)
+	addPostWithContent(t, b, user.ID, "Long post", words+"the needle is here "+words)
+
+	hits := searchPosts(t, b, "needle")
+	require.Len(t, hits, 1)
+
+	snippet := hits[0].Snippet
+	assert.Contains(t, snippet, "<b>needle</b>")
+	assert.Less(t, len(snippet), len(hits[0].Post.Content))
+}
+
+// Фрагмент отдается клиенту как HTML, поэтому разметка из текста не должна
+// в него попасть. Метки выделения в тексте тоже не дают лишних тегов
+func testSearchSnippetEscapesHTML(t *testing.T, b *Backend) {
+	user := addUser(t, b, "alice")
+	post := addPostWithContent(t, b, user.ID, "Title", `<script>alert("needle")</script>`)
+	addComment(t, b, post, user.ID, nil, "\x02<img src=x onerror=alert(1)> needle \x03")
+
+	for _, hits := range [][]*models.SearchHit{searchPosts(t, b, "needle"), searchComments(t, b, "needle")} {
+		require.Len(t, hits, 1)
+
+		snippet := hits[0].Snippet
+		assert.Contains(t, snippet, "<b>needle</b>")
+		assert.NotContains(t, snippet, "<script")
+		assert.NotContains(t, snippet, "<img")
+		assert.Equal(t, 1, strings.Count(snippet, "<b>"))
+		assert.Equal(t, 1, strings.Count(snippet, "</b>"))
+	}
+
+	assert.Contains(t, searchPosts(t, b, "needle")[0].Snippet, "&lt;script&gt;")
+}
+
+func testSearchUpdated(t *testing.T, b *Backend) {
+	ctx := context.Background()
+	user := addUser(t, b, "alice")
+	post := addPostWithContent(t, b, user.ID, "Title", "old words")
+	comment := addComment(t, b, post, user.ID, nil, "before edit")
+
+	_, err := b.Posts.Update(ctx, post.ID, "Title", "new words")
+	require.NoError(t, err)
+	_, err = b.Comments.UpdateContent(ctx, comment.ID, "after edit")
+	require.NoError(t, err)
+
+	assert.Empty(t, searchPosts(t, b, "old"))
+	assert.Equal(t, []uuid.UUID{post.ID}, idsOf(searchPosts(t, b, "new")))
+	assert.Empty(t, searchComments(t, b, "before"))
+	assert.Equal(t, []uuid.UUID{comment.ID}, idsOf(searchComments(t, b, "after")))
+}
+
+func testSearchDeleted(t *testing.T, b *Backend) {
+	ctx := context.Background()
+	user := addUser(t, b, "alice")
+	post := addPostWithContent(t, b, user.ID, "Needle", "needle")
+	kept := addPostWithContent(t, b, user.ID, "Another", "needle")
+
+	root := addComment(t, b, post, user.ID, nil, "needle root")
+	reply := addComment(t, b, post, user.ID, root, "needle reply")
+	softDeleted := addComment(t, b, post, user.ID, nil, "needle soft")
+
+	_, err := b.Posts.SoftDelete(ctx, post.ID)
+	require.NoError(t, err)
+	_, err = b.Comments.SoftDelete(ctx, softDeleted.ID)
+	require.NoError(t, err)
+
+	assert.Equal(t, []uuid.UUID{kept.ID}, idsOf(searchPosts(t, b, "needle")))
+	assert.ElementsMatch(t, []uuid.UUID{root.ID, reply.ID}, idsOf(searchComments(t, b, "needle")))
+
+	// Удаление комментария удаляет и ответы на него
+	require.NoError(t, b.Comments.Delete(ctx, root.ID))
+	assert.Empty(t, searchComments(t, b, "needle"))
+}
+
+func testSearchRollback(t *testing.T, b *Backend) {
+	ctx := context.Background()
+	user := addUser(t, b, "alice")
+	post := addPostWithContent(t, b, user.ID, "Title", "stable words")
+	comment := addComment(t, b, post, user.ID, nil, "stable comment")
+
+	errAbort := errors.New("abort")
+	err := inTx(ctx, b, func(ctx context.Context) error {
+		_, err := b.Posts.Add(ctx, user.ID, nil, "Title", "transient words", true)
+		if err != nil {
+			return err
+		}
+		_, err = b.Posts.Update(ctx, post.ID, "Title", "transient words")
+		if err != nil {
+			return err
+		}
+		_, err = b.Comments.UpdateContent(ctx, comment.ID, "transient comment")
+		if err != nil {
+			return err
+		}
+		return errAbort
+	})
+	require.ErrorIs(t, err, errAbort)
+
+	assert.Empty(t, searchPosts(t, b, "transient"))
+	assert.Equal(t, []uuid.UUID{post.ID}, idsOf(searchPosts(t, b, "stable")))
+	assert.Empty(t, searchComments(t, b, "transient"))
+	assert.Equal(t, []uuid.UUID{comment.ID}, idsOf(searchComments(t, b, "stable")))
+}
+
+// Ожидаемый порядок берется из полной выборки, так как рейтинг зависит
+// от хранилища. Проверяется, что он не возрастает, а страницы по
+// курсорам с рейтингом согласованы с полной выборкой
+func testSearchPagination(t *testing.T, b *Backend) {
+	user := addUser(t, b, "alice")
+	post := addPost(t, b, user.ID, "post")
+	for _, content := range []string{
+		"needle",
+		"needle needle",
+		"needle in a haystack",
+		"needle",
+		"needle needle needle",
+	} {
+		addComment(t, b, post, user.ID, nil, content)
+	}
+
+	hits := searchComments(t, b, "needle")
+	require.Len(t, hits, 5)
+	for i := 1; i < len(hits); i++ {
+		prevRank, prevCreatedAt, _ := hits[i-1].Key()
+		rank, createdAt, _ := hits[i].Key()
+		require.GreaterOrEqual(t, prevRank, rank)
+		if prevRank == rank {
+			require.False(t, createdAt.After(prevCreatedAt))
+		}
+	}
+
+	testSortedPagination(t, pagination.SortRelevance, idsOf(hits), hits, func(page *pagination.Page) ([]*models.SearchHit, error) {
+		return b.Comments.Search(context.Background(), "needle", page)
+	})
+}
@@ -0,0 +1,201 @@
+package conformance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/Govorov1705/ozon-test/internal/models"
+	"github.com/Govorov1705/ozon-test/internal/pagination"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var tagsTests = []testCase{
+	{"SetAndGet", testTagsSetAndGet},
+	{"GetPageByTag", testTagsGetPageByTag},
+	{"Pagination", testTagsPagination},
+	{"Search", testTagsSearch},
+	{"SearchEscapesPattern", testTagsSearchEscapesPattern},
+	{"SoftDelete", testTagsSoftDelete},
+	{"Rollback", testTagsRollback},
+}
+
+func setTags(t *testing.T, b *Backend, postID uuid.UUID, tags ...string) {
+	t.Helper()
+
+	require.NoError(t, b.Posts.SetTags(context.Background(), postID, tags))
+}
+
+func tagsOf(t *testing.T, b *Backend, postID uuid.UUID) []string {
+	t.Helper()
+
+	tags, err := b.Posts.GetTags(context.Background(), []uuid.UUID{postID})
+	require.NoError(t, err)
+
+	return tags[postID]
+}
+
+func searchTags(t *testing.T, b *Backend, prefix string, limit int32) []models.Tag {
+	t.Helper()
+
+	tags, err := b.Posts.SearchTags(context.Background(), prefix, limit)
+	require.NoError(t, err)
+
+	result := make([]models.Tag, len(tags))
+	for i, tag := range tags {
+		result[i] = *tag
+	}
+
+	return result
+}
+
+func testTagsSetAndGet(t *testing.T, b *Backend) {
+	ctx := context.Background()
+	user := addUser(t, b, "alice")
+	first := addPost(t, b, user.ID, "first")
+	second := addPost(t, b, user.ID, "second")
+	untagged := addPost(t, b, user.ID, "untagged")
+
+	setTags(t, b, first.ID, "golang", "backend", "sql")
+	setTags(t, b, second.ID, "rust")
+
+	tags, err := b.Posts.GetTags(ctx, []uuid.UUID{first.ID, second.ID, untagged.ID})
+	require.NoError(t, err)
+	assert.Equal(t, map[uuid.UUID][]string{
+		first.ID:    {"backend", "golang", "sql"},
+		second.ID:   {"rust"},
+		untagged.ID: {},
+	}, tags)
+
+	// Теги заменяются целиком
+	setTags(t, b, first.ID, "sql", "postgres")
+	assert.Equal(t, []string{"postgres", "sql"}, tagsOf(t, b, first.ID))
+
+	setTags(t, b, first.ID)
+	assert.Empty(t, tagsOf(t, b, first.ID))
+	assert.Equal(t, []string{"rust"}, tagsOf(t, b, second.ID))
+}
+
+func testTagsGetPageByTag(t *testing.T, b *Backend) {
+	ctx := context.Background()
+	user := addUser(t, b, "alice")
+	older := addPost(t, b, user.ID, "older")
+	other := addPost(t, b, user.ID, "other")
+	newer := addPost(t, b, user.ID, "newer")
+
+	setTags(t, b, older.ID, "golang")
+	setTags(t, b, other.ID, "rust")
+	setTags(t, b, newer.ID, "golang", "rust")
+
+	page, err := b.Posts.GetPageByTag(ctx, "golang", &pagination.Page{Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, newestFirst(older, newer), idsOf(page))
+
+	page, err = b.Posts.GetPageByTag(ctx, "go", &pagination.Page{Limit: 10})
+	require.NoError(t, err)
+	assert.Empty(t, page)
+}
+
+func testTagsPagination(t *testing.T, b *Backend) {
+	user := addUser(t, b, "alice")
+
+	posts := make([]*models.Post, 5)
+	for i := range posts {
+		posts[i] = addPost(t, b, user.ID, fmt.Sprintf("post %d", i))
+		setTags(t, b, posts[i].ID, "golang")
+		setTags(t, b, addPost(t, b, user.ID, fmt.Sprintf("other %d", i)).ID, "rust")
+	}
+
+	testPagination(t, newestFirst(posts...), posts, func(page *pagination.Page) ([]*models.Post, error) {
+		return b.Posts.GetPageByTag(context.Background(), "golang", page)
+	})
+}
+
+func testTagsSearch(t *testing.T, b *Backend) {
+	user := addUser(t, b, "alice")
+	for _, tags := range [][]string{
+		{"golang", "go-kit"},
+		{"golang", "gorm"},
+		{"golang"},
+		{"gorm", "rust"},
+		{"go-kit"},
+	} {
+		setTags(t, b, addPost(t, b, user.ID, "post").ID, tags...)
+	}
+
+	// Сначала самые популярные, при равенстве - по алфавиту
+	assert.Equal(t, []models.Tag{
+		{Name: "golang", PostCount: 3},
+		{Name: "go-kit", PostCount: 2},
+		{Name: "gorm", PostCount: 2},
+	}, searchTags(t, b, "go", 10))
+	assert.Equal(t, []models.Tag{
+		{Name: "golang", PostCount: 3},
+		{Name: "go-kit", PostCount: 2},
+	}, searchTags(t, b, "go", 2))
+	assert.Equal(t, []models.Tag{
+		{Name: "gorm", PostCount: 2},
+	}, searchTags(t, b, "gor", 10))
+	assert.Len(t, searchTags(t, b, "", 10), 4)
+	assert.Empty(t, searchTags(t, b, "java", 10))
+}
+
+func testTagsSearchEscapesPattern(t *testing.T, b *Backend) {
+	user := addUser(t, b, "alice")
+	setTags(t, b, addPost(t, b, user.ID, "post").ID, "c_sharp", "css", "c++")
+
+	// _ и % в префиксе - обычные символы, а не шаблон LIKE
+	assert.Equal(t, []models.Tag{{Name: "c_sharp", PostCount: 1}}, searchTags(t, b, "c_", 10))
+	assert.Empty(t, searchTags(t, b, "c%", 10))
+	assert.Equal(t, []models.Tag{{Name: "c++", PostCount: 1}}, searchTags(t, b, "c+", 10))
+}
+
+func testTagsSoftDelete(t *testing.T, b *Backend) {
+	ctx := context.Background()
+	user := addUser(t, b, "alice")
+	deleted := addPost(t, b, user.ID, "deleted")
+	kept := addPost(t, b, user.ID, "kept")
+	setTags(t, b, deleted.ID, "golang", "rust")
+	setTags(t, b, kept.ID, "golang")
+
+	_, err := b.Posts.SoftDelete(ctx, deleted.ID)
+	require.NoError(t, err)
+
+	assert.Empty(t, tagsOf(t, b, deleted.ID))
+	assert.Equal(t, []models.Tag{{Name: "golang", PostCount: 1}}, searchTags(t, b, "", 10))
+
+	page, err := b.Posts.GetPageByTag(ctx, "golang", &pagination.Page{Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{kept.ID}, idsOf(page))
+}
+
+func testTagsRollback(t *testing.T, b *Backend) {
+	ctx := context.Background()
+	user := addUser(t, b, "alice")
+	post := addPost(t, b, user.ID, "post")
+	setTags(t, b, post.ID, "golang")
+
+	errAbort := errors.New("abort")
+	err := inTx(ctx, b, func(ctx context.Context) error {
+		err := b.Posts.SetTags(ctx, post.ID, []string{"rust"})
+		if err != nil {
+			return err
+		}
+		added, err := b.Posts.Add(ctx, user.ID, nil, "added", "content", true)
+		if err != nil {
+			return err
+		}
+		err = b.Posts.SetTags(ctx, added.ID, []string{"transient"})
+		if err != nil {
+			return err
+		}
+		return errAbort
+	})
+	require.ErrorIs(t, err, errAbort)
+
+	assert.Equal(t, []string{"golang"}, tagsOf(t, b, post.ID))
+	assert.Equal(t, []models.Tag{{Name: "golang", PostCount: 1}}, searchTags(t, b, "", 10))
+}
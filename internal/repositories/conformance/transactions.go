@@ -0,0 +1,251 @@
+package conformance
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Govorov1705/ozon-test/internal/errs"
+	"github.com/Govorov1705/ozon-test/internal/pagination"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const concurrency = 10
+
+var transactionsTests = []testCase{
+	{"Commit", testTransactionsCommit},
+	{"Rollback", testTransactionsRollback},
+	{"ConcurrentInserts", testTransactionsConcurrentInserts},
+	{"ForUpdateSerializesPosts", testTransactionsForUpdateSerializesPosts},
+	{"ForUpdateSerializesComments", testTransactionsForUpdateSerializesComments},
+	{"ConcurrentOutboxRelays", testTransactionsConcurrentOutboxRelays},
+}
+
+func testTransactionsCommit(t *testing.T, b *Backend) {
+	ctx := context.Background()
+	user := addUser(t, b, "alice")
+
+	var postID uuid.UUID
+	err := inTx(ctx, b, func(ctx context.Context) error {
+		post, err := b.Posts.Add(ctx, user.ID, nil, "title", "content", true)
+		if err != nil {
+			return err
+		}
+		postID = post.ID
+
+		_, err = b.Posts.Update(ctx, post.ID, "updated", "content")
+		return err
+	})
+	require.NoError(t, err)
+
+	post, err := b.Posts.GetByID(ctx, postID, false)
+	require.NoError(t, err)
+	assert.Equal(t, "updated", post.Title)
+}
+
+func testTransactionsRollback(t *testing.T, b *Backend) {
+	ctx := context.Background()
+	user := addUser(t, b, "alice")
+	post := addPost(t, b, user.ID, "title")
+	comment := addComment(t, b, post, user.ID, nil, "content")
+
+	errAbort := errors.New("abort")
+	var addedPostID, addedCommentID uuid.UUID
+
+	err := inTx(ctx, b, func(ctx context.Context) error {
+		added, err := b.Posts.Add(ctx, user.ID, nil, "added", "content", true)
+		if err != nil {
+			return err
+		}
+		addedPostID = added.ID
+
+		addedComment, err := b.Comments.Add(ctx, post.ID, user.ID, nil, nil, "added")
+		if err != nil {
+			return err
+		}
+		addedCommentID = addedComment.ID
+
+		_, err = b.Posts.Update(ctx, post.ID, "updated", "updated")
+		if err != nil {
+			return err
+		}
+		_, err = b.Comments.SoftDelete(ctx, comment.ID)
+		if err != nil {
+			return err
+		}
+		err = b.Outbox.Add(ctx, uuid.New(), "rolled_back", []byte(`{}`))
+		if err != nil {
+			return err
+		}
+
+		return errAbort
+	})
+	require.ErrorIs(t, err, errAbort)
+
+	_, err = b.Posts.GetByID(ctx, addedPostID, false)
+	assert.ErrorIs(t, err, errs.ErrNotFound)
+	_, err = b.Comments.GetByID(ctx, addedCommentID, false)
+	assert.ErrorIs(t, err, errs.ErrNotFound)
+
+	gotPost, err := b.Posts.GetByID(ctx, post.ID, false)
+	require.NoError(t, err)
+	assert.Equal(t, "title", gotPost.Title)
+	assert.Nil(t, gotPost.UpdatedAt)
+
+	gotComment, err := b.Comments.GetByID(ctx, comment.ID, false)
+	require.NoError(t, err)
+	assert.Equal(t, "content", gotComment.Content)
+	assert.Nil(t, gotComment.DeletedAt)
+
+	events, err := b.Outbox.GetUnprocessed(ctx, 10)
+	require.NoError(t, err)
+	assert.Empty(t, events)
+}
+
+func testTransactionsConcurrentInserts(t *testing.T, b *Backend) {
+	ctx := context.Background()
+	user := addUser(t, b, "alice")
+	post := addPost(t, b, user.ID, "post")
+
+	runConcurrently(t, func() error {
+		return inTx(ctx, b, func(ctx context.Context) error {
+			_, err := b.Comments.Add(ctx, post.ID, user.ID, nil, nil, "comment")
+			if err != nil {
+				return err
+			}
+			return b.Outbox.Add(ctx, uuid.New(), "comment_added", []byte(`{}`))
+		})
+	})
+
+	comments, err := b.Comments.GetByPostID(ctx, post.ID, &pagination.Page{Limit: 2 * concurrency})
+	require.NoError(t, err)
+	assert.Len(t, comments, concurrency)
+
+	events, err := b.Outbox.GetUnprocessed(ctx, 2*concurrency)
+	require.NoError(t, err)
+	assert.Len(t, events, concurrency)
+}
+
+// Каждая транзакция читает пост с forUpdate и дописывает к заголовку
+// символ: если блокировка не держится до коммита, часть изменений теряется
+func testTransactionsForUpdateSerializesPosts(t *testing.T, b *Backend) {
+	ctx := context.Background()
+	user := addUser(t, b, "alice")
+	post := addPost(t, b, user.ID, "")
+
+	runConcurrently(t, func() error {
+		return inTx(ctx, b, func(ctx context.Context) error {
+			current, err := b.Posts.GetByID(ctx, post.ID, true)
+			if err != nil {
+				return err
+			}
+			_, err = b.Posts.Update(ctx, post.ID, current.Title+"x", current.Content)
+			return err
+		})
+	})
+
+	got, err := b.Posts.GetByID(ctx, post.ID, false)
+	require.NoError(t, err)
+	assert.Equal(t, strings.Repeat("x", concurrency), got.Title)
+}
+
+func testTransactionsForUpdateSerializesComments(t *testing.T, b *Backend) {
+	ctx := context.Background()
+	user := addUser(t, b, "alice")
+	post := addPost(t, b, user.ID, "post")
+	comment := addComment(t, b, post, user.ID, nil, "")
+
+	runConcurrently(t, func() error {
+		return inTx(ctx, b, func(ctx context.Context) error {
+			current, err := b.Comments.GetByID(ctx, comment.ID, true)
+			if err != nil {
+				return err
+			}
+			_, err = b.Comments.AddRevision(ctx, comment.ID, current.Content)
+			if err != nil {
+				return err
+			}
+			_, err = b.Comments.UpdateContent(ctx, comment.ID, current.Content+"x")
+			return err
+		})
+	})
+
+	got, err := b.Comments.GetByID(ctx, comment.ID, false)
+	require.NoError(t, err)
+	assert.Equal(t, strings.Repeat("x", concurrency), got.Content)
+
+	assert.Len(t, revisionsOf(t, b, comment.ID), concurrency)
+}
+
+// Несколько обработчиков разбирают очередь параллельно, и каждое
+// событие должно достаться ровно одному из них
+func testTransactionsConcurrentOutboxRelays(t *testing.T, b *Backend) {
+	ctx := context.Background()
+	events := addEvents(t, b, 3*concurrency)
+
+	var (
+		mu        sync.Mutex
+		processed = map[uuid.UUID]int{}
+	)
+
+	relay := func() error {
+		for {
+			var batch []uuid.UUID
+			err := inTx(ctx, b, func(ctx context.Context) error {
+				unprocessed, err := b.Outbox.GetUnprocessed(ctx, 3)
+				if err != nil {
+					return err
+				}
+				batch = idsOf(unprocessed)
+				return b.Outbox.MarkProcessed(ctx, batch)
+			})
+			if err != nil {
+				return err
+			}
+			if len(batch) == 0 {
+				return nil
+			}
+
+			mu.Lock()
+			for _, ID := range batch {
+				processed[ID]++
+			}
+			mu.Unlock()
+		}
+	}
+
+	runConcurrently(t, relay)
+
+	assert.Len(t, processed, len(events))
+	for ID, count := range processed {
+		assert.Equal(t, 1, count, "event %s", ID)
+	}
+
+	unprocessed, err := b.Outbox.GetUnprocessed(ctx, 10)
+	require.NoError(t, err)
+	assert.Empty(t, unprocessed)
+}
+
+func runConcurrently(t *testing.T, fn func() error) {
+	t.Helper()
+
+	var wg sync.WaitGroup
+	errCh := make(chan error, concurrency)
+	for range concurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errCh <- fn()
+		}()
+	}
+	wg.Wait()
+	close(errCh)
+
+	for err := range errCh {
+		require.NoError(t, err)
+	}
+}
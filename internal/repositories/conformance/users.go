@@ -0,0 +1,78 @@
+package conformance
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Govorov1705/ozon-test/internal/errs"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var usersTests = []testCase{
+	{"AddAndGet", testUsersAddAndGet},
+	{"DuplicateUsername", testUsersDuplicateUsername},
+	{"NotFound", testUsersNotFound},
+	{"GetByIDs", testUsersGetByIDs},
+}
+
+func testUsersAddAndGet(t *testing.T, b *Backend) {
+	ctx := context.Background()
+
+	user, err := b.Users.Add(ctx, "alice", "hash")
+	require.NoError(t, err)
+	assert.NotEqual(t, uuid.Nil, user.ID)
+	assert.Equal(t, "alice", user.Username)
+	assert.Equal(t, "hash", user.HashedPassword)
+	assert.False(t, user.CreatedAt.IsZero())
+
+	byID, err := b.Users.GetByID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, byID.ID)
+	assert.Equal(t, user.Username, byID.Username)
+	assert.Equal(t, user.HashedPassword, byID.HashedPassword)
+	assert.True(t, user.CreatedAt.Equal(byID.CreatedAt))
+
+	byUsername, err := b.Users.GetByUsername(ctx, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, byUsername.ID)
+}
+
+func testUsersDuplicateUsername(t *testing.T, b *Backend) {
+	addUser(t, b, "alice")
+
+	_, err := b.Users.Add(context.Background(), "alice", "other")
+	assert.ErrorIs(t, err, errs.ErrAlreadyExists)
+}
+
+func testUsersNotFound(t *testing.T, b *Backend) {
+	ctx := context.Background()
+
+	_, err := b.Users.GetByID(ctx, uuid.New())
+	assert.ErrorIs(t, err, errs.ErrNotFound)
+
+	_, err = b.Users.GetByUsername(ctx, "nobody")
+	assert.ErrorIs(t, err, errs.ErrNotFound)
+}
+
+func testUsersGetByIDs(t *testing.T, b *Backend) {
+	ctx := context.Background()
+
+	alice := addUser(t, b, "alice")
+	addUser(t, b, "bob")
+	carol := addUser(t, b, "carol")
+
+	users, err := b.Users.GetByIDs(ctx, []uuid.UUID{alice.ID, carol.ID, uuid.New()})
+	require.NoError(t, err)
+
+	found := make([]uuid.UUID, len(users))
+	for i, user := range users {
+		found[i] = user.ID
+	}
+	assert.ElementsMatch(t, []uuid.UUID{alice.ID, carol.ID}, found)
+
+	users, err = b.Users.GetByIDs(ctx, []uuid.UUID{})
+	require.NoError(t, err)
+	assert.Empty(t, users)
+}
@@ -0,0 +1,150 @@
+package conformance
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/Govorov1705/ozon-test/internal/errs"
+	"github.com/Govorov1705/ozon-test/internal/models"
+	"github.com/Govorov1705/ozon-test/internal/pagination"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var votesTests = []testCase{
+	{"SetGetDelete", testVotesSetGetDelete},
+	{"UpdateVotes", testVotesUpdateVotes},
+	{"DeletedWithComment", testVotesDeletedWithComment},
+	{"SortedRootComments", testVotesSortedRootComments},
+}
+
+func testVotesSetGetDelete(t *testing.T, b *Backend) {
+	ctx := context.Background()
+	alice := addUser(t, b, "alice")
+	bob := addUser(t, b, "bob")
+	post := addPost(t, b, alice.ID, "post")
+	comment := addComment(t, b, post, alice.ID, nil, "comment")
+
+	_, err := b.Comments.GetVote(ctx, comment.ID, alice.ID)
+	assert.ErrorIs(t, err, errs.ErrNotFound)
+
+	vote, err := b.Comments.SetVote(ctx, comment.ID, alice.ID, 1)
+	require.NoError(t, err)
+	assert.Equal(t, comment.ID, vote.CommentID)
+	assert.Equal(t, alice.ID, vote.UserID)
+	assert.Equal(t, int8(1), vote.Value)
+	assert.False(t, vote.CreatedAt.IsZero())
+
+	// Повторный голос заменяет прежний, а не добавляет второй
+	changed, err := b.Comments.SetVote(ctx, comment.ID, alice.ID, -1)
+	require.NoError(t, err)
+	assert.Equal(t, int8(-1), changed.Value)
+	assert.True(t, vote.CreatedAt.Equal(changed.CreatedAt))
+
+	_, err = b.Comments.SetVote(ctx, comment.ID, bob.ID, 1)
+	require.NoError(t, err)
+
+	got, err := b.Comments.GetVote(ctx, comment.ID, alice.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int8(-1), got.Value)
+
+	require.NoError(t, b.Comments.DeleteVote(ctx, comment.ID, alice.ID))
+	_, err = b.Comments.GetVote(ctx, comment.ID, alice.ID)
+	assert.ErrorIs(t, err, errs.ErrNotFound)
+	assert.NoError(t, b.Comments.DeleteVote(ctx, comment.ID, alice.ID))
+
+	got, err = b.Comments.GetVote(ctx, comment.ID, bob.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int8(1), got.Value)
+}
+
+func testVotesUpdateVotes(t *testing.T, b *Backend) {
+	ctx := context.Background()
+	user := addUser(t, b, "alice")
+	post := addPost(t, b, user.ID, "post")
+	comment := addComment(t, b, post, user.ID, nil, "comment")
+	assert.Equal(t, models.CommentVotes{}, comment.CommentVotes)
+
+	votes := models.NewCommentVotes(3, 2)
+	updated, err := b.Comments.UpdateVotes(ctx, comment.ID, votes)
+	require.NoError(t, err)
+	assert.Equal(t, votes, updated.CommentVotes)
+	assert.Equal(t, "comment", updated.Content)
+
+	got, err := b.Comments.GetByID(ctx, comment.ID, false)
+	require.NoError(t, err)
+	assert.Equal(t, votes, got.CommentVotes)
+
+	_, err = b.Comments.UpdateVotes(ctx, uuid.New(), votes)
+	assert.ErrorIs(t, err, errs.ErrNotFound)
+}
+
+func testVotesDeletedWithComment(t *testing.T, b *Backend) {
+	ctx := context.Background()
+	user := addUser(t, b, "alice")
+	post := addPost(t, b, user.ID, "post")
+	root := addComment(t, b, post, user.ID, nil, "root")
+	reply := addComment(t, b, post, user.ID, root, "reply")
+
+	for _, comment := range []*models.Comment{root, reply} {
+		_, err := b.Comments.SetVote(ctx, comment.ID, user.ID, 1)
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, b.Comments.Delete(ctx, root.ID))
+
+	for _, comment := range []*models.Comment{root, reply} {
+		_, err := b.Comments.GetVote(ctx, comment.ID, user.ID)
+		assert.ErrorIs(t, err, errs.ErrNotFound)
+	}
+}
+
+// Голоса подобраны так, что все порядки различаются, а при равном
+// рейтинге комментарии упорядочены от новых к старым
+func testVotesSortedRootComments(t *testing.T, b *Backend) {
+	ctx := context.Background()
+	user := addUser(t, b, "alice")
+	post := addPost(t, b, user.ID, "post")
+
+	votes := [][2]int32{{0, 0}, {30, 20}, {4, 0}, {2, 2}, {3, 0}}
+	comments := make([]*models.Comment, len(votes))
+	for i, v := range votes {
+		comment := addComment(t, b, post, user.ID, nil, fmt.Sprintf("comment %d", i))
+		updated, err := b.Comments.UpdateVotes(ctx, comment.ID, models.NewCommentVotes(v[0], v[1]))
+		require.NoError(t, err)
+		comments[i] = updated
+	}
+	// Ответы не попадают в выборку корневых комментариев
+	addComment(t, b, post, user.ID, comments[2], "reply")
+
+	order := func(indexes ...int) []uuid.UUID {
+		IDs := make([]uuid.UUID, len(indexes))
+		for i, index := range indexes {
+			IDs[i] = comments[index].ID
+		}
+		return IDs
+	}
+
+	// Комментарии создаются по очереди, но время создания в хранилище
+	// может совпасть, поэтому для NEW и OLD порядок берется из ключей
+	testCases := []struct {
+		sort     pagination.Sort
+		expected []uuid.UUID
+	}{
+		{pagination.SortNew, newestFirst(comments...)},
+		{pagination.SortOld, oldestFirst(comments...)},
+		{pagination.SortTop, order(1, 2, 4, 3, 0)},
+		{pagination.SortControversial, order(1, 3, 4, 2, 0)},
+		{pagination.SortBest, order(2, 1, 4, 3, 0)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(string(tc.sort), func(t *testing.T) {
+			testSortedPagination(t, tc.sort, tc.expected, comments, func(page *pagination.Page) ([]*models.Comment, error) {
+				return b.Comments.GetRootCommentsByPostID(ctx, post.ID, page)
+			})
+		})
+	}
+}
@@ -6,8 +6,10 @@ package mocks
 
 import (
 	"context"
+	"time"
 
 	"github.com/Govorov1705/ozon-test/internal/models"
+	"github.com/Govorov1705/ozon-test/internal/pagination"
 	"github.com/google/uuid"
 	mock "github.com/stretchr/testify/mock"
 )
@@ -131,48 +133,47 @@ func (_c *MockCommentsRepository_Add_Call) RunAndReturn(run func(ctx context.Con
 	return _c
 }
 
-// GetByID provides a mock function for the type MockCommentsRepository
-func (_mock *MockCommentsRepository) GetByID(ctx context.Context, commentID uuid.UUID, forUpdate bool) (*models.Comment, error) {
-	ret := _mock.Called(ctx, commentID, forUpdate)
+// AddReaction provides a mock function for the type MockCommentsRepository
+func (_mock *MockCommentsRepository) AddReaction(ctx context.Context, commentID uuid.UUID, userID uuid.UUID, emoji string) (bool, error) {
+	ret := _mock.Called(ctx, commentID, userID, emoji)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetByID")
+		panic("no return value specified for AddReaction")
 	}
 
-	var r0 *models.Comment
+	var r0 bool
 	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID, bool) (*models.Comment, error)); ok {
-		return returnFunc(ctx, commentID, forUpdate)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID, string) (bool, error)); ok {
+		return returnFunc(ctx, commentID, userID, emoji)
 	}
-	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID, bool) *models.Comment); ok {
-		r0 = returnFunc(ctx, commentID, forUpdate)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID, string) bool); ok {
+		r0 = returnFunc(ctx, commentID, userID, emoji)
 	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*models.Comment)
-		}
+		r0 = ret.Get(0).(bool)
 	}
-	if returnFunc, ok := ret.Get(1).(func(context.Context, uuid.UUID, bool) error); ok {
-		r1 = returnFunc(ctx, commentID, forUpdate)
+	if returnFunc, ok := ret.Get(1).(func(context.Context, uuid.UUID, uuid.UUID, string) error); ok {
+		r1 = returnFunc(ctx, commentID, userID, emoji)
 	} else {
 		r1 = ret.Error(1)
 	}
 	return r0, r1
 }
 
-// MockCommentsRepository_GetByID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetByID'
-type MockCommentsRepository_GetByID_Call struct {
+// MockCommentsRepository_AddReaction_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AddReaction'
+type MockCommentsRepository_AddReaction_Call struct {
 	*mock.Call
 }
 
-// GetByID is a helper method to define mock.On call
+// AddReaction is a helper method to define mock.On call
 //   - ctx context.Context
 //   - commentID uuid.UUID
-//   - forUpdate bool
-func (_e *MockCommentsRepository_Expecter) GetByID(ctx interface{}, commentID interface{}, forUpdate interface{}) *MockCommentsRepository_GetByID_Call {
-	return &MockCommentsRepository_GetByID_Call{Call: _e.mock.On("GetByID", ctx, commentID, forUpdate)}
+//   - userID uuid.UUID
+//   - emoji string
+func (_e *MockCommentsRepository_Expecter) AddReaction(ctx interface{}, commentID interface{}, userID interface{}, emoji interface{}) *MockCommentsRepository_AddReaction_Call {
+	return &MockCommentsRepository_AddReaction_Call{Call: _e.mock.On("AddReaction", ctx, commentID, userID, emoji)}
 }
 
-func (_c *MockCommentsRepository_GetByID_Call) Run(run func(ctx context.Context, commentID uuid.UUID, forUpdate bool)) *MockCommentsRepository_GetByID_Call {
+func (_c *MockCommentsRepository_AddReaction_Call) Run(run func(ctx context.Context, commentID uuid.UUID, userID uuid.UUID, emoji string)) *MockCommentsRepository_AddReaction_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		var arg0 context.Context
 		if args[0] != nil {
@@ -182,331 +183,274 @@ func (_c *MockCommentsRepository_GetByID_Call) Run(run func(ctx context.Context,
 		if args[1] != nil {
 			arg1 = args[1].(uuid.UUID)
 		}
-		var arg2 bool
+		var arg2 uuid.UUID
 		if args[2] != nil {
-			arg2 = args[2].(bool)
+			arg2 = args[2].(uuid.UUID)
+		}
+		var arg3 string
+		if args[3] != nil {
+			arg3 = args[3].(string)
 		}
 		run(
 			arg0,
 			arg1,
 			arg2,
+			arg3,
 		)
 	})
 	return _c
 }
 
-func (_c *MockCommentsRepository_GetByID_Call) Return(comment *models.Comment, err error) *MockCommentsRepository_GetByID_Call {
-	_c.Call.Return(comment, err)
+func (_c *MockCommentsRepository_AddReaction_Call) Return(b bool, err error) *MockCommentsRepository_AddReaction_Call {
+	_c.Call.Return(b, err)
 	return _c
 }
 
-func (_c *MockCommentsRepository_GetByID_Call) RunAndReturn(run func(ctx context.Context, commentID uuid.UUID, forUpdate bool) (*models.Comment, error)) *MockCommentsRepository_GetByID_Call {
+func (_c *MockCommentsRepository_AddReaction_Call) RunAndReturn(run func(ctx context.Context, commentID uuid.UUID, userID uuid.UUID, emoji string) (bool, error)) *MockCommentsRepository_AddReaction_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// GetChildrenCommentsByRootIDs provides a mock function for the type MockCommentsRepository
-func (_mock *MockCommentsRepository) GetChildrenCommentsByRootIDs(ctx context.Context, rootIDs []*uuid.UUID) ([]*models.Comment, error) {
-	ret := _mock.Called(ctx, rootIDs)
+// AddRevision provides a mock function for the type MockCommentsRepository
+func (_mock *MockCommentsRepository) AddRevision(ctx context.Context, commentID uuid.UUID, content string) (*models.CommentRevision, error) {
+	ret := _mock.Called(ctx, commentID, content)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetChildrenCommentsByRootIDs")
+		panic("no return value specified for AddRevision")
 	}
 
-	var r0 []*models.Comment
+	var r0 *models.CommentRevision
 	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, []*uuid.UUID) ([]*models.Comment, error)); ok {
-		return returnFunc(ctx, rootIDs)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID, string) (*models.CommentRevision, error)); ok {
+		return returnFunc(ctx, commentID, content)
 	}
-	if returnFunc, ok := ret.Get(0).(func(context.Context, []*uuid.UUID) []*models.Comment); ok {
-		r0 = returnFunc(ctx, rootIDs)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID, string) *models.CommentRevision); ok {
+		r0 = returnFunc(ctx, commentID, content)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]*models.Comment)
+			r0 = ret.Get(0).(*models.CommentRevision)
 		}
 	}
-	if returnFunc, ok := ret.Get(1).(func(context.Context, []*uuid.UUID) error); ok {
-		r1 = returnFunc(ctx, rootIDs)
+	if returnFunc, ok := ret.Get(1).(func(context.Context, uuid.UUID, string) error); ok {
+		r1 = returnFunc(ctx, commentID, content)
 	} else {
 		r1 = ret.Error(1)
 	}
 	return r0, r1
 }
 
-// MockCommentsRepository_GetChildrenCommentsByRootIDs_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetChildrenCommentsByRootIDs'
-type MockCommentsRepository_GetChildrenCommentsByRootIDs_Call struct {
+// MockCommentsRepository_AddRevision_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AddRevision'
+type MockCommentsRepository_AddRevision_Call struct {
 	*mock.Call
 }
 
-// GetChildrenCommentsByRootIDs is a helper method to define mock.On call
+// AddRevision is a helper method to define mock.On call
 //   - ctx context.Context
-//   - rootIDs []*uuid.UUID
-func (_e *MockCommentsRepository_Expecter) GetChildrenCommentsByRootIDs(ctx interface{}, rootIDs interface{}) *MockCommentsRepository_GetChildrenCommentsByRootIDs_Call {
-	return &MockCommentsRepository_GetChildrenCommentsByRootIDs_Call{Call: _e.mock.On("GetChildrenCommentsByRootIDs", ctx, rootIDs)}
+//   - commentID uuid.UUID
+//   - content string
+func (_e *MockCommentsRepository_Expecter) AddRevision(ctx interface{}, commentID interface{}, content interface{}) *MockCommentsRepository_AddRevision_Call {
+	return &MockCommentsRepository_AddRevision_Call{Call: _e.mock.On("AddRevision", ctx, commentID, content)}
 }
 
-func (_c *MockCommentsRepository_GetChildrenCommentsByRootIDs_Call) Run(run func(ctx context.Context, rootIDs []*uuid.UUID)) *MockCommentsRepository_GetChildrenCommentsByRootIDs_Call {
+func (_c *MockCommentsRepository_AddRevision_Call) Run(run func(ctx context.Context, commentID uuid.UUID, content string)) *MockCommentsRepository_AddRevision_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		var arg0 context.Context
 		if args[0] != nil {
 			arg0 = args[0].(context.Context)
 		}
-		var arg1 []*uuid.UUID
+		var arg1 uuid.UUID
 		if args[1] != nil {
-			arg1 = args[1].([]*uuid.UUID)
+			arg1 = args[1].(uuid.UUID)
+		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
 		}
 		run(
 			arg0,
 			arg1,
+			arg2,
 		)
 	})
 	return _c
 }
 
-func (_c *MockCommentsRepository_GetChildrenCommentsByRootIDs_Call) Return(comments []*models.Comment, err error) *MockCommentsRepository_GetChildrenCommentsByRootIDs_Call {
-	_c.Call.Return(comments, err)
+func (_c *MockCommentsRepository_AddRevision_Call) Return(commentRevision *models.CommentRevision, err error) *MockCommentsRepository_AddRevision_Call {
+	_c.Call.Return(commentRevision, err)
 	return _c
 }
 
-func (_c *MockCommentsRepository_GetChildrenCommentsByRootIDs_Call) RunAndReturn(run func(ctx context.Context, rootIDs []*uuid.UUID) ([]*models.Comment, error)) *MockCommentsRepository_GetChildrenCommentsByRootIDs_Call {
+func (_c *MockCommentsRepository_AddRevision_Call) RunAndReturn(run func(ctx context.Context, commentID uuid.UUID, content string) (*models.CommentRevision, error)) *MockCommentsRepository_AddRevision_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// GetRootCommentsByPostID provides a mock function for the type MockCommentsRepository
-func (_mock *MockCommentsRepository) GetRootCommentsByPostID(ctx context.Context, postID uuid.UUID, limit *int32, offset *int32) ([]*models.Comment, error) {
-	ret := _mock.Called(ctx, postID, limit, offset)
+// CountByUserIDs provides a mock function for the type MockCommentsRepository
+func (_mock *MockCommentsRepository) CountByUserIDs(ctx context.Context, userIDs []uuid.UUID) (map[uuid.UUID]int32, error) {
+	ret := _mock.Called(ctx, userIDs)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetRootCommentsByPostID")
+		panic("no return value specified for CountByUserIDs")
 	}
 
-	var r0 []*models.Comment
+	var r0 map[uuid.UUID]int32
 	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID, *int32, *int32) ([]*models.Comment, error)); ok {
-		return returnFunc(ctx, postID, limit, offset)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, []uuid.UUID) (map[uuid.UUID]int32, error)); ok {
+		return returnFunc(ctx, userIDs)
 	}
-	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID, *int32, *int32) []*models.Comment); ok {
-		r0 = returnFunc(ctx, postID, limit, offset)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, []uuid.UUID) map[uuid.UUID]int32); ok {
+		r0 = returnFunc(ctx, userIDs)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]*models.Comment)
+			r0 = ret.Get(0).(map[uuid.UUID]int32)
 		}
 	}
-	if returnFunc, ok := ret.Get(1).(func(context.Context, uuid.UUID, *int32, *int32) error); ok {
-		r1 = returnFunc(ctx, postID, limit, offset)
+	if returnFunc, ok := ret.Get(1).(func(context.Context, []uuid.UUID) error); ok {
+		r1 = returnFunc(ctx, userIDs)
 	} else {
 		r1 = ret.Error(1)
 	}
 	return r0, r1
 }
 
-// MockCommentsRepository_GetRootCommentsByPostID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetRootCommentsByPostID'
-type MockCommentsRepository_GetRootCommentsByPostID_Call struct {
+// MockCommentsRepository_CountByUserIDs_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CountByUserIDs'
+type MockCommentsRepository_CountByUserIDs_Call struct {
 	*mock.Call
 }
 
-// GetRootCommentsByPostID is a helper method to define mock.On call
+// CountByUserIDs is a helper method to define mock.On call
 //   - ctx context.Context
-//   - postID uuid.UUID
-//   - limit *int32
-//   - offset *int32
-func (_e *MockCommentsRepository_Expecter) GetRootCommentsByPostID(ctx interface{}, postID interface{}, limit interface{}, offset interface{}) *MockCommentsRepository_GetRootCommentsByPostID_Call {
-	return &MockCommentsRepository_GetRootCommentsByPostID_Call{Call: _e.mock.On("GetRootCommentsByPostID", ctx, postID, limit, offset)}
+//   - userIDs []uuid.UUID
+func (_e *MockCommentsRepository_Expecter) CountByUserIDs(ctx interface{}, userIDs interface{}) *MockCommentsRepository_CountByUserIDs_Call {
+	return &MockCommentsRepository_CountByUserIDs_Call{Call: _e.mock.On("CountByUserIDs", ctx, userIDs)}
 }
 
-func (_c *MockCommentsRepository_GetRootCommentsByPostID_Call) Run(run func(ctx context.Context, postID uuid.UUID, limit *int32, offset *int32)) *MockCommentsRepository_GetRootCommentsByPostID_Call {
+func (_c *MockCommentsRepository_CountByUserIDs_Call) Run(run func(ctx context.Context, userIDs []uuid.UUID)) *MockCommentsRepository_CountByUserIDs_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		var arg0 context.Context
 		if args[0] != nil {
 			arg0 = args[0].(context.Context)
 		}
-		var arg1 uuid.UUID
+		var arg1 []uuid.UUID
 		if args[1] != nil {
-			arg1 = args[1].(uuid.UUID)
-		}
-		var arg2 *int32
-		if args[2] != nil {
-			arg2 = args[2].(*int32)
-		}
-		var arg3 *int32
-		if args[3] != nil {
-			arg3 = args[3].(*int32)
+			arg1 = args[1].([]uuid.UUID)
 		}
 		run(
 			arg0,
 			arg1,
-			arg2,
-			arg3,
 		)
 	})
 	return _c
 }
 
-func (_c *MockCommentsRepository_GetRootCommentsByPostID_Call) Return(comments []*models.Comment, err error) *MockCommentsRepository_GetRootCommentsByPostID_Call {
-	_c.Call.Return(comments, err)
+func (_c *MockCommentsRepository_CountByUserIDs_Call) Return(m map[uuid.UUID]int32, err error) *MockCommentsRepository_CountByUserIDs_Call {
+	_c.Call.Return(m, err)
 	return _c
 }
 
-func (_c *MockCommentsRepository_GetRootCommentsByPostID_Call) RunAndReturn(run func(ctx context.Context, postID uuid.UUID, limit *int32, offset *int32) ([]*models.Comment, error)) *MockCommentsRepository_GetRootCommentsByPostID_Call {
+func (_c *MockCommentsRepository_CountByUserIDs_Call) RunAndReturn(run func(ctx context.Context, userIDs []uuid.UUID) (map[uuid.UUID]int32, error)) *MockCommentsRepository_CountByUserIDs_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// NewMockPostsRepository creates a new instance of MockPostsRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
-// The first argument is typically a *testing.T value.
-func NewMockPostsRepository(t interface {
-	mock.TestingT
-	Cleanup(func())
-}) *MockPostsRepository {
-	mock := &MockPostsRepository{}
-	mock.Mock.Test(t)
-
-	t.Cleanup(func() { mock.AssertExpectations(t) })
-
-	return mock
-}
-
-// MockPostsRepository is an autogenerated mock type for the PostsRepository type
-type MockPostsRepository struct {
-	mock.Mock
-}
-
-type MockPostsRepository_Expecter struct {
-	mock *mock.Mock
-}
-
-func (_m *MockPostsRepository) EXPECT() *MockPostsRepository_Expecter {
-	return &MockPostsRepository_Expecter{mock: &_m.Mock}
-}
-
-// Add provides a mock function for the type MockPostsRepository
-func (_mock *MockPostsRepository) Add(ctx context.Context, userID uuid.UUID, title string, content string, areCommentsAllowed bool) (*models.Post, error) {
-	ret := _mock.Called(ctx, userID, title, content, areCommentsAllowed)
+// CountRepliesByParentIDs provides a mock function for the type MockCommentsRepository
+func (_mock *MockCommentsRepository) CountRepliesByParentIDs(ctx context.Context, parentIDs []uuid.UUID) (map[uuid.UUID]int32, error) {
+	ret := _mock.Called(ctx, parentIDs)
 
 	if len(ret) == 0 {
-		panic("no return value specified for Add")
+		panic("no return value specified for CountRepliesByParentIDs")
 	}
 
-	var r0 *models.Post
+	var r0 map[uuid.UUID]int32
 	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID, string, string, bool) (*models.Post, error)); ok {
-		return returnFunc(ctx, userID, title, content, areCommentsAllowed)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, []uuid.UUID) (map[uuid.UUID]int32, error)); ok {
+		return returnFunc(ctx, parentIDs)
 	}
-	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID, string, string, bool) *models.Post); ok {
-		r0 = returnFunc(ctx, userID, title, content, areCommentsAllowed)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, []uuid.UUID) map[uuid.UUID]int32); ok {
+		r0 = returnFunc(ctx, parentIDs)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*models.Post)
+			r0 = ret.Get(0).(map[uuid.UUID]int32)
 		}
 	}
-	if returnFunc, ok := ret.Get(1).(func(context.Context, uuid.UUID, string, string, bool) error); ok {
-		r1 = returnFunc(ctx, userID, title, content, areCommentsAllowed)
+	if returnFunc, ok := ret.Get(1).(func(context.Context, []uuid.UUID) error); ok {
+		r1 = returnFunc(ctx, parentIDs)
 	} else {
 		r1 = ret.Error(1)
 	}
 	return r0, r1
 }
 
-// MockPostsRepository_Add_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Add'
-type MockPostsRepository_Add_Call struct {
+// MockCommentsRepository_CountRepliesByParentIDs_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CountRepliesByParentIDs'
+type MockCommentsRepository_CountRepliesByParentIDs_Call struct {
 	*mock.Call
 }
 
-// Add is a helper method to define mock.On call
+// CountRepliesByParentIDs is a helper method to define mock.On call
 //   - ctx context.Context
-//   - userID uuid.UUID
-//   - title string
-//   - content string
-//   - areCommentsAllowed bool
-func (_e *MockPostsRepository_Expecter) Add(ctx interface{}, userID interface{}, title interface{}, content interface{}, areCommentsAllowed interface{}) *MockPostsRepository_Add_Call {
-	return &MockPostsRepository_Add_Call{Call: _e.mock.On("Add", ctx, userID, title, content, areCommentsAllowed)}
+//   - parentIDs []uuid.UUID
+func (_e *MockCommentsRepository_Expecter) CountRepliesByParentIDs(ctx interface{}, parentIDs interface{}) *MockCommentsRepository_CountRepliesByParentIDs_Call {
+	return &MockCommentsRepository_CountRepliesByParentIDs_Call{Call: _e.mock.On("CountRepliesByParentIDs", ctx, parentIDs)}
 }
 
-func (_c *MockPostsRepository_Add_Call) Run(run func(ctx context.Context, userID uuid.UUID, title string, content string, areCommentsAllowed bool)) *MockPostsRepository_Add_Call {
+func (_c *MockCommentsRepository_CountRepliesByParentIDs_Call) Run(run func(ctx context.Context, parentIDs []uuid.UUID)) *MockCommentsRepository_CountRepliesByParentIDs_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		var arg0 context.Context
 		if args[0] != nil {
 			arg0 = args[0].(context.Context)
 		}
-		var arg1 uuid.UUID
+		var arg1 []uuid.UUID
 		if args[1] != nil {
-			arg1 = args[1].(uuid.UUID)
-		}
-		var arg2 string
-		if args[2] != nil {
-			arg2 = args[2].(string)
-		}
-		var arg3 string
-		if args[3] != nil {
-			arg3 = args[3].(string)
-		}
-		var arg4 bool
-		if args[4] != nil {
-			arg4 = args[4].(bool)
+			arg1 = args[1].([]uuid.UUID)
 		}
 		run(
 			arg0,
 			arg1,
-			arg2,
-			arg3,
-			arg4,
 		)
 	})
 	return _c
 }
 
-func (_c *MockPostsRepository_Add_Call) Return(post *models.Post, err error) *MockPostsRepository_Add_Call {
-	_c.Call.Return(post, err)
+func (_c *MockCommentsRepository_CountRepliesByParentIDs_Call) Return(m map[uuid.UUID]int32, err error) *MockCommentsRepository_CountRepliesByParentIDs_Call {
+	_c.Call.Return(m, err)
 	return _c
 }
 
-func (_c *MockPostsRepository_Add_Call) RunAndReturn(run func(ctx context.Context, userID uuid.UUID, title string, content string, areCommentsAllowed bool) (*models.Post, error)) *MockPostsRepository_Add_Call {
+func (_c *MockCommentsRepository_CountRepliesByParentIDs_Call) RunAndReturn(run func(ctx context.Context, parentIDs []uuid.UUID) (map[uuid.UUID]int32, error)) *MockCommentsRepository_CountRepliesByParentIDs_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// DisableComments provides a mock function for the type MockPostsRepository
-func (_mock *MockPostsRepository) DisableComments(ctx context.Context, postID uuid.UUID) (*models.Post, error) {
-	ret := _mock.Called(ctx, postID)
+// Delete provides a mock function for the type MockCommentsRepository
+func (_mock *MockCommentsRepository) Delete(ctx context.Context, commentID uuid.UUID) error {
+	ret := _mock.Called(ctx, commentID)
 
 	if len(ret) == 0 {
-		panic("no return value specified for DisableComments")
+		panic("no return value specified for Delete")
 	}
 
-	var r0 *models.Post
-	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID) (*models.Post, error)); ok {
-		return returnFunc(ctx, postID)
-	}
-	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID) *models.Post); ok {
-		r0 = returnFunc(ctx, postID)
-	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*models.Post)
-		}
-	}
-	if returnFunc, ok := ret.Get(1).(func(context.Context, uuid.UUID) error); ok {
-		r1 = returnFunc(ctx, postID)
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID) error); ok {
+		r0 = returnFunc(ctx, commentID)
 	} else {
-		r1 = ret.Error(1)
+		r0 = ret.Error(0)
 	}
-	return r0, r1
+	return r0
 }
 
-// MockPostsRepository_DisableComments_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DisableComments'
-type MockPostsRepository_DisableComments_Call struct {
+// MockCommentsRepository_Delete_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Delete'
+type MockCommentsRepository_Delete_Call struct {
 	*mock.Call
 }
 
-// DisableComments is a helper method to define mock.On call
+// Delete is a helper method to define mock.On call
 //   - ctx context.Context
-//   - postID uuid.UUID
-func (_e *MockPostsRepository_Expecter) DisableComments(ctx interface{}, postID interface{}) *MockPostsRepository_DisableComments_Call {
-	return &MockPostsRepository_DisableComments_Call{Call: _e.mock.On("DisableComments", ctx, postID)}
+//   - commentID uuid.UUID
+func (_e *MockCommentsRepository_Expecter) Delete(ctx interface{}, commentID interface{}) *MockCommentsRepository_Delete_Call {
+	return &MockCommentsRepository_Delete_Call{Call: _e.mock.On("Delete", ctx, commentID)}
 }
 
-func (_c *MockPostsRepository_DisableComments_Call) Run(run func(ctx context.Context, postID uuid.UUID)) *MockPostsRepository_DisableComments_Call {
+func (_c *MockCommentsRepository_Delete_Call) Run(run func(ctx context.Context, commentID uuid.UUID)) *MockCommentsRepository_Delete_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		var arg0 context.Context
 		if args[0] != nil {
@@ -524,57 +468,57 @@ func (_c *MockPostsRepository_DisableComments_Call) Run(run func(ctx context.Con
 	return _c
 }
 
-func (_c *MockPostsRepository_DisableComments_Call) Return(post *models.Post, err error) *MockPostsRepository_DisableComments_Call {
-	_c.Call.Return(post, err)
+func (_c *MockCommentsRepository_Delete_Call) Return(err error) *MockCommentsRepository_Delete_Call {
+	_c.Call.Return(err)
 	return _c
 }
 
-func (_c *MockPostsRepository_DisableComments_Call) RunAndReturn(run func(ctx context.Context, postID uuid.UUID) (*models.Post, error)) *MockPostsRepository_DisableComments_Call {
+func (_c *MockCommentsRepository_Delete_Call) RunAndReturn(run func(ctx context.Context, commentID uuid.UUID) error) *MockCommentsRepository_Delete_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// EnableComments provides a mock function for the type MockPostsRepository
-func (_mock *MockPostsRepository) EnableComments(ctx context.Context, postID uuid.UUID) (*models.Post, error) {
-	ret := _mock.Called(ctx, postID)
+// DeleteReaction provides a mock function for the type MockCommentsRepository
+func (_mock *MockCommentsRepository) DeleteReaction(ctx context.Context, commentID uuid.UUID, userID uuid.UUID, emoji string) (bool, error) {
+	ret := _mock.Called(ctx, commentID, userID, emoji)
 
 	if len(ret) == 0 {
-		panic("no return value specified for EnableComments")
+		panic("no return value specified for DeleteReaction")
 	}
 
-	var r0 *models.Post
+	var r0 bool
 	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID) (*models.Post, error)); ok {
-		return returnFunc(ctx, postID)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID, string) (bool, error)); ok {
+		return returnFunc(ctx, commentID, userID, emoji)
 	}
-	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID) *models.Post); ok {
-		r0 = returnFunc(ctx, postID)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID, string) bool); ok {
+		r0 = returnFunc(ctx, commentID, userID, emoji)
 	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*models.Post)
-		}
+		r0 = ret.Get(0).(bool)
 	}
-	if returnFunc, ok := ret.Get(1).(func(context.Context, uuid.UUID) error); ok {
-		r1 = returnFunc(ctx, postID)
+	if returnFunc, ok := ret.Get(1).(func(context.Context, uuid.UUID, uuid.UUID, string) error); ok {
+		r1 = returnFunc(ctx, commentID, userID, emoji)
 	} else {
 		r1 = ret.Error(1)
 	}
 	return r0, r1
 }
 
-// MockPostsRepository_EnableComments_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'EnableComments'
-type MockPostsRepository_EnableComments_Call struct {
+// MockCommentsRepository_DeleteReaction_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteReaction'
+type MockCommentsRepository_DeleteReaction_Call struct {
 	*mock.Call
 }
 
-// EnableComments is a helper method to define mock.On call
+// DeleteReaction is a helper method to define mock.On call
 //   - ctx context.Context
-//   - postID uuid.UUID
-func (_e *MockPostsRepository_Expecter) EnableComments(ctx interface{}, postID interface{}) *MockPostsRepository_EnableComments_Call {
-	return &MockPostsRepository_EnableComments_Call{Call: _e.mock.On("EnableComments", ctx, postID)}
+//   - commentID uuid.UUID
+//   - userID uuid.UUID
+//   - emoji string
+func (_e *MockCommentsRepository_Expecter) DeleteReaction(ctx interface{}, commentID interface{}, userID interface{}, emoji interface{}) *MockCommentsRepository_DeleteReaction_Call {
+	return &MockCommentsRepository_DeleteReaction_Call{Call: _e.mock.On("DeleteReaction", ctx, commentID, userID, emoji)}
 }
 
-func (_c *MockPostsRepository_EnableComments_Call) Run(run func(ctx context.Context, postID uuid.UUID)) *MockPostsRepository_EnableComments_Call {
+func (_c *MockCommentsRepository_DeleteReaction_Call) Run(run func(ctx context.Context, commentID uuid.UUID, userID uuid.UUID, emoji string)) *MockCommentsRepository_DeleteReaction_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		var arg0 context.Context
 		if args[0] != nil {
@@ -584,136 +528,3803 @@ func (_c *MockPostsRepository_EnableComments_Call) Run(run func(ctx context.Cont
 		if args[1] != nil {
 			arg1 = args[1].(uuid.UUID)
 		}
+		var arg2 uuid.UUID
+		if args[2] != nil {
+			arg2 = args[2].(uuid.UUID)
+		}
+		var arg3 string
+		if args[3] != nil {
+			arg3 = args[3].(string)
+		}
 		run(
 			arg0,
 			arg1,
+			arg2,
+			arg3,
 		)
 	})
 	return _c
 }
 
-func (_c *MockPostsRepository_EnableComments_Call) Return(post *models.Post, err error) *MockPostsRepository_EnableComments_Call {
-	_c.Call.Return(post, err)
+func (_c *MockCommentsRepository_DeleteReaction_Call) Return(b bool, err error) *MockCommentsRepository_DeleteReaction_Call {
+	_c.Call.Return(b, err)
 	return _c
 }
 
-func (_c *MockPostsRepository_EnableComments_Call) RunAndReturn(run func(ctx context.Context, postID uuid.UUID) (*models.Post, error)) *MockPostsRepository_EnableComments_Call {
+func (_c *MockCommentsRepository_DeleteReaction_Call) RunAndReturn(run func(ctx context.Context, commentID uuid.UUID, userID uuid.UUID, emoji string) (bool, error)) *MockCommentsRepository_DeleteReaction_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// GetAll provides a mock function for the type MockPostsRepository
-func (_mock *MockPostsRepository) GetAll(ctx context.Context) ([]*models.Post, error) {
-	ret := _mock.Called(ctx)
+// DeleteVote provides a mock function for the type MockCommentsRepository
+func (_mock *MockCommentsRepository) DeleteVote(ctx context.Context, commentID uuid.UUID, userID uuid.UUID) error {
+	ret := _mock.Called(ctx, commentID, userID)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetAll")
+		panic("no return value specified for DeleteVote")
 	}
 
-	var r0 []*models.Post
-	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context) ([]*models.Post, error)); ok {
-		return returnFunc(ctx)
-	}
-	if returnFunc, ok := ret.Get(0).(func(context.Context) []*models.Post); ok {
-		r0 = returnFunc(ctx)
-	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]*models.Post)
-		}
-	}
-	if returnFunc, ok := ret.Get(1).(func(context.Context) error); ok {
-		r1 = returnFunc(ctx)
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID) error); ok {
+		r0 = returnFunc(ctx, commentID, userID)
 	} else {
-		r1 = ret.Error(1)
+		r0 = ret.Error(0)
 	}
-	return r0, r1
+	return r0
 }
 
-// MockPostsRepository_GetAll_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetAll'
-type MockPostsRepository_GetAll_Call struct {
+// MockCommentsRepository_DeleteVote_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteVote'
+type MockCommentsRepository_DeleteVote_Call struct {
 	*mock.Call
 }
 
-// GetAll is a helper method to define mock.On call
+// DeleteVote is a helper method to define mock.On call
 //   - ctx context.Context
-func (_e *MockPostsRepository_Expecter) GetAll(ctx interface{}) *MockPostsRepository_GetAll_Call {
-	return &MockPostsRepository_GetAll_Call{Call: _e.mock.On("GetAll", ctx)}
+//   - commentID uuid.UUID
+//   - userID uuid.UUID
+func (_e *MockCommentsRepository_Expecter) DeleteVote(ctx interface{}, commentID interface{}, userID interface{}) *MockCommentsRepository_DeleteVote_Call {
+	return &MockCommentsRepository_DeleteVote_Call{Call: _e.mock.On("DeleteVote", ctx, commentID, userID)}
 }
 
-func (_c *MockPostsRepository_GetAll_Call) Run(run func(ctx context.Context)) *MockPostsRepository_GetAll_Call {
+func (_c *MockCommentsRepository_DeleteVote_Call) Run(run func(ctx context.Context, commentID uuid.UUID, userID uuid.UUID)) *MockCommentsRepository_DeleteVote_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		var arg0 context.Context
 		if args[0] != nil {
 			arg0 = args[0].(context.Context)
 		}
+		var arg1 uuid.UUID
+		if args[1] != nil {
+			arg1 = args[1].(uuid.UUID)
+		}
+		var arg2 uuid.UUID
+		if args[2] != nil {
+			arg2 = args[2].(uuid.UUID)
+		}
 		run(
 			arg0,
+			arg1,
+			arg2,
 		)
 	})
 	return _c
 }
 
-func (_c *MockPostsRepository_GetAll_Call) Return(posts []*models.Post, err error) *MockPostsRepository_GetAll_Call {
-	_c.Call.Return(posts, err)
+func (_c *MockCommentsRepository_DeleteVote_Call) Return(err error) *MockCommentsRepository_DeleteVote_Call {
+	_c.Call.Return(err)
 	return _c
 }
 
-func (_c *MockPostsRepository_GetAll_Call) RunAndReturn(run func(ctx context.Context) ([]*models.Post, error)) *MockPostsRepository_GetAll_Call {
+func (_c *MockCommentsRepository_DeleteVote_Call) RunAndReturn(run func(ctx context.Context, commentID uuid.UUID, userID uuid.UUID) error) *MockCommentsRepository_DeleteVote_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// GetByID provides a mock function for the type MockPostsRepository
-func (_mock *MockPostsRepository) GetByID(ctx context.Context, postID uuid.UUID, forUpdate bool) (*models.Post, error) {
-	ret := _mock.Called(ctx, postID, forUpdate)
+// GetByID provides a mock function for the type MockCommentsRepository
+func (_mock *MockCommentsRepository) GetByID(ctx context.Context, commentID uuid.UUID, forUpdate bool) (*models.Comment, error) {
+	ret := _mock.Called(ctx, commentID, forUpdate)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GetByID")
 	}
 
-	var r0 *models.Post
+	var r0 *models.Comment
 	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID, bool) (*models.Post, error)); ok {
-		return returnFunc(ctx, postID, forUpdate)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID, bool) (*models.Comment, error)); ok {
+		return returnFunc(ctx, commentID, forUpdate)
 	}
-	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID, bool) *models.Post); ok {
-		r0 = returnFunc(ctx, postID, forUpdate)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID, bool) *models.Comment); ok {
+		r0 = returnFunc(ctx, commentID, forUpdate)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*models.Post)
+			r0 = ret.Get(0).(*models.Comment)
 		}
 	}
 	if returnFunc, ok := ret.Get(1).(func(context.Context, uuid.UUID, bool) error); ok {
-		r1 = returnFunc(ctx, postID, forUpdate)
+		r1 = returnFunc(ctx, commentID, forUpdate)
 	} else {
 		r1 = ret.Error(1)
 	}
 	return r0, r1
 }
 
-// MockPostsRepository_GetByID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetByID'
-type MockPostsRepository_GetByID_Call struct {
+// MockCommentsRepository_GetByID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetByID'
+type MockCommentsRepository_GetByID_Call struct {
 	*mock.Call
 }
 
 // GetByID is a helper method to define mock.On call
 //   - ctx context.Context
-//   - postID uuid.UUID
+//   - commentID uuid.UUID
 //   - forUpdate bool
-func (_e *MockPostsRepository_Expecter) GetByID(ctx interface{}, postID interface{}, forUpdate interface{}) *MockPostsRepository_GetByID_Call {
-	return &MockPostsRepository_GetByID_Call{Call: _e.mock.On("GetByID", ctx, postID, forUpdate)}
-}
+func (_e *MockCommentsRepository_Expecter) GetByID(ctx interface{}, commentID interface{}, forUpdate interface{}) *MockCommentsRepository_GetByID_Call {
+	return &MockCommentsRepository_GetByID_Call{Call: _e.mock.On("GetByID", ctx, commentID, forUpdate)}
+}
+
+func (_c *MockCommentsRepository_GetByID_Call) Run(run func(ctx context.Context, commentID uuid.UUID, forUpdate bool)) *MockCommentsRepository_GetByID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uuid.UUID
+		if args[1] != nil {
+			arg1 = args[1].(uuid.UUID)
+		}
+		var arg2 bool
+		if args[2] != nil {
+			arg2 = args[2].(bool)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockCommentsRepository_GetByID_Call) Return(comment *models.Comment, err error) *MockCommentsRepository_GetByID_Call {
+	_c.Call.Return(comment, err)
+	return _c
+}
+
+func (_c *MockCommentsRepository_GetByID_Call) RunAndReturn(run func(ctx context.Context, commentID uuid.UUID, forUpdate bool) (*models.Comment, error)) *MockCommentsRepository_GetByID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetByIDs provides a mock function for the type MockCommentsRepository
+func (_mock *MockCommentsRepository) GetByIDs(ctx context.Context, IDs []uuid.UUID) ([]*models.Comment, error) {
+	ret := _mock.Called(ctx, IDs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByIDs")
+	}
+
+	var r0 []*models.Comment
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, []uuid.UUID) ([]*models.Comment, error)); ok {
+		return returnFunc(ctx, IDs)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, []uuid.UUID) []*models.Comment); ok {
+		r0 = returnFunc(ctx, IDs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Comment)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, []uuid.UUID) error); ok {
+		r1 = returnFunc(ctx, IDs)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockCommentsRepository_GetByIDs_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetByIDs'
+type MockCommentsRepository_GetByIDs_Call struct {
+	*mock.Call
+}
+
+// GetByIDs is a helper method to define mock.On call
+//   - ctx context.Context
+//   - IDs []uuid.UUID
+func (_e *MockCommentsRepository_Expecter) GetByIDs(ctx interface{}, IDs interface{}) *MockCommentsRepository_GetByIDs_Call {
+	return &MockCommentsRepository_GetByIDs_Call{Call: _e.mock.On("GetByIDs", ctx, IDs)}
+}
+
+func (_c *MockCommentsRepository_GetByIDs_Call) Run(run func(ctx context.Context, IDs []uuid.UUID)) *MockCommentsRepository_GetByIDs_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 []uuid.UUID
+		if args[1] != nil {
+			arg1 = args[1].([]uuid.UUID)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockCommentsRepository_GetByIDs_Call) Return(comments []*models.Comment, err error) *MockCommentsRepository_GetByIDs_Call {
+	_c.Call.Return(comments, err)
+	return _c
+}
+
+func (_c *MockCommentsRepository_GetByIDs_Call) RunAndReturn(run func(ctx context.Context, IDs []uuid.UUID) ([]*models.Comment, error)) *MockCommentsRepository_GetByIDs_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetByPostID provides a mock function for the type MockCommentsRepository
+func (_mock *MockCommentsRepository) GetByPostID(ctx context.Context, postID uuid.UUID, page *pagination.Page) ([]*models.Comment, error) {
+	ret := _mock.Called(ctx, postID, page)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByPostID")
+	}
+
+	var r0 []*models.Comment
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID, *pagination.Page) ([]*models.Comment, error)); ok {
+		return returnFunc(ctx, postID, page)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID, *pagination.Page) []*models.Comment); ok {
+		r0 = returnFunc(ctx, postID, page)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Comment)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, uuid.UUID, *pagination.Page) error); ok {
+		r1 = returnFunc(ctx, postID, page)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockCommentsRepository_GetByPostID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetByPostID'
+type MockCommentsRepository_GetByPostID_Call struct {
+	*mock.Call
+}
+
+// GetByPostID is a helper method to define mock.On call
+//   - ctx context.Context
+//   - postID uuid.UUID
+//   - page *pagination.Page
+func (_e *MockCommentsRepository_Expecter) GetByPostID(ctx interface{}, postID interface{}, page interface{}) *MockCommentsRepository_GetByPostID_Call {
+	return &MockCommentsRepository_GetByPostID_Call{Call: _e.mock.On("GetByPostID", ctx, postID, page)}
+}
+
+func (_c *MockCommentsRepository_GetByPostID_Call) Run(run func(ctx context.Context, postID uuid.UUID, page *pagination.Page)) *MockCommentsRepository_GetByPostID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uuid.UUID
+		if args[1] != nil {
+			arg1 = args[1].(uuid.UUID)
+		}
+		var arg2 *pagination.Page
+		if args[2] != nil {
+			arg2 = args[2].(*pagination.Page)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockCommentsRepository_GetByPostID_Call) Return(comments []*models.Comment, err error) *MockCommentsRepository_GetByPostID_Call {
+	_c.Call.Return(comments, err)
+	return _c
+}
+
+func (_c *MockCommentsRepository_GetByPostID_Call) RunAndReturn(run func(ctx context.Context, postID uuid.UUID, page *pagination.Page) ([]*models.Comment, error)) *MockCommentsRepository_GetByPostID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetReactions provides a mock function for the type MockCommentsRepository
+func (_mock *MockCommentsRepository) GetReactions(ctx context.Context, commentIDs []uuid.UUID, viewerID *uuid.UUID) (map[uuid.UUID][]*models.ReactionCount, error) {
+	ret := _mock.Called(ctx, commentIDs, viewerID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetReactions")
+	}
+
+	var r0 map[uuid.UUID][]*models.ReactionCount
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, []uuid.UUID, *uuid.UUID) (map[uuid.UUID][]*models.ReactionCount, error)); ok {
+		return returnFunc(ctx, commentIDs, viewerID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, []uuid.UUID, *uuid.UUID) map[uuid.UUID][]*models.ReactionCount); ok {
+		r0 = returnFunc(ctx, commentIDs, viewerID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[uuid.UUID][]*models.ReactionCount)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, []uuid.UUID, *uuid.UUID) error); ok {
+		r1 = returnFunc(ctx, commentIDs, viewerID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockCommentsRepository_GetReactions_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetReactions'
+type MockCommentsRepository_GetReactions_Call struct {
+	*mock.Call
+}
+
+// GetReactions is a helper method to define mock.On call
+//   - ctx context.Context
+//   - commentIDs []uuid.UUID
+//   - viewerID *uuid.UUID
+func (_e *MockCommentsRepository_Expecter) GetReactions(ctx interface{}, commentIDs interface{}, viewerID interface{}) *MockCommentsRepository_GetReactions_Call {
+	return &MockCommentsRepository_GetReactions_Call{Call: _e.mock.On("GetReactions", ctx, commentIDs, viewerID)}
+}
+
+func (_c *MockCommentsRepository_GetReactions_Call) Run(run func(ctx context.Context, commentIDs []uuid.UUID, viewerID *uuid.UUID)) *MockCommentsRepository_GetReactions_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 []uuid.UUID
+		if args[1] != nil {
+			arg1 = args[1].([]uuid.UUID)
+		}
+		var arg2 *uuid.UUID
+		if args[2] != nil {
+			arg2 = args[2].(*uuid.UUID)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockCommentsRepository_GetReactions_Call) Return(m map[uuid.UUID][]*models.ReactionCount, err error) *MockCommentsRepository_GetReactions_Call {
+	_c.Call.Return(m, err)
+	return _c
+}
+
+func (_c *MockCommentsRepository_GetReactions_Call) RunAndReturn(run func(ctx context.Context, commentIDs []uuid.UUID, viewerID *uuid.UUID) (map[uuid.UUID][]*models.ReactionCount, error)) *MockCommentsRepository_GetReactions_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetRepliesByParentID provides a mock function for the type MockCommentsRepository
+func (_mock *MockCommentsRepository) GetRepliesByParentID(ctx context.Context, parentID uuid.UUID, page *pagination.Page) ([]*models.Comment, error) {
+	ret := _mock.Called(ctx, parentID, page)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetRepliesByParentID")
+	}
+
+	var r0 []*models.Comment
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID, *pagination.Page) ([]*models.Comment, error)); ok {
+		return returnFunc(ctx, parentID, page)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID, *pagination.Page) []*models.Comment); ok {
+		r0 = returnFunc(ctx, parentID, page)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Comment)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, uuid.UUID, *pagination.Page) error); ok {
+		r1 = returnFunc(ctx, parentID, page)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockCommentsRepository_GetRepliesByParentID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetRepliesByParentID'
+type MockCommentsRepository_GetRepliesByParentID_Call struct {
+	*mock.Call
+}
+
+// GetRepliesByParentID is a helper method to define mock.On call
+//   - ctx context.Context
+//   - parentID uuid.UUID
+//   - page *pagination.Page
+func (_e *MockCommentsRepository_Expecter) GetRepliesByParentID(ctx interface{}, parentID interface{}, page interface{}) *MockCommentsRepository_GetRepliesByParentID_Call {
+	return &MockCommentsRepository_GetRepliesByParentID_Call{Call: _e.mock.On("GetRepliesByParentID", ctx, parentID, page)}
+}
+
+func (_c *MockCommentsRepository_GetRepliesByParentID_Call) Run(run func(ctx context.Context, parentID uuid.UUID, page *pagination.Page)) *MockCommentsRepository_GetRepliesByParentID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uuid.UUID
+		if args[1] != nil {
+			arg1 = args[1].(uuid.UUID)
+		}
+		var arg2 *pagination.Page
+		if args[2] != nil {
+			arg2 = args[2].(*pagination.Page)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockCommentsRepository_GetRepliesByParentID_Call) Return(comments []*models.Comment, err error) *MockCommentsRepository_GetRepliesByParentID_Call {
+	_c.Call.Return(comments, err)
+	return _c
+}
+
+func (_c *MockCommentsRepository_GetRepliesByParentID_Call) RunAndReturn(run func(ctx context.Context, parentID uuid.UUID, page *pagination.Page) ([]*models.Comment, error)) *MockCommentsRepository_GetRepliesByParentID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetRepliesByParentIDs provides a mock function for the type MockCommentsRepository
+func (_mock *MockCommentsRepository) GetRepliesByParentIDs(ctx context.Context, parentIDs []uuid.UUID, limit int32) ([]*models.Comment, error) {
+	ret := _mock.Called(ctx, parentIDs, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetRepliesByParentIDs")
+	}
+
+	var r0 []*models.Comment
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, []uuid.UUID, int32) ([]*models.Comment, error)); ok {
+		return returnFunc(ctx, parentIDs, limit)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, []uuid.UUID, int32) []*models.Comment); ok {
+		r0 = returnFunc(ctx, parentIDs, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Comment)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, []uuid.UUID, int32) error); ok {
+		r1 = returnFunc(ctx, parentIDs, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockCommentsRepository_GetRepliesByParentIDs_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetRepliesByParentIDs'
+type MockCommentsRepository_GetRepliesByParentIDs_Call struct {
+	*mock.Call
+}
+
+// GetRepliesByParentIDs is a helper method to define mock.On call
+//   - ctx context.Context
+//   - parentIDs []uuid.UUID
+//   - limit int32
+func (_e *MockCommentsRepository_Expecter) GetRepliesByParentIDs(ctx interface{}, parentIDs interface{}, limit interface{}) *MockCommentsRepository_GetRepliesByParentIDs_Call {
+	return &MockCommentsRepository_GetRepliesByParentIDs_Call{Call: _e.mock.On("GetRepliesByParentIDs", ctx, parentIDs, limit)}
+}
+
+func (_c *MockCommentsRepository_GetRepliesByParentIDs_Call) Run(run func(ctx context.Context, parentIDs []uuid.UUID, limit int32)) *MockCommentsRepository_GetRepliesByParentIDs_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 []uuid.UUID
+		if args[1] != nil {
+			arg1 = args[1].([]uuid.UUID)
+		}
+		var arg2 int32
+		if args[2] != nil {
+			arg2 = args[2].(int32)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockCommentsRepository_GetRepliesByParentIDs_Call) Return(comments []*models.Comment, err error) *MockCommentsRepository_GetRepliesByParentIDs_Call {
+	_c.Call.Return(comments, err)
+	return _c
+}
+
+func (_c *MockCommentsRepository_GetRepliesByParentIDs_Call) RunAndReturn(run func(ctx context.Context, parentIDs []uuid.UUID, limit int32) ([]*models.Comment, error)) *MockCommentsRepository_GetRepliesByParentIDs_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetRevisionsByCommentIDs provides a mock function for the type MockCommentsRepository
+func (_mock *MockCommentsRepository) GetRevisionsByCommentIDs(ctx context.Context, commentIDs []uuid.UUID) (map[uuid.UUID][]*models.CommentRevision, error) {
+	ret := _mock.Called(ctx, commentIDs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetRevisionsByCommentIDs")
+	}
+
+	var r0 map[uuid.UUID][]*models.CommentRevision
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, []uuid.UUID) (map[uuid.UUID][]*models.CommentRevision, error)); ok {
+		return returnFunc(ctx, commentIDs)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, []uuid.UUID) map[uuid.UUID][]*models.CommentRevision); ok {
+		r0 = returnFunc(ctx, commentIDs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[uuid.UUID][]*models.CommentRevision)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, []uuid.UUID) error); ok {
+		r1 = returnFunc(ctx, commentIDs)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockCommentsRepository_GetRevisionsByCommentIDs_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetRevisionsByCommentIDs'
+type MockCommentsRepository_GetRevisionsByCommentIDs_Call struct {
+	*mock.Call
+}
+
+// GetRevisionsByCommentIDs is a helper method to define mock.On call
+//   - ctx context.Context
+//   - commentIDs []uuid.UUID
+func (_e *MockCommentsRepository_Expecter) GetRevisionsByCommentIDs(ctx interface{}, commentIDs interface{}) *MockCommentsRepository_GetRevisionsByCommentIDs_Call {
+	return &MockCommentsRepository_GetRevisionsByCommentIDs_Call{Call: _e.mock.On("GetRevisionsByCommentIDs", ctx, commentIDs)}
+}
+
+func (_c *MockCommentsRepository_GetRevisionsByCommentIDs_Call) Run(run func(ctx context.Context, commentIDs []uuid.UUID)) *MockCommentsRepository_GetRevisionsByCommentIDs_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 []uuid.UUID
+		if args[1] != nil {
+			arg1 = args[1].([]uuid.UUID)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockCommentsRepository_GetRevisionsByCommentIDs_Call) Return(m map[uuid.UUID][]*models.CommentRevision, err error) *MockCommentsRepository_GetRevisionsByCommentIDs_Call {
+	_c.Call.Return(m, err)
+	return _c
+}
+
+func (_c *MockCommentsRepository_GetRevisionsByCommentIDs_Call) RunAndReturn(run func(ctx context.Context, commentIDs []uuid.UUID) (map[uuid.UUID][]*models.CommentRevision, error)) *MockCommentsRepository_GetRevisionsByCommentIDs_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetRootCommentsByPostID provides a mock function for the type MockCommentsRepository
+func (_mock *MockCommentsRepository) GetRootCommentsByPostID(ctx context.Context, postID uuid.UUID, page *pagination.Page) ([]*models.Comment, error) {
+	ret := _mock.Called(ctx, postID, page)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetRootCommentsByPostID")
+	}
+
+	var r0 []*models.Comment
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID, *pagination.Page) ([]*models.Comment, error)); ok {
+		return returnFunc(ctx, postID, page)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID, *pagination.Page) []*models.Comment); ok {
+		r0 = returnFunc(ctx, postID, page)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Comment)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, uuid.UUID, *pagination.Page) error); ok {
+		r1 = returnFunc(ctx, postID, page)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockCommentsRepository_GetRootCommentsByPostID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetRootCommentsByPostID'
+type MockCommentsRepository_GetRootCommentsByPostID_Call struct {
+	*mock.Call
+}
+
+// GetRootCommentsByPostID is a helper method to define mock.On call
+//   - ctx context.Context
+//   - postID uuid.UUID
+//   - page *pagination.Page
+func (_e *MockCommentsRepository_Expecter) GetRootCommentsByPostID(ctx interface{}, postID interface{}, page interface{}) *MockCommentsRepository_GetRootCommentsByPostID_Call {
+	return &MockCommentsRepository_GetRootCommentsByPostID_Call{Call: _e.mock.On("GetRootCommentsByPostID", ctx, postID, page)}
+}
+
+func (_c *MockCommentsRepository_GetRootCommentsByPostID_Call) Run(run func(ctx context.Context, postID uuid.UUID, page *pagination.Page)) *MockCommentsRepository_GetRootCommentsByPostID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uuid.UUID
+		if args[1] != nil {
+			arg1 = args[1].(uuid.UUID)
+		}
+		var arg2 *pagination.Page
+		if args[2] != nil {
+			arg2 = args[2].(*pagination.Page)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockCommentsRepository_GetRootCommentsByPostID_Call) Return(comments []*models.Comment, err error) *MockCommentsRepository_GetRootCommentsByPostID_Call {
+	_c.Call.Return(comments, err)
+	return _c
+}
+
+func (_c *MockCommentsRepository_GetRootCommentsByPostID_Call) RunAndReturn(run func(ctx context.Context, postID uuid.UUID, page *pagination.Page) ([]*models.Comment, error)) *MockCommentsRepository_GetRootCommentsByPostID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetVote provides a mock function for the type MockCommentsRepository
+func (_mock *MockCommentsRepository) GetVote(ctx context.Context, commentID uuid.UUID, userID uuid.UUID) (*models.CommentVote, error) {
+	ret := _mock.Called(ctx, commentID, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetVote")
+	}
+
+	var r0 *models.CommentVote
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID) (*models.CommentVote, error)); ok {
+		return returnFunc(ctx, commentID, userID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID) *models.CommentVote); ok {
+		r0 = returnFunc(ctx, commentID, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.CommentVote)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, uuid.UUID, uuid.UUID) error); ok {
+		r1 = returnFunc(ctx, commentID, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockCommentsRepository_GetVote_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetVote'
+type MockCommentsRepository_GetVote_Call struct {
+	*mock.Call
+}
+
+// GetVote is a helper method to define mock.On call
+//   - ctx context.Context
+//   - commentID uuid.UUID
+//   - userID uuid.UUID
+func (_e *MockCommentsRepository_Expecter) GetVote(ctx interface{}, commentID interface{}, userID interface{}) *MockCommentsRepository_GetVote_Call {
+	return &MockCommentsRepository_GetVote_Call{Call: _e.mock.On("GetVote", ctx, commentID, userID)}
+}
+
+func (_c *MockCommentsRepository_GetVote_Call) Run(run func(ctx context.Context, commentID uuid.UUID, userID uuid.UUID)) *MockCommentsRepository_GetVote_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uuid.UUID
+		if args[1] != nil {
+			arg1 = args[1].(uuid.UUID)
+		}
+		var arg2 uuid.UUID
+		if args[2] != nil {
+			arg2 = args[2].(uuid.UUID)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockCommentsRepository_GetVote_Call) Return(commentVote *models.CommentVote, err error) *MockCommentsRepository_GetVote_Call {
+	_c.Call.Return(commentVote, err)
+	return _c
+}
+
+func (_c *MockCommentsRepository_GetVote_Call) RunAndReturn(run func(ctx context.Context, commentID uuid.UUID, userID uuid.UUID) (*models.CommentVote, error)) *MockCommentsRepository_GetVote_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// HasUndeletedReplies provides a mock function for the type MockCommentsRepository
+func (_mock *MockCommentsRepository) HasUndeletedReplies(ctx context.Context, commentID uuid.UUID) (bool, error) {
+	ret := _mock.Called(ctx, commentID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for HasUndeletedReplies")
+	}
+
+	var r0 bool
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID) (bool, error)); ok {
+		return returnFunc(ctx, commentID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID) bool); ok {
+		r0 = returnFunc(ctx, commentID)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, uuid.UUID) error); ok {
+		r1 = returnFunc(ctx, commentID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockCommentsRepository_HasUndeletedReplies_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'HasUndeletedReplies'
+type MockCommentsRepository_HasUndeletedReplies_Call struct {
+	*mock.Call
+}
+
+// HasUndeletedReplies is a helper method to define mock.On call
+//   - ctx context.Context
+//   - commentID uuid.UUID
+func (_e *MockCommentsRepository_Expecter) HasUndeletedReplies(ctx interface{}, commentID interface{}) *MockCommentsRepository_HasUndeletedReplies_Call {
+	return &MockCommentsRepository_HasUndeletedReplies_Call{Call: _e.mock.On("HasUndeletedReplies", ctx, commentID)}
+}
+
+func (_c *MockCommentsRepository_HasUndeletedReplies_Call) Run(run func(ctx context.Context, commentID uuid.UUID)) *MockCommentsRepository_HasUndeletedReplies_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uuid.UUID
+		if args[1] != nil {
+			arg1 = args[1].(uuid.UUID)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockCommentsRepository_HasUndeletedReplies_Call) Return(b bool, err error) *MockCommentsRepository_HasUndeletedReplies_Call {
+	_c.Call.Return(b, err)
+	return _c
+}
+
+func (_c *MockCommentsRepository_HasUndeletedReplies_Call) RunAndReturn(run func(ctx context.Context, commentID uuid.UUID) (bool, error)) *MockCommentsRepository_HasUndeletedReplies_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Search provides a mock function for the type MockCommentsRepository
+func (_mock *MockCommentsRepository) Search(ctx context.Context, query string, page *pagination.Page) ([]*models.SearchHit, error) {
+	ret := _mock.Called(ctx, query, page)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Search")
+	}
+
+	var r0 []*models.SearchHit
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, *pagination.Page) ([]*models.SearchHit, error)); ok {
+		return returnFunc(ctx, query, page)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, *pagination.Page) []*models.SearchHit); ok {
+		r0 = returnFunc(ctx, query, page)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.SearchHit)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, *pagination.Page) error); ok {
+		r1 = returnFunc(ctx, query, page)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockCommentsRepository_Search_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Search'
+type MockCommentsRepository_Search_Call struct {
+	*mock.Call
+}
+
+// Search is a helper method to define mock.On call
+//   - ctx context.Context
+//   - query string
+//   - page *pagination.Page
+func (_e *MockCommentsRepository_Expecter) Search(ctx interface{}, query interface{}, page interface{}) *MockCommentsRepository_Search_Call {
+	return &MockCommentsRepository_Search_Call{Call: _e.mock.On("Search", ctx, query, page)}
+}
+
+func (_c *MockCommentsRepository_Search_Call) Run(run func(ctx context.Context, query string, page *pagination.Page)) *MockCommentsRepository_Search_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 *pagination.Page
+		if args[2] != nil {
+			arg2 = args[2].(*pagination.Page)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockCommentsRepository_Search_Call) Return(searchHits []*models.SearchHit, err error) *MockCommentsRepository_Search_Call {
+	_c.Call.Return(searchHits, err)
+	return _c
+}
+
+func (_c *MockCommentsRepository_Search_Call) RunAndReturn(run func(ctx context.Context, query string, page *pagination.Page) ([]*models.SearchHit, error)) *MockCommentsRepository_Search_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetVote provides a mock function for the type MockCommentsRepository
+func (_mock *MockCommentsRepository) SetVote(ctx context.Context, commentID uuid.UUID, userID uuid.UUID, value int8) (*models.CommentVote, error) {
+	ret := _mock.Called(ctx, commentID, userID, value)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetVote")
+	}
+
+	var r0 *models.CommentVote
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID, int8) (*models.CommentVote, error)); ok {
+		return returnFunc(ctx, commentID, userID, value)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID, int8) *models.CommentVote); ok {
+		r0 = returnFunc(ctx, commentID, userID, value)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.CommentVote)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, uuid.UUID, uuid.UUID, int8) error); ok {
+		r1 = returnFunc(ctx, commentID, userID, value)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockCommentsRepository_SetVote_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetVote'
+type MockCommentsRepository_SetVote_Call struct {
+	*mock.Call
+}
+
+// SetVote is a helper method to define mock.On call
+//   - ctx context.Context
+//   - commentID uuid.UUID
+//   - userID uuid.UUID
+//   - value int8
+func (_e *MockCommentsRepository_Expecter) SetVote(ctx interface{}, commentID interface{}, userID interface{}, value interface{}) *MockCommentsRepository_SetVote_Call {
+	return &MockCommentsRepository_SetVote_Call{Call: _e.mock.On("SetVote", ctx, commentID, userID, value)}
+}
+
+func (_c *MockCommentsRepository_SetVote_Call) Run(run func(ctx context.Context, commentID uuid.UUID, userID uuid.UUID, value int8)) *MockCommentsRepository_SetVote_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uuid.UUID
+		if args[1] != nil {
+			arg1 = args[1].(uuid.UUID)
+		}
+		var arg2 uuid.UUID
+		if args[2] != nil {
+			arg2 = args[2].(uuid.UUID)
+		}
+		var arg3 int8
+		if args[3] != nil {
+			arg3 = args[3].(int8)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+			arg3,
+		)
+	})
+	return _c
+}
+
+func (_c *MockCommentsRepository_SetVote_Call) Return(commentVote *models.CommentVote, err error) *MockCommentsRepository_SetVote_Call {
+	_c.Call.Return(commentVote, err)
+	return _c
+}
+
+func (_c *MockCommentsRepository_SetVote_Call) RunAndReturn(run func(ctx context.Context, commentID uuid.UUID, userID uuid.UUID, value int8) (*models.CommentVote, error)) *MockCommentsRepository_SetVote_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SoftDelete provides a mock function for the type MockCommentsRepository
+func (_mock *MockCommentsRepository) SoftDelete(ctx context.Context, commentID uuid.UUID) (*models.Comment, error) {
+	ret := _mock.Called(ctx, commentID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SoftDelete")
+	}
+
+	var r0 *models.Comment
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID) (*models.Comment, error)); ok {
+		return returnFunc(ctx, commentID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID) *models.Comment); ok {
+		r0 = returnFunc(ctx, commentID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Comment)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, uuid.UUID) error); ok {
+		r1 = returnFunc(ctx, commentID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockCommentsRepository_SoftDelete_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SoftDelete'
+type MockCommentsRepository_SoftDelete_Call struct {
+	*mock.Call
+}
+
+// SoftDelete is a helper method to define mock.On call
+//   - ctx context.Context
+//   - commentID uuid.UUID
+func (_e *MockCommentsRepository_Expecter) SoftDelete(ctx interface{}, commentID interface{}) *MockCommentsRepository_SoftDelete_Call {
+	return &MockCommentsRepository_SoftDelete_Call{Call: _e.mock.On("SoftDelete", ctx, commentID)}
+}
+
+func (_c *MockCommentsRepository_SoftDelete_Call) Run(run func(ctx context.Context, commentID uuid.UUID)) *MockCommentsRepository_SoftDelete_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uuid.UUID
+		if args[1] != nil {
+			arg1 = args[1].(uuid.UUID)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockCommentsRepository_SoftDelete_Call) Return(comment *models.Comment, err error) *MockCommentsRepository_SoftDelete_Call {
+	_c.Call.Return(comment, err)
+	return _c
+}
+
+func (_c *MockCommentsRepository_SoftDelete_Call) RunAndReturn(run func(ctx context.Context, commentID uuid.UUID) (*models.Comment, error)) *MockCommentsRepository_SoftDelete_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdateContent provides a mock function for the type MockCommentsRepository
+func (_mock *MockCommentsRepository) UpdateContent(ctx context.Context, commentID uuid.UUID, content string) (*models.Comment, error) {
+	ret := _mock.Called(ctx, commentID, content)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateContent")
+	}
+
+	var r0 *models.Comment
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID, string) (*models.Comment, error)); ok {
+		return returnFunc(ctx, commentID, content)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID, string) *models.Comment); ok {
+		r0 = returnFunc(ctx, commentID, content)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Comment)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, uuid.UUID, string) error); ok {
+		r1 = returnFunc(ctx, commentID, content)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockCommentsRepository_UpdateContent_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateContent'
+type MockCommentsRepository_UpdateContent_Call struct {
+	*mock.Call
+}
+
+// UpdateContent is a helper method to define mock.On call
+//   - ctx context.Context
+//   - commentID uuid.UUID
+//   - content string
+func (_e *MockCommentsRepository_Expecter) UpdateContent(ctx interface{}, commentID interface{}, content interface{}) *MockCommentsRepository_UpdateContent_Call {
+	return &MockCommentsRepository_UpdateContent_Call{Call: _e.mock.On("UpdateContent", ctx, commentID, content)}
+}
+
+func (_c *MockCommentsRepository_UpdateContent_Call) Run(run func(ctx context.Context, commentID uuid.UUID, content string)) *MockCommentsRepository_UpdateContent_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uuid.UUID
+		if args[1] != nil {
+			arg1 = args[1].(uuid.UUID)
+		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockCommentsRepository_UpdateContent_Call) Return(comment *models.Comment, err error) *MockCommentsRepository_UpdateContent_Call {
+	_c.Call.Return(comment, err)
+	return _c
+}
+
+func (_c *MockCommentsRepository_UpdateContent_Call) RunAndReturn(run func(ctx context.Context, commentID uuid.UUID, content string) (*models.Comment, error)) *MockCommentsRepository_UpdateContent_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdateVotes provides a mock function for the type MockCommentsRepository
+func (_mock *MockCommentsRepository) UpdateVotes(ctx context.Context, commentID uuid.UUID, votes models.CommentVotes) (*models.Comment, error) {
+	ret := _mock.Called(ctx, commentID, votes)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateVotes")
+	}
+
+	var r0 *models.Comment
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID, models.CommentVotes) (*models.Comment, error)); ok {
+		return returnFunc(ctx, commentID, votes)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID, models.CommentVotes) *models.Comment); ok {
+		r0 = returnFunc(ctx, commentID, votes)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Comment)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, uuid.UUID, models.CommentVotes) error); ok {
+		r1 = returnFunc(ctx, commentID, votes)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockCommentsRepository_UpdateVotes_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateVotes'
+type MockCommentsRepository_UpdateVotes_Call struct {
+	*mock.Call
+}
+
+// UpdateVotes is a helper method to define mock.On call
+//   - ctx context.Context
+//   - commentID uuid.UUID
+//   - votes models.CommentVotes
+func (_e *MockCommentsRepository_Expecter) UpdateVotes(ctx interface{}, commentID interface{}, votes interface{}) *MockCommentsRepository_UpdateVotes_Call {
+	return &MockCommentsRepository_UpdateVotes_Call{Call: _e.mock.On("UpdateVotes", ctx, commentID, votes)}
+}
+
+func (_c *MockCommentsRepository_UpdateVotes_Call) Run(run func(ctx context.Context, commentID uuid.UUID, votes models.CommentVotes)) *MockCommentsRepository_UpdateVotes_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uuid.UUID
+		if args[1] != nil {
+			arg1 = args[1].(uuid.UUID)
+		}
+		var arg2 models.CommentVotes
+		if args[2] != nil {
+			arg2 = args[2].(models.CommentVotes)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockCommentsRepository_UpdateVotes_Call) Return(comment *models.Comment, err error) *MockCommentsRepository_UpdateVotes_Call {
+	_c.Call.Return(comment, err)
+	return _c
+}
+
+func (_c *MockCommentsRepository_UpdateVotes_Call) RunAndReturn(run func(ctx context.Context, commentID uuid.UUID, votes models.CommentVotes) (*models.Comment, error)) *MockCommentsRepository_UpdateVotes_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockCommunitiesRepository creates a new instance of MockCommunitiesRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockCommunitiesRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockCommunitiesRepository {
+	mock := &MockCommunitiesRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockCommunitiesRepository is an autogenerated mock type for the CommunitiesRepository type
+type MockCommunitiesRepository struct {
+	mock.Mock
+}
+
+type MockCommunitiesRepository_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockCommunitiesRepository) EXPECT() *MockCommunitiesRepository_Expecter {
+	return &MockCommunitiesRepository_Expecter{mock: &_m.Mock}
+}
+
+// Add provides a mock function for the type MockCommunitiesRepository
+func (_mock *MockCommunitiesRepository) Add(ctx context.Context, ownerID uuid.UUID, name string, slug string, description string, areCommentsAllowed bool) (*models.Community, error) {
+	ret := _mock.Called(ctx, ownerID, name, slug, description, areCommentsAllowed)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Add")
+	}
+
+	var r0 *models.Community
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID, string, string, string, bool) (*models.Community, error)); ok {
+		return returnFunc(ctx, ownerID, name, slug, description, areCommentsAllowed)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID, string, string, string, bool) *models.Community); ok {
+		r0 = returnFunc(ctx, ownerID, name, slug, description, areCommentsAllowed)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Community)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, uuid.UUID, string, string, string, bool) error); ok {
+		r1 = returnFunc(ctx, ownerID, name, slug, description, areCommentsAllowed)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockCommunitiesRepository_Add_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Add'
+type MockCommunitiesRepository_Add_Call struct {
+	*mock.Call
+}
+
+// Add is a helper method to define mock.On call
+//   - ctx context.Context
+//   - ownerID uuid.UUID
+//   - name string
+//   - slug string
+//   - description string
+//   - areCommentsAllowed bool
+func (_e *MockCommunitiesRepository_Expecter) Add(ctx interface{}, ownerID interface{}, name interface{}, slug interface{}, description interface{}, areCommentsAllowed interface{}) *MockCommunitiesRepository_Add_Call {
+	return &MockCommunitiesRepository_Add_Call{Call: _e.mock.On("Add", ctx, ownerID, name, slug, description, areCommentsAllowed)}
+}
+
+func (_c *MockCommunitiesRepository_Add_Call) Run(run func(ctx context.Context, ownerID uuid.UUID, name string, slug string, description string, areCommentsAllowed bool)) *MockCommunitiesRepository_Add_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uuid.UUID
+		if args[1] != nil {
+			arg1 = args[1].(uuid.UUID)
+		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
+		}
+		var arg3 string
+		if args[3] != nil {
+			arg3 = args[3].(string)
+		}
+		var arg4 string
+		if args[4] != nil {
+			arg4 = args[4].(string)
+		}
+		var arg5 bool
+		if args[5] != nil {
+			arg5 = args[5].(bool)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+			arg3,
+			arg4,
+			arg5,
+		)
+	})
+	return _c
+}
+
+func (_c *MockCommunitiesRepository_Add_Call) Return(community *models.Community, err error) *MockCommunitiesRepository_Add_Call {
+	_c.Call.Return(community, err)
+	return _c
+}
+
+func (_c *MockCommunitiesRepository_Add_Call) RunAndReturn(run func(ctx context.Context, ownerID uuid.UUID, name string, slug string, description string, areCommentsAllowed bool) (*models.Community, error)) *MockCommunitiesRepository_Add_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// AddMember provides a mock function for the type MockCommunitiesRepository
+func (_mock *MockCommunitiesRepository) AddMember(ctx context.Context, communityID uuid.UUID, userID uuid.UUID, role models.CommunityRole) (bool, error) {
+	ret := _mock.Called(ctx, communityID, userID, role)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddMember")
+	}
+
+	var r0 bool
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID, models.CommunityRole) (bool, error)); ok {
+		return returnFunc(ctx, communityID, userID, role)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID, models.CommunityRole) bool); ok {
+		r0 = returnFunc(ctx, communityID, userID, role)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, uuid.UUID, uuid.UUID, models.CommunityRole) error); ok {
+		r1 = returnFunc(ctx, communityID, userID, role)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockCommunitiesRepository_AddMember_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AddMember'
+type MockCommunitiesRepository_AddMember_Call struct {
+	*mock.Call
+}
+
+// AddMember is a helper method to define mock.On call
+//   - ctx context.Context
+//   - communityID uuid.UUID
+//   - userID uuid.UUID
+//   - role models.CommunityRole
+func (_e *MockCommunitiesRepository_Expecter) AddMember(ctx interface{}, communityID interface{}, userID interface{}, role interface{}) *MockCommunitiesRepository_AddMember_Call {
+	return &MockCommunitiesRepository_AddMember_Call{Call: _e.mock.On("AddMember", ctx, communityID, userID, role)}
+}
+
+func (_c *MockCommunitiesRepository_AddMember_Call) Run(run func(ctx context.Context, communityID uuid.UUID, userID uuid.UUID, role models.CommunityRole)) *MockCommunitiesRepository_AddMember_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uuid.UUID
+		if args[1] != nil {
+			arg1 = args[1].(uuid.UUID)
+		}
+		var arg2 uuid.UUID
+		if args[2] != nil {
+			arg2 = args[2].(uuid.UUID)
+		}
+		var arg3 models.CommunityRole
+		if args[3] != nil {
+			arg3 = args[3].(models.CommunityRole)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+			arg3,
+		)
+	})
+	return _c
+}
+
+func (_c *MockCommunitiesRepository_AddMember_Call) Return(b bool, err error) *MockCommunitiesRepository_AddMember_Call {
+	_c.Call.Return(b, err)
+	return _c
+}
+
+func (_c *MockCommunitiesRepository_AddMember_Call) RunAndReturn(run func(ctx context.Context, communityID uuid.UUID, userID uuid.UUID, role models.CommunityRole) (bool, error)) *MockCommunitiesRepository_AddMember_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CountMembers provides a mock function for the type MockCommunitiesRepository
+func (_mock *MockCommunitiesRepository) CountMembers(ctx context.Context, communityIDs []uuid.UUID) (map[uuid.UUID]int32, error) {
+	ret := _mock.Called(ctx, communityIDs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CountMembers")
+	}
+
+	var r0 map[uuid.UUID]int32
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, []uuid.UUID) (map[uuid.UUID]int32, error)); ok {
+		return returnFunc(ctx, communityIDs)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, []uuid.UUID) map[uuid.UUID]int32); ok {
+		r0 = returnFunc(ctx, communityIDs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[uuid.UUID]int32)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, []uuid.UUID) error); ok {
+		r1 = returnFunc(ctx, communityIDs)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockCommunitiesRepository_CountMembers_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CountMembers'
+type MockCommunitiesRepository_CountMembers_Call struct {
+	*mock.Call
+}
+
+// CountMembers is a helper method to define mock.On call
+//   - ctx context.Context
+//   - communityIDs []uuid.UUID
+func (_e *MockCommunitiesRepository_Expecter) CountMembers(ctx interface{}, communityIDs interface{}) *MockCommunitiesRepository_CountMembers_Call {
+	return &MockCommunitiesRepository_CountMembers_Call{Call: _e.mock.On("CountMembers", ctx, communityIDs)}
+}
+
+func (_c *MockCommunitiesRepository_CountMembers_Call) Run(run func(ctx context.Context, communityIDs []uuid.UUID)) *MockCommunitiesRepository_CountMembers_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 []uuid.UUID
+		if args[1] != nil {
+			arg1 = args[1].([]uuid.UUID)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockCommunitiesRepository_CountMembers_Call) Return(m map[uuid.UUID]int32, err error) *MockCommunitiesRepository_CountMembers_Call {
+	_c.Call.Return(m, err)
+	return _c
+}
+
+func (_c *MockCommunitiesRepository_CountMembers_Call) RunAndReturn(run func(ctx context.Context, communityIDs []uuid.UUID) (map[uuid.UUID]int32, error)) *MockCommunitiesRepository_CountMembers_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteMember provides a mock function for the type MockCommunitiesRepository
+func (_mock *MockCommunitiesRepository) DeleteMember(ctx context.Context, communityID uuid.UUID, userID uuid.UUID) (bool, error) {
+	ret := _mock.Called(ctx, communityID, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteMember")
+	}
+
+	var r0 bool
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID) (bool, error)); ok {
+		return returnFunc(ctx, communityID, userID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID) bool); ok {
+		r0 = returnFunc(ctx, communityID, userID)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, uuid.UUID, uuid.UUID) error); ok {
+		r1 = returnFunc(ctx, communityID, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockCommunitiesRepository_DeleteMember_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteMember'
+type MockCommunitiesRepository_DeleteMember_Call struct {
+	*mock.Call
+}
+
+// DeleteMember is a helper method to define mock.On call
+//   - ctx context.Context
+//   - communityID uuid.UUID
+//   - userID uuid.UUID
+func (_e *MockCommunitiesRepository_Expecter) DeleteMember(ctx interface{}, communityID interface{}, userID interface{}) *MockCommunitiesRepository_DeleteMember_Call {
+	return &MockCommunitiesRepository_DeleteMember_Call{Call: _e.mock.On("DeleteMember", ctx, communityID, userID)}
+}
+
+func (_c *MockCommunitiesRepository_DeleteMember_Call) Run(run func(ctx context.Context, communityID uuid.UUID, userID uuid.UUID)) *MockCommunitiesRepository_DeleteMember_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uuid.UUID
+		if args[1] != nil {
+			arg1 = args[1].(uuid.UUID)
+		}
+		var arg2 uuid.UUID
+		if args[2] != nil {
+			arg2 = args[2].(uuid.UUID)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockCommunitiesRepository_DeleteMember_Call) Return(b bool, err error) *MockCommunitiesRepository_DeleteMember_Call {
+	_c.Call.Return(b, err)
+	return _c
+}
+
+func (_c *MockCommunitiesRepository_DeleteMember_Call) RunAndReturn(run func(ctx context.Context, communityID uuid.UUID, userID uuid.UUID) (bool, error)) *MockCommunitiesRepository_DeleteMember_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetByID provides a mock function for the type MockCommunitiesRepository
+func (_mock *MockCommunitiesRepository) GetByID(ctx context.Context, communityID uuid.UUID, forUpdate bool) (*models.Community, error) {
+	ret := _mock.Called(ctx, communityID, forUpdate)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByID")
+	}
+
+	var r0 *models.Community
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID, bool) (*models.Community, error)); ok {
+		return returnFunc(ctx, communityID, forUpdate)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID, bool) *models.Community); ok {
+		r0 = returnFunc(ctx, communityID, forUpdate)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Community)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, uuid.UUID, bool) error); ok {
+		r1 = returnFunc(ctx, communityID, forUpdate)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockCommunitiesRepository_GetByID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetByID'
+type MockCommunitiesRepository_GetByID_Call struct {
+	*mock.Call
+}
+
+// GetByID is a helper method to define mock.On call
+//   - ctx context.Context
+//   - communityID uuid.UUID
+//   - forUpdate bool
+func (_e *MockCommunitiesRepository_Expecter) GetByID(ctx interface{}, communityID interface{}, forUpdate interface{}) *MockCommunitiesRepository_GetByID_Call {
+	return &MockCommunitiesRepository_GetByID_Call{Call: _e.mock.On("GetByID", ctx, communityID, forUpdate)}
+}
+
+func (_c *MockCommunitiesRepository_GetByID_Call) Run(run func(ctx context.Context, communityID uuid.UUID, forUpdate bool)) *MockCommunitiesRepository_GetByID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uuid.UUID
+		if args[1] != nil {
+			arg1 = args[1].(uuid.UUID)
+		}
+		var arg2 bool
+		if args[2] != nil {
+			arg2 = args[2].(bool)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockCommunitiesRepository_GetByID_Call) Return(community *models.Community, err error) *MockCommunitiesRepository_GetByID_Call {
+	_c.Call.Return(community, err)
+	return _c
+}
+
+func (_c *MockCommunitiesRepository_GetByID_Call) RunAndReturn(run func(ctx context.Context, communityID uuid.UUID, forUpdate bool) (*models.Community, error)) *MockCommunitiesRepository_GetByID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetByIDs provides a mock function for the type MockCommunitiesRepository
+func (_mock *MockCommunitiesRepository) GetByIDs(ctx context.Context, IDs []uuid.UUID) ([]*models.Community, error) {
+	ret := _mock.Called(ctx, IDs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByIDs")
+	}
+
+	var r0 []*models.Community
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, []uuid.UUID) ([]*models.Community, error)); ok {
+		return returnFunc(ctx, IDs)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, []uuid.UUID) []*models.Community); ok {
+		r0 = returnFunc(ctx, IDs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Community)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, []uuid.UUID) error); ok {
+		r1 = returnFunc(ctx, IDs)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockCommunitiesRepository_GetByIDs_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetByIDs'
+type MockCommunitiesRepository_GetByIDs_Call struct {
+	*mock.Call
+}
+
+// GetByIDs is a helper method to define mock.On call
+//   - ctx context.Context
+//   - IDs []uuid.UUID
+func (_e *MockCommunitiesRepository_Expecter) GetByIDs(ctx interface{}, IDs interface{}) *MockCommunitiesRepository_GetByIDs_Call {
+	return &MockCommunitiesRepository_GetByIDs_Call{Call: _e.mock.On("GetByIDs", ctx, IDs)}
+}
+
+func (_c *MockCommunitiesRepository_GetByIDs_Call) Run(run func(ctx context.Context, IDs []uuid.UUID)) *MockCommunitiesRepository_GetByIDs_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 []uuid.UUID
+		if args[1] != nil {
+			arg1 = args[1].([]uuid.UUID)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockCommunitiesRepository_GetByIDs_Call) Return(communitys []*models.Community, err error) *MockCommunitiesRepository_GetByIDs_Call {
+	_c.Call.Return(communitys, err)
+	return _c
+}
+
+func (_c *MockCommunitiesRepository_GetByIDs_Call) RunAndReturn(run func(ctx context.Context, IDs []uuid.UUID) ([]*models.Community, error)) *MockCommunitiesRepository_GetByIDs_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetBySlug provides a mock function for the type MockCommunitiesRepository
+func (_mock *MockCommunitiesRepository) GetBySlug(ctx context.Context, slug string) (*models.Community, error) {
+	ret := _mock.Called(ctx, slug)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetBySlug")
+	}
+
+	var r0 *models.Community
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (*models.Community, error)); ok {
+		return returnFunc(ctx, slug)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) *models.Community); ok {
+		r0 = returnFunc(ctx, slug)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Community)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, slug)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockCommunitiesRepository_GetBySlug_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetBySlug'
+type MockCommunitiesRepository_GetBySlug_Call struct {
+	*mock.Call
+}
+
+// GetBySlug is a helper method to define mock.On call
+//   - ctx context.Context
+//   - slug string
+func (_e *MockCommunitiesRepository_Expecter) GetBySlug(ctx interface{}, slug interface{}) *MockCommunitiesRepository_GetBySlug_Call {
+	return &MockCommunitiesRepository_GetBySlug_Call{Call: _e.mock.On("GetBySlug", ctx, slug)}
+}
+
+func (_c *MockCommunitiesRepository_GetBySlug_Call) Run(run func(ctx context.Context, slug string)) *MockCommunitiesRepository_GetBySlug_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockCommunitiesRepository_GetBySlug_Call) Return(community *models.Community, err error) *MockCommunitiesRepository_GetBySlug_Call {
+	_c.Call.Return(community, err)
+	return _c
+}
+
+func (_c *MockCommunitiesRepository_GetBySlug_Call) RunAndReturn(run func(ctx context.Context, slug string) (*models.Community, error)) *MockCommunitiesRepository_GetBySlug_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetMember provides a mock function for the type MockCommunitiesRepository
+func (_mock *MockCommunitiesRepository) GetMember(ctx context.Context, communityID uuid.UUID, userID uuid.UUID) (*models.CommunityMember, error) {
+	ret := _mock.Called(ctx, communityID, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetMember")
+	}
+
+	var r0 *models.CommunityMember
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID) (*models.CommunityMember, error)); ok {
+		return returnFunc(ctx, communityID, userID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID) *models.CommunityMember); ok {
+		r0 = returnFunc(ctx, communityID, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.CommunityMember)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, uuid.UUID, uuid.UUID) error); ok {
+		r1 = returnFunc(ctx, communityID, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockCommunitiesRepository_GetMember_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetMember'
+type MockCommunitiesRepository_GetMember_Call struct {
+	*mock.Call
+}
+
+// GetMember is a helper method to define mock.On call
+//   - ctx context.Context
+//   - communityID uuid.UUID
+//   - userID uuid.UUID
+func (_e *MockCommunitiesRepository_Expecter) GetMember(ctx interface{}, communityID interface{}, userID interface{}) *MockCommunitiesRepository_GetMember_Call {
+	return &MockCommunitiesRepository_GetMember_Call{Call: _e.mock.On("GetMember", ctx, communityID, userID)}
+}
+
+func (_c *MockCommunitiesRepository_GetMember_Call) Run(run func(ctx context.Context, communityID uuid.UUID, userID uuid.UUID)) *MockCommunitiesRepository_GetMember_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uuid.UUID
+		if args[1] != nil {
+			arg1 = args[1].(uuid.UUID)
+		}
+		var arg2 uuid.UUID
+		if args[2] != nil {
+			arg2 = args[2].(uuid.UUID)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockCommunitiesRepository_GetMember_Call) Return(communityMember *models.CommunityMember, err error) *MockCommunitiesRepository_GetMember_Call {
+	_c.Call.Return(communityMember, err)
+	return _c
+}
+
+func (_c *MockCommunitiesRepository_GetMember_Call) RunAndReturn(run func(ctx context.Context, communityID uuid.UUID, userID uuid.UUID) (*models.CommunityMember, error)) *MockCommunitiesRepository_GetMember_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetModerators provides a mock function for the type MockCommunitiesRepository
+func (_mock *MockCommunitiesRepository) GetModerators(ctx context.Context, communityID uuid.UUID) ([]*models.CommunityMember, error) {
+	ret := _mock.Called(ctx, communityID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetModerators")
+	}
+
+	var r0 []*models.CommunityMember
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID) ([]*models.CommunityMember, error)); ok {
+		return returnFunc(ctx, communityID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID) []*models.CommunityMember); ok {
+		r0 = returnFunc(ctx, communityID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.CommunityMember)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, uuid.UUID) error); ok {
+		r1 = returnFunc(ctx, communityID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockCommunitiesRepository_GetModerators_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetModerators'
+type MockCommunitiesRepository_GetModerators_Call struct {
+	*mock.Call
+}
+
+// GetModerators is a helper method to define mock.On call
+//   - ctx context.Context
+//   - communityID uuid.UUID
+func (_e *MockCommunitiesRepository_Expecter) GetModerators(ctx interface{}, communityID interface{}) *MockCommunitiesRepository_GetModerators_Call {
+	return &MockCommunitiesRepository_GetModerators_Call{Call: _e.mock.On("GetModerators", ctx, communityID)}
+}
+
+func (_c *MockCommunitiesRepository_GetModerators_Call) Run(run func(ctx context.Context, communityID uuid.UUID)) *MockCommunitiesRepository_GetModerators_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uuid.UUID
+		if args[1] != nil {
+			arg1 = args[1].(uuid.UUID)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockCommunitiesRepository_GetModerators_Call) Return(communityMembers []*models.CommunityMember, err error) *MockCommunitiesRepository_GetModerators_Call {
+	_c.Call.Return(communityMembers, err)
+	return _c
+}
+
+func (_c *MockCommunitiesRepository_GetModerators_Call) RunAndReturn(run func(ctx context.Context, communityID uuid.UUID) ([]*models.CommunityMember, error)) *MockCommunitiesRepository_GetModerators_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetMemberRole provides a mock function for the type MockCommunitiesRepository
+func (_mock *MockCommunitiesRepository) SetMemberRole(ctx context.Context, communityID uuid.UUID, userID uuid.UUID, role models.CommunityRole) (*models.CommunityMember, error) {
+	ret := _mock.Called(ctx, communityID, userID, role)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetMemberRole")
+	}
+
+	var r0 *models.CommunityMember
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID, models.CommunityRole) (*models.CommunityMember, error)); ok {
+		return returnFunc(ctx, communityID, userID, role)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID, models.CommunityRole) *models.CommunityMember); ok {
+		r0 = returnFunc(ctx, communityID, userID, role)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.CommunityMember)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, uuid.UUID, uuid.UUID, models.CommunityRole) error); ok {
+		r1 = returnFunc(ctx, communityID, userID, role)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockCommunitiesRepository_SetMemberRole_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetMemberRole'
+type MockCommunitiesRepository_SetMemberRole_Call struct {
+	*mock.Call
+}
+
+// SetMemberRole is a helper method to define mock.On call
+//   - ctx context.Context
+//   - communityID uuid.UUID
+//   - userID uuid.UUID
+//   - role models.CommunityRole
+func (_e *MockCommunitiesRepository_Expecter) SetMemberRole(ctx interface{}, communityID interface{}, userID interface{}, role interface{}) *MockCommunitiesRepository_SetMemberRole_Call {
+	return &MockCommunitiesRepository_SetMemberRole_Call{Call: _e.mock.On("SetMemberRole", ctx, communityID, userID, role)}
+}
+
+func (_c *MockCommunitiesRepository_SetMemberRole_Call) Run(run func(ctx context.Context, communityID uuid.UUID, userID uuid.UUID, role models.CommunityRole)) *MockCommunitiesRepository_SetMemberRole_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uuid.UUID
+		if args[1] != nil {
+			arg1 = args[1].(uuid.UUID)
+		}
+		var arg2 uuid.UUID
+		if args[2] != nil {
+			arg2 = args[2].(uuid.UUID)
+		}
+		var arg3 models.CommunityRole
+		if args[3] != nil {
+			arg3 = args[3].(models.CommunityRole)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+			arg3,
+		)
+	})
+	return _c
+}
+
+func (_c *MockCommunitiesRepository_SetMemberRole_Call) Return(communityMember *models.CommunityMember, err error) *MockCommunitiesRepository_SetMemberRole_Call {
+	_c.Call.Return(communityMember, err)
+	return _c
+}
+
+func (_c *MockCommunitiesRepository_SetMemberRole_Call) RunAndReturn(run func(ctx context.Context, communityID uuid.UUID, userID uuid.UUID, role models.CommunityRole) (*models.CommunityMember, error)) *MockCommunitiesRepository_SetMemberRole_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockOutboxRepository creates a new instance of MockOutboxRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockOutboxRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockOutboxRepository {
+	mock := &MockOutboxRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockOutboxRepository is an autogenerated mock type for the OutboxRepository type
+type MockOutboxRepository struct {
+	mock.Mock
+}
+
+type MockOutboxRepository_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockOutboxRepository) EXPECT() *MockOutboxRepository_Expecter {
+	return &MockOutboxRepository_Expecter{mock: &_m.Mock}
+}
+
+// Add provides a mock function for the type MockOutboxRepository
+func (_mock *MockOutboxRepository) Add(ctx context.Context, ID uuid.UUID, eventType string, payload []byte) error {
+	ret := _mock.Called(ctx, ID, eventType, payload)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Add")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID, string, []byte) error); ok {
+		r0 = returnFunc(ctx, ID, eventType, payload)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockOutboxRepository_Add_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Add'
+type MockOutboxRepository_Add_Call struct {
+	*mock.Call
+}
+
+// Add is a helper method to define mock.On call
+//   - ctx context.Context
+//   - ID uuid.UUID
+//   - eventType string
+//   - payload []byte
+func (_e *MockOutboxRepository_Expecter) Add(ctx interface{}, ID interface{}, eventType interface{}, payload interface{}) *MockOutboxRepository_Add_Call {
+	return &MockOutboxRepository_Add_Call{Call: _e.mock.On("Add", ctx, ID, eventType, payload)}
+}
+
+func (_c *MockOutboxRepository_Add_Call) Run(run func(ctx context.Context, ID uuid.UUID, eventType string, payload []byte)) *MockOutboxRepository_Add_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uuid.UUID
+		if args[1] != nil {
+			arg1 = args[1].(uuid.UUID)
+		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
+		}
+		var arg3 []byte
+		if args[3] != nil {
+			arg3 = args[3].([]byte)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+			arg3,
+		)
+	})
+	return _c
+}
+
+func (_c *MockOutboxRepository_Add_Call) Return(err error) *MockOutboxRepository_Add_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockOutboxRepository_Add_Call) RunAndReturn(run func(ctx context.Context, ID uuid.UUID, eventType string, payload []byte) error) *MockOutboxRepository_Add_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteProcessedBefore provides a mock function for the type MockOutboxRepository
+func (_mock *MockOutboxRepository) DeleteProcessedBefore(ctx context.Context, before time.Time) error {
+	ret := _mock.Called(ctx, before)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteProcessedBefore")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, time.Time) error); ok {
+		r0 = returnFunc(ctx, before)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockOutboxRepository_DeleteProcessedBefore_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteProcessedBefore'
+type MockOutboxRepository_DeleteProcessedBefore_Call struct {
+	*mock.Call
+}
+
+// DeleteProcessedBefore is a helper method to define mock.On call
+//   - ctx context.Context
+//   - before time.Time
+func (_e *MockOutboxRepository_Expecter) DeleteProcessedBefore(ctx interface{}, before interface{}) *MockOutboxRepository_DeleteProcessedBefore_Call {
+	return &MockOutboxRepository_DeleteProcessedBefore_Call{Call: _e.mock.On("DeleteProcessedBefore", ctx, before)}
+}
+
+func (_c *MockOutboxRepository_DeleteProcessedBefore_Call) Run(run func(ctx context.Context, before time.Time)) *MockOutboxRepository_DeleteProcessedBefore_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 time.Time
+		if args[1] != nil {
+			arg1 = args[1].(time.Time)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockOutboxRepository_DeleteProcessedBefore_Call) Return(err error) *MockOutboxRepository_DeleteProcessedBefore_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockOutboxRepository_DeleteProcessedBefore_Call) RunAndReturn(run func(ctx context.Context, before time.Time) error) *MockOutboxRepository_DeleteProcessedBefore_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetUnprocessed provides a mock function for the type MockOutboxRepository
+func (_mock *MockOutboxRepository) GetUnprocessed(ctx context.Context, limit int32) ([]*models.OutboxEvent, error) {
+	ret := _mock.Called(ctx, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUnprocessed")
+	}
+
+	var r0 []*models.OutboxEvent
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int32) ([]*models.OutboxEvent, error)); ok {
+		return returnFunc(ctx, limit)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int32) []*models.OutboxEvent); ok {
+		r0 = returnFunc(ctx, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.OutboxEvent)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int32) error); ok {
+		r1 = returnFunc(ctx, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockOutboxRepository_GetUnprocessed_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetUnprocessed'
+type MockOutboxRepository_GetUnprocessed_Call struct {
+	*mock.Call
+}
+
+// GetUnprocessed is a helper method to define mock.On call
+//   - ctx context.Context
+//   - limit int32
+func (_e *MockOutboxRepository_Expecter) GetUnprocessed(ctx interface{}, limit interface{}) *MockOutboxRepository_GetUnprocessed_Call {
+	return &MockOutboxRepository_GetUnprocessed_Call{Call: _e.mock.On("GetUnprocessed", ctx, limit)}
+}
+
+func (_c *MockOutboxRepository_GetUnprocessed_Call) Run(run func(ctx context.Context, limit int32)) *MockOutboxRepository_GetUnprocessed_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 int32
+		if args[1] != nil {
+			arg1 = args[1].(int32)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockOutboxRepository_GetUnprocessed_Call) Return(outboxEvents []*models.OutboxEvent, err error) *MockOutboxRepository_GetUnprocessed_Call {
+	_c.Call.Return(outboxEvents, err)
+	return _c
+}
+
+func (_c *MockOutboxRepository_GetUnprocessed_Call) RunAndReturn(run func(ctx context.Context, limit int32) ([]*models.OutboxEvent, error)) *MockOutboxRepository_GetUnprocessed_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// MarkProcessed provides a mock function for the type MockOutboxRepository
+func (_mock *MockOutboxRepository) MarkProcessed(ctx context.Context, IDs []uuid.UUID) error {
+	ret := _mock.Called(ctx, IDs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MarkProcessed")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, []uuid.UUID) error); ok {
+		r0 = returnFunc(ctx, IDs)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockOutboxRepository_MarkProcessed_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'MarkProcessed'
+type MockOutboxRepository_MarkProcessed_Call struct {
+	*mock.Call
+}
+
+// MarkProcessed is a helper method to define mock.On call
+//   - ctx context.Context
+//   - IDs []uuid.UUID
+func (_e *MockOutboxRepository_Expecter) MarkProcessed(ctx interface{}, IDs interface{}) *MockOutboxRepository_MarkProcessed_Call {
+	return &MockOutboxRepository_MarkProcessed_Call{Call: _e.mock.On("MarkProcessed", ctx, IDs)}
+}
+
+func (_c *MockOutboxRepository_MarkProcessed_Call) Run(run func(ctx context.Context, IDs []uuid.UUID)) *MockOutboxRepository_MarkProcessed_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 []uuid.UUID
+		if args[1] != nil {
+			arg1 = args[1].([]uuid.UUID)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockOutboxRepository_MarkProcessed_Call) Return(err error) *MockOutboxRepository_MarkProcessed_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockOutboxRepository_MarkProcessed_Call) RunAndReturn(run func(ctx context.Context, IDs []uuid.UUID) error) *MockOutboxRepository_MarkProcessed_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockPostsRepository creates a new instance of MockPostsRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockPostsRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockPostsRepository {
+	mock := &MockPostsRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockPostsRepository is an autogenerated mock type for the PostsRepository type
+type MockPostsRepository struct {
+	mock.Mock
+}
+
+type MockPostsRepository_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockPostsRepository) EXPECT() *MockPostsRepository_Expecter {
+	return &MockPostsRepository_Expecter{mock: &_m.Mock}
+}
+
+// Add provides a mock function for the type MockPostsRepository
+func (_mock *MockPostsRepository) Add(ctx context.Context, userID uuid.UUID, communityID *uuid.UUID, title string, content string, areCommentsAllowed bool) (*models.Post, error) {
+	ret := _mock.Called(ctx, userID, communityID, title, content, areCommentsAllowed)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Add")
+	}
+
+	var r0 *models.Post
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID, *uuid.UUID, string, string, bool) (*models.Post, error)); ok {
+		return returnFunc(ctx, userID, communityID, title, content, areCommentsAllowed)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID, *uuid.UUID, string, string, bool) *models.Post); ok {
+		r0 = returnFunc(ctx, userID, communityID, title, content, areCommentsAllowed)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Post)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, uuid.UUID, *uuid.UUID, string, string, bool) error); ok {
+		r1 = returnFunc(ctx, userID, communityID, title, content, areCommentsAllowed)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockPostsRepository_Add_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Add'
+type MockPostsRepository_Add_Call struct {
+	*mock.Call
+}
+
+// Add is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID uuid.UUID
+//   - communityID *uuid.UUID
+//   - title string
+//   - content string
+//   - areCommentsAllowed bool
+func (_e *MockPostsRepository_Expecter) Add(ctx interface{}, userID interface{}, communityID interface{}, title interface{}, content interface{}, areCommentsAllowed interface{}) *MockPostsRepository_Add_Call {
+	return &MockPostsRepository_Add_Call{Call: _e.mock.On("Add", ctx, userID, communityID, title, content, areCommentsAllowed)}
+}
+
+func (_c *MockPostsRepository_Add_Call) Run(run func(ctx context.Context, userID uuid.UUID, communityID *uuid.UUID, title string, content string, areCommentsAllowed bool)) *MockPostsRepository_Add_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uuid.UUID
+		if args[1] != nil {
+			arg1 = args[1].(uuid.UUID)
+		}
+		var arg2 *uuid.UUID
+		if args[2] != nil {
+			arg2 = args[2].(*uuid.UUID)
+		}
+		var arg3 string
+		if args[3] != nil {
+			arg3 = args[3].(string)
+		}
+		var arg4 string
+		if args[4] != nil {
+			arg4 = args[4].(string)
+		}
+		var arg5 bool
+		if args[5] != nil {
+			arg5 = args[5].(bool)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+			arg3,
+			arg4,
+			arg5,
+		)
+	})
+	return _c
+}
+
+func (_c *MockPostsRepository_Add_Call) Return(post *models.Post, err error) *MockPostsRepository_Add_Call {
+	_c.Call.Return(post, err)
+	return _c
+}
+
+func (_c *MockPostsRepository_Add_Call) RunAndReturn(run func(ctx context.Context, userID uuid.UUID, communityID *uuid.UUID, title string, content string, areCommentsAllowed bool) (*models.Post, error)) *MockPostsRepository_Add_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// AddReaction provides a mock function for the type MockPostsRepository
+func (_mock *MockPostsRepository) AddReaction(ctx context.Context, postID uuid.UUID, userID uuid.UUID, emoji string) (bool, error) {
+	ret := _mock.Called(ctx, postID, userID, emoji)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddReaction")
+	}
+
+	var r0 bool
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID, string) (bool, error)); ok {
+		return returnFunc(ctx, postID, userID, emoji)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID, string) bool); ok {
+		r0 = returnFunc(ctx, postID, userID, emoji)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, uuid.UUID, uuid.UUID, string) error); ok {
+		r1 = returnFunc(ctx, postID, userID, emoji)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockPostsRepository_AddReaction_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AddReaction'
+type MockPostsRepository_AddReaction_Call struct {
+	*mock.Call
+}
+
+// AddReaction is a helper method to define mock.On call
+//   - ctx context.Context
+//   - postID uuid.UUID
+//   - userID uuid.UUID
+//   - emoji string
+func (_e *MockPostsRepository_Expecter) AddReaction(ctx interface{}, postID interface{}, userID interface{}, emoji interface{}) *MockPostsRepository_AddReaction_Call {
+	return &MockPostsRepository_AddReaction_Call{Call: _e.mock.On("AddReaction", ctx, postID, userID, emoji)}
+}
+
+func (_c *MockPostsRepository_AddReaction_Call) Run(run func(ctx context.Context, postID uuid.UUID, userID uuid.UUID, emoji string)) *MockPostsRepository_AddReaction_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uuid.UUID
+		if args[1] != nil {
+			arg1 = args[1].(uuid.UUID)
+		}
+		var arg2 uuid.UUID
+		if args[2] != nil {
+			arg2 = args[2].(uuid.UUID)
+		}
+		var arg3 string
+		if args[3] != nil {
+			arg3 = args[3].(string)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+			arg3,
+		)
+	})
+	return _c
+}
+
+func (_c *MockPostsRepository_AddReaction_Call) Return(b bool, err error) *MockPostsRepository_AddReaction_Call {
+	_c.Call.Return(b, err)
+	return _c
+}
+
+func (_c *MockPostsRepository_AddReaction_Call) RunAndReturn(run func(ctx context.Context, postID uuid.UUID, userID uuid.UUID, emoji string) (bool, error)) *MockPostsRepository_AddReaction_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CountByUserIDs provides a mock function for the type MockPostsRepository
+func (_mock *MockPostsRepository) CountByUserIDs(ctx context.Context, userIDs []uuid.UUID) (map[uuid.UUID]int32, error) {
+	ret := _mock.Called(ctx, userIDs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CountByUserIDs")
+	}
+
+	var r0 map[uuid.UUID]int32
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, []uuid.UUID) (map[uuid.UUID]int32, error)); ok {
+		return returnFunc(ctx, userIDs)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, []uuid.UUID) map[uuid.UUID]int32); ok {
+		r0 = returnFunc(ctx, userIDs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[uuid.UUID]int32)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, []uuid.UUID) error); ok {
+		r1 = returnFunc(ctx, userIDs)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockPostsRepository_CountByUserIDs_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CountByUserIDs'
+type MockPostsRepository_CountByUserIDs_Call struct {
+	*mock.Call
+}
+
+// CountByUserIDs is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userIDs []uuid.UUID
+func (_e *MockPostsRepository_Expecter) CountByUserIDs(ctx interface{}, userIDs interface{}) *MockPostsRepository_CountByUserIDs_Call {
+	return &MockPostsRepository_CountByUserIDs_Call{Call: _e.mock.On("CountByUserIDs", ctx, userIDs)}
+}
+
+func (_c *MockPostsRepository_CountByUserIDs_Call) Run(run func(ctx context.Context, userIDs []uuid.UUID)) *MockPostsRepository_CountByUserIDs_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 []uuid.UUID
+		if args[1] != nil {
+			arg1 = args[1].([]uuid.UUID)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockPostsRepository_CountByUserIDs_Call) Return(m map[uuid.UUID]int32, err error) *MockPostsRepository_CountByUserIDs_Call {
+	_c.Call.Return(m, err)
+	return _c
+}
+
+func (_c *MockPostsRepository_CountByUserIDs_Call) RunAndReturn(run func(ctx context.Context, userIDs []uuid.UUID) (map[uuid.UUID]int32, error)) *MockPostsRepository_CountByUserIDs_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteReaction provides a mock function for the type MockPostsRepository
+func (_mock *MockPostsRepository) DeleteReaction(ctx context.Context, postID uuid.UUID, userID uuid.UUID, emoji string) (bool, error) {
+	ret := _mock.Called(ctx, postID, userID, emoji)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteReaction")
+	}
+
+	var r0 bool
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID, string) (bool, error)); ok {
+		return returnFunc(ctx, postID, userID, emoji)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID, string) bool); ok {
+		r0 = returnFunc(ctx, postID, userID, emoji)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, uuid.UUID, uuid.UUID, string) error); ok {
+		r1 = returnFunc(ctx, postID, userID, emoji)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockPostsRepository_DeleteReaction_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteReaction'
+type MockPostsRepository_DeleteReaction_Call struct {
+	*mock.Call
+}
+
+// DeleteReaction is a helper method to define mock.On call
+//   - ctx context.Context
+//   - postID uuid.UUID
+//   - userID uuid.UUID
+//   - emoji string
+func (_e *MockPostsRepository_Expecter) DeleteReaction(ctx interface{}, postID interface{}, userID interface{}, emoji interface{}) *MockPostsRepository_DeleteReaction_Call {
+	return &MockPostsRepository_DeleteReaction_Call{Call: _e.mock.On("DeleteReaction", ctx, postID, userID, emoji)}
+}
+
+func (_c *MockPostsRepository_DeleteReaction_Call) Run(run func(ctx context.Context, postID uuid.UUID, userID uuid.UUID, emoji string)) *MockPostsRepository_DeleteReaction_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uuid.UUID
+		if args[1] != nil {
+			arg1 = args[1].(uuid.UUID)
+		}
+		var arg2 uuid.UUID
+		if args[2] != nil {
+			arg2 = args[2].(uuid.UUID)
+		}
+		var arg3 string
+		if args[3] != nil {
+			arg3 = args[3].(string)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+			arg3,
+		)
+	})
+	return _c
+}
+
+func (_c *MockPostsRepository_DeleteReaction_Call) Return(b bool, err error) *MockPostsRepository_DeleteReaction_Call {
+	_c.Call.Return(b, err)
+	return _c
+}
+
+func (_c *MockPostsRepository_DeleteReaction_Call) RunAndReturn(run func(ctx context.Context, postID uuid.UUID, userID uuid.UUID, emoji string) (bool, error)) *MockPostsRepository_DeleteReaction_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DisableComments provides a mock function for the type MockPostsRepository
+func (_mock *MockPostsRepository) DisableComments(ctx context.Context, postID uuid.UUID) (*models.Post, error) {
+	ret := _mock.Called(ctx, postID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DisableComments")
+	}
+
+	var r0 *models.Post
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID) (*models.Post, error)); ok {
+		return returnFunc(ctx, postID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID) *models.Post); ok {
+		r0 = returnFunc(ctx, postID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Post)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, uuid.UUID) error); ok {
+		r1 = returnFunc(ctx, postID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockPostsRepository_DisableComments_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DisableComments'
+type MockPostsRepository_DisableComments_Call struct {
+	*mock.Call
+}
+
+// DisableComments is a helper method to define mock.On call
+//   - ctx context.Context
+//   - postID uuid.UUID
+func (_e *MockPostsRepository_Expecter) DisableComments(ctx interface{}, postID interface{}) *MockPostsRepository_DisableComments_Call {
+	return &MockPostsRepository_DisableComments_Call{Call: _e.mock.On("DisableComments", ctx, postID)}
+}
+
+func (_c *MockPostsRepository_DisableComments_Call) Run(run func(ctx context.Context, postID uuid.UUID)) *MockPostsRepository_DisableComments_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uuid.UUID
+		if args[1] != nil {
+			arg1 = args[1].(uuid.UUID)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockPostsRepository_DisableComments_Call) Return(post *models.Post, err error) *MockPostsRepository_DisableComments_Call {
+	_c.Call.Return(post, err)
+	return _c
+}
+
+func (_c *MockPostsRepository_DisableComments_Call) RunAndReturn(run func(ctx context.Context, postID uuid.UUID) (*models.Post, error)) *MockPostsRepository_DisableComments_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// EnableComments provides a mock function for the type MockPostsRepository
+func (_mock *MockPostsRepository) EnableComments(ctx context.Context, postID uuid.UUID) (*models.Post, error) {
+	ret := _mock.Called(ctx, postID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for EnableComments")
+	}
+
+	var r0 *models.Post
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID) (*models.Post, error)); ok {
+		return returnFunc(ctx, postID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID) *models.Post); ok {
+		r0 = returnFunc(ctx, postID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Post)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, uuid.UUID) error); ok {
+		r1 = returnFunc(ctx, postID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockPostsRepository_EnableComments_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'EnableComments'
+type MockPostsRepository_EnableComments_Call struct {
+	*mock.Call
+}
+
+// EnableComments is a helper method to define mock.On call
+//   - ctx context.Context
+//   - postID uuid.UUID
+func (_e *MockPostsRepository_Expecter) EnableComments(ctx interface{}, postID interface{}) *MockPostsRepository_EnableComments_Call {
+	return &MockPostsRepository_EnableComments_Call{Call: _e.mock.On("EnableComments", ctx, postID)}
+}
+
+func (_c *MockPostsRepository_EnableComments_Call) Run(run func(ctx context.Context, postID uuid.UUID)) *MockPostsRepository_EnableComments_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uuid.UUID
+		if args[1] != nil {
+			arg1 = args[1].(uuid.UUID)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockPostsRepository_EnableComments_Call) Return(post *models.Post, err error) *MockPostsRepository_EnableComments_Call {
+	_c.Call.Return(post, err)
+	return _c
+}
+
+func (_c *MockPostsRepository_EnableComments_Call) RunAndReturn(run func(ctx context.Context, postID uuid.UUID) (*models.Post, error)) *MockPostsRepository_EnableComments_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetAll provides a mock function for the type MockPostsRepository
+func (_mock *MockPostsRepository) GetAll(ctx context.Context) ([]*models.Post, error) {
+	ret := _mock.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAll")
+	}
+
+	var r0 []*models.Post
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context) ([]*models.Post, error)); ok {
+		return returnFunc(ctx)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context) []*models.Post); ok {
+		r0 = returnFunc(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Post)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = returnFunc(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockPostsRepository_GetAll_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetAll'
+type MockPostsRepository_GetAll_Call struct {
+	*mock.Call
+}
+
+// GetAll is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockPostsRepository_Expecter) GetAll(ctx interface{}) *MockPostsRepository_GetAll_Call {
+	return &MockPostsRepository_GetAll_Call{Call: _e.mock.On("GetAll", ctx)}
+}
+
+func (_c *MockPostsRepository_GetAll_Call) Run(run func(ctx context.Context)) *MockPostsRepository_GetAll_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockPostsRepository_GetAll_Call) Return(posts []*models.Post, err error) *MockPostsRepository_GetAll_Call {
+	_c.Call.Return(posts, err)
+	return _c
+}
+
+func (_c *MockPostsRepository_GetAll_Call) RunAndReturn(run func(ctx context.Context) ([]*models.Post, error)) *MockPostsRepository_GetAll_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetByID provides a mock function for the type MockPostsRepository
+func (_mock *MockPostsRepository) GetByID(ctx context.Context, postID uuid.UUID, forUpdate bool) (*models.Post, error) {
+	ret := _mock.Called(ctx, postID, forUpdate)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByID")
+	}
+
+	var r0 *models.Post
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID, bool) (*models.Post, error)); ok {
+		return returnFunc(ctx, postID, forUpdate)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID, bool) *models.Post); ok {
+		r0 = returnFunc(ctx, postID, forUpdate)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Post)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, uuid.UUID, bool) error); ok {
+		r1 = returnFunc(ctx, postID, forUpdate)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockPostsRepository_GetByID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetByID'
+type MockPostsRepository_GetByID_Call struct {
+	*mock.Call
+}
+
+// GetByID is a helper method to define mock.On call
+//   - ctx context.Context
+//   - postID uuid.UUID
+//   - forUpdate bool
+func (_e *MockPostsRepository_Expecter) GetByID(ctx interface{}, postID interface{}, forUpdate interface{}) *MockPostsRepository_GetByID_Call {
+	return &MockPostsRepository_GetByID_Call{Call: _e.mock.On("GetByID", ctx, postID, forUpdate)}
+}
+
+func (_c *MockPostsRepository_GetByID_Call) Run(run func(ctx context.Context, postID uuid.UUID, forUpdate bool)) *MockPostsRepository_GetByID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uuid.UUID
+		if args[1] != nil {
+			arg1 = args[1].(uuid.UUID)
+		}
+		var arg2 bool
+		if args[2] != nil {
+			arg2 = args[2].(bool)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockPostsRepository_GetByID_Call) Return(post *models.Post, err error) *MockPostsRepository_GetByID_Call {
+	_c.Call.Return(post, err)
+	return _c
+}
+
+func (_c *MockPostsRepository_GetByID_Call) RunAndReturn(run func(ctx context.Context, postID uuid.UUID, forUpdate bool) (*models.Post, error)) *MockPostsRepository_GetByID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetPage provides a mock function for the type MockPostsRepository
+func (_mock *MockPostsRepository) GetPage(ctx context.Context, page *pagination.Page) ([]*models.Post, error) {
+	ret := _mock.Called(ctx, page)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPage")
+	}
+
+	var r0 []*models.Post
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *pagination.Page) ([]*models.Post, error)); ok {
+		return returnFunc(ctx, page)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *pagination.Page) []*models.Post); ok {
+		r0 = returnFunc(ctx, page)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Post)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *pagination.Page) error); ok {
+		r1 = returnFunc(ctx, page)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockPostsRepository_GetPage_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetPage'
+type MockPostsRepository_GetPage_Call struct {
+	*mock.Call
+}
+
+// GetPage is a helper method to define mock.On call
+//   - ctx context.Context
+//   - page *pagination.Page
+func (_e *MockPostsRepository_Expecter) GetPage(ctx interface{}, page interface{}) *MockPostsRepository_GetPage_Call {
+	return &MockPostsRepository_GetPage_Call{Call: _e.mock.On("GetPage", ctx, page)}
+}
+
+func (_c *MockPostsRepository_GetPage_Call) Run(run func(ctx context.Context, page *pagination.Page)) *MockPostsRepository_GetPage_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *pagination.Page
+		if args[1] != nil {
+			arg1 = args[1].(*pagination.Page)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockPostsRepository_GetPage_Call) Return(posts []*models.Post, err error) *MockPostsRepository_GetPage_Call {
+	_c.Call.Return(posts, err)
+	return _c
+}
+
+func (_c *MockPostsRepository_GetPage_Call) RunAndReturn(run func(ctx context.Context, page *pagination.Page) ([]*models.Post, error)) *MockPostsRepository_GetPage_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetPageByCommunity provides a mock function for the type MockPostsRepository
+func (_mock *MockPostsRepository) GetPageByCommunity(ctx context.Context, communityID uuid.UUID, page *pagination.Page) ([]*models.Post, error) {
+	ret := _mock.Called(ctx, communityID, page)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPageByCommunity")
+	}
+
+	var r0 []*models.Post
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID, *pagination.Page) ([]*models.Post, error)); ok {
+		return returnFunc(ctx, communityID, page)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID, *pagination.Page) []*models.Post); ok {
+		r0 = returnFunc(ctx, communityID, page)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Post)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, uuid.UUID, *pagination.Page) error); ok {
+		r1 = returnFunc(ctx, communityID, page)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockPostsRepository_GetPageByCommunity_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetPageByCommunity'
+type MockPostsRepository_GetPageByCommunity_Call struct {
+	*mock.Call
+}
+
+// GetPageByCommunity is a helper method to define mock.On call
+//   - ctx context.Context
+//   - communityID uuid.UUID
+//   - page *pagination.Page
+func (_e *MockPostsRepository_Expecter) GetPageByCommunity(ctx interface{}, communityID interface{}, page interface{}) *MockPostsRepository_GetPageByCommunity_Call {
+	return &MockPostsRepository_GetPageByCommunity_Call{Call: _e.mock.On("GetPageByCommunity", ctx, communityID, page)}
+}
+
+func (_c *MockPostsRepository_GetPageByCommunity_Call) Run(run func(ctx context.Context, communityID uuid.UUID, page *pagination.Page)) *MockPostsRepository_GetPageByCommunity_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uuid.UUID
+		if args[1] != nil {
+			arg1 = args[1].(uuid.UUID)
+		}
+		var arg2 *pagination.Page
+		if args[2] != nil {
+			arg2 = args[2].(*pagination.Page)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockPostsRepository_GetPageByCommunity_Call) Return(posts []*models.Post, err error) *MockPostsRepository_GetPageByCommunity_Call {
+	_c.Call.Return(posts, err)
+	return _c
+}
+
+func (_c *MockPostsRepository_GetPageByCommunity_Call) RunAndReturn(run func(ctx context.Context, communityID uuid.UUID, page *pagination.Page) ([]*models.Post, error)) *MockPostsRepository_GetPageByCommunity_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetPageByTag provides a mock function for the type MockPostsRepository
+func (_mock *MockPostsRepository) GetPageByTag(ctx context.Context, tag string, page *pagination.Page) ([]*models.Post, error) {
+	ret := _mock.Called(ctx, tag, page)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPageByTag")
+	}
+
+	var r0 []*models.Post
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, *pagination.Page) ([]*models.Post, error)); ok {
+		return returnFunc(ctx, tag, page)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, *pagination.Page) []*models.Post); ok {
+		r0 = returnFunc(ctx, tag, page)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Post)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, *pagination.Page) error); ok {
+		r1 = returnFunc(ctx, tag, page)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockPostsRepository_GetPageByTag_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetPageByTag'
+type MockPostsRepository_GetPageByTag_Call struct {
+	*mock.Call
+}
+
+// GetPageByTag is a helper method to define mock.On call
+//   - ctx context.Context
+//   - tag string
+//   - page *pagination.Page
+func (_e *MockPostsRepository_Expecter) GetPageByTag(ctx interface{}, tag interface{}, page interface{}) *MockPostsRepository_GetPageByTag_Call {
+	return &MockPostsRepository_GetPageByTag_Call{Call: _e.mock.On("GetPageByTag", ctx, tag, page)}
+}
+
+func (_c *MockPostsRepository_GetPageByTag_Call) Run(run func(ctx context.Context, tag string, page *pagination.Page)) *MockPostsRepository_GetPageByTag_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 *pagination.Page
+		if args[2] != nil {
+			arg2 = args[2].(*pagination.Page)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockPostsRepository_GetPageByTag_Call) Return(posts []*models.Post, err error) *MockPostsRepository_GetPageByTag_Call {
+	_c.Call.Return(posts, err)
+	return _c
+}
+
+func (_c *MockPostsRepository_GetPageByTag_Call) RunAndReturn(run func(ctx context.Context, tag string, page *pagination.Page) ([]*models.Post, error)) *MockPostsRepository_GetPageByTag_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetReactions provides a mock function for the type MockPostsRepository
+func (_mock *MockPostsRepository) GetReactions(ctx context.Context, postIDs []uuid.UUID, viewerID *uuid.UUID) (map[uuid.UUID][]*models.ReactionCount, error) {
+	ret := _mock.Called(ctx, postIDs, viewerID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetReactions")
+	}
+
+	var r0 map[uuid.UUID][]*models.ReactionCount
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, []uuid.UUID, *uuid.UUID) (map[uuid.UUID][]*models.ReactionCount, error)); ok {
+		return returnFunc(ctx, postIDs, viewerID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, []uuid.UUID, *uuid.UUID) map[uuid.UUID][]*models.ReactionCount); ok {
+		r0 = returnFunc(ctx, postIDs, viewerID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[uuid.UUID][]*models.ReactionCount)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, []uuid.UUID, *uuid.UUID) error); ok {
+		r1 = returnFunc(ctx, postIDs, viewerID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockPostsRepository_GetReactions_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetReactions'
+type MockPostsRepository_GetReactions_Call struct {
+	*mock.Call
+}
+
+// GetReactions is a helper method to define mock.On call
+//   - ctx context.Context
+//   - postIDs []uuid.UUID
+//   - viewerID *uuid.UUID
+func (_e *MockPostsRepository_Expecter) GetReactions(ctx interface{}, postIDs interface{}, viewerID interface{}) *MockPostsRepository_GetReactions_Call {
+	return &MockPostsRepository_GetReactions_Call{Call: _e.mock.On("GetReactions", ctx, postIDs, viewerID)}
+}
+
+func (_c *MockPostsRepository_GetReactions_Call) Run(run func(ctx context.Context, postIDs []uuid.UUID, viewerID *uuid.UUID)) *MockPostsRepository_GetReactions_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 []uuid.UUID
+		if args[1] != nil {
+			arg1 = args[1].([]uuid.UUID)
+		}
+		var arg2 *uuid.UUID
+		if args[2] != nil {
+			arg2 = args[2].(*uuid.UUID)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockPostsRepository_GetReactions_Call) Return(m map[uuid.UUID][]*models.ReactionCount, err error) *MockPostsRepository_GetReactions_Call {
+	_c.Call.Return(m, err)
+	return _c
+}
+
+func (_c *MockPostsRepository_GetReactions_Call) RunAndReturn(run func(ctx context.Context, postIDs []uuid.UUID, viewerID *uuid.UUID) (map[uuid.UUID][]*models.ReactionCount, error)) *MockPostsRepository_GetReactions_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetTags provides a mock function for the type MockPostsRepository
+func (_mock *MockPostsRepository) GetTags(ctx context.Context, postIDs []uuid.UUID) (map[uuid.UUID][]string, error) {
+	ret := _mock.Called(ctx, postIDs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTags")
+	}
+
+	var r0 map[uuid.UUID][]string
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, []uuid.UUID) (map[uuid.UUID][]string, error)); ok {
+		return returnFunc(ctx, postIDs)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, []uuid.UUID) map[uuid.UUID][]string); ok {
+		r0 = returnFunc(ctx, postIDs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[uuid.UUID][]string)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, []uuid.UUID) error); ok {
+		r1 = returnFunc(ctx, postIDs)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockPostsRepository_GetTags_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetTags'
+type MockPostsRepository_GetTags_Call struct {
+	*mock.Call
+}
+
+// GetTags is a helper method to define mock.On call
+//   - ctx context.Context
+//   - postIDs []uuid.UUID
+func (_e *MockPostsRepository_Expecter) GetTags(ctx interface{}, postIDs interface{}) *MockPostsRepository_GetTags_Call {
+	return &MockPostsRepository_GetTags_Call{Call: _e.mock.On("GetTags", ctx, postIDs)}
+}
+
+func (_c *MockPostsRepository_GetTags_Call) Run(run func(ctx context.Context, postIDs []uuid.UUID)) *MockPostsRepository_GetTags_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 []uuid.UUID
+		if args[1] != nil {
+			arg1 = args[1].([]uuid.UUID)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockPostsRepository_GetTags_Call) Return(m map[uuid.UUID][]string, err error) *MockPostsRepository_GetTags_Call {
+	_c.Call.Return(m, err)
+	return _c
+}
+
+func (_c *MockPostsRepository_GetTags_Call) RunAndReturn(run func(ctx context.Context, postIDs []uuid.UUID) (map[uuid.UUID][]string, error)) *MockPostsRepository_GetTags_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Search provides a mock function for the type MockPostsRepository
+func (_mock *MockPostsRepository) Search(ctx context.Context, query string, page *pagination.Page) ([]*models.SearchHit, error) {
+	ret := _mock.Called(ctx, query, page)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Search")
+	}
+
+	var r0 []*models.SearchHit
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, *pagination.Page) ([]*models.SearchHit, error)); ok {
+		return returnFunc(ctx, query, page)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, *pagination.Page) []*models.SearchHit); ok {
+		r0 = returnFunc(ctx, query, page)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.SearchHit)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, *pagination.Page) error); ok {
+		r1 = returnFunc(ctx, query, page)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockPostsRepository_Search_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Search'
+type MockPostsRepository_Search_Call struct {
+	*mock.Call
+}
+
+// Search is a helper method to define mock.On call
+//   - ctx context.Context
+//   - query string
+//   - page *pagination.Page
+func (_e *MockPostsRepository_Expecter) Search(ctx interface{}, query interface{}, page interface{}) *MockPostsRepository_Search_Call {
+	return &MockPostsRepository_Search_Call{Call: _e.mock.On("Search", ctx, query, page)}
+}
+
+func (_c *MockPostsRepository_Search_Call) Run(run func(ctx context.Context, query string, page *pagination.Page)) *MockPostsRepository_Search_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 *pagination.Page
+		if args[2] != nil {
+			arg2 = args[2].(*pagination.Page)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockPostsRepository_Search_Call) Return(searchHits []*models.SearchHit, err error) *MockPostsRepository_Search_Call {
+	_c.Call.Return(searchHits, err)
+	return _c
+}
+
+func (_c *MockPostsRepository_Search_Call) RunAndReturn(run func(ctx context.Context, query string, page *pagination.Page) ([]*models.SearchHit, error)) *MockPostsRepository_Search_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SearchTags provides a mock function for the type MockPostsRepository
+func (_mock *MockPostsRepository) SearchTags(ctx context.Context, prefix string, limit int32) ([]*models.Tag, error) {
+	ret := _mock.Called(ctx, prefix, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SearchTags")
+	}
+
+	var r0 []*models.Tag
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, int32) ([]*models.Tag, error)); ok {
+		return returnFunc(ctx, prefix, limit)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, int32) []*models.Tag); ok {
+		r0 = returnFunc(ctx, prefix, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Tag)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, int32) error); ok {
+		r1 = returnFunc(ctx, prefix, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockPostsRepository_SearchTags_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SearchTags'
+type MockPostsRepository_SearchTags_Call struct {
+	*mock.Call
+}
+
+// SearchTags is a helper method to define mock.On call
+//   - ctx context.Context
+//   - prefix string
+//   - limit int32
+func (_e *MockPostsRepository_Expecter) SearchTags(ctx interface{}, prefix interface{}, limit interface{}) *MockPostsRepository_SearchTags_Call {
+	return &MockPostsRepository_SearchTags_Call{Call: _e.mock.On("SearchTags", ctx, prefix, limit)}
+}
+
+func (_c *MockPostsRepository_SearchTags_Call) Run(run func(ctx context.Context, prefix string, limit int32)) *MockPostsRepository_SearchTags_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 int32
+		if args[2] != nil {
+			arg2 = args[2].(int32)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockPostsRepository_SearchTags_Call) Return(tags []*models.Tag, err error) *MockPostsRepository_SearchTags_Call {
+	_c.Call.Return(tags, err)
+	return _c
+}
+
+func (_c *MockPostsRepository_SearchTags_Call) RunAndReturn(run func(ctx context.Context, prefix string, limit int32) ([]*models.Tag, error)) *MockPostsRepository_SearchTags_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetTags provides a mock function for the type MockPostsRepository
+func (_mock *MockPostsRepository) SetTags(ctx context.Context, postID uuid.UUID, tags []string) error {
+	ret := _mock.Called(ctx, postID, tags)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetTags")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID, []string) error); ok {
+		r0 = returnFunc(ctx, postID, tags)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockPostsRepository_SetTags_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetTags'
+type MockPostsRepository_SetTags_Call struct {
+	*mock.Call
+}
+
+// SetTags is a helper method to define mock.On call
+//   - ctx context.Context
+//   - postID uuid.UUID
+//   - tags []string
+func (_e *MockPostsRepository_Expecter) SetTags(ctx interface{}, postID interface{}, tags interface{}) *MockPostsRepository_SetTags_Call {
+	return &MockPostsRepository_SetTags_Call{Call: _e.mock.On("SetTags", ctx, postID, tags)}
+}
+
+func (_c *MockPostsRepository_SetTags_Call) Run(run func(ctx context.Context, postID uuid.UUID, tags []string)) *MockPostsRepository_SetTags_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uuid.UUID
+		if args[1] != nil {
+			arg1 = args[1].(uuid.UUID)
+		}
+		var arg2 []string
+		if args[2] != nil {
+			arg2 = args[2].([]string)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockPostsRepository_SetTags_Call) Return(err error) *MockPostsRepository_SetTags_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockPostsRepository_SetTags_Call) RunAndReturn(run func(ctx context.Context, postID uuid.UUID, tags []string) error) *MockPostsRepository_SetTags_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SoftDelete provides a mock function for the type MockPostsRepository
+func (_mock *MockPostsRepository) SoftDelete(ctx context.Context, postID uuid.UUID) (*models.Post, error) {
+	ret := _mock.Called(ctx, postID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SoftDelete")
+	}
+
+	var r0 *models.Post
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID) (*models.Post, error)); ok {
+		return returnFunc(ctx, postID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID) *models.Post); ok {
+		r0 = returnFunc(ctx, postID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Post)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, uuid.UUID) error); ok {
+		r1 = returnFunc(ctx, postID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockPostsRepository_SoftDelete_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SoftDelete'
+type MockPostsRepository_SoftDelete_Call struct {
+	*mock.Call
+}
+
+// SoftDelete is a helper method to define mock.On call
+//   - ctx context.Context
+//   - postID uuid.UUID
+func (_e *MockPostsRepository_Expecter) SoftDelete(ctx interface{}, postID interface{}) *MockPostsRepository_SoftDelete_Call {
+	return &MockPostsRepository_SoftDelete_Call{Call: _e.mock.On("SoftDelete", ctx, postID)}
+}
+
+func (_c *MockPostsRepository_SoftDelete_Call) Run(run func(ctx context.Context, postID uuid.UUID)) *MockPostsRepository_SoftDelete_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uuid.UUID
+		if args[1] != nil {
+			arg1 = args[1].(uuid.UUID)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockPostsRepository_SoftDelete_Call) Return(post *models.Post, err error) *MockPostsRepository_SoftDelete_Call {
+	_c.Call.Return(post, err)
+	return _c
+}
+
+func (_c *MockPostsRepository_SoftDelete_Call) RunAndReturn(run func(ctx context.Context, postID uuid.UUID) (*models.Post, error)) *MockPostsRepository_SoftDelete_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Update provides a mock function for the type MockPostsRepository
+func (_mock *MockPostsRepository) Update(ctx context.Context, postID uuid.UUID, title string, content string) (*models.Post, error) {
+	ret := _mock.Called(ctx, postID, title, content)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Update")
+	}
+
+	var r0 *models.Post
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID, string, string) (*models.Post, error)); ok {
+		return returnFunc(ctx, postID, title, content)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID, string, string) *models.Post); ok {
+		r0 = returnFunc(ctx, postID, title, content)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Post)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, uuid.UUID, string, string) error); ok {
+		r1 = returnFunc(ctx, postID, title, content)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockPostsRepository_Update_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Update'
+type MockPostsRepository_Update_Call struct {
+	*mock.Call
+}
+
+// Update is a helper method to define mock.On call
+//   - ctx context.Context
+//   - postID uuid.UUID
+//   - title string
+//   - content string
+func (_e *MockPostsRepository_Expecter) Update(ctx interface{}, postID interface{}, title interface{}, content interface{}) *MockPostsRepository_Update_Call {
+	return &MockPostsRepository_Update_Call{Call: _e.mock.On("Update", ctx, postID, title, content)}
+}
+
+func (_c *MockPostsRepository_Update_Call) Run(run func(ctx context.Context, postID uuid.UUID, title string, content string)) *MockPostsRepository_Update_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uuid.UUID
+		if args[1] != nil {
+			arg1 = args[1].(uuid.UUID)
+		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
+		}
+		var arg3 string
+		if args[3] != nil {
+			arg3 = args[3].(string)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+			arg3,
+		)
+	})
+	return _c
+}
+
+func (_c *MockPostsRepository_Update_Call) Return(post *models.Post, err error) *MockPostsRepository_Update_Call {
+	_c.Call.Return(post, err)
+	return _c
+}
+
+func (_c *MockPostsRepository_Update_Call) RunAndReturn(run func(ctx context.Context, postID uuid.UUID, title string, content string) (*models.Post, error)) *MockPostsRepository_Update_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockRefreshTokensRepository creates a new instance of MockRefreshTokensRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockRefreshTokensRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockRefreshTokensRepository {
+	mock := &MockRefreshTokensRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockRefreshTokensRepository is an autogenerated mock type for the RefreshTokensRepository type
+type MockRefreshTokensRepository struct {
+	mock.Mock
+}
+
+type MockRefreshTokensRepository_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockRefreshTokensRepository) EXPECT() *MockRefreshTokensRepository_Expecter {
+	return &MockRefreshTokensRepository_Expecter{mock: &_m.Mock}
+}
+
+// Add provides a mock function for the type MockRefreshTokensRepository
+func (_mock *MockRefreshTokensRepository) Add(ctx context.Context, ID uuid.UUID, sessionID uuid.UUID, userID uuid.UUID, tokenHash string, expiresAt time.Time) (*models.RefreshToken, error) {
+	ret := _mock.Called(ctx, ID, sessionID, userID, tokenHash, expiresAt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Add")
+	}
+
+	var r0 *models.RefreshToken
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID, uuid.UUID, string, time.Time) (*models.RefreshToken, error)); ok {
+		return returnFunc(ctx, ID, sessionID, userID, tokenHash, expiresAt)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID, uuid.UUID, string, time.Time) *models.RefreshToken); ok {
+		r0 = returnFunc(ctx, ID, sessionID, userID, tokenHash, expiresAt)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.RefreshToken)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, uuid.UUID, uuid.UUID, uuid.UUID, string, time.Time) error); ok {
+		r1 = returnFunc(ctx, ID, sessionID, userID, tokenHash, expiresAt)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockRefreshTokensRepository_Add_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Add'
+type MockRefreshTokensRepository_Add_Call struct {
+	*mock.Call
+}
+
+// Add is a helper method to define mock.On call
+//   - ctx context.Context
+//   - ID uuid.UUID
+//   - sessionID uuid.UUID
+//   - userID uuid.UUID
+//   - tokenHash string
+//   - expiresAt time.Time
+func (_e *MockRefreshTokensRepository_Expecter) Add(ctx interface{}, ID interface{}, sessionID interface{}, userID interface{}, tokenHash interface{}, expiresAt interface{}) *MockRefreshTokensRepository_Add_Call {
+	return &MockRefreshTokensRepository_Add_Call{Call: _e.mock.On("Add", ctx, ID, sessionID, userID, tokenHash, expiresAt)}
+}
+
+func (_c *MockRefreshTokensRepository_Add_Call) Run(run func(ctx context.Context, ID uuid.UUID, sessionID uuid.UUID, userID uuid.UUID, tokenHash string, expiresAt time.Time)) *MockRefreshTokensRepository_Add_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uuid.UUID
+		if args[1] != nil {
+			arg1 = args[1].(uuid.UUID)
+		}
+		var arg2 uuid.UUID
+		if args[2] != nil {
+			arg2 = args[2].(uuid.UUID)
+		}
+		var arg3 uuid.UUID
+		if args[3] != nil {
+			arg3 = args[3].(uuid.UUID)
+		}
+		var arg4 string
+		if args[4] != nil {
+			arg4 = args[4].(string)
+		}
+		var arg5 time.Time
+		if args[5] != nil {
+			arg5 = args[5].(time.Time)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+			arg3,
+			arg4,
+			arg5,
+		)
+	})
+	return _c
+}
+
+func (_c *MockRefreshTokensRepository_Add_Call) Return(refreshToken *models.RefreshToken, err error) *MockRefreshTokensRepository_Add_Call {
+	_c.Call.Return(refreshToken, err)
+	return _c
+}
+
+func (_c *MockRefreshTokensRepository_Add_Call) RunAndReturn(run func(ctx context.Context, ID uuid.UUID, sessionID uuid.UUID, userID uuid.UUID, tokenHash string, expiresAt time.Time) (*models.RefreshToken, error)) *MockRefreshTokensRepository_Add_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetByID provides a mock function for the type MockRefreshTokensRepository
+func (_mock *MockRefreshTokensRepository) GetByID(ctx context.Context, ID uuid.UUID) (*models.RefreshToken, error) {
+	ret := _mock.Called(ctx, ID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByID")
+	}
+
+	var r0 *models.RefreshToken
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID) (*models.RefreshToken, error)); ok {
+		return returnFunc(ctx, ID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID) *models.RefreshToken); ok {
+		r0 = returnFunc(ctx, ID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.RefreshToken)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, uuid.UUID) error); ok {
+		r1 = returnFunc(ctx, ID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockRefreshTokensRepository_GetByID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetByID'
+type MockRefreshTokensRepository_GetByID_Call struct {
+	*mock.Call
+}
+
+// GetByID is a helper method to define mock.On call
+//   - ctx context.Context
+//   - ID uuid.UUID
+func (_e *MockRefreshTokensRepository_Expecter) GetByID(ctx interface{}, ID interface{}) *MockRefreshTokensRepository_GetByID_Call {
+	return &MockRefreshTokensRepository_GetByID_Call{Call: _e.mock.On("GetByID", ctx, ID)}
+}
+
+func (_c *MockRefreshTokensRepository_GetByID_Call) Run(run func(ctx context.Context, ID uuid.UUID)) *MockRefreshTokensRepository_GetByID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uuid.UUID
+		if args[1] != nil {
+			arg1 = args[1].(uuid.UUID)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
 
-func (_c *MockPostsRepository_GetByID_Call) Run(run func(ctx context.Context, postID uuid.UUID, forUpdate bool)) *MockPostsRepository_GetByID_Call {
+func (_c *MockRefreshTokensRepository_GetByID_Call) Return(refreshToken *models.RefreshToken, err error) *MockRefreshTokensRepository_GetByID_Call {
+	_c.Call.Return(refreshToken, err)
+	return _c
+}
+
+func (_c *MockRefreshTokensRepository_GetByID_Call) RunAndReturn(run func(ctx context.Context, ID uuid.UUID) (*models.RefreshToken, error)) *MockRefreshTokensRepository_GetByID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetByTokenHash provides a mock function for the type MockRefreshTokensRepository
+func (_mock *MockRefreshTokensRepository) GetByTokenHash(ctx context.Context, tokenHash string, forUpdate bool) (*models.RefreshToken, error) {
+	ret := _mock.Called(ctx, tokenHash, forUpdate)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByTokenHash")
+	}
+
+	var r0 *models.RefreshToken
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, bool) (*models.RefreshToken, error)); ok {
+		return returnFunc(ctx, tokenHash, forUpdate)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, bool) *models.RefreshToken); ok {
+		r0 = returnFunc(ctx, tokenHash, forUpdate)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.RefreshToken)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, bool) error); ok {
+		r1 = returnFunc(ctx, tokenHash, forUpdate)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockRefreshTokensRepository_GetByTokenHash_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetByTokenHash'
+type MockRefreshTokensRepository_GetByTokenHash_Call struct {
+	*mock.Call
+}
+
+// GetByTokenHash is a helper method to define mock.On call
+//   - ctx context.Context
+//   - tokenHash string
+//   - forUpdate bool
+func (_e *MockRefreshTokensRepository_Expecter) GetByTokenHash(ctx interface{}, tokenHash interface{}, forUpdate interface{}) *MockRefreshTokensRepository_GetByTokenHash_Call {
+	return &MockRefreshTokensRepository_GetByTokenHash_Call{Call: _e.mock.On("GetByTokenHash", ctx, tokenHash, forUpdate)}
+}
+
+func (_c *MockRefreshTokensRepository_GetByTokenHash_Call) Run(run func(ctx context.Context, tokenHash string, forUpdate bool)) *MockRefreshTokensRepository_GetByTokenHash_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		var arg0 context.Context
 		if args[0] != nil {
 			arg0 = args[0].(context.Context)
 		}
-		var arg1 uuid.UUID
+		var arg1 string
 		if args[1] != nil {
-			arg1 = args[1].(uuid.UUID)
+			arg1 = args[1].(string)
 		}
 		var arg2 bool
 		if args[2] != nil {
@@ -728,12 +4339,183 @@ func (_c *MockPostsRepository_GetByID_Call) Run(run func(ctx context.Context, po
 	return _c
 }
 
-func (_c *MockPostsRepository_GetByID_Call) Return(post *models.Post, err error) *MockPostsRepository_GetByID_Call {
-	_c.Call.Return(post, err)
+func (_c *MockRefreshTokensRepository_GetByTokenHash_Call) Return(refreshToken *models.RefreshToken, err error) *MockRefreshTokensRepository_GetByTokenHash_Call {
+	_c.Call.Return(refreshToken, err)
 	return _c
 }
 
-func (_c *MockPostsRepository_GetByID_Call) RunAndReturn(run func(ctx context.Context, postID uuid.UUID, forUpdate bool) (*models.Post, error)) *MockPostsRepository_GetByID_Call {
+func (_c *MockRefreshTokensRepository_GetByTokenHash_Call) RunAndReturn(run func(ctx context.Context, tokenHash string, forUpdate bool) (*models.RefreshToken, error)) *MockRefreshTokensRepository_GetByTokenHash_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Revoke provides a mock function for the type MockRefreshTokensRepository
+func (_mock *MockRefreshTokensRepository) Revoke(ctx context.Context, ID uuid.UUID) error {
+	ret := _mock.Called(ctx, ID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Revoke")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID) error); ok {
+		r0 = returnFunc(ctx, ID)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockRefreshTokensRepository_Revoke_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Revoke'
+type MockRefreshTokensRepository_Revoke_Call struct {
+	*mock.Call
+}
+
+// Revoke is a helper method to define mock.On call
+//   - ctx context.Context
+//   - ID uuid.UUID
+func (_e *MockRefreshTokensRepository_Expecter) Revoke(ctx interface{}, ID interface{}) *MockRefreshTokensRepository_Revoke_Call {
+	return &MockRefreshTokensRepository_Revoke_Call{Call: _e.mock.On("Revoke", ctx, ID)}
+}
+
+func (_c *MockRefreshTokensRepository_Revoke_Call) Run(run func(ctx context.Context, ID uuid.UUID)) *MockRefreshTokensRepository_Revoke_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uuid.UUID
+		if args[1] != nil {
+			arg1 = args[1].(uuid.UUID)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockRefreshTokensRepository_Revoke_Call) Return(err error) *MockRefreshTokensRepository_Revoke_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockRefreshTokensRepository_Revoke_Call) RunAndReturn(run func(ctx context.Context, ID uuid.UUID) error) *MockRefreshTokensRepository_Revoke_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RevokeAllByUserID provides a mock function for the type MockRefreshTokensRepository
+func (_mock *MockRefreshTokensRepository) RevokeAllByUserID(ctx context.Context, userID uuid.UUID) error {
+	ret := _mock.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeAllByUserID")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID) error); ok {
+		r0 = returnFunc(ctx, userID)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockRefreshTokensRepository_RevokeAllByUserID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RevokeAllByUserID'
+type MockRefreshTokensRepository_RevokeAllByUserID_Call struct {
+	*mock.Call
+}
+
+// RevokeAllByUserID is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID uuid.UUID
+func (_e *MockRefreshTokensRepository_Expecter) RevokeAllByUserID(ctx interface{}, userID interface{}) *MockRefreshTokensRepository_RevokeAllByUserID_Call {
+	return &MockRefreshTokensRepository_RevokeAllByUserID_Call{Call: _e.mock.On("RevokeAllByUserID", ctx, userID)}
+}
+
+func (_c *MockRefreshTokensRepository_RevokeAllByUserID_Call) Run(run func(ctx context.Context, userID uuid.UUID)) *MockRefreshTokensRepository_RevokeAllByUserID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uuid.UUID
+		if args[1] != nil {
+			arg1 = args[1].(uuid.UUID)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockRefreshTokensRepository_RevokeAllByUserID_Call) Return(err error) *MockRefreshTokensRepository_RevokeAllByUserID_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockRefreshTokensRepository_RevokeAllByUserID_Call) RunAndReturn(run func(ctx context.Context, userID uuid.UUID) error) *MockRefreshTokensRepository_RevokeAllByUserID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RevokeSession provides a mock function for the type MockRefreshTokensRepository
+func (_mock *MockRefreshTokensRepository) RevokeSession(ctx context.Context, sessionID uuid.UUID) error {
+	ret := _mock.Called(ctx, sessionID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeSession")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID) error); ok {
+		r0 = returnFunc(ctx, sessionID)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockRefreshTokensRepository_RevokeSession_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RevokeSession'
+type MockRefreshTokensRepository_RevokeSession_Call struct {
+	*mock.Call
+}
+
+// RevokeSession is a helper method to define mock.On call
+//   - ctx context.Context
+//   - sessionID uuid.UUID
+func (_e *MockRefreshTokensRepository_Expecter) RevokeSession(ctx interface{}, sessionID interface{}) *MockRefreshTokensRepository_RevokeSession_Call {
+	return &MockRefreshTokensRepository_RevokeSession_Call{Call: _e.mock.On("RevokeSession", ctx, sessionID)}
+}
+
+func (_c *MockRefreshTokensRepository_RevokeSession_Call) Run(run func(ctx context.Context, sessionID uuid.UUID)) *MockRefreshTokensRepository_RevokeSession_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uuid.UUID
+		if args[1] != nil {
+			arg1 = args[1].(uuid.UUID)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockRefreshTokensRepository_RevokeSession_Call) Return(err error) *MockRefreshTokensRepository_RevokeSession_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockRefreshTokensRepository_RevokeSession_Call) RunAndReturn(run func(ctx context.Context, sessionID uuid.UUID) error) *MockRefreshTokensRepository_RevokeSession_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -839,6 +4621,142 @@ func (_c *MockUsersRepository_Add_Call) RunAndReturn(run func(ctx context.Contex
 	return _c
 }
 
+// GetByID provides a mock function for the type MockUsersRepository
+func (_mock *MockUsersRepository) GetByID(ctx context.Context, ID uuid.UUID) (*models.User, error) {
+	ret := _mock.Called(ctx, ID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByID")
+	}
+
+	var r0 *models.User
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID) (*models.User, error)); ok {
+		return returnFunc(ctx, ID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID) *models.User); ok {
+		r0 = returnFunc(ctx, ID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.User)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, uuid.UUID) error); ok {
+		r1 = returnFunc(ctx, ID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockUsersRepository_GetByID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetByID'
+type MockUsersRepository_GetByID_Call struct {
+	*mock.Call
+}
+
+// GetByID is a helper method to define mock.On call
+//   - ctx context.Context
+//   - ID uuid.UUID
+func (_e *MockUsersRepository_Expecter) GetByID(ctx interface{}, ID interface{}) *MockUsersRepository_GetByID_Call {
+	return &MockUsersRepository_GetByID_Call{Call: _e.mock.On("GetByID", ctx, ID)}
+}
+
+func (_c *MockUsersRepository_GetByID_Call) Run(run func(ctx context.Context, ID uuid.UUID)) *MockUsersRepository_GetByID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uuid.UUID
+		if args[1] != nil {
+			arg1 = args[1].(uuid.UUID)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockUsersRepository_GetByID_Call) Return(user *models.User, err error) *MockUsersRepository_GetByID_Call {
+	_c.Call.Return(user, err)
+	return _c
+}
+
+func (_c *MockUsersRepository_GetByID_Call) RunAndReturn(run func(ctx context.Context, ID uuid.UUID) (*models.User, error)) *MockUsersRepository_GetByID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetByIDs provides a mock function for the type MockUsersRepository
+func (_mock *MockUsersRepository) GetByIDs(ctx context.Context, IDs []uuid.UUID) ([]*models.User, error) {
+	ret := _mock.Called(ctx, IDs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByIDs")
+	}
+
+	var r0 []*models.User
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, []uuid.UUID) ([]*models.User, error)); ok {
+		return returnFunc(ctx, IDs)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, []uuid.UUID) []*models.User); ok {
+		r0 = returnFunc(ctx, IDs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.User)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, []uuid.UUID) error); ok {
+		r1 = returnFunc(ctx, IDs)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockUsersRepository_GetByIDs_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetByIDs'
+type MockUsersRepository_GetByIDs_Call struct {
+	*mock.Call
+}
+
+// GetByIDs is a helper method to define mock.On call
+//   - ctx context.Context
+//   - IDs []uuid.UUID
+func (_e *MockUsersRepository_Expecter) GetByIDs(ctx interface{}, IDs interface{}) *MockUsersRepository_GetByIDs_Call {
+	return &MockUsersRepository_GetByIDs_Call{Call: _e.mock.On("GetByIDs", ctx, IDs)}
+}
+
+func (_c *MockUsersRepository_GetByIDs_Call) Run(run func(ctx context.Context, IDs []uuid.UUID)) *MockUsersRepository_GetByIDs_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 []uuid.UUID
+		if args[1] != nil {
+			arg1 = args[1].([]uuid.UUID)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockUsersRepository_GetByIDs_Call) Return(users []*models.User, err error) *MockUsersRepository_GetByIDs_Call {
+	_c.Call.Return(users, err)
+	return _c
+}
+
+func (_c *MockUsersRepository_GetByIDs_Call) RunAndReturn(run func(ctx context.Context, IDs []uuid.UUID) ([]*models.User, error)) *MockUsersRepository_GetByIDs_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // GetByUsername provides a mock function for the type MockUsersRepository
 func (_mock *MockUsersRepository) GetByUsername(ctx context.Context, username string) (*models.User, error) {
 	ret := _mock.Called(ctx, username)
@@ -0,0 +1,18 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/Govorov1705/ozon-test/internal/models"
+	"github.com/google/uuid"
+)
+
+type OutboxRepository interface {
+	Add(ctx context.Context, ID uuid.UUID, eventType string, payload []byte) error
+	// GetUnprocessed блокирует выбранные события до конца транзакции,
+	// пропуская уже заблокированные другими экземплярами
+	GetUnprocessed(ctx context.Context, limit int32) ([]*models.OutboxEvent, error)
+	MarkProcessed(ctx context.Context, IDs []uuid.UUID) error
+	DeleteProcessedBefore(ctx context.Context, before time.Time) error
+}
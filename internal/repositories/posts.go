@@ -4,13 +4,42 @@ import (
 	"context"
 
 	"github.com/Govorov1705/ozon-test/internal/models"
+	"github.com/Govorov1705/ozon-test/internal/pagination"
 	"github.com/google/uuid"
 )
 
 type PostsRepository interface {
-	Add(ctx context.Context, userID uuid.UUID, title, content string, areCommentsAllowed bool) (*models.Post, error)
+	// communityID - сообщество поста, nil для поста вне сообществ
+	Add(ctx context.Context, userID uuid.UUID, communityID *uuid.UUID, title, content string, areCommentsAllowed bool) (*models.Post, error)
 	GetByID(ctx context.Context, postID uuid.UUID, forUpdate bool) (*models.Post, error)
 	GetAll(ctx context.Context) ([]*models.Post, error)
+	GetPage(ctx context.Context, page *pagination.Page) ([]*models.Post, error)
+	// GetPageByTag - GetPage только по постам с тегом tag
+	GetPageByTag(ctx context.Context, tag string, page *pagination.Page) ([]*models.Post, error)
+	// GetPageByCommunity - GetPage только по постам сообщества
+	GetPageByCommunity(ctx context.Context, communityID uuid.UUID, page *pagination.Page) ([]*models.Post, error)
 	DisableComments(ctx context.Context, postID uuid.UUID) (*models.Post, error)
 	EnableComments(ctx context.Context, postID uuid.UUID) (*models.Post, error)
+	Update(ctx context.Context, postID uuid.UUID, title, content string) (*models.Post, error)
+	// SoftDelete также снимает с поста теги
+	SoftDelete(ctx context.Context, postID uuid.UUID) (*models.Post, error)
+	CountByUserIDs(ctx context.Context, userIDs []uuid.UUID) (map[uuid.UUID]int32, error)
+	// Search находит неудаленные посты, содержащие все слова запроса,
+	// в порядке pagination.SortRelevance
+	Search(ctx context.Context, query string, page *pagination.Page) ([]*models.SearchHit, error)
+	// AddReaction возвращает false, если такая реакция уже есть
+	AddReaction(ctx context.Context, postID, userID uuid.UUID, emoji string) (bool, error)
+	// DeleteReaction возвращает false, если реакции не было
+	DeleteReaction(ctx context.Context, postID, userID uuid.UUID, emoji string) (bool, error)
+	// GetReactions группирует реакции по эмодзи в порядке первой реакции
+	// каждым из них. viewerID - пользователь, для которого заполняется
+	// ViewerHasReacted, nil для анонимного
+	GetReactions(ctx context.Context, postIDs []uuid.UUID, viewerID *uuid.UUID) (map[uuid.UUID][]*models.ReactionCount, error)
+	// SetTags заменяет теги поста. Теги должны быть уже нормализованы
+	SetTags(ctx context.Context, postID uuid.UUID, tags []string) error
+	// GetTags возвращает теги постов по алфавиту
+	GetTags(ctx context.Context, postIDs []uuid.UUID) (map[uuid.UUID][]string, error)
+	// SearchTags возвращает не больше limit тегов, начинающихся с prefix,
+	// от самых популярных к менее популярным, а при равенстве - по алфавиту
+	SearchTags(ctx context.Context, prefix string, limit int32) ([]*models.Tag, error)
 }
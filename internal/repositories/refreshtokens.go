@@ -0,0 +1,18 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/Govorov1705/ozon-test/internal/models"
+	"github.com/google/uuid"
+)
+
+type RefreshTokensRepository interface {
+	Add(ctx context.Context, ID, sessionID, userID uuid.UUID, tokenHash string, expiresAt time.Time) (*models.RefreshToken, error)
+	GetByID(ctx context.Context, ID uuid.UUID) (*models.RefreshToken, error)
+	GetByTokenHash(ctx context.Context, tokenHash string, forUpdate bool) (*models.RefreshToken, error)
+	Revoke(ctx context.Context, ID uuid.UUID) error
+	RevokeSession(ctx context.Context, sessionID uuid.UUID) error
+	RevokeAllByUserID(ctx context.Context, userID uuid.UUID) error
+}
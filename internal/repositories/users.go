@@ -4,9 +4,12 @@ import (
 	"context"
 
 	"github.com/Govorov1705/ozon-test/internal/models"
+	"github.com/google/uuid"
 )
 
 type UsersRepository interface {
+	GetByID(ctx context.Context, ID uuid.UUID) (*models.User, error)
+	GetByIDs(ctx context.Context, IDs []uuid.UUID) ([]*models.User, error)
 	GetByUsername(ctx context.Context, username string) (*models.User, error)
 	Add(ctx context.Context, username, hashedPassword string) (*models.User, error)
 }
@@ -0,0 +1,151 @@
+package search
+
+import (
+	"html"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Разметка фрагментов одинакова во всех хранилищах. Фрагмент - это HTML:
+// текст в нем экранирован, а теги добавляются только вокруг слов запроса
+const (
+	HighlightStart = "<b>"
+	HighlightEnd   = "</b>"
+	Ellipsis       = "…"
+	// PostgreSQL и SQLite выделяют слова управляющими символами вместо тегов,
+	// а Highlight затем экранирует их фрагмент и подставляет теги
+	MarkStart = "\x02"
+	MarkEnd   = "\x03"
+	// SnippetWords - примерная длина фрагмента в словах
+	SnippetWords = 20
+)
+
+// Token - слово текста в нижнем регистре и его границы в байтах
+type Token struct {
+	Term  string
+	Start int
+	End   int
+}
+
+// Tokenize делит текст на слова из букв и цифр так же, как конфигурация
+// simple в PostgreSQL и токенизатор unicode61 в SQLite: без стемминга
+// и стоп-слов, регистр не учитывается
+func Tokenize(text string) []Token {
+	tokens := []Token{}
+
+	start := -1
+	for i, r := range text {
+		isWord := unicode.IsLetter(r) || unicode.IsDigit(r)
+		if isWord && start < 0 {
+			start = i
+		}
+		if !isWord && start >= 0 {
+			tokens = append(tokens, newToken(text, start, i))
+			start = -1
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, newToken(text, start, len(text)))
+	}
+
+	return tokens
+}
+
+func newToken(text string, start, end int) Token {
+	return Token{Term: strings.ToLower(text[start:end]), Start: start, End: end}
+}
+
+// Terms возвращает различные слова запроса. Документ подходит под запрос,
+// если содержит их все
+func Terms(query string) []string {
+	terms := []string{}
+	for _, token := range Tokenize(query) {
+		if !slices.Contains(terms, token.Term) {
+			terms = append(terms, token.Term)
+		}
+	}
+
+	return terms
+}
+
+// Snippet вырезает из текста около SnippetWords слов вокруг первого
+// найденного слова запроса и выделяет в них все слова запроса
+func Snippet(text string, terms []string) string {
+	tokens := Tokenize(text)
+	if len(tokens) == 0 {
+		return ""
+	}
+
+	first := slices.IndexFunc(tokens, func(token Token) bool {
+		return slices.Contains(terms, token.Term)
+	})
+
+	// Перед первым совпадением оставляем немного контекста
+	start := max(first-SnippetWords/4, 0)
+	end := min(start+SnippetWords, len(tokens))
+	start = max(end-SnippetWords, 0)
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString(Ellipsis)
+	}
+
+	pos := tokens[start].Start
+	for _, token := range tokens[start:end] {
+		if !slices.Contains(terms, token.Term) {
+			continue
+		}
+		b.WriteString(html.EscapeString(text[pos:token.Start]))
+		b.WriteString(HighlightStart)
+		b.WriteString(html.EscapeString(text[token.Start:token.End]))
+		b.WriteString(HighlightEnd)
+		pos = token.End
+	}
+	if end < len(tokens) {
+		b.WriteString(html.EscapeString(text[pos:tokens[end-1].End]))
+		b.WriteString(Ellipsis)
+	} else {
+		b.WriteString(html.EscapeString(text[pos:]))
+	}
+
+	return b.String()
+}
+
+// Highlight превращает фрагмент с метками MarkStart и MarkEnd в разметку
+// Snippet. Хранилища выделяют метками отдельные слова, а метки из самого
+// текста, которые не обрамляют слово, отбрасываются
+func Highlight(marked string) string {
+	var b strings.Builder
+
+	for {
+		i := strings.IndexAny(marked, MarkStart+MarkEnd)
+		if i < 0 {
+			b.WriteString(html.EscapeString(marked))
+			break
+		}
+		b.WriteString(html.EscapeString(marked[:i]))
+
+		isStart := marked[i:i+1] == MarkStart
+		marked = marked[i+1:]
+		if !isStart {
+			continue
+		}
+
+		end := strings.IndexAny(marked, MarkStart+MarkEnd)
+		if end > 0 && marked[end:end+1] == MarkEnd && isWord(marked[:end]) {
+			b.WriteString(HighlightStart)
+			b.WriteString(marked[:end])
+			b.WriteString(HighlightEnd)
+			marked = marked[end+1:]
+		}
+	}
+
+	return b.String()
+}
+
+func isWord(text string) bool {
+	return !strings.ContainsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
@@ -2,32 +2,42 @@ package services
 
 import (
 	"context"
+	"errors"
 
 	"github.com/Govorov1705/ozon-test/internal/dtos"
 	"github.com/Govorov1705/ozon-test/internal/errs"
 	"github.com/Govorov1705/ozon-test/internal/logger"
 	"github.com/Govorov1705/ozon-test/internal/models"
+	"github.com/Govorov1705/ozon-test/internal/pagination"
 	"github.com/Govorov1705/ozon-test/internal/repositories"
 	"github.com/Govorov1705/ozon-test/internal/transactions"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
+const defaultMaxDepth int32 = 3
+
 type CommentsService struct {
 	txStarter    transactions.TxStarter
 	commentsRepo repositories.CommentsRepository
 	postsRepo    repositories.PostsRepository
+	usersRepo    repositories.UsersRepository
+	outboxRepo   repositories.OutboxRepository
 }
 
 func NewCommentsService(
 	txStarter transactions.TxStarter,
 	cr repositories.CommentsRepository,
 	pr repositories.PostsRepository,
+	ur repositories.UsersRepository,
+	or repositories.OutboxRepository,
 ) *CommentsService {
 	return &CommentsService{
 		txStarter:    txStarter,
 		commentsRepo: cr,
 		postsRepo:    pr,
+		usersRepo:    ur,
+		outboxRepo:   or,
 	}
 }
 
@@ -62,6 +72,10 @@ func (s *CommentsService) CreateComment(ctx context.Context, req *dtos.CreateCom
 		return nil, err
 	}
 
+	if post.DeletedAt != nil {
+		return nil, errs.ErrPostDeleted
+	}
+
 	if !post.AreCommentsAllowed {
 		return nil, errs.ErrCommentsNotAllowed
 	}
@@ -75,8 +89,536 @@ func (s *CommentsService) CreateComment(ctx context.Context, req *dtos.CreateCom
 		if parentComment.PostID != post.ID {
 			return nil, errs.ErrPostAndReplyMismatch
 		}
+		if parentComment.DeletedAt != nil {
+			return nil, errs.ErrCommentDeleted
+		}
 		rootID = &parentComment.RootID
 	}
 
-	return s.commentsRepo.Add(ctx, req.PostID, req.UserID, rootID, req.ReplyTo, req.Content)
+	comment, err = s.commentsRepo.Add(ctx, req.PostID, req.UserID, rootID, req.ReplyTo, req.Content)
+	if err != nil {
+		return nil, err
+	}
+
+	err = addPostActivity(ctx, s.outboxRepo, &dtos.PostActivityEvent{
+		Type:      models.EventCommentAdded,
+		PostID:    comment.PostID,
+		CommentID: comment.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return comment, nil
+}
+
+// EditComment меняет текст комментария. Предыдущий текст сохраняется в истории правок
+func (s *CommentsService) EditComment(ctx context.Context, req *dtos.EditCommentRequest) (comment *models.Comment, err error) {
+	tx, err := s.txStarter.Begin(ctx)
+	if err != nil {
+		logger.Logger.Error("error starting transaction", zap.Error(err))
+		return nil, errs.ErrInternal
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback(ctx)
+			panic(p)
+		} else if err != nil {
+			if rollbackErr := tx.Rollback(ctx); rollbackErr != nil {
+				logger.Logger.Error("rollback failed", zap.Error(rollbackErr))
+				err = errs.ErrInternal
+			}
+		} else {
+			if commitErr := tx.Commit(ctx); commitErr != nil {
+				logger.Logger.Error("error committing transaction", zap.Error(commitErr))
+				err = errs.ErrInternal
+			}
+		}
+	}()
+
+	ctx = transactions.PutTxIntoContext(ctx, tx)
+
+	comment, err = s.commentsRepo.GetByID(ctx, req.CommentID, true)
+	if err != nil {
+		return nil, err
+	}
+
+	if comment.UserID != req.UserID {
+		return nil, errs.ErrUnauthorized
+	}
+
+	if comment.DeletedAt != nil {
+		return nil, errs.ErrCommentDeleted
+	}
+
+	if comment.Content == req.Content {
+		return comment, nil
+	}
+
+	_, err = s.commentsRepo.AddRevision(ctx, comment.ID, comment.Content)
+	if err != nil {
+		return nil, err
+	}
+
+	comment, err = s.commentsRepo.UpdateContent(ctx, comment.ID, req.Content)
+	if err != nil {
+		return nil, err
+	}
+
+	err = addPostActivity(ctx, s.outboxRepo, &dtos.PostActivityEvent{
+		Type:      models.EventCommentUpdated,
+		PostID:    comment.PostID,
+		CommentID: comment.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return comment, nil
+}
+
+// DeleteComment помечает комментарий удаленным. Он остается в дереве
+// как заглушка, чтобы ответы на него не пропали. Удалять может автор или модератор
+func (s *CommentsService) DeleteComment(ctx context.Context, req *dtos.DeleteCommentRequest) (comment *models.Comment, err error) {
+	tx, err := s.txStarter.Begin(ctx)
+	if err != nil {
+		logger.Logger.Error("error starting transaction", zap.Error(err))
+		return nil, errs.ErrInternal
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback(ctx)
+			panic(p)
+		} else if err != nil {
+			if rollbackErr := tx.Rollback(ctx); rollbackErr != nil {
+				logger.Logger.Error("rollback failed", zap.Error(rollbackErr))
+				err = errs.ErrInternal
+			}
+		} else {
+			if commitErr := tx.Commit(ctx); commitErr != nil {
+				logger.Logger.Error("error committing transaction", zap.Error(commitErr))
+				err = errs.ErrInternal
+			}
+		}
+	}()
+
+	ctx = transactions.PutTxIntoContext(ctx, tx)
+
+	comment, err = s.commentsRepo.GetByID(ctx, req.CommentID, true)
+	if err != nil {
+		return nil, err
+	}
+
+	if comment.UserID != req.UserID {
+		moderator, err := isModerator(ctx, s.usersRepo, req.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if !moderator {
+			return nil, errs.ErrUnauthorized
+		}
+	}
+
+	if comment.DeletedAt != nil {
+		return comment, nil
+	}
+
+	// Текст на момент удаления сохраняется в истории правок для модераторов
+	_, err = s.commentsRepo.AddRevision(ctx, comment.ID, comment.Content)
+	if err != nil {
+		return nil, err
+	}
+
+	comment, err = s.commentsRepo.SoftDelete(ctx, comment.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = addPostActivity(ctx, s.outboxRepo, &dtos.PostActivityEvent{
+		Type:      models.EventCommentDeleted,
+		PostID:    comment.PostID,
+		CommentID: comment.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return comment, nil
+}
+
+// HardDeleteComment доступен только модераторам. Комментарий помечается
+// удаленным, а затем физически удаляется, если в его поддереве не осталось
+// живых ответов. После этого проверяются предки: удаленный предок, у которого
+// не осталось живых ответов, тоже удаляется. Возвращает true, если сам
+// комментарий был удален физически, и false, если он остался заглушкой
+func (s *CommentsService) HardDeleteComment(ctx context.Context, req *dtos.DeleteCommentRequest) (removed bool, err error) {
+	tx, err := s.txStarter.Begin(ctx)
+	if err != nil {
+		logger.Logger.Error("error starting transaction", zap.Error(err))
+		return false, errs.ErrInternal
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback(ctx)
+			panic(p)
+		} else if err != nil {
+			if rollbackErr := tx.Rollback(ctx); rollbackErr != nil {
+				logger.Logger.Error("rollback failed", zap.Error(rollbackErr))
+				err = errs.ErrInternal
+			}
+		} else {
+			if commitErr := tx.Commit(ctx); commitErr != nil {
+				logger.Logger.Error("error committing transaction", zap.Error(commitErr))
+				err = errs.ErrInternal
+			}
+		}
+	}()
+
+	ctx = transactions.PutTxIntoContext(ctx, tx)
+
+	moderator, err := isModerator(ctx, s.usersRepo, req.UserID)
+	if err != nil {
+		return false, err
+	}
+	if !moderator {
+		return false, errs.ErrUnauthorized
+	}
+
+	comment, err := s.commentsRepo.GetByID(ctx, req.CommentID, true)
+	if err != nil {
+		return false, err
+	}
+
+	softDeleted := false
+	if comment.DeletedAt == nil {
+		_, err = s.commentsRepo.AddRevision(ctx, comment.ID, comment.Content)
+		if err != nil {
+			return false, err
+		}
+
+		comment, err = s.commentsRepo.SoftDelete(ctx, comment.ID)
+		if err != nil {
+			return false, err
+		}
+		softDeleted = true
+	}
+
+	for {
+		hasReplies, err := s.commentsRepo.HasUndeletedReplies(ctx, comment.ID)
+		if err != nil {
+			return false, err
+		}
+		if hasReplies {
+			break
+		}
+
+		err = s.commentsRepo.Delete(ctx, comment.ID)
+		if err != nil {
+			return false, err
+		}
+		if comment.ID == req.CommentID {
+			removed = true
+		}
+
+		// Ответы удаляются вместе с комментарием, отдельных событий для них нет
+		err = addPostActivity(ctx, s.outboxRepo, &dtos.PostActivityEvent{
+			Type:      models.EventCommentDeleted,
+			PostID:    comment.PostID,
+			CommentID: comment.ID,
+			Removed:   true,
+		})
+		if err != nil {
+			return false, err
+		}
+
+		if comment.ReplyTo == nil {
+			break
+		}
+
+		comment, err = s.commentsRepo.GetByID(ctx, *comment.ReplyTo, true)
+		if err != nil {
+			return false, err
+		}
+		if comment.DeletedAt == nil {
+			break
+		}
+	}
+
+	if softDeleted && !removed {
+		err = addPostActivity(ctx, s.outboxRepo, &dtos.PostActivityEvent{
+			Type:      models.EventCommentDeleted,
+			PostID:    comment.PostID,
+			CommentID: req.CommentID,
+		})
+		if err != nil {
+			return false, err
+		}
+	}
+
+	return removed, nil
+}
+
+// VoteComment ставит, меняет или снимает голос пользователя и в той же
+// транзакции пересчитывает счетчики комментария. Комментарий блокируется,
+// поэтому одновременные голоса за него не теряются. Голосовать за удаленный
+// комментарий нельзя, но снять голос можно
+func (s *CommentsService) VoteComment(ctx context.Context, req *dtos.VoteCommentRequest) (comment *models.Comment, err error) {
+	tx, err := s.txStarter.Begin(ctx)
+	if err != nil {
+		logger.Logger.Error("error starting transaction", zap.Error(err))
+		return nil, errs.ErrInternal
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback(ctx)
+			panic(p)
+		} else if err != nil {
+			if rollbackErr := tx.Rollback(ctx); rollbackErr != nil {
+				logger.Logger.Error("rollback failed", zap.Error(rollbackErr))
+				err = errs.ErrInternal
+			}
+		} else {
+			if commitErr := tx.Commit(ctx); commitErr != nil {
+				logger.Logger.Error("error committing transaction", zap.Error(commitErr))
+				err = errs.ErrInternal
+			}
+		}
+	}()
+
+	ctx = transactions.PutTxIntoContext(ctx, tx)
+
+	comment, err = s.commentsRepo.GetByID(ctx, req.CommentID, true)
+	if err != nil {
+		return nil, err
+	}
+
+	if comment.DeletedAt != nil && req.Value != 0 {
+		return nil, errs.ErrCommentDeleted
+	}
+
+	var previous int8
+	vote, err := s.commentsRepo.GetVote(ctx, comment.ID, req.UserID)
+	if err == nil {
+		previous = vote.Value
+	} else if !errors.Is(err, errs.ErrNotFound) {
+		return nil, err
+	}
+
+	if previous == req.Value {
+		return comment, nil
+	}
+
+	if req.Value == 0 {
+		err = s.commentsRepo.DeleteVote(ctx, comment.ID, req.UserID)
+	} else {
+		_, err = s.commentsRepo.SetVote(ctx, comment.ID, req.UserID, req.Value)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	upvotes, downvotes := comment.Upvotes, comment.Downvotes
+	switch previous {
+	case 1:
+		upvotes--
+	case -1:
+		downvotes--
+	}
+	switch req.Value {
+	case 1:
+		upvotes++
+	case -1:
+		downvotes++
+	}
+
+	return s.commentsRepo.UpdateVotes(ctx, comment.ID, models.NewCommentVotes(upvotes, downvotes))
+}
+
+// GetRevisions возвращает историю правок комментариев. Всю историю видят
+// модераторы, автор - только историю своих неудаленных комментариев, остальным
+// она не отдается. История удаленных комментариев доступна только модераторам
+func (s *CommentsService) GetRevisions(ctx context.Context, commentIDs []uuid.UUID, viewerID *uuid.UUID) (map[uuid.UUID][]*models.CommentRevision, error) {
+	if viewerID == nil {
+		return map[uuid.UUID][]*models.CommentRevision{}, nil
+	}
+
+	moderator, err := isModerator(ctx, s.usersRepo, *viewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !moderator {
+		comments, err := s.commentsRepo.GetByIDs(ctx, commentIDs)
+		if err != nil {
+			return nil, err
+		}
+
+		commentIDs = make([]uuid.UUID, 0, len(comments))
+		for _, comment := range comments {
+			if comment.UserID == *viewerID && comment.DeletedAt == nil {
+				commentIDs = append(commentIDs, comment.ID)
+			}
+		}
+
+		if len(commentIDs) == 0 {
+			return map[uuid.UUID][]*models.CommentRevision{}, nil
+		}
+	}
+
+	return s.commentsRepo.GetRevisionsByCommentIDs(ctx, commentIDs)
+}
+
+// GetCommentsSince возвращает до limit комментариев поста, добавленных после
+// курсора, в порядке добавления
+func (s *CommentsService) GetCommentsSince(ctx context.Context, postID uuid.UUID, since string, limit int32) ([]*models.Comment, error) {
+	cursor, err := pagination.DecodeCursor(since)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &pagination.Page{
+		Before:   cursor,
+		Limit:    limit,
+		Backward: true,
+	}
+
+	return s.commentsRepo.GetByPostID(ctx, postID, page)
+}
+
+func (s *CommentsService) GetReplies(ctx context.Context, req *dtos.GetRepliesRequest) (*dtos.CommentsConnection, error) {
+	page, err := pagination.NewPage(req.First, req.After, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	maxDepth := defaultMaxDepth
+	if req.MaxDepth != nil {
+		maxDepth = *req.MaxDepth
+	}
+
+	parent, err := s.commentsRepo.GetByID(ctx, req.CommentID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	replies, err := s.commentsRepo.GetRepliesByParentID(ctx, parent.ID, page)
+	if err != nil {
+		return nil, err
+	}
+
+	replies, pageInfo := buildPage(replies, page, func(c *models.Comment) string {
+		return pagination.EncodeCursor(c.CreatedAt, c.ID)
+	})
+
+	nodes := make([]*dtos.CommentWithReplies, len(replies))
+	edges := make([]*dtos.CommentEdge, len(replies))
+	for i, r := range replies {
+		nodes[i] = &dtos.CommentWithReplies{
+			Comment:        *r,
+			RemainingDepth: maxDepth - 1,
+		}
+		edges[i] = &dtos.CommentEdge{
+			Cursor: pagination.EncodeCursor(r.CreatedAt, r.ID),
+			Node:   nodes[i],
+		}
+	}
+
+	err = loadReplies(ctx, s.commentsRepo, nodes)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dtos.CommentsConnection{
+		Edges:    edges,
+		PageInfo: pageInfo,
+	}, nil
+}
+
+func (s *CommentsService) CountByUserIDs(ctx context.Context, userIDs []uuid.UUID) (map[uuid.UUID]int32, error) {
+	return s.commentsRepo.CountByUserIDs(ctx, userIDs)
+}
+
+func (s *CommentsService) GetReactions(ctx context.Context, commentIDs []uuid.UUID, viewerID *uuid.UUID) (map[uuid.UUID][]*models.ReactionCount, error) {
+	return s.commentsRepo.GetReactions(ctx, commentIDs, viewerID)
+}
+
+// loadReplies достраивает дерево ответов уровень за уровнем: на каждом уровне
+// для комментария загружается не более DefaultRepliesPageSize последних ответов,
+// пока не будет исчерпан его RemainingDepth. Количество ответов считается и для
+// комментариев последнего уровня, чтобы клиент мог предложить загрузить остальные
+func loadReplies(ctx context.Context, commentsRepo repositories.CommentsRepository, level []*dtos.CommentWithReplies) error {
+	for len(level) > 0 {
+		parentIDs := make([]uuid.UUID, len(level))
+		for i, c := range level {
+			parentIDs[i] = c.ID
+		}
+
+		counts, err := commentsRepo.CountRepliesByParentIDs(ctx, parentIDs)
+		if err != nil {
+			return err
+		}
+
+		parents := make(map[uuid.UUID]*dtos.CommentWithReplies)
+		expandIDs := []uuid.UUID{}
+
+		for _, c := range level {
+			c.ReplyCount = counts[c.ID]
+			c.Replies = dtos.CommentsConnection{
+				Edges: []*dtos.CommentEdge{},
+				PageInfo: dtos.PageInfo{
+					HasNextPage: c.ReplyCount > 0,
+				},
+			}
+
+			if c.ReplyCount > 0 && c.RemainingDepth > 0 {
+				parents[c.ID] = c
+				expandIDs = append(expandIDs, c.ID)
+			}
+		}
+
+		if len(expandIDs) == 0 {
+			return nil
+		}
+
+		replies, err := commentsRepo.GetRepliesByParentIDs(ctx, expandIDs, pagination.DefaultRepliesPageSize)
+		if err != nil {
+			return err
+		}
+
+		nextLevel := make([]*dtos.CommentWithReplies, 0, len(replies))
+
+		for _, r := range replies {
+			parent, ok := parents[*r.ReplyTo]
+			if !ok {
+				continue
+			}
+
+			node := &dtos.CommentWithReplies{
+				Comment:        *r,
+				RemainingDepth: parent.RemainingDepth - 1,
+			}
+			parent.Replies.Edges = append(parent.Replies.Edges, &dtos.CommentEdge{
+				Cursor: pagination.EncodeCursor(r.CreatedAt, r.ID),
+				Node:   node,
+			})
+			nextLevel = append(nextLevel, node)
+		}
+
+		for _, parent := range parents {
+			edges := parent.Replies.Edges
+			parent.Replies.PageInfo.HasNextPage = parent.ReplyCount > int32(len(edges))
+			if len(edges) > 0 {
+				parent.Replies.PageInfo.StartCursor = &edges[0].Cursor
+				parent.Replies.PageInfo.EndCursor = &edges[len(edges)-1].Cursor
+			}
+		}
+
+		level = nextLevel
+	}
+
+	return nil
 }
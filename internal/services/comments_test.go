@@ -6,8 +6,11 @@ import (
 	"testing"
 	"time"
 
+	"github.com/Govorov1705/ozon-test/config"
 	"github.com/Govorov1705/ozon-test/internal/dtos"
+	"github.com/Govorov1705/ozon-test/internal/errs"
 	"github.com/Govorov1705/ozon-test/internal/models"
+	"github.com/Govorov1705/ozon-test/internal/pagination"
 	"github.com/Govorov1705/ozon-test/internal/repositories/mocks"
 	"github.com/Govorov1705/ozon-test/internal/services"
 	txMocks "github.com/Govorov1705/ozon-test/internal/transactions/mocks"
@@ -24,6 +27,7 @@ func TestCommentsService_CreateComment(t *testing.T) {
 			ts *txMocks.MockTxStarter,
 			pr *mocks.MockPostsRepository,
 			cr *mocks.MockCommentsRepository,
+			or *mocks.MockOutboxRepository,
 		)
 		expectError bool
 	}
@@ -47,6 +51,7 @@ func TestCommentsService_CreateComment(t *testing.T) {
 				ts *txMocks.MockTxStarter,
 				pr *mocks.MockPostsRepository,
 				cr *mocks.MockCommentsRepository,
+				or *mocks.MockOutboxRepository,
 			) {
 				mockTx := &txMocks.MockTx{}
 
@@ -90,6 +95,13 @@ func TestCommentsService_CreateComment(t *testing.T) {
 						CreatedAt: time.Now(),
 					}, nil,
 				)
+				or.On(
+					"Add",
+					mock.Anything,
+					mock.AnythingOfType("uuid.UUID"),
+					models.EventCommentAdded,
+					mock.AnythingOfType("[]uint8"),
+				).Return(nil)
 			},
 			expectError: false,
 		},
@@ -105,6 +117,7 @@ func TestCommentsService_CreateComment(t *testing.T) {
 				ts *txMocks.MockTxStarter,
 				pr *mocks.MockPostsRepository,
 				cr *mocks.MockCommentsRepository,
+				or *mocks.MockOutboxRepository,
 			) {
 				mockTx := &txMocks.MockTx{}
 
@@ -163,6 +176,13 @@ func TestCommentsService_CreateComment(t *testing.T) {
 						CreatedAt: time.Now(),
 					}, nil,
 				)
+				or.On(
+					"Add",
+					mock.Anything,
+					mock.AnythingOfType("uuid.UUID"),
+					models.EventCommentAdded,
+					mock.AnythingOfType("[]uint8"),
+				).Return(nil)
 			},
 			expectError: false,
 		},
@@ -178,6 +198,7 @@ func TestCommentsService_CreateComment(t *testing.T) {
 				ts *txMocks.MockTxStarter,
 				pr *mocks.MockPostsRepository,
 				cr *mocks.MockCommentsRepository,
+				or *mocks.MockOutboxRepository,
 			) {
 				ts.On("Begin", mock.Anything).Return(nil, errors.New("some error"))
 			},
@@ -195,6 +216,7 @@ func TestCommentsService_CreateComment(t *testing.T) {
 				ts *txMocks.MockTxStarter,
 				pr *mocks.MockPostsRepository,
 				cr *mocks.MockCommentsRepository,
+				or *mocks.MockOutboxRepository,
 			) {
 				mockTx := &txMocks.MockTx{}
 
@@ -225,6 +247,7 @@ func TestCommentsService_CreateComment(t *testing.T) {
 				ts *txMocks.MockTxStarter,
 				pr *mocks.MockPostsRepository,
 				cr *mocks.MockCommentsRepository,
+				or *mocks.MockOutboxRepository,
 			) {
 				mockTx := &txMocks.MockTx{}
 
@@ -262,6 +285,7 @@ func TestCommentsService_CreateComment(t *testing.T) {
 				ts *txMocks.MockTxStarter,
 				pr *mocks.MockPostsRepository,
 				cr *mocks.MockCommentsRepository,
+				or *mocks.MockOutboxRepository,
 			) {
 				mockTx := &txMocks.MockTx{}
 
@@ -310,6 +334,7 @@ func TestCommentsService_CreateComment(t *testing.T) {
 				ts *txMocks.MockTxStarter,
 				pr *mocks.MockPostsRepository,
 				cr *mocks.MockCommentsRepository,
+				or *mocks.MockOutboxRepository,
 			) {
 				mockTx := &txMocks.MockTx{}
 
@@ -356,6 +381,7 @@ func TestCommentsService_CreateComment(t *testing.T) {
 				ts *txMocks.MockTxStarter,
 				pr *mocks.MockPostsRepository,
 				cr *mocks.MockCommentsRepository,
+				or *mocks.MockOutboxRepository,
 			) {
 				mockTx := &txMocks.MockTx{}
 
@@ -410,6 +436,7 @@ func TestCommentsService_CreateComment(t *testing.T) {
 				ts *txMocks.MockTxStarter,
 				pr *mocks.MockPostsRepository,
 				cr *mocks.MockCommentsRepository,
+				or *mocks.MockOutboxRepository,
 			) {
 				mockTx := &txMocks.MockTx{}
 
@@ -463,6 +490,73 @@ func TestCommentsService_CreateComment(t *testing.T) {
 			},
 			expectError: true,
 		},
+		{
+			name: "outboxRepo.Add error",
+			input: &dtos.CreateCommentRequest{
+				PostID:  postID,
+				UserID:  userID,
+				ReplyTo: nil,
+				Content: content,
+			},
+			setupMocks: func(
+				ts *txMocks.MockTxStarter,
+				pr *mocks.MockPostsRepository,
+				cr *mocks.MockCommentsRepository,
+				or *mocks.MockOutboxRepository,
+			) {
+				mockTx := &txMocks.MockTx{}
+
+				ts.On("Begin", mock.Anything).Return(mockTx, nil)
+
+				mockTx.On("Rollback", mock.Anything).Return(nil)
+
+				pr.On(
+					"GetByID",
+					mock.Anything,
+					postID,
+					true,
+				).Return(
+					&models.Post{
+						ID:                 postID,
+						UserID:             uuid.New(),
+						Title:              "Test title",
+						Content:            "Test content",
+						CreatedAt:          time.Now(),
+						AreCommentsAllowed: true,
+					}, nil,
+				)
+
+				commentID := uuid.New()
+
+				cr.On(
+					"Add",
+					mock.Anything,
+					postID, userID,
+					mock.AnythingOfType("*uuid.UUID"),
+					mock.AnythingOfType("*uuid.UUID"),
+					content,
+				).Return(
+					&models.Comment{
+						ID:        commentID,
+						PostID:    postID,
+						UserID:    userID,
+						RootID:    commentID,
+						ReplyTo:   nil,
+						Content:   content,
+						CreatedAt: time.Now(),
+					}, nil,
+				)
+
+				or.On(
+					"Add",
+					mock.Anything,
+					mock.AnythingOfType("uuid.UUID"),
+					models.EventCommentAdded,
+					mock.AnythingOfType("[]uint8"),
+				).Return(errors.New("some error"))
+			},
+			expectError: true,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -470,17 +564,22 @@ func TestCommentsService_CreateComment(t *testing.T) {
 			mockTxStarter := txMocks.NewMockTxStarter(t)
 			mockPostsRepo := mocks.NewMockPostsRepository(t)
 			mockCommentsRepo := mocks.NewMockCommentsRepository(t)
+			mockUsersRepo := mocks.NewMockUsersRepository(t)
+			mockOutboxRepo := mocks.NewMockOutboxRepository(t)
 
 			tc.setupMocks(
 				mockTxStarter,
 				mockPostsRepo,
 				mockCommentsRepo,
+				mockOutboxRepo,
 			)
 
 			commentsService := services.NewCommentsService(
 				mockTxStarter,
 				mockCommentsRepo,
 				mockPostsRepo,
+				mockUsersRepo,
+				mockOutboxRepo,
 			)
 			comment, err := commentsService.CreateComment(context.Background(), tc.input)
 
@@ -495,6 +594,1256 @@ func TestCommentsService_CreateComment(t *testing.T) {
 			mockTxStarter.AssertExpectations(t)
 			mockPostsRepo.AssertExpectations(t)
 			mockCommentsRepo.AssertExpectations(t)
+			mockOutboxRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCommentsService_GetReplies(t *testing.T) {
+	type testCase struct {
+		name       string
+		input      *dtos.GetRepliesRequest
+		setupMocks func(
+			ts *txMocks.MockTxStarter,
+			pr *mocks.MockPostsRepository,
+			cr *mocks.MockCommentsRepository,
+		)
+		expectError         bool
+		expectedEdges       int
+		expectedHasNextPage bool
+	}
+
+	postID := uuid.New()
+	commentID := uuid.New()
+	replyID1 := uuid.New()
+	replyID2 := uuid.New()
+
+	parent := &models.Comment{
+		ID:        commentID,
+		PostID:    postID,
+		UserID:    uuid.New(),
+		RootID:    commentID,
+		Content:   "Parent comment",
+		CreatedAt: time.Now(),
+	}
+
+	replies := []*models.Comment{
+		{
+			ID:        replyID1,
+			PostID:    postID,
+			UserID:    uuid.New(),
+			RootID:    commentID,
+			ReplyTo:   &commentID,
+			Content:   "Reply 1",
+			CreatedAt: time.Now(),
+		},
+		{
+			ID:        replyID2,
+			PostID:    postID,
+			UserID:    uuid.New(),
+			RootID:    commentID,
+			ReplyTo:   &commentID,
+			Content:   "Reply 2",
+			CreatedAt: time.Now(),
+		},
+	}
+
+	int32Ptr := func(i int32) *int32 { return &i }
+	invalidCursor := "invalid"
+
+	testCases := []testCase{
+		{
+			name: "OK",
+			input: &dtos.GetRepliesRequest{
+				CommentID: commentID,
+				First:     int32Ptr(10),
+				MaxDepth:  int32Ptr(1),
+			},
+			setupMocks: func(
+				ts *txMocks.MockTxStarter,
+				pr *mocks.MockPostsRepository,
+				cr *mocks.MockCommentsRepository,
+			) {
+				cr.On("GetByID", mock.Anything, commentID, false).Return(parent, nil)
+
+				cr.On("GetRepliesByParentID", mock.Anything, commentID, &pagination.Page{Limit: 11}).Return(
+					replies, nil,
+				)
+
+				cr.On("CountRepliesByParentIDs", mock.Anything, []uuid.UUID{replyID1, replyID2}).Return(
+					map[uuid.UUID]int32{replyID1: 3, replyID2: 0}, nil,
+				)
+			},
+			expectError:         false,
+			expectedEdges:       2,
+			expectedHasNextPage: false,
+		},
+		{
+			name: "OK (more replies than requested)",
+			input: &dtos.GetRepliesRequest{
+				CommentID: commentID,
+				First:     int32Ptr(1),
+				MaxDepth:  int32Ptr(1),
+			},
+			setupMocks: func(
+				ts *txMocks.MockTxStarter,
+				pr *mocks.MockPostsRepository,
+				cr *mocks.MockCommentsRepository,
+			) {
+				cr.On("GetByID", mock.Anything, commentID, false).Return(parent, nil)
+
+				cr.On("GetRepliesByParentID", mock.Anything, commentID, &pagination.Page{Limit: 2}).Return(
+					replies, nil,
+				)
+
+				cr.On("CountRepliesByParentIDs", mock.Anything, []uuid.UUID{replyID1}).Return(
+					map[uuid.UUID]int32{replyID1: 3}, nil,
+				)
+			},
+			expectError:         false,
+			expectedEdges:       1,
+			expectedHasNextPage: true,
+		},
+		{
+			name: "invalid cursor",
+			input: &dtos.GetRepliesRequest{
+				CommentID: commentID,
+				After:     &invalidCursor,
+			},
+			setupMocks: func(
+				ts *txMocks.MockTxStarter,
+				pr *mocks.MockPostsRepository,
+				cr *mocks.MockCommentsRepository,
+			) {
+			},
+			expectError: true,
+		},
+		{
+			name: "commentsRepo.GetByID error",
+			input: &dtos.GetRepliesRequest{
+				CommentID: commentID,
+			},
+			setupMocks: func(
+				ts *txMocks.MockTxStarter,
+				pr *mocks.MockPostsRepository,
+				cr *mocks.MockCommentsRepository,
+			) {
+				cr.On("GetByID", mock.Anything, commentID, false).Return(
+					nil, errors.New("some error"),
+				)
+			},
+			expectError: true,
+		},
+		{
+			name: "commentsRepo.GetRepliesByParentID error",
+			input: &dtos.GetRepliesRequest{
+				CommentID: commentID,
+			},
+			setupMocks: func(
+				ts *txMocks.MockTxStarter,
+				pr *mocks.MockPostsRepository,
+				cr *mocks.MockCommentsRepository,
+			) {
+				cr.On("GetByID", mock.Anything, commentID, false).Return(parent, nil)
+
+				cr.On("GetRepliesByParentID", mock.Anything, commentID, mock.Anything).Return(
+					nil, errors.New("some error"),
+				)
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockTxStarter := txMocks.NewMockTxStarter(t)
+			mockPostsRepo := mocks.NewMockPostsRepository(t)
+			mockCommentsRepo := mocks.NewMockCommentsRepository(t)
+			mockUsersRepo := mocks.NewMockUsersRepository(t)
+			mockOutboxRepo := mocks.NewMockOutboxRepository(t)
+
+			tc.setupMocks(
+				mockTxStarter,
+				mockPostsRepo,
+				mockCommentsRepo,
+			)
+
+			commentsService := services.NewCommentsService(
+				mockTxStarter,
+				mockCommentsRepo,
+				mockPostsRepo,
+				mockUsersRepo,
+				mockOutboxRepo,
+			)
+			repliesConnection, err := commentsService.GetReplies(context.Background(), tc.input)
+
+			if tc.expectError {
+				assert.Error(t, err)
+				assert.Nil(t, repliesConnection)
+			} else {
+				assert.NoError(t, err)
+				assert.Len(t, repliesConnection.Edges, tc.expectedEdges)
+				assert.Equal(t, tc.expectedHasNextPage, repliesConnection.PageInfo.HasNextPage)
+			}
+
+			mockTxStarter.AssertExpectations(t)
+			mockPostsRepo.AssertExpectations(t)
+			mockCommentsRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCommentsService_GetCommentsSince(t *testing.T) {
+	type testCase struct {
+		name          string
+		since         string
+		setupMocks    func(cr *mocks.MockCommentsRepository)
+		expectedError error
+		expectedLen   int
+	}
+
+	postID := uuid.New()
+	sinceID := uuid.New()
+	sinceCreatedAt := time.Now().Add(-time.Minute).UTC()
+	since := pagination.EncodeCursor(sinceCreatedAt, sinceID)
+
+	comments := []*models.Comment{
+		{
+			ID:        uuid.New(),
+			PostID:    postID,
+			UserID:    uuid.New(),
+			Content:   "Comment 1",
+			CreatedAt: time.Now(),
+		},
+	}
+
+	testCases := []testCase{
+		{
+			name:  "OK",
+			since: since,
+			setupMocks: func(cr *mocks.MockCommentsRepository) {
+				cr.On(
+					"GetByPostID",
+					mock.Anything,
+					postID,
+					mock.MatchedBy(func(page *pagination.Page) bool {
+						return page.Backward &&
+							page.After == nil &&
+							page.Before.ID == sinceID &&
+							page.Before.CreatedAt.Equal(sinceCreatedAt) &&
+							page.Limit == 50
+					}),
+				).Return(comments, nil)
+			},
+			expectedLen: 1,
+		},
+		{
+			name:          "invalid cursor",
+			since:         "invalid",
+			setupMocks:    func(cr *mocks.MockCommentsRepository) {},
+			expectedError: errs.ErrInvalidCursor,
+		},
+		{
+			name:  "commentsRepo.GetByPostID error",
+			since: since,
+			setupMocks: func(cr *mocks.MockCommentsRepository) {
+				cr.On("GetByPostID", mock.Anything, postID, mock.Anything).Return(
+					nil, errs.ErrInternal,
+				)
+			},
+			expectedError: errs.ErrInternal,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockTxStarter := txMocks.NewMockTxStarter(t)
+			mockPostsRepo := mocks.NewMockPostsRepository(t)
+			mockCommentsRepo := mocks.NewMockCommentsRepository(t)
+			mockUsersRepo := mocks.NewMockUsersRepository(t)
+			mockOutboxRepo := mocks.NewMockOutboxRepository(t)
+
+			tc.setupMocks(mockCommentsRepo)
+
+			commentsService := services.NewCommentsService(
+				mockTxStarter,
+				mockCommentsRepo,
+				mockPostsRepo,
+				mockUsersRepo,
+				mockOutboxRepo,
+			)
+			result, err := commentsService.GetCommentsSince(context.Background(), postID, tc.since, 50)
+
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+				assert.Nil(t, result)
+			} else {
+				assert.NoError(t, err)
+				assert.Len(t, result, tc.expectedLen)
+			}
+
+			mockCommentsRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCommentsService_EditComment(t *testing.T) {
+	type testCase struct {
+		name       string
+		input      *dtos.EditCommentRequest
+		setupMocks func(
+			ts *txMocks.MockTxStarter,
+			pr *mocks.MockPostsRepository,
+			cr *mocks.MockCommentsRepository,
+			or *mocks.MockOutboxRepository,
+		)
+		expectedError error
+	}
+
+	commentID := uuid.New()
+	userID := uuid.New()
+	oldContent := "Old content"
+	newContent := "New content"
+
+	comment := func() *models.Comment {
+		return &models.Comment{
+			ID:      commentID,
+			UserID:  userID,
+			Content: oldContent,
+		}
+	}
+
+	testCases := []testCase{
+		{
+			name: "OK",
+			input: &dtos.EditCommentRequest{
+				CommentID: commentID,
+				UserID:    userID,
+				Content:   newContent,
+			},
+			setupMocks: func(
+				ts *txMocks.MockTxStarter,
+				pr *mocks.MockPostsRepository,
+				cr *mocks.MockCommentsRepository,
+				or *mocks.MockOutboxRepository,
+			) {
+				mockTx := &txMocks.MockTx{}
+
+				ts.On("Begin", mock.Anything).Return(mockTx, nil)
+
+				mockTx.On("Commit", mock.Anything).Return(nil)
+
+				cr.On("GetByID", mock.Anything, commentID, true).Return(comment(), nil)
+				cr.On("AddRevision", mock.Anything, commentID, oldContent).Return(
+					&models.CommentRevision{
+						ID:        uuid.New(),
+						CommentID: commentID,
+						Content:   oldContent,
+					}, nil,
+				)
+
+				editedAt := time.Now()
+				cr.On("UpdateContent", mock.Anything, commentID, newContent).Return(
+					&models.Comment{
+						ID:       commentID,
+						UserID:   userID,
+						Content:  newContent,
+						EditedAt: &editedAt,
+					}, nil,
+				)
+				or.On(
+					"Add",
+					mock.Anything,
+					mock.AnythingOfType("uuid.UUID"),
+					models.EventCommentUpdated,
+					mock.AnythingOfType("[]uint8"),
+				).Return(nil)
+			},
+			expectedError: nil,
+		},
+		{
+			name: "unchanged content",
+			input: &dtos.EditCommentRequest{
+				CommentID: commentID,
+				UserID:    userID,
+				Content:   oldContent,
+			},
+			setupMocks: func(
+				ts *txMocks.MockTxStarter,
+				pr *mocks.MockPostsRepository,
+				cr *mocks.MockCommentsRepository,
+				or *mocks.MockOutboxRepository,
+			) {
+				mockTx := &txMocks.MockTx{}
+
+				ts.On("Begin", mock.Anything).Return(mockTx, nil)
+
+				mockTx.On("Commit", mock.Anything).Return(nil)
+
+				cr.On("GetByID", mock.Anything, commentID, true).Return(comment(), nil)
+			},
+			expectedError: nil,
+		},
+		{
+			name: "not the author",
+			input: &dtos.EditCommentRequest{
+				CommentID: commentID,
+				UserID:    uuid.New(),
+				Content:   newContent,
+			},
+			setupMocks: func(
+				ts *txMocks.MockTxStarter,
+				pr *mocks.MockPostsRepository,
+				cr *mocks.MockCommentsRepository,
+				or *mocks.MockOutboxRepository,
+			) {
+				mockTx := &txMocks.MockTx{}
+
+				ts.On("Begin", mock.Anything).Return(mockTx, nil)
+
+				mockTx.On("Rollback", mock.Anything).Return(nil)
+
+				cr.On("GetByID", mock.Anything, commentID, true).Return(comment(), nil)
+			},
+			expectedError: errs.ErrUnauthorized,
+		},
+		{
+			name: "commentsRepo.GetByID error",
+			input: &dtos.EditCommentRequest{
+				CommentID: commentID,
+				UserID:    userID,
+				Content:   newContent,
+			},
+			setupMocks: func(
+				ts *txMocks.MockTxStarter,
+				pr *mocks.MockPostsRepository,
+				cr *mocks.MockCommentsRepository,
+				or *mocks.MockOutboxRepository,
+			) {
+				mockTx := &txMocks.MockTx{}
+
+				ts.On("Begin", mock.Anything).Return(mockTx, nil)
+
+				mockTx.On("Rollback", mock.Anything).Return(nil)
+
+				cr.On("GetByID", mock.Anything, commentID, true).Return(nil, errs.ErrNotFound)
+			},
+			expectedError: errs.ErrNotFound,
+		},
+		{
+			name: "commentsRepo.AddRevision error",
+			input: &dtos.EditCommentRequest{
+				CommentID: commentID,
+				UserID:    userID,
+				Content:   newContent,
+			},
+			setupMocks: func(
+				ts *txMocks.MockTxStarter,
+				pr *mocks.MockPostsRepository,
+				cr *mocks.MockCommentsRepository,
+				or *mocks.MockOutboxRepository,
+			) {
+				mockTx := &txMocks.MockTx{}
+
+				ts.On("Begin", mock.Anything).Return(mockTx, nil)
+
+				mockTx.On("Rollback", mock.Anything).Return(nil)
+
+				cr.On("GetByID", mock.Anything, commentID, true).Return(comment(), nil)
+				cr.On("AddRevision", mock.Anything, commentID, oldContent).Return(nil, errs.ErrInternal)
+			},
+			expectedError: errs.ErrInternal,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockTxStarter := txMocks.NewMockTxStarter(t)
+			mockPostsRepo := mocks.NewMockPostsRepository(t)
+			mockCommentsRepo := mocks.NewMockCommentsRepository(t)
+			mockUsersRepo := mocks.NewMockUsersRepository(t)
+			mockOutboxRepo := mocks.NewMockOutboxRepository(t)
+
+			tc.setupMocks(
+				mockTxStarter,
+				mockPostsRepo,
+				mockCommentsRepo,
+				mockOutboxRepo,
+			)
+
+			commentsService := services.NewCommentsService(
+				mockTxStarter,
+				mockCommentsRepo,
+				mockPostsRepo,
+				mockUsersRepo,
+				mockOutboxRepo,
+			)
+			comment, err := commentsService.EditComment(context.Background(), tc.input)
+
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+				assert.Nil(t, comment)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.input.Content, comment.Content)
+			}
+
+			mockTxStarter.AssertExpectations(t)
+			mockPostsRepo.AssertExpectations(t)
+			mockCommentsRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCommentsService_DeleteComment(t *testing.T) {
+	config.Cfg.Moderators = []string{"moderator"}
+	defer func() { config.Cfg.Moderators = nil }()
+
+	type testCase struct {
+		name       string
+		input      *dtos.DeleteCommentRequest
+		setupMocks func(
+			ts *txMocks.MockTxStarter,
+			cr *mocks.MockCommentsRepository,
+			ur *mocks.MockUsersRepository,
+			or *mocks.MockOutboxRepository,
+		)
+		expectedError error
+	}
+
+	commentID := uuid.New()
+	authorID := uuid.New()
+	moderatorID := uuid.New()
+	otherID := uuid.New()
+	deletedAt := time.Now()
+
+	comment := func() *models.Comment {
+		return &models.Comment{
+			ID:      commentID,
+			UserID:  authorID,
+			Content: "Test comment",
+		}
+	}
+
+	testCases := []testCase{
+		{
+			name: "OK by author",
+			input: &dtos.DeleteCommentRequest{
+				CommentID: commentID,
+				UserID:    authorID,
+			},
+			setupMocks: func(
+				ts *txMocks.MockTxStarter,
+				cr *mocks.MockCommentsRepository,
+				ur *mocks.MockUsersRepository,
+				or *mocks.MockOutboxRepository,
+			) {
+				mockTx := &txMocks.MockTx{}
+
+				ts.On("Begin", mock.Anything).Return(mockTx, nil)
+
+				mockTx.On("Commit", mock.Anything).Return(nil)
+
+				cr.On("GetByID", mock.Anything, commentID, true).Return(comment(), nil)
+				cr.On("AddRevision", mock.Anything, commentID, "Test comment").Return(
+					&models.CommentRevision{CommentID: commentID, Content: "Test comment"}, nil,
+				)
+				cr.On("SoftDelete", mock.Anything, commentID).Return(
+					&models.Comment{
+						ID:        commentID,
+						UserID:    authorID,
+						DeletedAt: &deletedAt,
+					}, nil,
+				)
+				or.On(
+					"Add",
+					mock.Anything,
+					mock.AnythingOfType("uuid.UUID"),
+					models.EventCommentDeleted,
+					mock.AnythingOfType("[]uint8"),
+				).Return(nil)
+			},
+			expectedError: nil,
+		},
+		{
+			name: "OK by moderator",
+			input: &dtos.DeleteCommentRequest{
+				CommentID: commentID,
+				UserID:    moderatorID,
+			},
+			setupMocks: func(
+				ts *txMocks.MockTxStarter,
+				cr *mocks.MockCommentsRepository,
+				ur *mocks.MockUsersRepository,
+				or *mocks.MockOutboxRepository,
+			) {
+				mockTx := &txMocks.MockTx{}
+
+				ts.On("Begin", mock.Anything).Return(mockTx, nil)
+
+				mockTx.On("Commit", mock.Anything).Return(nil)
+
+				cr.On("GetByID", mock.Anything, commentID, true).Return(comment(), nil)
+				ur.On("GetByID", mock.Anything, moderatorID).Return(
+					&models.User{ID: moderatorID, Username: "moderator"}, nil,
+				)
+				cr.On("AddRevision", mock.Anything, commentID, "Test comment").Return(
+					&models.CommentRevision{CommentID: commentID, Content: "Test comment"}, nil,
+				)
+				cr.On("SoftDelete", mock.Anything, commentID).Return(
+					&models.Comment{
+						ID:        commentID,
+						UserID:    authorID,
+						DeletedAt: &deletedAt,
+					}, nil,
+				)
+				or.On(
+					"Add",
+					mock.Anything,
+					mock.AnythingOfType("uuid.UUID"),
+					models.EventCommentDeleted,
+					mock.AnythingOfType("[]uint8"),
+				).Return(nil)
+			},
+			expectedError: nil,
+		},
+		{
+			name: "already deleted",
+			input: &dtos.DeleteCommentRequest{
+				CommentID: commentID,
+				UserID:    authorID,
+			},
+			setupMocks: func(
+				ts *txMocks.MockTxStarter,
+				cr *mocks.MockCommentsRepository,
+				ur *mocks.MockUsersRepository,
+				or *mocks.MockOutboxRepository,
+			) {
+				mockTx := &txMocks.MockTx{}
+
+				ts.On("Begin", mock.Anything).Return(mockTx, nil)
+
+				mockTx.On("Commit", mock.Anything).Return(nil)
+
+				cr.On("GetByID", mock.Anything, commentID, true).Return(
+					&models.Comment{
+						ID:        commentID,
+						UserID:    authorID,
+						DeletedAt: &deletedAt,
+					}, nil,
+				)
+			},
+			expectedError: nil,
+		},
+		{
+			name: "neither author nor moderator",
+			input: &dtos.DeleteCommentRequest{
+				CommentID: commentID,
+				UserID:    otherID,
+			},
+			setupMocks: func(
+				ts *txMocks.MockTxStarter,
+				cr *mocks.MockCommentsRepository,
+				ur *mocks.MockUsersRepository,
+				or *mocks.MockOutboxRepository,
+			) {
+				mockTx := &txMocks.MockTx{}
+
+				ts.On("Begin", mock.Anything).Return(mockTx, nil)
+
+				mockTx.On("Rollback", mock.Anything).Return(nil)
+
+				cr.On("GetByID", mock.Anything, commentID, true).Return(comment(), nil)
+				ur.On("GetByID", mock.Anything, otherID).Return(
+					&models.User{ID: otherID, Username: "someone"}, nil,
+				)
+			},
+			expectedError: errs.ErrUnauthorized,
+		},
+		{
+			name: "commentsRepo.AddRevision error",
+			input: &dtos.DeleteCommentRequest{
+				CommentID: commentID,
+				UserID:    authorID,
+			},
+			setupMocks: func(
+				ts *txMocks.MockTxStarter,
+				cr *mocks.MockCommentsRepository,
+				ur *mocks.MockUsersRepository,
+				or *mocks.MockOutboxRepository,
+			) {
+				mockTx := &txMocks.MockTx{}
+
+				ts.On("Begin", mock.Anything).Return(mockTx, nil)
+
+				mockTx.On("Rollback", mock.Anything).Return(nil)
+
+				cr.On("GetByID", mock.Anything, commentID, true).Return(comment(), nil)
+				cr.On("AddRevision", mock.Anything, commentID, "Test comment").Return(nil, errs.ErrInternal)
+			},
+			expectedError: errs.ErrInternal,
+		},
+		{
+			name: "commentsRepo.GetByID error",
+			input: &dtos.DeleteCommentRequest{
+				CommentID: commentID,
+				UserID:    authorID,
+			},
+			setupMocks: func(
+				ts *txMocks.MockTxStarter,
+				cr *mocks.MockCommentsRepository,
+				ur *mocks.MockUsersRepository,
+				or *mocks.MockOutboxRepository,
+			) {
+				mockTx := &txMocks.MockTx{}
+
+				ts.On("Begin", mock.Anything).Return(mockTx, nil)
+
+				mockTx.On("Rollback", mock.Anything).Return(nil)
+
+				cr.On("GetByID", mock.Anything, commentID, true).Return(nil, errs.ErrNotFound)
+			},
+			expectedError: errs.ErrNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockTxStarter := txMocks.NewMockTxStarter(t)
+			mockPostsRepo := mocks.NewMockPostsRepository(t)
+			mockCommentsRepo := mocks.NewMockCommentsRepository(t)
+			mockUsersRepo := mocks.NewMockUsersRepository(t)
+			mockOutboxRepo := mocks.NewMockOutboxRepository(t)
+
+			tc.setupMocks(
+				mockTxStarter,
+				mockCommentsRepo,
+				mockUsersRepo,
+				mockOutboxRepo,
+			)
+
+			commentsService := services.NewCommentsService(
+				mockTxStarter,
+				mockCommentsRepo,
+				mockPostsRepo,
+				mockUsersRepo,
+				mockOutboxRepo,
+			)
+			comment, err := commentsService.DeleteComment(context.Background(), tc.input)
+
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+				assert.Nil(t, comment)
+			} else {
+				assert.NoError(t, err)
+				assert.NotNil(t, comment.DeletedAt)
+			}
+
+			mockTxStarter.AssertExpectations(t)
+			mockCommentsRepo.AssertExpectations(t)
+			mockUsersRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCommentsService_HardDeleteComment(t *testing.T) {
+	config.Cfg.Moderators = []string{"moderator"}
+	defer func() { config.Cfg.Moderators = nil }()
+
+	type testCase struct {
+		name       string
+		input      *dtos.DeleteCommentRequest
+		setupMocks func(
+			ts *txMocks.MockTxStarter,
+			cr *mocks.MockCommentsRepository,
+			ur *mocks.MockUsersRepository,
+			or *mocks.MockOutboxRepository,
+		)
+		expectedRemoved bool
+		expectedError   error
+	}
+
+	moderatorID := uuid.New()
+	otherID := uuid.New()
+	rootID := uuid.New()
+	parentID := uuid.New()
+	commentID := uuid.New()
+	deletedAt := time.Now()
+
+	expectModerator := func(ur *mocks.MockUsersRepository) {
+		ur.On("GetByID", mock.Anything, moderatorID).Return(
+			&models.User{ID: moderatorID, Username: "moderator"}, nil,
+		)
+	}
+
+	deletedComment := &models.Comment{
+		ID:        commentID,
+		RootID:    rootID,
+		ReplyTo:   &parentID,
+		DeletedAt: &deletedAt,
+	}
+
+	testCases := []testCase{
+		{
+			name: "prunes deleted ancestors",
+			input: &dtos.DeleteCommentRequest{
+				CommentID: commentID,
+				UserID:    moderatorID,
+			},
+			setupMocks: func(
+				ts *txMocks.MockTxStarter,
+				cr *mocks.MockCommentsRepository,
+				ur *mocks.MockUsersRepository,
+				or *mocks.MockOutboxRepository,
+			) {
+				mockTx := &txMocks.MockTx{}
+
+				ts.On("Begin", mock.Anything).Return(mockTx, nil)
+
+				mockTx.On("Commit", mock.Anything).Return(nil)
+
+				expectModerator(ur)
+				cr.On("GetByID", mock.Anything, commentID, true).Return(
+					&models.Comment{
+						ID:      commentID,
+						RootID:  rootID,
+						ReplyTo: &parentID,
+						Content: "Test comment",
+					}, nil,
+				)
+				cr.On("AddRevision", mock.Anything, commentID, "Test comment").Return(
+					&models.CommentRevision{CommentID: commentID, Content: "Test comment"}, nil,
+				)
+				cr.On("SoftDelete", mock.Anything, commentID).Return(deletedComment, nil)
+				cr.On("HasUndeletedReplies", mock.Anything, commentID).Return(false, nil)
+				cr.On("Delete", mock.Anything, commentID).Return(nil)
+				cr.On("GetByID", mock.Anything, parentID, true).Return(
+					&models.Comment{
+						ID:        parentID,
+						RootID:    rootID,
+						ReplyTo:   &rootID,
+						DeletedAt: &deletedAt,
+					}, nil,
+				)
+				cr.On("HasUndeletedReplies", mock.Anything, parentID).Return(false, nil)
+				cr.On("Delete", mock.Anything, parentID).Return(nil)
+				cr.On("GetByID", mock.Anything, rootID, true).Return(
+					&models.Comment{
+						ID:     rootID,
+						RootID: rootID,
+					}, nil,
+				)
+				or.On(
+					"Add",
+					mock.Anything,
+					mock.AnythingOfType("uuid.UUID"),
+					models.EventCommentDeleted,
+					mock.AnythingOfType("[]uint8"),
+				).Return(nil).Times(2)
+			},
+			expectedRemoved: true,
+			expectedError:   nil,
+		},
+		{
+			name: "keeps tombstone with undeleted replies",
+			input: &dtos.DeleteCommentRequest{
+				CommentID: commentID,
+				UserID:    moderatorID,
+			},
+			setupMocks: func(
+				ts *txMocks.MockTxStarter,
+				cr *mocks.MockCommentsRepository,
+				ur *mocks.MockUsersRepository,
+				or *mocks.MockOutboxRepository,
+			) {
+				mockTx := &txMocks.MockTx{}
+
+				ts.On("Begin", mock.Anything).Return(mockTx, nil)
+
+				mockTx.On("Commit", mock.Anything).Return(nil)
+
+				expectModerator(ur)
+				cr.On("GetByID", mock.Anything, commentID, true).Return(deletedComment, nil)
+				cr.On("HasUndeletedReplies", mock.Anything, commentID).Return(true, nil)
+			},
+			expectedRemoved: false,
+			expectedError:   nil,
+		},
+		{
+			name: "not a moderator",
+			input: &dtos.DeleteCommentRequest{
+				CommentID: commentID,
+				UserID:    otherID,
+			},
+			setupMocks: func(
+				ts *txMocks.MockTxStarter,
+				cr *mocks.MockCommentsRepository,
+				ur *mocks.MockUsersRepository,
+				or *mocks.MockOutboxRepository,
+			) {
+				mockTx := &txMocks.MockTx{}
+
+				ts.On("Begin", mock.Anything).Return(mockTx, nil)
+
+				mockTx.On("Rollback", mock.Anything).Return(nil)
+
+				ur.On("GetByID", mock.Anything, otherID).Return(
+					&models.User{ID: otherID, Username: "someone"}, nil,
+				)
+			},
+			expectedRemoved: false,
+			expectedError:   errs.ErrUnauthorized,
+		},
+		{
+			name: "commentsRepo.Delete error",
+			input: &dtos.DeleteCommentRequest{
+				CommentID: commentID,
+				UserID:    moderatorID,
+			},
+			setupMocks: func(
+				ts *txMocks.MockTxStarter,
+				cr *mocks.MockCommentsRepository,
+				ur *mocks.MockUsersRepository,
+				or *mocks.MockOutboxRepository,
+			) {
+				mockTx := &txMocks.MockTx{}
+
+				ts.On("Begin", mock.Anything).Return(mockTx, nil)
+
+				mockTx.On("Rollback", mock.Anything).Return(nil)
+
+				expectModerator(ur)
+				cr.On("GetByID", mock.Anything, commentID, true).Return(deletedComment, nil)
+				cr.On("HasUndeletedReplies", mock.Anything, commentID).Return(false, nil)
+				cr.On("Delete", mock.Anything, commentID).Return(errs.ErrInternal)
+			},
+			expectedRemoved: false,
+			expectedError:   errs.ErrInternal,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockTxStarter := txMocks.NewMockTxStarter(t)
+			mockPostsRepo := mocks.NewMockPostsRepository(t)
+			mockCommentsRepo := mocks.NewMockCommentsRepository(t)
+			mockUsersRepo := mocks.NewMockUsersRepository(t)
+			mockOutboxRepo := mocks.NewMockOutboxRepository(t)
+
+			tc.setupMocks(
+				mockTxStarter,
+				mockCommentsRepo,
+				mockUsersRepo,
+				mockOutboxRepo,
+			)
+
+			commentsService := services.NewCommentsService(
+				mockTxStarter,
+				mockCommentsRepo,
+				mockPostsRepo,
+				mockUsersRepo,
+				mockOutboxRepo,
+			)
+			removed, err := commentsService.HardDeleteComment(context.Background(), tc.input)
+
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tc.expectedRemoved, removed)
+
+			mockTxStarter.AssertExpectations(t)
+			mockCommentsRepo.AssertExpectations(t)
+			mockUsersRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCommentsService_VoteComment(t *testing.T) {
+	type testCase struct {
+		name       string
+		input      *dtos.VoteCommentRequest
+		setupMocks func(
+			ts *txMocks.MockTxStarter,
+			cr *mocks.MockCommentsRepository,
+		)
+		expectedVotes models.CommentVotes
+		expectedError error
+	}
+
+	commentID := uuid.New()
+	userID := uuid.New()
+	deletedAt := time.Now()
+
+	// У комментария уже 3 голоса "за" и 1 "против"
+	comment := func() *models.Comment {
+		return &models.Comment{
+			ID:           commentID,
+			Content:      "Test comment",
+			CommentVotes: models.NewCommentVotes(3, 1),
+		}
+	}
+
+	deletedComment := func() *models.Comment {
+		c := comment()
+		c.Content = ""
+		c.DeletedAt = &deletedAt
+		return c
+	}
+
+	vote := func(value int8) *models.CommentVote {
+		return &models.CommentVote{CommentID: commentID, UserID: userID, Value: value}
+	}
+
+	expectUpdateVotes := func(cr *mocks.MockCommentsRepository, votes models.CommentVotes) {
+		cr.On("UpdateVotes", mock.Anything, commentID, votes).Return(
+			&models.Comment{ID: commentID, CommentVotes: votes}, nil,
+		)
+	}
+
+	testCases := []testCase{
+		{
+			name:  "OK, new vote",
+			input: &dtos.VoteCommentRequest{CommentID: commentID, UserID: userID, Value: 1},
+			setupMocks: func(ts *txMocks.MockTxStarter, cr *mocks.MockCommentsRepository) {
+				mockTx := &txMocks.MockTx{}
+				ts.On("Begin", mock.Anything).Return(mockTx, nil)
+				mockTx.On("Commit", mock.Anything).Return(nil)
+
+				cr.On("GetByID", mock.Anything, commentID, true).Return(comment(), nil)
+				cr.On("GetVote", mock.Anything, commentID, userID).Return(nil, errs.ErrNotFound)
+				cr.On("SetVote", mock.Anything, commentID, userID, int8(1)).Return(vote(1), nil)
+				expectUpdateVotes(cr, models.NewCommentVotes(4, 1))
+			},
+			expectedVotes: models.NewCommentVotes(4, 1),
+		},
+		{
+			name:  "OK, flip from upvote to downvote",
+			input: &dtos.VoteCommentRequest{CommentID: commentID, UserID: userID, Value: -1},
+			setupMocks: func(ts *txMocks.MockTxStarter, cr *mocks.MockCommentsRepository) {
+				mockTx := &txMocks.MockTx{}
+				ts.On("Begin", mock.Anything).Return(mockTx, nil)
+				mockTx.On("Commit", mock.Anything).Return(nil)
+
+				cr.On("GetByID", mock.Anything, commentID, true).Return(comment(), nil)
+				cr.On("GetVote", mock.Anything, commentID, userID).Return(vote(1), nil)
+				cr.On("SetVote", mock.Anything, commentID, userID, int8(-1)).Return(vote(-1), nil)
+				expectUpdateVotes(cr, models.NewCommentVotes(2, 2))
+			},
+			expectedVotes: models.NewCommentVotes(2, 2),
+		},
+		{
+			name:  "OK, clear",
+			input: &dtos.VoteCommentRequest{CommentID: commentID, UserID: userID, Value: 0},
+			setupMocks: func(ts *txMocks.MockTxStarter, cr *mocks.MockCommentsRepository) {
+				mockTx := &txMocks.MockTx{}
+				ts.On("Begin", mock.Anything).Return(mockTx, nil)
+				mockTx.On("Commit", mock.Anything).Return(nil)
+
+				cr.On("GetByID", mock.Anything, commentID, true).Return(comment(), nil)
+				cr.On("GetVote", mock.Anything, commentID, userID).Return(vote(-1), nil)
+				cr.On("DeleteVote", mock.Anything, commentID, userID).Return(nil)
+				expectUpdateVotes(cr, models.NewCommentVotes(3, 0))
+			},
+			expectedVotes: models.NewCommentVotes(3, 0),
+		},
+		{
+			name:  "OK, same value is not written",
+			input: &dtos.VoteCommentRequest{CommentID: commentID, UserID: userID, Value: 1},
+			setupMocks: func(ts *txMocks.MockTxStarter, cr *mocks.MockCommentsRepository) {
+				mockTx := &txMocks.MockTx{}
+				ts.On("Begin", mock.Anything).Return(mockTx, nil)
+				mockTx.On("Commit", mock.Anything).Return(nil)
+
+				cr.On("GetByID", mock.Anything, commentID, true).Return(comment(), nil)
+				cr.On("GetVote", mock.Anything, commentID, userID).Return(vote(1), nil)
+			},
+			expectedVotes: models.NewCommentVotes(3, 1),
+		},
+		{
+			name:  "OK, clear on deleted comment",
+			input: &dtos.VoteCommentRequest{CommentID: commentID, UserID: userID, Value: 0},
+			setupMocks: func(ts *txMocks.MockTxStarter, cr *mocks.MockCommentsRepository) {
+				mockTx := &txMocks.MockTx{}
+				ts.On("Begin", mock.Anything).Return(mockTx, nil)
+				mockTx.On("Commit", mock.Anything).Return(nil)
+
+				cr.On("GetByID", mock.Anything, commentID, true).Return(deletedComment(), nil)
+				cr.On("GetVote", mock.Anything, commentID, userID).Return(vote(1), nil)
+				cr.On("DeleteVote", mock.Anything, commentID, userID).Return(nil)
+				expectUpdateVotes(cr, models.NewCommentVotes(2, 1))
+			},
+			expectedVotes: models.NewCommentVotes(2, 1),
+		},
+		{
+			name:  "vote on deleted comment",
+			input: &dtos.VoteCommentRequest{CommentID: commentID, UserID: userID, Value: 1},
+			setupMocks: func(ts *txMocks.MockTxStarter, cr *mocks.MockCommentsRepository) {
+				mockTx := &txMocks.MockTx{}
+				ts.On("Begin", mock.Anything).Return(mockTx, nil)
+				mockTx.On("Rollback", mock.Anything).Return(nil)
+
+				cr.On("GetByID", mock.Anything, commentID, true).Return(deletedComment(), nil)
+			},
+			expectedError: errs.ErrCommentDeleted,
+		},
+		{
+			name:  "commentsRepo.GetVote error",
+			input: &dtos.VoteCommentRequest{CommentID: commentID, UserID: userID, Value: 1},
+			setupMocks: func(ts *txMocks.MockTxStarter, cr *mocks.MockCommentsRepository) {
+				mockTx := &txMocks.MockTx{}
+				ts.On("Begin", mock.Anything).Return(mockTx, nil)
+				mockTx.On("Rollback", mock.Anything).Return(nil)
+
+				cr.On("GetByID", mock.Anything, commentID, true).Return(comment(), nil)
+				cr.On("GetVote", mock.Anything, commentID, userID).Return(nil, errs.ErrInternal)
+			},
+			expectedError: errs.ErrInternal,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockTxStarter := txMocks.NewMockTxStarter(t)
+			mockCommentsRepo := mocks.NewMockCommentsRepository(t)
+
+			tc.setupMocks(mockTxStarter, mockCommentsRepo)
+
+			commentsService := services.NewCommentsService(
+				mockTxStarter,
+				mockCommentsRepo,
+				nil,
+				nil,
+				nil,
+			)
+
+			comment, err := commentsService.VoteComment(context.Background(), tc.input)
+
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+				assert.Nil(t, comment)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedVotes, comment.CommentVotes)
+			}
+
+			mockTxStarter.AssertExpectations(t)
+			mockCommentsRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCommentsService_GetRevisions(t *testing.T) {
+	config.Cfg.Moderators = []string{"moderator"}
+	defer func() { config.Cfg.Moderators = nil }()
+
+	type testCase struct {
+		name              string
+		viewerID          *uuid.UUID
+		setupMocks        func(cr *mocks.MockCommentsRepository, ur *mocks.MockUsersRepository)
+		expectedRevisions map[uuid.UUID][]*models.CommentRevision
+	}
+
+	moderatorID := uuid.New()
+	authorID := uuid.New()
+	otherID := uuid.New()
+	strangerID := uuid.New()
+	ownCommentID := uuid.New()
+	otherCommentID := uuid.New()
+	commentIDs := []uuid.UUID{ownCommentID, otherCommentID}
+
+	revisions := map[uuid.UUID][]*models.CommentRevision{
+		ownCommentID:   {{CommentID: ownCommentID, Content: "own v1"}},
+		otherCommentID: {{CommentID: otherCommentID, Content: "other v1"}},
+	}
+
+	comments := []*models.Comment{
+		{ID: ownCommentID, UserID: authorID},
+		{ID: otherCommentID, UserID: otherID},
+	}
+
+	testCases := []testCase{
+		{
+			name:     "moderator sees all revisions",
+			viewerID: &moderatorID,
+			setupMocks: func(cr *mocks.MockCommentsRepository, ur *mocks.MockUsersRepository) {
+				ur.On("GetByID", mock.Anything, moderatorID).Return(
+					&models.User{ID: moderatorID, Username: "moderator"}, nil,
+				)
+				cr.On("GetRevisionsByCommentIDs", mock.Anything, commentIDs).Return(revisions, nil)
+			},
+			expectedRevisions: revisions,
+		},
+		{
+			name:     "author sees revisions of own comments",
+			viewerID: &authorID,
+			setupMocks: func(cr *mocks.MockCommentsRepository, ur *mocks.MockUsersRepository) {
+				ur.On("GetByID", mock.Anything, authorID).Return(
+					&models.User{ID: authorID, Username: "author"}, nil,
+				)
+				cr.On("GetByIDs", mock.Anything, commentIDs).Return(comments, nil)
+				cr.On("GetRevisionsByCommentIDs", mock.Anything, []uuid.UUID{ownCommentID}).Return(
+					map[uuid.UUID][]*models.CommentRevision{ownCommentID: revisions[ownCommentID]}, nil,
+				)
+			},
+			expectedRevisions: map[uuid.UUID][]*models.CommentRevision{ownCommentID: revisions[ownCommentID]},
+		},
+		{
+			name:     "author does not see revisions of deleted comments",
+			viewerID: &authorID,
+			setupMocks: func(cr *mocks.MockCommentsRepository, ur *mocks.MockUsersRepository) {
+				ur.On("GetByID", mock.Anything, authorID).Return(
+					&models.User{ID: authorID, Username: "author"}, nil,
+				)
+				deletedAt := time.Now()
+				cr.On("GetByIDs", mock.Anything, commentIDs).Return([]*models.Comment{
+					{ID: ownCommentID, UserID: authorID, DeletedAt: &deletedAt},
+					{ID: otherCommentID, UserID: otherID},
+				}, nil)
+			},
+			expectedRevisions: map[uuid.UUID][]*models.CommentRevision{},
+		},
+		{
+			name:     "other user sees nothing",
+			viewerID: &strangerID,
+			setupMocks: func(cr *mocks.MockCommentsRepository, ur *mocks.MockUsersRepository) {
+				ur.On("GetByID", mock.Anything, strangerID).Return(
+					&models.User{ID: strangerID, Username: "stranger"}, nil,
+				)
+				cr.On("GetByIDs", mock.Anything, commentIDs).Return(comments, nil)
+			},
+			expectedRevisions: map[uuid.UUID][]*models.CommentRevision{},
+		},
+		{
+			name:              "anonymous viewer sees nothing",
+			setupMocks:        func(cr *mocks.MockCommentsRepository, ur *mocks.MockUsersRepository) {},
+			expectedRevisions: map[uuid.UUID][]*models.CommentRevision{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCommentsRepo := mocks.NewMockCommentsRepository(t)
+			mockUsersRepo := mocks.NewMockUsersRepository(t)
+
+			tc.setupMocks(mockCommentsRepo, mockUsersRepo)
+
+			commentsService := services.NewCommentsService(
+				nil,
+				mockCommentsRepo,
+				nil,
+				mockUsersRepo,
+				nil,
+			)
+
+			got, err := commentsService.GetRevisions(context.Background(), commentIDs, tc.viewerID)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedRevisions, got)
+
+			mockCommentsRepo.AssertExpectations(t)
+			mockUsersRepo.AssertExpectations(t)
 		})
 	}
 }
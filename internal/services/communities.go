@@ -0,0 +1,264 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Govorov1705/ozon-test/internal/dtos"
+	"github.com/Govorov1705/ozon-test/internal/errs"
+	"github.com/Govorov1705/ozon-test/internal/logger"
+	"github.com/Govorov1705/ozon-test/internal/models"
+	"github.com/Govorov1705/ozon-test/internal/repositories"
+	"github.com/Govorov1705/ozon-test/internal/transactions"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type CommunitiesService struct {
+	txStarter       transactions.TxStarter
+	communitiesRepo repositories.CommunitiesRepository
+}
+
+func NewCommunitiesService(
+	txStarter transactions.TxStarter,
+	cmr repositories.CommunitiesRepository,
+) *CommunitiesService {
+	return &CommunitiesService{
+		txStarter:       txStarter,
+		communitiesRepo: cmr,
+	}
+}
+
+// Создатель сообщества становится его владельцем и первым участником
+func (s *CommunitiesService) CreateCommunity(ctx context.Context, req *dtos.CreateCommunityRequest) (community *models.Community, err error) {
+	areCommentsAllowed := true
+	if req.AreCommentsAllowed != nil {
+		areCommentsAllowed = *req.AreCommentsAllowed
+	}
+
+	tx, err := s.txStarter.Begin(ctx)
+	if err != nil {
+		logger.Logger.Error("error starting transaction", zap.Error(err))
+		return nil, errs.ErrInternal
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback(ctx)
+			panic(p)
+		} else if err != nil {
+			if rollbackErr := tx.Rollback(ctx); rollbackErr != nil {
+				logger.Logger.Error("rollback failed", zap.Error(rollbackErr))
+				err = errs.ErrInternal
+			}
+		} else {
+			if commitErr := tx.Commit(ctx); commitErr != nil {
+				logger.Logger.Error("error committing transaction", zap.Error(commitErr))
+				err = errs.ErrInternal
+			}
+		}
+	}()
+
+	ctx = transactions.PutTxIntoContext(ctx, tx)
+
+	community, err = s.communitiesRepo.Add(
+		ctx,
+		req.OwnerID,
+		req.Name,
+		req.Slug,
+		req.Description,
+		areCommentsAllowed,
+	)
+	if err != nil {
+		if errors.Is(err, errs.ErrAlreadyExists) {
+			return nil, fmt.Errorf("community %w", errs.ErrAlreadyExists)
+		}
+		return nil, err
+	}
+
+	_, err = s.communitiesRepo.AddMember(ctx, community.ID, req.OwnerID, models.CommunityRoleOwner)
+	if err != nil {
+		return nil, err
+	}
+
+	return community, nil
+}
+
+func (s *CommunitiesService) GetCommunity(ctx context.Context, slug string) (*models.Community, error) {
+	return s.communitiesRepo.GetBySlug(ctx, slug)
+}
+
+// Повторное вступление ничего не меняет. Сообщество блокируется, чтобы
+// изменения участников не пересекались с другими операциями над ним
+func (s *CommunitiesService) JoinCommunity(ctx context.Context, userID, communityID uuid.UUID) (community *models.Community, err error) {
+	tx, err := s.txStarter.Begin(ctx)
+	if err != nil {
+		logger.Logger.Error("error starting transaction", zap.Error(err))
+		return nil, errs.ErrInternal
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback(ctx)
+			panic(p)
+		} else if err != nil {
+			if rollbackErr := tx.Rollback(ctx); rollbackErr != nil {
+				logger.Logger.Error("rollback failed", zap.Error(rollbackErr))
+				err = errs.ErrInternal
+			}
+		} else {
+			if commitErr := tx.Commit(ctx); commitErr != nil {
+				logger.Logger.Error("error committing transaction", zap.Error(commitErr))
+				err = errs.ErrInternal
+			}
+		}
+	}()
+
+	ctx = transactions.PutTxIntoContext(ctx, tx)
+
+	community, err = s.communitiesRepo.GetByID(ctx, communityID, true)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.communitiesRepo.AddMember(ctx, communityID, userID, models.CommunityRoleMember)
+	if err != nil {
+		return nil, err
+	}
+
+	return community, nil
+}
+
+// Владелец не может покинуть сообщество, иначе его некому будет администрировать
+func (s *CommunitiesService) LeaveCommunity(ctx context.Context, userID, communityID uuid.UUID) (community *models.Community, err error) {
+	tx, err := s.txStarter.Begin(ctx)
+	if err != nil {
+		logger.Logger.Error("error starting transaction", zap.Error(err))
+		return nil, errs.ErrInternal
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback(ctx)
+			panic(p)
+		} else if err != nil {
+			if rollbackErr := tx.Rollback(ctx); rollbackErr != nil {
+				logger.Logger.Error("rollback failed", zap.Error(rollbackErr))
+				err = errs.ErrInternal
+			}
+		} else {
+			if commitErr := tx.Commit(ctx); commitErr != nil {
+				logger.Logger.Error("error committing transaction", zap.Error(commitErr))
+				err = errs.ErrInternal
+			}
+		}
+	}()
+
+	ctx = transactions.PutTxIntoContext(ctx, tx)
+
+	community, err = s.communitiesRepo.GetByID(ctx, communityID, true)
+	if err != nil {
+		return nil, err
+	}
+
+	if community.OwnerID == userID {
+		return nil, errs.ErrOwnerCannotLeave
+	}
+
+	_, err = s.communitiesRepo.DeleteMember(ctx, communityID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return community, nil
+}
+
+func (s *CommunitiesService) AddModerator(ctx context.Context, req *dtos.CommunityModeratorRequest) (*models.CommunityMember, error) {
+	return s.setMemberRole(ctx, req, models.CommunityRoleModerator)
+}
+
+func (s *CommunitiesService) RemoveModerator(ctx context.Context, req *dtos.CommunityModeratorRequest) (*models.CommunityMember, error) {
+	return s.setMemberRole(ctx, req, models.CommunityRoleMember)
+}
+
+// Назначать и снимать модераторов может только владелец сообщества.
+// Роль самого владельца не меняется
+func (s *CommunitiesService) setMemberRole(ctx context.Context, req *dtos.CommunityModeratorRequest, role models.CommunityRole) (member *models.CommunityMember, err error) {
+	tx, err := s.txStarter.Begin(ctx)
+	if err != nil {
+		logger.Logger.Error("error starting transaction", zap.Error(err))
+		return nil, errs.ErrInternal
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback(ctx)
+			panic(p)
+		} else if err != nil {
+			if rollbackErr := tx.Rollback(ctx); rollbackErr != nil {
+				logger.Logger.Error("rollback failed", zap.Error(rollbackErr))
+				err = errs.ErrInternal
+			}
+		} else {
+			if commitErr := tx.Commit(ctx); commitErr != nil {
+				logger.Logger.Error("error committing transaction", zap.Error(commitErr))
+				err = errs.ErrInternal
+			}
+		}
+	}()
+
+	ctx = transactions.PutTxIntoContext(ctx, tx)
+
+	community, err := s.communitiesRepo.GetByID(ctx, req.CommunityID, true)
+	if err != nil {
+		return nil, err
+	}
+
+	if community.OwnerID != req.UserID {
+		return nil, errs.ErrUnauthorized
+	}
+
+	member, err = s.communitiesRepo.GetMember(ctx, req.CommunityID, req.MemberID)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			return nil, errs.ErrNotCommunityMember
+		}
+		return nil, err
+	}
+
+	if member.Role == models.CommunityRoleOwner {
+		return nil, errs.ErrUnauthorized
+	}
+
+	if member.Role == role {
+		return member, nil
+	}
+
+	return s.communitiesRepo.SetMemberRole(ctx, req.CommunityID, req.MemberID, role)
+}
+
+// GetViewerRole возвращает nil, если пользователь не состоит в сообществе
+func (s *CommunitiesService) GetViewerRole(ctx context.Context, communityID, userID uuid.UUID) (*models.CommunityRole, error) {
+	member, err := s.communitiesRepo.GetMember(ctx, communityID, userID)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &member.Role, nil
+}
+
+func (s *CommunitiesService) GetModerators(ctx context.Context, communityID uuid.UUID) ([]*models.CommunityMember, error) {
+	return s.communitiesRepo.GetModerators(ctx, communityID)
+}
+
+func (s *CommunitiesService) GetByIDs(ctx context.Context, IDs []uuid.UUID) ([]*models.Community, error) {
+	return s.communitiesRepo.GetByIDs(ctx, IDs)
+}
+
+func (s *CommunitiesService) CountMembers(ctx context.Context, communityIDs []uuid.UUID) (map[uuid.UUID]int32, error) {
+	return s.communitiesRepo.CountMembers(ctx, communityIDs)
+}
@@ -0,0 +1,427 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Govorov1705/ozon-test/internal/dtos"
+	"github.com/Govorov1705/ozon-test/internal/errs"
+	"github.com/Govorov1705/ozon-test/internal/models"
+	"github.com/Govorov1705/ozon-test/internal/repositories/mocks"
+	"github.com/Govorov1705/ozon-test/internal/services"
+	txMocks "github.com/Govorov1705/ozon-test/internal/transactions/mocks"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCommunitiesService_CreateCommunity(t *testing.T) {
+	type testCase struct {
+		name          string
+		input         *dtos.CreateCommunityRequest
+		setupMocks    func(ts *txMocks.MockTxStarter, cmr *mocks.MockCommunitiesRepository)
+		expectedError error
+	}
+
+	ownerID := uuid.New()
+	communityID := uuid.New()
+	areCommentsAllowed := false
+
+	community := &models.Community{
+		ID:      communityID,
+		OwnerID: ownerID,
+		Name:    "Go",
+		Slug:    "golang",
+	}
+
+	testCases := []testCase{
+		{
+			name: "OK",
+			input: &dtos.CreateCommunityRequest{
+				OwnerID:            ownerID,
+				Name:               "Go",
+				Slug:               "golang",
+				AreCommentsAllowed: &areCommentsAllowed,
+			},
+			setupMocks: func(ts *txMocks.MockTxStarter, cmr *mocks.MockCommunitiesRepository) {
+				mockTx := &txMocks.MockTx{}
+				ts.On("Begin", mock.Anything).Return(mockTx, nil)
+				mockTx.On("Commit", mock.Anything).Return(nil)
+
+				cmr.On("Add", mock.Anything, ownerID, "Go", "golang", "", false).Return(community, nil)
+				cmr.On("AddMember", mock.Anything, communityID, ownerID, models.CommunityRoleOwner).Return(true, nil)
+			},
+		},
+		{
+			name: "slug is taken",
+			input: &dtos.CreateCommunityRequest{
+				OwnerID: ownerID,
+				Name:    "Go",
+				Slug:    "golang",
+			},
+			setupMocks: func(ts *txMocks.MockTxStarter, cmr *mocks.MockCommunitiesRepository) {
+				mockTx := &txMocks.MockTx{}
+				ts.On("Begin", mock.Anything).Return(mockTx, nil)
+				mockTx.On("Rollback", mock.Anything).Return(nil)
+
+				cmr.On("Add", mock.Anything, ownerID, "Go", "golang", "", true).Return(nil, errs.ErrAlreadyExists)
+			},
+			expectedError: errs.ErrAlreadyExists,
+		},
+		{
+			name: "communitiesRepo.AddMember error",
+			input: &dtos.CreateCommunityRequest{
+				OwnerID: ownerID,
+				Name:    "Go",
+				Slug:    "golang",
+			},
+			setupMocks: func(ts *txMocks.MockTxStarter, cmr *mocks.MockCommunitiesRepository) {
+				mockTx := &txMocks.MockTx{}
+				ts.On("Begin", mock.Anything).Return(mockTx, nil)
+				mockTx.On("Rollback", mock.Anything).Return(nil)
+
+				cmr.On("Add", mock.Anything, ownerID, "Go", "golang", "", true).Return(community, nil)
+				cmr.On("AddMember", mock.Anything, communityID, ownerID, models.CommunityRoleOwner).Return(
+					false, errs.ErrInternal,
+				)
+			},
+			expectedError: errs.ErrInternal,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockTxStarter := txMocks.NewMockTxStarter(t)
+			mockCommunitiesRepo := mocks.NewMockCommunitiesRepository(t)
+
+			tc.setupMocks(mockTxStarter, mockCommunitiesRepo)
+
+			communitiesService := services.NewCommunitiesService(mockTxStarter, mockCommunitiesRepo)
+
+			community, err := communitiesService.CreateCommunity(context.Background(), tc.input)
+
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+				assert.Nil(t, community)
+			} else {
+				assert.NoError(t, err)
+				assert.NotNil(t, community)
+			}
+
+			mockTxStarter.AssertExpectations(t)
+			mockCommunitiesRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCommunitiesService_JoinCommunity(t *testing.T) {
+	type testCase struct {
+		name          string
+		setupMocks    func(ts *txMocks.MockTxStarter, cmr *mocks.MockCommunitiesRepository)
+		expectedError error
+	}
+
+	userID := uuid.New()
+	communityID := uuid.New()
+
+	community := &models.Community{ID: communityID, OwnerID: uuid.New()}
+
+	testCases := []testCase{
+		{
+			name: "OK",
+			setupMocks: func(ts *txMocks.MockTxStarter, cmr *mocks.MockCommunitiesRepository) {
+				mockTx := &txMocks.MockTx{}
+				ts.On("Begin", mock.Anything).Return(mockTx, nil)
+				mockTx.On("Commit", mock.Anything).Return(nil)
+
+				cmr.On("GetByID", mock.Anything, communityID, true).Return(community, nil)
+				cmr.On("AddMember", mock.Anything, communityID, userID, models.CommunityRoleMember).Return(true, nil)
+			},
+		},
+		{
+			name: "OK, already a member",
+			setupMocks: func(ts *txMocks.MockTxStarter, cmr *mocks.MockCommunitiesRepository) {
+				mockTx := &txMocks.MockTx{}
+				ts.On("Begin", mock.Anything).Return(mockTx, nil)
+				mockTx.On("Commit", mock.Anything).Return(nil)
+
+				cmr.On("GetByID", mock.Anything, communityID, true).Return(community, nil)
+				cmr.On("AddMember", mock.Anything, communityID, userID, models.CommunityRoleMember).Return(false, nil)
+			},
+		},
+		{
+			name: "community not found",
+			setupMocks: func(ts *txMocks.MockTxStarter, cmr *mocks.MockCommunitiesRepository) {
+				mockTx := &txMocks.MockTx{}
+				ts.On("Begin", mock.Anything).Return(mockTx, nil)
+				mockTx.On("Rollback", mock.Anything).Return(nil)
+
+				cmr.On("GetByID", mock.Anything, communityID, true).Return(nil, errs.ErrNotFound)
+			},
+			expectedError: errs.ErrNotFound,
+		},
+		{
+			name: "communitiesRepo.AddMember error",
+			setupMocks: func(ts *txMocks.MockTxStarter, cmr *mocks.MockCommunitiesRepository) {
+				mockTx := &txMocks.MockTx{}
+				ts.On("Begin", mock.Anything).Return(mockTx, nil)
+				mockTx.On("Rollback", mock.Anything).Return(nil)
+
+				cmr.On("GetByID", mock.Anything, communityID, true).Return(community, nil)
+				cmr.On("AddMember", mock.Anything, communityID, userID, models.CommunityRoleMember).Return(false, errs.ErrInternal)
+			},
+			expectedError: errs.ErrInternal,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockTxStarter := txMocks.NewMockTxStarter(t)
+			mockCommunitiesRepo := mocks.NewMockCommunitiesRepository(t)
+
+			tc.setupMocks(mockTxStarter, mockCommunitiesRepo)
+
+			communitiesService := services.NewCommunitiesService(mockTxStarter, mockCommunitiesRepo)
+
+			community, err := communitiesService.JoinCommunity(context.Background(), userID, communityID)
+
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+				assert.Nil(t, community)
+			} else {
+				assert.NoError(t, err)
+				assert.NotNil(t, community)
+			}
+
+			mockTxStarter.AssertExpectations(t)
+			mockCommunitiesRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCommunitiesService_LeaveCommunity(t *testing.T) {
+	type testCase struct {
+		name          string
+		userID        uuid.UUID
+		setupMocks    func(ts *txMocks.MockTxStarter, cmr *mocks.MockCommunitiesRepository)
+		expectedError error
+	}
+
+	ownerID := uuid.New()
+	memberID := uuid.New()
+	communityID := uuid.New()
+
+	community := &models.Community{ID: communityID, OwnerID: ownerID}
+
+	testCases := []testCase{
+		{
+			name:   "OK",
+			userID: memberID,
+			setupMocks: func(ts *txMocks.MockTxStarter, cmr *mocks.MockCommunitiesRepository) {
+				mockTx := &txMocks.MockTx{}
+				ts.On("Begin", mock.Anything).Return(mockTx, nil)
+				mockTx.On("Commit", mock.Anything).Return(nil)
+
+				cmr.On("GetByID", mock.Anything, communityID, true).Return(community, nil)
+				cmr.On("DeleteMember", mock.Anything, communityID, memberID).Return(true, nil)
+			},
+		},
+		{
+			name:   "owner cannot leave",
+			userID: ownerID,
+			setupMocks: func(ts *txMocks.MockTxStarter, cmr *mocks.MockCommunitiesRepository) {
+				mockTx := &txMocks.MockTx{}
+				ts.On("Begin", mock.Anything).Return(mockTx, nil)
+				mockTx.On("Rollback", mock.Anything).Return(nil)
+
+				cmr.On("GetByID", mock.Anything, communityID, true).Return(community, nil)
+			},
+			expectedError: errs.ErrOwnerCannotLeave,
+		},
+		{
+			name:   "community not found",
+			userID: memberID,
+			setupMocks: func(ts *txMocks.MockTxStarter, cmr *mocks.MockCommunitiesRepository) {
+				mockTx := &txMocks.MockTx{}
+				ts.On("Begin", mock.Anything).Return(mockTx, nil)
+				mockTx.On("Rollback", mock.Anything).Return(nil)
+
+				cmr.On("GetByID", mock.Anything, communityID, true).Return(nil, errs.ErrNotFound)
+			},
+			expectedError: errs.ErrNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockTxStarter := txMocks.NewMockTxStarter(t)
+			mockCommunitiesRepo := mocks.NewMockCommunitiesRepository(t)
+
+			tc.setupMocks(mockTxStarter, mockCommunitiesRepo)
+
+			communitiesService := services.NewCommunitiesService(mockTxStarter, mockCommunitiesRepo)
+
+			community, err := communitiesService.LeaveCommunity(context.Background(), tc.userID, communityID)
+
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+				assert.Nil(t, community)
+			} else {
+				assert.NoError(t, err)
+				assert.NotNil(t, community)
+			}
+
+			mockTxStarter.AssertExpectations(t)
+			mockCommunitiesRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCommunitiesService_SetModerator(t *testing.T) {
+	type testCase struct {
+		name          string
+		input         *dtos.CommunityModeratorRequest
+		remove        bool
+		setupMocks    func(ts *txMocks.MockTxStarter, cmr *mocks.MockCommunitiesRepository)
+		expectedRole  models.CommunityRole
+		expectedError error
+	}
+
+	ownerID := uuid.New()
+	memberID := uuid.New()
+	communityID := uuid.New()
+
+	community := &models.Community{ID: communityID, OwnerID: ownerID}
+
+	member := func(userID uuid.UUID, role models.CommunityRole) *models.CommunityMember {
+		return &models.CommunityMember{CommunityID: communityID, UserID: userID, Role: role}
+	}
+
+	testCases := []testCase{
+		{
+			name:  "OK (add)",
+			input: &dtos.CommunityModeratorRequest{CommunityID: communityID, UserID: ownerID, MemberID: memberID},
+			setupMocks: func(ts *txMocks.MockTxStarter, cmr *mocks.MockCommunitiesRepository) {
+				mockTx := &txMocks.MockTx{}
+				ts.On("Begin", mock.Anything).Return(mockTx, nil)
+				mockTx.On("Commit", mock.Anything).Return(nil)
+
+				cmr.On("GetByID", mock.Anything, communityID, true).Return(community, nil)
+				cmr.On("GetMember", mock.Anything, communityID, memberID).Return(
+					member(memberID, models.CommunityRoleMember), nil,
+				)
+				cmr.On("SetMemberRole", mock.Anything, communityID, memberID, models.CommunityRoleModerator).Return(
+					member(memberID, models.CommunityRoleModerator), nil,
+				)
+			},
+			expectedRole: models.CommunityRoleModerator,
+		},
+		{
+			name:   "OK (remove)",
+			input:  &dtos.CommunityModeratorRequest{CommunityID: communityID, UserID: ownerID, MemberID: memberID},
+			remove: true,
+			setupMocks: func(ts *txMocks.MockTxStarter, cmr *mocks.MockCommunitiesRepository) {
+				mockTx := &txMocks.MockTx{}
+				ts.On("Begin", mock.Anything).Return(mockTx, nil)
+				mockTx.On("Commit", mock.Anything).Return(nil)
+
+				cmr.On("GetByID", mock.Anything, communityID, true).Return(community, nil)
+				cmr.On("GetMember", mock.Anything, communityID, memberID).Return(
+					member(memberID, models.CommunityRoleModerator), nil,
+				)
+				cmr.On("SetMemberRole", mock.Anything, communityID, memberID, models.CommunityRoleMember).Return(
+					member(memberID, models.CommunityRoleMember), nil,
+				)
+			},
+			expectedRole: models.CommunityRoleMember,
+		},
+		{
+			name:  "OK (already a moderator)",
+			input: &dtos.CommunityModeratorRequest{CommunityID: communityID, UserID: ownerID, MemberID: memberID},
+			setupMocks: func(ts *txMocks.MockTxStarter, cmr *mocks.MockCommunitiesRepository) {
+				mockTx := &txMocks.MockTx{}
+				ts.On("Begin", mock.Anything).Return(mockTx, nil)
+				mockTx.On("Commit", mock.Anything).Return(nil)
+
+				cmr.On("GetByID", mock.Anything, communityID, true).Return(community, nil)
+				cmr.On("GetMember", mock.Anything, communityID, memberID).Return(
+					member(memberID, models.CommunityRoleModerator), nil,
+				)
+			},
+			expectedRole: models.CommunityRoleModerator,
+		},
+		{
+			name:  "not the owner",
+			input: &dtos.CommunityModeratorRequest{CommunityID: communityID, UserID: memberID, MemberID: memberID},
+			setupMocks: func(ts *txMocks.MockTxStarter, cmr *mocks.MockCommunitiesRepository) {
+				mockTx := &txMocks.MockTx{}
+				ts.On("Begin", mock.Anything).Return(mockTx, nil)
+				mockTx.On("Rollback", mock.Anything).Return(nil)
+
+				cmr.On("GetByID", mock.Anything, communityID, true).Return(community, nil)
+			},
+			expectedError: errs.ErrUnauthorized,
+		},
+		{
+			name:  "not a member",
+			input: &dtos.CommunityModeratorRequest{CommunityID: communityID, UserID: ownerID, MemberID: memberID},
+			setupMocks: func(ts *txMocks.MockTxStarter, cmr *mocks.MockCommunitiesRepository) {
+				mockTx := &txMocks.MockTx{}
+				ts.On("Begin", mock.Anything).Return(mockTx, nil)
+				mockTx.On("Rollback", mock.Anything).Return(nil)
+
+				cmr.On("GetByID", mock.Anything, communityID, true).Return(community, nil)
+				cmr.On("GetMember", mock.Anything, communityID, memberID).Return(nil, errs.ErrNotFound)
+			},
+			expectedError: errs.ErrNotCommunityMember,
+		},
+		{
+			name:   "owner role cannot change",
+			input:  &dtos.CommunityModeratorRequest{CommunityID: communityID, UserID: ownerID, MemberID: ownerID},
+			remove: true,
+			setupMocks: func(ts *txMocks.MockTxStarter, cmr *mocks.MockCommunitiesRepository) {
+				mockTx := &txMocks.MockTx{}
+				ts.On("Begin", mock.Anything).Return(mockTx, nil)
+				mockTx.On("Rollback", mock.Anything).Return(nil)
+
+				cmr.On("GetByID", mock.Anything, communityID, true).Return(community, nil)
+				cmr.On("GetMember", mock.Anything, communityID, ownerID).Return(
+					member(ownerID, models.CommunityRoleOwner), nil,
+				)
+			},
+			expectedError: errs.ErrUnauthorized,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockTxStarter := txMocks.NewMockTxStarter(t)
+			mockCommunitiesRepo := mocks.NewMockCommunitiesRepository(t)
+
+			tc.setupMocks(mockTxStarter, mockCommunitiesRepo)
+
+			communitiesService := services.NewCommunitiesService(mockTxStarter, mockCommunitiesRepo)
+
+			var (
+				member *models.CommunityMember
+				err    error
+			)
+			if tc.remove {
+				member, err = communitiesService.RemoveModerator(context.Background(), tc.input)
+			} else {
+				member, err = communitiesService.AddModerator(context.Background(), tc.input)
+			}
+
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+				assert.Nil(t, member)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedRole, member.Role)
+			}
+
+			mockTxStarter.AssertExpectations(t)
+			mockCommunitiesRepo.AssertExpectations(t)
+		})
+	}
+}
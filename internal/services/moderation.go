@@ -0,0 +1,24 @@
+package services
+
+import (
+	"context"
+	"slices"
+
+	"github.com/Govorov1705/ozon-test/config"
+	"github.com/Govorov1705/ozon-test/internal/repositories"
+	"github.com/google/uuid"
+)
+
+// Модераторы задаются списком username в переменной окружения MODERATORS
+func isModerator(ctx context.Context, usersRepo repositories.UsersRepository, userID uuid.UUID) (bool, error) {
+	if len(config.Cfg.Moderators) == 0 {
+		return false, nil
+	}
+
+	user, err := usersRepo.GetByID(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+
+	return slices.Contains(config.Cfg.Moderators, user.Username), nil
+}
@@ -0,0 +1,26 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/Govorov1705/ozon-test/internal/dtos"
+	"github.com/Govorov1705/ozon-test/internal/errs"
+	"github.com/Govorov1705/ozon-test/internal/logger"
+	"github.com/Govorov1705/ozon-test/internal/repositories"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// addPostActivity записывает событие в outbox. Вызывается в транзакции
+// изменения, чтобы подписчики узнали о нем только после коммита
+// и не потеряли его при сбое
+func addPostActivity(ctx context.Context, outboxRepo repositories.OutboxRepository, event *dtos.PostActivityEvent) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		logger.Logger.Error("error marshalling event", zap.Error(err))
+		return errs.ErrInternal
+	}
+
+	return outboxRepo.Add(ctx, uuid.New(), event.Type, payload)
+}
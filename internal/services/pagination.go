@@ -0,0 +1,37 @@
+package services
+
+import (
+	"slices"
+
+	"github.com/Govorov1705/ozon-test/internal/dtos"
+	"github.com/Govorov1705/ozon-test/internal/pagination"
+)
+
+// buildPage отбрасывает дополнительную запись, запрошенную для определения наличия
+// следующей страницы, восстанавливает порядок при обратной пагинации и собирает PageInfo
+func buildPage[T any](items []T, page *pagination.Page, cursor func(T) string) ([]T, dtos.PageInfo) {
+	pageInfo := dtos.PageInfo{}
+
+	hasMore := len(items) > page.Size()
+	if hasMore {
+		items = items[:page.Size()]
+	}
+
+	if page.Backward {
+		slices.Reverse(items)
+		pageInfo.HasPreviousPage = hasMore
+		pageInfo.HasNextPage = page.Before != nil
+	} else {
+		pageInfo.HasNextPage = hasMore
+		pageInfo.HasPreviousPage = page.After != nil
+	}
+
+	if len(items) > 0 {
+		startCursor := cursor(items[0])
+		endCursor := cursor(items[len(items)-1])
+		pageInfo.StartCursor = &startCursor
+		pageInfo.EndCursor = &endCursor
+	}
+
+	return items, pageInfo
+}
@@ -2,99 +2,259 @@ package services
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"slices"
 
+	"github.com/Govorov1705/ozon-test/config"
 	"github.com/Govorov1705/ozon-test/internal/dtos"
 	"github.com/Govorov1705/ozon-test/internal/errs"
 	"github.com/Govorov1705/ozon-test/internal/logger"
 	"github.com/Govorov1705/ozon-test/internal/models"
+	"github.com/Govorov1705/ozon-test/internal/pagination"
 	"github.com/Govorov1705/ozon-test/internal/repositories"
+	"github.com/Govorov1705/ozon-test/internal/tags"
 	"github.com/Govorov1705/ozon-test/internal/transactions"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
+const defaultTagsLimit int32 = 10
+
 type PostsService struct {
-	txStarter    transactions.TxStarter
-	postsRepo    repositories.PostsRepository
-	commentsRepo repositories.CommentsRepository
+	txStarter       transactions.TxStarter
+	postsRepo       repositories.PostsRepository
+	commentsRepo    repositories.CommentsRepository
+	outboxRepo      repositories.OutboxRepository
+	communitiesRepo repositories.CommunitiesRepository
 }
 
 func NewPostsService(
 	txStarter transactions.TxStarter,
 	pr repositories.PostsRepository,
 	cr repositories.CommentsRepository,
+	or repositories.OutboxRepository,
+	cmr repositories.CommunitiesRepository,
 ) *PostsService {
 	return &PostsService{
-		txStarter:    txStarter,
-		postsRepo:    pr,
-		commentsRepo: cr,
+		txStarter:       txStarter,
+		postsRepo:       pr,
+		commentsRepo:    cr,
+		outboxRepo:      or,
+		communitiesRepo: cmr,
 	}
 }
 
-func (s *PostsService) CreatePost(ctx context.Context, input *dtos.CreatePostRequest) (*models.Post, error) {
+// Пост без тегов вне сообщества создается одной вставкой, остальные - в
+// транзакции. Участие в сообществе проверяется под блокировкой его строки,
+// поэтому пост не появится от участника, который уже вышел. Если разрешение
+// комментариев не указано, пост в сообществе наследует настройку сообщества
+func (s *PostsService) CreatePost(ctx context.Context, input *dtos.CreatePostRequest) (post *models.Post, err error) {
+	postTags, err := normalizeTags(input.Tags)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.CommunityID == nil && len(postTags) == 0 {
+		areCommentsAllowed := input.AreCommentsAllowed == nil || *input.AreCommentsAllowed
+		return s.postsRepo.Add(ctx, input.UserID, nil, input.Title, input.Content, areCommentsAllowed)
+	}
+
+	tx, err := s.txStarter.Begin(ctx)
+	if err != nil {
+		logger.Logger.Error("error starting transaction", zap.Error(err))
+		return nil, errs.ErrInternal
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback(ctx)
+			panic(p)
+		} else if err != nil {
+			if rollbackErr := tx.Rollback(ctx); rollbackErr != nil {
+				logger.Logger.Error("rollback failed", zap.Error(rollbackErr))
+				err = errs.ErrInternal
+			}
+		} else {
+			if commitErr := tx.Commit(ctx); commitErr != nil {
+				logger.Logger.Error("error committing transaction", zap.Error(commitErr))
+				err = errs.ErrInternal
+			}
+		}
+	}()
+
+	ctx = transactions.PutTxIntoContext(ctx, tx)
+
 	areCommentsAllowed := true
+	if input.CommunityID != nil {
+		var community *models.Community
+		community, err = s.communityForPost(ctx, *input.CommunityID, input.UserID)
+		if err != nil {
+			return nil, err
+		}
+		areCommentsAllowed = community.AreCommentsAllowed
+	}
 	if input.AreCommentsAllowed != nil {
 		areCommentsAllowed = *input.AreCommentsAllowed
 	}
 
-	return s.postsRepo.Add(ctx, input.UserID, input.Title, input.Content, areCommentsAllowed)
+	post, err = s.postsRepo.Add(ctx, input.UserID, input.CommunityID, input.Title, input.Content, areCommentsAllowed)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(postTags) > 0 {
+		err = s.postsRepo.SetTags(ctx, post.ID, postTags)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return post, nil
+}
+
+// communityForPost возвращает сообщество, в котором публикуется пост.
+// Публиковать в сообществе могут только его участники. Вызывается внутри
+// транзакции: строка сообщества блокируется до публикации поста
+func (s *PostsService) communityForPost(ctx context.Context, communityID, userID uuid.UUID) (*models.Community, error) {
+	community, err := s.communitiesRepo.GetByID(ctx, communityID, true)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.communitiesRepo.GetMember(ctx, communityID, userID)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			return nil, errs.ErrNotCommunityMember
+		}
+		return nil, err
+	}
+
+	return community, nil
+}
+
+// normalizeTags приводит теги к хранимому виду и проверяет их число,
+// заданное переменной окружения MAX_POST_TAGS
+func normalizeTags(postTags []string) ([]string, error) {
+	if postTags == nil {
+		return nil, nil
+	}
+
+	postTags, err := tags.NormalizeAll(postTags)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(postTags) > config.Cfg.MaxPostTags {
+		return nil, fmt.Errorf("%w: at most %d allowed", errs.ErrTooManyTags, config.Cfg.MaxPostTags)
+	}
+
+	return postTags, nil
 }
 
 func (s *PostsService) GetAllPosts(ctx context.Context) ([]*models.Post, error) {
 	return s.postsRepo.GetAll(ctx)
 }
 
-// Данный сервис сначала получает рутовые комментарии с учетом пагинации,
-// а затем их потомков, чтобы в конце собрать общую вложенную структуру
-func (s *PostsService) GetPostWithComments(ctx context.Context, postID uuid.UUID, limit, offset *int32) (*dtos.PostWithComments, error) {
-	postWithComments := dtos.PostWithComments{}
-
-	post, err := s.postsRepo.GetByID(ctx, postID, false)
+func (s *PostsService) GetPosts(ctx context.Context, req *dtos.GetPostsRequest) (*dtos.PostsConnection, error) {
+	page, err := pagination.NewPage(req.First, req.After, req.Last, req.Before)
 	if err != nil {
 		return nil, err
 	}
-	postWithComments.Post = post
 
-	rootComments, err := s.commentsRepo.GetRootCommentsByPostID(ctx, post.ID, limit, offset)
+	var posts []*models.Post
+	if req.Tag != nil {
+		var tag string
+		tag, err = tags.Normalize(*req.Tag)
+		if err != nil {
+			return nil, err
+		}
+		posts, err = s.postsRepo.GetPageByTag(ctx, tag, page)
+	} else if req.CommunityID != nil {
+		posts, err = s.postsRepo.GetPageByCommunity(ctx, *req.CommunityID, page)
+	} else {
+		posts, err = s.postsRepo.GetPage(ctx, page)
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	commentMap := make(map[uuid.UUID]*dtos.CommentWithReplies)
-	rootIDs := make([]*uuid.UUID, len(rootComments))
+	posts, pageInfo := buildPage(posts, page, func(p *models.Post) string {
+		return pagination.EncodeCursor(p.CreatedAt, p.ID)
+	})
 
-	for i, rc := range rootComments {
-		commentMap[rc.ID] = &dtos.CommentWithReplies{
-			Comment: *rc,
-			Replies: []*dtos.CommentWithReplies{},
+	edges := make([]*dtos.PostEdge, len(posts))
+	for i, p := range posts {
+		edges[i] = &dtos.PostEdge{
+			Cursor: pagination.EncodeCursor(p.CreatedAt, p.ID),
+			Node:   p,
 		}
-		rootIDs[i] = &rc.ID
 	}
 
-	childrenComments, err := s.commentsRepo.GetChildrenCommentsByRootIDs(ctx, rootIDs)
+	return &dtos.PostsConnection{
+		Edges:    edges,
+		PageInfo: pageInfo,
+	}, nil
+}
+
+// Данный сервис сначала получает рутовые комментарии с учетом пагинации,
+// а затем их ответы не глубже MaxDepth уровней, чтобы в конце собрать общую вложенную структуру
+func (s *PostsService) GetPostWithComments(ctx context.Context, req *dtos.GetPostWithCommentsRequest) (*dtos.PostWithComments, error) {
+	postWithComments := dtos.PostWithComments{}
+
+	page, err := pagination.NewSortedPage(req.First, req.After, req.Sort)
+	if err != nil {
+		return nil, err
+	}
+
+	maxDepth := defaultMaxDepth
+	if req.MaxDepth != nil {
+		maxDepth = *req.MaxDepth
+	}
+
+	post, err := s.postsRepo.GetByID(ctx, req.PostID, false)
 	if err != nil {
 		return nil, err
 	}
+	postWithComments.Post = post
 
-	for _, cc := range childrenComments {
-		commentMap[cc.ID] = &dtos.CommentWithReplies{
-			Comment: *cc,
-			Replies: []*dtos.CommentWithReplies{},
-		}
+	rootComments, err := s.commentsRepo.GetRootCommentsByPostID(ctx, post.ID, page)
+	if err != nil {
+		return nil, err
 	}
 
-	for _, c := range commentMap {
-		if c.ReplyTo != nil {
-			commentMap[*c.ReplyTo].Replies = append(commentMap[*c.ReplyTo].Replies, c)
+	// Позиция в ранжированной выборке задается и рейтингом комментария
+	cursor := func(c *models.Comment) string {
+		if page.Sort.Ranked() {
+			return pagination.EncodeRankedCursor(c.Rank(page.Sort), c.CreatedAt, c.ID)
 		}
+		return pagination.EncodeCursor(c.CreatedAt, c.ID)
 	}
 
-	paginatedComments := make([]*dtos.CommentWithReplies, len(rootComments))
+	rootComments, pageInfo := buildPage(rootComments, page, cursor)
+
+	nodes := make([]*dtos.CommentWithReplies, len(rootComments))
+	edges := make([]*dtos.CommentEdge, len(rootComments))
 	for i, rc := range rootComments {
-		paginatedComments[i] = commentMap[rc.ID]
+		nodes[i] = &dtos.CommentWithReplies{
+			Comment:        *rc,
+			RemainingDepth: maxDepth,
+		}
+		edges[i] = &dtos.CommentEdge{
+			Cursor: cursor(rc),
+			Node:   nodes[i],
+		}
+	}
+
+	err = loadReplies(ctx, s.commentsRepo, nodes)
+	if err != nil {
+		return nil, err
 	}
 
-	postWithComments.Comments = paginatedComments
+	postWithComments.Comments = dtos.CommentsConnection{
+		Edges:    edges,
+		PageInfo: pageInfo,
+	}
 
 	return &postWithComments, nil
 }
@@ -130,15 +290,29 @@ func (s *PostsService) DisableComments(ctx context.Context, userID, postID uuid.
 		return nil, err
 	}
 
-	if post.UserID != userID {
-		return nil, errs.ErrUnauthorized
+	err = s.checkCanToggleComments(ctx, post, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if post.DeletedAt != nil {
+		return nil, errs.ErrPostDeleted
 	}
 
+	wereCommentsAllowed := post.AreCommentsAllowed
+
 	post, err = s.postsRepo.DisableComments(ctx, postID)
 	if err != nil {
 		return nil, err
 	}
 
+	if post.AreCommentsAllowed != wereCommentsAllowed {
+		err = s.addCommentsToggled(ctx, post)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return post, nil
 }
 
@@ -173,14 +347,207 @@ func (s *PostsService) EnableComments(ctx context.Context, userID, postID uuid.U
 		return nil, err
 	}
 
-	if post.UserID != userID {
-		return nil, errs.ErrUnauthorized
+	err = s.checkCanToggleComments(ctx, post, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if post.DeletedAt != nil {
+		return nil, errs.ErrPostDeleted
 	}
 
+	wereCommentsAllowed := post.AreCommentsAllowed
+
 	post, err = s.postsRepo.EnableComments(ctx, postID)
 	if err != nil {
 		return nil, err
 	}
 
+	if post.AreCommentsAllowed != wereCommentsAllowed {
+		err = s.addCommentsToggled(ctx, post)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return post, nil
 }
+
+func (s *PostsService) addCommentsToggled(ctx context.Context, post *models.Post) error {
+	return addPostActivity(ctx, s.outboxRepo, &dtos.PostActivityEvent{
+		Type:               models.EventCommentsToggled,
+		PostID:             post.ID,
+		AreCommentsAllowed: post.AreCommentsAllowed,
+	})
+}
+
+// Комментарии к посту переключает его автор, а к посту в сообществе -
+// еще и модераторы сообщества
+func (s *PostsService) checkCanToggleComments(ctx context.Context, post *models.Post, userID uuid.UUID) error {
+	if post.UserID == userID {
+		return nil
+	}
+
+	if post.CommunityID == nil {
+		return errs.ErrUnauthorized
+	}
+
+	member, err := s.communitiesRepo.GetMember(ctx, *post.CommunityID, userID)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			return errs.ErrUnauthorized
+		}
+		return err
+	}
+
+	if !member.Role.CanModerate() {
+		return errs.ErrUnauthorized
+	}
+
+	return nil
+}
+
+// UpdatePost меняет заголовок, текст и/или теги поста. Не переданные поля остаются прежними
+func (s *PostsService) UpdatePost(ctx context.Context, req *dtos.UpdatePostRequest) (post *models.Post, err error) {
+	postTags, err := normalizeTags(req.Tags)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.txStarter.Begin(ctx)
+	if err != nil {
+		logger.Logger.Error("error starting transaction", zap.Error(err))
+		return nil, errs.ErrInternal
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback(ctx)
+			panic(p)
+		} else if err != nil {
+			if rollbackErr := tx.Rollback(ctx); rollbackErr != nil {
+				logger.Logger.Error("rollback failed", zap.Error(rollbackErr))
+				err = errs.ErrInternal
+			}
+		} else {
+			if commitErr := tx.Commit(ctx); commitErr != nil {
+				logger.Logger.Error("error committing transaction", zap.Error(commitErr))
+				err = errs.ErrInternal
+			}
+		}
+	}()
+
+	ctx = transactions.PutTxIntoContext(ctx, tx)
+
+	post, err = s.postsRepo.GetByID(ctx, req.PostID, true)
+	if err != nil {
+		return nil, err
+	}
+
+	if post.UserID != req.UserID {
+		return nil, errs.ErrUnauthorized
+	}
+
+	if post.DeletedAt != nil {
+		return nil, errs.ErrPostDeleted
+	}
+
+	title, content := post.Title, post.Content
+	if req.Title != nil {
+		title = *req.Title
+	}
+	if req.Content != nil {
+		content = *req.Content
+	}
+
+	// Смена тегов тоже считается правкой поста и обновляет updatedAt
+	tagsChanged := false
+	if req.Tags != nil {
+		currentTags, err := s.postsRepo.GetTags(ctx, []uuid.UUID{post.ID})
+		if err != nil {
+			return nil, err
+		}
+
+		sortedTags := slices.Sorted(slices.Values(postTags))
+		if !slices.Equal(sortedTags, currentTags[post.ID]) {
+			err = s.postsRepo.SetTags(ctx, post.ID, postTags)
+			if err != nil {
+				return nil, err
+			}
+			tagsChanged = true
+		}
+	}
+
+	if title == post.Title && content == post.Content && !tagsChanged {
+		return post, nil
+	}
+
+	return s.postsRepo.Update(ctx, post.ID, title, content)
+}
+
+// DeletePost помечает пост удаленным. Комментарии к нему сохраняются, а сам
+// пост отдается в состоянии "удален", чтобы ссылки на него не ломались
+func (s *PostsService) DeletePost(ctx context.Context, userID, postID uuid.UUID) (post *models.Post, err error) {
+	tx, err := s.txStarter.Begin(ctx)
+	if err != nil {
+		logger.Logger.Error("error starting transaction", zap.Error(err))
+		return nil, errs.ErrInternal
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback(ctx)
+			panic(p)
+		} else if err != nil {
+			if rollbackErr := tx.Rollback(ctx); rollbackErr != nil {
+				logger.Logger.Error("rollback failed", zap.Error(rollbackErr))
+				err = errs.ErrInternal
+			}
+		} else {
+			if commitErr := tx.Commit(ctx); commitErr != nil {
+				logger.Logger.Error("error committing transaction", zap.Error(commitErr))
+				err = errs.ErrInternal
+			}
+		}
+	}()
+
+	ctx = transactions.PutTxIntoContext(ctx, tx)
+
+	post, err = s.postsRepo.GetByID(ctx, postID, true)
+	if err != nil {
+		return nil, err
+	}
+
+	if post.UserID != userID {
+		return nil, errs.ErrUnauthorized
+	}
+
+	if post.DeletedAt != nil {
+		return post, nil
+	}
+
+	return s.postsRepo.SoftDelete(ctx, post.ID)
+}
+
+func (s *PostsService) CountByUserIDs(ctx context.Context, userIDs []uuid.UUID) (map[uuid.UUID]int32, error) {
+	return s.postsRepo.CountByUserIDs(ctx, userIDs)
+}
+
+func (s *PostsService) GetReactions(ctx context.Context, postIDs []uuid.UUID, viewerID *uuid.UUID) (map[uuid.UUID][]*models.ReactionCount, error) {
+	return s.postsRepo.GetReactions(ctx, postIDs, viewerID)
+}
+
+func (s *PostsService) GetTags(ctx context.Context, postIDs []uuid.UUID) (map[uuid.UUID][]string, error) {
+	return s.postsRepo.GetTags(ctx, postIDs)
+}
+
+// SearchTags подсказывает теги по началу. Пустой префикс дает самые
+// популярные теги
+func (s *PostsService) SearchTags(ctx context.Context, req *dtos.GetTagsRequest) ([]*models.Tag, error) {
+	limit := defaultTagsLimit
+	if req.First != nil {
+		limit = *req.First
+	}
+
+	return s.postsRepo.SearchTags(ctx, tags.NormalizePrefix(req.Prefix), limit)
+}
@@ -6,8 +6,11 @@ import (
 	"testing"
 	"time"
 
+	"github.com/Govorov1705/ozon-test/config"
 	"github.com/Govorov1705/ozon-test/internal/dtos"
+	"github.com/Govorov1705/ozon-test/internal/errs"
 	"github.com/Govorov1705/ozon-test/internal/models"
+	"github.com/Govorov1705/ozon-test/internal/pagination"
 	"github.com/Govorov1705/ozon-test/internal/repositories/mocks"
 	"github.com/Govorov1705/ozon-test/internal/services"
 	txMocks "github.com/Govorov1705/ozon-test/internal/transactions/mocks"
@@ -24,6 +27,7 @@ func TestPostsService_CreatePost(t *testing.T) {
 			ts *txMocks.MockTxStarter,
 			pr *mocks.MockPostsRepository,
 			cr *mocks.MockCommentsRepository,
+			cmr *mocks.MockCommunitiesRepository,
 		)
 		expectError bool
 	}
@@ -32,6 +36,11 @@ func TestPostsService_CreatePost(t *testing.T) {
 	title := "Test title"
 	content := "Test content"
 	areCommentsAllowed := false
+	postID := uuid.New()
+	communityID := uuid.New()
+
+	config.Cfg.MaxPostTags = 2
+	defer func() { config.Cfg.MaxPostTags = 0 }()
 
 	testCases := []testCase{
 		{
@@ -45,11 +54,13 @@ func TestPostsService_CreatePost(t *testing.T) {
 				ts *txMocks.MockTxStarter,
 				pr *mocks.MockPostsRepository,
 				cr *mocks.MockCommentsRepository,
+				cmr *mocks.MockCommunitiesRepository,
 			) {
 				pr.On(
 					"Add",
 					mock.Anything,
 					userID,
+					(*uuid.UUID)(nil),
 					title,
 					content,
 					true,
@@ -77,11 +88,13 @@ func TestPostsService_CreatePost(t *testing.T) {
 				ts *txMocks.MockTxStarter,
 				pr *mocks.MockPostsRepository,
 				cr *mocks.MockCommentsRepository,
+				cmr *mocks.MockCommunitiesRepository,
 			) {
 				pr.On(
 					"Add",
 					mock.Anything,
 					userID,
+					(*uuid.UUID)(nil),
 					title,
 					content,
 					false,
@@ -109,11 +122,13 @@ func TestPostsService_CreatePost(t *testing.T) {
 				ts *txMocks.MockTxStarter,
 				pr *mocks.MockPostsRepository,
 				cr *mocks.MockCommentsRepository,
+				cmr *mocks.MockCommunitiesRepository,
 			) {
 				pr.On(
 					"Add",
 					mock.Anything,
 					userID,
+					(*uuid.UUID)(nil),
 					title,
 					content,
 					true,
@@ -123,6 +138,223 @@ func TestPostsService_CreatePost(t *testing.T) {
 			},
 			expectError: true,
 		},
+		{
+			name: "OK (with tags)",
+			input: &dtos.CreatePostRequest{
+				UserID:  userID,
+				Title:   title,
+				Content: content,
+				Tags:    []string{"#GoLang", "golang", "Code Review"},
+			},
+			setupMocks: func(
+				ts *txMocks.MockTxStarter,
+				pr *mocks.MockPostsRepository,
+				cr *mocks.MockCommentsRepository,
+				cmr *mocks.MockCommunitiesRepository,
+			) {
+				mockTx := &txMocks.MockTx{}
+
+				ts.On("Begin", mock.Anything).Return(mockTx, nil)
+
+				mockTx.On("Commit", mock.Anything).Return(nil)
+
+				pr.On(
+					"Add",
+					mock.Anything,
+					userID,
+					(*uuid.UUID)(nil),
+					title,
+					content,
+					true,
+				).Return(
+					&models.Post{
+						ID:        postID,
+						UserID:    userID,
+						Title:     title,
+						Content:   content,
+						CreatedAt: time.Now(),
+					}, nil,
+				)
+
+				pr.On(
+					"SetTags",
+					mock.Anything,
+					postID,
+					[]string{"golang", "code-review"},
+				).Return(nil)
+			},
+			expectError: false,
+		},
+		{
+			name: "postsRepo.SetTags error",
+			input: &dtos.CreatePostRequest{
+				UserID:  userID,
+				Title:   title,
+				Content: content,
+				Tags:    []string{"golang"},
+			},
+			setupMocks: func(
+				ts *txMocks.MockTxStarter,
+				pr *mocks.MockPostsRepository,
+				cr *mocks.MockCommentsRepository,
+				cmr *mocks.MockCommunitiesRepository,
+			) {
+				mockTx := &txMocks.MockTx{}
+
+				ts.On("Begin", mock.Anything).Return(mockTx, nil)
+
+				mockTx.On("Rollback", mock.Anything).Return(nil)
+
+				pr.On(
+					"Add",
+					mock.Anything,
+					userID,
+					(*uuid.UUID)(nil),
+					title,
+					content,
+					true,
+				).Return(
+					&models.Post{ID: postID, UserID: userID}, nil,
+				)
+
+				pr.On("SetTags", mock.Anything, postID, []string{"golang"}).Return(errs.ErrInternal)
+			},
+			expectError: true,
+		},
+		{
+			name: "too many tags",
+			input: &dtos.CreatePostRequest{
+				UserID:  userID,
+				Title:   title,
+				Content: content,
+				Tags:    []string{"golang", "rust", "sql"},
+			},
+			setupMocks: func(
+				ts *txMocks.MockTxStarter,
+				pr *mocks.MockPostsRepository,
+				cr *mocks.MockCommentsRepository,
+				cmr *mocks.MockCommunitiesRepository,
+			) {
+			},
+			expectError: true,
+		},
+		{
+			name: "invalid tag",
+			input: &dtos.CreatePostRequest{
+				UserID:  userID,
+				Title:   title,
+				Content: content,
+				Tags:    []string{"golang", "c#"},
+			},
+			setupMocks: func(
+				ts *txMocks.MockTxStarter,
+				pr *mocks.MockPostsRepository,
+				cr *mocks.MockCommentsRepository,
+				cmr *mocks.MockCommunitiesRepository,
+			) {
+			},
+			expectError: true,
+		},
+		{
+			name: "OK (community default)",
+			input: &dtos.CreatePostRequest{
+				UserID:      userID,
+				Title:       title,
+				Content:     content,
+				CommunityID: &communityID,
+			},
+			setupMocks: func(
+				ts *txMocks.MockTxStarter,
+				pr *mocks.MockPostsRepository,
+				cr *mocks.MockCommentsRepository,
+				cmr *mocks.MockCommunitiesRepository,
+			) {
+				mockTx := &txMocks.MockTx{}
+
+				ts.On("Begin", mock.Anything).Return(mockTx, nil)
+
+				mockTx.On("Commit", mock.Anything).Return(nil)
+
+				cmr.On("GetByID", mock.Anything, communityID, true).Return(
+					&models.Community{ID: communityID, AreCommentsAllowed: false}, nil,
+				)
+				cmr.On("GetMember", mock.Anything, communityID, userID).Return(
+					&models.CommunityMember{
+						CommunityID: communityID,
+						UserID:      userID,
+						Role:        models.CommunityRoleMember,
+					}, nil,
+				)
+
+				pr.On(
+					"Add",
+					mock.Anything,
+					userID,
+					&communityID,
+					title,
+					content,
+					false,
+				).Return(
+					&models.Post{ID: postID, UserID: userID, CommunityID: &communityID}, nil,
+				)
+			},
+			expectError: false,
+		},
+		{
+			name: "not a community member",
+			input: &dtos.CreatePostRequest{
+				UserID:      userID,
+				Title:       title,
+				Content:     content,
+				CommunityID: &communityID,
+			},
+			setupMocks: func(
+				ts *txMocks.MockTxStarter,
+				pr *mocks.MockPostsRepository,
+				cr *mocks.MockCommentsRepository,
+				cmr *mocks.MockCommunitiesRepository,
+			) {
+				mockTx := &txMocks.MockTx{}
+
+				ts.On("Begin", mock.Anything).Return(mockTx, nil)
+
+				mockTx.On("Rollback", mock.Anything).Return(nil)
+
+				cmr.On("GetByID", mock.Anything, communityID, true).Return(
+					&models.Community{ID: communityID, AreCommentsAllowed: true}, nil,
+				)
+				cmr.On("GetMember", mock.Anything, communityID, userID).Return(
+					nil, errs.ErrNotFound,
+				)
+			},
+			expectError: true,
+		},
+		{
+			name: "community not found",
+			input: &dtos.CreatePostRequest{
+				UserID:      userID,
+				Title:       title,
+				Content:     content,
+				CommunityID: &communityID,
+			},
+			setupMocks: func(
+				ts *txMocks.MockTxStarter,
+				pr *mocks.MockPostsRepository,
+				cr *mocks.MockCommentsRepository,
+				cmr *mocks.MockCommunitiesRepository,
+			) {
+				mockTx := &txMocks.MockTx{}
+
+				ts.On("Begin", mock.Anything).Return(mockTx, nil)
+
+				mockTx.On("Rollback", mock.Anything).Return(nil)
+
+				cmr.On("GetByID", mock.Anything, communityID, true).Return(
+					nil, errs.ErrNotFound,
+				)
+			},
+			expectError: true,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -130,17 +362,22 @@ func TestPostsService_CreatePost(t *testing.T) {
 			mockTxStarter := txMocks.NewMockTxStarter(t)
 			mockPostsRepo := mocks.NewMockPostsRepository(t)
 			mockCommentsRepo := mocks.NewMockCommentsRepository(t)
+			mockOutboxRepo := mocks.NewMockOutboxRepository(t)
+			mockCommunitiesRepo := mocks.NewMockCommunitiesRepository(t)
 
 			tc.setupMocks(
 				mockTxStarter,
 				mockPostsRepo,
 				mockCommentsRepo,
+				mockCommunitiesRepo,
 			)
 
 			postsService := services.NewPostsService(
 				mockTxStarter,
 				mockPostsRepo,
 				mockCommentsRepo,
+				mockOutboxRepo,
+				mockCommunitiesRepo,
 			)
 
 			post, err := postsService.CreatePost(context.Background(), tc.input)
@@ -156,6 +393,7 @@ func TestPostsService_CreatePost(t *testing.T) {
 			mockTxStarter.AssertExpectations(t)
 			mockPostsRepo.AssertExpectations(t)
 			mockCommentsRepo.AssertExpectations(t)
+			mockCommunitiesRepo.AssertExpectations(t)
 		})
 	}
 }
@@ -235,6 +473,8 @@ func TestPostsService_GetAllPosts(t *testing.T) {
 			mockTxStarter := txMocks.NewMockTxStarter(t)
 			mockPostsRepo := mocks.NewMockPostsRepository(t)
 			mockCommentsRepo := mocks.NewMockCommentsRepository(t)
+			mockOutboxRepo := mocks.NewMockOutboxRepository(t)
+			mockCommunitiesRepo := mocks.NewMockCommunitiesRepository(t)
 
 			tc.setupMocks(
 				mockTxStarter,
@@ -246,6 +486,8 @@ func TestPostsService_GetAllPosts(t *testing.T) {
 				mockTxStarter,
 				mockPostsRepo,
 				mockCommentsRepo,
+				mockOutboxRepo,
+				mockCommunitiesRepo,
 			)
 
 			posts, err := postsService.GetAllPosts(context.Background())
@@ -267,162 +509,590 @@ func TestPostsService_GetAllPosts(t *testing.T) {
 	}
 }
 
-func TestPostsService_GetPostWithComments(t *testing.T) {
-	type testCase struct {
-		name       string
-		postID     uuid.UUID
-		limit      *int32
-		offset     *int32
-		setupMocks func(
-			ts *txMocks.MockTxStarter,
-			pr *mocks.MockPostsRepository,
-			cr *mocks.MockCommentsRepository,
-		)
-		expectError bool
-	}
-
-	postID := uuid.New()
-	userID := uuid.New()
-	title := "Test title"
-	content := "Test content"
-
-	rootCommentID1 := uuid.New()
-	rootCommentID2 := uuid.New()
-
-	rootComments := []*models.Comment{
-		{
-			ID:        rootCommentID1,
-			PostID:    postID,
-			UserID:    uuid.New(),
-			RootID:    rootCommentID1,
-			ReplyTo:   nil,
-			Content:   "Root comment 1",
-			CreatedAt: time.Now(),
-		},
-		{
-			ID:        rootCommentID2,
-			PostID:    postID,
-			UserID:    uuid.New(),
-			RootID:    rootCommentID2,
-			ReplyTo:   nil,
-			Content:   "Root comment 2",
-			CreatedAt: time.Now(),
-		},
-	}
-
-	replyCommentID1_1 := uuid.New()
-	replyCommentID1_2 := uuid.New()
-	replyCommentID2_1 := uuid.New()
+func TestPostsService_GetPosts(t *testing.T) {
+	now := time.Now()
 
-	childrenComments := []*models.Comment{
+	mockPosts := []*models.Post{
 		{
-			ID:        replyCommentID1_1,
-			PostID:    postID,
-			UserID:    uuid.New(),
-			RootID:    rootCommentID1,
-			ReplyTo:   &rootCommentID1,
-			Content:   "1st reply to root 1",
-			CreatedAt: time.Now(),
+			ID:                 uuid.New(),
+			UserID:             uuid.New(),
+			Title:              "Post 3 title",
+			Content:            "Post 3 content",
+			CreatedAt:          now,
+			AreCommentsAllowed: true,
 		},
 		{
-			ID:        replyCommentID1_2,
-			PostID:    postID,
-			UserID:    uuid.New(),
-			RootID:    rootCommentID1,
-			ReplyTo:   &replyCommentID1_1,
-			Content:   "1st reply to 1st reply to root 1",
-			CreatedAt: time.Now(),
+			ID:                 uuid.New(),
+			UserID:             uuid.New(),
+			Title:              "Post 2 title",
+			Content:            "Post 2 content",
+			CreatedAt:          now.Add(-time.Minute),
+			AreCommentsAllowed: true,
 		},
 		{
-			ID:        replyCommentID2_1,
-			PostID:    postID,
-			UserID:    uuid.New(),
-			RootID:    rootCommentID2,
-			ReplyTo:   &rootCommentID2,
-			Content:   "1st reply to root 2",
-			CreatedAt: time.Now(),
+			ID:                 uuid.New(),
+			UserID:             uuid.New(),
+			Title:              "Post 1 title",
+			Content:            "Post 1 content",
+			CreatedAt:          now.Add(-2 * time.Minute),
+			AreCommentsAllowed: false,
 		},
 	}
 
 	int32Ptr := func(i int32) *int32 { return &i }
+	strPtr := func(s string) *string { return &s }
+
+	afterCursor := pagination.EncodeCursor(mockPosts[0].CreatedAt, mockPosts[0].ID)
+
+	type testCase struct {
+		name       string
+		input      *dtos.GetPostsRequest
+		setupMocks func(
+			ts *txMocks.MockTxStarter,
+			pr *mocks.MockPostsRepository,
+			cr *mocks.MockCommentsRepository,
+		)
+		expectError             bool
+		expectedPosts           []*models.Post
+		expectedHasNextPage     bool
+		expectedHasPreviousPage bool
+	}
 
 	testCases := []testCase{
 		{
-			name:   "OK (post without comments)",
-			postID: postID,
-			limit:  int32Ptr(10),
-			offset: int32Ptr(0),
+			name:  "OK (first page with more posts)",
+			input: &dtos.GetPostsRequest{First: int32Ptr(2)},
+			setupMocks: func(
+				ts *txMocks.MockTxStarter,
+				pr *mocks.MockPostsRepository,
+				cr *mocks.MockCommentsRepository,
+			) {
+				pr.On("GetPage", mock.Anything, &pagination.Page{Limit: 3}).Return(mockPosts, nil)
+			},
+			expectError:             false,
+			expectedPosts:           mockPosts[:2],
+			expectedHasNextPage:     true,
+			expectedHasPreviousPage: false,
+		},
+		{
+			name:  "OK (page after cursor)",
+			input: &dtos.GetPostsRequest{First: int32Ptr(2), After: &afterCursor},
+			setupMocks: func(
+				ts *txMocks.MockTxStarter,
+				pr *mocks.MockPostsRepository,
+				cr *mocks.MockCommentsRepository,
+			) {
+				pr.On("GetPage", mock.Anything, mock.MatchedBy(func(p *pagination.Page) bool {
+					return p.After != nil && p.After.ID == mockPosts[0].ID && p.Limit == 3
+				})).Return(mockPosts[1:], nil)
+			},
+			expectError:             false,
+			expectedPosts:           mockPosts[1:],
+			expectedHasNextPage:     false,
+			expectedHasPreviousPage: true,
+		},
+		{
+			name:  "OK (last posts)",
+			input: &dtos.GetPostsRequest{Last: int32Ptr(2)},
+			setupMocks: func(
+				ts *txMocks.MockTxStarter,
+				pr *mocks.MockPostsRepository,
+				cr *mocks.MockCommentsRepository,
+			) {
+				pr.On("GetPage", mock.Anything, &pagination.Page{Limit: 3, Backward: true}).Return(
+					[]*models.Post{mockPosts[2], mockPosts[1], mockPosts[0]}, nil,
+				)
+			},
+			expectError:             false,
+			expectedPosts:           mockPosts[1:],
+			expectedHasNextPage:     false,
+			expectedHasPreviousPage: true,
+		},
+		{
+			name:  "first and last together",
+			input: &dtos.GetPostsRequest{First: int32Ptr(2), Last: int32Ptr(2)},
+			setupMocks: func(
+				ts *txMocks.MockTxStarter,
+				pr *mocks.MockPostsRepository,
+				cr *mocks.MockCommentsRepository,
+			) {
+			},
+			expectError: true,
+		},
+		{
+			name:  "invalid cursor",
+			input: &dtos.GetPostsRequest{First: int32Ptr(2), After: strPtr("invalid")},
+			setupMocks: func(
+				ts *txMocks.MockTxStarter,
+				pr *mocks.MockPostsRepository,
+				cr *mocks.MockCommentsRepository,
+			) {
+			},
+			expectError: true,
+		},
+		{
+			name:  "postsRepo.GetPage error",
+			input: &dtos.GetPostsRequest{},
+			setupMocks: func(
+				ts *txMocks.MockTxStarter,
+				pr *mocks.MockPostsRepository,
+				cr *mocks.MockCommentsRepository,
+			) {
+				pr.On("GetPage", mock.Anything, mock.Anything).Return(nil, errors.New("some error"))
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockTxStarter := txMocks.NewMockTxStarter(t)
+			mockPostsRepo := mocks.NewMockPostsRepository(t)
+			mockCommentsRepo := mocks.NewMockCommentsRepository(t)
+			mockOutboxRepo := mocks.NewMockOutboxRepository(t)
+			mockCommunitiesRepo := mocks.NewMockCommunitiesRepository(t)
+
+			tc.setupMocks(
+				mockTxStarter,
+				mockPostsRepo,
+				mockCommentsRepo,
+			)
+
+			postsService := services.NewPostsService(
+				mockTxStarter,
+				mockPostsRepo,
+				mockCommentsRepo,
+				mockOutboxRepo,
+				mockCommunitiesRepo,
+			)
+
+			postsConnection, err := postsService.GetPosts(context.Background(), tc.input)
+
+			if tc.expectError {
+				assert.Error(t, err)
+				assert.Nil(t, postsConnection)
+			} else {
+				assert.NoError(t, err)
+				assert.Len(t, postsConnection.Edges, len(tc.expectedPosts))
+				for i, e := range postsConnection.Edges {
+					assert.Equal(t, tc.expectedPosts[i], e.Node)
+					assert.Equal(t, pagination.EncodeCursor(e.Node.CreatedAt, e.Node.ID), e.Cursor)
+				}
+				assert.Equal(t, tc.expectedHasNextPage, postsConnection.PageInfo.HasNextPage)
+				assert.Equal(t, tc.expectedHasPreviousPage, postsConnection.PageInfo.HasPreviousPage)
+			}
+
+			mockTxStarter.AssertExpectations(t)
+			mockPostsRepo.AssertExpectations(t)
+			mockCommentsRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPostsService_GetPostWithComments(t *testing.T) {
+	type testCase struct {
+		name       string
+		postID     uuid.UUID
+		first      *int32
+		after      *string
+		maxDepth   *int32
+		setupMocks func(
+			ts *txMocks.MockTxStarter,
+			pr *mocks.MockPostsRepository,
+			cr *mocks.MockCommentsRepository,
+		)
+		expectError bool
+	}
+
+	postID := uuid.New()
+	userID := uuid.New()
+	title := "Test title"
+	content := "Test content"
+
+	post := &models.Post{
+		ID:                 postID,
+		UserID:             userID,
+		Title:              title,
+		Content:            content,
+		CreatedAt:          time.Now(),
+		AreCommentsAllowed: true,
+	}
+
+	rootCommentID1 := uuid.New()
+	rootCommentID2 := uuid.New()
+
+	rootComments := []*models.Comment{
+		{
+			ID:        rootCommentID1,
+			PostID:    postID,
+			UserID:    uuid.New(),
+			RootID:    rootCommentID1,
+			ReplyTo:   nil,
+			Content:   "Root comment 1",
+			CreatedAt: time.Now(),
+		},
+		{
+			ID:        rootCommentID2,
+			PostID:    postID,
+			UserID:    uuid.New(),
+			RootID:    rootCommentID2,
+			ReplyTo:   nil,
+			Content:   "Root comment 2",
+			CreatedAt: time.Now(),
+		},
+	}
+
+	replyCommentID1_1 := uuid.New()
+	replyCommentID1_2 := uuid.New()
+	replyCommentID2_1 := uuid.New()
+
+	firstLevelReplies := []*models.Comment{
+		{
+			ID:        replyCommentID1_1,
+			PostID:    postID,
+			UserID:    uuid.New(),
+			RootID:    rootCommentID1,
+			ReplyTo:   &rootCommentID1,
+			Content:   "1st reply to root 1",
+			CreatedAt: time.Now(),
+		},
+		{
+			ID:        replyCommentID2_1,
+			PostID:    postID,
+			UserID:    uuid.New(),
+			RootID:    rootCommentID2,
+			ReplyTo:   &rootCommentID2,
+			Content:   "1st reply to root 2",
+			CreatedAt: time.Now(),
+		},
+	}
+
+	secondLevelReplies := []*models.Comment{
+		{
+			ID:        replyCommentID1_2,
+			PostID:    postID,
+			UserID:    uuid.New(),
+			RootID:    rootCommentID1,
+			ReplyTo:   &replyCommentID1_1,
+			Content:   "1st reply to 1st reply to root 1",
+			CreatedAt: time.Now(),
+		},
+	}
+
+	int32Ptr := func(i int32) *int32 { return &i }
+	invalidCursor := "invalid"
+
+	testCases := []testCase{
+		{
+			name:   "OK (post without comments)",
+			postID: postID,
+			first:  int32Ptr(10),
+			setupMocks: func(
+				ts *txMocks.MockTxStarter,
+				pr *mocks.MockPostsRepository,
+				cr *mocks.MockCommentsRepository,
+			) {
+				pr.On("GetByID", mock.Anything, postID, false).Return(post, nil)
+
+				cr.On("GetRootCommentsByPostID", mock.Anything, postID, &pagination.Page{Limit: 11}).Return(
+					[]*models.Comment{}, nil,
+				)
+			},
+			expectError: false,
+		},
+		{
+			name:   "OK (post with root comments only)",
+			postID: postID,
+			first:  int32Ptr(10),
+			setupMocks: func(
+				ts *txMocks.MockTxStarter,
+				pr *mocks.MockPostsRepository,
+				cr *mocks.MockCommentsRepository,
+			) {
+				pr.On("GetByID", mock.Anything, postID, false).Return(post, nil)
+
+				cr.On("GetRootCommentsByPostID", mock.Anything, postID, &pagination.Page{Limit: 11}).Return(
+					rootComments, nil,
+				)
+
+				cr.On("CountRepliesByParentIDs", mock.Anything, []uuid.UUID{rootCommentID1, rootCommentID2}).Return(
+					map[uuid.UUID]int32{rootCommentID1: 0, rootCommentID2: 0}, nil,
+				)
+			},
+			expectError: false,
+		},
+		{
+			name:   "OK (post with nested comments)",
+			postID: postID,
+			first:  int32Ptr(10),
+			setupMocks: func(
+				ts *txMocks.MockTxStarter,
+				pr *mocks.MockPostsRepository,
+				cr *mocks.MockCommentsRepository,
+			) {
+				pr.On("GetByID", mock.Anything, postID, false).Return(post, nil)
+
+				cr.On("GetRootCommentsByPostID", mock.Anything, postID, &pagination.Page{Limit: 11}).Return(
+					rootComments, nil,
+				)
+
+				cr.On("CountRepliesByParentIDs", mock.Anything, []uuid.UUID{rootCommentID1, rootCommentID2}).Return(
+					map[uuid.UUID]int32{rootCommentID1: 1, rootCommentID2: 1}, nil,
+				)
+
+				cr.On("GetRepliesByParentIDs", mock.Anything, []uuid.UUID{rootCommentID1, rootCommentID2}, pagination.DefaultRepliesPageSize).Return(
+					firstLevelReplies, nil,
+				)
+
+				cr.On("CountRepliesByParentIDs", mock.Anything, []uuid.UUID{replyCommentID1_1, replyCommentID2_1}).Return(
+					map[uuid.UUID]int32{replyCommentID1_1: 1, replyCommentID2_1: 0}, nil,
+				)
+
+				cr.On("GetRepliesByParentIDs", mock.Anything, []uuid.UUID{replyCommentID1_1}, pagination.DefaultRepliesPageSize).Return(
+					secondLevelReplies, nil,
+				)
+
+				cr.On("CountRepliesByParentIDs", mock.Anything, []uuid.UUID{replyCommentID1_2}).Return(
+					map[uuid.UUID]int32{replyCommentID1_2: 0}, nil,
+				)
+			},
+			expectError: false,
+		},
+		{
+			name:     "OK (replies deeper than maxDepth are only counted)",
+			postID:   postID,
+			first:    int32Ptr(10),
+			maxDepth: int32Ptr(1),
+			setupMocks: func(
+				ts *txMocks.MockTxStarter,
+				pr *mocks.MockPostsRepository,
+				cr *mocks.MockCommentsRepository,
+			) {
+				pr.On("GetByID", mock.Anything, postID, false).Return(post, nil)
+
+				cr.On("GetRootCommentsByPostID", mock.Anything, postID, &pagination.Page{Limit: 11}).Return(
+					rootComments, nil,
+				)
+
+				cr.On("CountRepliesByParentIDs", mock.Anything, []uuid.UUID{rootCommentID1, rootCommentID2}).Return(
+					map[uuid.UUID]int32{rootCommentID1: 1, rootCommentID2: 1}, nil,
+				)
+
+				cr.On("GetRepliesByParentIDs", mock.Anything, []uuid.UUID{rootCommentID1, rootCommentID2}, pagination.DefaultRepliesPageSize).Return(
+					firstLevelReplies, nil,
+				)
+
+				cr.On("CountRepliesByParentIDs", mock.Anything, []uuid.UUID{replyCommentID1_1, replyCommentID2_1}).Return(
+					map[uuid.UUID]int32{replyCommentID1_1: 1, replyCommentID2_1: 0}, nil,
+				)
+			},
+			expectError: false,
+		},
+		{
+			name:   "OK (more root comments than requested)",
+			postID: postID,
+			first:  int32Ptr(1),
+			setupMocks: func(
+				ts *txMocks.MockTxStarter,
+				pr *mocks.MockPostsRepository,
+				cr *mocks.MockCommentsRepository,
+			) {
+				pr.On("GetByID", mock.Anything, postID, false).Return(post, nil)
+
+				cr.On("GetRootCommentsByPostID", mock.Anything, postID, &pagination.Page{Limit: 2}).Return(
+					rootComments, nil,
+				)
+
+				cr.On("CountRepliesByParentIDs", mock.Anything, []uuid.UUID{rootCommentID1}).Return(
+					map[uuid.UUID]int32{rootCommentID1: 0}, nil,
+				)
+			},
+			expectError: false,
+		},
+		{
+			name:   "invalid cursor",
+			postID: postID,
+			first:  int32Ptr(10),
+			after:  &invalidCursor,
+			setupMocks: func(
+				ts *txMocks.MockTxStarter,
+				pr *mocks.MockPostsRepository,
+				cr *mocks.MockCommentsRepository,
+			) {
+			},
+			expectError: true,
+		},
+		{
+			name:   "postsRepo.GetByID error",
+			postID: postID,
+			first:  int32Ptr(10),
 			setupMocks: func(
 				ts *txMocks.MockTxStarter,
 				pr *mocks.MockPostsRepository,
 				cr *mocks.MockCommentsRepository,
 			) {
 				pr.On("GetByID", mock.Anything, postID, false).Return(
-					&models.Post{
-						ID:                 postID,
-						UserID:             userID,
-						Title:              title,
-						Content:            content,
-						CreatedAt:          time.Now(),
-						AreCommentsAllowed: true,
-					}, nil,
+					nil, errors.New("some error"),
+				)
+			},
+			expectError: true,
+		},
+		{
+			name:   "commentsRepo.GetRootCommentsByPostID error",
+			postID: postID,
+			first:  int32Ptr(10),
+			setupMocks: func(
+				ts *txMocks.MockTxStarter,
+				pr *mocks.MockPostsRepository,
+				cr *mocks.MockCommentsRepository,
+			) {
+				pr.On("GetByID", mock.Anything, postID, false).Return(post, nil)
+
+				cr.On("GetRootCommentsByPostID", mock.Anything, postID, &pagination.Page{Limit: 11}).Return(
+					nil, errors.New("some error"),
 				)
+			},
+			expectError: true,
+		},
+		{
+			name:   "commentsRepo.CountRepliesByParentIDs error",
+			postID: postID,
+			first:  int32Ptr(10),
+			setupMocks: func(
+				ts *txMocks.MockTxStarter,
+				pr *mocks.MockPostsRepository,
+				cr *mocks.MockCommentsRepository,
+			) {
+				pr.On("GetByID", mock.Anything, postID, false).Return(post, nil)
 
-				cr.On("GetRootCommentsByPostID", mock.Anything, postID, int32Ptr(10), int32Ptr(0)).Return(
-					[]*models.Comment{}, nil,
+				cr.On("GetRootCommentsByPostID", mock.Anything, postID, &pagination.Page{Limit: 11}).Return(
+					rootComments, nil,
 				)
 
-				cr.On("GetChildrenCommentsByRootIDs", mock.Anything, []*uuid.UUID{}).Return(
-					[]*models.Comment{}, nil,
+				cr.On("CountRepliesByParentIDs", mock.Anything, mock.Anything).Return(
+					nil, errors.New("some error"),
 				)
 			},
-			expectError: false,
+			expectError: true,
 		},
 		{
-			name:   "OK (post with root comments only)",
+			name:   "commentsRepo.GetRepliesByParentIDs error",
 			postID: postID,
-			limit:  int32Ptr(10),
-			offset: int32Ptr(0),
+			first:  int32Ptr(10),
 			setupMocks: func(
 				ts *txMocks.MockTxStarter,
 				pr *mocks.MockPostsRepository,
 				cr *mocks.MockCommentsRepository,
 			) {
-				pr.On("GetByID", mock.Anything, postID, false).Return(
-					&models.Post{
-						ID:                 postID,
-						UserID:             userID,
-						Title:              title,
-						Content:            content,
-						CreatedAt:          time.Now(),
-						AreCommentsAllowed: true,
-					}, nil,
-				)
+				pr.On("GetByID", mock.Anything, postID, false).Return(post, nil)
 
-				cr.On("GetRootCommentsByPostID", mock.Anything, postID, int32Ptr(10), int32Ptr(0)).Return(
+				cr.On("GetRootCommentsByPostID", mock.Anything, postID, &pagination.Page{Limit: 11}).Return(
 					rootComments, nil,
 				)
 
-				cr.On("GetChildrenCommentsByRootIDs", mock.Anything, []*uuid.UUID{&rootCommentID1, &rootCommentID2}).Return(
-					[]*models.Comment{}, nil,
+				cr.On("CountRepliesByParentIDs", mock.Anything, mock.Anything).Return(
+					map[uuid.UUID]int32{rootCommentID1: 1, rootCommentID2: 0}, nil,
+				)
+
+				cr.On("GetRepliesByParentIDs", mock.Anything, mock.Anything, mock.Anything).Return(
+					nil, errors.New("some error"),
 				)
 			},
-			expectError: false,
+			expectError: true,
 		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockTxStarter := txMocks.NewMockTxStarter(t)
+			mockPostsRepo := mocks.NewMockPostsRepository(t)
+			mockCommentsRepo := mocks.NewMockCommentsRepository(t)
+			mockOutboxRepo := mocks.NewMockOutboxRepository(t)
+			mockCommunitiesRepo := mocks.NewMockCommunitiesRepository(t)
+
+			tc.setupMocks(
+				mockTxStarter,
+				mockPostsRepo,
+				mockCommentsRepo,
+			)
+
+			postsService := services.NewPostsService(
+				mockTxStarter,
+				mockPostsRepo,
+				mockCommentsRepo,
+				mockOutboxRepo,
+				mockCommunitiesRepo,
+			)
+
+			postWithComments, err := postsService.GetPostWithComments(
+				context.Background(),
+				&dtos.GetPostWithCommentsRequest{
+					PostID:   tc.postID,
+					First:    tc.first,
+					After:    tc.after,
+					MaxDepth: tc.maxDepth,
+				},
+			)
+
+			if tc.expectError {
+				assert.Error(t, err)
+				assert.Nil(t, postWithComments)
+			} else {
+				assert.NoError(t, err)
+				assert.NotNil(t, postWithComments)
+			}
+
+			mockTxStarter.AssertExpectations(t)
+			mockPostsRepo.AssertExpectations(t)
+			mockCommentsRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPostsService_DisableComments(t *testing.T) {
+	type testCase struct {
+		name       string
+		userID     uuid.UUID
+		postID     uuid.UUID
+		setupMocks func(
+			ts *txMocks.MockTxStarter,
+			pr *mocks.MockPostsRepository,
+			cr *mocks.MockCommentsRepository,
+			or *mocks.MockOutboxRepository,
+			cmr *mocks.MockCommunitiesRepository,
+		)
+		expectError bool
+	}
+
+	ownerUserID := uuid.New()
+	otherUserID := uuid.New()
+	postID := uuid.New()
+	communityID := uuid.New()
+	title := "Test title"
+	content := "Test content"
+
+	testCases := []testCase{
 		{
-			name:   "OK (post with nested comments)",
+			name:   "OK",
+			userID: ownerUserID,
 			postID: postID,
-			limit:  int32Ptr(10),
-			offset: int32Ptr(0),
 			setupMocks: func(
 				ts *txMocks.MockTxStarter,
 				pr *mocks.MockPostsRepository,
 				cr *mocks.MockCommentsRepository,
+				or *mocks.MockOutboxRepository,
+				cmr *mocks.MockCommunitiesRepository,
 			) {
-				pr.On("GetByID", mock.Anything, postID, false).Return(
+				mockTx := &txMocks.MockTx{}
+
+				ts.On("Begin", mock.Anything).Return(mockTx, nil)
+
+				mockTx.On("Commit", mock.Anything).Return(nil)
+
+				pr.On("GetByID", mock.Anything, postID, true).Return(
 					&models.Post{
 						ID:                 postID,
-						UserID:             userID,
+						UserID:             ownerUserID,
 						Title:              title,
 						Content:            content,
 						CreatedAt:          time.Now(),
@@ -430,86 +1100,249 @@ func TestPostsService_GetPostWithComments(t *testing.T) {
 					}, nil,
 				)
 
-				cr.On("GetRootCommentsByPostID", mock.Anything, postID, int32Ptr(10), int32Ptr(0)).Return(
-					rootComments, nil,
+				pr.On("DisableComments", mock.Anything, postID).Return(
+					&models.Post{
+						ID:                 postID,
+						UserID:             ownerUserID,
+						Title:              title,
+						Content:            content,
+						CreatedAt:          time.Now(),
+						AreCommentsAllowed: false,
+					}, nil,
 				)
+				or.On(
+					"Add",
+					mock.Anything,
+					mock.AnythingOfType("uuid.UUID"),
+					models.EventCommentsToggled,
+					mock.AnythingOfType("[]uint8"),
+				).Return(nil)
+			},
+			expectError: false,
+		},
+		{
+			name:   "OK (already disabled)",
+			userID: ownerUserID,
+			postID: postID,
+			setupMocks: func(
+				ts *txMocks.MockTxStarter,
+				pr *mocks.MockPostsRepository,
+				cr *mocks.MockCommentsRepository,
+				or *mocks.MockOutboxRepository,
+				cmr *mocks.MockCommunitiesRepository,
+			) {
+				mockTx := &txMocks.MockTx{}
 
-				cr.On("GetChildrenCommentsByRootIDs", mock.Anything, []*uuid.UUID{&rootCommentID1, &rootCommentID2}).Return(
-					childrenComments, nil,
-				)
+				ts.On("Begin", mock.Anything).Return(mockTx, nil)
+
+				mockTx.On("Commit", mock.Anything).Return(nil)
+
+				post := &models.Post{
+					ID:                 postID,
+					UserID:             ownerUserID,
+					Title:              title,
+					Content:            content,
+					CreatedAt:          time.Now(),
+					AreCommentsAllowed: false,
+				}
+
+				pr.On("GetByID", mock.Anything, postID, true).Return(post, nil)
+				pr.On("DisableComments", mock.Anything, postID).Return(post, nil)
 			},
 			expectError: false,
 		},
+		{
+			name:   "error starting transaction",
+			userID: ownerUserID,
+			postID: postID,
+			setupMocks: func(
+				ts *txMocks.MockTxStarter,
+				pr *mocks.MockPostsRepository,
+				cr *mocks.MockCommentsRepository,
+				or *mocks.MockOutboxRepository,
+				cmr *mocks.MockCommunitiesRepository,
+			) {
+				ts.On("Begin", mock.Anything).Return(nil, errors.New("some error"))
+			},
+			expectError: true,
+		},
 		{
 			name:   "postsRepo.GetByID error",
+			userID: ownerUserID,
 			postID: postID,
-			limit:  int32Ptr(10),
-			offset: int32Ptr(0),
 			setupMocks: func(
 				ts *txMocks.MockTxStarter,
 				pr *mocks.MockPostsRepository,
 				cr *mocks.MockCommentsRepository,
+				or *mocks.MockOutboxRepository,
+				cmr *mocks.MockCommunitiesRepository,
 			) {
-				pr.On("GetByID", mock.Anything, postID, false).Return(
+				mockTx := &txMocks.MockTx{}
+
+				ts.On("Begin", mock.Anything).Return(mockTx, nil)
+
+				mockTx.On("Rollback", mock.Anything).Return(nil)
+
+				pr.On("GetByID", mock.Anything, postID, true).Return(
 					nil, errors.New("some error"),
 				)
 			},
 			expectError: true,
 		},
 		{
-			name:   "commentsRepo.GetRootCommentsByPostID error",
+			name:   "unauthorized user",
+			userID: otherUserID,
 			postID: postID,
-			limit:  int32Ptr(10),
-			offset: int32Ptr(0),
 			setupMocks: func(
 				ts *txMocks.MockTxStarter,
 				pr *mocks.MockPostsRepository,
 				cr *mocks.MockCommentsRepository,
+				or *mocks.MockOutboxRepository,
+				cmr *mocks.MockCommunitiesRepository,
 			) {
-				pr.On("GetByID", mock.Anything, postID, false).Return(
+				mockTx := &txMocks.MockTx{}
+
+				ts.On("Begin", mock.Anything).Return(mockTx, nil)
+
+				mockTx.On("Rollback", mock.Anything).Return(nil)
+
+				pr.On("GetByID", mock.Anything, postID, true).Return(
 					&models.Post{
 						ID:                 postID,
-						UserID:             userID,
+						UserID:             ownerUserID,
 						Title:              title,
 						Content:            content,
 						CreatedAt:          time.Now(),
 						AreCommentsAllowed: true,
 					}, nil,
 				)
+			},
+			expectError: true,
+		},
+		{
+			name:   "postsRepo.DisableComments error",
+			userID: ownerUserID,
+			postID: postID,
+			setupMocks: func(
+				ts *txMocks.MockTxStarter,
+				pr *mocks.MockPostsRepository,
+				cr *mocks.MockCommentsRepository,
+				or *mocks.MockOutboxRepository,
+				cmr *mocks.MockCommunitiesRepository,
+			) {
+				mockTx := &txMocks.MockTx{}
+
+				ts.On("Begin", mock.Anything).Return(mockTx, nil)
+
+				mockTx.On("Rollback", mock.Anything).Return(nil)
 
-				cr.On("GetRootCommentsByPostID", mock.Anything, postID, int32Ptr(10), int32Ptr(0)).Return(
+				pr.On("GetByID", mock.Anything, postID, true).Return(
+					&models.Post{
+						ID:                 postID,
+						UserID:             ownerUserID,
+						Title:              title,
+						Content:            content,
+						CreatedAt:          time.Now(),
+						AreCommentsAllowed: true,
+					}, nil,
+				)
+				pr.On("DisableComments", mock.Anything, postID).Return(
 					nil, errors.New("some error"),
 				)
 			},
 			expectError: true,
 		},
 		{
-			name:   "commentsRepo.GetChildrenCommentsByRootIDs error",
+			name:   "OK (community moderator)",
+			userID: otherUserID,
 			postID: postID,
-			limit:  int32Ptr(10),
-			offset: int32Ptr(0),
 			setupMocks: func(
 				ts *txMocks.MockTxStarter,
 				pr *mocks.MockPostsRepository,
 				cr *mocks.MockCommentsRepository,
+				or *mocks.MockOutboxRepository,
+				cmr *mocks.MockCommunitiesRepository,
 			) {
-				pr.On("GetByID", mock.Anything, postID, false).Return(
+				mockTx := &txMocks.MockTx{}
+
+				ts.On("Begin", mock.Anything).Return(mockTx, nil)
+
+				mockTx.On("Commit", mock.Anything).Return(nil)
+
+				pr.On("GetByID", mock.Anything, postID, true).Return(
 					&models.Post{
 						ID:                 postID,
-						UserID:             userID,
+						UserID:             ownerUserID,
+						CommunityID:        &communityID,
 						Title:              title,
 						Content:            content,
 						CreatedAt:          time.Now(),
 						AreCommentsAllowed: true,
 					}, nil,
 				)
+				cmr.On("GetMember", mock.Anything, communityID, otherUserID).Return(
+					&models.CommunityMember{
+						CommunityID: communityID,
+						UserID:      otherUserID,
+						Role:        models.CommunityRoleModerator,
+					}, nil,
+				)
 
-				cr.On("GetRootCommentsByPostID", mock.Anything, postID, int32Ptr(10), int32Ptr(0)).Return(
-					rootComments, nil,
+				pr.On("DisableComments", mock.Anything, postID).Return(
+					&models.Post{
+						ID:                 postID,
+						UserID:             ownerUserID,
+						CommunityID:        &communityID,
+						Title:              title,
+						Content:            content,
+						CreatedAt:          time.Now(),
+						AreCommentsAllowed: false,
+					}, nil,
 				)
+				or.On(
+					"Add",
+					mock.Anything,
+					mock.AnythingOfType("uuid.UUID"),
+					models.EventCommentsToggled,
+					mock.AnythingOfType("[]uint8"),
+				).Return(nil)
+			},
+			expectError: false,
+		},
+		{
+			name:   "community member is not a moderator",
+			userID: otherUserID,
+			postID: postID,
+			setupMocks: func(
+				ts *txMocks.MockTxStarter,
+				pr *mocks.MockPostsRepository,
+				cr *mocks.MockCommentsRepository,
+				or *mocks.MockOutboxRepository,
+				cmr *mocks.MockCommunitiesRepository,
+			) {
+				mockTx := &txMocks.MockTx{}
 
-				cr.On("GetChildrenCommentsByRootIDs", mock.Anything, mock.Anything).Return(
-					nil, errors.New("some error"),
+				ts.On("Begin", mock.Anything).Return(mockTx, nil)
+
+				mockTx.On("Rollback", mock.Anything).Return(nil)
+
+				pr.On("GetByID", mock.Anything, postID, true).Return(
+					&models.Post{
+						ID:                 postID,
+						UserID:             ownerUserID,
+						CommunityID:        &communityID,
+						Title:              title,
+						Content:            content,
+						CreatedAt:          time.Now(),
+						AreCommentsAllowed: true,
+					}, nil,
+				)
+				cmr.On("GetMember", mock.Anything, communityID, otherUserID).Return(
+					&models.CommunityMember{
+						CommunityID: communityID,
+						UserID:      otherUserID,
+						Role:        models.CommunityRoleMember,
+					}, nil,
 				)
 			},
 			expectError: true,
@@ -521,42 +1354,49 @@ func TestPostsService_GetPostWithComments(t *testing.T) {
 			mockTxStarter := txMocks.NewMockTxStarter(t)
 			mockPostsRepo := mocks.NewMockPostsRepository(t)
 			mockCommentsRepo := mocks.NewMockCommentsRepository(t)
+			mockOutboxRepo := mocks.NewMockOutboxRepository(t)
+			mockCommunitiesRepo := mocks.NewMockCommunitiesRepository(t)
 
 			tc.setupMocks(
 				mockTxStarter,
 				mockPostsRepo,
 				mockCommentsRepo,
+				mockOutboxRepo,
+				mockCommunitiesRepo,
 			)
 
 			postsService := services.NewPostsService(
 				mockTxStarter,
 				mockPostsRepo,
 				mockCommentsRepo,
+				mockOutboxRepo,
+				mockCommunitiesRepo,
 			)
 
-			postWithComments, err := postsService.GetPostWithComments(
+			post, err := postsService.DisableComments(
 				context.Background(),
+				tc.userID,
 				tc.postID,
-				tc.limit,
-				tc.offset,
 			)
 
 			if tc.expectError {
 				assert.Error(t, err)
-				assert.Nil(t, postWithComments)
+				assert.Nil(t, post)
 			} else {
 				assert.NoError(t, err)
-				assert.NotNil(t, postWithComments)
+				assert.NotNil(t, post)
 			}
 
 			mockTxStarter.AssertExpectations(t)
 			mockPostsRepo.AssertExpectations(t)
 			mockCommentsRepo.AssertExpectations(t)
+			mockOutboxRepo.AssertExpectations(t)
+			mockCommunitiesRepo.AssertExpectations(t)
 		})
 	}
 }
 
-func TestPostsService_DisableComments(t *testing.T) {
+func TestPostsService_EnableComments(t *testing.T) {
 	type testCase struct {
 		name       string
 		userID     uuid.UUID
@@ -565,6 +1405,7 @@ func TestPostsService_DisableComments(t *testing.T) {
 			ts *txMocks.MockTxStarter,
 			pr *mocks.MockPostsRepository,
 			cr *mocks.MockCommentsRepository,
+			or *mocks.MockOutboxRepository,
 		)
 		expectError bool
 	}
@@ -584,6 +1425,7 @@ func TestPostsService_DisableComments(t *testing.T) {
 				ts *txMocks.MockTxStarter,
 				pr *mocks.MockPostsRepository,
 				cr *mocks.MockCommentsRepository,
+				or *mocks.MockOutboxRepository,
 			) {
 				mockTx := &txMocks.MockTx{}
 
@@ -598,20 +1440,27 @@ func TestPostsService_DisableComments(t *testing.T) {
 						Title:              title,
 						Content:            content,
 						CreatedAt:          time.Now(),
-						AreCommentsAllowed: true,
+						AreCommentsAllowed: false,
 					}, nil,
 				)
 
-				pr.On("DisableComments", mock.Anything, postID).Return(
+				pr.On("EnableComments", mock.Anything, postID).Return(
 					&models.Post{
 						ID:                 postID,
 						UserID:             ownerUserID,
 						Title:              title,
 						Content:            content,
 						CreatedAt:          time.Now(),
-						AreCommentsAllowed: false,
+						AreCommentsAllowed: true,
 					}, nil,
 				)
+				or.On(
+					"Add",
+					mock.Anything,
+					mock.AnythingOfType("uuid.UUID"),
+					models.EventCommentsToggled,
+					mock.AnythingOfType("[]uint8"),
+				).Return(nil)
 			},
 			expectError: false,
 		},
@@ -623,6 +1472,7 @@ func TestPostsService_DisableComments(t *testing.T) {
 				ts *txMocks.MockTxStarter,
 				pr *mocks.MockPostsRepository,
 				cr *mocks.MockCommentsRepository,
+				or *mocks.MockOutboxRepository,
 			) {
 				ts.On("Begin", mock.Anything).Return(nil, errors.New("some error"))
 			},
@@ -636,6 +1486,7 @@ func TestPostsService_DisableComments(t *testing.T) {
 				ts *txMocks.MockTxStarter,
 				pr *mocks.MockPostsRepository,
 				cr *mocks.MockCommentsRepository,
+				or *mocks.MockOutboxRepository,
 			) {
 				mockTx := &txMocks.MockTx{}
 
@@ -657,6 +1508,7 @@ func TestPostsService_DisableComments(t *testing.T) {
 				ts *txMocks.MockTxStarter,
 				pr *mocks.MockPostsRepository,
 				cr *mocks.MockCommentsRepository,
+				or *mocks.MockOutboxRepository,
 			) {
 				mockTx := &txMocks.MockTx{}
 
@@ -671,20 +1523,21 @@ func TestPostsService_DisableComments(t *testing.T) {
 						Title:              title,
 						Content:            content,
 						CreatedAt:          time.Now(),
-						AreCommentsAllowed: true,
+						AreCommentsAllowed: false,
 					}, nil,
 				)
 			},
 			expectError: true,
 		},
 		{
-			name:   "postsRepo.DisableComments error",
+			name:   "postsRepo.EnableComments error",
 			userID: ownerUserID,
 			postID: postID,
 			setupMocks: func(
 				ts *txMocks.MockTxStarter,
 				pr *mocks.MockPostsRepository,
 				cr *mocks.MockCommentsRepository,
+				or *mocks.MockOutboxRepository,
 			) {
 				mockTx := &txMocks.MockTx{}
 
@@ -699,10 +1552,10 @@ func TestPostsService_DisableComments(t *testing.T) {
 						Title:              title,
 						Content:            content,
 						CreatedAt:          time.Now(),
-						AreCommentsAllowed: true,
+						AreCommentsAllowed: false,
 					}, nil,
 				)
-				pr.On("DisableComments", mock.Anything, postID).Return(
+				pr.On("EnableComments", mock.Anything, postID).Return(
 					nil, errors.New("some error"),
 				)
 			},
@@ -715,20 +1568,25 @@ func TestPostsService_DisableComments(t *testing.T) {
 			mockTxStarter := txMocks.NewMockTxStarter(t)
 			mockPostsRepo := mocks.NewMockPostsRepository(t)
 			mockCommentsRepo := mocks.NewMockCommentsRepository(t)
+			mockOutboxRepo := mocks.NewMockOutboxRepository(t)
+			mockCommunitiesRepo := mocks.NewMockCommunitiesRepository(t)
 
 			tc.setupMocks(
 				mockTxStarter,
 				mockPostsRepo,
 				mockCommentsRepo,
+				mockOutboxRepo,
 			)
 
 			postsService := services.NewPostsService(
 				mockTxStarter,
 				mockPostsRepo,
 				mockCommentsRepo,
+				mockOutboxRepo,
+				mockCommunitiesRepo,
 			)
 
-			post, err := postsService.DisableComments(
+			post, err := postsService.EnableComments(
 				context.Background(),
 				tc.userID,
 				tc.postID,
@@ -745,21 +1603,23 @@ func TestPostsService_DisableComments(t *testing.T) {
 			mockTxStarter.AssertExpectations(t)
 			mockPostsRepo.AssertExpectations(t)
 			mockCommentsRepo.AssertExpectations(t)
+			mockOutboxRepo.AssertExpectations(t)
 		})
 	}
 }
 
-func TestPostsService_EnableComments(t *testing.T) {
+func TestPostsService_UpdatePost(t *testing.T) {
 	type testCase struct {
 		name       string
-		userID     uuid.UUID
-		postID     uuid.UUID
+		input      *dtos.UpdatePostRequest
 		setupMocks func(
 			ts *txMocks.MockTxStarter,
 			pr *mocks.MockPostsRepository,
 			cr *mocks.MockCommentsRepository,
 		)
-		expectError bool
+		expectedTitle   string
+		expectedContent string
+		expectedError   error
 	}
 
 	ownerUserID := uuid.New()
@@ -767,12 +1627,31 @@ func TestPostsService_EnableComments(t *testing.T) {
 	postID := uuid.New()
 	title := "Test title"
 	content := "Test content"
+	newTitle := "New title"
+	deletedAt := time.Now()
+
+	config.Cfg.MaxPostTags = 2
+	defer func() { config.Cfg.MaxPostTags = 0 }()
+
+	post := func() *models.Post {
+		return &models.Post{
+			ID:                 postID,
+			UserID:             ownerUserID,
+			Title:              title,
+			Content:            content,
+			CreatedAt:          time.Now(),
+			AreCommentsAllowed: true,
+		}
+	}
 
 	testCases := []testCase{
 		{
-			name:   "OK",
-			userID: ownerUserID,
-			postID: postID,
+			name: "OK, only title",
+			input: &dtos.UpdatePostRequest{
+				PostID: postID,
+				UserID: ownerUserID,
+				Title:  &newTitle,
+			},
 			setupMocks: func(
 				ts *txMocks.MockTxStarter,
 				pr *mocks.MockPostsRepository,
@@ -784,47 +1663,138 @@ func TestPostsService_EnableComments(t *testing.T) {
 
 				mockTx.On("Commit", mock.Anything).Return(nil)
 
-				pr.On("GetByID", mock.Anything, postID, true).Return(
+				pr.On("GetByID", mock.Anything, postID, true).Return(post(), nil)
+
+				updatedAt := time.Now()
+				pr.On("Update", mock.Anything, postID, newTitle, content).Return(
 					&models.Post{
-						ID:                 postID,
-						UserID:             ownerUserID,
-						Title:              title,
-						Content:            content,
-						CreatedAt:          time.Now(),
-						AreCommentsAllowed: false,
+						ID:        postID,
+						UserID:    ownerUserID,
+						Title:     newTitle,
+						Content:   content,
+						UpdatedAt: &updatedAt,
 					}, nil,
 				)
+			},
+			expectedTitle:   newTitle,
+			expectedContent: content,
+			expectedError:   nil,
+		},
+		{
+			name: "OK, only tags",
+			input: &dtos.UpdatePostRequest{
+				PostID: postID,
+				UserID: ownerUserID,
+				Tags:   []string{"Rust", "#golang"},
+			},
+			setupMocks: func(
+				ts *txMocks.MockTxStarter,
+				pr *mocks.MockPostsRepository,
+				cr *mocks.MockCommentsRepository,
+			) {
+				mockTx := &txMocks.MockTx{}
+
+				ts.On("Begin", mock.Anything).Return(mockTx, nil)
+
+				mockTx.On("Commit", mock.Anything).Return(nil)
+
+				pr.On("GetByID", mock.Anything, postID, true).Return(post(), nil)
+
+				pr.On("GetTags", mock.Anything, []uuid.UUID{postID}).Return(
+					map[uuid.UUID][]string{postID: {"golang"}}, nil,
+				)
+				pr.On("SetTags", mock.Anything, postID, []string{"rust", "golang"}).Return(nil)
 
-				pr.On("EnableComments", mock.Anything, postID).Return(
+				// Смена тегов обновляет updatedAt
+				updatedAt := time.Now()
+				pr.On("Update", mock.Anything, postID, title, content).Return(
 					&models.Post{
-						ID:                 postID,
-						UserID:             ownerUserID,
-						Title:              title,
-						Content:            content,
-						CreatedAt:          time.Now(),
-						AreCommentsAllowed: true,
+						ID:        postID,
+						UserID:    ownerUserID,
+						Title:     title,
+						Content:   content,
+						UpdatedAt: &updatedAt,
 					}, nil,
 				)
 			},
-			expectError: false,
+			expectedTitle:   title,
+			expectedContent: content,
+			expectedError:   nil,
 		},
 		{
-			name:   "error starting transaction",
-			userID: ownerUserID,
-			postID: postID,
+			name: "same tags",
+			input: &dtos.UpdatePostRequest{
+				PostID: postID,
+				UserID: ownerUserID,
+				Tags:   []string{"Rust", "#golang"},
+			},
 			setupMocks: func(
 				ts *txMocks.MockTxStarter,
 				pr *mocks.MockPostsRepository,
 				cr *mocks.MockCommentsRepository,
 			) {
-				ts.On("Begin", mock.Anything).Return(nil, errors.New("some error"))
+				mockTx := &txMocks.MockTx{}
+
+				ts.On("Begin", mock.Anything).Return(mockTx, nil)
+
+				mockTx.On("Commit", mock.Anything).Return(nil)
+
+				pr.On("GetByID", mock.Anything, postID, true).Return(post(), nil)
+
+				pr.On("GetTags", mock.Anything, []uuid.UUID{postID}).Return(
+					map[uuid.UUID][]string{postID: {"golang", "rust"}}, nil,
+				)
 			},
-			expectError: true,
+			expectedTitle:   title,
+			expectedContent: content,
+			expectedError:   nil,
 		},
 		{
-			name:   "postsRepo.GetByID error",
-			userID: ownerUserID,
-			postID: postID,
+			name: "too many tags",
+			input: &dtos.UpdatePostRequest{
+				PostID: postID,
+				UserID: ownerUserID,
+				Tags:   []string{"golang", "rust", "sql"},
+			},
+			setupMocks: func(
+				ts *txMocks.MockTxStarter,
+				pr *mocks.MockPostsRepository,
+				cr *mocks.MockCommentsRepository,
+			) {
+			},
+			expectedError: errs.ErrTooManyTags,
+		},
+		{
+			name: "nothing changed",
+			input: &dtos.UpdatePostRequest{
+				PostID: postID,
+				UserID: ownerUserID,
+				Title:  &title,
+			},
+			setupMocks: func(
+				ts *txMocks.MockTxStarter,
+				pr *mocks.MockPostsRepository,
+				cr *mocks.MockCommentsRepository,
+			) {
+				mockTx := &txMocks.MockTx{}
+
+				ts.On("Begin", mock.Anything).Return(mockTx, nil)
+
+				mockTx.On("Commit", mock.Anything).Return(nil)
+
+				pr.On("GetByID", mock.Anything, postID, true).Return(post(), nil)
+			},
+			expectedTitle:   title,
+			expectedContent: content,
+			expectedError:   nil,
+		},
+		{
+			name: "not the author",
+			input: &dtos.UpdatePostRequest{
+				PostID: postID,
+				UserID: otherUserID,
+				Title:  &newTitle,
+			},
 			setupMocks: func(
 				ts *txMocks.MockTxStarter,
 				pr *mocks.MockPostsRepository,
@@ -836,16 +1806,17 @@ func TestPostsService_EnableComments(t *testing.T) {
 
 				mockTx.On("Rollback", mock.Anything).Return(nil)
 
-				pr.On("GetByID", mock.Anything, postID, true).Return(
-					nil, errors.New("some error"),
-				)
+				pr.On("GetByID", mock.Anything, postID, true).Return(post(), nil)
 			},
-			expectError: true,
+			expectedError: errs.ErrUnauthorized,
 		},
 		{
-			name:   "unauthorized user",
-			userID: otherUserID,
-			postID: postID,
+			name: "deleted post",
+			input: &dtos.UpdatePostRequest{
+				PostID: postID,
+				UserID: ownerUserID,
+				Title:  &newTitle,
+			},
 			setupMocks: func(
 				ts *txMocks.MockTxStarter,
 				pr *mocks.MockPostsRepository,
@@ -857,21 +1828,134 @@ func TestPostsService_EnableComments(t *testing.T) {
 
 				mockTx.On("Rollback", mock.Anything).Return(nil)
 
-				pr.On("GetByID", mock.Anything, postID, true).Return(
+				deletedPost := post()
+				deletedPost.DeletedAt = &deletedAt
+				pr.On("GetByID", mock.Anything, postID, true).Return(deletedPost, nil)
+			},
+			expectedError: errs.ErrPostDeleted,
+		},
+		{
+			name: "postsRepo.GetByID error",
+			input: &dtos.UpdatePostRequest{
+				PostID: postID,
+				UserID: ownerUserID,
+				Title:  &newTitle,
+			},
+			setupMocks: func(
+				ts *txMocks.MockTxStarter,
+				pr *mocks.MockPostsRepository,
+				cr *mocks.MockCommentsRepository,
+			) {
+				mockTx := &txMocks.MockTx{}
+
+				ts.On("Begin", mock.Anything).Return(mockTx, nil)
+
+				mockTx.On("Rollback", mock.Anything).Return(nil)
+
+				pr.On("GetByID", mock.Anything, postID, true).Return(nil, errs.ErrNotFound)
+			},
+			expectedError: errs.ErrNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockTxStarter := txMocks.NewMockTxStarter(t)
+			mockPostsRepo := mocks.NewMockPostsRepository(t)
+			mockCommentsRepo := mocks.NewMockCommentsRepository(t)
+			mockOutboxRepo := mocks.NewMockOutboxRepository(t)
+			mockCommunitiesRepo := mocks.NewMockCommunitiesRepository(t)
+
+			tc.setupMocks(
+				mockTxStarter,
+				mockPostsRepo,
+				mockCommentsRepo,
+			)
+
+			postsService := services.NewPostsService(
+				mockTxStarter,
+				mockPostsRepo,
+				mockCommentsRepo,
+				mockOutboxRepo,
+				mockCommunitiesRepo,
+			)
+
+			post, err := postsService.UpdatePost(context.Background(), tc.input)
+
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+				assert.Nil(t, post)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedTitle, post.Title)
+				assert.Equal(t, tc.expectedContent, post.Content)
+			}
+
+			mockTxStarter.AssertExpectations(t)
+			mockPostsRepo.AssertExpectations(t)
+			mockCommentsRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPostsService_DeletePost(t *testing.T) {
+	type testCase struct {
+		name       string
+		userID     uuid.UUID
+		postID     uuid.UUID
+		setupMocks func(
+			ts *txMocks.MockTxStarter,
+			pr *mocks.MockPostsRepository,
+			cr *mocks.MockCommentsRepository,
+		)
+		expectedError error
+	}
+
+	ownerUserID := uuid.New()
+	otherUserID := uuid.New()
+	postID := uuid.New()
+	deletedAt := time.Now()
+
+	post := func() *models.Post {
+		return &models.Post{
+			ID:                 postID,
+			UserID:             ownerUserID,
+			Title:              "Test title",
+			Content:            "Test content",
+			CreatedAt:          time.Now(),
+			AreCommentsAllowed: true,
+		}
+	}
+
+	testCases := []testCase{
+		{
+			name:   "OK",
+			userID: ownerUserID,
+			postID: postID,
+			setupMocks: func(
+				ts *txMocks.MockTxStarter,
+				pr *mocks.MockPostsRepository,
+				cr *mocks.MockCommentsRepository,
+			) {
+				mockTx := &txMocks.MockTx{}
+
+				ts.On("Begin", mock.Anything).Return(mockTx, nil)
+
+				mockTx.On("Commit", mock.Anything).Return(nil)
+
+				pr.On("GetByID", mock.Anything, postID, true).Return(post(), nil)
+				pr.On("SoftDelete", mock.Anything, postID).Return(
 					&models.Post{
-						ID:                 postID,
-						UserID:             ownerUserID,
-						Title:              title,
-						Content:            content,
-						CreatedAt:          time.Now(),
-						AreCommentsAllowed: false,
+						ID:        postID,
+						UserID:    ownerUserID,
+						DeletedAt: &deletedAt,
 					}, nil,
 				)
 			},
-			expectError: true,
+			expectedError: nil,
 		},
 		{
-			name:   "postsRepo.EnableComments error",
+			name:   "already deleted",
 			userID: ownerUserID,
 			postID: postID,
 			setupMocks: func(
@@ -883,23 +1967,45 @@ func TestPostsService_EnableComments(t *testing.T) {
 
 				ts.On("Begin", mock.Anything).Return(mockTx, nil)
 
+				mockTx.On("Commit", mock.Anything).Return(nil)
+
+				deletedPost := post()
+				deletedPost.DeletedAt = &deletedAt
+				pr.On("GetByID", mock.Anything, postID, true).Return(deletedPost, nil)
+			},
+			expectedError: nil,
+		},
+		{
+			name:   "not the author",
+			userID: otherUserID,
+			postID: postID,
+			setupMocks: func(
+				ts *txMocks.MockTxStarter,
+				pr *mocks.MockPostsRepository,
+				cr *mocks.MockCommentsRepository,
+			) {
+				mockTx := &txMocks.MockTx{}
+
+				ts.On("Begin", mock.Anything).Return(mockTx, nil)
+
 				mockTx.On("Rollback", mock.Anything).Return(nil)
 
-				pr.On("GetByID", mock.Anything, postID, true).Return(
-					&models.Post{
-						ID:                 postID,
-						UserID:             ownerUserID,
-						Title:              title,
-						Content:            content,
-						CreatedAt:          time.Now(),
-						AreCommentsAllowed: false,
-					}, nil,
-				)
-				pr.On("EnableComments", mock.Anything, postID).Return(
-					nil, errors.New("some error"),
-				)
+				pr.On("GetByID", mock.Anything, postID, true).Return(post(), nil)
 			},
-			expectError: true,
+			expectedError: errs.ErrUnauthorized,
+		},
+		{
+			name:   "error starting transaction",
+			userID: ownerUserID,
+			postID: postID,
+			setupMocks: func(
+				ts *txMocks.MockTxStarter,
+				pr *mocks.MockPostsRepository,
+				cr *mocks.MockCommentsRepository,
+			) {
+				ts.On("Begin", mock.Anything).Return(nil, errors.New("some error"))
+			},
+			expectedError: errs.ErrInternal,
 		},
 	}
 
@@ -908,6 +2014,8 @@ func TestPostsService_EnableComments(t *testing.T) {
 			mockTxStarter := txMocks.NewMockTxStarter(t)
 			mockPostsRepo := mocks.NewMockPostsRepository(t)
 			mockCommentsRepo := mocks.NewMockCommentsRepository(t)
+			mockOutboxRepo := mocks.NewMockOutboxRepository(t)
+			mockCommunitiesRepo := mocks.NewMockCommunitiesRepository(t)
 
 			tc.setupMocks(
 				mockTxStarter,
@@ -919,20 +2027,22 @@ func TestPostsService_EnableComments(t *testing.T) {
 				mockTxStarter,
 				mockPostsRepo,
 				mockCommentsRepo,
+				mockOutboxRepo,
+				mockCommunitiesRepo,
 			)
 
-			post, err := postsService.EnableComments(
+			post, err := postsService.DeletePost(
 				context.Background(),
 				tc.userID,
 				tc.postID,
 			)
 
-			if tc.expectError {
-				assert.Error(t, err)
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
 				assert.Nil(t, post)
 			} else {
 				assert.NoError(t, err)
-				assert.NotNil(t, post)
+				assert.NotNil(t, post.DeletedAt)
 			}
 
 			mockTxStarter.AssertExpectations(t)
@@ -0,0 +1,175 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/Govorov1705/ozon-test/config"
+	"github.com/Govorov1705/ozon-test/internal/dtos"
+	"github.com/Govorov1705/ozon-test/internal/errs"
+	"github.com/Govorov1705/ozon-test/internal/logger"
+	"github.com/Govorov1705/ozon-test/internal/models"
+	"github.com/Govorov1705/ozon-test/internal/repositories"
+	"github.com/Govorov1705/ozon-test/internal/transactions"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type ReactionsService struct {
+	txStarter    transactions.TxStarter
+	postsRepo    repositories.PostsRepository
+	commentsRepo repositories.CommentsRepository
+	outboxRepo   repositories.OutboxRepository
+}
+
+func NewReactionsService(
+	txStarter transactions.TxStarter,
+	pr repositories.PostsRepository,
+	cr repositories.CommentsRepository,
+	or repositories.OutboxRepository,
+) *ReactionsService {
+	return &ReactionsService{
+		txStarter:    txStarter,
+		postsRepo:    pr,
+		commentsRepo: cr,
+		outboxRepo:   or,
+	}
+}
+
+// reactionTarget - пост или комментарий, на который ставится реакция
+type reactionTarget struct {
+	postID    uuid.UUID
+	isDeleted bool
+	repo      interface {
+		AddReaction(ctx context.Context, targetID, userID uuid.UUID, emoji string) (bool, error)
+		DeleteReaction(ctx context.Context, targetID, userID uuid.UUID, emoji string) (bool, error)
+		GetReactions(ctx context.Context, targetIDs []uuid.UUID, viewerID *uuid.UUID) (map[uuid.UUID][]*models.ReactionCount, error)
+	}
+}
+
+// AddReaction возвращает реакции на цель после добавления. Допустимые
+// эмодзи задаются переменной окружения ALLOWED_REACTIONS
+func (s *ReactionsService) AddReaction(ctx context.Context, req *dtos.ReactionRequest) ([]*models.ReactionCount, error) {
+	if !slices.Contains(config.Cfg.AllowedReactions, req.Emoji) {
+		return nil, errs.ErrReactionNotAllowed
+	}
+
+	return s.changeReaction(ctx, req, true)
+}
+
+// RemoveReaction не проверяет эмодзи, чтобы реакцию можно было снять
+// и после того, как эмодзи убрали из списка допустимых
+func (s *ReactionsService) RemoveReaction(ctx context.Context, req *dtos.ReactionRequest) ([]*models.ReactionCount, error) {
+	return s.changeReaction(ctx, req, false)
+}
+
+func (s *ReactionsService) changeReaction(ctx context.Context, req *dtos.ReactionRequest, add bool) (reactions []*models.ReactionCount, err error) {
+	tx, err := s.txStarter.Begin(ctx)
+	if err != nil {
+		logger.Logger.Error("error starting transaction", zap.Error(err))
+		return nil, errs.ErrInternal
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback(ctx)
+			panic(p)
+		} else if err != nil {
+			if rollbackErr := tx.Rollback(ctx); rollbackErr != nil {
+				logger.Logger.Error("rollback failed", zap.Error(rollbackErr))
+				err = errs.ErrInternal
+			}
+		} else {
+			if commitErr := tx.Commit(ctx); commitErr != nil {
+				logger.Logger.Error("error committing transaction", zap.Error(commitErr))
+				err = errs.ErrInternal
+			}
+		}
+	}()
+
+	ctx = transactions.PutTxIntoContext(ctx, tx)
+
+	// Цель блокируется, чтобы изменения ее реакций шли по очереди
+	// и события о них приходили с правильными счетчиками
+	target, err := s.getTarget(ctx, req.TargetID)
+	if err != nil {
+		return nil, err
+	}
+
+	var changed bool
+	if add {
+		if target.isDeleted {
+			if target.postID == req.TargetID {
+				return nil, errs.ErrPostDeleted
+			}
+			return nil, errs.ErrCommentDeleted
+		}
+		changed, err = target.repo.AddReaction(ctx, req.TargetID, req.UserID, req.Emoji)
+	} else {
+		changed, err = target.repo.DeleteReaction(ctx, req.TargetID, req.UserID, req.Emoji)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	all, err := target.repo.GetReactions(ctx, []uuid.UUID{req.TargetID}, &req.UserID)
+	if err != nil {
+		return nil, err
+	}
+	reactions = all[req.TargetID]
+
+	if !changed {
+		return reactions, nil
+	}
+
+	event := &dtos.PostActivityEvent{
+		Type:     models.EventReactionChanged,
+		PostID:   target.postID,
+		TargetID: req.TargetID,
+		Emoji:    req.Emoji,
+	}
+	for _, reaction := range reactions {
+		if reaction.Emoji == req.Emoji {
+			event.Count = reaction.Count
+		}
+	}
+
+	err = addPostActivity(ctx, s.outboxRepo, event)
+	if err != nil {
+		return nil, err
+	}
+
+	return reactions, nil
+}
+
+// getTarget ищет ID сначала среди постов, затем среди комментариев
+// и блокирует найденную строку
+func (s *ReactionsService) getTarget(ctx context.Context, targetID uuid.UUID) (*reactionTarget, error) {
+	post, err := s.postsRepo.GetByID(ctx, targetID, true)
+	if err == nil {
+		return &reactionTarget{
+			postID:    post.ID,
+			isDeleted: post.DeletedAt != nil,
+			repo:      s.postsRepo,
+		}, nil
+	}
+	if !errors.Is(err, errs.ErrNotFound) {
+		return nil, err
+	}
+
+	comment, err := s.commentsRepo.GetByID(ctx, targetID, true)
+	if err == nil {
+		return &reactionTarget{
+			postID:    comment.PostID,
+			isDeleted: comment.DeletedAt != nil,
+			repo:      s.commentsRepo,
+		}, nil
+	}
+	if !errors.Is(err, errs.ErrNotFound) {
+		return nil, err
+	}
+
+	return nil, fmt.Errorf("post or comment %w", errs.ErrNotFound)
+}
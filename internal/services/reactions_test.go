@@ -0,0 +1,258 @@
+package services_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Govorov1705/ozon-test/config"
+	"github.com/Govorov1705/ozon-test/internal/dtos"
+	"github.com/Govorov1705/ozon-test/internal/errs"
+	"github.com/Govorov1705/ozon-test/internal/models"
+	"github.com/Govorov1705/ozon-test/internal/repositories/mocks"
+	"github.com/Govorov1705/ozon-test/internal/services"
+	txMocks "github.com/Govorov1705/ozon-test/internal/transactions/mocks"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestReactionsService_ChangeReaction(t *testing.T) {
+	config.Cfg.AllowedReactions = []string{"👍", "🎉"}
+	defer func() { config.Cfg.AllowedReactions = nil }()
+
+	type testCase struct {
+		name       string
+		input      *dtos.ReactionRequest
+		remove     bool
+		setupMocks func(
+			ts *txMocks.MockTxStarter,
+			pr *mocks.MockPostsRepository,
+			cr *mocks.MockCommentsRepository,
+			or *mocks.MockOutboxRepository,
+		)
+		expectedReactions []*models.ReactionCount
+		expectedError     error
+	}
+
+	postID := uuid.New()
+	commentID := uuid.New()
+	userID := uuid.New()
+	deletedAt := time.Now()
+
+	post := &models.Post{ID: postID}
+	comment := &models.Comment{ID: commentID, PostID: postID}
+
+	reactions := []*models.ReactionCount{
+		{Emoji: "🎉", Count: 1},
+		{Emoji: "👍", Count: 2, ViewerHasReacted: true},
+	}
+
+	expectCommit := func(ts *txMocks.MockTxStarter) {
+		mockTx := &txMocks.MockTx{}
+		ts.On("Begin", mock.Anything).Return(mockTx, nil)
+		mockTx.On("Commit", mock.Anything).Return(nil)
+	}
+
+	expectRollback := func(ts *txMocks.MockTxStarter) {
+		mockTx := &txMocks.MockTx{}
+		ts.On("Begin", mock.Anything).Return(mockTx, nil)
+		mockTx.On("Rollback", mock.Anything).Return(nil)
+	}
+
+	// Событие должно нести пост обсуждения и счетчик эмодзи после изменения
+	expectEvent := func(or *mocks.MockOutboxRepository, targetID uuid.UUID, count int32) {
+		or.On(
+			"Add",
+			mock.Anything,
+			mock.AnythingOfType("uuid.UUID"),
+			models.EventReactionChanged,
+			mock.MatchedBy(func(payload []byte) bool {
+				var event dtos.PostActivityEvent
+				err := json.Unmarshal(payload, &event)
+				return err == nil &&
+					event.PostID == postID &&
+					event.TargetID == targetID &&
+					event.Emoji == "👍" &&
+					event.Count == count
+			}),
+		).Return(nil)
+	}
+
+	testCases := []testCase{
+		{
+			name:  "OK (post)",
+			input: &dtos.ReactionRequest{TargetID: postID, UserID: userID, Emoji: "👍"},
+			setupMocks: func(
+				ts *txMocks.MockTxStarter,
+				pr *mocks.MockPostsRepository,
+				cr *mocks.MockCommentsRepository,
+				or *mocks.MockOutboxRepository,
+			) {
+				expectCommit(ts)
+
+				pr.On("GetByID", mock.Anything, postID, true).Return(post, nil)
+				pr.On("AddReaction", mock.Anything, postID, userID, "👍").Return(true, nil)
+				pr.On("GetReactions", mock.Anything, []uuid.UUID{postID}, &userID).Return(
+					map[uuid.UUID][]*models.ReactionCount{postID: reactions}, nil,
+				)
+				expectEvent(or, postID, 2)
+			},
+			expectedReactions: reactions,
+		},
+		{
+			name:  "OK (comment)",
+			input: &dtos.ReactionRequest{TargetID: commentID, UserID: userID, Emoji: "👍"},
+			setupMocks: func(
+				ts *txMocks.MockTxStarter,
+				pr *mocks.MockPostsRepository,
+				cr *mocks.MockCommentsRepository,
+				or *mocks.MockOutboxRepository,
+			) {
+				expectCommit(ts)
+
+				pr.On("GetByID", mock.Anything, commentID, true).Return(nil, fmt.Errorf("post %w", errs.ErrNotFound))
+				cr.On("GetByID", mock.Anything, commentID, true).Return(comment, nil)
+				cr.On("AddReaction", mock.Anything, commentID, userID, "👍").Return(true, nil)
+				cr.On("GetReactions", mock.Anything, []uuid.UUID{commentID}, &userID).Return(
+					map[uuid.UUID][]*models.ReactionCount{commentID: reactions}, nil,
+				)
+				expectEvent(or, commentID, 2)
+			},
+			expectedReactions: reactions,
+		},
+		{
+			name:  "already reacted",
+			input: &dtos.ReactionRequest{TargetID: postID, UserID: userID, Emoji: "👍"},
+			setupMocks: func(
+				ts *txMocks.MockTxStarter,
+				pr *mocks.MockPostsRepository,
+				cr *mocks.MockCommentsRepository,
+				or *mocks.MockOutboxRepository,
+			) {
+				expectCommit(ts)
+
+				pr.On("GetByID", mock.Anything, postID, true).Return(post, nil)
+				pr.On("AddReaction", mock.Anything, postID, userID, "👍").Return(false, nil)
+				pr.On("GetReactions", mock.Anything, []uuid.UUID{postID}, &userID).Return(
+					map[uuid.UUID][]*models.ReactionCount{postID: reactions}, nil,
+				)
+			},
+			expectedReactions: reactions,
+		},
+		{
+			name:   "remove",
+			input:  &dtos.ReactionRequest{TargetID: postID, UserID: userID, Emoji: "👍"},
+			remove: true,
+			setupMocks: func(
+				ts *txMocks.MockTxStarter,
+				pr *mocks.MockPostsRepository,
+				cr *mocks.MockCommentsRepository,
+				or *mocks.MockOutboxRepository,
+			) {
+				expectCommit(ts)
+
+				pr.On("GetByID", mock.Anything, postID, true).Return(post, nil)
+				pr.On("DeleteReaction", mock.Anything, postID, userID, "👍").Return(true, nil)
+				pr.On("GetReactions", mock.Anything, []uuid.UUID{postID}, &userID).Return(
+					map[uuid.UUID][]*models.ReactionCount{postID: {}}, nil,
+				)
+				expectEvent(or, postID, 0)
+			},
+			expectedReactions: []*models.ReactionCount{},
+		},
+		{
+			name:  "emoji not allowed",
+			input: &dtos.ReactionRequest{TargetID: postID, UserID: userID, Emoji: "🦆"},
+			setupMocks: func(
+				ts *txMocks.MockTxStarter,
+				pr *mocks.MockPostsRepository,
+				cr *mocks.MockCommentsRepository,
+				or *mocks.MockOutboxRepository,
+			) {
+			},
+			expectedError: errs.ErrReactionNotAllowed,
+		},
+		{
+			name:  "target not found",
+			input: &dtos.ReactionRequest{TargetID: commentID, UserID: userID, Emoji: "👍"},
+			setupMocks: func(
+				ts *txMocks.MockTxStarter,
+				pr *mocks.MockPostsRepository,
+				cr *mocks.MockCommentsRepository,
+				or *mocks.MockOutboxRepository,
+			) {
+				expectRollback(ts)
+
+				pr.On("GetByID", mock.Anything, commentID, true).Return(nil, fmt.Errorf("post %w", errs.ErrNotFound))
+				cr.On("GetByID", mock.Anything, commentID, true).Return(nil, fmt.Errorf("comment %w", errs.ErrNotFound))
+			},
+			expectedError: errs.ErrNotFound,
+		},
+		{
+			name:  "comment deleted",
+			input: &dtos.ReactionRequest{TargetID: commentID, UserID: userID, Emoji: "👍"},
+			setupMocks: func(
+				ts *txMocks.MockTxStarter,
+				pr *mocks.MockPostsRepository,
+				cr *mocks.MockCommentsRepository,
+				or *mocks.MockOutboxRepository,
+			) {
+				expectRollback(ts)
+
+				pr.On("GetByID", mock.Anything, commentID, true).Return(nil, fmt.Errorf("post %w", errs.ErrNotFound))
+				cr.On("GetByID", mock.Anything, commentID, true).Return(
+					&models.Comment{ID: commentID, PostID: postID, DeletedAt: &deletedAt}, nil,
+				)
+			},
+			expectedError: errs.ErrCommentDeleted,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockTxStarter := txMocks.NewMockTxStarter(t)
+			mockPostsRepo := mocks.NewMockPostsRepository(t)
+			mockCommentsRepo := mocks.NewMockCommentsRepository(t)
+			mockOutboxRepo := mocks.NewMockOutboxRepository(t)
+
+			tc.setupMocks(
+				mockTxStarter,
+				mockPostsRepo,
+				mockCommentsRepo,
+				mockOutboxRepo,
+			)
+
+			reactionsService := services.NewReactionsService(
+				mockTxStarter,
+				mockPostsRepo,
+				mockCommentsRepo,
+				mockOutboxRepo,
+			)
+
+			var (
+				reactions []*models.ReactionCount
+				err       error
+			)
+			if tc.remove {
+				reactions, err = reactionsService.RemoveReaction(context.Background(), tc.input)
+			} else {
+				reactions, err = reactionsService.AddReaction(context.Background(), tc.input)
+			}
+
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tc.expectedReactions, reactions)
+
+			mockTxStarter.AssertExpectations(t)
+			mockPostsRepo.AssertExpectations(t)
+			mockCommentsRepo.AssertExpectations(t)
+			mockOutboxRepo.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package services
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"slices"
+
+	"github.com/Govorov1705/ozon-test/internal/dtos"
+	"github.com/Govorov1705/ozon-test/internal/errs"
+	"github.com/Govorov1705/ozon-test/internal/models"
+	"github.com/Govorov1705/ozon-test/internal/pagination"
+	"github.com/Govorov1705/ozon-test/internal/repositories"
+	"github.com/Govorov1705/ozon-test/internal/search"
+)
+
+type SearchService struct {
+	postsRepo    repositories.PostsRepository
+	commentsRepo repositories.CommentsRepository
+}
+
+func NewSearchService(pr repositories.PostsRepository, cr repositories.CommentsRepository) *SearchService {
+	return &SearchService{
+		postsRepo:    pr,
+		commentsRepo: cr,
+	}
+}
+
+// Search возвращает результаты по убыванию релевантности. При поиске по
+// постам и комментариям сразу каждый репозиторий отдает страницу от одного
+// и того же курсора, а страницы сливаются в одну
+func (s *SearchService) Search(ctx context.Context, req *dtos.SearchRequest) (*dtos.SearchConnection, error) {
+	if len(search.Terms(req.Query)) == 0 {
+		return nil, errs.ErrInvalidSearchQuery
+	}
+
+	page, err := pagination.NewSortedPage(req.First, req.After, pagination.SortRelevance)
+	if err != nil {
+		return nil, err
+	}
+
+	hits := []*models.SearchHit{}
+
+	if req.Type != dtos.SearchComments {
+		posts, err := s.postsRepo.Search(ctx, req.Query, page)
+		if err != nil {
+			return nil, err
+		}
+		hits = append(hits, posts...)
+	}
+
+	if req.Type != dtos.SearchPosts {
+		comments, err := s.commentsRepo.Search(ctx, req.Query, page)
+		if err != nil {
+			return nil, err
+		}
+		hits = append(hits, comments...)
+	}
+
+	slices.SortFunc(hits, compareHits)
+	if int32(len(hits)) > page.Limit {
+		hits = hits[:page.Limit]
+	}
+
+	cursor := func(hit *models.SearchHit) string {
+		return pagination.EncodeRankedCursor(hit.Key())
+	}
+
+	hits, pageInfo := buildPage(hits, page, cursor)
+
+	edges := make([]*dtos.SearchEdge, len(hits))
+	for i, hit := range hits {
+		edges[i] = &dtos.SearchEdge{
+			Cursor: cursor(hit),
+			Node:   hit,
+		}
+	}
+
+	return &dtos.SearchConnection{
+		Edges:    edges,
+		PageInfo: pageInfo,
+	}, nil
+}
+
+// compareHits задает порядок (rank DESC, created_at DESC, id DESC),
+// в котором репозитории отдают результаты
+func compareHits(a, b *models.SearchHit) int {
+	aRank, aCreatedAt, aID := a.Key()
+	bRank, bCreatedAt, bID := b.Key()
+
+	if order := cmp.Compare(bRank, aRank); order != 0 {
+		return order
+	}
+	if order := bCreatedAt.Compare(aCreatedAt); order != 0 {
+		return order
+	}
+	return bytes.Compare(bID[:], aID[:])
+}
@@ -0,0 +1,172 @@
+package services_test
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/Govorov1705/ozon-test/internal/dtos"
+	"github.com/Govorov1705/ozon-test/internal/errs"
+	"github.com/Govorov1705/ozon-test/internal/models"
+	"github.com/Govorov1705/ozon-test/internal/pagination"
+	"github.com/Govorov1705/ozon-test/internal/repositories/mocks"
+	"github.com/Govorov1705/ozon-test/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSearchService_Search(t *testing.T) {
+	type testCase struct {
+		name       string
+		input      *dtos.SearchRequest
+		setupMocks func(
+			pr *mocks.MockPostsRepository,
+			cr *mocks.MockCommentsRepository,
+		)
+		expectedHits     []*models.SearchHit
+		expectedNextPage bool
+		expectedError    error
+	}
+
+	strPtr := func(s string) *string { return &s }
+
+	now := time.Now()
+	first := int32(2)
+
+	bestPost := &models.SearchHit{Post: &models.Post{CreatedAt: now}, Rank: 0.9}
+	otherPost := &models.SearchHit{Post: &models.Post{CreatedAt: now}, Rank: 0.2}
+	bestComment := &models.SearchHit{Comment: &models.Comment{CreatedAt: now}, Rank: 0.5}
+	otherComment := &models.SearchHit{Comment: &models.Comment{CreatedAt: now}, Rank: 0.1}
+
+	// Оба репозитория получают одну и ту же страницу по релевантности
+	relevancePage := mock.MatchedBy(func(page *pagination.Page) bool {
+		return page.Sort == pagination.SortRelevance && page.Limit == first+1
+	})
+
+	testCases := []testCase{
+		{
+			name:  "OK (all)",
+			input: &dtos.SearchRequest{Query: "golang", Type: dtos.SearchAll, First: &first},
+			setupMocks: func(
+				pr *mocks.MockPostsRepository,
+				cr *mocks.MockCommentsRepository,
+			) {
+				pr.On("Search", mock.Anything, "golang", relevancePage).Return(
+					[]*models.SearchHit{bestPost, otherPost}, nil,
+				)
+				cr.On("Search", mock.Anything, "golang", relevancePage).Return(
+					[]*models.SearchHit{bestComment, otherComment}, nil,
+				)
+			},
+			expectedHits:     []*models.SearchHit{bestPost, bestComment},
+			expectedNextPage: true,
+		},
+		{
+			name:  "OK (posts)",
+			input: &dtos.SearchRequest{Query: "golang", Type: dtos.SearchPosts, First: &first},
+			setupMocks: func(
+				pr *mocks.MockPostsRepository,
+				cr *mocks.MockCommentsRepository,
+			) {
+				pr.On("Search", mock.Anything, "golang", relevancePage).Return(
+					[]*models.SearchHit{bestPost, otherPost}, nil,
+				)
+			},
+			expectedHits: []*models.SearchHit{bestPost, otherPost},
+		},
+		{
+			name:  "OK (comments)",
+			input: &dtos.SearchRequest{Query: "golang", Type: dtos.SearchComments, First: &first},
+			setupMocks: func(
+				pr *mocks.MockPostsRepository,
+				cr *mocks.MockCommentsRepository,
+			) {
+				cr.On("Search", mock.Anything, "golang", relevancePage).Return(
+					[]*models.SearchHit{bestComment}, nil,
+				)
+			},
+			expectedHits: []*models.SearchHit{bestComment},
+		},
+		{
+			name:  "no words in query",
+			input: &dtos.SearchRequest{Query: "?!", Type: dtos.SearchAll},
+			setupMocks: func(
+				pr *mocks.MockPostsRepository,
+				cr *mocks.MockCommentsRepository,
+			) {
+			},
+			expectedError: errs.ErrInvalidSearchQuery,
+		},
+		{
+			name: "cursor from another ordering",
+			input: &dtos.SearchRequest{
+				Query: "golang",
+				Type:  dtos.SearchAll,
+				After: strPtr(pagination.EncodeCursor(now, bestPost.Post.ID)),
+			},
+			setupMocks: func(
+				pr *mocks.MockPostsRepository,
+				cr *mocks.MockCommentsRepository,
+			) {
+			},
+			expectedError: errs.ErrInvalidCursor,
+		},
+		{
+			name: "cursor with non-finite rank",
+			input: &dtos.SearchRequest{
+				Query: "golang",
+				Type:  dtos.SearchAll,
+				After: strPtr(pagination.EncodeRankedCursor(math.NaN(), now, bestPost.Post.ID)),
+			},
+			setupMocks: func(
+				pr *mocks.MockPostsRepository,
+				cr *mocks.MockCommentsRepository,
+			) {
+			},
+			expectedError: errs.ErrInvalidCursor,
+		},
+		{
+			name:  "repository error",
+			input: &dtos.SearchRequest{Query: "golang", Type: dtos.SearchAll},
+			setupMocks: func(
+				pr *mocks.MockPostsRepository,
+				cr *mocks.MockCommentsRepository,
+			) {
+				pr.On("Search", mock.Anything, "golang", mock.Anything).Return(nil, errs.ErrInternal)
+			},
+			expectedError: errs.ErrInternal,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockPostsRepo := mocks.NewMockPostsRepository(t)
+			mockCommentsRepo := mocks.NewMockCommentsRepository(t)
+
+			tc.setupMocks(mockPostsRepo, mockCommentsRepo)
+
+			searchService := services.NewSearchService(mockPostsRepo, mockCommentsRepo)
+
+			connection, err := searchService.Search(context.Background(), tc.input)
+
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+				assert.Nil(t, connection)
+			} else {
+				assert.NoError(t, err)
+
+				hits := make([]*models.SearchHit, len(connection.Edges))
+				for i, edge := range connection.Edges {
+					hits[i] = edge.Node
+					assert.Equal(t, pagination.EncodeRankedCursor(edge.Node.Key()), edge.Cursor)
+				}
+				assert.Equal(t, tc.expectedHits, hits)
+				assert.Equal(t, tc.expectedNextPage, connection.PageInfo.HasNextPage)
+			}
+
+			mockPostsRepo.AssertExpectations(t)
+			mockCommentsRepo.AssertExpectations(t)
+		})
+	}
+}
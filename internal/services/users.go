@@ -2,7 +2,16 @@ package services
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Govorov1705/ozon-test/config"
 
 	"github.com/Govorov1705/ozon-test/internal/dtos"
 	"github.com/Govorov1705/ozon-test/internal/errs"
@@ -11,47 +20,262 @@ import (
 	"github.com/Govorov1705/ozon-test/internal/models"
 	pwd "github.com/Govorov1705/ozon-test/internal/password"
 	"github.com/Govorov1705/ozon-test/internal/repositories"
+	"github.com/Govorov1705/ozon-test/internal/transactions"
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 	"golang.org/x/crypto/bcrypt"
 )
 
+// dummyPasswordHash сверяется с паролем, когда пользователь не найден, чтобы
+// по времени ответа нельзя было понять, существует ли username. Хеш
+// строится через pwd.HashPassword, поэтому его стоимость совпадает с настоящими
+var dummyPasswordHash = sync.OnceValue(func() []byte {
+	hashedPassword, err := pwd.HashPassword("dummy password")
+	if err != nil {
+		panic(err)
+	}
+	return []byte(hashedPassword)
+})
+
 type UsersService struct {
-	usersRepo repositories.UsersRepository
+	txStarter         transactions.TxStarter
+	usersRepo         repositories.UsersRepository
+	refreshTokensRepo repositories.RefreshTokensRepository
+}
+
+func NewUsersService(
+	txStarter transactions.TxStarter,
+	ur repositories.UsersRepository,
+	rtr repositories.RefreshTokensRepository,
+) *UsersService {
+	return &UsersService{
+		txStarter:         txStarter,
+		usersRepo:         ur,
+		refreshTokensRepo: rtr,
+	}
+}
+
+func (s *UsersService) Register(ctx context.Context, input *dtos.RegisterRequest) (*dtos.TokenPair, error) {
+	user, err := s.createUser(ctx, input.Username, input.Password)
+	if err != nil {
+		if errors.Is(err, errs.ErrAlreadyExists) {
+			return nil, fmt.Errorf("user %w", errs.ErrAlreadyExists)
+		}
+		return nil, err
+	}
+
+	return s.issueTokens(ctx, user.ID, uuid.New())
+}
+
+func (s *UsersService) Login(ctx context.Context, input *dtos.LoginRequest) (*dtos.TokenPair, error) {
+	user, err := s.usersRepo.GetByUsername(ctx, input.Username)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			bcrypt.CompareHashAndPassword(dummyPasswordHash(), []byte(input.Password))
+			return nil, errs.ErrInvalidCredentials
+		}
+		return nil, err
+	}
+
+	err = bcrypt.CompareHashAndPassword([]byte(user.HashedPassword), []byte(input.Password))
+	if err != nil {
+		return nil, errs.ErrInvalidCredentials
+	}
+
+	return s.issueTokens(ctx, user.ID, uuid.New())
+}
+
+// Auth - устаревший способ аутентификации, при котором неизвестный username
+// приводит к регистрации нового пользователя. Оставлен для совместимости
+func (s *UsersService) Auth(ctx context.Context, input *dtos.AuthRequest) (*dtos.TokenPair, error) {
+	user, err := s.usersRepo.GetByUsername(ctx, input.Username)
+	if err != nil {
+		if !errors.Is(err, errs.ErrNotFound) {
+			return nil, err
+		}
+
+		user, err = s.createUser(ctx, input.Username, input.Password)
+		if err != nil {
+			if errors.Is(err, errs.ErrAlreadyExists) {
+				return nil, errs.ErrInvalidCredentials
+			}
+			return nil, err
+		}
+
+		return s.issueTokens(ctx, user.ID, uuid.New())
+	}
+
+	err = bcrypt.CompareHashAndPassword([]byte(user.HashedPassword), []byte(input.Password))
+	if err != nil {
+		return nil, errs.ErrInvalidCredentials
+	}
+
+	return s.issueTokens(ctx, user.ID, uuid.New())
+}
+
+func (s *UsersService) createUser(ctx context.Context, username, password string) (*models.User, error) {
+	hashedPassword, err := pwd.HashPassword(password)
+	if err != nil {
+		logger.Logger.Error("error hashing password", zap.Error(err))
+		return nil, errs.ErrInternal
+	}
+
+	return s.usersRepo.Add(ctx, username, hashedPassword)
+}
+
+func (s *UsersService) GetByID(ctx context.Context, ID uuid.UUID) (*models.User, error) {
+	return s.usersRepo.GetByID(ctx, ID)
+}
+
+func (s *UsersService) GetByUsername(ctx context.Context, username string) (*models.User, error) {
+	return s.usersRepo.GetByUsername(ctx, username)
+}
+
+// GetByIDs возвращает найденных пользователей в произвольном порядке;
+// отсутствующие ID пропускаются
+func (s *UsersService) GetByIDs(ctx context.Context, IDs []uuid.UUID) ([]*models.User, error) {
+	return s.usersRepo.GetByIDs(ctx, IDs)
+}
+
+// RefreshToken обменивает refresh-токен на новую пару токенов. Предъявленный
+// токен отзывается; повторное предъявление уже отозванного токена считается
+// признаком утечки, и тогда отзывается вся сессия
+func (s *UsersService) RefreshToken(ctx context.Context, input *dtos.RefreshTokenRequest) (*dtos.TokenPair, error) {
+	tokens, reusedSessionID, err := s.rotateRefreshToken(ctx, hashRefreshToken(input.RefreshToken))
+	if reusedSessionID != nil {
+		if revokeErr := s.refreshTokensRepo.RevokeSession(ctx, *reusedSessionID); revokeErr != nil {
+			return nil, revokeErr
+		}
+	}
+
+	return tokens, err
 }
 
-func NewUsersService(ur repositories.UsersRepository) *UsersService {
-	return &UsersService{usersRepo: ur}
+// Logout отзывает сессию, к которой относится текущий access-токен
+func (s *UsersService) Logout(ctx context.Context, tokenID uuid.UUID) error {
+	token, err := s.refreshTokensRepo.GetByID(ctx, tokenID)
+	if err != nil {
+		return err
+	}
+
+	return s.refreshTokensRepo.RevokeSession(ctx, token.SessionID)
 }
 
-func (s *UsersService) Auth(ctx context.Context, input *dtos.AuthRequest) (token string, err error) {
-	var user *models.User
+func (s *UsersService) LogoutAllSessions(ctx context.Context, userID uuid.UUID) error {
+	return s.refreshTokensRepo.RevokeAllByUserID(ctx, userID)
+}
 
-	user, err = s.usersRepo.GetByUsername(ctx, input.Username)
+// IsSessionActive сообщает, действителен ли refresh-токен, выданный вместе
+// с access-токеном. После ротации или выхода access-токен перестает приниматься
+func (s *UsersService) IsSessionActive(ctx context.Context, tokenID uuid.UUID) (bool, error) {
+	token, err := s.refreshTokensRepo.GetByID(ctx, tokenID)
 	if err != nil {
 		if errors.Is(err, errs.ErrNotFound) {
-			hashedPassword, err := pwd.HashPassword(input.Password)
-			if err != nil {
-				return "", errs.ErrInternal
+			return false, nil
+		}
+		return false, err
+	}
+
+	return token.RevokedAt == nil && time.Now().Before(token.ExpiresAt), nil
+}
+
+func (s *UsersService) rotateRefreshToken(ctx context.Context, tokenHash string) (tokens *dtos.TokenPair, reusedSessionID *uuid.UUID, err error) {
+	tx, err := s.txStarter.Begin(ctx)
+	if err != nil {
+		logger.Logger.Error("error starting transaction", zap.Error(err))
+		return nil, nil, errs.ErrInternal
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback(ctx)
+			panic(p)
+		} else if err != nil {
+			if rollbackErr := tx.Rollback(ctx); rollbackErr != nil {
+				logger.Logger.Error("rollback failed", zap.Error(rollbackErr))
+				err = errs.ErrInternal
 			}
-			user, err = s.usersRepo.Add(ctx, input.Username, hashedPassword)
-			if err != nil {
-				if errors.Is(err, errs.ErrAlreadyExists) {
-					return "", errs.ErrInvalidCredentials
-				}
+		} else {
+			if commitErr := tx.Commit(ctx); commitErr != nil {
+				logger.Logger.Error("error committing transaction", zap.Error(commitErr))
+				err = errs.ErrInternal
 			}
 		}
-	} else {
-		err := bcrypt.CompareHashAndPassword([]byte(user.HashedPassword), []byte(input.Password))
-		if err != nil {
-			return "", errs.ErrInvalidCredentials
+	}()
+
+	ctx = transactions.PutTxIntoContext(ctx, tx)
+
+	token, err := s.refreshTokensRepo.GetByTokenHash(ctx, tokenHash, true)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			return nil, nil, errs.ErrInvalidRefreshToken
 		}
+		return nil, nil, err
 	}
 
-	token, err = jwt.CreateJWT(user.ID.String())
+	if token.RevokedAt != nil {
+		return nil, &token.SessionID, errs.ErrInvalidRefreshToken
+	}
+
+	if !time.Now().Before(token.ExpiresAt) {
+		return nil, nil, errs.ErrInvalidRefreshToken
+	}
+
+	err = s.refreshTokensRepo.Revoke(ctx, token.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tokens, err = s.issueTokens(ctx, token.UserID, token.SessionID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return tokens, nil, nil
+}
+
+func (s *UsersService) issueTokens(ctx context.Context, userID, sessionID uuid.UUID) (*dtos.TokenPair, error) {
+	refreshToken, err := generateRefreshToken()
+	if err != nil {
+		logger.Logger.Error("error generating refresh token", zap.Error(err))
+		return nil, errs.ErrInternal
+	}
+
+	token, err := s.refreshTokensRepo.Add(
+		ctx,
+		uuid.New(),
+		sessionID,
+		userID,
+		hashRefreshToken(refreshToken),
+		time.Now().Add(config.Cfg.RefreshTokenTTL),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	accessToken, err := jwt.CreateJWT(userID.String(), token.ID.String())
 	if err != nil {
 		logger.Logger.Error("error creating JWT", zap.Error(err))
-		return "", errs.ErrInternal
+		return nil, errs.ErrInternal
 	}
 
-	return token, nil
+	return &dtos.TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+	}, nil
+}
+
+func generateRefreshToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// В хранилище попадает только хеш: утечка таблицы не дает рабочих токенов
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
 }
@@ -0,0 +1,439 @@
+package services_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Govorov1705/ozon-test/config"
+	"github.com/Govorov1705/ozon-test/internal/dtos"
+	"github.com/Govorov1705/ozon-test/internal/errs"
+	"github.com/Govorov1705/ozon-test/internal/models"
+	"github.com/Govorov1705/ozon-test/internal/repositories/mocks"
+	"github.com/Govorov1705/ozon-test/internal/services"
+	txMocks "github.com/Govorov1705/ozon-test/internal/transactions/mocks"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestUsersService_Register(t *testing.T) {
+	config.Cfg.SecretKey = "test secret key"
+
+	type testCase struct {
+		name          string
+		input         *dtos.RegisterRequest
+		setupMocks    func(ur *mocks.MockUsersRepository, rtr *mocks.MockRefreshTokensRepository)
+		expectedError error
+	}
+
+	username := "test_user"
+	password := "Str0ngPassword"
+
+	testCases := []testCase{
+		{
+			name: "OK",
+			input: &dtos.RegisterRequest{
+				Username: username,
+				Password: password,
+			},
+			setupMocks: func(ur *mocks.MockUsersRepository, rtr *mocks.MockRefreshTokensRepository) {
+				userID := uuid.New()
+				ur.On("Add", mock.Anything, username, mock.AnythingOfType("string")).Return(
+					&models.User{
+						ID:       userID,
+						Username: username,
+					}, nil,
+				)
+				expectRefreshTokenAdded(rtr, userID)
+			},
+			expectedError: nil,
+		},
+		{
+			name: "username already taken",
+			input: &dtos.RegisterRequest{
+				Username: username,
+				Password: password,
+			},
+			setupMocks: func(ur *mocks.MockUsersRepository, rtr *mocks.MockRefreshTokensRepository) {
+				ur.On("Add", mock.Anything, username, mock.AnythingOfType("string")).Return(
+					nil, errs.ErrAlreadyExists,
+				)
+			},
+			expectedError: errs.ErrAlreadyExists,
+		},
+		{
+			name: "usersRepo.Add error",
+			input: &dtos.RegisterRequest{
+				Username: username,
+				Password: password,
+			},
+			setupMocks: func(ur *mocks.MockUsersRepository, rtr *mocks.MockRefreshTokensRepository) {
+				ur.On("Add", mock.Anything, username, mock.AnythingOfType("string")).Return(
+					nil, errs.ErrInternal,
+				)
+			},
+			expectedError: errs.ErrInternal,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockTxStarter := txMocks.NewMockTxStarter(t)
+			mockUsersRepo := mocks.NewMockUsersRepository(t)
+			mockRefreshTokensRepo := mocks.NewMockRefreshTokensRepository(t)
+
+			tc.setupMocks(mockUsersRepo, mockRefreshTokensRepo)
+
+			usersService := services.NewUsersService(mockTxStarter, mockUsersRepo, mockRefreshTokensRepo)
+
+			token, err := usersService.Register(context.Background(), tc.input)
+
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+				assert.Empty(t, token)
+			} else {
+				assert.NoError(t, err)
+				assert.NotEmpty(t, token)
+			}
+
+			mockUsersRepo.AssertExpectations(t)
+			mockRefreshTokensRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUsersService_Login(t *testing.T) {
+	config.Cfg.SecretKey = "test secret key"
+
+	type testCase struct {
+		name          string
+		input         *dtos.LoginRequest
+		setupMocks    func(ur *mocks.MockUsersRepository, rtr *mocks.MockRefreshTokensRepository)
+		expectedError error
+	}
+
+	username := "test_user"
+	password := "Str0ngPassword"
+	errSome := errors.New("some error")
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user := &models.User{
+		ID:             uuid.New(),
+		Username:       username,
+		HashedPassword: string(hashedPassword),
+	}
+
+	testCases := []testCase{
+		{
+			name: "OK",
+			input: &dtos.LoginRequest{
+				Username: username,
+				Password: password,
+			},
+			setupMocks: func(ur *mocks.MockUsersRepository, rtr *mocks.MockRefreshTokensRepository) {
+				ur.On("GetByUsername", mock.Anything, username).Return(user, nil)
+				expectRefreshTokenAdded(rtr, user.ID)
+			},
+			expectedError: nil,
+		},
+		{
+			name: "unknown username",
+			input: &dtos.LoginRequest{
+				Username: username,
+				Password: password,
+			},
+			setupMocks: func(ur *mocks.MockUsersRepository, rtr *mocks.MockRefreshTokensRepository) {
+				ur.On("GetByUsername", mock.Anything, username).Return(nil, errs.ErrNotFound)
+			},
+			expectedError: errs.ErrInvalidCredentials,
+		},
+		{
+			name: "wrong password",
+			input: &dtos.LoginRequest{
+				Username: username,
+				Password: "Wr0ngPassword",
+			},
+			setupMocks: func(ur *mocks.MockUsersRepository, rtr *mocks.MockRefreshTokensRepository) {
+				ur.On("GetByUsername", mock.Anything, username).Return(user, nil)
+			},
+			expectedError: errs.ErrInvalidCredentials,
+		},
+		{
+			name: "usersRepo.GetByUsername error",
+			input: &dtos.LoginRequest{
+				Username: username,
+				Password: password,
+			},
+			setupMocks: func(ur *mocks.MockUsersRepository, rtr *mocks.MockRefreshTokensRepository) {
+				ur.On("GetByUsername", mock.Anything, username).Return(nil, errSome)
+			},
+			expectedError: errSome,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockTxStarter := txMocks.NewMockTxStarter(t)
+			mockUsersRepo := mocks.NewMockUsersRepository(t)
+			mockRefreshTokensRepo := mocks.NewMockRefreshTokensRepository(t)
+
+			tc.setupMocks(mockUsersRepo, mockRefreshTokensRepo)
+
+			usersService := services.NewUsersService(mockTxStarter, mockUsersRepo, mockRefreshTokensRepo)
+
+			token, err := usersService.Login(context.Background(), tc.input)
+
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+				assert.Empty(t, token)
+			} else {
+				assert.NoError(t, err)
+				assert.NotEmpty(t, token)
+			}
+
+			mockUsersRepo.AssertExpectations(t)
+			mockRefreshTokensRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUsersService_RefreshToken(t *testing.T) {
+	config.Cfg.SecretKey = "test secret key"
+
+	type testCase struct {
+		name       string
+		setupMocks func(
+			ts *txMocks.MockTxStarter,
+			rtr *mocks.MockRefreshTokensRepository,
+		)
+		expectedError error
+	}
+
+	refreshToken := "refresh-token"
+	sum := sha256.Sum256([]byte(refreshToken))
+	tokenHash := hex.EncodeToString(sum[:])
+
+	userID := uuid.New()
+	sessionID := uuid.New()
+	revokedAt := time.Now().Add(-time.Minute)
+
+	activeToken := &models.RefreshToken{
+		ID:        uuid.New(),
+		SessionID: sessionID,
+		UserID:    userID,
+		TokenHash: tokenHash,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+
+	testCases := []testCase{
+		{
+			name: "OK",
+			setupMocks: func(
+				ts *txMocks.MockTxStarter,
+				rtr *mocks.MockRefreshTokensRepository,
+			) {
+				mockTx := &txMocks.MockTx{}
+
+				ts.On("Begin", mock.Anything).Return(mockTx, nil)
+
+				mockTx.On("Commit", mock.Anything).Return(nil)
+
+				rtr.On("GetByTokenHash", mock.Anything, tokenHash, true).Return(activeToken, nil)
+				rtr.On("Revoke", mock.Anything, activeToken.ID).Return(nil)
+				rtr.On(
+					"Add",
+					mock.Anything,
+					mock.AnythingOfType("uuid.UUID"),
+					sessionID,
+					userID,
+					mock.AnythingOfType("string"),
+					mock.AnythingOfType("time.Time"),
+				).Return(&models.RefreshToken{ID: uuid.New()}, nil)
+			},
+			expectedError: nil,
+		},
+		{
+			name: "unknown token",
+			setupMocks: func(
+				ts *txMocks.MockTxStarter,
+				rtr *mocks.MockRefreshTokensRepository,
+			) {
+				mockTx := &txMocks.MockTx{}
+
+				ts.On("Begin", mock.Anything).Return(mockTx, nil)
+
+				mockTx.On("Rollback", mock.Anything).Return(nil)
+
+				rtr.On("GetByTokenHash", mock.Anything, tokenHash, true).Return(nil, errs.ErrNotFound)
+			},
+			expectedError: errs.ErrInvalidRefreshToken,
+		},
+		{
+			name: "expired token",
+			setupMocks: func(
+				ts *txMocks.MockTxStarter,
+				rtr *mocks.MockRefreshTokensRepository,
+			) {
+				mockTx := &txMocks.MockTx{}
+
+				ts.On("Begin", mock.Anything).Return(mockTx, nil)
+
+				mockTx.On("Rollback", mock.Anything).Return(nil)
+
+				rtr.On("GetByTokenHash", mock.Anything, tokenHash, true).Return(
+					&models.RefreshToken{
+						ID:        uuid.New(),
+						SessionID: sessionID,
+						UserID:    userID,
+						TokenHash: tokenHash,
+						ExpiresAt: time.Now().Add(-time.Hour),
+					}, nil,
+				)
+			},
+			expectedError: errs.ErrInvalidRefreshToken,
+		},
+		{
+			name: "reused token revokes session",
+			setupMocks: func(
+				ts *txMocks.MockTxStarter,
+				rtr *mocks.MockRefreshTokensRepository,
+			) {
+				mockTx := &txMocks.MockTx{}
+
+				ts.On("Begin", mock.Anything).Return(mockTx, nil)
+
+				mockTx.On("Rollback", mock.Anything).Return(nil)
+
+				rtr.On("GetByTokenHash", mock.Anything, tokenHash, true).Return(
+					&models.RefreshToken{
+						ID:        uuid.New(),
+						SessionID: sessionID,
+						UserID:    userID,
+						TokenHash: tokenHash,
+						ExpiresAt: time.Now().Add(time.Hour),
+						RevokedAt: &revokedAt,
+					}, nil,
+				)
+				rtr.On("RevokeSession", mock.Anything, sessionID).Return(nil)
+			},
+			expectedError: errs.ErrInvalidRefreshToken,
+		},
+		{
+			name: "txStarter.Begin error",
+			setupMocks: func(
+				ts *txMocks.MockTxStarter,
+				rtr *mocks.MockRefreshTokensRepository,
+			) {
+				ts.On("Begin", mock.Anything).Return(nil, errors.New("some error"))
+			},
+			expectedError: errs.ErrInternal,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockTxStarter := txMocks.NewMockTxStarter(t)
+			mockUsersRepo := mocks.NewMockUsersRepository(t)
+			mockRefreshTokensRepo := mocks.NewMockRefreshTokensRepository(t)
+
+			tc.setupMocks(mockTxStarter, mockRefreshTokensRepo)
+
+			usersService := services.NewUsersService(mockTxStarter, mockUsersRepo, mockRefreshTokensRepo)
+
+			tokens, err := usersService.RefreshToken(
+				context.Background(),
+				&dtos.RefreshTokenRequest{RefreshToken: refreshToken},
+			)
+
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+				assert.Nil(t, tokens)
+			} else {
+				assert.NoError(t, err)
+				assert.NotEmpty(t, tokens.AccessToken)
+				assert.NotEmpty(t, tokens.RefreshToken)
+				assert.NotEqual(t, refreshToken, tokens.RefreshToken)
+			}
+
+			mockTxStarter.AssertExpectations(t)
+			mockRefreshTokensRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUsersService_Logout(t *testing.T) {
+	type testCase struct {
+		name          string
+		setupMocks    func(rtr *mocks.MockRefreshTokensRepository)
+		expectedError error
+	}
+
+	tokenID := uuid.New()
+	sessionID := uuid.New()
+
+	testCases := []testCase{
+		{
+			name: "OK",
+			setupMocks: func(rtr *mocks.MockRefreshTokensRepository) {
+				rtr.On("GetByID", mock.Anything, tokenID).Return(
+					&models.RefreshToken{
+						ID:        tokenID,
+						SessionID: sessionID,
+					}, nil,
+				)
+				rtr.On("RevokeSession", mock.Anything, sessionID).Return(nil)
+			},
+			expectedError: nil,
+		},
+		{
+			name: "refreshTokensRepo.GetByID error",
+			setupMocks: func(rtr *mocks.MockRefreshTokensRepository) {
+				rtr.On("GetByID", mock.Anything, tokenID).Return(nil, errs.ErrNotFound)
+			},
+			expectedError: errs.ErrNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockTxStarter := txMocks.NewMockTxStarter(t)
+			mockUsersRepo := mocks.NewMockUsersRepository(t)
+			mockRefreshTokensRepo := mocks.NewMockRefreshTokensRepository(t)
+
+			tc.setupMocks(mockRefreshTokensRepo)
+
+			usersService := services.NewUsersService(mockTxStarter, mockUsersRepo, mockRefreshTokensRepo)
+
+			err := usersService.Logout(context.Background(), tokenID)
+
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			mockRefreshTokensRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func expectRefreshTokenAdded(rtr *mocks.MockRefreshTokensRepository, userID uuid.UUID) {
+	rtr.On(
+		"Add",
+		mock.Anything,
+		mock.AnythingOfType("uuid.UUID"),
+		mock.AnythingOfType("uuid.UUID"),
+		userID,
+		mock.AnythingOfType("string"),
+		mock.AnythingOfType("time.Time"),
+	).Return(&models.RefreshToken{ID: uuid.New()}, nil)
+}
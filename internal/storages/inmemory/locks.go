@@ -0,0 +1,142 @@
+package inmemory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// Таблица блокировок общая для всех репозиториев in-memory хранилища
+var rowLocks = &lockManager{
+	owners:  make(map[string]*InMemoryTx),
+	waiters: make(map[string]chan struct{}),
+}
+
+type lockManager struct {
+	mu      sync.Mutex
+	owners  map[string]*InMemoryTx
+	waiters map[string]chan struct{}
+}
+
+func rowKey(table string, ID uuid.UUID) string {
+	return table + ":" + ID.String()
+}
+
+// acquire ждет, пока строку отпустит другой владелец. Повторный захват
+// тем же владельцем ничего не меняет. Возвращает true, если строка
+// захвачена этим вызовом
+func (m *lockManager) acquire(ctx context.Context, key string, owner *InMemoryTx) (bool, error) {
+	for {
+		acquired, wait := m.tryAcquire(key, owner)
+		if wait == nil {
+			return acquired, nil
+		}
+
+		select {
+		case <-wait:
+		case <-ctx.Done():
+			return false, ctx.Err()
+		}
+	}
+}
+
+// tryAcquire не ждет: если строку держит другой владелец, возвращает
+// канал, который закроется при ее освобождении
+func (m *lockManager) tryAcquire(key string, owner *InMemoryTx) (bool, <-chan struct{}) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	current, ok := m.owners[key]
+	if !ok {
+		m.owners[key] = owner
+		return true, nil
+	}
+	if current == owner {
+		return false, nil
+	}
+
+	wait, ok := m.waiters[key]
+	if !ok {
+		wait = make(chan struct{})
+		m.waiters[key] = wait
+	}
+
+	return false, wait
+}
+
+func (m *lockManager) release(key string, owner *InMemoryTx) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.owners[key] != owner {
+		return
+	}
+	delete(m.owners, key)
+
+	if wait, ok := m.waiters[key]; ok {
+		close(wait)
+		delete(m.waiters, key)
+	}
+}
+
+func releaseAll(keys []string, owner *InMemoryTx) {
+	for _, key := range keys {
+		rowLocks.release(key, owner)
+	}
+}
+
+// LockRow блокирует строку таблицы, как SELECT ... FOR UPDATE или UPDATE.
+// В транзакции блокировка держится до ее завершения, а release ничего
+// не делает. Вне транзакции вызов дожидается чужих блокировок, и строку
+// нужно отпустить через release после изменения.
+// Вызывающий не должен удерживать мьютекс репозитория, пока ждет блокировку
+func LockRow(ctx context.Context, table string, ID uuid.UUID) (release func(), err error) {
+	key := rowKey(table, ID)
+
+	tx, ok := txFromContext(ctx)
+	if !ok {
+		owner := &InMemoryTx{}
+		_, err := rowLocks.acquire(ctx, key, owner)
+		if err != nil {
+			return nil, err
+		}
+		return func() { rowLocks.release(key, owner) }, nil
+	}
+
+	acquired, err := rowLocks.acquire(ctx, key, tx)
+	if err != nil {
+		return nil, err
+	}
+	if acquired {
+		tx.addLock(key)
+	}
+
+	return func() {}, nil
+}
+
+// TryLockRow работает как FOR UPDATE SKIP LOCKED: не ждет и возвращает
+// false, если строку держит другая транзакция. Вне транзакции только
+// проверяет, что строка свободна. Можно вызывать под мьютексом репозитория
+func TryLockRow(ctx context.Context, table string, ID uuid.UUID) bool {
+	key := rowKey(table, ID)
+
+	tx, ok := txFromContext(ctx)
+	if !ok {
+		rowLocks.mu.Lock()
+		defer rowLocks.mu.Unlock()
+
+		_, locked := rowLocks.owners[key]
+		return !locked
+	}
+
+	acquired, wait := rowLocks.tryAcquire(key, tx)
+	if wait != nil {
+		return false
+	}
+	if acquired {
+		tx.addLock(key)
+	}
+
+	return true
+}
@@ -2,25 +2,43 @@ package repositories
 
 import (
 	"context"
-	"sort"
+	"fmt"
 	"sync"
 	"time"
 
 	"github.com/Govorov1705/ozon-test/internal/errs"
 	"github.com/Govorov1705/ozon-test/internal/models"
+	"github.com/Govorov1705/ozon-test/internal/pagination"
 	"github.com/Govorov1705/ozon-test/internal/repositories"
+	"github.com/Govorov1705/ozon-test/internal/search"
+	"github.com/Govorov1705/ozon-test/internal/storages/inmemory"
 	"github.com/google/uuid"
 )
 
 type InMemoryCommentsRepository struct {
-	mu       sync.RWMutex
-	comments map[uuid.UUID]*models.Comment
+	mu        sync.RWMutex
+	comments  map[uuid.UUID]*models.Comment
+	revisions map[uuid.UUID][]*models.CommentRevision
+	// Голоса по комментарию и пользователю
+	votes     map[uuid.UUID]map[uuid.UUID]*models.CommentVote
+	reactions *reactions
+	index     *searchIndex
+	storage   *inmemory.Storage
 }
 
-func NewCommentsRepository() repositories.CommentsRepository {
-	return &InMemoryCommentsRepository{
-		comments: make(map[uuid.UUID]*models.Comment),
+// В журнале ревизии хранятся списком, а голоса - словарем на комментарий
+func NewCommentsRepository(storage *inmemory.Storage) repositories.CommentsRepository {
+	r := &InMemoryCommentsRepository{
+		comments:  make(map[uuid.UUID]*models.Comment),
+		revisions: make(map[uuid.UUID][]*models.CommentRevision),
+		votes:     make(map[uuid.UUID]map[uuid.UUID]*models.CommentVote),
+		index:     newSearchIndex(),
+		storage:   storage,
 	}
+	r.reactions = newReactions(&r.mu, storage, commentReactionsTable)
+	storage.Register(r, commentsTable, commentRevisionsTable, commentVotesTable, commentReactionsTable)
+
+	return r
 }
 
 func (r *InMemoryCommentsRepository) Add(ctx context.Context, postID, userID uuid.UUID, rootID, replyTo *uuid.UUID, content string) (*models.Comment, error) {
@@ -43,76 +61,573 @@ func (r *InMemoryCommentsRepository) Add(ctx context.Context, postID, userID uui
 	}
 
 	r.comments[comment.ID] = comment
+	r.indexComment(comment)
+	r.storage.Put(ctx, commentsTable, comment.ID, comment)
+	inmemory.TryLockRow(ctx, commentsTable, comment.ID)
+	inmemory.OnRollback(ctx, func() {
+		r.mu.Lock()
+		delete(r.comments, comment.ID)
+		r.index.remove(comment.ID)
+		r.mu.Unlock()
+	})
 
-	return comment, nil
+	return clone(comment), nil
 }
 
 func (r *InMemoryCommentsRepository) GetByID(ctx context.Context, commentID uuid.UUID, forUpdate bool) (*models.Comment, error) {
+	if forUpdate {
+		release, err := lockRow(ctx, commentsTable, commentID)
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+	}
+
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
 	comment, ok := r.comments[commentID]
 	if !ok {
-		return nil, errs.ErrNotFound
+		return nil, fmt.Errorf("comment %w", errs.ErrNotFound)
 	}
 
-	return comment, nil
+	return clone(comment), nil
 }
 
-func (r *InMemoryCommentsRepository) GetRootCommentsByPostID(ctx context.Context, postID uuid.UUID, limit, offset *int32) ([]*models.Comment, error) {
+func (r *InMemoryCommentsRepository) GetByIDs(ctx context.Context, IDs []uuid.UUID) ([]*models.Comment, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	var rootsComments []*models.Comment
+	comments := make([]*models.Comment, 0, len(IDs))
 
-	for _, comment := range r.comments {
-		if comment.PostID == postID && comment.ReplyTo == nil {
-			rootsComments = append(rootsComments, comment)
+	for _, ID := range IDs {
+		comment, ok := r.comments[ID]
+		if ok {
+			comments = append(comments, comment)
 		}
 	}
 
-	sort.Slice(rootsComments, func(i, j int) bool {
-		return rootsComments[i].CreatedAt.After(rootsComments[j].CreatedAt)
-	})
+	return cloneAll(comments), nil
+}
+
+func (r *InMemoryCommentsRepository) GetByPostID(ctx context.Context, postID uuid.UUID, page *pagination.Page) ([]*models.Comment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	comments := []*models.Comment{}
 
-	start := int32(0)
-	if offset != nil {
-		start = *offset
+	for _, comment := range r.comments {
+		if comment.PostID == postID {
+			comments = append(comments, comment)
+		}
 	}
 
-	end := int32(len(rootsComments))
-	if limit != nil && start+*limit < end {
-		end = start + *limit
+	return cloneAll(paginate(comments, func(c *models.Comment) (time.Time, uuid.UUID) {
+		return c.CreatedAt, c.ID
+	}, page)), nil
+}
+
+func (r *InMemoryCommentsRepository) GetRootCommentsByPostID(ctx context.Context, postID uuid.UUID, page *pagination.Page) ([]*models.Comment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	rootComments := []*models.Comment{}
+
+	for _, comment := range r.comments {
+		if comment.PostID == postID && comment.ReplyTo == nil {
+			rootComments = append(rootComments, comment)
+		}
 	}
 
-	if start > end {
-		return []*models.Comment{}, nil
+	return cloneAll(paginateRanked(rootComments, func(c *models.Comment) (float64, time.Time, uuid.UUID) {
+		return c.Rank(page.Sort), c.CreatedAt, c.ID
+	}, page)), nil
+}
+
+func (r *InMemoryCommentsRepository) GetRepliesByParentID(ctx context.Context, parentID uuid.UUID, page *pagination.Page) ([]*models.Comment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	replies := []*models.Comment{}
+
+	for _, comment := range r.comments {
+		if comment.ReplyTo != nil && *comment.ReplyTo == parentID {
+			replies = append(replies, comment)
+		}
 	}
 
-	return rootsComments[start:end], nil
+	return cloneAll(paginate(replies, func(c *models.Comment) (time.Time, uuid.UUID) {
+		return c.CreatedAt, c.ID
+	}, page)), nil
 }
 
-func (r *InMemoryCommentsRepository) GetChildrenCommentsByRootIDs(ctx context.Context, rootIDs []*uuid.UUID) ([]*models.Comment, error) {
+func (r *InMemoryCommentsRepository) GetRepliesByParentIDs(ctx context.Context, parentIDs []uuid.UUID, limit int32) ([]*models.Comment, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
+	repliesByParent := make(map[uuid.UUID][]*models.Comment, len(parentIDs))
+	for _, ID := range parentIDs {
+		repliesByParent[ID] = []*models.Comment{}
+	}
+
+	for _, comment := range r.comments {
+		if comment.ReplyTo == nil {
+			continue
+		}
+		replies, ok := repliesByParent[*comment.ReplyTo]
+		if ok {
+			repliesByParent[*comment.ReplyTo] = append(replies, comment)
+		}
+	}
+
 	comments := []*models.Comment{}
-	IDSet := map[uuid.UUID]struct{}{}
+	page := &pagination.Page{Limit: limit}
+	key := func(c *models.Comment) (time.Time, uuid.UUID) {
+		return c.CreatedAt, c.ID
+	}
 
-	for _, ID := range rootIDs {
-		IDSet[*ID] = struct{}{}
+	for _, replies := range repliesByParent {
+		comments = append(comments, paginate(replies, key, page)...)
+	}
+
+	return cloneAll(paginate(comments, key, &pagination.Page{Limit: int32(len(comments))})), nil
+}
+
+func (r *InMemoryCommentsRepository) CountRepliesByParentIDs(ctx context.Context, parentIDs []uuid.UUID) (map[uuid.UUID]int32, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	counts := make(map[uuid.UUID]int32, len(parentIDs))
+	for _, ID := range parentIDs {
+		counts[ID] = 0
 	}
 
 	for _, comment := range r.comments {
-		_, ok := IDSet[comment.RootID]
+		if comment.ReplyTo == nil {
+			continue
+		}
+		count, ok := counts[*comment.ReplyTo]
 		if ok {
-			comments = append(comments, comment)
+			counts[*comment.ReplyTo] = count + 1
+		}
+	}
+
+	return counts, nil
+}
+
+func (r *InMemoryCommentsRepository) UpdateContent(ctx context.Context, commentID uuid.UUID, content string) (*models.Comment, error) {
+	return r.update(ctx, commentID, func(comment *models.Comment) {
+		now := time.Now()
+		comment.Content = content
+		comment.EditedAt = &now
+	})
+}
+
+func (r *InMemoryCommentsRepository) AddRevision(ctx context.Context, commentID uuid.UUID, content string) (*models.CommentRevision, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	revision := &models.CommentRevision{
+		ID:        uuid.New(),
+		CommentID: commentID,
+		Content:   content,
+		CreatedAt: time.Now(),
+	}
+
+	r.revisions[commentID] = append(r.revisions[commentID], revision)
+	r.storage.Put(ctx, commentRevisionsTable, commentID, r.revisions[commentID])
+	inmemory.OnRollback(ctx, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		revisions := r.revisions[commentID]
+		for i, rev := range revisions {
+			if rev == revision {
+				r.revisions[commentID] = append(revisions[:i:i], revisions[i+1:]...)
+				break
+			}
+		}
+	})
+
+	return clone(revision), nil
+}
+
+func (r *InMemoryCommentsRepository) GetRevisionsByCommentIDs(ctx context.Context, commentIDs []uuid.UUID) (map[uuid.UUID][]*models.CommentRevision, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	revisions := make(map[uuid.UUID][]*models.CommentRevision, len(commentIDs))
+	for _, ID := range commentIDs {
+		if commentRevisions, ok := r.revisions[ID]; ok {
+			revisions[ID] = cloneAll(commentRevisions)
 		}
 	}
 
-	sort.Slice(comments, func(i, j int) bool {
-		return comments[i].CreatedAt.After(comments[j].CreatedAt)
+	return revisions, nil
+}
+
+func (r *InMemoryCommentsRepository) SoftDelete(ctx context.Context, commentID uuid.UUID) (*models.Comment, error) {
+	return r.update(ctx, commentID, func(comment *models.Comment) {
+		now := time.Now()
+		comment.Content = ""
+		comment.DeletedAt = &now
 	})
+}
+
+func (r *InMemoryCommentsRepository) HasUndeletedReplies(ctx context.Context, commentID uuid.UUID) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, reply := range r.subtree(commentID) {
+		if reply.DeletedAt == nil {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func (r *InMemoryCommentsRepository) Delete(ctx context.Context, commentID uuid.UUID) error {
+	release, err := lockRow(ctx, commentsTable, commentID)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	comment, ok := r.comments[commentID]
+	if !ok {
+		return fmt.Errorf("comment %w", errs.ErrNotFound)
+	}
+
+	removed := append(r.subtree(commentID), comment)
+	removedRevisions := make(map[uuid.UUID][]*models.CommentRevision)
+	removedVotes := make(map[uuid.UUID]map[uuid.UUID]*models.CommentVote)
+	removedReactions := make(map[uuid.UUID][]*models.Reaction)
+	for _, c := range removed {
+		if revisions, ok := r.revisions[c.ID]; ok {
+			removedRevisions[c.ID] = revisions
+		}
+		if votes, ok := r.votes[c.ID]; ok {
+			removedVotes[c.ID] = votes
+		}
+		delete(r.comments, c.ID)
+		r.index.remove(c.ID)
+		delete(r.revisions, c.ID)
+		delete(r.votes, c.ID)
+		r.storage.Delete(ctx, commentsTable, c.ID)
+		r.storage.Delete(ctx, commentRevisionsTable, c.ID)
+		r.storage.Delete(ctx, commentVotesTable, c.ID)
+		if reactions := r.reactions.drop(ctx, c.ID); reactions != nil {
+			removedReactions[c.ID] = reactions
+		}
+	}
+
+	inmemory.OnRollback(ctx, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		for _, c := range removed {
+			r.comments[c.ID] = c
+			r.indexComment(c)
+		}
+		for ID, revisions := range removedRevisions {
+			r.revisions[ID] = revisions
+		}
+		for ID, votes := range removedVotes {
+			r.votes[ID] = votes
+		}
+		for ID, reactions := range removedReactions {
+			r.reactions.rows[ID] = reactions
+		}
+	})
+
+	return nil
+}
+
+func (r *InMemoryCommentsRepository) GetVote(ctx context.Context, commentID, userID uuid.UUID) (*models.CommentVote, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	vote, ok := r.votes[commentID][userID]
+	if !ok {
+		return nil, fmt.Errorf("vote %w", errs.ErrNotFound)
+	}
+
+	return clone(vote), nil
+}
+
+func (r *InMemoryCommentsRepository) SetVote(ctx context.Context, commentID, userID uuid.UUID, value int8) (*models.CommentVote, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	old, existed := r.votes[commentID][userID]
+
+	vote := &models.CommentVote{
+		CommentID: commentID,
+		UserID:    userID,
+		Value:     value,
+		CreatedAt: time.Now(),
+	}
+	if existed {
+		vote.CreatedAt = old.CreatedAt
+	}
+
+	r.putVote(ctx, vote)
+	inmemory.OnRollback(ctx, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		if existed {
+			r.votes[commentID][userID] = old
+		} else {
+			delete(r.votes[commentID], userID)
+		}
+	})
+
+	return clone(vote), nil
+}
+
+func (r *InMemoryCommentsRepository) DeleteVote(ctx context.Context, commentID, userID uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	old, ok := r.votes[commentID][userID]
+	if !ok {
+		return nil
+	}
+
+	delete(r.votes[commentID], userID)
+	if len(r.votes[commentID]) == 0 {
+		delete(r.votes, commentID)
+		r.storage.Delete(ctx, commentVotesTable, commentID)
+	} else {
+		r.storage.Put(ctx, commentVotesTable, commentID, r.votes[commentID])
+	}
+	inmemory.OnRollback(ctx, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		if r.votes[commentID] == nil {
+			r.votes[commentID] = make(map[uuid.UUID]*models.CommentVote)
+		}
+		r.votes[commentID][userID] = old
+	})
+
+	return nil
+}
+
+// putVote сохраняет голос. Вызывающий должен удерживать мьютекс
+func (r *InMemoryCommentsRepository) putVote(ctx context.Context, vote *models.CommentVote) {
+	votes, ok := r.votes[vote.CommentID]
+	if !ok {
+		votes = make(map[uuid.UUID]*models.CommentVote)
+		r.votes[vote.CommentID] = votes
+	}
+	votes[vote.UserID] = vote
+	r.storage.Put(ctx, commentVotesTable, vote.CommentID, votes)
+}
+
+func (r *InMemoryCommentsRepository) UpdateVotes(ctx context.Context, commentID uuid.UUID, votes models.CommentVotes) (*models.Comment, error) {
+	return r.update(ctx, commentID, func(comment *models.Comment) {
+		comment.CommentVotes = votes
+	})
+}
+
+func (r *InMemoryCommentsRepository) AddReaction(ctx context.Context, commentID, userID uuid.UUID, emoji string) (bool, error) {
+	return r.reactions.add(ctx, commentID, userID, emoji), nil
+}
+
+func (r *InMemoryCommentsRepository) DeleteReaction(ctx context.Context, commentID, userID uuid.UUID, emoji string) (bool, error) {
+	return r.reactions.delete(ctx, commentID, userID, emoji), nil
+}
+
+func (r *InMemoryCommentsRepository) GetReactions(ctx context.Context, commentIDs []uuid.UUID, viewerID *uuid.UUID) (map[uuid.UUID][]*models.ReactionCount, error) {
+	return r.reactions.counts(commentIDs, viewerID), nil
+}
+
+func (r *InMemoryCommentsRepository) Search(ctx context.Context, query string, page *pagination.Page) ([]*models.SearchHit, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	terms := search.Terms(query)
+	ranks := r.index.match(terms)
+
+	hits := make([]*models.SearchHit, 0, len(ranks))
+	for ID, rank := range ranks {
+		comment := r.comments[ID]
+		if comment.DeletedAt == nil {
+			hits = append(hits, &models.SearchHit{Comment: comment, Rank: rank})
+		}
+	}
+
+	hits = paginateRanked(hits, (*models.SearchHit).Key, page)
+	for _, hit := range hits {
+		hit.Comment = clone(hit.Comment)
+		hit.Snippet = search.Snippet(hit.Comment.Content, terms)
+	}
+
+	return hits, nil
+}
+
+// indexComment обновляет комментарий в поисковом индексе. Вызывающий
+// должен удерживать мьютекс
+func (r *InMemoryCommentsRepository) indexComment(comment *models.Comment) {
+	r.index.put(comment.ID, searchField{comment.Content, contentWeight})
+}
+
+// update блокирует комментарий, как UPDATE в PostgreSQL, и при откате
+// транзакции возвращает его прежнее состояние
+func (r *InMemoryCommentsRepository) update(ctx context.Context, commentID uuid.UUID, apply func(comment *models.Comment)) (*models.Comment, error) {
+	release, err := lockRow(ctx, commentsTable, commentID)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	comment, ok := r.comments[commentID]
+	if !ok {
+		return nil, fmt.Errorf("comment %w", errs.ErrNotFound)
+	}
+
+	old := *comment
+	apply(comment)
+	r.indexComment(comment)
+	r.storage.Put(ctx, commentsTable, comment.ID, comment)
+	inmemory.OnRollback(ctx, func() {
+		r.mu.Lock()
+		*comment = old
+		r.indexComment(comment)
+		r.mu.Unlock()
+	})
+
+	return clone(comment), nil
+}
+
+// subtree возвращает все ответы на комментарий на любой глубине.
+// Вызывающий должен удерживать мьютекс
+func (r *InMemoryCommentsRepository) subtree(commentID uuid.UUID) []*models.Comment {
+	replies := []*models.Comment{}
+
+	level := []uuid.UUID{commentID}
+	for len(level) > 0 {
+		parents := make(map[uuid.UUID]struct{}, len(level))
+		for _, ID := range level {
+			parents[ID] = struct{}{}
+		}
+
+		level = level[:0]
+		for _, comment := range r.comments {
+			if comment.ReplyTo == nil {
+				continue
+			}
+			if _, ok := parents[*comment.ReplyTo]; ok {
+				replies = append(replies, comment)
+				level = append(level, comment.ID)
+			}
+		}
+	}
+
+	return replies
+}
+
+func (r *InMemoryCommentsRepository) CountByUserIDs(ctx context.Context, userIDs []uuid.UUID) (map[uuid.UUID]int32, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	counts := make(map[uuid.UUID]int32, len(userIDs))
+	for _, ID := range userIDs {
+		counts[ID] = 0
+	}
+
+	for _, comment := range r.comments {
+		if comment.DeletedAt != nil {
+			continue
+		}
+		if _, ok := counts[comment.UserID]; ok {
+			counts[comment.UserID]++
+		}
+	}
+
+	return counts, nil
+}
+
+func (r *InMemoryCommentsRepository) Restore(record *inmemory.Record) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if record.Table == commentReactionsTable {
+		return r.reactions.restore(record)
+	}
+
+	if record.Table == commentVotesTable {
+		votes, err := decodeRecord[map[uuid.UUID]*models.CommentVote](record)
+		if err != nil {
+			return err
+		}
+		if votes == nil {
+			delete(r.votes, record.Key)
+		} else {
+			r.votes[record.Key] = *votes
+		}
+		return nil
+	}
+
+	if record.Table == commentRevisionsTable {
+		revisions, err := decodeRecord[[]*models.CommentRevision](record)
+		if err != nil {
+			return err
+		}
+		if revisions == nil {
+			delete(r.revisions, record.Key)
+		} else {
+			r.revisions[record.Key] = *revisions
+		}
+		return nil
+	}
+
+	comment, err := decodeRecord[models.Comment](record)
+	if err != nil {
+		return err
+	}
+	if comment == nil {
+		delete(r.comments, record.Key)
+		r.index.remove(record.Key)
+	} else {
+		r.comments[record.Key] = comment
+		r.indexComment(comment)
+	}
+
+	return nil
+}
+
+func (r *InMemoryCommentsRepository) Dump(emit func(record *inmemory.Record)) error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for ID, comment := range r.comments {
+		err := dumpRecord(emit, commentsTable, ID, comment)
+		if err != nil {
+			return err
+		}
+	}
+	for ID, revisions := range r.revisions {
+		err := dumpRecord(emit, commentRevisionsTable, ID, revisions)
+		if err != nil {
+			return err
+		}
+	}
+	for ID, votes := range r.votes {
+		err := dumpRecord(emit, commentVotesTable, ID, votes)
+		if err != nil {
+			return err
+		}
+	}
 
-	return comments, nil
+	return r.reactions.dump(emit)
 }
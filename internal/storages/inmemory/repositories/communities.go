@@ -0,0 +1,311 @@
+package repositories
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/Govorov1705/ozon-test/internal/errs"
+	"github.com/Govorov1705/ozon-test/internal/models"
+	"github.com/Govorov1705/ozon-test/internal/repositories"
+	"github.com/Govorov1705/ozon-test/internal/storages/inmemory"
+	"github.com/google/uuid"
+)
+
+type InMemoryCommunitiesRepository struct {
+	mu          sync.RWMutex
+	communities map[uuid.UUID]*models.Community
+	slugs       map[string]uuid.UUID
+	// Участники сообщества в порядке вступления. Список и его элементы не
+	// меняются на месте, а заменяются, поэтому при откате достаточно
+	// вернуть прежний список
+	members map[uuid.UUID][]*models.CommunityMember
+	storage *inmemory.Storage
+}
+
+func NewCommunitiesRepository(storage *inmemory.Storage) repositories.CommunitiesRepository {
+	r := &InMemoryCommunitiesRepository{
+		communities: make(map[uuid.UUID]*models.Community),
+		slugs:       make(map[string]uuid.UUID),
+		members:     make(map[uuid.UUID][]*models.CommunityMember),
+		storage:     storage,
+	}
+	storage.Register(r, communitiesTable, communityMembersTable)
+
+	return r
+}
+
+func (r *InMemoryCommunitiesRepository) Add(ctx context.Context, ownerID uuid.UUID, name, slug, description string, areCommentsAllowed bool) (*models.Community, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, ok := r.slugs[slug]
+	if ok {
+		return nil, errs.ErrAlreadyExists
+	}
+
+	community := &models.Community{
+		ID:                 uuid.New(),
+		OwnerID:            ownerID,
+		Name:               name,
+		Slug:               slug,
+		Description:        description,
+		AreCommentsAllowed: areCommentsAllowed,
+		CreatedAt:          time.Now(),
+	}
+
+	r.communities[community.ID] = community
+	r.slugs[slug] = community.ID
+	r.storage.Put(ctx, communitiesTable, community.ID, community)
+	inmemory.TryLockRow(ctx, communitiesTable, community.ID)
+	inmemory.OnRollback(ctx, func() {
+		r.mu.Lock()
+		delete(r.communities, community.ID)
+		delete(r.slugs, slug)
+		r.mu.Unlock()
+	})
+
+	return clone(community), nil
+}
+
+func (r *InMemoryCommunitiesRepository) GetByID(ctx context.Context, communityID uuid.UUID, forUpdate bool) (*models.Community, error) {
+	if forUpdate {
+		release, err := lockRow(ctx, communitiesTable, communityID)
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	community, ok := r.communities[communityID]
+	if !ok {
+		return nil, fmt.Errorf("community %w", errs.ErrNotFound)
+	}
+
+	return clone(community), nil
+}
+
+func (r *InMemoryCommunitiesRepository) GetByIDs(ctx context.Context, IDs []uuid.UUID) ([]*models.Community, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	communities := make([]*models.Community, 0, len(IDs))
+
+	for _, ID := range IDs {
+		community, ok := r.communities[ID]
+		if ok {
+			communities = append(communities, community)
+		}
+	}
+
+	return cloneAll(communities), nil
+}
+
+func (r *InMemoryCommunitiesRepository) GetBySlug(ctx context.Context, slug string) (*models.Community, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	ID, ok := r.slugs[slug]
+	if !ok {
+		return nil, fmt.Errorf("community %w", errs.ErrNotFound)
+	}
+
+	return clone(r.communities[ID]), nil
+}
+
+func (r *InMemoryCommunitiesRepository) AddMember(ctx context.Context, communityID, userID uuid.UUID, role models.CommunityRole) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	old := r.members[communityID]
+	if slices.ContainsFunc(old, matchMember(userID)) {
+		return false, nil
+	}
+
+	member := &models.CommunityMember{
+		CommunityID: communityID,
+		UserID:      userID,
+		Role:        role,
+		JoinedAt:    time.Now(),
+	}
+	r.replaceMembers(ctx, communityID, append(slices.Clip(old), member))
+
+	return true, nil
+}
+
+func (r *InMemoryCommunitiesRepository) DeleteMember(ctx context.Context, communityID, userID uuid.UUID) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	old := r.members[communityID]
+	i := slices.IndexFunc(old, matchMember(userID))
+	if i < 0 {
+		return false, nil
+	}
+
+	r.replaceMembers(ctx, communityID, slices.Delete(slices.Clone(old), i, i+1))
+
+	return true, nil
+}
+
+func (r *InMemoryCommunitiesRepository) GetMember(ctx context.Context, communityID, userID uuid.UUID) (*models.CommunityMember, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	members := r.members[communityID]
+	i := slices.IndexFunc(members, matchMember(userID))
+	if i < 0 {
+		return nil, fmt.Errorf("community member %w", errs.ErrNotFound)
+	}
+
+	return clone(members[i]), nil
+}
+
+func (r *InMemoryCommunitiesRepository) SetMemberRole(ctx context.Context, communityID, userID uuid.UUID, role models.CommunityRole) (*models.CommunityMember, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	old := r.members[communityID]
+	i := slices.IndexFunc(old, matchMember(userID))
+	if i < 0 {
+		return nil, fmt.Errorf("community member %w", errs.ErrNotFound)
+	}
+
+	member := clone(old[i])
+	member.Role = role
+
+	members := slices.Clone(old)
+	members[i] = member
+	r.replaceMembers(ctx, communityID, members)
+
+	return clone(member), nil
+}
+
+// replaceMembers заменяет список участников сообщества и при откате
+// транзакции возвращает прежний. Вызывающий должен удерживать мьютекс
+func (r *InMemoryCommunitiesRepository) replaceMembers(ctx context.Context, communityID uuid.UUID, members []*models.CommunityMember) {
+	old, existed := r.members[communityID]
+
+	if len(members) == 0 {
+		delete(r.members, communityID)
+		r.storage.Delete(ctx, communityMembersTable, communityID)
+	} else {
+		r.members[communityID] = members
+		r.storage.Put(ctx, communityMembersTable, communityID, members)
+	}
+
+	inmemory.OnRollback(ctx, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		if existed {
+			r.members[communityID] = old
+		} else {
+			delete(r.members, communityID)
+		}
+	})
+}
+
+func (r *InMemoryCommunitiesRepository) GetModerators(ctx context.Context, communityID uuid.UUID) ([]*models.CommunityMember, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	moderators := []*models.CommunityMember{}
+	for _, member := range r.members[communityID] {
+		if member.Role.CanModerate() {
+			moderators = append(moderators, member)
+		}
+	}
+
+	// Порядок добавления совпадает с порядком времени, пока часы не
+	// переводят назад, поэтому сортируем явно, как ORDER BY в SQL
+	slices.SortStableFunc(moderators, func(a, b *models.CommunityMember) int {
+		return cmp.Or(
+			a.JoinedAt.Compare(b.JoinedAt),
+			bytes.Compare(a.UserID[:], b.UserID[:]),
+		)
+	})
+
+	return cloneAll(moderators), nil
+}
+
+func (r *InMemoryCommunitiesRepository) CountMembers(ctx context.Context, communityIDs []uuid.UUID) (map[uuid.UUID]int32, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	counts := make(map[uuid.UUID]int32, len(communityIDs))
+	for _, ID := range communityIDs {
+		counts[ID] = int32(len(r.members[ID]))
+	}
+
+	return counts, nil
+}
+
+func (r *InMemoryCommunitiesRepository) Restore(record *inmemory.Record) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if record.Table == communityMembersTable {
+		members, err := decodeRecord[[]*models.CommunityMember](record)
+		if err != nil {
+			return err
+		}
+
+		if members == nil {
+			delete(r.members, record.Key)
+		} else {
+			r.members[record.Key] = *members
+		}
+		return nil
+	}
+
+	community, err := decodeRecord[models.Community](record)
+	if err != nil {
+		return err
+	}
+
+	if old, ok := r.communities[record.Key]; ok {
+		delete(r.slugs, old.Slug)
+		delete(r.communities, old.ID)
+	}
+	if community != nil {
+		r.communities[community.ID] = community
+		r.slugs[community.Slug] = community.ID
+	}
+
+	return nil
+}
+
+func (r *InMemoryCommunitiesRepository) Dump(emit func(record *inmemory.Record)) error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for ID, community := range r.communities {
+		err := dumpRecord(emit, communitiesTable, ID, community)
+		if err != nil {
+			return err
+		}
+	}
+
+	for ID, members := range r.members {
+		err := dumpRecord(emit, communityMembersTable, ID, members)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func matchMember(userID uuid.UUID) func(member *models.CommunityMember) bool {
+	return func(member *models.CommunityMember) bool {
+		return member.UserID == userID
+	}
+}
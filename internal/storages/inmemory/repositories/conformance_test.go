@@ -0,0 +1,31 @@
+package repositories_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/Govorov1705/ozon-test/internal/logger"
+	"github.com/Govorov1705/ozon-test/internal/repositories/conformance"
+	"github.com/Govorov1705/ozon-test/internal/storages/inmemory"
+	"github.com/Govorov1705/ozon-test/internal/storages/inmemory/repositories"
+)
+
+func TestMain(m *testing.M) {
+	logger.InitLogger()
+	code := m.Run()
+	os.Exit(code)
+}
+
+func TestConformance(t *testing.T) {
+	conformance.Run(t, func(t *testing.T) *conformance.Backend {
+		return &conformance.Backend{
+			TxStarter:     inmemory.NewTxStarter(nil),
+			Users:         repositories.NewUsersRepository(nil),
+			RefreshTokens: repositories.NewRefreshTokensRepository(nil),
+			Posts:         repositories.NewPostsRepository(nil),
+			Comments:      repositories.NewCommentsRepository(nil),
+			Communities:   repositories.NewCommunitiesRepository(nil),
+			Outbox:        repositories.NewOutboxRepository(nil),
+		}
+	})
+}
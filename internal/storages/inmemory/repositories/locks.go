@@ -0,0 +1,39 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/Govorov1705/ozon-test/internal/errs"
+	"github.com/Govorov1705/ozon-test/internal/logger"
+	"github.com/Govorov1705/ozon-test/internal/storages/inmemory"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Имена таблиц совпадают с PostgreSQL и служат пространствами ключей
+// блокировок и таблицами в журнале inmemory.Storage
+const (
+	usersTable            = "users"
+	refreshTokensTable    = "refresh_tokens"
+	postsTable            = "posts"
+	postReactionsTable    = "post_reactions"
+	postTagsTable         = "post_tags"
+	commentsTable         = "comments"
+	commentRevisionsTable = "comment_revisions"
+	commentVotesTable     = "comment_votes"
+	commentReactionsTable = "comment_reactions"
+	communitiesTable      = "communities"
+	communityMembersTable = "community_members"
+	outboxTable           = "outbox"
+)
+
+// lockRow захватывает блокировку строки до изменения и до захвата мьютекса
+// репозитория. Ожидание прерывается только отменой ctx
+func lockRow(ctx context.Context, table string, ID uuid.UUID) (func(), error) {
+	release, err := inmemory.LockRow(ctx, table, ID)
+	if err != nil {
+		logger.Logger.Error("error locking row", zap.String("table", table), zap.Error(err))
+		return nil, errs.ErrInternal
+	}
+	return release, nil
+}
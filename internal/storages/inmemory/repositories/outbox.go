@@ -0,0 +1,173 @@
+package repositories
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Govorov1705/ozon-test/internal/errs"
+	"github.com/Govorov1705/ozon-test/internal/models"
+	"github.com/Govorov1705/ozon-test/internal/repositories"
+	"github.com/Govorov1705/ozon-test/internal/storages/inmemory"
+	"github.com/google/uuid"
+)
+
+type InMemoryOutboxRepository struct {
+	mu      sync.RWMutex
+	events  map[uuid.UUID]*models.OutboxEvent
+	storage *inmemory.Storage
+}
+
+func NewOutboxRepository(storage *inmemory.Storage) repositories.OutboxRepository {
+	r := &InMemoryOutboxRepository{
+		events:  make(map[uuid.UUID]*models.OutboxEvent),
+		storage: storage,
+	}
+	storage.Register(r, outboxTable)
+
+	return r
+}
+
+func (r *InMemoryOutboxRepository) Add(ctx context.Context, ID uuid.UUID, eventType string, payload []byte) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, ok := r.events[ID]
+	if ok {
+		return errs.ErrAlreadyExists
+	}
+
+	event := &models.OutboxEvent{
+		ID:        ID,
+		Type:      eventType,
+		Payload:   payload,
+		CreatedAt: time.Now(),
+	}
+	r.events[ID] = event
+	r.storage.Put(ctx, outboxTable, ID, event)
+	// Пока транзакция не зафиксирована, GetUnprocessed пропускает событие
+	inmemory.TryLockRow(ctx, outboxTable, ID)
+	inmemory.OnRollback(ctx, func() {
+		r.mu.Lock()
+		delete(r.events, ID)
+		r.mu.Unlock()
+	})
+
+	return nil
+}
+
+func (r *InMemoryOutboxRepository) GetUnprocessed(ctx context.Context, limit int32) ([]*models.OutboxEvent, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	events := []*models.OutboxEvent{}
+	for _, event := range r.events {
+		if event.ProcessedAt == nil {
+			events = append(events, event)
+		}
+	}
+
+	sort.Slice(events, func(i, j int) bool {
+		if events[i].CreatedAt.Equal(events[j].CreatedAt) {
+			return events[i].ID.String() < events[j].ID.String()
+		}
+		return events[i].CreatedAt.Before(events[j].CreatedAt)
+	})
+
+	// Как FOR UPDATE SKIP LOCKED: события чужих транзакций пропускаются
+	locked := make([]*models.OutboxEvent, 0, min(len(events), int(limit)))
+	for _, event := range events {
+		if int32(len(locked)) == limit {
+			break
+		}
+		if inmemory.TryLockRow(ctx, outboxTable, event.ID) {
+			locked = append(locked, event)
+		}
+	}
+
+	return cloneAll(locked), nil
+}
+
+func (r *InMemoryOutboxRepository) MarkProcessed(ctx context.Context, IDs []uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	previous := make(map[*models.OutboxEvent]*time.Time, len(IDs))
+	for _, ID := range IDs {
+		if event, ok := r.events[ID]; ok {
+			previous[event] = event.ProcessedAt
+			event.ProcessedAt = &now
+			r.storage.Put(ctx, outboxTable, ID, event)
+		}
+	}
+
+	inmemory.OnRollback(ctx, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		for event, processedAt := range previous {
+			event.ProcessedAt = processedAt
+		}
+	})
+
+	return nil
+}
+
+func (r *InMemoryOutboxRepository) DeleteProcessedBefore(ctx context.Context, before time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	deleted := []*models.OutboxEvent{}
+	for ID, event := range r.events {
+		if event.ProcessedAt != nil && event.ProcessedAt.Before(before) {
+			delete(r.events, ID)
+			r.storage.Delete(ctx, outboxTable, ID)
+			deleted = append(deleted, event)
+		}
+	}
+
+	inmemory.OnRollback(ctx, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		for _, event := range deleted {
+			r.events[event.ID] = event
+		}
+	})
+
+	return nil
+}
+
+func (r *InMemoryOutboxRepository) Restore(record *inmemory.Record) error {
+	event, err := decodeRecord[models.OutboxEvent](record)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if event == nil {
+		delete(r.events, record.Key)
+	} else {
+		r.events[record.Key] = event
+	}
+
+	return nil
+}
+
+func (r *InMemoryOutboxRepository) Dump(emit func(record *inmemory.Record)) error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for ID, event := range r.events {
+		err := dumpRecord(emit, outboxTable, ID, event)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
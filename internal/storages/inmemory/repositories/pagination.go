@@ -0,0 +1,64 @@
+package repositories
+
+import (
+	"bytes"
+	"cmp"
+	"slices"
+	"time"
+
+	"github.com/Govorov1705/ozon-test/internal/pagination"
+	"github.com/google/uuid"
+)
+
+// paginate сортирует записи по (created_at DESC, id DESC) и отбирает из них страницу
+// так же, как это делает keyset-запрос в PostgreSQL
+func paginate[T any](items []T, key func(T) (time.Time, uuid.UUID), page *pagination.Page) []T {
+	return paginateRanked(items, func(item T) (float64, time.Time, uuid.UUID) {
+		createdAt, ID := key(item)
+		return 0, createdAt, ID
+	}, page)
+}
+
+// paginateRanked учитывает порядок page.Sort: в ранжированных выборках записи
+// сначала сравниваются по рейтингу, который возвращает key
+func paginateRanked[T any](items []T, key func(T) (float64, time.Time, uuid.UUID), page *pagination.Page) []T {
+	ranked := page.Sort.Ranked()
+
+	slices.SortFunc(items, func(a, b T) int {
+		aRank, aCreatedAt, aID := key(a)
+		bRank, bCreatedAt, bID := key(b)
+
+		order := 0
+		if ranked {
+			order = cmp.Compare(bRank, aRank)
+		}
+		if order == 0 {
+			order = bCreatedAt.Compare(aCreatedAt)
+		}
+		if order == 0 {
+			order = bytes.Compare(bID[:], aID[:])
+		}
+
+		if page.Sort == pagination.SortOld {
+			return -order
+		}
+		return order
+	})
+
+	if page.Backward {
+		slices.Reverse(items)
+	}
+
+	result := make([]T, 0, page.Limit)
+	for _, item := range items {
+		if int32(len(result)) == page.Limit {
+			break
+		}
+		rank, createdAt, ID := key(item)
+		if page.ContainsRanked(rank, createdAt, ID) {
+			result = append(result, item)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,34 @@
+package repositories
+
+import (
+	"encoding/json"
+
+	"github.com/Govorov1705/ozon-test/internal/storages/inmemory"
+	"github.com/google/uuid"
+)
+
+// decodeRecord возвращает сохраненную строку или nil, если запись - удаление
+func decodeRecord[T any](record *inmemory.Record) (*T, error) {
+	if record.Value == nil {
+		return nil, nil
+	}
+
+	var row T
+	err := json.Unmarshal(record.Value, &row)
+	if err != nil {
+		return nil, err
+	}
+
+	return &row, nil
+}
+
+func dumpRecord(emit func(record *inmemory.Record), table string, key uuid.UUID, value any) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	emit(&inmemory.Record{Table: table, Key: key, Value: data})
+
+	return nil
+}
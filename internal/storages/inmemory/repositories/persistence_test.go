@@ -0,0 +1,147 @@
+package repositories_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Govorov1705/ozon-test/internal/errs"
+	"github.com/Govorov1705/ozon-test/internal/models"
+	"github.com/Govorov1705/ozon-test/internal/repositories/conformance"
+	"github.com/Govorov1705/ozon-test/internal/storages/inmemory"
+	"github.com/Govorov1705/ozon-test/internal/storages/inmemory/repositories"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// openBackend поднимает репозитории из каталога данных dir. Close не
+// вызывается: брошенное хранилище ведет себя как упавший процесс
+func openBackend(t *testing.T, dir string) *conformance.Backend {
+	t.Helper()
+
+	storage, err := inmemory.NewStorage(dir, inmemory.FsyncNever, 0)
+	require.NoError(t, err)
+
+	b := &conformance.Backend{
+		TxStarter:     inmemory.NewTxStarter(storage),
+		Users:         repositories.NewUsersRepository(storage),
+		RefreshTokens: repositories.NewRefreshTokensRepository(storage),
+		Posts:         repositories.NewPostsRepository(storage),
+		Comments:      repositories.NewCommentsRepository(storage),
+		Communities:   repositories.NewCommunitiesRepository(storage),
+		Outbox:        repositories.NewOutboxRepository(storage),
+	}
+	require.NoError(t, storage.Load())
+
+	return b
+}
+
+// Каждая таблица переживает перезапуск без финального снимка
+func TestPersistence_ReplayAfterRestart(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	b := openBackend(t, dir)
+
+	user, err := b.Users.Add(ctx, "alice", "hash")
+	require.NoError(t, err)
+
+	tokenID := uuid.New()
+	_, err = b.RefreshTokens.Add(ctx, tokenID, uuid.New(), user.ID, "token hash", time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	require.NoError(t, b.RefreshTokens.Revoke(ctx, tokenID))
+
+	post, err := b.Posts.Add(ctx, user.ID, nil, "title", "content", true)
+	require.NoError(t, err)
+	_, err = b.Posts.Update(ctx, post.ID, "new title", "new content")
+	require.NoError(t, err)
+	require.NoError(t, b.Posts.SetTags(ctx, post.ID, []string{"golang", "rust"}))
+	_, err = b.Posts.AddReaction(ctx, post.ID, user.ID, "🎉")
+	require.NoError(t, err)
+
+	comment, err := b.Comments.Add(ctx, post.ID, user.ID, nil, nil, "comment")
+	require.NoError(t, err)
+	_, err = b.Comments.AddRevision(ctx, comment.ID, "comment")
+	require.NoError(t, err)
+	_, err = b.Comments.UpdateContent(ctx, comment.ID, "edited")
+	require.NoError(t, err)
+	_, err = b.Comments.SetVote(ctx, comment.ID, user.ID, 1)
+	require.NoError(t, err)
+	_, err = b.Comments.UpdateVotes(ctx, comment.ID, models.CommentVotes{Upvotes: 1, Score: 1})
+	require.NoError(t, err)
+	_, err = b.Comments.AddReaction(ctx, comment.ID, user.ID, "👀")
+	require.NoError(t, err)
+
+	removed, err := b.Comments.Add(ctx, post.ID, user.ID, nil, nil, "removed")
+	require.NoError(t, err)
+	require.NoError(t, b.Comments.Delete(ctx, removed.ID))
+
+	community, err := b.Communities.Add(ctx, user.ID, "Go", "golang", "", true)
+	require.NoError(t, err)
+	_, err = b.Communities.AddMember(ctx, community.ID, user.ID, models.CommunityRoleOwner)
+	require.NoError(t, err)
+
+	processedID, pendingID := uuid.New(), uuid.New()
+	require.NoError(t, b.Outbox.Add(ctx, processedID, "processed", []byte(`{}`)))
+	require.NoError(t, b.Outbox.Add(ctx, pendingID, "pending", []byte(`{}`)))
+	require.NoError(t, b.Outbox.MarkProcessed(ctx, []uuid.UUID{processedID}))
+
+	b = openBackend(t, dir)
+
+	gotUser, err := b.Users.GetByUsername(ctx, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, gotUser.ID)
+
+	gotToken, err := b.RefreshTokens.GetByID(ctx, tokenID)
+	require.NoError(t, err)
+	assert.Equal(t, "token hash", gotToken.TokenHash)
+	assert.NotNil(t, gotToken.RevokedAt)
+
+	gotPost, err := b.Posts.GetByID(ctx, post.ID, false)
+	require.NoError(t, err)
+	assert.Equal(t, "new title", gotPost.Title)
+	assert.NotNil(t, gotPost.UpdatedAt)
+
+	postTags, err := b.Posts.GetTags(ctx, []uuid.UUID{post.ID})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"golang", "rust"}, postTags[post.ID])
+
+	postReactions, err := b.Posts.GetReactions(ctx, []uuid.UUID{post.ID}, &user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []*models.ReactionCount{{Emoji: "🎉", Count: 1, ViewerHasReacted: true}}, postReactions[post.ID])
+
+	gotComment, err := b.Comments.GetByID(ctx, comment.ID, false)
+	require.NoError(t, err)
+	assert.Equal(t, "edited", gotComment.Content)
+	assert.Equal(t, int32(1), gotComment.Score)
+
+	revisions, err := b.Comments.GetRevisionsByCommentIDs(ctx, []uuid.UUID{comment.ID})
+	require.NoError(t, err)
+	require.Len(t, revisions[comment.ID], 1)
+	assert.Equal(t, "comment", revisions[comment.ID][0].Content)
+
+	vote, err := b.Comments.GetVote(ctx, comment.ID, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int8(1), vote.Value)
+
+	commentReactions, err := b.Comments.GetReactions(ctx, []uuid.UUID{comment.ID}, &user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []*models.ReactionCount{{Emoji: "👀", Count: 1, ViewerHasReacted: true}}, commentReactions[comment.ID])
+
+	_, err = b.Comments.GetByID(ctx, removed.ID, false)
+	assert.ErrorIs(t, err, errs.ErrNotFound)
+
+	gotCommunity, err := b.Communities.GetByID(ctx, community.ID, false)
+	require.NoError(t, err)
+	assert.Equal(t, "golang", gotCommunity.Slug)
+
+	member, err := b.Communities.GetMember(ctx, community.ID, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.CommunityRoleOwner, member.Role)
+
+	events, err := b.Outbox.GetUnprocessed(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, pendingID, events[0].ID)
+}
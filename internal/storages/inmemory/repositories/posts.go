@@ -1,35 +1,55 @@
 package repositories
 
 import (
+	"cmp"
 	"context"
+	"fmt"
+	"slices"
 	"sort"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/Govorov1705/ozon-test/internal/errs"
 	"github.com/Govorov1705/ozon-test/internal/models"
+	"github.com/Govorov1705/ozon-test/internal/pagination"
 	"github.com/Govorov1705/ozon-test/internal/repositories"
+	"github.com/Govorov1705/ozon-test/internal/search"
+	"github.com/Govorov1705/ozon-test/internal/storages/inmemory"
 	"github.com/google/uuid"
 )
 
 type InMemoryPostsRepository struct {
 	mu    sync.RWMutex
 	posts map[uuid.UUID]*models.Post
+	// Теги поста по алфавиту. Список не меняется на месте, а заменяется
+	tags      map[uuid.UUID][]string
+	reactions *reactions
+	index     *searchIndex
+	storage   *inmemory.Storage
 }
 
-func NewPostsRepository() repositories.PostsRepository {
-	return &InMemoryPostsRepository{
-		posts: make(map[uuid.UUID]*models.Post),
+func NewPostsRepository(storage *inmemory.Storage) repositories.PostsRepository {
+	r := &InMemoryPostsRepository{
+		posts:   make(map[uuid.UUID]*models.Post),
+		tags:    make(map[uuid.UUID][]string),
+		index:   newSearchIndex(),
+		storage: storage,
 	}
+	r.reactions = newReactions(&r.mu, storage, postReactionsTable)
+	storage.Register(r, postsTable, postTagsTable, postReactionsTable)
+
+	return r
 }
 
-func (r *InMemoryPostsRepository) Add(ctx context.Context, userID uuid.UUID, title, content string, areCommentsAllowed bool) (*models.Post, error) {
+func (r *InMemoryPostsRepository) Add(ctx context.Context, userID uuid.UUID, communityID *uuid.UUID, title, content string, areCommentsAllowed bool) (*models.Post, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
 	post := &models.Post{
 		ID:                 uuid.New(),
 		UserID:             userID,
+		CommunityID:        communityID,
 		Title:              title,
 		Content:            content,
 		AreCommentsAllowed: areCommentsAllowed,
@@ -37,20 +57,37 @@ func (r *InMemoryPostsRepository) Add(ctx context.Context, userID uuid.UUID, tit
 	}
 
 	r.posts[post.ID] = post
+	r.indexPost(post)
+	r.storage.Put(ctx, postsTable, post.ID, post)
+	inmemory.TryLockRow(ctx, postsTable, post.ID)
+	inmemory.OnRollback(ctx, func() {
+		r.mu.Lock()
+		delete(r.posts, post.ID)
+		r.index.remove(post.ID)
+		r.mu.Unlock()
+	})
 
-	return post, nil
+	return clone(post), nil
 }
 
 func (r *InMemoryPostsRepository) GetByID(ctx context.Context, postID uuid.UUID, forUpdate bool) (*models.Post, error) {
+	if forUpdate {
+		release, err := lockRow(ctx, postsTable, postID)
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+	}
+
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
 	post, ok := r.posts[postID]
 	if !ok {
-		return nil, errs.ErrNotFound
+		return nil, fmt.Errorf("post %w", errs.ErrNotFound)
 	}
 
-	return post, nil
+	return clone(post), nil
 }
 
 func (r *InMemoryPostsRepository) GetAll(ctx context.Context) ([]*models.Post, error) {
@@ -60,40 +97,338 @@ func (r *InMemoryPostsRepository) GetAll(ctx context.Context) ([]*models.Post, e
 	posts := make([]*models.Post, 0, len(r.posts))
 
 	for _, post := range r.posts {
-		posts = append(posts, post)
+		if post.DeletedAt == nil {
+			posts = append(posts, post)
+		}
 	}
 
 	sort.Slice(posts, func(i, j int) bool {
 		return posts[i].CreatedAt.After(posts[j].CreatedAt)
 	})
 
-	return posts, nil
+	return cloneAll(posts), nil
+}
+
+func (r *InMemoryPostsRepository) GetPage(ctx context.Context, page *pagination.Page) ([]*models.Post, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	posts := make([]*models.Post, 0, len(r.posts))
+
+	for _, post := range r.posts {
+		if post.DeletedAt == nil {
+			posts = append(posts, post)
+		}
+	}
+
+	return cloneAll(paginate(posts, func(p *models.Post) (time.Time, uuid.UUID) {
+		return p.CreatedAt, p.ID
+	}, page)), nil
+}
+
+func (r *InMemoryPostsRepository) GetPageByTag(ctx context.Context, tag string, page *pagination.Page) ([]*models.Post, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	posts := []*models.Post{}
+
+	for ID, tags := range r.tags {
+		if _, ok := slices.BinarySearch(tags, tag); !ok {
+			continue
+		}
+		if post := r.posts[ID]; post.DeletedAt == nil {
+			posts = append(posts, post)
+		}
+	}
+
+	return cloneAll(paginate(posts, func(p *models.Post) (time.Time, uuid.UUID) {
+		return p.CreatedAt, p.ID
+	}, page)), nil
+}
+
+func (r *InMemoryPostsRepository) GetPageByCommunity(ctx context.Context, communityID uuid.UUID, page *pagination.Page) ([]*models.Post, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	posts := []*models.Post{}
+
+	for _, post := range r.posts {
+		if post.CommunityID != nil && *post.CommunityID == communityID && post.DeletedAt == nil {
+			posts = append(posts, post)
+		}
+	}
+
+	return cloneAll(paginate(posts, func(p *models.Post) (time.Time, uuid.UUID) {
+		return p.CreatedAt, p.ID
+	}, page)), nil
 }
 
 func (r *InMemoryPostsRepository) DisableComments(ctx context.Context, postID uuid.UUID) (*models.Post, error) {
+	return r.update(ctx, postID, func(post *models.Post) {
+		post.AreCommentsAllowed = false
+	})
+}
+
+func (r *InMemoryPostsRepository) EnableComments(ctx context.Context, postID uuid.UUID) (*models.Post, error) {
+	return r.update(ctx, postID, func(post *models.Post) {
+		post.AreCommentsAllowed = true
+	})
+}
+
+func (r *InMemoryPostsRepository) Update(ctx context.Context, postID uuid.UUID, title, content string) (*models.Post, error) {
+	return r.update(ctx, postID, func(post *models.Post) {
+		now := time.Now()
+		post.Title = title
+		post.Content = content
+		post.UpdatedAt = &now
+	})
+}
+
+func (r *InMemoryPostsRepository) SoftDelete(ctx context.Context, postID uuid.UUID) (*models.Post, error) {
+	return r.update(ctx, postID, func(post *models.Post) {
+		now := time.Now()
+		post.Title = ""
+		post.Content = ""
+		post.DeletedAt = &now
+		r.replaceTags(ctx, postID, nil)
+	})
+}
+
+// update блокирует пост, как UPDATE в PostgreSQL, и при откате транзакции
+// возвращает его прежнее состояние
+func (r *InMemoryPostsRepository) update(ctx context.Context, postID uuid.UUID, apply func(post *models.Post)) (*models.Post, error) {
+	release, err := lockRow(ctx, postsTable, postID)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
 	post, ok := r.posts[postID]
 	if !ok {
-		return nil, errs.ErrNotFound
+		return nil, fmt.Errorf("post %w", errs.ErrNotFound)
 	}
 
-	post.AreCommentsAllowed = false
+	old := *post
+	apply(post)
+	r.indexPost(post)
+	r.storage.Put(ctx, postsTable, post.ID, post)
+	inmemory.OnRollback(ctx, func() {
+		r.mu.Lock()
+		*post = old
+		r.indexPost(post)
+		r.mu.Unlock()
+	})
 
-	return post, nil
+	return clone(post), nil
 }
 
-func (r *InMemoryPostsRepository) EnableComments(ctx context.Context, postID uuid.UUID) (*models.Post, error) {
+func (r *InMemoryPostsRepository) CountByUserIDs(ctx context.Context, userIDs []uuid.UUID) (map[uuid.UUID]int32, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	counts := make(map[uuid.UUID]int32, len(userIDs))
+	for _, ID := range userIDs {
+		counts[ID] = 0
+	}
+
+	for _, post := range r.posts {
+		if post.DeletedAt != nil {
+			continue
+		}
+		if _, ok := counts[post.UserID]; ok {
+			counts[post.UserID]++
+		}
+	}
+
+	return counts, nil
+}
+
+func (r *InMemoryPostsRepository) AddReaction(ctx context.Context, postID, userID uuid.UUID, emoji string) (bool, error) {
+	return r.reactions.add(ctx, postID, userID, emoji), nil
+}
+
+func (r *InMemoryPostsRepository) DeleteReaction(ctx context.Context, postID, userID uuid.UUID, emoji string) (bool, error) {
+	return r.reactions.delete(ctx, postID, userID, emoji), nil
+}
+
+func (r *InMemoryPostsRepository) GetReactions(ctx context.Context, postIDs []uuid.UUID, viewerID *uuid.UUID) (map[uuid.UUID][]*models.ReactionCount, error) {
+	return r.reactions.counts(postIDs, viewerID), nil
+}
+
+func (r *InMemoryPostsRepository) SetTags(ctx context.Context, postID uuid.UUID, tags []string) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	post, ok := r.posts[postID]
-	if !ok {
-		return nil, errs.ErrNotFound
+	r.replaceTags(ctx, postID, slices.Sorted(slices.Values(tags)))
+
+	return nil
+}
+
+// replaceTags заменяет список тегов поста и при откате возвращает прежний.
+// Вызывающий должен удерживать мьютекс
+func (r *InMemoryPostsRepository) replaceTags(ctx context.Context, postID uuid.UUID, tags []string) {
+	old, ok := r.tags[postID]
+	if !ok && len(tags) == 0 {
+		return
+	}
+
+	if len(tags) == 0 {
+		delete(r.tags, postID)
+		r.storage.Delete(ctx, postTagsTable, postID)
+	} else {
+		r.tags[postID] = tags
+		r.storage.Put(ctx, postTagsTable, postID, tags)
+	}
+
+	inmemory.OnRollback(ctx, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		if ok {
+			r.tags[postID] = old
+		} else {
+			delete(r.tags, postID)
+		}
+	})
+}
+
+func (r *InMemoryPostsRepository) GetTags(ctx context.Context, postIDs []uuid.UUID) (map[uuid.UUID][]string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tags := make(map[uuid.UUID][]string, len(postIDs))
+	for _, ID := range postIDs {
+		tags[ID] = slices.Clone(r.tags[ID])
+		if tags[ID] == nil {
+			tags[ID] = []string{}
+		}
+	}
+
+	return tags, nil
+}
+
+func (r *InMemoryPostsRepository) SearchTags(ctx context.Context, prefix string, limit int32) ([]*models.Tag, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	counts := make(map[string]int32)
+	for _, tags := range r.tags {
+		for _, tag := range tags {
+			if strings.HasPrefix(tag, prefix) {
+				counts[tag]++
+			}
+		}
 	}
 
-	post.AreCommentsAllowed = true
+	tags := make([]*models.Tag, 0, len(counts))
+	for name, count := range counts {
+		tags = append(tags, &models.Tag{Name: name, PostCount: count})
+	}
+
+	slices.SortFunc(tags, func(a, b *models.Tag) int {
+		if order := cmp.Compare(b.PostCount, a.PostCount); order != 0 {
+			return order
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return tags[:min(len(tags), int(limit))], nil
+}
+
+func (r *InMemoryPostsRepository) Search(ctx context.Context, query string, page *pagination.Page) ([]*models.SearchHit, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	terms := search.Terms(query)
+	ranks := r.index.match(terms)
+
+	hits := make([]*models.SearchHit, 0, len(ranks))
+	for ID, rank := range ranks {
+		post := r.posts[ID]
+		if post.DeletedAt == nil {
+			hits = append(hits, &models.SearchHit{Post: post, Rank: rank})
+		}
+	}
+
+	// Фрагменты строятся только для записей страницы
+	hits = paginateRanked(hits, (*models.SearchHit).Key, page)
+	for _, hit := range hits {
+		hit.Post = clone(hit.Post)
+		hit.Snippet = search.Snippet(hit.Post.Title+"\n"+hit.Post.Content, terms)
+	}
+
+	return hits, nil
+}
+
+// indexPost обновляет пост в поисковом индексе. Вызывающий должен
+// удерживать мьютекс
+func (r *InMemoryPostsRepository) indexPost(post *models.Post) {
+	r.index.put(post.ID, searchField{post.Title, titleWeight}, searchField{post.Content, contentWeight})
+}
+
+func (r *InMemoryPostsRepository) Restore(record *inmemory.Record) error {
+	if record.Table == postReactionsTable {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		return r.reactions.restore(record)
+	}
+
+	if record.Table == postTagsTable {
+		tags, err := decodeRecord[[]string](record)
+		if err != nil {
+			return err
+		}
+
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		if tags == nil {
+			delete(r.tags, record.Key)
+		} else {
+			r.tags[record.Key] = *tags
+		}
+		return nil
+	}
+
+	post, err := decodeRecord[models.Post](record)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if post == nil {
+		delete(r.posts, record.Key)
+		r.index.remove(record.Key)
+	} else {
+		r.posts[record.Key] = post
+		r.indexPost(post)
+	}
+
+	return nil
+}
+
+func (r *InMemoryPostsRepository) Dump(emit func(record *inmemory.Record)) error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for ID, post := range r.posts {
+		err := dumpRecord(emit, postsTable, ID, post)
+		if err != nil {
+			return err
+		}
+	}
+
+	for ID, tags := range r.tags {
+		err := dumpRecord(emit, postTagsTable, ID, tags)
+		if err != nil {
+			return err
+		}
+	}
 
-	return post, nil
+	return r.reactions.dump(emit)
 }
@@ -0,0 +1,175 @@
+package repositories
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/Govorov1705/ozon-test/internal/models"
+	"github.com/Govorov1705/ozon-test/internal/storages/inmemory"
+	"github.com/google/uuid"
+)
+
+// reactions - таблица реакций, общая для постов и комментариев. Она
+// защищена мьютексом репозитория-владельца, чтобы удаление цели и ее
+// реакций было атомарным. Список реакций на цель не меняется на месте,
+// а заменяется, поэтому при откате достаточно вернуть прежний список
+type reactions struct {
+	mu      *sync.RWMutex
+	table   string
+	storage *inmemory.Storage
+	// Реакции по цели в порядке добавления
+	rows map[uuid.UUID][]*models.Reaction
+}
+
+func newReactions(mu *sync.RWMutex, storage *inmemory.Storage, table string) *reactions {
+	return &reactions{
+		mu:      mu,
+		table:   table,
+		storage: storage,
+		rows:    make(map[uuid.UUID][]*models.Reaction),
+	}
+}
+
+func (t *reactions) add(ctx context.Context, targetID, userID uuid.UUID, emoji string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	old := t.rows[targetID]
+	if slices.ContainsFunc(old, matchReaction(userID, emoji)) {
+		return false
+	}
+
+	reaction := &models.Reaction{
+		TargetID:  targetID,
+		UserID:    userID,
+		Emoji:     emoji,
+		CreatedAt: time.Now(),
+	}
+	t.replace(ctx, targetID, append(slices.Clip(old), reaction))
+
+	return true
+}
+
+func (t *reactions) delete(ctx context.Context, targetID, userID uuid.UUID, emoji string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	old := t.rows[targetID]
+	i := slices.IndexFunc(old, matchReaction(userID, emoji))
+	if i < 0 {
+		return false
+	}
+
+	t.replace(ctx, targetID, slices.Delete(slices.Clone(old), i, i+1))
+
+	return true
+}
+
+// replace заменяет список реакций на цель и при откате транзакции
+// возвращает прежний. Вызывающий должен удерживать мьютекс
+func (t *reactions) replace(ctx context.Context, targetID uuid.UUID, rows []*models.Reaction) {
+	old, existed := t.rows[targetID]
+
+	if len(rows) == 0 {
+		delete(t.rows, targetID)
+		t.storage.Delete(ctx, t.table, targetID)
+	} else {
+		t.rows[targetID] = rows
+		t.storage.Put(ctx, t.table, targetID, rows)
+	}
+
+	inmemory.OnRollback(ctx, func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+
+		if existed {
+			t.rows[targetID] = old
+		} else {
+			delete(t.rows, targetID)
+		}
+	})
+}
+
+// drop удаляет реакции на удаленную цель и возвращает их для отката.
+// Вызывающий должен удерживать мьютекс
+func (t *reactions) drop(ctx context.Context, targetID uuid.UUID) []*models.Reaction {
+	rows := t.rows[targetID]
+	delete(t.rows, targetID)
+	t.storage.Delete(ctx, t.table, targetID)
+
+	return rows
+}
+
+func (t *reactions) counts(targetIDs []uuid.UUID, viewerID *uuid.UUID) map[uuid.UUID][]*models.ReactionCount {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	counts := make(map[uuid.UUID][]*models.ReactionCount, len(targetIDs))
+	for _, ID := range targetIDs {
+		byEmoji := make(map[string]*models.ReactionCount)
+		firstAt := make(map[string]time.Time)
+		targetCounts := []*models.ReactionCount{}
+
+		for _, reaction := range t.rows[ID] {
+			count, ok := byEmoji[reaction.Emoji]
+			if !ok {
+				count = &models.ReactionCount{Emoji: reaction.Emoji}
+				byEmoji[reaction.Emoji] = count
+				firstAt[reaction.Emoji] = reaction.CreatedAt
+				targetCounts = append(targetCounts, count)
+			}
+			count.Count++
+			if viewerID != nil && reaction.UserID == *viewerID {
+				count.ViewerHasReacted = true
+			}
+		}
+
+		// Порядок добавления совпадает с порядком времени, пока часы
+		// не переводят назад, поэтому сортируем явно, как ORDER BY в SQL
+		slices.SortStableFunc(targetCounts, func(a, b *models.ReactionCount) int {
+			return cmp.Or(
+				firstAt[a.Emoji].Compare(firstAt[b.Emoji]),
+				cmp.Compare(a.Emoji, b.Emoji),
+			)
+		})
+		counts[ID] = targetCounts
+	}
+
+	return counts
+}
+
+// Вызывающий должен удерживать мьютекс
+func (t *reactions) restore(record *inmemory.Record) error {
+	rows, err := decodeRecord[[]*models.Reaction](record)
+	if err != nil {
+		return err
+	}
+	if rows == nil {
+		delete(t.rows, record.Key)
+	} else {
+		t.rows[record.Key] = *rows
+	}
+
+	return nil
+}
+
+// Вызывающий должен удерживать мьютекс
+func (t *reactions) dump(emit func(record *inmemory.Record)) error {
+	for ID, rows := range t.rows {
+		err := dumpRecord(emit, t.table, ID, rows)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func matchReaction(userID uuid.UUID, emoji string) func(reaction *models.Reaction) bool {
+	return func(reaction *models.Reaction) bool {
+		return reaction.UserID == userID && reaction.Emoji == emoji
+	}
+}
@@ -0,0 +1,212 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Govorov1705/ozon-test/internal/errs"
+	"github.com/Govorov1705/ozon-test/internal/models"
+	"github.com/Govorov1705/ozon-test/internal/repositories"
+	"github.com/Govorov1705/ozon-test/internal/storages/inmemory"
+	"github.com/google/uuid"
+)
+
+type InMemoryRefreshTokensRepository struct {
+	mu      sync.RWMutex
+	tokens  map[uuid.UUID]*models.RefreshToken
+	hashes  map[string]uuid.UUID
+	storage *inmemory.Storage
+}
+
+func NewRefreshTokensRepository(storage *inmemory.Storage) repositories.RefreshTokensRepository {
+	r := &InMemoryRefreshTokensRepository{
+		tokens:  make(map[uuid.UUID]*models.RefreshToken),
+		hashes:  make(map[string]uuid.UUID),
+		storage: storage,
+	}
+	storage.Register(r, refreshTokensTable)
+
+	return r
+}
+
+func (r *InMemoryRefreshTokensRepository) Add(ctx context.Context, ID, sessionID, userID uuid.UUID, tokenHash string, expiresAt time.Time) (*models.RefreshToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, ok := r.hashes[tokenHash]
+	if ok {
+		return nil, errs.ErrAlreadyExists
+	}
+
+	token := &models.RefreshToken{
+		ID:        ID,
+		SessionID: sessionID,
+		UserID:    userID,
+		TokenHash: tokenHash,
+		ExpiresAt: expiresAt,
+		CreatedAt: time.Now(),
+	}
+
+	r.tokens[token.ID] = token
+	r.hashes[token.TokenHash] = token.ID
+	r.storage.Put(ctx, refreshTokensTable, token.ID, token)
+	inmemory.TryLockRow(ctx, refreshTokensTable, token.ID)
+	inmemory.OnRollback(ctx, func() {
+		r.mu.Lock()
+		delete(r.tokens, token.ID)
+		delete(r.hashes, token.TokenHash)
+		r.mu.Unlock()
+	})
+
+	return clone(token), nil
+}
+
+func (r *InMemoryRefreshTokensRepository) GetByID(ctx context.Context, ID uuid.UUID) (*models.RefreshToken, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	token, ok := r.tokens[ID]
+	if !ok {
+		return nil, fmt.Errorf("refresh token %w", errs.ErrNotFound)
+	}
+
+	return clone(token), nil
+}
+
+func (r *InMemoryRefreshTokensRepository) GetByTokenHash(ctx context.Context, tokenHash string, forUpdate bool) (*models.RefreshToken, error) {
+	r.mu.RLock()
+	ID, ok := r.hashes[tokenHash]
+	r.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("refresh token %w", errs.ErrNotFound)
+	}
+
+	if forUpdate {
+		release, err := lockRow(ctx, refreshTokensTable, ID)
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	// Пока ждали блокировку, токен могли удалить откатом транзакции
+	token, ok := r.tokens[ID]
+	if !ok {
+		return nil, fmt.Errorf("refresh token %w", errs.ErrNotFound)
+	}
+
+	return clone(token), nil
+}
+
+func (r *InMemoryRefreshTokensRepository) Revoke(ctx context.Context, ID uuid.UUID) error {
+	release, err := lockRow(ctx, refreshTokensTable, ID)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Как UPDATE без подходящих строк, отзыв неизвестного токена ничего не делает
+	token, ok := r.tokens[ID]
+	if ok && token.RevokedAt == nil {
+		now := time.Now()
+		token.RevokedAt = &now
+		r.storage.Put(ctx, refreshTokensTable, token.ID, token)
+		r.onRollbackUnrevoke(ctx, []*models.RefreshToken{token})
+	}
+
+	return nil
+}
+
+func (r *InMemoryRefreshTokensRepository) RevokeSession(ctx context.Context, sessionID uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	revoked := []*models.RefreshToken{}
+	for _, token := range r.tokens {
+		if token.SessionID == sessionID && token.RevokedAt == nil {
+			token.RevokedAt = &now
+			r.storage.Put(ctx, refreshTokensTable, token.ID, token)
+			revoked = append(revoked, token)
+		}
+	}
+	r.onRollbackUnrevoke(ctx, revoked)
+
+	return nil
+}
+
+func (r *InMemoryRefreshTokensRepository) RevokeAllByUserID(ctx context.Context, userID uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	revoked := []*models.RefreshToken{}
+	for _, token := range r.tokens {
+		if token.UserID == userID && token.RevokedAt == nil {
+			token.RevokedAt = &now
+			r.storage.Put(ctx, refreshTokensTable, token.ID, token)
+			revoked = append(revoked, token)
+		}
+	}
+	r.onRollbackUnrevoke(ctx, revoked)
+
+	return nil
+}
+
+func (r *InMemoryRefreshTokensRepository) onRollbackUnrevoke(ctx context.Context, tokens []*models.RefreshToken) {
+	if len(tokens) == 0 {
+		return
+	}
+
+	inmemory.OnRollback(ctx, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		for _, token := range tokens {
+			token.RevokedAt = nil
+		}
+	})
+}
+
+func (r *InMemoryRefreshTokensRepository) Restore(record *inmemory.Record) error {
+	token, err := decodeRecord[models.RefreshToken](record)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if old, ok := r.tokens[record.Key]; ok {
+		delete(r.hashes, old.TokenHash)
+		delete(r.tokens, old.ID)
+	}
+	if token != nil {
+		r.tokens[token.ID] = token
+		r.hashes[token.TokenHash] = token.ID
+	}
+
+	return nil
+}
+
+func (r *InMemoryRefreshTokensRepository) Dump(emit func(record *inmemory.Record)) error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for ID, token := range r.tokens {
+		err := dumpRecord(emit, refreshTokensTable, ID, token)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
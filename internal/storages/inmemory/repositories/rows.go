@@ -0,0 +1,19 @@
+package repositories
+
+// clone возвращает копию строки: как и при чтении из базы, вызывающий
+// не видит последующих изменений и не может изменить хранилище в обход
+// репозитория. Поля-указатели при изменении строки заменяются, а не
+// меняются на месте, поэтому поверхностной копии достаточно
+func clone[T any](row *T) *T {
+	c := *row
+	return &c
+}
+
+func cloneAll[T any](rows []*T) []*T {
+	clones := make([]*T, len(rows))
+	for i, row := range rows {
+		clones[i] = clone(row)
+	}
+
+	return clones
+}
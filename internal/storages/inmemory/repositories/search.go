@@ -0,0 +1,108 @@
+package repositories
+
+import (
+	"math"
+
+	"github.com/Govorov1705/ozon-test/internal/search"
+	"github.com/google/uuid"
+)
+
+// Веса полей повторяют веса A и B в ts_rank PostgreSQL: совпадение
+// в заголовке поста важнее совпадения в тексте
+const (
+	titleWeight   = 1.0
+	contentWeight = 0.4
+)
+
+type searchField struct {
+	text   string
+	weight float64
+}
+
+// searchIndex - инвертированный индекс: для каждого слова хранит документы,
+// в которых оно встречается, и взвешенное число вхождений. Индекс защищен
+// мьютексом репозитория-владельца
+type searchIndex struct {
+	postings map[string]map[uuid.UUID]float64
+	// Слова документа, чтобы удалять его из индекса без повторного разбора
+	terms map[uuid.UUID][]string
+}
+
+func newSearchIndex() *searchIndex {
+	return &searchIndex{
+		postings: make(map[string]map[uuid.UUID]float64),
+		terms:    make(map[uuid.UUID][]string),
+	}
+}
+
+// put заменяет проиндексированный текст документа
+func (x *searchIndex) put(ID uuid.UUID, fields ...searchField) {
+	x.remove(ID)
+
+	weights := make(map[string]float64)
+	for _, field := range fields {
+		for _, token := range search.Tokenize(field.text) {
+			weights[token.Term] += field.weight
+		}
+	}
+	if len(weights) == 0 {
+		return
+	}
+
+	terms := make([]string, 0, len(weights))
+	for term, weight := range weights {
+		docs, ok := x.postings[term]
+		if !ok {
+			docs = make(map[uuid.UUID]float64)
+			x.postings[term] = docs
+		}
+		docs[ID] = weight
+		terms = append(terms, term)
+	}
+	x.terms[ID] = terms
+}
+
+func (x *searchIndex) remove(ID uuid.UUID) {
+	for _, term := range x.terms[ID] {
+		docs := x.postings[term]
+		delete(docs, ID)
+		if len(docs) == 0 {
+			delete(x.postings, term)
+		}
+	}
+	delete(x.terms, ID)
+}
+
+// match возвращает рейтинги документов, содержащих все слова. Рейтинг
+// растет с числом вхождений медленнее линейного и, в отличие от TF-IDF,
+// не зависит от остальных документов, поэтому курсоры не устаревают при
+// добавлении записей
+func (x *searchIndex) match(terms []string) map[uuid.UUID]float64 {
+	if len(terms) == 0 {
+		return map[uuid.UUID]float64{}
+	}
+
+	// Перебираем документы самого редкого слова
+	rarest := x.postings[terms[0]]
+	for _, term := range terms[1:] {
+		if len(x.postings[term]) < len(rarest) {
+			rarest = x.postings[term]
+		}
+	}
+
+	ranks := make(map[uuid.UUID]float64, len(rarest))
+docs:
+	for ID := range rarest {
+		rank := 0.0
+		for _, term := range terms {
+			weight, ok := x.postings[term][ID]
+			if !ok {
+				continue docs
+			}
+			rank += math.Log1p(weight)
+		}
+		ranks[ID] = rank
+	}
+
+	return ranks
+}
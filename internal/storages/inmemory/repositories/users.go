@@ -2,23 +2,61 @@ package repositories
 
 import (
 	"context"
+	"fmt"
 	"sync"
+	"time"
 
 	"github.com/Govorov1705/ozon-test/internal/errs"
 	"github.com/Govorov1705/ozon-test/internal/models"
 	"github.com/Govorov1705/ozon-test/internal/repositories"
+	"github.com/Govorov1705/ozon-test/internal/storages/inmemory"
 	"github.com/google/uuid"
 )
 
 type InMemoryUsersRepository struct {
-	mu    sync.RWMutex
-	users map[string]*models.User
+	mu      sync.RWMutex
+	users   map[string]*models.User
+	ids     map[uuid.UUID]*models.User
+	storage *inmemory.Storage
 }
 
-func NewUsersRepository() repositories.UsersRepository {
-	return &InMemoryUsersRepository{
-		users: make(map[string]*models.User),
+func NewUsersRepository(storage *inmemory.Storage) repositories.UsersRepository {
+	r := &InMemoryUsersRepository{
+		users:   make(map[string]*models.User),
+		ids:     make(map[uuid.UUID]*models.User),
+		storage: storage,
 	}
+	storage.Register(r, usersTable)
+
+	return r
+}
+
+func (r *InMemoryUsersRepository) GetByID(ctx context.Context, ID uuid.UUID) (*models.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	user, ok := r.ids[ID]
+	if !ok {
+		return nil, fmt.Errorf("user %w", errs.ErrNotFound)
+	}
+
+	return clone(user), nil
+}
+
+func (r *InMemoryUsersRepository) GetByIDs(ctx context.Context, IDs []uuid.UUID) ([]*models.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	users := make([]*models.User, 0, len(IDs))
+
+	for _, ID := range IDs {
+		user, ok := r.ids[ID]
+		if ok {
+			users = append(users, user)
+		}
+	}
+
+	return cloneAll(users), nil
 }
 
 func (r *InMemoryUsersRepository) GetByUsername(ctx context.Context, username string) (*models.User, error) {
@@ -27,10 +65,10 @@ func (r *InMemoryUsersRepository) GetByUsername(ctx context.Context, username st
 
 	user, ok := r.users[username]
 	if !ok {
-		return nil, errs.ErrNotFound
+		return nil, fmt.Errorf("user %w", errs.ErrNotFound)
 	}
 
-	return user, nil
+	return clone(user), nil
 }
 
 func (r *InMemoryUsersRepository) Add(ctx context.Context, username, hashedPassword string) (*models.User, error) {
@@ -46,9 +84,54 @@ func (r *InMemoryUsersRepository) Add(ctx context.Context, username, hashedPassw
 		ID:             uuid.New(),
 		Username:       username,
 		HashedPassword: hashedPassword,
+		CreatedAt:      time.Now(),
 	}
 
 	r.users[username] = user
+	r.ids[user.ID] = user
+	r.storage.Put(ctx, usersTable, user.ID, user)
+	inmemory.TryLockRow(ctx, usersTable, user.ID)
+	inmemory.OnRollback(ctx, func() {
+		r.mu.Lock()
+		delete(r.users, username)
+		delete(r.ids, user.ID)
+		r.mu.Unlock()
+	})
+
+	return clone(user), nil
+}
+
+func (r *InMemoryUsersRepository) Restore(record *inmemory.Record) error {
+	user, err := decodeRecord[models.User](record)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if old, ok := r.ids[record.Key]; ok {
+		delete(r.users, old.Username)
+		delete(r.ids, old.ID)
+	}
+	if user != nil {
+		r.users[user.Username] = user
+		r.ids[user.ID] = user
+	}
+
+	return nil
+}
+
+func (r *InMemoryUsersRepository) Dump(emit func(record *inmemory.Record)) error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for ID, user := range r.ids {
+		err := dumpRecord(emit, usersTable, ID, user)
+		if err != nil {
+			return err
+		}
+	}
 
-	return user, nil
+	return nil
 }
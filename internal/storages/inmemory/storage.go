@@ -0,0 +1,436 @@
+package inmemory
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/Govorov1705/ozon-test/internal/logger"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// FsyncPolicy определяет, когда журнал сбрасывается на диск
+type FsyncPolicy string
+
+const (
+	// FsyncAlways сбрасывает журнал после каждого коммита
+	FsyncAlways FsyncPolicy = "always"
+	// FsyncInterval сбрасывает журнал в фоне раз в заданный интервал,
+	// при сбое теряются коммиты за последний интервал
+	FsyncInterval FsyncPolicy = "interval"
+	// FsyncNever оставляет сброс на усмотрение ОС
+	FsyncNever FsyncPolicy = "never"
+)
+
+// Record - состояние строки после изменения. Пустой Value означает удаление.
+// Повторное применение записи ничего не меняет, поэтому журнал можно
+// проигрывать поверх снимка, в который часть изменений уже попала
+type Record struct {
+	Table string          `json:"table"`
+	Key   uuid.UUID       `json:"key"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// Persistent - репозиторий, состояние которого сохраняет Storage
+type Persistent interface {
+	// Restore применяет запись из снимка или журнала
+	Restore(record *Record) error
+	// Dump перечисляет все строки репозитория
+	Dump(emit func(record *Record)) error
+}
+
+// Storage сохраняет изменения in-memory репозиториев в журнал (WAL) и
+// периодически записывает снимок, после которого старые сегменты журнала
+// удаляются. Без каталога данных Storage ничего не сохраняет
+type Storage struct {
+	dir           string
+	fsync         FsyncPolicy
+	fsyncInterval time.Duration
+
+	// gate держат на чтение открытые транзакции, а снимок - на запись,
+	// чтобы в него не попали незафиксированные изменения
+	gate sync.RWMutex
+
+	mu      sync.Mutex
+	wal     *segment
+	closed  bool
+	tables  map[string]Persistent
+	members []Persistent
+}
+
+func NewStorage(dir string, fsync FsyncPolicy, fsyncInterval time.Duration) (*Storage, error) {
+	switch fsync {
+	case FsyncAlways, FsyncInterval, FsyncNever:
+	default:
+		return nil, fmt.Errorf("unsupported fsync policy %q", fsync)
+	}
+	if fsync == FsyncInterval && fsyncInterval <= 0 {
+		return nil, errors.New("fsync interval must be positive")
+	}
+
+	return &Storage{
+		dir:           dir,
+		fsync:         fsync,
+		fsyncInterval: fsyncInterval,
+		tables:        make(map[string]Persistent),
+	}, nil
+}
+
+func (s *Storage) persistent() bool {
+	return s != nil && s.dir != ""
+}
+
+// Register вызывают конструкторы репозиториев для каждой своей таблицы
+func (s *Storage) Register(p Persistent, tables ...string) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, table := range tables {
+		s.tables[table] = p
+	}
+	s.members = append(s.members, p)
+}
+
+// Put записывает в журнал новое состояние строки. В транзакции запись
+// попадает в журнал при коммите, вне ее - сразу
+func (s *Storage) Put(ctx context.Context, table string, key uuid.UUID, value any) {
+	if !s.persistent() {
+		return
+	}
+
+	// Сериализуем сразу: вызывающий держит мьютекс репозитория,
+	// а строка может измениться до коммита
+	data, err := json.Marshal(value)
+	if err != nil {
+		logger.Logger.Fatal("error marshalling record", zap.String("table", table), zap.Error(err))
+	}
+
+	s.write(ctx, &Record{Table: table, Key: key, Value: data})
+}
+
+func (s *Storage) Delete(ctx context.Context, table string, key uuid.UUID) {
+	if !s.persistent() {
+		return
+	}
+
+	s.write(ctx, &Record{Table: table, Key: key})
+}
+
+func (s *Storage) write(ctx context.Context, record *Record) {
+	tx, ok := txFromContext(ctx)
+	if ok && tx.journal(s, record) {
+		return
+	}
+
+	s.append([]*Record{record})
+}
+
+func (s *Storage) beginTx() {
+	if s.persistent() {
+		s.gate.RLock()
+	}
+}
+
+func (s *Storage) endTx() {
+	if s.persistent() {
+		s.gate.RUnlock()
+	}
+}
+
+// append не возвращает ошибку: если журнал не удалось записать, состояние
+// в памяти уже разошлось с диском, и продолжать работу нельзя
+func (s *Storage) append(records []*Record) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		logger.Logger.Warn("storage is closed, dropping records", zap.Int("count", len(records)))
+		return
+	}
+
+	err := s.wal.append(records)
+	if err == nil && s.fsync == FsyncAlways {
+		err = s.wal.sync()
+	}
+	if err != nil {
+		logger.Logger.Fatal("error writing WAL", zap.Error(err))
+	}
+}
+
+// Load восстанавливает состояние зарегистрированных репозиториев из
+// снимка и журнала. Вызывается один раз после создания всех репозиториев
+func (s *Storage) Load() error {
+	if !s.persistent() {
+		return nil
+	}
+
+	err := os.MkdirAll(s.dir, 0o755)
+	if err != nil {
+		return err
+	}
+
+	next, err := s.loadSnapshot()
+	if err != nil {
+		return err
+	}
+
+	segments, err := listSegments(s.dir)
+	if err != nil {
+		return err
+	}
+
+	for i, seq := range segments {
+		if seq < next {
+			continue
+		}
+		// Оборванной из-за сбоя может быть только последняя запись
+		// последнего сегмента, в остальных сегментах это повреждение
+		err = readSegment(segmentPath(s.dir, seq), i == len(segments)-1, s.restore)
+		if err != nil {
+			return fmt.Errorf("error replaying WAL segment %d: %w", seq, err)
+		}
+		next = seq + 1
+	}
+
+	s.wal, err = createSegment(s.dir, next)
+	if err != nil {
+		return err
+	}
+
+	// Снимок сразу удаляет проигранные сегменты: иначе оборванный хвост
+	// перестанет быть последним и при следующем запуске сочтется повреждением
+	err = s.Snapshot()
+	if err != nil {
+		return err
+	}
+	logger.Logger.Info("In-memory storage loaded", zap.String("dir", s.dir))
+
+	return nil
+}
+
+func (s *Storage) restore(record *Record) error {
+	p, ok := s.tables[record.Table]
+	if !ok {
+		return fmt.Errorf("unknown table %q", record.Table)
+	}
+	return p.Restore(record)
+}
+
+// Run периодически сбрасывает журнал на диск и записывает снимки,
+// пока не отменен ctx
+func (s *Storage) Run(ctx context.Context, snapshotInterval time.Duration) {
+	if !s.persistent() {
+		return
+	}
+
+	snapshotTicker := time.NewTicker(snapshotInterval)
+	defer snapshotTicker.Stop()
+
+	var fsyncC <-chan time.Time
+	if s.fsync == FsyncInterval {
+		fsyncTicker := time.NewTicker(s.fsyncInterval)
+		defer fsyncTicker.Stop()
+		fsyncC = fsyncTicker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-fsyncC:
+			s.sync()
+		case <-snapshotTicker.C:
+			err := s.Snapshot()
+			if err != nil {
+				logger.Logger.Error("error writing snapshot", zap.Error(err))
+			}
+		}
+	}
+}
+
+func (s *Storage) sync() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return
+	}
+
+	err := s.wal.sync()
+	if err != nil {
+		logger.Logger.Fatal("error syncing WAL", zap.Error(err))
+	}
+}
+
+// Snapshot записывает все строки в новый снимок и удаляет сегменты
+// журнала, которые в него вошли
+func (s *Storage) Snapshot() error {
+	if !s.persistent() {
+		return nil
+	}
+
+	s.gate.Lock()
+
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		s.gate.Unlock()
+		return nil
+	}
+	next := s.wal.seq + 1
+	wal, err := createSegment(s.dir, next)
+	if err != nil {
+		s.mu.Unlock()
+		s.gate.Unlock()
+		return err
+	}
+	old := s.wal
+	s.wal = wal
+	s.mu.Unlock()
+
+	// Изменения вне транзакций могут попасть и в снимок, и в новый сегмент,
+	// это безопасно, потому что записи идемпотентны
+	records := []*Record{}
+	for _, p := range s.members {
+		err = p.Dump(func(record *Record) {
+			records = append(records, record)
+		})
+		if err != nil {
+			break
+		}
+	}
+	s.gate.Unlock()
+
+	closeErr := old.close()
+	if err != nil {
+		return err
+	}
+	if closeErr != nil {
+		return closeErr
+	}
+
+	err = s.writeSnapshot(next, records)
+	if err != nil {
+		return err
+	}
+
+	segments, err := listSegments(s.dir)
+	if err != nil {
+		return err
+	}
+	for _, seq := range segments {
+		if seq < next {
+			os.Remove(segmentPath(s.dir, seq))
+		}
+	}
+
+	return nil
+}
+
+// Close записывает финальный снимок и закрывает журнал. Изменения после
+// Close не сохраняются
+func (s *Storage) Close() error {
+	if !s.persistent() {
+		return nil
+	}
+
+	err := s.Snapshot()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return err
+	}
+	s.closed = true
+
+	return errors.Join(err, s.wal.close())
+}
+
+func (s *Storage) snapshotPath() string {
+	return filepath.Join(s.dir, "snapshot.json")
+}
+
+type snapshotHeader struct {
+	// Первый сегмент журнала, не вошедший в снимок
+	NextSegment uint64 `json:"nextSegment"`
+}
+
+func (s *Storage) writeSnapshot(next uint64, records []*Record) error {
+	tmpPath := s.snapshotPath() + ".tmp"
+
+	f, err := os.Create(tmpPath)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpPath)
+
+	enc := json.NewEncoder(f)
+	err = enc.Encode(snapshotHeader{NextSegment: next})
+	for _, record := range records {
+		if err != nil {
+			break
+		}
+		err = enc.Encode(record)
+	}
+	if err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	err = os.Rename(tmpPath, s.snapshotPath())
+	if err != nil {
+		return err
+	}
+
+	return syncDir(s.dir)
+}
+
+// loadSnapshot возвращает номер первого сегмента журнала после снимка
+func (s *Storage) loadSnapshot() (uint64, error) {
+	f, err := os.Open(s.snapshotPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	dec := json.NewDecoder(f)
+
+	var header snapshotHeader
+	err = dec.Decode(&header)
+	if err != nil {
+		return 0, fmt.Errorf("error reading snapshot header: %w", err)
+	}
+
+	for dec.More() {
+		var record Record
+		err = dec.Decode(&record)
+		if err != nil {
+			return 0, fmt.Errorf("error reading snapshot: %w", err)
+		}
+		err = s.restore(&record)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return header.NextSegment, nil
+}
@@ -0,0 +1,241 @@
+package inmemory_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Govorov1705/ozon-test/internal/logger"
+	"github.com/Govorov1705/ozon-test/internal/storages/inmemory"
+	"github.com/Govorov1705/ozon-test/internal/transactions"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const rowsTable = "rows"
+
+func TestMain(m *testing.M) {
+	logger.InitLogger()
+	code := m.Run()
+	os.Exit(code)
+}
+
+// rows - простейший Persistent: строки таблицы rowsTable
+type rows struct {
+	mu     sync.Mutex
+	values map[uuid.UUID]string
+}
+
+func (r *rows) Restore(record *inmemory.Record) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if record.Value == nil {
+		delete(r.values, record.Key)
+		return nil
+	}
+
+	var value string
+	err := json.Unmarshal(record.Value, &value)
+	if err != nil {
+		return err
+	}
+	r.values[record.Key] = value
+
+	return nil
+}
+
+func (r *rows) Dump(emit func(record *inmemory.Record)) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for key, value := range r.values {
+		data, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		emit(&inmemory.Record{Table: rowsTable, Key: key, Value: data})
+	}
+
+	return nil
+}
+
+func (r *rows) put(ctx context.Context, s *inmemory.Storage, key uuid.UUID, value string) {
+	r.mu.Lock()
+	r.values[key] = value
+	r.mu.Unlock()
+
+	s.Put(ctx, rowsTable, key, value)
+}
+
+func (r *rows) delete(ctx context.Context, s *inmemory.Storage, key uuid.UUID) {
+	r.mu.Lock()
+	delete(r.values, key)
+	r.mu.Unlock()
+
+	s.Delete(ctx, rowsTable, key)
+}
+
+// open поднимает хранилище из dir. Close не вызывается: брошенное хранилище
+// ведет себя как процесс, упавший без финального снимка
+func open(t *testing.T, dir string) (*inmemory.Storage, *rows) {
+	t.Helper()
+
+	s, err := inmemory.NewStorage(dir, inmemory.FsyncNever, 0)
+	require.NoError(t, err)
+
+	r := &rows{values: make(map[uuid.UUID]string)}
+	s.Register(r, rowsTable)
+	require.NoError(t, s.Load())
+
+	return s, r
+}
+
+func segments(t *testing.T, dir string) []string {
+	t.Helper()
+
+	paths, err := filepath.Glob(filepath.Join(dir, "wal-*.log"))
+	require.NoError(t, err)
+	require.NotEmpty(t, paths)
+
+	return paths
+}
+
+func appendToFile(t *testing.T, path, data string) {
+	t.Helper()
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o644)
+	require.NoError(t, err)
+	_, err = f.WriteString(data)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+}
+
+func nextSegmentPath(t *testing.T, dir string) string {
+	t.Helper()
+
+	paths := segments(t, dir)
+	var seq uint64
+	_, err := fmt.Sscanf(filepath.Base(paths[len(paths)-1]), "wal-%016d.log", &seq)
+	require.NoError(t, err)
+
+	return filepath.Join(dir, fmt.Sprintf("wal-%016d.log", seq+1))
+}
+
+func TestStorage_ReplayAfterRestart(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	kept, deleted := uuid.New(), uuid.New()
+
+	s, r := open(t, dir)
+	r.put(ctx, s, kept, "first")
+	r.put(ctx, s, deleted, "deleted")
+	r.put(ctx, s, kept, "second")
+	r.delete(ctx, s, deleted)
+
+	_, restored := open(t, dir)
+	assert.Equal(t, map[uuid.UUID]string{kept: "second"}, restored.values)
+}
+
+func TestStorage_DropsTornLastLine(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	first, second := uuid.New(), uuid.New()
+
+	s, r := open(t, dir)
+	r.put(ctx, s, first, "first")
+	r.put(ctx, s, second, "second")
+
+	// Запись коммита оборвалась на середине строки
+	paths := segments(t, dir)
+	appendToFile(t, paths[len(paths)-1], `0badc0de [{"table":"rows","key":"`)
+
+	_, restored := open(t, dir)
+	assert.Equal(t, map[uuid.UUID]string{first: "first", second: "second"}, restored.values)
+
+	// Снимок при загрузке убрал оборванный хвост, поэтому следующий запуск
+	// не считает его повреждением
+	_, restored = open(t, dir)
+	assert.Equal(t, map[uuid.UUID]string{first: "first", second: "second"}, restored.values)
+}
+
+func TestStorage_TornLineInNonLastSegment(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	s, r := open(t, dir)
+	r.put(ctx, s, uuid.New(), "value")
+
+	paths := segments(t, dir)
+	appendToFile(t, paths[len(paths)-1], `0badc0de [{"table":"rows","key":"`)
+
+	// После оборванной строки есть еще сегмент, значит это не хвост сбоя
+	require.NoError(t, os.WriteFile(nextSegmentPath(t, dir), nil, 0o644))
+
+	s, err := inmemory.NewStorage(dir, inmemory.FsyncNever, 0)
+	require.NoError(t, err)
+	s.Register(&rows{values: make(map[uuid.UUID]string)}, rowsTable)
+	assert.ErrorContains(t, s.Load(), "error replaying WAL segment")
+}
+
+func TestStorage_SnapshotThenReplay(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	snapshotted, logged := uuid.New(), uuid.New()
+
+	s, r := open(t, dir)
+	r.put(ctx, s, snapshotted, "in snapshot")
+	require.NoError(t, s.Snapshot())
+	r.put(ctx, s, logged, "in WAL")
+	r.put(ctx, s, snapshotted, "updated in WAL")
+
+	paths := segments(t, dir)
+	require.Len(t, paths, 1)
+	wal, err := os.ReadFile(paths[0])
+	require.NoError(t, err)
+
+	expected := map[uuid.UUID]string{snapshotted: "updated in WAL", logged: "in WAL"}
+
+	_, restored := open(t, dir)
+	assert.Equal(t, expected, restored.values)
+
+	// Те же коммиты поверх снимка, в который они уже вошли, ничего не меняют
+	require.NoError(t, os.WriteFile(nextSegmentPath(t, dir), wal, 0o644))
+
+	_, restored = open(t, dir)
+	assert.Equal(t, expected, restored.values)
+}
+
+func TestStorage_RolledBackTxIsNotLogged(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	rolledBack, committed := uuid.New(), uuid.New()
+
+	s, r := open(t, dir)
+	txStarter := inmemory.NewTxStarter(s)
+
+	tx, err := txStarter.Begin(ctx)
+	require.NoError(t, err)
+	r.put(transactions.PutTxIntoContext(ctx, tx), s, rolledBack, "rolled back")
+	require.NoError(t, tx.Rollback(ctx))
+
+	tx, err = txStarter.Begin(ctx)
+	require.NoError(t, err)
+	r.put(transactions.PutTxIntoContext(ctx, tx), s, committed, "committed")
+	require.NoError(t, tx.Commit(ctx))
+
+	paths := segments(t, dir)
+	wal, err := os.ReadFile(paths[len(paths)-1])
+	require.NoError(t, err)
+	assert.NotContains(t, string(wal), rolledBack.String())
+	assert.Equal(t, 1, strings.Count(string(wal), "\n"))
+
+	_, restored := open(t, dir)
+	assert.Equal(t, map[uuid.UUID]string{committed: "committed"}, restored.values)
+}
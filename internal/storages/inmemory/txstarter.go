@@ -2,22 +2,149 @@ package inmemory
 
 import (
 	"context"
+	"errors"
+	"sync"
 
 	"github.com/Govorov1705/ozon-test/internal/transactions"
 )
 
-type InMemoryTx struct{}
+var ErrTxDone = errors.New("transaction has already been committed or rolled back")
+
+// InMemoryTx ведет журнал отмены: репозитории пишут изменения сразу в
+// хранилище и регистрируют через OnRollback функцию, возвращающую прежнее
+// состояние. Блокировки строк держатся до Commit или Rollback.
+// Изоляция от чтения не обеспечивается: запросы без forUpdate видят
+// незафиксированные изменения других транзакций.
+// Если транзакция открыта с Storage, записи журнала копятся в ней
+// и сохраняются одним коммитом
+type InMemoryTx struct {
+	storage *Storage
+	mu      sync.Mutex
+	undo    []func()
+	locks   []string
+	records []*Record
+	done    bool
+}
 
 func (i *InMemoryTx) Commit(ctx context.Context) error {
+	_, locks, records, err := i.finish()
+	if err != nil {
+		return err
+	}
+
+	// Журнал пишется до снятия блокировок, чтобы изменения одной строки
+	// попадали в него в том же порядке, в каком применялись
+	if len(records) > 0 {
+		i.storage.append(records)
+	}
+	releaseAll(locks, i)
+	i.storage.endTx()
+
 	return nil
 }
 
+// Rollback не зависит от ctx, чтобы изменения откатывались и после отмены запроса
 func (i *InMemoryTx) Rollback(ctx context.Context) error {
+	undo, locks, _, err := i.finish()
+	if err != nil {
+		return err
+	}
+
+	// Блокировки строк снимаются только после отката, чтобы ожидающие
+	// транзакции не увидели промежуточного состояния
+	for j := len(undo) - 1; j >= 0; j-- {
+		undo[j]()
+	}
+	releaseAll(locks, i)
+	i.storage.endTx()
+
 	return nil
 }
 
-type InMemoryTxStarter struct{}
+func (i *InMemoryTx) finish() ([]func(), []string, []*Record, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if i.done {
+		return nil, nil, nil, ErrTxDone
+	}
+
+	undo, locks, records := i.undo, i.locks, i.records
+	i.done = true
+	i.undo = nil
+	i.locks = nil
+	i.records = nil
+
+	return undo, locks, records, nil
+}
+
+func (i *InMemoryTx) onRollback(undo func()) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if !i.done {
+		i.undo = append(i.undo, undo)
+	}
+}
+
+func (i *InMemoryTx) addLock(key string) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if i.done {
+		rowLocks.release(key, i)
+		return
+	}
+	i.locks = append(i.locks, key)
+}
+
+// journal откладывает запись до коммита. Возвращает false, если транзакция
+// открыта не с этим Storage и запись нужно сохранить сразу
+func (i *InMemoryTx) journal(s *Storage, record *Record) bool {
+	if i.storage != s {
+		return false
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if i.done {
+		return false
+	}
+	i.records = append(i.records, record)
+
+	return true
+}
+
+type InMemoryTxStarter struct {
+	storage *Storage
+}
+
+// storage может быть nil, тогда транзакции не участвуют в сохранении
+func NewTxStarter(storage *Storage) *InMemoryTxStarter {
+	return &InMemoryTxStarter{storage: storage}
+}
 
 func (i *InMemoryTxStarter) Begin(ctx context.Context) (transactions.Tx, error) {
-	return &InMemoryTx{}, nil
+	i.storage.beginTx()
+	return &InMemoryTx{storage: i.storage}, nil
+}
+
+func txFromContext(ctx context.Context) (*InMemoryTx, bool) {
+	tx, ok := transactions.GetTxFromContext(ctx)
+	if !ok {
+		return nil, false
+	}
+	inMemoryTx, ok := tx.(*InMemoryTx)
+	return inMemoryTx, ok
+}
+
+// OnRollback регистрирует отмену изменения в транзакции из ctx.
+// Вне транзакции изменение сразу считается зафиксированным.
+// undo вызывается без блокировок репозитория, захватывать их должна она сама
+func OnRollback(ctx context.Context, undo func()) {
+	tx, ok := txFromContext(ctx)
+	if ok {
+		tx.onRollback(undo)
+	}
 }
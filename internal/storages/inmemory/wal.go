@@ -0,0 +1,158 @@
+package inmemory
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Сегмент журнала - текстовый файл, где каждая строка содержит записи одного
+// коммита в виде "<crc32> <json>", поэтому коммит восстанавливается целиком
+// или не восстанавливается вовсе
+type segment struct {
+	seq  uint64
+	file *os.File
+	buf  *bufio.Writer
+}
+
+func segmentPath(dir string, seq uint64) string {
+	return filepath.Join(dir, fmt.Sprintf("wal-%016d.log", seq))
+}
+
+func createSegment(dir string, seq uint64) (*segment, error) {
+	f, err := os.OpenFile(segmentPath(dir, seq), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	err = syncDir(dir)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return &segment{seq: seq, file: f, buf: bufio.NewWriter(f)}, nil
+}
+
+func (s *segment) append(records []*Record) error {
+	data, err := json.Marshal(records)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(s.buf, "%08x %s\n", crc32.ChecksumIEEE(data), data)
+	if err != nil {
+		return err
+	}
+
+	// Без буфера в ОС коммит не переживет даже падения процесса
+	return s.buf.Flush()
+}
+
+func (s *segment) sync() error {
+	return s.file.Sync()
+}
+
+func (s *segment) close() error {
+	return errors.Join(s.buf.Flush(), s.file.Sync(), s.file.Close())
+}
+
+func listSegments(dir string) ([]uint64, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	segments := []uint64{}
+	for _, entry := range entries {
+		var seq uint64
+		_, err := fmt.Sscanf(entry.Name(), "wal-%016d.log", &seq)
+		if err == nil && !entry.IsDir() {
+			segments = append(segments, seq)
+		}
+	}
+	slices.Sort(segments)
+
+	return segments, nil
+}
+
+// readSegment применяет коммиты сегмента по порядку. В последнем сегменте
+// поврежденная строка считается оборванной при сбое записью и вместе со
+// всем, что после нее, отбрасывается
+func readSegment(path string, last bool, apply func(record *Record) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	for line := 1; ; line++ {
+		data, err := r.ReadBytes('\n')
+		if errors.Is(err, io.EOF) && len(data) == 0 {
+			return nil
+		}
+
+		var records []*Record
+		if err == nil {
+			records, err = decodeCommit(data)
+		} else if errors.Is(err, io.EOF) {
+			err = errors.New("unterminated line")
+		}
+		if err != nil {
+			if last {
+				return nil
+			}
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+
+		for _, record := range records {
+			err = apply(record)
+			if err != nil {
+				return fmt.Errorf("line %d: %w", line, err)
+			}
+		}
+	}
+}
+
+func decodeCommit(line []byte) ([]*Record, error) {
+	checksum, data, ok := strings.Cut(string(bytes.TrimSuffix(line, []byte("\n"))), " ")
+	if !ok {
+		return nil, errors.New("malformed line")
+	}
+
+	var expected uint32
+	_, err := fmt.Sscanf(checksum, "%08x", &expected)
+	if err != nil {
+		return nil, err
+	}
+	if crc32.ChecksumIEEE([]byte(data)) != expected {
+		return nil, errors.New("checksum mismatch")
+	}
+
+	var records []*Record
+	err = json.Unmarshal([]byte(data), &records)
+	if err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+
+	return d.Sync()
+}
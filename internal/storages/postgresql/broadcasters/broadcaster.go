@@ -0,0 +1,168 @@
+package broadcasters
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/Govorov1705/ozon-test/internal/broadcasters"
+	"github.com/Govorov1705/ozon-test/internal/errs"
+	"github.com/Govorov1705/ozon-test/internal/logger"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+const (
+	publishTimeout = 5 * time.Second
+	reconnectDelay = time.Second
+)
+
+// Hydrate дополняет полученное из канала событие данными, которые не
+// передаются через NOTIFY. При ошибке событие пропускается, при
+// errs.ErrNotFound - без записи в лог
+type Hydrate[T any] func(ctx context.Context, event T) error
+
+type notification[T any] struct {
+	EventID uuid.UUID `json:"eventId"`
+	Topic   uuid.UUID `json:"topic"`
+	Event   T         `json:"event"`
+}
+
+// PgNotifyBroadcaster публикует события через pg_notify, поэтому их получают
+// подписчики на всех репликах. Каждая реплика слушает канал и раздает события
+// своим подписчикам через локальный broadcaster.
+// Размер payload у NOTIFY ограничен 8000 байт, поэтому событие должно
+// сериализоваться компактно, а крупные данные догружаться в hydrate
+type PgNotifyBroadcaster[T any] struct {
+	pool    *pgxpool.Pool
+	channel string
+	hydrate Hydrate[T]
+	local   broadcasters.Broadcaster[T]
+}
+
+// Слушатель работает, пока не отменен ctx
+func NewBroadcaster[T any](
+	ctx context.Context,
+	pool *pgxpool.Pool,
+	channel string,
+	hydrate Hydrate[T],
+	bufferSize int,
+	policy broadcasters.OverflowPolicy,
+) broadcasters.Broadcaster[T] {
+	b := &PgNotifyBroadcaster[T]{
+		pool:    pool,
+		channel: channel,
+		hydrate: hydrate,
+		local:   broadcasters.NewBroadcaster[T](bufferSize, policy),
+	}
+
+	go b.listen(ctx)
+
+	return b
+}
+
+func (b *PgNotifyBroadcaster[T]) Subscribe(topic uuid.UUID) *broadcasters.Subscription[T] {
+	return b.local.Subscribe(topic)
+}
+
+func (b *PgNotifyBroadcaster[T]) Unsubscribe(topic uuid.UUID, sub *broadcasters.Subscription[T]) {
+	b.local.Unsubscribe(topic, sub)
+}
+
+// Publish не раздает событие локально напрямую: уведомление вернется
+// и в этот процесс через LISTEN
+func (b *PgNotifyBroadcaster[T]) Publish(eventID, topic uuid.UUID, event T) error {
+	payload, err := json.Marshal(notification[T]{
+		EventID: eventID,
+		Topic:   topic,
+		Event:   event,
+	})
+	if err != nil {
+		logger.Logger.Error("error marshalling notification", zap.Error(err))
+		return errs.ErrInternal
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), publishTimeout)
+	defer cancel()
+
+	_, err = b.pool.Exec(ctx, "SELECT pg_notify($1, $2);", b.channel, string(payload))
+	if err != nil {
+		logger.Logger.Error("error publishing notification", zap.Error(err))
+		return errs.ErrInternal
+	}
+
+	return nil
+}
+
+func (b *PgNotifyBroadcaster[T]) listen(ctx context.Context) {
+	for {
+		err := b.listenOnce(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		logger.Logger.Error(
+			"notification listener stopped, reconnecting",
+			zap.String("channel", b.channel),
+			zap.Error(err),
+		)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(reconnectDelay):
+		}
+	}
+}
+
+func (b *PgNotifyBroadcaster[T]) listenOnce(ctx context.Context) error {
+	conn, err := b.pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		// Соединение возвращается в пул, поэтому подписку нужно снять
+		conn.Exec(context.Background(), "UNLISTEN *;")
+		conn.Release()
+	}()
+
+	_, err = conn.Exec(ctx, "LISTEN "+b.channel+";")
+	if err != nil {
+		return err
+	}
+
+	for {
+		n, err := conn.Conn().WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+
+		b.deliver(ctx, n.Payload)
+	}
+}
+
+func (b *PgNotifyBroadcaster[T]) deliver(ctx context.Context, payload string) {
+	var n notification[T]
+	err := json.Unmarshal([]byte(payload), &n)
+	if err != nil {
+		logger.Logger.Error("error unmarshalling notification", zap.Error(err))
+		return
+	}
+
+	if b.hydrate != nil {
+		err = b.hydrate(ctx, n.Event)
+		if errors.Is(err, errs.ErrNotFound) {
+			return
+		}
+		if err != nil {
+			logger.Logger.Error("error hydrating notified event", zap.Error(err))
+			return
+		}
+	}
+
+	err = b.local.Publish(n.EventID, n.Topic, n.Event)
+	if err != nil {
+		logger.Logger.Error("error delivering notified event", zap.Error(err))
+	}
+}
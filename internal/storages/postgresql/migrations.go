@@ -0,0 +1,19 @@
+package postgresql
+
+import (
+	"embed"
+
+	"github.com/Govorov1705/ozon-test/internal/migrations"
+	"github.com/golang-migrate/migrate/v4"
+	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/source"
+)
+
+//go:embed migrations/*.sql
+var migrationsFS embed.FS
+
+func NewMigrator(DBURL string) (*migrations.Migrator, error) {
+	return migrations.New(migrationsFS, func(src source.Driver) (*migrate.Migrate, error) {
+		return migrate.NewWithSourceInstance("iofs", src, DBURL+"sslmode=disable")
+	})
+}
@@ -0,0 +1,5 @@
+BEGIN;
+
+CREATE INDEX idx_posts_created_at_id ON posts(created_at DESC, id DESC);
+
+COMMIT;
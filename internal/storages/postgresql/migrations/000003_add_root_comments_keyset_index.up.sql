@@ -0,0 +1,5 @@
+BEGIN;
+
+CREATE INDEX idx_comments_root_keyset ON comments(post_id, created_at DESC, id DESC) WHERE reply_to IS NULL;
+
+COMMIT;
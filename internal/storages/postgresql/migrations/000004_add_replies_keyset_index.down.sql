@@ -0,0 +1,5 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx_comments_replies_keyset;
+
+COMMIT;
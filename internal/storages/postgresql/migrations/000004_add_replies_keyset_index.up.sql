@@ -0,0 +1,5 @@
+BEGIN;
+
+CREATE INDEX idx_comments_replies_keyset ON comments(reply_to, created_at DESC, id DESC) WHERE reply_to IS NOT NULL;
+
+COMMIT;
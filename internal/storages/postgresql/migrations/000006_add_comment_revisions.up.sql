@@ -0,0 +1,14 @@
+BEGIN;
+
+ALTER TABLE comments ADD COLUMN edited_at TIMESTAMPTZ;
+
+CREATE TABLE comment_revisions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    comment_id UUID NOT NULL REFERENCES comments(id) ON DELETE CASCADE,
+    content VARCHAR(2000) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_comment_revisions_comment_id ON comment_revisions(comment_id, created_at);
+
+COMMIT;
@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE comments DROP COLUMN IF EXISTS deleted_at;
+
+COMMIT;
@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE comments ADD COLUMN deleted_at TIMESTAMPTZ;
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+ALTER TABLE posts DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE posts DROP COLUMN IF EXISTS updated_at;
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+ALTER TABLE posts ADD COLUMN updated_at TIMESTAMPTZ;
+ALTER TABLE posts ADD COLUMN deleted_at TIMESTAMPTZ;
+
+COMMIT;
@@ -0,0 +1,8 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx_comments_user_id;
+DROP INDEX IF EXISTS idx_posts_user_id;
+
+ALTER TABLE users DROP COLUMN IF EXISTS created_at;
+
+COMMIT;
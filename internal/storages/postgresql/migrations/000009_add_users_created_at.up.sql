@@ -0,0 +1,8 @@
+BEGIN;
+
+ALTER TABLE users ADD COLUMN created_at TIMESTAMPTZ NOT NULL DEFAULT now();
+
+CREATE INDEX idx_posts_user_id ON posts(user_id) WHERE deleted_at IS NULL;
+CREATE INDEX idx_comments_user_id ON comments(user_id) WHERE deleted_at IS NULL;
+
+COMMIT;
@@ -0,0 +1,14 @@
+BEGIN;
+
+CREATE TABLE outbox (
+    id UUID PRIMARY KEY,
+    type VARCHAR(64) NOT NULL,
+    payload JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    processed_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_outbox_unprocessed ON outbox(created_at) WHERE processed_at IS NULL;
+CREATE INDEX idx_outbox_processed_at ON outbox(processed_at) WHERE processed_at IS NOT NULL;
+
+COMMIT;
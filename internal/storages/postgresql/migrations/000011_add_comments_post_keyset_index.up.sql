@@ -0,0 +1,5 @@
+BEGIN;
+
+CREATE INDEX idx_comments_post_keyset ON comments(post_id, created_at, id);
+
+COMMIT;
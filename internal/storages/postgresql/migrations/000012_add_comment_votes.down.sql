@@ -0,0 +1,16 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx_comments_root_best;
+DROP INDEX IF EXISTS idx_comments_root_controversial;
+DROP INDEX IF EXISTS idx_comments_root_top;
+
+DROP TABLE IF EXISTS comment_votes;
+
+ALTER TABLE comments
+    DROP COLUMN IF EXISTS best,
+    DROP COLUMN IF EXISTS controversy,
+    DROP COLUMN IF EXISTS score,
+    DROP COLUMN IF EXISTS downvotes,
+    DROP COLUMN IF EXISTS upvotes;
+
+COMMIT;
@@ -0,0 +1,22 @@
+BEGIN;
+
+ALTER TABLE comments
+    ADD COLUMN upvotes INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN downvotes INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN score INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN controversy DOUBLE PRECISION NOT NULL DEFAULT 0,
+    ADD COLUMN best DOUBLE PRECISION NOT NULL DEFAULT 0;
+
+CREATE TABLE comment_votes (
+    comment_id UUID NOT NULL REFERENCES comments(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    value SMALLINT NOT NULL CHECK (value IN (-1, 1)),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (comment_id, user_id)
+);
+
+CREATE INDEX idx_comments_root_top ON comments(post_id, score DESC, created_at DESC, id DESC) WHERE reply_to IS NULL;
+CREATE INDEX idx_comments_root_controversial ON comments(post_id, controversy DESC, created_at DESC, id DESC) WHERE reply_to IS NULL;
+CREATE INDEX idx_comments_root_best ON comments(post_id, best DESC, created_at DESC, id DESC) WHERE reply_to IS NULL;
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP TABLE IF EXISTS comment_reactions;
+DROP TABLE IF EXISTS post_reactions;
+
+COMMIT;
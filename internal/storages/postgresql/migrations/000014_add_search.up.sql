@@ -0,0 +1,14 @@
+BEGIN;
+
+ALTER TABLE posts ADD COLUMN search_vector TSVECTOR GENERATED ALWAYS AS (
+    setweight(to_tsvector('simple', title), 'A') || setweight(to_tsvector('simple', content), 'B')
+) STORED;
+
+ALTER TABLE comments ADD COLUMN search_vector TSVECTOR GENERATED ALWAYS AS (
+    setweight(to_tsvector('simple', content), 'B')
+) STORED;
+
+CREATE INDEX idx_posts_search ON posts USING GIN (search_vector);
+CREATE INDEX idx_comments_search ON comments USING GIN (search_vector);
+
+COMMIT;
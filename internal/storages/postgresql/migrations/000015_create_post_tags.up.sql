@@ -0,0 +1,11 @@
+BEGIN;
+
+CREATE TABLE post_tags (
+    post_id UUID NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    tag VARCHAR(32) NOT NULL,
+    PRIMARY KEY (post_id, tag)
+);
+
+CREATE INDEX idx_post_tags_tag ON post_tags(tag text_pattern_ops, post_id);
+
+COMMIT;
@@ -0,0 +1,25 @@
+BEGIN;
+
+CREATE TABLE communities (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    owner_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    slug VARCHAR(32) NOT NULL UNIQUE,
+    description VARCHAR(1000) NOT NULL DEFAULT '',
+    are_comments_allowed BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE community_members (
+    community_id UUID NOT NULL REFERENCES communities(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role VARCHAR(16) NOT NULL CHECK (role IN ('MEMBER', 'MODERATOR', 'OWNER')),
+    joined_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (community_id, user_id)
+);
+
+ALTER TABLE posts ADD COLUMN community_id UUID REFERENCES communities(id) ON DELETE CASCADE;
+
+CREATE INDEX idx_posts_community_keyset ON posts(community_id, created_at DESC, id DESC) WHERE community_id IS NOT NULL;
+
+COMMIT;
@@ -2,6 +2,7 @@ package repositories
 
 import (
 	"context"
+	"strings"
 
 	"github.com/Govorov1705/ozon-test/internal/storages/postgresql"
 	"github.com/Govorov1705/ozon-test/internal/transactions"
@@ -25,3 +26,11 @@ func (r *BaseRepository) GetQuerier(ctx context.Context) postgresql.Querier {
 	}
 	return r.Pool
 }
+
+// likePrefix строит шаблон LIKE для поиска по началу строки. Символы
+// шаблона в prefix экранируются обратной косой чертой
+func likePrefix(prefix string) string {
+	return likeEscaper.Replace(prefix) + "%"
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
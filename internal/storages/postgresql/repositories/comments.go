@@ -8,7 +8,9 @@ import (
 	"github.com/Govorov1705/ozon-test/internal/errs"
 	"github.com/Govorov1705/ozon-test/internal/logger"
 	"github.com/Govorov1705/ozon-test/internal/models"
+	"github.com/Govorov1705/ozon-test/internal/pagination"
 	"github.com/Govorov1705/ozon-test/internal/repositories"
+	"github.com/Govorov1705/ozon-test/internal/search"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -31,7 +33,8 @@ func (r *CommentsRepository) Add(ctx context.Context, postID, userID uuid.UUID,
 	stmt := `
 		INSERT INTO comments(id, post_id, user_id, root_id, reply_to, content) 
 		VALUES ($1, $2, $3, $4, $5, $6)
-		RETURNING id, post_id, user_id, root_id, reply_to, content, created_at;
+		RETURNING id, post_id, user_id, root_id, reply_to, content, created_at, edited_at, deleted_at,
+			upvotes, downvotes, score, controversy, best;
 	`
 
 	commentID := uuid.New()
@@ -50,6 +53,13 @@ func (r *CommentsRepository) Add(ctx context.Context, postID, userID uuid.UUID,
 		&comment.ReplyTo,
 		&comment.Content,
 		&comment.CreatedAt,
+		&comment.EditedAt,
+		&comment.DeletedAt,
+		&comment.Upvotes,
+		&comment.Downvotes,
+		&comment.Score,
+		&comment.Controversy,
+		&comment.Best,
 	)
 	if err != nil {
 		logger.Logger.Error("error scanning row", zap.Error(err))
@@ -63,7 +73,8 @@ func (r *CommentsRepository) GetByID(ctx context.Context, commentID uuid.UUID, f
 	comment := models.Comment{}
 
 	query := `
-		SELECT id, post_id, user_id, root_id, reply_to, content, created_at 
+		SELECT id, post_id, user_id, root_id, reply_to, content, created_at, edited_at, deleted_at,
+			upvotes, downvotes, score, controversy, best
 		FROM comments
 		WHERE id = $1`
 	if forUpdate {
@@ -82,6 +93,13 @@ func (r *CommentsRepository) GetByID(ctx context.Context, commentID uuid.UUID, f
 		&comment.ReplyTo,
 		&comment.Content,
 		&comment.CreatedAt,
+		&comment.EditedAt,
+		&comment.DeletedAt,
+		&comment.Upvotes,
+		&comment.Downvotes,
+		&comment.Score,
+		&comment.Controversy,
+		&comment.Best,
 	)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -94,29 +112,317 @@ func (r *CommentsRepository) GetByID(ctx context.Context, commentID uuid.UUID, f
 	return &comment, nil
 }
 
-func (r *CommentsRepository) GetRootCommentsByPostID(ctx context.Context, postID uuid.UUID, limit, offset *int32) ([]*models.Comment, error) {
-	comments := []*models.Comment{}
+func (r *CommentsRepository) GetByIDs(ctx context.Context, IDs []uuid.UUID) ([]*models.Comment, error) {
+	query := `
+		SELECT id, post_id, user_id, root_id, reply_to, content, created_at, edited_at, deleted_at,
+			upvotes, downvotes, score, controversy, best
+		FROM comments
+		WHERE id = ANY($1);
+	`
 
-	l := int32(10)
-	o := int32(0)
+	return r.queryComments(ctx, query, IDs)
+}
 
-	if limit != nil {
-		l = *limit
-	}
-	if offset != nil {
-		o = *offset
+// GetByPostID выбирает комментарии всех уровней вложенности
+func (r *CommentsRepository) GetByPostID(ctx context.Context, postID uuid.UUID, page *pagination.Page) ([]*models.Comment, error) {
+	query := `
+		SELECT id, post_id, user_id, root_id, reply_to, content, created_at, edited_at, deleted_at,
+			upvotes, downvotes, score, controversy, best
+		FROM comments
+		WHERE post_id = $1`
+	query, args := appendKeyset(query, []any{postID}, page, "comments")
+
+	return r.queryComments(ctx, query, args...)
+}
+
+func (r *CommentsRepository) GetRootCommentsByPostID(ctx context.Context, postID uuid.UUID, page *pagination.Page) ([]*models.Comment, error) {
+	query := `
+		SELECT id, post_id, user_id, root_id, reply_to, content, created_at, edited_at, deleted_at,
+			upvotes, downvotes, score, controversy, best
+		FROM comments
+		WHERE post_id = $1 AND reply_to IS NULL`
+	query, args := appendKeyset(query, []any{postID}, page, "comments")
+
+	return r.queryComments(ctx, query, args...)
+}
+
+func (r *CommentsRepository) GetRepliesByParentID(ctx context.Context, parentID uuid.UUID, page *pagination.Page) ([]*models.Comment, error) {
+	query := `
+		SELECT id, post_id, user_id, root_id, reply_to, content, created_at, edited_at, deleted_at,
+			upvotes, downvotes, score, controversy, best
+		FROM comments
+		WHERE reply_to = $1`
+	query, args := appendKeyset(query, []any{parentID}, page, "comments")
+
+	return r.queryComments(ctx, query, args...)
+}
+
+// Для каждого родителя выбирается не более limit последних ответов
+func (r *CommentsRepository) GetRepliesByParentIDs(ctx context.Context, parentIDs []uuid.UUID, limit int32) ([]*models.Comment, error) {
+	query := `
+		SELECT id, post_id, user_id, root_id, reply_to, content, created_at, edited_at, deleted_at,
+			upvotes, downvotes, score, controversy, best
+		FROM (
+			SELECT
+				id, post_id, user_id, root_id, reply_to, content, created_at, edited_at, deleted_at,
+				upvotes, downvotes, score, controversy, best,
+				ROW_NUMBER() OVER (PARTITION BY reply_to ORDER BY created_at DESC, id DESC) AS rn
+			FROM comments
+			WHERE reply_to = ANY($1)
+		) replies
+		WHERE rn <= $2
+		ORDER BY created_at DESC, id DESC;
+	`
+
+	return r.queryComments(ctx, query, parentIDs, limit)
+}
+
+func (r *CommentsRepository) CountRepliesByParentIDs(ctx context.Context, parentIDs []uuid.UUID) (map[uuid.UUID]int32, error) {
+	counts := make(map[uuid.UUID]int32, len(parentIDs))
+	for _, ID := range parentIDs {
+		counts[ID] = 0
 	}
 
 	query := `
-		SELECT id, post_id, user_id, root_id, reply_to, content, created_at
+		SELECT reply_to, COUNT(*)
 		FROM comments
-		WHERE post_id = $1 AND reply_to is NULL
-		ORDER BY created_at DESC
-		LIMIT $2 OFFSET $3;
+		WHERE reply_to = ANY($1)
+		GROUP BY reply_to;
 	`
 
 	querier := r.GetQuerier(ctx)
-	rows, err := querier.Query(ctx, query, postID, l, o)
+	rows, err := querier.Query(ctx, query, parentIDs)
+	if err != nil {
+		logger.Logger.Error("error during query", zap.Error(err))
+		return nil, errs.ErrInternal
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			parentID uuid.UUID
+			count    int32
+		)
+
+		err := rows.Scan(&parentID, &count)
+		if err != nil {
+			logger.Logger.Error("error scanning row", zap.Error(err))
+			return nil, errs.ErrInternal
+		}
+
+		counts[parentID] = count
+	}
+
+	if err := rows.Err(); err != nil {
+		logger.Logger.Error("error during row iteration", zap.Error(err))
+		return nil, errs.ErrInternal
+	}
+
+	return counts, nil
+}
+
+func (r *CommentsRepository) UpdateContent(ctx context.Context, commentID uuid.UUID, content string) (*models.Comment, error) {
+	comment := models.Comment{}
+
+	stmt := `
+		UPDATE comments
+		SET content = $2, edited_at = now()
+		WHERE id = $1
+		RETURNING id, post_id, user_id, root_id, reply_to, content, created_at, edited_at, deleted_at,
+			upvotes, downvotes, score, controversy, best;
+	`
+
+	querier := r.GetQuerier(ctx)
+	row := querier.QueryRow(ctx, stmt, commentID, content)
+
+	err := row.Scan(
+		&comment.ID,
+		&comment.PostID,
+		&comment.UserID,
+		&comment.RootID,
+		&comment.ReplyTo,
+		&comment.Content,
+		&comment.CreatedAt,
+		&comment.EditedAt,
+		&comment.DeletedAt,
+		&comment.Upvotes,
+		&comment.Downvotes,
+		&comment.Score,
+		&comment.Controversy,
+		&comment.Best,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("comment %w", errs.ErrNotFound)
+		}
+		logger.Logger.Error("error scanning row", zap.Error(err))
+		return nil, errs.ErrInternal
+	}
+
+	return &comment, nil
+}
+
+func (r *CommentsRepository) AddRevision(ctx context.Context, commentID uuid.UUID, content string) (*models.CommentRevision, error) {
+	revision := models.CommentRevision{}
+
+	stmt := `
+		INSERT INTO comment_revisions(id, comment_id, content)
+		VALUES ($1, $2, $3)
+		RETURNING id, comment_id, content, created_at;
+	`
+
+	querier := r.GetQuerier(ctx)
+	row := querier.QueryRow(ctx, stmt, uuid.New(), commentID, content)
+
+	err := row.Scan(
+		&revision.ID,
+		&revision.CommentID,
+		&revision.Content,
+		&revision.CreatedAt,
+	)
+	if err != nil {
+		logger.Logger.Error("error scanning row", zap.Error(err))
+		return nil, errs.ErrInternal
+	}
+
+	return &revision, nil
+}
+
+func (r *CommentsRepository) GetRevisionsByCommentIDs(ctx context.Context, commentIDs []uuid.UUID) (map[uuid.UUID][]*models.CommentRevision, error) {
+	revisions := make(map[uuid.UUID][]*models.CommentRevision, len(commentIDs))
+
+	query := `
+		SELECT id, comment_id, content, created_at
+		FROM comment_revisions
+		WHERE comment_id = ANY($1)
+		ORDER BY created_at, id;
+	`
+
+	querier := r.GetQuerier(ctx)
+	rows, err := querier.Query(ctx, query, commentIDs)
+	if err != nil {
+		logger.Logger.Error("error during query", zap.Error(err))
+		return nil, errs.ErrInternal
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		revision := models.CommentRevision{}
+
+		err := rows.Scan(
+			&revision.ID,
+			&revision.CommentID,
+			&revision.Content,
+			&revision.CreatedAt,
+		)
+		if err != nil {
+			logger.Logger.Error("error scanning row", zap.Error(err))
+			return nil, errs.ErrInternal
+		}
+
+		revisions[revision.CommentID] = append(revisions[revision.CommentID], &revision)
+	}
+
+	if err := rows.Err(); err != nil {
+		logger.Logger.Error("error during row iteration", zap.Error(err))
+		return nil, errs.ErrInternal
+	}
+
+	return revisions, nil
+}
+
+// SoftDelete стирает текст комментария, но оставляет сам комментарий в дереве,
+// чтобы не терять ответы на него. История правок сохраняется
+func (r *CommentsRepository) SoftDelete(ctx context.Context, commentID uuid.UUID) (*models.Comment, error) {
+	comment := models.Comment{}
+
+	querier := r.GetQuerier(ctx)
+
+	stmt := `
+		UPDATE comments
+		SET content = '', deleted_at = now()
+		WHERE id = $1
+		RETURNING id, post_id, user_id, root_id, reply_to, content, created_at, edited_at, deleted_at,
+			upvotes, downvotes, score, controversy, best;
+	`
+
+	row := querier.QueryRow(ctx, stmt, commentID)
+
+	err := row.Scan(
+		&comment.ID,
+		&comment.PostID,
+		&comment.UserID,
+		&comment.RootID,
+		&comment.ReplyTo,
+		&comment.Content,
+		&comment.CreatedAt,
+		&comment.EditedAt,
+		&comment.DeletedAt,
+		&comment.Upvotes,
+		&comment.Downvotes,
+		&comment.Score,
+		&comment.Controversy,
+		&comment.Best,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("comment %w", errs.ErrNotFound)
+		}
+		logger.Logger.Error("error scanning row", zap.Error(err))
+		return nil, errs.ErrInternal
+	}
+
+	return &comment, nil
+}
+
+// HasUndeletedReplies проверяет все поддерево ответов, а не только прямые ответы
+func (r *CommentsRepository) HasUndeletedReplies(ctx context.Context, commentID uuid.UUID) (bool, error) {
+	var exists bool
+
+	query := `
+		WITH RECURSIVE subtree AS (
+			SELECT id, deleted_at
+			FROM comments
+			WHERE reply_to = $1
+			UNION ALL
+			SELECT c.id, c.deleted_at
+			FROM comments c
+			JOIN subtree s ON c.reply_to = s.id
+		)
+		SELECT EXISTS (SELECT 1 FROM subtree WHERE deleted_at IS NULL);
+	`
+
+	querier := r.GetQuerier(ctx)
+	err := querier.QueryRow(ctx, query, commentID).Scan(&exists)
+	if err != nil {
+		logger.Logger.Error("error scanning row", zap.Error(err))
+		return false, errs.ErrInternal
+	}
+
+	return exists, nil
+}
+
+// Ответы на комментарий удаляются каскадно
+func (r *CommentsRepository) Delete(ctx context.Context, commentID uuid.UUID) error {
+	querier := r.GetQuerier(ctx)
+	tag, err := querier.Exec(ctx, "DELETE FROM comments WHERE id = $1;", commentID)
+	if err != nil {
+		logger.Logger.Error("error executing statement", zap.Error(err))
+		return errs.ErrInternal
+	}
+
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("comment %w", errs.ErrNotFound)
+	}
+
+	return nil
+}
+
+func (r *CommentsRepository) queryComments(ctx context.Context, query string, args ...any) ([]*models.Comment, error) {
+	comments := []*models.Comment{}
+
+	querier := r.GetQuerier(ctx)
+	rows, err := querier.Query(ctx, query, args...)
 	if err != nil {
 		logger.Logger.Error("error during query", zap.Error(err))
 		return nil, errs.ErrInternal
@@ -134,6 +440,13 @@ func (r *CommentsRepository) GetRootCommentsByPostID(ctx context.Context, postID
 			&comment.ReplyTo,
 			&comment.Content,
 			&comment.CreatedAt,
+			&comment.EditedAt,
+			&comment.DeletedAt,
+			&comment.Upvotes,
+			&comment.Downvotes,
+			&comment.Score,
+			&comment.Controversy,
+			&comment.Best,
 		)
 		if err != nil {
 			logger.Logger.Error("error scanning row", zap.Error(err))
@@ -151,18 +464,199 @@ func (r *CommentsRepository) GetRootCommentsByPostID(ctx context.Context, postID
 	return comments, nil
 }
 
-func (r *CommentsRepository) GetChildrenCommentsByRootIDs(ctx context.Context, rootIDs []*uuid.UUID) ([]*models.Comment, error) {
-	comments := []*models.Comment{}
+// Удаленные комментарии не учитываются
+func (r *CommentsRepository) CountByUserIDs(ctx context.Context, userIDs []uuid.UUID) (map[uuid.UUID]int32, error) {
+	counts := make(map[uuid.UUID]int32, len(userIDs))
+	for _, ID := range userIDs {
+		counts[ID] = 0
+	}
 
 	query := `
-		SELECT id, post_id, user_id, root_id, reply_to, content, created_at
+		SELECT user_id, COUNT(*)
 		FROM comments
-		WHERE root_id = ANY($1)
-		ORDER BY created_at DESC;
+		WHERE user_id = ANY($1) AND deleted_at IS NULL
+		GROUP BY user_id;
+	`
+
+	querier := r.GetQuerier(ctx)
+	rows, err := querier.Query(ctx, query, userIDs)
+	if err != nil {
+		logger.Logger.Error("error during query", zap.Error(err))
+		return nil, errs.ErrInternal
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			userID uuid.UUID
+			count  int32
+		)
+
+		err := rows.Scan(&userID, &count)
+		if err != nil {
+			logger.Logger.Error("error scanning row", zap.Error(err))
+			return nil, errs.ErrInternal
+		}
+
+		counts[userID] = count
+	}
+
+	if err := rows.Err(); err != nil {
+		logger.Logger.Error("error during row iteration", zap.Error(err))
+		return nil, errs.ErrInternal
+	}
+
+	return counts, nil
+}
+
+func (r *CommentsRepository) GetVote(ctx context.Context, commentID, userID uuid.UUID) (*models.CommentVote, error) {
+	vote := models.CommentVote{}
+
+	query := `
+		SELECT comment_id, user_id, value, created_at
+		FROM comment_votes
+		WHERE comment_id = $1 AND user_id = $2;
+	`
+
+	querier := r.GetQuerier(ctx)
+	row := querier.QueryRow(ctx, query, commentID, userID)
+
+	err := row.Scan(
+		&vote.CommentID,
+		&vote.UserID,
+		&vote.Value,
+		&vote.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("vote %w", errs.ErrNotFound)
+		}
+		logger.Logger.Error("error scanning row", zap.Error(err))
+		return nil, errs.ErrInternal
+	}
+
+	return &vote, nil
+}
+
+// SetVote заменяет прежний голос пользователя, сохраняя время первого голоса
+func (r *CommentsRepository) SetVote(ctx context.Context, commentID, userID uuid.UUID, value int8) (*models.CommentVote, error) {
+	vote := models.CommentVote{}
+
+	stmt := `
+		INSERT INTO comment_votes(comment_id, user_id, value)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (comment_id, user_id) DO UPDATE SET value = EXCLUDED.value
+		RETURNING comment_id, user_id, value, created_at;
+	`
+
+	querier := r.GetQuerier(ctx)
+	row := querier.QueryRow(ctx, stmt, commentID, userID, value)
+
+	err := row.Scan(
+		&vote.CommentID,
+		&vote.UserID,
+		&vote.Value,
+		&vote.CreatedAt,
+	)
+	if err != nil {
+		logger.Logger.Error("error scanning row", zap.Error(err))
+		return nil, errs.ErrInternal
+	}
+
+	return &vote, nil
+}
+
+func (r *CommentsRepository) DeleteVote(ctx context.Context, commentID, userID uuid.UUID) error {
+	querier := r.GetQuerier(ctx)
+	_, err := querier.Exec(ctx, "DELETE FROM comment_votes WHERE comment_id = $1 AND user_id = $2;", commentID, userID)
+	if err != nil {
+		logger.Logger.Error("error executing statement", zap.Error(err))
+		return errs.ErrInternal
+	}
+
+	return nil
+}
+
+func (r *CommentsRepository) UpdateVotes(ctx context.Context, commentID uuid.UUID, votes models.CommentVotes) (*models.Comment, error) {
+	comment := models.Comment{}
+
+	stmt := `
+		UPDATE comments
+		SET upvotes = $2, downvotes = $3, score = $4, controversy = $5, best = $6
+		WHERE id = $1
+		RETURNING id, post_id, user_id, root_id, reply_to, content, created_at, edited_at, deleted_at,
+			upvotes, downvotes, score, controversy, best;
 	`
 
 	querier := r.GetQuerier(ctx)
-	rows, err := querier.Query(ctx, query, rootIDs)
+	row := querier.QueryRow(
+		ctx,
+		stmt,
+		commentID,
+		votes.Upvotes,
+		votes.Downvotes,
+		votes.Score,
+		votes.Controversy,
+		votes.Best,
+	)
+
+	err := row.Scan(
+		&comment.ID,
+		&comment.PostID,
+		&comment.UserID,
+		&comment.RootID,
+		&comment.ReplyTo,
+		&comment.Content,
+		&comment.CreatedAt,
+		&comment.EditedAt,
+		&comment.DeletedAt,
+		&comment.Upvotes,
+		&comment.Downvotes,
+		&comment.Score,
+		&comment.Controversy,
+		&comment.Best,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("comment %w", errs.ErrNotFound)
+		}
+		logger.Logger.Error("error scanning row", zap.Error(err))
+		return nil, errs.ErrInternal
+	}
+
+	return &comment, nil
+}
+
+func (r *CommentsRepository) AddReaction(ctx context.Context, commentID, userID uuid.UUID, emoji string) (bool, error) {
+	return commentReactions.add(ctx, r.GetQuerier(ctx), commentID, userID, emoji)
+}
+
+func (r *CommentsRepository) DeleteReaction(ctx context.Context, commentID, userID uuid.UUID, emoji string) (bool, error) {
+	return commentReactions.delete(ctx, r.GetQuerier(ctx), commentID, userID, emoji)
+}
+
+func (r *CommentsRepository) GetReactions(ctx context.Context, commentIDs []uuid.UUID, viewerID *uuid.UUID) (map[uuid.UUID][]*models.ReactionCount, error) {
+	return commentReactions.counts(ctx, r.GetQuerier(ctx), commentIDs, viewerID)
+}
+
+func (r *CommentsRepository) Search(ctx context.Context, query string, page *pagination.Page) ([]*models.SearchHit, error) {
+	hits := []*models.SearchHit{}
+
+	sqlQuery := `
+		SELECT hits.id, hits.post_id, hits.user_id, hits.root_id, hits.reply_to, hits.content,
+			hits.created_at, hits.edited_at, hits.deleted_at,
+			hits.upvotes, hits.downvotes, hits.score, hits.controversy, hits.best,
+			hits.rank, ts_headline('simple', hits.content, hits.q, $2)
+		FROM (
+			SELECT comments.*, q, ts_rank(comments.search_vector, q)::float8 AS rank
+			FROM comments, plainto_tsquery('simple', $1) q
+			WHERE comments.search_vector @@ q
+		) hits
+		WHERE hits.deleted_at IS NULL`
+	sqlQuery, args := appendKeyset(sqlQuery, []any{query, headlineOptions}, page, "hits")
+
+	querier := r.GetQuerier(ctx)
+	rows, err := querier.Query(ctx, sqlQuery, args...)
 	if err != nil {
 		logger.Logger.Error("error during query", zap.Error(err))
 		return nil, errs.ErrInternal
@@ -171,6 +665,7 @@ func (r *CommentsRepository) GetChildrenCommentsByRootIDs(ctx context.Context, r
 
 	for rows.Next() {
 		comment := models.Comment{}
+		hit := models.SearchHit{Comment: &comment}
 
 		err := rows.Scan(
 			&comment.ID,
@@ -180,13 +675,24 @@ func (r *CommentsRepository) GetChildrenCommentsByRootIDs(ctx context.Context, r
 			&comment.ReplyTo,
 			&comment.Content,
 			&comment.CreatedAt,
+			&comment.EditedAt,
+			&comment.DeletedAt,
+			&comment.Upvotes,
+			&comment.Downvotes,
+			&comment.Score,
+			&comment.Controversy,
+			&comment.Best,
+			&hit.Rank,
+			&hit.Snippet,
 		)
 		if err != nil {
 			logger.Logger.Error("error scanning row", zap.Error(err))
 			return nil, errs.ErrInternal
 		}
 
-		comments = append(comments, &comment)
+		hit.Snippet = search.Highlight(hit.Snippet)
+
+		hits = append(hits, &hit)
 	}
 
 	if err := rows.Err(); err != nil {
@@ -194,5 +700,5 @@ func (r *CommentsRepository) GetChildrenCommentsByRootIDs(ctx context.Context, r
 		return nil, errs.ErrInternal
 	}
 
-	return comments, nil
+	return hits, nil
 }
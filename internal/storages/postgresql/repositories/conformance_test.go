@@ -0,0 +1,152 @@
+package repositories_test
+
+import (
+	"context"
+	"flag"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/Govorov1705/ozon-test/internal/logger"
+	"github.com/Govorov1705/ozon-test/internal/repositories/conformance"
+	"github.com/Govorov1705/ozon-test/internal/storages/postgresql"
+	"github.com/Govorov1705/ozon-test/internal/storages/postgresql/repositories"
+	embeddedpostgres "github.com/fergusstrange/embedded-postgres"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	pool *pgxpool.Pool
+	// Причина, по которой PostgreSQL недоступен
+	poolErr error
+	// Причина, по которой тесты PostgreSQL пропускаются по запросу
+	skipReason string
+)
+
+// TestMain подключается к базе из TEST_DB_URL, а без нее поднимает временный
+// PostgreSQL той же версии, что и в docker-compose (при первом запуске его
+// бинарники скачиваются из Maven). Таблицы тестовой базы очищаются, поэтому
+// TEST_DB_URL не должна указывать на рабочую базу.
+// Если PostgreSQL недоступен, TestConformance падает; пропустить его можно
+// флагом -short или переменной SKIP_POSTGRES_TESTS=true
+func TestMain(m *testing.M) {
+	flag.Parse()
+	logger.InitLogger()
+
+	if testing.Short() {
+		skipReason = "skipped in short mode"
+		os.Exit(m.Run())
+	}
+	if skip, _ := strconv.ParseBool(os.Getenv("SKIP_POSTGRES_TESTS")); skip {
+		skipReason = "skipped by SKIP_POSTGRES_TESTS"
+		os.Exit(m.Run())
+	}
+
+	if DBURL := os.Getenv("TEST_DB_URL"); DBURL != "" {
+		poolErr = connect(DBURL)
+		code := m.Run()
+		if pool != nil {
+			pool.Close()
+		}
+		os.Exit(code)
+	}
+
+	db, err := startPostgres()
+	if err != nil {
+		poolErr = err
+		os.Exit(m.Run())
+	}
+
+	code := m.Run()
+	pool.Close()
+	db.Stop()
+	os.Exit(code)
+}
+
+// connect применяет миграции и открывает пул
+func connect(DBURL string) error {
+	m, err := postgresql.NewMigrator(DBURL)
+	if err != nil {
+		return err
+	}
+	err = m.Up()
+	m.Close()
+	if err != nil {
+		return err
+	}
+
+	pool, err = pgxpool.New(context.Background(), DBURL)
+	return err
+}
+
+func startPostgres() (*embeddedpostgres.EmbeddedPostgres, error) {
+	port, err := freePort()
+	if err != nil {
+		return nil, err
+	}
+
+	runtimePath, err := os.MkdirTemp("", "ozon-test-postgres-")
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := embeddedpostgres.DefaultConfig().
+		Version(embeddedpostgres.V17).
+		Port(port).
+		RuntimePath(runtimePath).
+		Logger(io.Discard)
+
+	db := embeddedpostgres.NewDatabase(cfg)
+	err = db.Start()
+	if err != nil {
+		os.RemoveAll(runtimePath)
+		return nil, err
+	}
+
+	err = connect(cfg.GetConnectionURL() + "?")
+	if err != nil {
+		db.Stop()
+		return nil, err
+	}
+
+	return db, nil
+}
+
+func freePort() (uint32, error) {
+	l, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		return 0, err
+	}
+	defer l.Close()
+
+	return uint32(l.Addr().(*net.TCPAddr).Port), nil
+}
+
+func TestConformance(t *testing.T) {
+	if skipReason != "" {
+		t.Skip(skipReason)
+	}
+	if poolErr != nil {
+		t.Fatalf("PostgreSQL is unavailable: %v", poolErr)
+	}
+
+	conformance.Run(t, func(t *testing.T) *conformance.Backend {
+		_, err := pool.Exec(context.Background(), `
+			TRUNCATE users, posts, comments, comment_revisions, refresh_tokens, outbox CASCADE;
+		`)
+		require.NoError(t, err)
+
+		return &conformance.Backend{
+			TxStarter:     postgresql.NewPgxpoolTxStarter(pool),
+			Users:         repositories.NewUsersRepository(pool),
+			RefreshTokens: repositories.NewRefreshTokensRepository(pool),
+			Posts:         repositories.NewPostsRepository(pool),
+			Comments:      repositories.NewCommentsRepository(pool),
+			Communities:   repositories.NewCommunitiesRepository(pool),
+			Outbox:        repositories.NewOutboxRepository(pool),
+		}
+	})
+}
@@ -0,0 +1,106 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/Govorov1705/ozon-test/internal/errs"
+	"github.com/Govorov1705/ozon-test/internal/logger"
+	"github.com/Govorov1705/ozon-test/internal/models"
+	"github.com/Govorov1705/ozon-test/internal/repositories"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+type OutboxRepository struct {
+	*BaseRepository
+}
+
+func NewOutboxRepository(pool *pgxpool.Pool) repositories.OutboxRepository {
+	return &OutboxRepository{
+		BaseRepository: NewBaseRepository(pool),
+	}
+}
+
+func (r *OutboxRepository) Add(ctx context.Context, ID uuid.UUID, eventType string, payload []byte) error {
+	stmt := `
+		INSERT INTO outbox(id, type, payload)
+		VALUES ($1, $2, $3);
+	`
+
+	return r.exec(ctx, stmt, ID, eventType, payload)
+}
+
+func (r *OutboxRepository) GetUnprocessed(ctx context.Context, limit int32) ([]*models.OutboxEvent, error) {
+	query := `
+		SELECT id, type, payload, created_at, processed_at
+		FROM outbox
+		WHERE processed_at IS NULL
+		ORDER BY created_at, id
+		LIMIT $1
+		FOR UPDATE SKIP LOCKED;
+	`
+
+	querier := r.GetQuerier(ctx)
+	rows, err := querier.Query(ctx, query, limit)
+	if err != nil {
+		logger.Logger.Error("error querying rows", zap.Error(err))
+		return nil, errs.ErrInternal
+	}
+	defer rows.Close()
+
+	events := []*models.OutboxEvent{}
+	for rows.Next() {
+		event := models.OutboxEvent{}
+		err := rows.Scan(
+			&event.ID,
+			&event.Type,
+			&event.Payload,
+			&event.CreatedAt,
+			&event.ProcessedAt,
+		)
+		if err != nil {
+			logger.Logger.Error("error scanning row", zap.Error(err))
+			return nil, errs.ErrInternal
+		}
+		events = append(events, &event)
+	}
+
+	if err := rows.Err(); err != nil {
+		logger.Logger.Error("error iterating rows", zap.Error(err))
+		return nil, errs.ErrInternal
+	}
+
+	return events, nil
+}
+
+func (r *OutboxRepository) MarkProcessed(ctx context.Context, IDs []uuid.UUID) error {
+	stmt := `
+		UPDATE outbox
+		SET processed_at = now()
+		WHERE id = ANY($1);
+	`
+
+	return r.exec(ctx, stmt, IDs)
+}
+
+func (r *OutboxRepository) DeleteProcessedBefore(ctx context.Context, before time.Time) error {
+	stmt := `
+		DELETE FROM outbox
+		WHERE processed_at < $1;
+	`
+
+	return r.exec(ctx, stmt, before)
+}
+
+func (r *OutboxRepository) exec(ctx context.Context, stmt string, args ...any) error {
+	querier := r.GetQuerier(ctx)
+	_, err := querier.Exec(ctx, stmt, args...)
+	if err != nil {
+		logger.Logger.Error("error executing statement", zap.Error(err))
+		return errs.ErrInternal
+	}
+
+	return nil
+}
@@ -0,0 +1,101 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/Govorov1705/ozon-test/internal/errs"
+	"github.com/Govorov1705/ozon-test/internal/logger"
+	"github.com/Govorov1705/ozon-test/internal/models"
+	"github.com/Govorov1705/ozon-test/internal/storages/postgresql"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// reactionsTable - таблица реакций на посты или комментарии. Таблицы
+// устроены одинаково и различаются только столбцом с ID цели
+type reactionsTable struct {
+	name         string
+	targetColumn string
+}
+
+var (
+	postReactions    = reactionsTable{name: "post_reactions", targetColumn: "post_id"}
+	commentReactions = reactionsTable{name: "comment_reactions", targetColumn: "comment_id"}
+)
+
+func (t reactionsTable) add(ctx context.Context, querier postgresql.Querier, targetID, userID uuid.UUID, emoji string) (bool, error) {
+	stmt := `
+		INSERT INTO ` + t.name + `(` + t.targetColumn + `, user_id, emoji)
+		VALUES ($1, $2, $3)
+		ON CONFLICT DO NOTHING;
+	`
+
+	tag, err := querier.Exec(ctx, stmt, targetID, userID, emoji)
+	if err != nil {
+		logger.Logger.Error("error executing statement", zap.Error(err))
+		return false, errs.ErrInternal
+	}
+
+	return tag.RowsAffected() == 1, nil
+}
+
+func (t reactionsTable) delete(ctx context.Context, querier postgresql.Querier, targetID, userID uuid.UUID, emoji string) (bool, error) {
+	stmt := `
+		DELETE FROM ` + t.name + `
+		WHERE ` + t.targetColumn + ` = $1 AND user_id = $2 AND emoji = $3;
+	`
+
+	tag, err := querier.Exec(ctx, stmt, targetID, userID, emoji)
+	if err != nil {
+		logger.Logger.Error("error executing statement", zap.Error(err))
+		return false, errs.ErrInternal
+	}
+
+	return tag.RowsAffected() == 1, nil
+}
+
+// Для анонимного пользователя сравнение с NULL дает NULL, и BOOL_OR
+// возвращает NULL, поэтому нужен COALESCE
+func (t reactionsTable) counts(ctx context.Context, querier postgresql.Querier, targetIDs []uuid.UUID, viewerID *uuid.UUID) (map[uuid.UUID][]*models.ReactionCount, error) {
+	counts := make(map[uuid.UUID][]*models.ReactionCount, len(targetIDs))
+	for _, ID := range targetIDs {
+		counts[ID] = []*models.ReactionCount{}
+	}
+
+	query := `
+		SELECT ` + t.targetColumn + `, emoji, COUNT(*), COALESCE(BOOL_OR(user_id = $2), false)
+		FROM ` + t.name + `
+		WHERE ` + t.targetColumn + ` = ANY($1)
+		GROUP BY ` + t.targetColumn + `, emoji
+		ORDER BY MIN(created_at), emoji;
+	`
+
+	rows, err := querier.Query(ctx, query, targetIDs, viewerID)
+	if err != nil {
+		logger.Logger.Error("error during query", zap.Error(err))
+		return nil, errs.ErrInternal
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			targetID uuid.UUID
+			count    models.ReactionCount
+		)
+
+		err := rows.Scan(&targetID, &count.Emoji, &count.Count, &count.ViewerHasReacted)
+		if err != nil {
+			logger.Logger.Error("error scanning row", zap.Error(err))
+			return nil, errs.ErrInternal
+		}
+
+		counts[targetID] = append(counts[targetID], &count)
+	}
+
+	if err := rows.Err(); err != nil {
+		logger.Logger.Error("error during row iteration", zap.Error(err))
+		return nil, errs.ErrInternal
+	}
+
+	return counts, nil
+}
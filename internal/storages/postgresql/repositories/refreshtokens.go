@@ -0,0 +1,151 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Govorov1705/ozon-test/internal/errs"
+	"github.com/Govorov1705/ozon-test/internal/logger"
+	"github.com/Govorov1705/ozon-test/internal/models"
+	"github.com/Govorov1705/ozon-test/internal/repositories"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+type RefreshTokensRepository struct {
+	*BaseRepository
+}
+
+func NewRefreshTokensRepository(pool *pgxpool.Pool) repositories.RefreshTokensRepository {
+	return &RefreshTokensRepository{
+		BaseRepository: NewBaseRepository(pool),
+	}
+}
+
+func (r *RefreshTokensRepository) Add(ctx context.Context, ID, sessionID, userID uuid.UUID, tokenHash string, expiresAt time.Time) (*models.RefreshToken, error) {
+	token := models.RefreshToken{}
+
+	stmt := `
+		INSERT INTO refresh_tokens(id, session_id, user_id, token_hash, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, session_id, user_id, token_hash, expires_at, revoked_at, created_at;
+	`
+
+	querier := r.GetQuerier(ctx)
+	row := querier.QueryRow(ctx, stmt, ID, sessionID, userID, tokenHash, expiresAt)
+
+	err := row.Scan(
+		&token.ID,
+		&token.SessionID,
+		&token.UserID,
+		&token.TokenHash,
+		&token.ExpiresAt,
+		&token.RevokedAt,
+		&token.CreatedAt,
+	)
+	var pgErr *pgconn.PgError
+	if err != nil {
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return nil, errs.ErrAlreadyExists
+		}
+		logger.Logger.Error("error scanning row", zap.Error(err))
+		return nil, errs.ErrInternal
+	}
+
+	return &token, nil
+}
+
+func (r *RefreshTokensRepository) GetByID(ctx context.Context, ID uuid.UUID) (*models.RefreshToken, error) {
+	query := `
+		SELECT id, session_id, user_id, token_hash, expires_at, revoked_at, created_at
+		FROM refresh_tokens
+		WHERE id = $1;
+	`
+
+	return r.getOne(ctx, query, ID)
+}
+
+func (r *RefreshTokensRepository) GetByTokenHash(ctx context.Context, tokenHash string, forUpdate bool) (*models.RefreshToken, error) {
+	query := `
+		SELECT id, session_id, user_id, token_hash, expires_at, revoked_at, created_at
+		FROM refresh_tokens
+		WHERE token_hash = $1`
+	if forUpdate {
+		query += " FOR UPDATE"
+	}
+	query += ";"
+
+	return r.getOne(ctx, query, tokenHash)
+}
+
+func (r *RefreshTokensRepository) Revoke(ctx context.Context, ID uuid.UUID) error {
+	stmt := `
+		UPDATE refresh_tokens
+		SET revoked_at = now()
+		WHERE id = $1 AND revoked_at IS NULL;
+	`
+
+	return r.exec(ctx, stmt, ID)
+}
+
+func (r *RefreshTokensRepository) RevokeSession(ctx context.Context, sessionID uuid.UUID) error {
+	stmt := `
+		UPDATE refresh_tokens
+		SET revoked_at = now()
+		WHERE session_id = $1 AND revoked_at IS NULL;
+	`
+
+	return r.exec(ctx, stmt, sessionID)
+}
+
+func (r *RefreshTokensRepository) RevokeAllByUserID(ctx context.Context, userID uuid.UUID) error {
+	stmt := `
+		UPDATE refresh_tokens
+		SET revoked_at = now()
+		WHERE user_id = $1 AND revoked_at IS NULL;
+	`
+
+	return r.exec(ctx, stmt, userID)
+}
+
+func (r *RefreshTokensRepository) getOne(ctx context.Context, query string, args ...any) (*models.RefreshToken, error) {
+	token := models.RefreshToken{}
+
+	querier := r.GetQuerier(ctx)
+	row := querier.QueryRow(ctx, query, args...)
+
+	err := row.Scan(
+		&token.ID,
+		&token.SessionID,
+		&token.UserID,
+		&token.TokenHash,
+		&token.ExpiresAt,
+		&token.RevokedAt,
+		&token.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("refresh token %w", errs.ErrNotFound)
+		}
+		logger.Logger.Error("error scanning row", zap.Error(err))
+		return nil, errs.ErrInternal
+	}
+
+	return &token, nil
+}
+
+func (r *RefreshTokensRepository) exec(ctx context.Context, stmt string, args ...any) error {
+	querier := r.GetQuerier(ctx)
+	_, err := querier.Exec(ctx, stmt, args...)
+	if err != nil {
+		logger.Logger.Error("error executing statement", zap.Error(err))
+		return errs.ErrInternal
+	}
+
+	return nil
+}
@@ -0,0 +1,19 @@
+package repositories
+
+import (
+	"fmt"
+
+	"github.com/Govorov1705/ozon-test/internal/search"
+)
+
+// headlineOptions настраивает ts_headline под разметку фрагментов,
+// общую для всех хранилищ. Слова выделяются метками, а не тегами: ts_headline
+// не экранирует текст, поэтому результат проходит через search.Highlight.
+// ShortWord=0 не дает отбрасывать короткие слова на краях фрагмента
+var headlineOptions = fmt.Sprintf(
+	"StartSel=%s, StopSel=%s, MaxWords=%d, MinWords=%d, ShortWord=0",
+	search.MarkStart,
+	search.MarkEnd,
+	search.SnippetWords,
+	search.SnippetWords/2,
+)
@@ -9,6 +9,7 @@ import (
 	"github.com/Govorov1705/ozon-test/internal/logger"
 	"github.com/Govorov1705/ozon-test/internal/models"
 	"github.com/Govorov1705/ozon-test/internal/repositories"
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -25,11 +26,82 @@ func NewUsersRepository(pool *pgxpool.Pool) repositories.UsersRepository {
 	}
 }
 
+func (r *UsersRepository) GetByID(ctx context.Context, ID uuid.UUID) (*models.User, error) {
+	user := models.User{}
+
+	query := `
+		SELECT id, username, hashed_password, created_at
+		FROM users
+		WHERE id = $1;
+	`
+
+	querier := r.GetQuerier(ctx)
+	row := querier.QueryRow(ctx, query, ID)
+
+	err := row.Scan(
+		&user.ID,
+		&user.Username,
+		&user.HashedPassword,
+		&user.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("user %w", errs.ErrNotFound)
+		}
+		logger.Logger.Error("error scanning row", zap.Error(err))
+		return nil, errs.ErrInternal
+	}
+
+	return &user, nil
+}
+
+func (r *UsersRepository) GetByIDs(ctx context.Context, IDs []uuid.UUID) ([]*models.User, error) {
+	users := []*models.User{}
+
+	query := `
+		SELECT id, username, hashed_password, created_at
+		FROM users
+		WHERE id = ANY($1);
+	`
+
+	querier := r.GetQuerier(ctx)
+	rows, err := querier.Query(ctx, query, IDs)
+	if err != nil {
+		logger.Logger.Error("error during query", zap.Error(err))
+		return nil, errs.ErrInternal
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		user := models.User{}
+
+		err := rows.Scan(
+			&user.ID,
+			&user.Username,
+			&user.HashedPassword,
+			&user.CreatedAt,
+		)
+		if err != nil {
+			logger.Logger.Error("error scanning row", zap.Error(err))
+			return nil, errs.ErrInternal
+		}
+
+		users = append(users, &user)
+	}
+
+	if err := rows.Err(); err != nil {
+		logger.Logger.Error("error during row iteration", zap.Error(err))
+		return nil, errs.ErrInternal
+	}
+
+	return users, nil
+}
+
 func (r *UsersRepository) GetByUsername(ctx context.Context, username string) (*models.User, error) {
 	user := models.User{}
 
 	query := `
-		SELECT id, username, hashed_password
+		SELECT id, username, hashed_password, created_at
 		FROM users
 		WHERE username = $1;
 	`
@@ -41,6 +113,7 @@ func (r *UsersRepository) GetByUsername(ctx context.Context, username string) (*
 		&user.ID,
 		&user.Username,
 		&user.HashedPassword,
+		&user.CreatedAt,
 	)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -59,7 +132,7 @@ func (r *UsersRepository) Add(ctx context.Context, username, hashedPassword stri
 	stmt := `
 		INSERT INTO users(username, hashed_password) 
 		VALUES ($1, $2)
-		RETURNING id, username, hashed_password;
+		RETURNING id, username, hashed_password, created_at;
 	`
 
 	querier := r.GetQuerier(ctx)
@@ -69,6 +142,7 @@ func (r *UsersRepository) Add(ctx context.Context, username, hashedPassword stri
 		&user.ID,
 		&user.Username,
 		&user.HashedPassword,
+		&user.CreatedAt,
 	)
 	var pgErr *pgconn.PgError
 	if err != nil {
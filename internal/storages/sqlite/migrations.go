@@ -0,0 +1,27 @@
+package sqlite
+
+import (
+	"database/sql"
+	"embed"
+
+	"github.com/Govorov1705/ozon-test/internal/migrations"
+	"github.com/golang-migrate/migrate/v4"
+	migrateSQLite "github.com/golang-migrate/migrate/v4/database/sqlite"
+	"github.com/golang-migrate/migrate/v4/source"
+)
+
+//go:embed migrations/*.sql
+var migrationsFS embed.FS
+
+// NewMigrator работает поверх уже открытой базы, поэтому закрытие
+// мигратора закрывает и db
+func NewMigrator(db *sql.DB) (*migrations.Migrator, error) {
+	return migrations.New(migrationsFS, func(src source.Driver) (*migrate.Migrate, error) {
+		// Миграции сами открывают транзакцию, как и для PostgreSQL
+		driver, err := migrateSQLite.WithInstance(db, &migrateSQLite.Config{NoTxWrap: true})
+		if err != nil {
+			return nil, err
+		}
+		return migrate.NewWithInstance("iofs", src, "sqlite", driver)
+	})
+}
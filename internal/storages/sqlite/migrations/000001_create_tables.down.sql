@@ -0,0 +1,10 @@
+BEGIN;
+
+DROP TABLE IF EXISTS outbox;
+DROP TABLE IF EXISTS refresh_tokens;
+DROP TABLE IF EXISTS comment_revisions;
+DROP TABLE IF EXISTS comments;
+DROP TABLE IF EXISTS posts;
+DROP TABLE IF EXISTS users;
+
+COMMIT;
@@ -0,0 +1,75 @@
+BEGIN;
+
+CREATE TABLE users (
+    id TEXT PRIMARY KEY,
+    username VARCHAR(100) NOT NULL UNIQUE,
+    hashed_password VARCHAR(60) NOT NULL,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE TABLE posts (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    title VARCHAR(100) NOT NULL,
+    content VARCHAR(2000) NOT NULL,
+    are_comments_allowed BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP,
+    deleted_at TIMESTAMP
+);
+
+CREATE TABLE comments (
+    id TEXT PRIMARY KEY,
+    post_id TEXT NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    root_id TEXT NOT NULL REFERENCES comments(id) ON DELETE CASCADE,
+    reply_to TEXT REFERENCES comments(id) ON DELETE CASCADE,
+    content VARCHAR(2000) NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    edited_at TIMESTAMP,
+    deleted_at TIMESTAMP
+);
+
+CREATE TABLE comment_revisions (
+    id TEXT PRIMARY KEY,
+    comment_id TEXT NOT NULL REFERENCES comments(id) ON DELETE CASCADE,
+    content VARCHAR(2000) NOT NULL,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE TABLE refresh_tokens (
+    id TEXT PRIMARY KEY,
+    session_id TEXT NOT NULL,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    expires_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE TABLE outbox (
+    id TEXT PRIMARY KEY,
+    type VARCHAR(64) NOT NULL,
+    payload BLOB NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    processed_at TIMESTAMP
+);
+
+CREATE INDEX idx_posts_created_at_id ON posts(created_at DESC, id DESC);
+CREATE INDEX idx_posts_user_id ON posts(user_id) WHERE deleted_at IS NULL;
+
+CREATE INDEX idx_comments_root_id ON comments(root_id);
+CREATE INDEX idx_comments_post_keyset ON comments(post_id, created_at, id);
+CREATE INDEX idx_comments_root_keyset ON comments(post_id, created_at DESC, id DESC) WHERE reply_to IS NULL;
+CREATE INDEX idx_comments_replies_keyset ON comments(reply_to, created_at DESC, id DESC) WHERE reply_to IS NOT NULL;
+CREATE INDEX idx_comments_user_id ON comments(user_id) WHERE deleted_at IS NULL;
+
+CREATE INDEX idx_comment_revisions_comment_id ON comment_revisions(comment_id, created_at);
+
+CREATE INDEX idx_refresh_tokens_session_id ON refresh_tokens(session_id);
+CREATE INDEX idx_refresh_tokens_user_id ON refresh_tokens(user_id);
+
+CREATE INDEX idx_outbox_unprocessed ON outbox(created_at) WHERE processed_at IS NULL;
+CREATE INDEX idx_outbox_processed_at ON outbox(processed_at) WHERE processed_at IS NOT NULL;
+
+COMMIT;
@@ -0,0 +1,15 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx_comments_root_best;
+DROP INDEX IF EXISTS idx_comments_root_controversial;
+DROP INDEX IF EXISTS idx_comments_root_top;
+
+DROP TABLE IF EXISTS comment_votes;
+
+ALTER TABLE comments DROP COLUMN best;
+ALTER TABLE comments DROP COLUMN controversy;
+ALTER TABLE comments DROP COLUMN score;
+ALTER TABLE comments DROP COLUMN downvotes;
+ALTER TABLE comments DROP COLUMN upvotes;
+
+COMMIT;
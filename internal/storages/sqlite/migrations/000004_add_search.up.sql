@@ -0,0 +1,43 @@
+BEGIN;
+
+CREATE VIRTUAL TABLE posts_fts USING fts5(
+    id UNINDEXED,
+    title,
+    content,
+    tokenize = 'unicode61 remove_diacritics 0'
+);
+
+CREATE VIRTUAL TABLE comments_fts USING fts5(
+    id UNINDEXED,
+    content,
+    tokenize = 'unicode61 remove_diacritics 0'
+);
+
+INSERT INTO posts_fts(id, title, content) SELECT id, title, content FROM posts;
+INSERT INTO comments_fts(id, content) SELECT id, content FROM comments;
+
+CREATE TRIGGER posts_fts_insert AFTER INSERT ON posts BEGIN
+    INSERT INTO posts_fts(id, title, content) VALUES (new.id, new.title, new.content);
+END;
+
+CREATE TRIGGER posts_fts_update AFTER UPDATE OF title, content ON posts BEGIN
+    UPDATE posts_fts SET title = new.title, content = new.content WHERE id = new.id;
+END;
+
+CREATE TRIGGER posts_fts_delete AFTER DELETE ON posts BEGIN
+    DELETE FROM posts_fts WHERE id = old.id;
+END;
+
+CREATE TRIGGER comments_fts_insert AFTER INSERT ON comments BEGIN
+    INSERT INTO comments_fts(id, content) VALUES (new.id, new.content);
+END;
+
+CREATE TRIGGER comments_fts_update AFTER UPDATE OF content ON comments BEGIN
+    UPDATE comments_fts SET content = new.content WHERE id = new.id;
+END;
+
+CREATE TRIGGER comments_fts_delete AFTER DELETE ON comments BEGIN
+    DELETE FROM comments_fts WHERE id = old.id;
+END;
+
+COMMIT;
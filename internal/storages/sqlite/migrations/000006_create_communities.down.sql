@@ -0,0 +1,10 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx_posts_community_keyset;
+
+ALTER TABLE posts DROP COLUMN community_id;
+
+DROP TABLE IF EXISTS community_members;
+DROP TABLE IF EXISTS communities;
+
+COMMIT;
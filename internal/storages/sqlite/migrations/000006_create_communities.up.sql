@@ -0,0 +1,25 @@
+BEGIN;
+
+CREATE TABLE communities (
+    id TEXT PRIMARY KEY,
+    owner_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    slug VARCHAR(32) NOT NULL UNIQUE,
+    description VARCHAR(1000) NOT NULL DEFAULT '',
+    are_comments_allowed BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE TABLE community_members (
+    community_id TEXT NOT NULL REFERENCES communities(id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role VARCHAR(16) NOT NULL CHECK (role IN ('MEMBER', 'MODERATOR', 'OWNER')),
+    joined_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (community_id, user_id)
+);
+
+ALTER TABLE posts ADD COLUMN community_id TEXT REFERENCES communities(id) ON DELETE CASCADE;
+
+CREATE INDEX idx_posts_community_keyset ON posts(community_id, created_at DESC, id DESC) WHERE community_id IS NOT NULL;
+
+COMMIT;
@@ -0,0 +1,60 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/Govorov1705/ozon-test/internal/storages/sqlite"
+	"github.com/Govorov1705/ozon-test/internal/transactions"
+	"github.com/google/uuid"
+)
+
+type BaseRepository struct {
+	DB *sql.DB
+}
+
+func NewBaseRepository(db *sql.DB) *BaseRepository {
+	return &BaseRepository{DB: db}
+}
+
+func (r *BaseRepository) GetQuerier(ctx context.Context) sqlite.Querier {
+	if tx, ok := transactions.GetTxFromContext(ctx); ok {
+		if sqliteTx, ok := tx.(*sqlite.SQLiteTx); ok {
+			return sqliteTx
+		}
+	}
+	return r.DB
+}
+
+// Время хранится строкой фиксированной ширины в UTC, чтобы сравнение строк
+// в keyset-условиях совпадало со сравнением моментов времени
+const timestampLayout = "2006-01-02T15:04:05.000000000Z07:00"
+
+func timestamp(t time.Time) string {
+	return t.UTC().Format(timestampLayout)
+}
+
+func now() string {
+	return timestamp(time.Now())
+}
+
+// inList заменяет PostgreSQL-шное = ANY($1): возвращает "(?, ?, ...)"
+// и аргументы для него. Для пустого списка условие IN () ложно
+func inList(IDs []uuid.UUID) (string, []any) {
+	args := make([]any, len(IDs))
+	for i, ID := range IDs {
+		args[i] = ID
+	}
+
+	return "(" + strings.TrimSuffix(strings.Repeat("?, ", len(IDs)), ", ") + ")", args
+}
+
+// likePrefix строит шаблон LIKE для поиска по началу строки. Символы
+// шаблона в prefix экранируются обратной косой чертой
+func likePrefix(prefix string) string {
+	return likeEscaper.Replace(prefix) + "%"
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
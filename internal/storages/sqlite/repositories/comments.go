@@ -0,0 +1,715 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/Govorov1705/ozon-test/internal/errs"
+	"github.com/Govorov1705/ozon-test/internal/logger"
+	"github.com/Govorov1705/ozon-test/internal/models"
+	"github.com/Govorov1705/ozon-test/internal/pagination"
+	"github.com/Govorov1705/ozon-test/internal/repositories"
+	"github.com/Govorov1705/ozon-test/internal/search"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type CommentsRepository struct {
+	*BaseRepository
+}
+
+func NewCommentsRepository(db *sql.DB) repositories.CommentsRepository {
+	return &CommentsRepository{
+		BaseRepository: NewBaseRepository(db),
+	}
+}
+
+func (r *CommentsRepository) Add(ctx context.Context, postID, userID uuid.UUID, rootID, replyTo *uuid.UUID, content string) (*models.Comment, error) {
+	comment := models.Comment{}
+
+	stmt := `
+		INSERT INTO comments(id, post_id, user_id, root_id, reply_to, content, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		RETURNING id, post_id, user_id, root_id, reply_to, content, created_at, edited_at, deleted_at,
+			upvotes, downvotes, score, controversy, best;
+	`
+
+	commentID := uuid.New()
+	if rootID == nil {
+		rootID = &commentID
+	}
+
+	querier := r.GetQuerier(ctx)
+	row := querier.QueryRowContext(ctx, stmt, commentID, postID, userID, rootID, replyTo, content, now())
+
+	err := row.Scan(
+		&comment.ID,
+		&comment.PostID,
+		&comment.UserID,
+		&comment.RootID,
+		&comment.ReplyTo,
+		&comment.Content,
+		&comment.CreatedAt,
+		&comment.EditedAt,
+		&comment.DeletedAt,
+		&comment.Upvotes,
+		&comment.Downvotes,
+		&comment.Score,
+		&comment.Controversy,
+		&comment.Best,
+	)
+	if err != nil {
+		logger.Logger.Error("error scanning row", zap.Error(err))
+		return nil, errs.ErrInternal
+	}
+
+	return &comment, nil
+}
+
+// forUpdate не нужен: транзакция SQLite уже держит блокировку записи
+func (r *CommentsRepository) GetByID(ctx context.Context, commentID uuid.UUID, forUpdate bool) (*models.Comment, error) {
+	comment := models.Comment{}
+
+	query := `
+		SELECT id, post_id, user_id, root_id, reply_to, content, created_at, edited_at, deleted_at,
+			upvotes, downvotes, score, controversy, best
+		FROM comments
+		WHERE id = ?;`
+
+	querier := r.GetQuerier(ctx)
+	row := querier.QueryRowContext(ctx, query, commentID)
+
+	err := row.Scan(
+		&comment.ID,
+		&comment.PostID,
+		&comment.UserID,
+		&comment.RootID,
+		&comment.ReplyTo,
+		&comment.Content,
+		&comment.CreatedAt,
+		&comment.EditedAt,
+		&comment.DeletedAt,
+		&comment.Upvotes,
+		&comment.Downvotes,
+		&comment.Score,
+		&comment.Controversy,
+		&comment.Best,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("comment %w", errs.ErrNotFound)
+		}
+		logger.Logger.Error("error scanning row", zap.Error(err))
+		return nil, errs.ErrInternal
+	}
+
+	return &comment, nil
+}
+
+func (r *CommentsRepository) GetByIDs(ctx context.Context, IDs []uuid.UUID) ([]*models.Comment, error) {
+	in, args := inList(IDs)
+	query := `
+		SELECT id, post_id, user_id, root_id, reply_to, content, created_at, edited_at, deleted_at,
+			upvotes, downvotes, score, controversy, best
+		FROM comments
+		WHERE id IN ` + in + `;
+	`
+
+	return r.queryComments(ctx, query, args...)
+}
+
+// GetByPostID выбирает комментарии всех уровней вложенности
+func (r *CommentsRepository) GetByPostID(ctx context.Context, postID uuid.UUID, page *pagination.Page) ([]*models.Comment, error) {
+	query := `
+		SELECT id, post_id, user_id, root_id, reply_to, content, created_at, edited_at, deleted_at,
+			upvotes, downvotes, score, controversy, best
+		FROM comments
+		WHERE post_id = ?`
+	query, args := appendKeyset(query, []any{postID}, page, "comments")
+
+	return r.queryComments(ctx, query, args...)
+}
+
+func (r *CommentsRepository) GetRootCommentsByPostID(ctx context.Context, postID uuid.UUID, page *pagination.Page) ([]*models.Comment, error) {
+	query := `
+		SELECT id, post_id, user_id, root_id, reply_to, content, created_at, edited_at, deleted_at,
+			upvotes, downvotes, score, controversy, best
+		FROM comments
+		WHERE post_id = ? AND reply_to IS NULL`
+	query, args := appendKeyset(query, []any{postID}, page, "comments")
+
+	return r.queryComments(ctx, query, args...)
+}
+
+func (r *CommentsRepository) GetRepliesByParentID(ctx context.Context, parentID uuid.UUID, page *pagination.Page) ([]*models.Comment, error) {
+	query := `
+		SELECT id, post_id, user_id, root_id, reply_to, content, created_at, edited_at, deleted_at,
+			upvotes, downvotes, score, controversy, best
+		FROM comments
+		WHERE reply_to = ?`
+	query, args := appendKeyset(query, []any{parentID}, page, "comments")
+
+	return r.queryComments(ctx, query, args...)
+}
+
+// Для каждого родителя выбирается не более limit последних ответов
+func (r *CommentsRepository) GetRepliesByParentIDs(ctx context.Context, parentIDs []uuid.UUID, limit int32) ([]*models.Comment, error) {
+	in, args := inList(parentIDs)
+	query := `
+		SELECT id, post_id, user_id, root_id, reply_to, content, created_at, edited_at, deleted_at,
+			upvotes, downvotes, score, controversy, best
+		FROM (
+			SELECT
+				id, post_id, user_id, root_id, reply_to, content, created_at, edited_at, deleted_at,
+				upvotes, downvotes, score, controversy, best,
+				ROW_NUMBER() OVER (PARTITION BY reply_to ORDER BY created_at DESC, id DESC) AS rn
+			FROM comments
+			WHERE reply_to IN ` + in + `
+		) replies
+		WHERE rn <= ?
+		ORDER BY created_at DESC, id DESC;
+	`
+
+	return r.queryComments(ctx, query, append(args, limit)...)
+}
+
+func (r *CommentsRepository) CountRepliesByParentIDs(ctx context.Context, parentIDs []uuid.UUID) (map[uuid.UUID]int32, error) {
+	counts := make(map[uuid.UUID]int32, len(parentIDs))
+	for _, ID := range parentIDs {
+		counts[ID] = 0
+	}
+
+	in, args := inList(parentIDs)
+	query := `
+		SELECT reply_to, COUNT(*)
+		FROM comments
+		WHERE reply_to IN ` + in + `
+		GROUP BY reply_to;
+	`
+
+	querier := r.GetQuerier(ctx)
+	rows, err := querier.QueryContext(ctx, query, args...)
+	if err != nil {
+		logger.Logger.Error("error during query", zap.Error(err))
+		return nil, errs.ErrInternal
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			parentID uuid.UUID
+			count    int32
+		)
+
+		err := rows.Scan(&parentID, &count)
+		if err != nil {
+			logger.Logger.Error("error scanning row", zap.Error(err))
+			return nil, errs.ErrInternal
+		}
+
+		counts[parentID] = count
+	}
+
+	if err := rows.Err(); err != nil {
+		logger.Logger.Error("error during row iteration", zap.Error(err))
+		return nil, errs.ErrInternal
+	}
+
+	return counts, nil
+}
+
+func (r *CommentsRepository) UpdateContent(ctx context.Context, commentID uuid.UUID, content string) (*models.Comment, error) {
+	comment := models.Comment{}
+
+	stmt := `
+		UPDATE comments
+		SET content = ?, edited_at = ?
+		WHERE id = ?
+		RETURNING id, post_id, user_id, root_id, reply_to, content, created_at, edited_at, deleted_at,
+			upvotes, downvotes, score, controversy, best;
+	`
+
+	querier := r.GetQuerier(ctx)
+	row := querier.QueryRowContext(ctx, stmt, content, now(), commentID)
+
+	err := row.Scan(
+		&comment.ID,
+		&comment.PostID,
+		&comment.UserID,
+		&comment.RootID,
+		&comment.ReplyTo,
+		&comment.Content,
+		&comment.CreatedAt,
+		&comment.EditedAt,
+		&comment.DeletedAt,
+		&comment.Upvotes,
+		&comment.Downvotes,
+		&comment.Score,
+		&comment.Controversy,
+		&comment.Best,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("comment %w", errs.ErrNotFound)
+		}
+		logger.Logger.Error("error scanning row", zap.Error(err))
+		return nil, errs.ErrInternal
+	}
+
+	return &comment, nil
+}
+
+func (r *CommentsRepository) AddRevision(ctx context.Context, commentID uuid.UUID, content string) (*models.CommentRevision, error) {
+	revision := models.CommentRevision{}
+
+	stmt := `
+		INSERT INTO comment_revisions(id, comment_id, content, created_at)
+		VALUES (?, ?, ?, ?)
+		RETURNING id, comment_id, content, created_at;
+	`
+
+	querier := r.GetQuerier(ctx)
+	row := querier.QueryRowContext(ctx, stmt, uuid.New(), commentID, content, now())
+
+	err := row.Scan(
+		&revision.ID,
+		&revision.CommentID,
+		&revision.Content,
+		&revision.CreatedAt,
+	)
+	if err != nil {
+		logger.Logger.Error("error scanning row", zap.Error(err))
+		return nil, errs.ErrInternal
+	}
+
+	return &revision, nil
+}
+
+func (r *CommentsRepository) GetRevisionsByCommentIDs(ctx context.Context, commentIDs []uuid.UUID) (map[uuid.UUID][]*models.CommentRevision, error) {
+	revisions := make(map[uuid.UUID][]*models.CommentRevision, len(commentIDs))
+	in, args := inList(commentIDs)
+
+	query := `
+		SELECT id, comment_id, content, created_at
+		FROM comment_revisions
+		WHERE comment_id IN ` + in + `
+		ORDER BY created_at, id;
+	`
+
+	querier := r.GetQuerier(ctx)
+	rows, err := querier.QueryContext(ctx, query, args...)
+	if err != nil {
+		logger.Logger.Error("error during query", zap.Error(err))
+		return nil, errs.ErrInternal
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		revision := models.CommentRevision{}
+
+		err := rows.Scan(
+			&revision.ID,
+			&revision.CommentID,
+			&revision.Content,
+			&revision.CreatedAt,
+		)
+		if err != nil {
+			logger.Logger.Error("error scanning row", zap.Error(err))
+			return nil, errs.ErrInternal
+		}
+
+		revisions[revision.CommentID] = append(revisions[revision.CommentID], &revision)
+	}
+
+	if err := rows.Err(); err != nil {
+		logger.Logger.Error("error during row iteration", zap.Error(err))
+		return nil, errs.ErrInternal
+	}
+
+	return revisions, nil
+}
+
+// SoftDelete стирает текст комментария, но оставляет сам комментарий в дереве,
+// чтобы не терять ответы на него. История правок сохраняется
+func (r *CommentsRepository) SoftDelete(ctx context.Context, commentID uuid.UUID) (*models.Comment, error) {
+	comment := models.Comment{}
+
+	querier := r.GetQuerier(ctx)
+
+	stmt := `
+		UPDATE comments
+		SET content = '', deleted_at = ?
+		WHERE id = ?
+		RETURNING id, post_id, user_id, root_id, reply_to, content, created_at, edited_at, deleted_at,
+			upvotes, downvotes, score, controversy, best;
+	`
+
+	row := querier.QueryRowContext(ctx, stmt, now(), commentID)
+
+	err := row.Scan(
+		&comment.ID,
+		&comment.PostID,
+		&comment.UserID,
+		&comment.RootID,
+		&comment.ReplyTo,
+		&comment.Content,
+		&comment.CreatedAt,
+		&comment.EditedAt,
+		&comment.DeletedAt,
+		&comment.Upvotes,
+		&comment.Downvotes,
+		&comment.Score,
+		&comment.Controversy,
+		&comment.Best,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("comment %w", errs.ErrNotFound)
+		}
+		logger.Logger.Error("error scanning row", zap.Error(err))
+		return nil, errs.ErrInternal
+	}
+
+	return &comment, nil
+}
+
+// HasUndeletedReplies проверяет все поддерево ответов, а не только прямые ответы
+func (r *CommentsRepository) HasUndeletedReplies(ctx context.Context, commentID uuid.UUID) (bool, error) {
+	var exists bool
+
+	query := `
+		WITH RECURSIVE subtree AS (
+			SELECT id, deleted_at
+			FROM comments
+			WHERE reply_to = ?
+			UNION ALL
+			SELECT c.id, c.deleted_at
+			FROM comments c
+			JOIN subtree s ON c.reply_to = s.id
+		)
+		SELECT EXISTS (SELECT 1 FROM subtree WHERE deleted_at IS NULL);
+	`
+
+	querier := r.GetQuerier(ctx)
+	err := querier.QueryRowContext(ctx, query, commentID).Scan(&exists)
+	if err != nil {
+		logger.Logger.Error("error scanning row", zap.Error(err))
+		return false, errs.ErrInternal
+	}
+
+	return exists, nil
+}
+
+// Ответы на комментарий удаляются каскадно
+func (r *CommentsRepository) Delete(ctx context.Context, commentID uuid.UUID) error {
+	querier := r.GetQuerier(ctx)
+	res, err := querier.ExecContext(ctx, "DELETE FROM comments WHERE id = ?;", commentID)
+	if err != nil {
+		logger.Logger.Error("error executing statement", zap.Error(err))
+		return errs.ErrInternal
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		logger.Logger.Error("error getting affected rows", zap.Error(err))
+		return errs.ErrInternal
+	}
+
+	if affected == 0 {
+		return fmt.Errorf("comment %w", errs.ErrNotFound)
+	}
+
+	return nil
+}
+
+func (r *CommentsRepository) queryComments(ctx context.Context, query string, args ...any) ([]*models.Comment, error) {
+	comments := []*models.Comment{}
+
+	querier := r.GetQuerier(ctx)
+	rows, err := querier.QueryContext(ctx, query, args...)
+	if err != nil {
+		logger.Logger.Error("error during query", zap.Error(err))
+		return nil, errs.ErrInternal
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		comment := models.Comment{}
+
+		err := rows.Scan(
+			&comment.ID,
+			&comment.PostID,
+			&comment.UserID,
+			&comment.RootID,
+			&comment.ReplyTo,
+			&comment.Content,
+			&comment.CreatedAt,
+			&comment.EditedAt,
+			&comment.DeletedAt,
+			&comment.Upvotes,
+			&comment.Downvotes,
+			&comment.Score,
+			&comment.Controversy,
+			&comment.Best,
+		)
+		if err != nil {
+			logger.Logger.Error("error scanning row", zap.Error(err))
+			return nil, errs.ErrInternal
+		}
+
+		comments = append(comments, &comment)
+	}
+
+	if err := rows.Err(); err != nil {
+		logger.Logger.Error("error during row iteration", zap.Error(err))
+		return nil, errs.ErrInternal
+	}
+
+	return comments, nil
+}
+
+// Удаленные комментарии не учитываются
+func (r *CommentsRepository) CountByUserIDs(ctx context.Context, userIDs []uuid.UUID) (map[uuid.UUID]int32, error) {
+	counts := make(map[uuid.UUID]int32, len(userIDs))
+	for _, ID := range userIDs {
+		counts[ID] = 0
+	}
+
+	in, args := inList(userIDs)
+	query := `
+		SELECT user_id, COUNT(*)
+		FROM comments
+		WHERE user_id IN ` + in + ` AND deleted_at IS NULL
+		GROUP BY user_id;
+	`
+
+	querier := r.GetQuerier(ctx)
+	rows, err := querier.QueryContext(ctx, query, args...)
+	if err != nil {
+		logger.Logger.Error("error during query", zap.Error(err))
+		return nil, errs.ErrInternal
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			userID uuid.UUID
+			count  int32
+		)
+
+		err := rows.Scan(&userID, &count)
+		if err != nil {
+			logger.Logger.Error("error scanning row", zap.Error(err))
+			return nil, errs.ErrInternal
+		}
+
+		counts[userID] = count
+	}
+
+	if err := rows.Err(); err != nil {
+		logger.Logger.Error("error during row iteration", zap.Error(err))
+		return nil, errs.ErrInternal
+	}
+
+	return counts, nil
+}
+
+func (r *CommentsRepository) GetVote(ctx context.Context, commentID, userID uuid.UUID) (*models.CommentVote, error) {
+	vote := models.CommentVote{}
+
+	query := `
+		SELECT comment_id, user_id, value, created_at
+		FROM comment_votes
+		WHERE comment_id = ? AND user_id = ?;
+	`
+
+	querier := r.GetQuerier(ctx)
+	row := querier.QueryRowContext(ctx, query, commentID, userID)
+
+	err := row.Scan(
+		&vote.CommentID,
+		&vote.UserID,
+		&vote.Value,
+		&vote.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("vote %w", errs.ErrNotFound)
+		}
+		logger.Logger.Error("error scanning row", zap.Error(err))
+		return nil, errs.ErrInternal
+	}
+
+	return &vote, nil
+}
+
+// SetVote заменяет прежний голос пользователя, сохраняя время первого голоса
+func (r *CommentsRepository) SetVote(ctx context.Context, commentID, userID uuid.UUID, value int8) (*models.CommentVote, error) {
+	vote := models.CommentVote{}
+
+	stmt := `
+		INSERT INTO comment_votes(comment_id, user_id, value, created_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT (comment_id, user_id) DO UPDATE SET value = excluded.value
+		RETURNING comment_id, user_id, value, created_at;
+	`
+
+	querier := r.GetQuerier(ctx)
+	row := querier.QueryRowContext(ctx, stmt, commentID, userID, value, now())
+
+	err := row.Scan(
+		&vote.CommentID,
+		&vote.UserID,
+		&vote.Value,
+		&vote.CreatedAt,
+	)
+	if err != nil {
+		logger.Logger.Error("error scanning row", zap.Error(err))
+		return nil, errs.ErrInternal
+	}
+
+	return &vote, nil
+}
+
+func (r *CommentsRepository) DeleteVote(ctx context.Context, commentID, userID uuid.UUID) error {
+	querier := r.GetQuerier(ctx)
+	_, err := querier.ExecContext(ctx, "DELETE FROM comment_votes WHERE comment_id = ? AND user_id = ?;", commentID, userID)
+	if err != nil {
+		logger.Logger.Error("error executing statement", zap.Error(err))
+		return errs.ErrInternal
+	}
+
+	return nil
+}
+
+func (r *CommentsRepository) UpdateVotes(ctx context.Context, commentID uuid.UUID, votes models.CommentVotes) (*models.Comment, error) {
+	comment := models.Comment{}
+
+	stmt := `
+		UPDATE comments
+		SET upvotes = ?, downvotes = ?, score = ?, controversy = ?, best = ?
+		WHERE id = ?
+		RETURNING id, post_id, user_id, root_id, reply_to, content, created_at, edited_at, deleted_at,
+			upvotes, downvotes, score, controversy, best;
+	`
+
+	querier := r.GetQuerier(ctx)
+	row := querier.QueryRowContext(
+		ctx,
+		stmt,
+		votes.Upvotes,
+		votes.Downvotes,
+		votes.Score,
+		votes.Controversy,
+		votes.Best,
+		commentID,
+	)
+
+	err := row.Scan(
+		&comment.ID,
+		&comment.PostID,
+		&comment.UserID,
+		&comment.RootID,
+		&comment.ReplyTo,
+		&comment.Content,
+		&comment.CreatedAt,
+		&comment.EditedAt,
+		&comment.DeletedAt,
+		&comment.Upvotes,
+		&comment.Downvotes,
+		&comment.Score,
+		&comment.Controversy,
+		&comment.Best,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("comment %w", errs.ErrNotFound)
+		}
+		logger.Logger.Error("error scanning row", zap.Error(err))
+		return nil, errs.ErrInternal
+	}
+
+	return &comment, nil
+}
+
+func (r *CommentsRepository) AddReaction(ctx context.Context, commentID, userID uuid.UUID, emoji string) (bool, error) {
+	return commentReactions.add(ctx, r.GetQuerier(ctx), commentID, userID, emoji)
+}
+
+func (r *CommentsRepository) DeleteReaction(ctx context.Context, commentID, userID uuid.UUID, emoji string) (bool, error) {
+	return commentReactions.delete(ctx, r.GetQuerier(ctx), commentID, userID, emoji)
+}
+
+func (r *CommentsRepository) GetReactions(ctx context.Context, commentIDs []uuid.UUID, viewerID *uuid.UUID) (map[uuid.UUID][]*models.ReactionCount, error) {
+	return commentReactions.counts(ctx, r.GetQuerier(ctx), commentIDs, viewerID)
+}
+
+func (r *CommentsRepository) Search(ctx context.Context, query string, page *pagination.Page) ([]*models.SearchHit, error) {
+	hits := []*models.SearchHit{}
+
+	sqlQuery := `
+		SELECT hits.id, hits.post_id, hits.user_id, hits.root_id, hits.reply_to, hits.content,
+			hits.created_at, hits.edited_at, hits.deleted_at,
+			hits.upvotes, hits.downvotes, hits.score, hits.controversy, hits.best,
+			hits.rank, hits.snippet
+		FROM (
+			SELECT comments.*,
+				-bm25(comments_fts, 0, ` + contentWeight + `) AS rank,
+				snippet(comments_fts, -1, ?, ?, ?, ?) AS snippet
+			FROM comments_fts
+			JOIN comments ON comments.id = comments_fts.id
+			WHERE comments_fts MATCH ?
+		) hits
+		WHERE hits.deleted_at IS NULL`
+	args := append(snippetArgs(), matchQuery(query))
+	sqlQuery, args = appendKeyset(sqlQuery, args, page, "hits")
+
+	querier := r.GetQuerier(ctx)
+	rows, err := querier.QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		logger.Logger.Error("error during query", zap.Error(err))
+		return nil, errs.ErrInternal
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		comment := models.Comment{}
+		hit := models.SearchHit{Comment: &comment}
+
+		err := rows.Scan(
+			&comment.ID,
+			&comment.PostID,
+			&comment.UserID,
+			&comment.RootID,
+			&comment.ReplyTo,
+			&comment.Content,
+			&comment.CreatedAt,
+			&comment.EditedAt,
+			&comment.DeletedAt,
+			&comment.Upvotes,
+			&comment.Downvotes,
+			&comment.Score,
+			&comment.Controversy,
+			&comment.Best,
+			&hit.Rank,
+			&hit.Snippet,
+		)
+		if err != nil {
+			logger.Logger.Error("error scanning row", zap.Error(err))
+			return nil, errs.ErrInternal
+		}
+
+		hit.Snippet = search.Highlight(hit.Snippet)
+
+		hits = append(hits, &hit)
+	}
+
+	if err := rows.Err(); err != nil {
+		logger.Logger.Error("error during row iteration", zap.Error(err))
+		return nil, errs.ErrInternal
+	}
+
+	return hits, nil
+}
@@ -0,0 +1,325 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/Govorov1705/ozon-test/internal/errs"
+	"github.com/Govorov1705/ozon-test/internal/logger"
+	"github.com/Govorov1705/ozon-test/internal/models"
+	"github.com/Govorov1705/ozon-test/internal/repositories"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type CommunitiesRepository struct {
+	*BaseRepository
+}
+
+func NewCommunitiesRepository(db *sql.DB) repositories.CommunitiesRepository {
+	return &CommunitiesRepository{
+		BaseRepository: NewBaseRepository(db),
+	}
+}
+
+func (r *CommunitiesRepository) Add(ctx context.Context, ownerID uuid.UUID, name, slug, description string, areCommentsAllowed bool) (*models.Community, error) {
+	community := models.Community{}
+
+	stmt := `
+		INSERT INTO communities(id, owner_id, name, slug, description, are_comments_allowed, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		RETURNING id, owner_id, name, slug, description, are_comments_allowed, created_at;
+	`
+
+	querier := r.GetQuerier(ctx)
+	row := querier.QueryRowContext(ctx, stmt, uuid.New(), ownerID, name, slug, description, areCommentsAllowed, now())
+
+	err := row.Scan(
+		&community.ID,
+		&community.OwnerID,
+		&community.Name,
+		&community.Slug,
+		&community.Description,
+		&community.AreCommentsAllowed,
+		&community.CreatedAt,
+	)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, errs.ErrAlreadyExists
+		}
+		logger.Logger.Error("error scanning row", zap.Error(err))
+		return nil, errs.ErrInternal
+	}
+
+	return &community, nil
+}
+
+// forUpdate не нужен: транзакция SQLite уже держит блокировку записи
+func (r *CommunitiesRepository) GetByID(ctx context.Context, communityID uuid.UUID, forUpdate bool) (*models.Community, error) {
+	query := `
+		SELECT id, owner_id, name, slug, description, are_comments_allowed, created_at
+		FROM communities
+		WHERE id = ?;
+	`
+
+	return r.queryCommunity(ctx, query, communityID)
+}
+
+func (r *CommunitiesRepository) GetBySlug(ctx context.Context, slug string) (*models.Community, error) {
+	query := `
+		SELECT id, owner_id, name, slug, description, are_comments_allowed, created_at
+		FROM communities
+		WHERE slug = ?;
+	`
+
+	return r.queryCommunity(ctx, query, slug)
+}
+
+func (r *CommunitiesRepository) queryCommunity(ctx context.Context, query string, args ...any) (*models.Community, error) {
+	community := models.Community{}
+
+	querier := r.GetQuerier(ctx)
+	row := querier.QueryRowContext(ctx, query, args...)
+
+	err := row.Scan(
+		&community.ID,
+		&community.OwnerID,
+		&community.Name,
+		&community.Slug,
+		&community.Description,
+		&community.AreCommentsAllowed,
+		&community.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("community %w", errs.ErrNotFound)
+		}
+		logger.Logger.Error("error scanning row", zap.Error(err))
+		return nil, errs.ErrInternal
+	}
+
+	return &community, nil
+}
+
+func (r *CommunitiesRepository) GetByIDs(ctx context.Context, IDs []uuid.UUID) ([]*models.Community, error) {
+	communities := []*models.Community{}
+
+	in, args := inList(IDs)
+	query := `
+		SELECT id, owner_id, name, slug, description, are_comments_allowed, created_at
+		FROM communities
+		WHERE id IN ` + in + `;
+	`
+
+	querier := r.GetQuerier(ctx)
+	rows, err := querier.QueryContext(ctx, query, args...)
+	if err != nil {
+		logger.Logger.Error("error during query", zap.Error(err))
+		return nil, errs.ErrInternal
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		community := models.Community{}
+
+		err := rows.Scan(
+			&community.ID,
+			&community.OwnerID,
+			&community.Name,
+			&community.Slug,
+			&community.Description,
+			&community.AreCommentsAllowed,
+			&community.CreatedAt,
+		)
+		if err != nil {
+			logger.Logger.Error("error scanning row", zap.Error(err))
+			return nil, errs.ErrInternal
+		}
+
+		communities = append(communities, &community)
+	}
+
+	if err := rows.Err(); err != nil {
+		logger.Logger.Error("error during row iteration", zap.Error(err))
+		return nil, errs.ErrInternal
+	}
+
+	return communities, nil
+}
+
+func (r *CommunitiesRepository) AddMember(ctx context.Context, communityID, userID uuid.UUID, role models.CommunityRole) (bool, error) {
+	stmt := `
+		INSERT INTO community_members(community_id, user_id, role, joined_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT DO NOTHING;
+	`
+
+	res, err := r.GetQuerier(ctx).ExecContext(ctx, stmt, communityID, userID, role, now())
+	if err != nil {
+		logger.Logger.Error("error executing statement", zap.Error(err))
+		return false, errs.ErrInternal
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		logger.Logger.Error("error getting affected rows", zap.Error(err))
+		return false, errs.ErrInternal
+	}
+
+	return affected == 1, nil
+}
+
+func (r *CommunitiesRepository) DeleteMember(ctx context.Context, communityID, userID uuid.UUID) (bool, error) {
+	stmt := `
+		DELETE FROM community_members
+		WHERE community_id = ? AND user_id = ?;
+	`
+
+	res, err := r.GetQuerier(ctx).ExecContext(ctx, stmt, communityID, userID)
+	if err != nil {
+		logger.Logger.Error("error executing statement", zap.Error(err))
+		return false, errs.ErrInternal
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		logger.Logger.Error("error getting affected rows", zap.Error(err))
+		return false, errs.ErrInternal
+	}
+
+	return affected == 1, nil
+}
+
+func (r *CommunitiesRepository) GetMember(ctx context.Context, communityID, userID uuid.UUID) (*models.CommunityMember, error) {
+	query := `
+		SELECT community_id, user_id, role, joined_at
+		FROM community_members
+		WHERE community_id = ? AND user_id = ?;
+	`
+
+	return r.queryMember(ctx, query, communityID, userID)
+}
+
+func (r *CommunitiesRepository) SetMemberRole(ctx context.Context, communityID, userID uuid.UUID, role models.CommunityRole) (*models.CommunityMember, error) {
+	stmt := `
+		UPDATE community_members
+		SET role = ?
+		WHERE community_id = ? AND user_id = ?
+		RETURNING community_id, user_id, role, joined_at;
+	`
+
+	return r.queryMember(ctx, stmt, role, communityID, userID)
+}
+
+func (r *CommunitiesRepository) queryMember(ctx context.Context, query string, args ...any) (*models.CommunityMember, error) {
+	member := models.CommunityMember{}
+
+	querier := r.GetQuerier(ctx)
+	row := querier.QueryRowContext(ctx, query, args...)
+
+	err := row.Scan(
+		&member.CommunityID,
+		&member.UserID,
+		&member.Role,
+		&member.JoinedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("community member %w", errs.ErrNotFound)
+		}
+		logger.Logger.Error("error scanning row", zap.Error(err))
+		return nil, errs.ErrInternal
+	}
+
+	return &member, nil
+}
+
+func (r *CommunitiesRepository) GetModerators(ctx context.Context, communityID uuid.UUID) ([]*models.CommunityMember, error) {
+	members := []*models.CommunityMember{}
+
+	query := `
+		SELECT community_id, user_id, role, joined_at
+		FROM community_members
+		WHERE community_id = ? AND role IN ('MODERATOR', 'OWNER')
+		ORDER BY joined_at, user_id;
+	`
+
+	querier := r.GetQuerier(ctx)
+	rows, err := querier.QueryContext(ctx, query, communityID)
+	if err != nil {
+		logger.Logger.Error("error during query", zap.Error(err))
+		return nil, errs.ErrInternal
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		member := models.CommunityMember{}
+
+		err := rows.Scan(
+			&member.CommunityID,
+			&member.UserID,
+			&member.Role,
+			&member.JoinedAt,
+		)
+		if err != nil {
+			logger.Logger.Error("error scanning row", zap.Error(err))
+			return nil, errs.ErrInternal
+		}
+
+		members = append(members, &member)
+	}
+
+	if err := rows.Err(); err != nil {
+		logger.Logger.Error("error during row iteration", zap.Error(err))
+		return nil, errs.ErrInternal
+	}
+
+	return members, nil
+}
+
+func (r *CommunitiesRepository) CountMembers(ctx context.Context, communityIDs []uuid.UUID) (map[uuid.UUID]int32, error) {
+	counts := make(map[uuid.UUID]int32, len(communityIDs))
+	for _, ID := range communityIDs {
+		counts[ID] = 0
+	}
+
+	in, args := inList(communityIDs)
+	query := `
+		SELECT community_id, COUNT(*)
+		FROM community_members
+		WHERE community_id IN ` + in + `
+		GROUP BY community_id;
+	`
+
+	querier := r.GetQuerier(ctx)
+	rows, err := querier.QueryContext(ctx, query, args...)
+	if err != nil {
+		logger.Logger.Error("error during query", zap.Error(err))
+		return nil, errs.ErrInternal
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			communityID uuid.UUID
+			count       int32
+		)
+
+		err := rows.Scan(&communityID, &count)
+		if err != nil {
+			logger.Logger.Error("error scanning row", zap.Error(err))
+			return nil, errs.ErrInternal
+		}
+
+		counts[communityID] = count
+	}
+
+	if err := rows.Err(); err != nil {
+		logger.Logger.Error("error during row iteration", zap.Error(err))
+		return nil, errs.ErrInternal
+	}
+
+	return counts, nil
+}
@@ -0,0 +1,41 @@
+package repositories_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Govorov1705/ozon-test/internal/logger"
+	"github.com/Govorov1705/ozon-test/internal/repositories/conformance"
+	"github.com/Govorov1705/ozon-test/internal/storages/sqlite"
+	"github.com/Govorov1705/ozon-test/internal/storages/sqlite/repositories"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMain(m *testing.M) {
+	logger.InitLogger()
+	code := m.Run()
+	os.Exit(code)
+}
+
+func TestConformance(t *testing.T) {
+	conformance.Run(t, func(t *testing.T) *conformance.Backend {
+		db, err := sqlite.Open(filepath.Join(t.TempDir(), "test.db"))
+		require.NoError(t, err)
+		t.Cleanup(func() { db.Close() })
+
+		m, err := sqlite.NewMigrator(db)
+		require.NoError(t, err)
+		require.NoError(t, m.Up())
+
+		return &conformance.Backend{
+			TxStarter:     sqlite.NewSQLiteTxStarter(db),
+			Users:         repositories.NewUsersRepository(db),
+			RefreshTokens: repositories.NewRefreshTokensRepository(db),
+			Posts:         repositories.NewPostsRepository(db),
+			Comments:      repositories.NewCommentsRepository(db),
+			Communities:   repositories.NewCommunitiesRepository(db),
+			Outbox:        repositories.NewOutboxRepository(db),
+		}
+	})
+}
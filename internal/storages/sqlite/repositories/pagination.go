@@ -0,0 +1,77 @@
+package repositories
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Govorov1705/ozon-test/internal/pagination"
+)
+
+// rankColumns - столбцы, по которым упорядочены ранжированные выборки
+var rankColumns = map[pagination.Sort]string{
+	pagination.SortTop:           "score",
+	pagination.SortControversial: "controversy",
+	pagination.SortBest:          "best",
+	pagination.SortRelevance:     "rank",
+}
+
+// appendKeyset дописывает к запросу условия keyset-пагинации по (created_at, id),
+// а для ранжированных выборок - по (rank, created_at, id), сортировку и LIMIT.
+// Запрос уже должен содержать WHERE
+func appendKeyset(query string, args []any, page *pagination.Page, table string) (string, []any) {
+	columns := []string{table + ".created_at", table + ".id"}
+	rankColumn, ranked := rankColumns[page.Sort]
+	if ranked {
+		columns = append([]string{table + "." + rankColumn}, columns...)
+	}
+	key := strings.Join(columns, ", ")
+
+	condition := func(cursor *pagination.Cursor, op string) {
+		cursorArgs := []any{timestamp(cursor.CreatedAt), cursor.ID}
+		if ranked {
+			cursorArgs = append([]any{rankArg(page.Sort, cursor.Rank)}, cursorArgs...)
+		}
+
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(cursorArgs)), ", ")
+		args = append(args, cursorArgs...)
+
+		query += fmt.Sprintf(" AND (%s) %s (%s)", key, op, placeholders)
+	}
+
+	// При порядке по убыванию записи после курсора меньше него
+	desc := page.Sort != pagination.SortOld
+	afterOp, beforeOp := "<", ">"
+	if !desc {
+		afterOp, beforeOp = ">", "<"
+	}
+
+	if page.After != nil {
+		condition(page.After, afterOp)
+	}
+	if page.Before != nil {
+		condition(page.Before, beforeOp)
+	}
+
+	order := "DESC"
+	if desc == page.Backward {
+		order = "ASC"
+	}
+
+	orderBy := make([]string, len(columns))
+	for i, column := range columns {
+		orderBy[i] = column + " " + order
+	}
+
+	args = append(args, page.Limit)
+	query += fmt.Sprintf(" ORDER BY %s LIMIT ?;", strings.Join(orderBy, ", "))
+
+	return query, args
+}
+
+// Счет - целое число, и сравнивать его с курсором нужно как целое
+func rankArg(sort pagination.Sort, rank float64) any {
+	if sort == pagination.SortTop {
+		return int64(rank)
+	}
+	return rank
+}
@@ -0,0 +1,567 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/Govorov1705/ozon-test/internal/errs"
+	"github.com/Govorov1705/ozon-test/internal/logger"
+	"github.com/Govorov1705/ozon-test/internal/models"
+	"github.com/Govorov1705/ozon-test/internal/pagination"
+	"github.com/Govorov1705/ozon-test/internal/repositories"
+	"github.com/Govorov1705/ozon-test/internal/search"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type PostsRepository struct {
+	*BaseRepository
+}
+
+func NewPostsRepository(db *sql.DB) repositories.PostsRepository {
+	return &PostsRepository{
+		BaseRepository: NewBaseRepository(db),
+	}
+}
+
+func (r *PostsRepository) Add(ctx context.Context, userID uuid.UUID, communityID *uuid.UUID, title, content string, areCommentsAllowed bool) (*models.Post, error) {
+	post := models.Post{}
+
+	stmt := `
+		INSERT INTO posts(id, user_id, community_id, title, content, are_comments_allowed, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		RETURNING id, user_id, community_id, title, content, are_comments_allowed, created_at, updated_at, deleted_at;
+	`
+
+	querier := r.GetQuerier(ctx)
+	row := querier.QueryRowContext(ctx, stmt, uuid.New(), userID, communityID, title, content, areCommentsAllowed, now())
+
+	err := row.Scan(
+		&post.ID,
+		&post.UserID,
+		&post.CommunityID,
+		&post.Title,
+		&post.Content,
+		&post.AreCommentsAllowed,
+		&post.CreatedAt,
+		&post.UpdatedAt,
+		&post.DeletedAt,
+	)
+	if err != nil {
+		logger.Logger.Error("error scanning row", zap.Error(err))
+		return nil, errs.ErrInternal
+	}
+
+	return &post, nil
+}
+
+// forUpdate не нужен: транзакция SQLite уже держит блокировку записи
+func (r *PostsRepository) GetByID(ctx context.Context, postID uuid.UUID, forUpdate bool) (*models.Post, error) {
+	post := models.Post{}
+
+	query := `
+		SELECT id, user_id, community_id, title, content, are_comments_allowed, created_at, updated_at, deleted_at 
+		FROM posts
+		WHERE id = ?;`
+
+	querier := r.GetQuerier(ctx)
+	row := querier.QueryRowContext(ctx, query, postID)
+
+	err := row.Scan(
+		&post.ID,
+		&post.UserID,
+		&post.CommunityID,
+		&post.Title,
+		&post.Content,
+		&post.AreCommentsAllowed,
+		&post.CreatedAt,
+		&post.UpdatedAt,
+		&post.DeletedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("post %w", errs.ErrNotFound)
+		}
+		logger.Logger.Error("error scanning row", zap.Error(err))
+		return nil, errs.ErrInternal
+	}
+
+	return &post, nil
+}
+
+func (r *PostsRepository) GetAll(ctx context.Context) ([]*models.Post, error) {
+	posts := []*models.Post{}
+
+	query := `
+		SELECT id, user_id, community_id, title, content, are_comments_allowed, created_at, updated_at, deleted_at
+		FROM posts
+		WHERE deleted_at IS NULL
+		ORDER BY created_at DESC;
+	`
+
+	querier := r.GetQuerier(ctx)
+	rows, err := querier.QueryContext(ctx, query)
+	if err != nil {
+		logger.Logger.Error("error during query", zap.Error(err))
+		return nil, errs.ErrInternal
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		post := models.Post{}
+
+		err := rows.Scan(
+			&post.ID,
+			&post.UserID,
+			&post.CommunityID,
+			&post.Title,
+			&post.Content,
+			&post.AreCommentsAllowed,
+			&post.CreatedAt,
+			&post.UpdatedAt,
+			&post.DeletedAt,
+		)
+		if err != nil {
+			logger.Logger.Error("error scanning row", zap.Error(err))
+			return nil, errs.ErrInternal
+		}
+
+		posts = append(posts, &post)
+	}
+
+	if err := rows.Err(); err != nil {
+		logger.Logger.Error("error during row iteration", zap.Error(err))
+		return nil, errs.ErrInternal
+	}
+
+	return posts, nil
+}
+
+func (r *PostsRepository) GetPage(ctx context.Context, page *pagination.Page) ([]*models.Post, error) {
+	query := `
+		SELECT id, user_id, community_id, title, content, are_comments_allowed, created_at, updated_at, deleted_at
+		FROM posts
+		WHERE deleted_at IS NULL`
+	query, args := appendKeyset(query, []any{}, page, "posts")
+
+	return r.queryPosts(ctx, query, args...)
+}
+
+func (r *PostsRepository) GetPageByTag(ctx context.Context, tag string, page *pagination.Page) ([]*models.Post, error) {
+	query := `
+		SELECT posts.id, posts.user_id, posts.community_id, posts.title, posts.content, posts.are_comments_allowed,
+			posts.created_at, posts.updated_at, posts.deleted_at
+		FROM posts
+		JOIN post_tags ON post_tags.post_id = posts.id
+		WHERE post_tags.tag = ? AND posts.deleted_at IS NULL`
+	query, args := appendKeyset(query, []any{tag}, page, "posts")
+
+	return r.queryPosts(ctx, query, args...)
+}
+
+func (r *PostsRepository) GetPageByCommunity(ctx context.Context, communityID uuid.UUID, page *pagination.Page) ([]*models.Post, error) {
+	query := `
+		SELECT id, user_id, community_id, title, content, are_comments_allowed, created_at, updated_at, deleted_at
+		FROM posts
+		WHERE community_id = ? AND deleted_at IS NULL`
+	query, args := appendKeyset(query, []any{communityID}, page, "posts")
+
+	return r.queryPosts(ctx, query, args...)
+}
+
+func (r *PostsRepository) queryPosts(ctx context.Context, query string, args ...any) ([]*models.Post, error) {
+	posts := []*models.Post{}
+
+	querier := r.GetQuerier(ctx)
+	rows, err := querier.QueryContext(ctx, query, args...)
+	if err != nil {
+		logger.Logger.Error("error during query", zap.Error(err))
+		return nil, errs.ErrInternal
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		post := models.Post{}
+
+		err := rows.Scan(
+			&post.ID,
+			&post.UserID,
+			&post.CommunityID,
+			&post.Title,
+			&post.Content,
+			&post.AreCommentsAllowed,
+			&post.CreatedAt,
+			&post.UpdatedAt,
+			&post.DeletedAt,
+		)
+		if err != nil {
+			logger.Logger.Error("error scanning row", zap.Error(err))
+			return nil, errs.ErrInternal
+		}
+
+		posts = append(posts, &post)
+	}
+
+	if err := rows.Err(); err != nil {
+		logger.Logger.Error("error during row iteration", zap.Error(err))
+		return nil, errs.ErrInternal
+	}
+
+	return posts, nil
+}
+
+func (r *PostsRepository) DisableComments(ctx context.Context, postID uuid.UUID) (*models.Post, error) {
+	post := models.Post{}
+
+	stmt := `
+		UPDATE posts
+		SET are_comments_allowed = false
+		WHERE id = ?
+		RETURNING id, user_id, community_id, title, content, are_comments_allowed, created_at, updated_at, deleted_at;
+	`
+
+	querier := r.GetQuerier(ctx)
+	row := querier.QueryRowContext(ctx, stmt, postID)
+
+	err := row.Scan(
+		&post.ID,
+		&post.UserID,
+		&post.CommunityID,
+		&post.Title,
+		&post.Content,
+		&post.AreCommentsAllowed,
+		&post.CreatedAt,
+		&post.UpdatedAt,
+		&post.DeletedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("post %w", errs.ErrNotFound)
+		}
+		logger.Logger.Error("error scanning row", zap.Error(err))
+		return nil, errs.ErrInternal
+	}
+
+	return &post, nil
+}
+
+func (r *PostsRepository) EnableComments(ctx context.Context, postID uuid.UUID) (*models.Post, error) {
+	post := models.Post{}
+
+	stmt := `
+		UPDATE posts
+		SET are_comments_allowed = true
+		WHERE id = ?
+		RETURNING id, user_id, community_id, title, content, are_comments_allowed, created_at, updated_at, deleted_at;
+	`
+
+	querier := r.GetQuerier(ctx)
+	row := querier.QueryRowContext(ctx, stmt, postID)
+
+	err := row.Scan(
+		&post.ID,
+		&post.UserID,
+		&post.CommunityID,
+		&post.Title,
+		&post.Content,
+		&post.AreCommentsAllowed,
+		&post.CreatedAt,
+		&post.UpdatedAt,
+		&post.DeletedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("post %w", errs.ErrNotFound)
+		}
+		logger.Logger.Error("error scanning row", zap.Error(err))
+		return nil, errs.ErrInternal
+	}
+
+	return &post, nil
+}
+
+func (r *PostsRepository) Update(ctx context.Context, postID uuid.UUID, title, content string) (*models.Post, error) {
+	stmt := `
+		UPDATE posts
+		SET title = ?, content = ?, updated_at = ?
+		WHERE id = ?
+		RETURNING id, user_id, community_id, title, content, are_comments_allowed, created_at, updated_at, deleted_at;
+	`
+
+	return r.updateOne(ctx, stmt, title, content, now(), postID)
+}
+
+// SoftDelete стирает заголовок, текст и теги поста, но оставляет саму
+// запись, чтобы ссылки на пост и его комментарии продолжали разрешаться
+func (r *PostsRepository) SoftDelete(ctx context.Context, postID uuid.UUID) (*models.Post, error) {
+	_, err := r.GetQuerier(ctx).ExecContext(ctx, "DELETE FROM post_tags WHERE post_id = ?;", postID)
+	if err != nil {
+		logger.Logger.Error("error executing statement", zap.Error(err))
+		return nil, errs.ErrInternal
+	}
+
+	stmt := `
+		UPDATE posts
+		SET title = '', content = '', deleted_at = ?
+		WHERE id = ?
+		RETURNING id, user_id, community_id, title, content, are_comments_allowed, created_at, updated_at, deleted_at;
+	`
+
+	return r.updateOne(ctx, stmt, now(), postID)
+}
+
+func (r *PostsRepository) updateOne(ctx context.Context, stmt string, args ...any) (*models.Post, error) {
+	post := models.Post{}
+
+	querier := r.GetQuerier(ctx)
+	row := querier.QueryRowContext(ctx, stmt, args...)
+
+	err := row.Scan(
+		&post.ID,
+		&post.UserID,
+		&post.CommunityID,
+		&post.Title,
+		&post.Content,
+		&post.AreCommentsAllowed,
+		&post.CreatedAt,
+		&post.UpdatedAt,
+		&post.DeletedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("post %w", errs.ErrNotFound)
+		}
+		logger.Logger.Error("error scanning row", zap.Error(err))
+		return nil, errs.ErrInternal
+	}
+
+	return &post, nil
+}
+
+// Удаленные посты не учитываются
+func (r *PostsRepository) CountByUserIDs(ctx context.Context, userIDs []uuid.UUID) (map[uuid.UUID]int32, error) {
+	counts := make(map[uuid.UUID]int32, len(userIDs))
+	for _, ID := range userIDs {
+		counts[ID] = 0
+	}
+
+	in, args := inList(userIDs)
+	query := `
+		SELECT user_id, COUNT(*)
+		FROM posts
+		WHERE user_id IN ` + in + ` AND deleted_at IS NULL
+		GROUP BY user_id;
+	`
+
+	querier := r.GetQuerier(ctx)
+	rows, err := querier.QueryContext(ctx, query, args...)
+	if err != nil {
+		logger.Logger.Error("error during query", zap.Error(err))
+		return nil, errs.ErrInternal
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			userID uuid.UUID
+			count  int32
+		)
+
+		err := rows.Scan(&userID, &count)
+		if err != nil {
+			logger.Logger.Error("error scanning row", zap.Error(err))
+			return nil, errs.ErrInternal
+		}
+
+		counts[userID] = count
+	}
+
+	if err := rows.Err(); err != nil {
+		logger.Logger.Error("error during row iteration", zap.Error(err))
+		return nil, errs.ErrInternal
+	}
+
+	return counts, nil
+}
+
+func (r *PostsRepository) AddReaction(ctx context.Context, postID, userID uuid.UUID, emoji string) (bool, error) {
+	return postReactions.add(ctx, r.GetQuerier(ctx), postID, userID, emoji)
+}
+
+func (r *PostsRepository) DeleteReaction(ctx context.Context, postID, userID uuid.UUID, emoji string) (bool, error) {
+	return postReactions.delete(ctx, r.GetQuerier(ctx), postID, userID, emoji)
+}
+
+func (r *PostsRepository) GetReactions(ctx context.Context, postIDs []uuid.UUID, viewerID *uuid.UUID) (map[uuid.UUID][]*models.ReactionCount, error) {
+	return postReactions.counts(ctx, r.GetQuerier(ctx), postIDs, viewerID)
+}
+
+func (r *PostsRepository) Search(ctx context.Context, query string, page *pagination.Page) ([]*models.SearchHit, error) {
+	hits := []*models.SearchHit{}
+
+	sqlQuery := `
+		SELECT hits.id, hits.user_id, hits.community_id, hits.title, hits.content, hits.are_comments_allowed,
+			hits.created_at, hits.updated_at, hits.deleted_at, hits.rank, hits.snippet
+		FROM (
+			SELECT posts.*,
+				-bm25(posts_fts, 0, ` + titleWeight + `, ` + contentWeight + `) AS rank,
+				snippet(posts_fts, -1, ?, ?, ?, ?) AS snippet
+			FROM posts_fts
+			JOIN posts ON posts.id = posts_fts.id
+			WHERE posts_fts MATCH ?
+		) hits
+		WHERE hits.deleted_at IS NULL`
+	args := append(snippetArgs(), matchQuery(query))
+	sqlQuery, args = appendKeyset(sqlQuery, args, page, "hits")
+
+	querier := r.GetQuerier(ctx)
+	rows, err := querier.QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		logger.Logger.Error("error during query", zap.Error(err))
+		return nil, errs.ErrInternal
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		post := models.Post{}
+		hit := models.SearchHit{Post: &post}
+
+		err := rows.Scan(
+			&post.ID,
+			&post.UserID,
+			&post.CommunityID,
+			&post.Title,
+			&post.Content,
+			&post.AreCommentsAllowed,
+			&post.CreatedAt,
+			&post.UpdatedAt,
+			&post.DeletedAt,
+			&hit.Rank,
+			&hit.Snippet,
+		)
+		if err != nil {
+			logger.Logger.Error("error scanning row", zap.Error(err))
+			return nil, errs.ErrInternal
+		}
+
+		hit.Snippet = search.Highlight(hit.Snippet)
+
+		hits = append(hits, &hit)
+	}
+
+	if err := rows.Err(); err != nil {
+		logger.Logger.Error("error during row iteration", zap.Error(err))
+		return nil, errs.ErrInternal
+	}
+
+	return hits, nil
+}
+
+func (r *PostsRepository) SetTags(ctx context.Context, postID uuid.UUID, tags []string) error {
+	querier := r.GetQuerier(ctx)
+
+	_, err := querier.ExecContext(ctx, "DELETE FROM post_tags WHERE post_id = ?;", postID)
+	if err != nil {
+		logger.Logger.Error("error executing statement", zap.Error(err))
+		return errs.ErrInternal
+	}
+
+	for _, tag := range tags {
+		_, err := querier.ExecContext(ctx, "INSERT INTO post_tags(post_id, tag) VALUES (?, ?);", postID, tag)
+		if err != nil {
+			logger.Logger.Error("error executing statement", zap.Error(err))
+			return errs.ErrInternal
+		}
+	}
+
+	return nil
+}
+
+func (r *PostsRepository) GetTags(ctx context.Context, postIDs []uuid.UUID) (map[uuid.UUID][]string, error) {
+	tags := make(map[uuid.UUID][]string, len(postIDs))
+	for _, ID := range postIDs {
+		tags[ID] = []string{}
+	}
+
+	in, args := inList(postIDs)
+	query := `
+		SELECT post_id, tag
+		FROM post_tags
+		WHERE post_id IN ` + in + `
+		ORDER BY tag;
+	`
+
+	querier := r.GetQuerier(ctx)
+	rows, err := querier.QueryContext(ctx, query, args...)
+	if err != nil {
+		logger.Logger.Error("error during query", zap.Error(err))
+		return nil, errs.ErrInternal
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			postID uuid.UUID
+			tag    string
+		)
+
+		err := rows.Scan(&postID, &tag)
+		if err != nil {
+			logger.Logger.Error("error scanning row", zap.Error(err))
+			return nil, errs.ErrInternal
+		}
+
+		tags[postID] = append(tags[postID], tag)
+	}
+
+	if err := rows.Err(); err != nil {
+		logger.Logger.Error("error during row iteration", zap.Error(err))
+		return nil, errs.ErrInternal
+	}
+
+	return tags, nil
+}
+
+// Теги удаленных постов снимаются в SoftDelete, поэтому счетчики
+// учитывают только неудаленные посты без соединения с posts
+func (r *PostsRepository) SearchTags(ctx context.Context, prefix string, limit int32) ([]*models.Tag, error) {
+	tags := []*models.Tag{}
+
+	query := `
+		SELECT tag, COUNT(*)
+		FROM post_tags
+		WHERE tag LIKE ? ESCAPE '\'
+		GROUP BY tag
+		ORDER BY COUNT(*) DESC, tag
+		LIMIT ?;
+	`
+
+	querier := r.GetQuerier(ctx)
+	rows, err := querier.QueryContext(ctx, query, likePrefix(prefix), limit)
+	if err != nil {
+		logger.Logger.Error("error during query", zap.Error(err))
+		return nil, errs.ErrInternal
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		tag := models.Tag{}
+
+		err := rows.Scan(&tag.Name, &tag.PostCount)
+		if err != nil {
+			logger.Logger.Error("error scanning row", zap.Error(err))
+			return nil, errs.ErrInternal
+		}
+
+		tags = append(tags, &tag)
+	}
+
+	if err := rows.Err(); err != nil {
+		logger.Logger.Error("error during row iteration", zap.Error(err))
+		return nil, errs.ErrInternal
+	}
+
+	return tags, nil
+}
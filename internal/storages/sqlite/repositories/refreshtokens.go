@@ -0,0 +1,145 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Govorov1705/ozon-test/internal/errs"
+	"github.com/Govorov1705/ozon-test/internal/logger"
+	"github.com/Govorov1705/ozon-test/internal/models"
+	"github.com/Govorov1705/ozon-test/internal/repositories"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type RefreshTokensRepository struct {
+	*BaseRepository
+}
+
+func NewRefreshTokensRepository(db *sql.DB) repositories.RefreshTokensRepository {
+	return &RefreshTokensRepository{
+		BaseRepository: NewBaseRepository(db),
+	}
+}
+
+func (r *RefreshTokensRepository) Add(ctx context.Context, ID, sessionID, userID uuid.UUID, tokenHash string, expiresAt time.Time) (*models.RefreshToken, error) {
+	token := models.RefreshToken{}
+
+	stmt := `
+		INSERT INTO refresh_tokens(id, session_id, user_id, token_hash, expires_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		RETURNING id, session_id, user_id, token_hash, expires_at, revoked_at, created_at;
+	`
+
+	querier := r.GetQuerier(ctx)
+	row := querier.QueryRowContext(ctx, stmt, ID, sessionID, userID, tokenHash, timestamp(expiresAt), now())
+
+	err := row.Scan(
+		&token.ID,
+		&token.SessionID,
+		&token.UserID,
+		&token.TokenHash,
+		&token.ExpiresAt,
+		&token.RevokedAt,
+		&token.CreatedAt,
+	)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, errs.ErrAlreadyExists
+		}
+		logger.Logger.Error("error scanning row", zap.Error(err))
+		return nil, errs.ErrInternal
+	}
+
+	return &token, nil
+}
+
+func (r *RefreshTokensRepository) GetByID(ctx context.Context, ID uuid.UUID) (*models.RefreshToken, error) {
+	query := `
+		SELECT id, session_id, user_id, token_hash, expires_at, revoked_at, created_at
+		FROM refresh_tokens
+		WHERE id = ?;
+	`
+
+	return r.getOne(ctx, query, ID)
+}
+
+// forUpdate не нужен: транзакция SQLite уже держит блокировку записи
+func (r *RefreshTokensRepository) GetByTokenHash(ctx context.Context, tokenHash string, forUpdate bool) (*models.RefreshToken, error) {
+	query := `
+		SELECT id, session_id, user_id, token_hash, expires_at, revoked_at, created_at
+		FROM refresh_tokens
+		WHERE token_hash = ?;`
+
+	return r.getOne(ctx, query, tokenHash)
+}
+
+func (r *RefreshTokensRepository) Revoke(ctx context.Context, ID uuid.UUID) error {
+	stmt := `
+		UPDATE refresh_tokens
+		SET revoked_at = ?
+		WHERE id = ? AND revoked_at IS NULL;
+	`
+
+	return r.exec(ctx, stmt, now(), ID)
+}
+
+func (r *RefreshTokensRepository) RevokeSession(ctx context.Context, sessionID uuid.UUID) error {
+	stmt := `
+		UPDATE refresh_tokens
+		SET revoked_at = ?
+		WHERE session_id = ? AND revoked_at IS NULL;
+	`
+
+	return r.exec(ctx, stmt, now(), sessionID)
+}
+
+func (r *RefreshTokensRepository) RevokeAllByUserID(ctx context.Context, userID uuid.UUID) error {
+	stmt := `
+		UPDATE refresh_tokens
+		SET revoked_at = ?
+		WHERE user_id = ? AND revoked_at IS NULL;
+	`
+
+	return r.exec(ctx, stmt, now(), userID)
+}
+
+func (r *RefreshTokensRepository) getOne(ctx context.Context, query string, args ...any) (*models.RefreshToken, error) {
+	token := models.RefreshToken{}
+
+	querier := r.GetQuerier(ctx)
+	row := querier.QueryRowContext(ctx, query, args...)
+
+	err := row.Scan(
+		&token.ID,
+		&token.SessionID,
+		&token.UserID,
+		&token.TokenHash,
+		&token.ExpiresAt,
+		&token.RevokedAt,
+		&token.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("refresh token %w", errs.ErrNotFound)
+		}
+		logger.Logger.Error("error scanning row", zap.Error(err))
+		return nil, errs.ErrInternal
+	}
+
+	return &token, nil
+}
+
+func (r *RefreshTokensRepository) exec(ctx context.Context, stmt string, args ...any) error {
+	querier := r.GetQuerier(ctx)
+	_, err := querier.ExecContext(ctx, stmt, args...)
+	if err != nil {
+		logger.Logger.Error("error executing statement", zap.Error(err))
+		return errs.ErrInternal
+	}
+
+	return nil
+}
@@ -0,0 +1,34 @@
+package repositories
+
+import (
+	"strings"
+
+	"github.com/Govorov1705/ozon-test/internal/search"
+)
+
+// Веса столбцов в bm25 повторяют веса A и B в ts_rank PostgreSQL:
+// совпадение в заголовке поста важнее совпадения в тексте
+const (
+	titleWeight   = "1.0"
+	contentWeight = "0.4"
+)
+
+// matchQuery превращает запрос в выражение FTS5, где каждое слово взято
+// в кавычки: так операторы FTS5 в запросе не действуют, а документ должен
+// содержать все слова, как и в plainto_tsquery. Слова состоят только
+// из букв и цифр, поэтому экранировать кавычки не нужно
+func matchQuery(query string) string {
+	terms := search.Terms(query)
+	for i, term := range terms {
+		terms[i] = `"` + term + `"`
+	}
+
+	return strings.Join(terms, " ")
+}
+
+// snippetArgs - аргументы snippet() после номера столбца. snippet() не
+// экранирует текст, поэтому слова выделяются метками, а результат проходит
+// через search.Highlight
+func snippetArgs() []any {
+	return []any{search.MarkStart, search.MarkEnd, search.Ellipsis, search.SnippetWords}
+}
@@ -0,0 +1,155 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/Govorov1705/ozon-test/internal/errs"
+	"github.com/Govorov1705/ozon-test/internal/logger"
+	"github.com/Govorov1705/ozon-test/internal/models"
+	"github.com/Govorov1705/ozon-test/internal/repositories"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type UsersRepository struct {
+	*BaseRepository
+}
+
+func NewUsersRepository(db *sql.DB) repositories.UsersRepository {
+	return &UsersRepository{
+		BaseRepository: NewBaseRepository(db),
+	}
+}
+
+func (r *UsersRepository) GetByID(ctx context.Context, ID uuid.UUID) (*models.User, error) {
+	user := models.User{}
+
+	query := `
+		SELECT id, username, hashed_password, created_at
+		FROM users
+		WHERE id = ?;
+	`
+
+	querier := r.GetQuerier(ctx)
+	row := querier.QueryRowContext(ctx, query, ID)
+
+	err := row.Scan(
+		&user.ID,
+		&user.Username,
+		&user.HashedPassword,
+		&user.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("user %w", errs.ErrNotFound)
+		}
+		logger.Logger.Error("error scanning row", zap.Error(err))
+		return nil, errs.ErrInternal
+	}
+
+	return &user, nil
+}
+
+func (r *UsersRepository) GetByIDs(ctx context.Context, IDs []uuid.UUID) ([]*models.User, error) {
+	users := []*models.User{}
+
+	in, args := inList(IDs)
+	query := `
+		SELECT id, username, hashed_password, created_at
+		FROM users
+		WHERE id IN ` + in + `;
+	`
+
+	querier := r.GetQuerier(ctx)
+	rows, err := querier.QueryContext(ctx, query, args...)
+	if err != nil {
+		logger.Logger.Error("error during query", zap.Error(err))
+		return nil, errs.ErrInternal
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		user := models.User{}
+
+		err := rows.Scan(
+			&user.ID,
+			&user.Username,
+			&user.HashedPassword,
+			&user.CreatedAt,
+		)
+		if err != nil {
+			logger.Logger.Error("error scanning row", zap.Error(err))
+			return nil, errs.ErrInternal
+		}
+
+		users = append(users, &user)
+	}
+
+	if err := rows.Err(); err != nil {
+		logger.Logger.Error("error during row iteration", zap.Error(err))
+		return nil, errs.ErrInternal
+	}
+
+	return users, nil
+}
+
+func (r *UsersRepository) GetByUsername(ctx context.Context, username string) (*models.User, error) {
+	user := models.User{}
+
+	query := `
+		SELECT id, username, hashed_password, created_at
+		FROM users
+		WHERE username = ?;
+	`
+
+	querier := r.GetQuerier(ctx)
+	row := querier.QueryRowContext(ctx, query, username)
+
+	err := row.Scan(
+		&user.ID,
+		&user.Username,
+		&user.HashedPassword,
+		&user.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("user %w", errs.ErrNotFound)
+		}
+		logger.Logger.Error("error scanning row", zap.Error(err))
+		return nil, errs.ErrInternal
+	}
+
+	return &user, nil
+}
+
+func (r *UsersRepository) Add(ctx context.Context, username, hashedPassword string) (*models.User, error) {
+	user := models.User{}
+
+	stmt := `
+		INSERT INTO users(id, username, hashed_password, created_at)
+		VALUES (?, ?, ?, ?)
+		RETURNING id, username, hashed_password, created_at;
+	`
+
+	querier := r.GetQuerier(ctx)
+	row := querier.QueryRowContext(ctx, stmt, uuid.New(), username, hashedPassword, now())
+
+	err := row.Scan(
+		&user.ID,
+		&user.Username,
+		&user.HashedPassword,
+		&user.CreatedAt,
+	)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, errs.ErrAlreadyExists
+		}
+		logger.Logger.Error("error scanning row", zap.Error(err))
+		return nil, errs.ErrInternal
+	}
+
+	return &user, nil
+}
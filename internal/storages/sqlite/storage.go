@@ -0,0 +1,58 @@
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/Govorov1705/ozon-test/config"
+	"github.com/Govorov1705/ozon-test/internal/logger"
+	_ "modernc.org/sqlite"
+
+	"go.uber.org/zap"
+)
+
+// Транзакции открываются как BEGIN IMMEDIATE: SQLite допускает одного
+// писателя, и так конфликт обнаруживается при старте транзакции, а не
+// посреди нее, когда повторить уже нельзя. busy_timeout заставляет
+// конкурирующие транзакции ждать, а не сразу получать SQLITE_BUSY
+const pragmas = "_pragma=foreign_keys(1)&_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)&_txlock=immediate"
+
+type Storage struct {
+	DB *sql.DB
+}
+
+// Open открывает базу с настройками, на которые рассчитаны репозитории
+func Open(path string) (*sql.DB, error) {
+	db, err := sql.Open("sqlite", fmt.Sprintf("file:%s?%s", path, pragmas))
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return db, nil
+}
+
+func NewStorage(path string) *Storage {
+	db, err := Open(path)
+	if err != nil {
+		logger.Logger.Fatal("Error opening SQLite database", zap.Error(err))
+	}
+	logger.Logger.Info("SQLite database opened", zap.String("path", path))
+
+	// Мигратор не закрывается: вместе с ним закрылась бы и база
+	m, err := NewMigrator(db)
+	if err != nil {
+		logger.Logger.Fatal("Error creating Migrate instance", zap.Error(err))
+	}
+
+	if err := m.Prepare(config.Cfg.AutoMigrate); err != nil {
+		logger.Logger.Fatal("Error preparing database schema", zap.Error(err))
+	}
+
+	return &Storage{
+		DB: db,
+	}
+}
@@ -0,0 +1,38 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/Govorov1705/ozon-test/internal/transactions"
+)
+
+// SQLiteTx открывается как BEGIN IMMEDIATE (см. NewStorage) и сразу захватывает
+// блокировку записи всей базы, поэтому отдельный FOR UPDATE не нужен
+type SQLiteTx struct {
+	*sql.Tx
+}
+
+func (t *SQLiteTx) Commit(ctx context.Context) error {
+	return t.Tx.Commit()
+}
+
+func (t *SQLiteTx) Rollback(ctx context.Context) error {
+	return t.Tx.Rollback()
+}
+
+type SQLiteTxStarter struct {
+	db *sql.DB
+}
+
+func NewSQLiteTxStarter(db *sql.DB) *SQLiteTxStarter {
+	return &SQLiteTxStarter{db: db}
+}
+
+func (s *SQLiteTxStarter) Begin(ctx context.Context) (transactions.Tx, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &SQLiteTx{Tx: tx}, nil
+}
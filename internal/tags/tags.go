@@ -0,0 +1,60 @@
+package tags
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/Govorov1705/ozon-test/internal/errs"
+)
+
+// MaxLength - наибольшая длина тега в символах
+const MaxLength = 32
+
+// Normalize приводит тег к виду, в котором он хранится: без пробелов по
+// краям и ведущего #, в нижнем регистре, а пробелы внутри заменены на -.
+// Так "#Go Lang" и "go-lang" - один и тот же тег
+func Normalize(tag string) (string, error) {
+	tag = normalize(tag)
+
+	if tag == "" || utf8.RuneCountInString(tag) > MaxLength {
+		return "", errs.ErrInvalidTag
+	}
+	if strings.ContainsFunc(tag, func(r rune) bool { return !isTagRune(r) }) {
+		return "", errs.ErrInvalidTag
+	}
+
+	return tag, nil
+}
+
+// NormalizeAll нормализует теги поста и убирает повторы, сохраняя порядок
+func NormalizeAll(tags []string) ([]string, error) {
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag, err := Normalize(tag)
+		if err != nil {
+			return nil, err
+		}
+		if !slices.Contains(normalized, tag) {
+			normalized = append(normalized, tag)
+		}
+	}
+
+	return normalized, nil
+}
+
+// NormalizePrefix готовит начало тега для автодополнения. Недопустимые
+// символы не считаются ошибкой: такой префикс просто ничего не найдет
+func NormalizePrefix(prefix string) string {
+	return normalize(prefix)
+}
+
+func normalize(tag string) string {
+	tag = strings.TrimPrefix(strings.TrimSpace(tag), "#")
+	return strings.ToLower(strings.Join(strings.Fields(tag), "-"))
+}
+
+func isTagRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("-_+.", r)
+}